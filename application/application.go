@@ -7,6 +7,7 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/cloudreve/Cloudreve/v4/application/constants"
@@ -46,6 +47,7 @@ type server struct {
 	dbClient  *ent.Client
 	config    conf.ConfigProvider
 	server    *http.Server
+	inFlight  *inFlightRequests
 	kv        cache.Driver
 	mailQueue email.Driver
 }
@@ -97,6 +99,21 @@ func (s *server) Start() error {
 			cred := dep.CredManager()
 			cred.RefreshAll(ctx)
 		})
+		crontab.Register(setting.CronTypeSettingAuditCollect, func(ctx context.Context) {
+			dep := dependency.FromContext(ctx)
+			retention := dep.SettingProvider().SettingAuditRetention(ctx)
+			if retention <= 0 {
+				return
+			}
+
+			deleted, err := dep.SettingClient().DeleteAuditsBefore(ctx, time.Now().Add(-retention))
+			if err != nil {
+				dep.Logger().Warning("Failed to delete expired setting audit logs: %s", err)
+				return
+			}
+
+			dep.Logger().Info("Deleted %d expired setting audit logs.", deleted)
+		})
 
 		// Initialize email queue before user traffic starts.
 		_ = s.dep.EmailClient(context.Background())
@@ -125,7 +142,8 @@ func (s *server) Start() error {
 
 	api := routers.InitRouter(s.dep)
 	api.TrustedPlatform = s.config.System().ProxyHeader
-	s.server = &http.Server{Handler: api}
+	s.inFlight = newInFlightRequests()
+	s.server = &http.Server{Handler: s.inFlight.Wrap(api)}
 
 	// 如果启用了SSL
 	if s.config.SSL().CertPath != "" {
@@ -164,36 +182,42 @@ func (s *server) Start() error {
 }
 
 func (s *server) Close() {
-	if s.dbClient != nil {
-		s.logger.Info("Shutting down database connection...")
-		if err := s.dbClient.Close(); err != nil {
-			s.logger.Error("Failed to close database connection: %s", err)
-		}
-	}
-
 	ctx := context.Background()
-	if conf.SystemConfig.GracePeriod != 0 {
+	if s.config.System().GracePeriod != 0 {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, time.Duration(s.config.System().GracePeriod)*time.Second)
 		defer cancel()
 	}
 
-	// Shutdown http server
+	// Stop accepting new connections and wait for in-flight requests
 	if s.server != nil {
-		err := s.server.Shutdown(ctx)
+		s.logger.Info("Waiting for in-flight requests to finish...")
+		pending, err := drainServer(ctx, s.server, s.inFlight)
+		if len(pending) > 0 {
+			s.logger.Warning("%d request(s) still in flight at shutdown deadline, connections are closed: %s",
+				len(pending), strings.Join(pending, "; "))
+		}
 		if err != nil {
 			s.logger.Error("Failed to shutdown server: %s", err)
 		}
 	}
 
+	// Drain email queue and checkpoint tasks in queues, DB is still needed here
+	if err := s.dep.Shutdown(ctx); err != nil {
+		s.logger.Warning("Failed to shutdown dependency manager: %s", err)
+	}
+
 	if s.kv != nil {
 		if err := s.kv.Persist(util.DataPath(cache.DefaultCacheFile)); err != nil {
 			s.logger.Warning("Failed to persist cache: %s", err)
 		}
 	}
 
-	if err := s.dep.Shutdown(ctx); err != nil {
-		s.logger.Warning("Failed to shutdown dependency manager: %s", err)
+	if s.dbClient != nil {
+		s.logger.Info("Shutting down database connection...")
+		if err := s.dbClient.Close(); err != nil {
+			s.logger.Error("Failed to close database connection: %s", err)
+		}
 	}
 }
 
@@ -3,8 +3,10 @@ package dependency
 import (
 	"context"
 	"errors"
+	"fmt"
 	iofs "io/fs"
 	"net/url"
+	"strings"
 	"sync"
 	"time"
 
@@ -18,6 +20,7 @@ import (
 	"github.com/cloudreve/Cloudreve/v4/pkg/credmanager"
 	"github.com/cloudreve/Cloudreve/v4/pkg/email"
 	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/fs/mime"
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/limiter"
 	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/lock"
 	"github.com/cloudreve/Cloudreve/v4/pkg/hashid"
 	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
@@ -91,6 +94,8 @@ type Dep interface {
 	TokenAuth() auth.TokenAuth
 	// LockSystem Get a singleton lock.LockSystem instance for file lock management.
 	LockSystem() lock.LockSystem
+	// ArchiveLimiter Get a singleton limiter.ArchiveLimiter instance for limiting concurrent archive creation.
+	ArchiveLimiter() limiter.ArchiveLimiter
 	// ShareClient Creates a new inventory.ShareClient instance for access DB share store.
 	StoragePolicyClient() inventory.StoragePolicyClient
 	// RequestClient Creates a new request.Client instance for HTTP requests.
@@ -109,6 +114,8 @@ type Dep interface {
 	MediaMetaExtractor(ctx context.Context) mediameta.Extractor
 	// ThumbPipeline Get a singleton thumb.Generator instance for chained thumbnail generation.
 	ThumbPipeline() thumb.Generator
+	// ThumbProcessLimiter Get a singleton thumb.ProcessLimiter instance for limiting external thumbnail generator processes.
+	ThumbProcessLimiter() thumb.ProcessLimiter
 	// ThumbQueue Get a singleton queue.Queue instance for thumbnail generation.
 	ThumbQueue(ctx context.Context) queue.Queue
 	// EntityRecycleQueue Get a singleton queue.Queue instance for entity recycle.
@@ -156,6 +163,7 @@ type dependency struct {
 	hashidEncoder       hashid.Encoder
 	tokenAuth           auth.TokenAuth
 	lockSystem          lock.LockSystem
+	archiveLimiter      limiter.ArchiveLimiter
 	requestClient       request.Client
 	ioIntenseQueue      queue.Queue
 	thumbQueue          queue.Queue
@@ -166,6 +174,7 @@ type dependency struct {
 	ioIntenseQueueTask  queue.Task
 	mediaMeta           mediameta.Extractor
 	thumbPipeline       thumb.Generator
+	thumbLimiter        thumb.ProcessLimiter
 	mimeDetector        mime.MimeDetector
 	credManager         credmanager.CredManager
 	nodePool            cluster.NodePool
@@ -325,12 +334,8 @@ func (d *dependency) KV() cache.Driver {
 	}
 
 	config := d.ConfigProvider().Redis()
-	if config.Server != "" {
-		d.kv = cache.NewRedisStore(
-			d.Logger(),
-			10,
-			config,
-		)
+	if config.Server != "" || len(config.Nodes) > 0 {
+		d.kv = cache.NewRedisDriver(d.Logger(), config)
 	} else {
 		d.kv = cache.NewMemoStore(util.DataPath(cache.DefaultCacheFile), d.Logger())
 	}
@@ -439,7 +444,15 @@ func (d *dependency) EmailClient(ctx context.Context) email.Driver {
 		if d.emailClient != nil {
 			d.emailClient.Close()
 		}
-		d.emailClient = email.NewSMTPPool(d.SettingProvider(), d.Logger())
+		limiter := email.NewRateLimiter(d.KV(), d.GroupClient())
+		switch d.SettingProvider().MailDriver(ctx) {
+		case setting.MailDriverMailgun, setting.MailDriverSendGrid:
+			d.emailClient = email.NewAPIClient(d.SettingProvider(), d.RequestClient(), limiter, d.Logger())
+		case setting.MailDriverSES:
+			d.emailClient = email.NewSESClient(d.SettingProvider(), limiter, d.Logger())
+		default:
+			d.emailClient = email.NewSMTPPool(d.SettingProvider(), limiter, d.Logger())
+		}
 	}
 
 	return d.emailClient
@@ -734,7 +747,7 @@ func (d *dependency) CredManager() credmanager.CredManager {
 	}
 
 	if d.ConfigProvider().System().Mode == conf.MasterMode {
-		d.credManager = credmanager.New(d.KV())
+		d.credManager = credmanager.New(d.KV(), d.SettingProvider())
 	} else {
 		d.credManager = credmanager.NewSlaveManager(d.KV(), d.ConfigProvider())
 	}
@@ -760,6 +773,15 @@ func (d *dependency) LockSystem() lock.LockSystem {
 	return d.lockSystem
 }
 
+func (d *dependency) ArchiveLimiter() limiter.ArchiveLimiter {
+	if d.archiveLimiter != nil {
+		return d.archiveLimiter
+	}
+
+	d.archiveLimiter = limiter.NewArchiveLimiter()
+	return d.archiveLimiter
+}
+
 func (d *dependency) StoragePolicyClient() inventory.StoragePolicyClient {
 	if d.storagePolicyClient != nil {
 		return d.storagePolicyClient
@@ -773,10 +795,19 @@ func (d *dependency) ThumbPipeline() thumb.Generator {
 		return d.thumbPipeline
 	}
 
-	d.thumbPipeline = thumb.NewPipeline(d.SettingProvider(), d.Logger())
+	d.thumbPipeline = thumb.NewPipeline(d.SettingProvider(), d.Logger(), d.ThumbProcessLimiter())
 	return d.thumbPipeline
 }
 
+func (d *dependency) ThumbProcessLimiter() thumb.ProcessLimiter {
+	if d.thumbLimiter != nil {
+		return d.thumbLimiter
+	}
+
+	d.thumbLimiter = thumb.NewProcessLimiter()
+	return d.thumbLimiter
+}
+
 func (d *dependency) TaskRegistry() queue.TaskRegistry {
 	if d.taskRegistry != nil {
 		return d.taskRegistry
@@ -786,65 +817,78 @@ func (d *dependency) TaskRegistry() queue.TaskRegistry {
 	return d.taskRegistry
 }
 
+// Shutdown drains the email queue and stops all task queues. Tasks interrupted by shutdown are
+// checkpointed and resumed on next start. It returns error if ctx is done before all of them stop.
 func (d *dependency) Shutdown(ctx context.Context) error {
 	d.mu.Lock()
 
-	if d.emailClient != nil {
-		d.emailClient.Close()
-	}
-
-	wg := sync.WaitGroup{}
+	var (
+		emailErr error
+		wg       sync.WaitGroup
+	)
 
-	if d.mediaMetaQueue != nil {
+	if d.emailClient != nil {
 		wg.Add(1)
 		go func() {
-			d.mediaMetaQueue.Shutdown()
 			defer wg.Done()
-		}()
-	}
+			if gc, ok := d.emailClient.(email.GracefulCloser); ok {
+				emailErr = gc.CloseWithContext(ctx)
+				return
+			}
 
-	if d.thumbQueue != nil {
-		wg.Add(1)
-		go func() {
-			d.thumbQueue.Shutdown()
-			defer wg.Done()
+			d.emailClient.Close()
 		}()
 	}
 
-	if d.ioIntenseQueue != nil {
-		wg.Add(1)
-		go func() {
-			d.ioIntenseQueue.Shutdown()
-			defer wg.Done()
-		}()
+	// Archive tasks still waiting for a slot give up before queues are stopped.
+	if d.archiveLimiter != nil {
+		d.archiveLimiter.Close()
 	}
 
-	if d.entityRecycleQueue != nil {
-		wg.Add(1)
-		go func() {
-			d.entityRecycleQueue.Shutdown()
-			defer wg.Done()
-		}()
+	queues := map[string]queue.Queue{
+		string(setting.QueueTypeMediaMeta):      d.mediaMetaQueue,
+		string(setting.QueueTypeThumb):          d.thumbQueue,
+		string(setting.QueueTypeIOIntense):      d.ioIntenseQueue,
+		string(setting.QueueTypeEntityRecycle):  d.entityRecycleQueue,
+		string(setting.QueueTypeSlave):          d.slaveQueue,
+		string(setting.QueueTypeRemoteDownload): d.remoteDownloadQueue,
 	}
+	for _, q := range queues {
+		if q == nil {
+			continue
+		}
 
-	if d.slaveQueue != nil {
 		wg.Add(1)
 		go func() {
-			d.slaveQueue.Shutdown()
 			defer wg.Done()
+			q.Shutdown()
 		}()
 	}
 
-	if d.remoteDownloadQueue != nil {
-		wg.Add(1)
-		go func() {
-			d.remoteDownloadQueue.Shutdown()
-			defer wg.Done()
-		}()
+	d.mu.Unlock()
+
+	stopped := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		busy := make([]string, 0, len(queues))
+		for name, q := range queues {
+			if q != nil && q.BusyWorkers() > 0 {
+				busy = append(busy, fmt.Sprintf("%s: %d task(s)", name, q.BusyWorkers()))
+			}
+		}
+
+		return fmt.Errorf("shutdown deadline reached with running tasks [%s]: %w", strings.Join(busy, ", "), ctx.Err())
 	}
 
-	d.mu.Unlock()
-	wg.Wait()
+	if emailErr != nil {
+		return fmt.Errorf("failed to drain email queue: %w", emailErr)
+	}
 
 	return nil
 }
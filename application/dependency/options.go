@@ -7,6 +7,7 @@ import (
 	"github.com/cloudreve/Cloudreve/v4/pkg/cache"
 	"github.com/cloudreve/Cloudreve/v4/pkg/conf"
 	"github.com/cloudreve/Cloudreve/v4/pkg/email"
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/limiter"
 	"github.com/cloudreve/Cloudreve/v4/pkg/hashid"
 	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
 	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
@@ -163,3 +164,10 @@ func WithShareClient(s inventory.ShareClient) Option {
 		o.shareClient = s
 	})
 }
+
+// WithArchiveLimiter Set the default archive limiter.
+func WithArchiveLimiter(s limiter.ArchiveLimiter) Option {
+	return optionFunc(func(o *dependency) {
+		o.archiveLimiter = s
+	})
+}
@@ -0,0 +1,90 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// inFlightRequests tracks HTTP requests being served, so that requests still running
+// when the shutdown deadline is reached can be reported.
+type inFlightRequests struct {
+	mu       sync.Mutex
+	seq      uint64
+	requests map[uint64]inFlightRequest
+}
+
+type inFlightRequest struct {
+	method string
+	path   string
+	start  time.Time
+}
+
+func newInFlightRequests() *inFlightRequests {
+	return &inFlightRequests{requests: make(map[uint64]inFlightRequest)}
+}
+
+// Wrap returns a handler tracking all requests served by next.
+func (f *inFlightRequests) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.seq++
+		id := f.seq
+		f.requests[id] = inFlightRequest{method: r.Method, path: r.URL.Path, start: time.Now()}
+		f.mu.Unlock()
+
+		defer func() {
+			f.mu.Lock()
+			delete(f.requests, id)
+			f.mu.Unlock()
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Pending returns descriptions of requests still being served, oldest first.
+func (f *inFlightRequests) Pending() []string {
+	f.mu.Lock()
+	requests := make([]inFlightRequest, 0, len(f.requests))
+	for _, r := range f.requests {
+		requests = append(requests, r)
+	}
+	f.mu.Unlock()
+
+	sort.Slice(requests, func(i, j int) bool {
+		return requests[i].start.Before(requests[j].start)
+	})
+
+	res := make([]string, len(requests))
+	for i, r := range requests {
+		res[i] = fmt.Sprintf("%s %s (running for %s)", r.method, r.path, time.Since(r.start).Truncate(time.Millisecond))
+	}
+
+	return res
+}
+
+// drainServer stops accepting new connections and waits for in-flight requests to finish until
+// ctx is done. Requests still running at the deadline are returned, and their connections are
+// closed forcibly.
+func drainServer(ctx context.Context, srv *http.Server, inFlight *inFlightRequests) ([]string, error) {
+	err := srv.Shutdown(ctx)
+	if err == nil {
+		return nil, nil
+	}
+
+	var pending []string
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		pending = inFlight.Pending()
+	}
+
+	if closeErr := srv.Close(); closeErr != nil {
+		err = errors.Join(err, closeErr)
+	}
+
+	return pending, err
+}
@@ -0,0 +1,92 @@
+package application
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// startDrainTestServer serves a handler taking given duration on a random port.
+func startDrainTestServer(t *testing.T, handleTime time.Duration) (*http.Server, *inFlightRequests, string, chan struct{}) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	started := make(chan struct{}, 1)
+	inFlight := newInFlightRequests()
+	srv := &http.Server{Handler: inFlight.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		time.Sleep(handleTime)
+		_, _ = w.Write([]byte("done"))
+	}))}
+	go srv.Serve(listener)
+
+	return srv, inFlight, "http://" + listener.Addr().String() + "/slow", started
+}
+
+func TestDrainServer_InFlightCompletes(t *testing.T) {
+	a := assert.New(t)
+	srv, inFlight, url, started := startDrainTestServer(t, 200*time.Millisecond)
+
+	result := make(chan string, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err != nil {
+			result <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		result <- string(body)
+	}()
+
+	<-started
+	a.Len(inFlight.Pending(), 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	pending, err := drainServer(ctx, srv, inFlight)
+	a.NoError(err)
+	a.Empty(pending)
+	a.Equal("done", <-result)
+	a.Empty(inFlight.Pending())
+
+	// New connections are rejected after shutdown
+	_, err = http.Get(url)
+	a.Error(err)
+}
+
+func TestDrainServer_DeadlineCutoff(t *testing.T) {
+	a := assert.New(t)
+	srv, inFlight, url, started := startDrainTestServer(t, 2*time.Second)
+
+	result := make(chan error, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err == nil {
+			_, err = io.ReadAll(resp.Body)
+			resp.Body.Close()
+		}
+		result <- err
+	}()
+
+	<-started
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	pending, err := drainServer(ctx, srv, inFlight)
+	a.ErrorIs(err, context.DeadlineExceeded)
+	a.Less(time.Since(start), time.Second)
+	if a.Len(pending, 1) {
+		a.Contains(pending[0], "GET /slow")
+	}
+
+	// Connection of pending request is closed forcibly
+	a.Error(<-result)
+}
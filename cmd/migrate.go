@@ -1,12 +1,14 @@
 package cmd
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 
 	"github.com/cloudreve/Cloudreve/v4/application/constants"
 	"github.com/cloudreve/Cloudreve/v4/application/dependency"
 	"github.com/cloudreve/Cloudreve/v4/application/migrator"
+	"github.com/cloudreve/Cloudreve/v4/inventory"
 	"github.com/cloudreve/Cloudreve/v4/pkg/util"
 	"github.com/spf13/cobra"
 )
@@ -14,12 +16,14 @@ import (
 var (
 	v3ConfPath string
 	forceReset bool
+	dryRun     bool
 )
 
 func init() {
 	rootCmd.AddCommand(migrateCmd)
 	migrateCmd.PersistentFlags().StringVar(&v3ConfPath, "v3-conf", "", "Path to the v3 config file")
 	migrateCmd.PersistentFlags().BoolVar(&forceReset, "force-reset", false, "Force reset migration state and start from beginning")
+	migrateCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Print pending database schema migration as JSON lines without writing to database")
 }
 
 var migrateCmd = &cobra.Command{
@@ -32,6 +36,27 @@ var migrateCmd = &cobra.Command{
 			dependency.WithProFlag(constants.IsPro == "true"),
 		)
 		logger := dep.Logger()
+		if dryRun {
+			client, err := inventory.NewRawEntClient(logger, dep.ConfigProvider())
+			if err != nil {
+				logger.Error("Failed to connect to database: %s", err)
+				os.Exit(1)
+			}
+
+			requiredDbVersion := constants.BackendVersion
+			if constants.IsPro == "true" {
+				requiredDbVersion += "-pro"
+			}
+
+			if err := inventory.DryRunMigration(logger, client, os.Stdout, requiredDbVersion); !errors.Is(err, inventory.ErrMigrationDryRun) {
+				logger.Error("Failed to dry run migration: %s", err)
+				os.Exit(1)
+			}
+
+			logger.Info("Migration dry run finished, no changes are written to database.")
+			return
+		}
+
 		logger.Info("Migrating from v3 to v4...")
 
 		if v3ConfPath == "" {
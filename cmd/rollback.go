@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"context"
+	"os"
+
+	"github.com/cloudreve/Cloudreve/v4/application/constants"
+	"github.com/cloudreve/Cloudreve/v4/application/dependency"
+	"github.com/cloudreve/Cloudreve/v4/inventory"
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/spf13/cobra"
+)
+
+const envConfirmRollback = "CR_CONFIRM_ROLLBACK"
+
+var (
+	rollbackVersion string
+	rollbackConfirm bool
+)
+
+func init() {
+	rootCmd.AddCommand(rollbackCmd)
+	rollbackCmd.PersistentFlags().StringVar(&rollbackVersion, "to", "", "Target database schema version to roll back to")
+	rollbackCmd.PersistentFlags().BoolVar(&rollbackConfirm, "confirm", false, "Confirm the rollback, changes made by reverted patches will be lost")
+}
+
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "Revert database schema patches applied after given version",
+	Run: func(cmd *cobra.Command, args []string) {
+		dep := dependency.NewDependency(
+			dependency.WithConfigPath(confPath),
+			dependency.WithProFlag(constants.IsPro == "true"),
+		)
+		logger := dep.Logger()
+
+		if rollbackVersion == "" {
+			logger.Error("Target version is required, please use --to to specify it.")
+			os.Exit(1)
+		}
+
+		if !rollbackConfirm && os.Getenv(envConfirmRollback) != "true" {
+			logger.Error("Rollback is destructive, please back up your database first and confirm with --confirm flag or %s=true.", envConfirmRollback)
+			os.Exit(1)
+		}
+
+		client, err := inventory.NewRawEntClient(logger, dep.ConfigProvider())
+		if err != nil {
+			logger.Error("Failed to connect to database: %s", err)
+			os.Exit(1)
+		}
+		defer client.Close()
+
+		ctx := inventory.WithPrimaryRead(context.WithValue(context.Background(), logging.LoggerCtx{}, logger))
+		if err := inventory.RollbackTo(logger, client, ctx, dep.KV(), rollbackVersion); err != nil {
+			logger.Error("Failed to roll back database: %s", err)
+			os.Exit(1)
+		}
+
+		logger.Info("Database rolled back to %s.", rollbackVersion)
+	},
+}
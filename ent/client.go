@@ -24,6 +24,7 @@ import (
 	"github.com/cloudreve/Cloudreve/v4/ent/node"
 	"github.com/cloudreve/Cloudreve/v4/ent/passkey"
 	"github.com/cloudreve/Cloudreve/v4/ent/setting"
+	"github.com/cloudreve/Cloudreve/v4/ent/settingaudit"
 	"github.com/cloudreve/Cloudreve/v4/ent/share"
 	"github.com/cloudreve/Cloudreve/v4/ent/storagepolicy"
 	"github.com/cloudreve/Cloudreve/v4/ent/task"
@@ -55,6 +56,8 @@ type Client struct {
 	Passkey *PasskeyClient
 	// Setting is the client for interacting with the Setting builders.
 	Setting *SettingClient
+	// SettingAudit is the client for interacting with the SettingAudit builders.
+	SettingAudit *SettingAuditClient
 	// Share is the client for interacting with the Share builders.
 	Share *ShareClient
 	// StoragePolicy is the client for interacting with the StoragePolicy builders.
@@ -83,6 +86,7 @@ func (c *Client) init() {
 	c.Node = NewNodeClient(c.config)
 	c.Passkey = NewPasskeyClient(c.config)
 	c.Setting = NewSettingClient(c.config)
+	c.SettingAudit = NewSettingAuditClient(c.config)
 	c.Share = NewShareClient(c.config)
 	c.StoragePolicy = NewStoragePolicyClient(c.config)
 	c.Task = NewTaskClient(c.config)
@@ -188,6 +192,7 @@ func (c *Client) Tx(ctx context.Context) (*Tx, error) {
 		Node:          NewNodeClient(cfg),
 		Passkey:       NewPasskeyClient(cfg),
 		Setting:       NewSettingClient(cfg),
+		SettingAudit:  NewSettingAuditClient(cfg),
 		Share:         NewShareClient(cfg),
 		StoragePolicy: NewStoragePolicyClient(cfg),
 		Task:          NewTaskClient(cfg),
@@ -220,6 +225,7 @@ func (c *Client) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error)
 		Node:          NewNodeClient(cfg),
 		Passkey:       NewPasskeyClient(cfg),
 		Setting:       NewSettingClient(cfg),
+		SettingAudit:  NewSettingAuditClient(cfg),
 		Share:         NewShareClient(cfg),
 		StoragePolicy: NewStoragePolicyClient(cfg),
 		Task:          NewTaskClient(cfg),
@@ -254,7 +260,7 @@ func (c *Client) Close() error {
 func (c *Client) Use(hooks ...Hook) {
 	for _, n := range []interface{ Use(...Hook) }{
 		c.DavAccount, c.DirectLink, c.Entity, c.File, c.Group, c.Metadata, c.Node,
-		c.Passkey, c.Setting, c.Share, c.StoragePolicy, c.Task, c.User,
+		c.Passkey, c.Setting, c.SettingAudit, c.Share, c.StoragePolicy, c.Task, c.User,
 	} {
 		n.Use(hooks...)
 	}
@@ -265,7 +271,7 @@ func (c *Client) Use(hooks ...Hook) {
 func (c *Client) Intercept(interceptors ...Interceptor) {
 	for _, n := range []interface{ Intercept(...Interceptor) }{
 		c.DavAccount, c.DirectLink, c.Entity, c.File, c.Group, c.Metadata, c.Node,
-		c.Passkey, c.Setting, c.Share, c.StoragePolicy, c.Task, c.User,
+		c.Passkey, c.Setting, c.SettingAudit, c.Share, c.StoragePolicy, c.Task, c.User,
 	} {
 		n.Intercept(interceptors...)
 	}
@@ -292,6 +298,8 @@ func (c *Client) Mutate(ctx context.Context, m Mutation) (Value, error) {
 		return c.Passkey.mutate(ctx, m)
 	case *SettingMutation:
 		return c.Setting.mutate(ctx, m)
+	case *SettingAuditMutation:
+		return c.SettingAudit.mutate(ctx, m)
 	case *ShareMutation:
 		return c.Share.mutate(ctx, m)
 	case *StoragePolicyMutation:
@@ -1034,7 +1042,8 @@ func (c *FileClient) Hooks() []Hook {
 
 // Interceptors returns the client interceptors.
 func (c *FileClient) Interceptors() []Interceptor {
-	return c.inters.File
+	inters := c.inters.File
+	return append(inters[:len(inters):len(inters)], file.Interceptors[:]...)
 }
 
 func (c *FileClient) mutate(ctx context.Context, m *FileMutation) (Value, error) {
@@ -1807,6 +1816,139 @@ func (c *SettingClient) mutate(ctx context.Context, m *SettingMutation) (Value,
 	}
 }
 
+// SettingAuditClient is a client for the SettingAudit schema.
+type SettingAuditClient struct {
+	config
+}
+
+// NewSettingAuditClient returns a client for the SettingAudit from the given config.
+func NewSettingAuditClient(c config) *SettingAuditClient {
+	return &SettingAuditClient{config: c}
+}
+
+// Use adds a list of mutation hooks to the hooks stack.
+// A call to `Use(f, g, h)` equals to `settingaudit.Hooks(f(g(h())))`.
+func (c *SettingAuditClient) Use(hooks ...Hook) {
+	c.hooks.SettingAudit = append(c.hooks.SettingAudit, hooks...)
+}
+
+// Intercept adds a list of query interceptors to the interceptors stack.
+// A call to `Intercept(f, g, h)` equals to `settingaudit.Intercept(f(g(h())))`.
+func (c *SettingAuditClient) Intercept(interceptors ...Interceptor) {
+	c.inters.SettingAudit = append(c.inters.SettingAudit, interceptors...)
+}
+
+// Create returns a builder for creating a SettingAudit entity.
+func (c *SettingAuditClient) Create() *SettingAuditCreate {
+	mutation := newSettingAuditMutation(c.config, OpCreate)
+	return &SettingAuditCreate{config: c.config, hooks: c.Hooks(), mutation: mutation}
+}
+
+// CreateBulk returns a builder for creating a bulk of SettingAudit entities.
+func (c *SettingAuditClient) CreateBulk(builders ...*SettingAuditCreate) *SettingAuditCreateBulk {
+	return &SettingAuditCreateBulk{config: c.config, builders: builders}
+}
+
+// MapCreateBulk creates a bulk creation builder from the given slice. For each item in the slice, the function creates
+// a builder and applies setFunc on it.
+func (c *SettingAuditClient) MapCreateBulk(slice any, setFunc func(*SettingAuditCreate, int)) *SettingAuditCreateBulk {
+	rv := reflect.ValueOf(slice)
+	if rv.Kind() != reflect.Slice {
+		return &SettingAuditCreateBulk{err: fmt.Errorf("calling to SettingAuditClient.MapCreateBulk with wrong type %T, need slice", slice)}
+	}
+	builders := make([]*SettingAuditCreate, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		builders[i] = c.Create()
+		setFunc(builders[i], i)
+	}
+	return &SettingAuditCreateBulk{config: c.config, builders: builders}
+}
+
+// Update returns an update builder for SettingAudit.
+func (c *SettingAuditClient) Update() *SettingAuditUpdate {
+	mutation := newSettingAuditMutation(c.config, OpUpdate)
+	return &SettingAuditUpdate{config: c.config, hooks: c.Hooks(), mutation: mutation}
+}
+
+// UpdateOne returns an update builder for the given entity.
+func (c *SettingAuditClient) UpdateOne(sa *SettingAudit) *SettingAuditUpdateOne {
+	mutation := newSettingAuditMutation(c.config, OpUpdateOne, withSettingAudit(sa))
+	return &SettingAuditUpdateOne{config: c.config, hooks: c.Hooks(), mutation: mutation}
+}
+
+// UpdateOneID returns an update builder for the given id.
+func (c *SettingAuditClient) UpdateOneID(id int) *SettingAuditUpdateOne {
+	mutation := newSettingAuditMutation(c.config, OpUpdateOne, withSettingAuditID(id))
+	return &SettingAuditUpdateOne{config: c.config, hooks: c.Hooks(), mutation: mutation}
+}
+
+// Delete returns a delete builder for SettingAudit.
+func (c *SettingAuditClient) Delete() *SettingAuditDelete {
+	mutation := newSettingAuditMutation(c.config, OpDelete)
+	return &SettingAuditDelete{config: c.config, hooks: c.Hooks(), mutation: mutation}
+}
+
+// DeleteOne returns a builder for deleting the given entity.
+func (c *SettingAuditClient) DeleteOne(sa *SettingAudit) *SettingAuditDeleteOne {
+	return c.DeleteOneID(sa.ID)
+}
+
+// DeleteOneID returns a builder for deleting the given entity by its id.
+func (c *SettingAuditClient) DeleteOneID(id int) *SettingAuditDeleteOne {
+	builder := c.Delete().Where(settingaudit.ID(id))
+	builder.mutation.id = &id
+	builder.mutation.op = OpDeleteOne
+	return &SettingAuditDeleteOne{builder}
+}
+
+// Query returns a query builder for SettingAudit.
+func (c *SettingAuditClient) Query() *SettingAuditQuery {
+	return &SettingAuditQuery{
+		config: c.config,
+		ctx:    &QueryContext{Type: TypeSettingAudit},
+		inters: c.Interceptors(),
+	}
+}
+
+// Get returns a SettingAudit entity by its id.
+func (c *SettingAuditClient) Get(ctx context.Context, id int) (*SettingAudit, error) {
+	return c.Query().Where(settingaudit.ID(id)).Only(ctx)
+}
+
+// GetX is like Get, but panics if an error occurs.
+func (c *SettingAuditClient) GetX(ctx context.Context, id int) *SettingAudit {
+	obj, err := c.Get(ctx, id)
+	if err != nil {
+		panic(err)
+	}
+	return obj
+}
+
+// Hooks returns the client hooks.
+func (c *SettingAuditClient) Hooks() []Hook {
+	return c.hooks.SettingAudit
+}
+
+// Interceptors returns the client interceptors.
+func (c *SettingAuditClient) Interceptors() []Interceptor {
+	return c.inters.SettingAudit
+}
+
+func (c *SettingAuditClient) mutate(ctx context.Context, m *SettingAuditMutation) (Value, error) {
+	switch m.Op() {
+	case OpCreate:
+		return (&SettingAuditCreate{config: c.config, hooks: c.Hooks(), mutation: m}).Save(ctx)
+	case OpUpdate:
+		return (&SettingAuditUpdate{config: c.config, hooks: c.Hooks(), mutation: m}).Save(ctx)
+	case OpUpdateOne:
+		return (&SettingAuditUpdateOne{config: c.config, hooks: c.Hooks(), mutation: m}).Save(ctx)
+	case OpDelete, OpDeleteOne:
+		return (&SettingAuditDelete{config: c.config, hooks: c.Hooks(), mutation: m}).Exec(ctx)
+	default:
+		return nil, fmt.Errorf("ent: unknown SettingAudit mutation op: %q", m.Op())
+	}
+}
+
 // ShareClient is a client for the Share schema.
 type ShareClient struct {
 	config
@@ -2575,11 +2717,11 @@ func (c *UserClient) mutate(ctx context.Context, m *UserMutation) (Value, error)
 type (
 	hooks struct {
 		DavAccount, DirectLink, Entity, File, Group, Metadata, Node, Passkey, Setting,
-		Share, StoragePolicy, Task, User []ent.Hook
+		SettingAudit, Share, StoragePolicy, Task, User []ent.Hook
 	}
 	inters struct {
 		DavAccount, DirectLink, Entity, File, Group, Metadata, Node, Passkey, Setting,
-		Share, StoragePolicy, Task, User []ent.Interceptor
+		SettingAudit, Share, StoragePolicy, Task, User []ent.Interceptor
 	}
 )
 
@@ -21,6 +21,7 @@ import (
 	"github.com/cloudreve/Cloudreve/v4/ent/node"
 	"github.com/cloudreve/Cloudreve/v4/ent/passkey"
 	"github.com/cloudreve/Cloudreve/v4/ent/setting"
+	"github.com/cloudreve/Cloudreve/v4/ent/settingaudit"
 	"github.com/cloudreve/Cloudreve/v4/ent/share"
 	"github.com/cloudreve/Cloudreve/v4/ent/storagepolicy"
 	"github.com/cloudreve/Cloudreve/v4/ent/task"
@@ -94,6 +95,7 @@ func checkColumn(table, column string) error {
 			node.Table:          node.ValidColumn,
 			passkey.Table:       passkey.ValidColumn,
 			setting.Table:       setting.ValidColumn,
+			settingaudit.Table:  settingaudit.ValidColumn,
 			share.Table:         share.ValidColumn,
 			storagepolicy.Table: storagepolicy.ValidColumn,
 			task.Table:          task.ValidColumn,
@@ -44,6 +44,8 @@ type Entity struct {
 	UploadSessionID *uuid.UUID `json:"upload_session_id,omitempty"`
 	// RecycleOptions holds the value of the "recycle_options" field.
 	RecycleOptions *types.EntityRecycleOption `json:"recycle_options,omitempty"`
+	// Checksums holds the value of the "checksums" field.
+	Checksums map[string]string `json:"checksums,omitempty"`
 	// Edges holds the relations/edges for other nodes in the graph.
 	// The values are being populated by the EntityQuery when eager-loading is set.
 	Edges        EntityEdges `json:"edges"`
@@ -105,7 +107,7 @@ func (*Entity) scanValues(columns []string) ([]any, error) {
 		switch columns[i] {
 		case entity.FieldUploadSessionID:
 			values[i] = &sql.NullScanner{S: new(uuid.UUID)}
-		case entity.FieldRecycleOptions:
+		case entity.FieldRecycleOptions, entity.FieldChecksums:
 			values[i] = new([]byte)
 		case entity.FieldID, entity.FieldType, entity.FieldSize, entity.FieldReferenceCount, entity.FieldStoragePolicyEntities, entity.FieldCreatedBy:
 			values[i] = new(sql.NullInt64)
@@ -204,6 +206,14 @@ func (e *Entity) assignValues(columns []string, values []any) error {
 					return fmt.Errorf("unmarshal field recycle_options: %w", err)
 				}
 			}
+		case entity.FieldChecksums:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field checksums", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &e.Checksums); err != nil {
+					return fmt.Errorf("unmarshal field checksums: %w", err)
+				}
+			}
 		default:
 			e.selectValues.Set(columns[i], values[i])
 		}
@@ -291,6 +301,9 @@ func (e *Entity) String() string {
 	builder.WriteString(", ")
 	builder.WriteString("recycle_options=")
 	builder.WriteString(fmt.Sprintf("%v", e.RecycleOptions))
+	builder.WriteString(", ")
+	builder.WriteString("checksums=")
+	builder.WriteString(fmt.Sprintf("%v", e.Checksums))
 	builder.WriteByte(')')
 	return builder.String()
 }
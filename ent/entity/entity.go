@@ -37,6 +37,8 @@ const (
 	FieldUploadSessionID = "upload_session_id"
 	// FieldRecycleOptions holds the string denoting the recycle_options field in the database.
 	FieldRecycleOptions = "recycle_options"
+	// FieldChecksums holds the string denoting the checksums field in the database.
+	FieldChecksums = "checksums"
 	// EdgeFile holds the string denoting the file edge name in mutations.
 	EdgeFile = "file"
 	// EdgeUser holds the string denoting the user edge name in mutations.
@@ -80,6 +82,7 @@ var Columns = []string{
 	FieldCreatedBy,
 	FieldUploadSessionID,
 	FieldRecycleOptions,
+	FieldChecksums,
 }
 
 var (
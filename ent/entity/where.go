@@ -531,6 +531,16 @@ func RecycleOptionsNotNil() predicate.Entity {
 	return predicate.Entity(sql.FieldNotNull(FieldRecycleOptions))
 }
 
+// ChecksumsIsNil applies the IsNil predicate on the "checksums" field.
+func ChecksumsIsNil() predicate.Entity {
+	return predicate.Entity(sql.FieldIsNull(FieldChecksums))
+}
+
+// ChecksumsNotNil applies the NotNil predicate on the "checksums" field.
+func ChecksumsNotNil() predicate.Entity {
+	return predicate.Entity(sql.FieldNotNull(FieldChecksums))
+}
+
 // HasFile applies the HasEdge predicate on the "file" edge.
 func HasFile() predicate.Entity {
 	return predicate.Entity(func(s *sql.Selector) {
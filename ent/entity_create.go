@@ -141,6 +141,12 @@ func (ec *EntityCreate) SetRecycleOptions(tro *types.EntityRecycleOption) *Entit
 	return ec
 }
 
+// SetChecksums sets the "checksums" field.
+func (ec *EntityCreate) SetChecksums(m map[string]string) *EntityCreate {
+	ec.mutation.SetChecksums(m)
+	return ec
+}
+
 // AddFileIDs adds the "file" edge to the File entity by IDs.
 func (ec *EntityCreate) AddFileIDs(ids ...int) *EntityCreate {
 	ec.mutation.AddFileIDs(ids...)
@@ -340,6 +346,10 @@ func (ec *EntityCreate) createSpec() (*Entity, *sqlgraph.CreateSpec) {
 		_spec.SetField(entity.FieldRecycleOptions, field.TypeJSON, value)
 		_node.RecycleOptions = value
 	}
+	if value, ok := ec.mutation.Checksums(); ok {
+		_spec.SetField(entity.FieldChecksums, field.TypeJSON, value)
+		_node.Checksums = value
+	}
 	if nodes := ec.mutation.FileIDs(); len(nodes) > 0 {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2M,
@@ -604,6 +614,24 @@ func (u *EntityUpsert) ClearRecycleOptions() *EntityUpsert {
 	return u
 }
 
+// SetChecksums sets the "checksums" field.
+func (u *EntityUpsert) SetChecksums(v map[string]string) *EntityUpsert {
+	u.Set(entity.FieldChecksums, v)
+	return u
+}
+
+// UpdateChecksums sets the "checksums" field to the value that was provided on create.
+func (u *EntityUpsert) UpdateChecksums() *EntityUpsert {
+	u.SetExcluded(entity.FieldChecksums)
+	return u
+}
+
+// ClearChecksums clears the value of the "checksums" field.
+func (u *EntityUpsert) ClearChecksums() *EntityUpsert {
+	u.SetNull(entity.FieldChecksums)
+	return u
+}
+
 // UpdateNewValues updates the mutable fields using the new values that were set on create.
 // Using this option is equivalent to using:
 //
@@ -838,6 +866,27 @@ func (u *EntityUpsertOne) ClearRecycleOptions() *EntityUpsertOne {
 	})
 }
 
+// SetChecksums sets the "checksums" field.
+func (u *EntityUpsertOne) SetChecksums(v map[string]string) *EntityUpsertOne {
+	return u.Update(func(s *EntityUpsert) {
+		s.SetChecksums(v)
+	})
+}
+
+// UpdateChecksums sets the "checksums" field to the value that was provided on create.
+func (u *EntityUpsertOne) UpdateChecksums() *EntityUpsertOne {
+	return u.Update(func(s *EntityUpsert) {
+		s.UpdateChecksums()
+	})
+}
+
+// ClearChecksums clears the value of the "checksums" field.
+func (u *EntityUpsertOne) ClearChecksums() *EntityUpsertOne {
+	return u.Update(func(s *EntityUpsert) {
+		s.ClearChecksums()
+	})
+}
+
 // Exec executes the query.
 func (u *EntityUpsertOne) Exec(ctx context.Context) error {
 	if len(u.create.conflict) == 0 {
@@ -1243,6 +1292,27 @@ func (u *EntityUpsertBulk) ClearRecycleOptions() *EntityUpsertBulk {
 	})
 }
 
+// SetChecksums sets the "checksums" field.
+func (u *EntityUpsertBulk) SetChecksums(v map[string]string) *EntityUpsertBulk {
+	return u.Update(func(s *EntityUpsert) {
+		s.SetChecksums(v)
+	})
+}
+
+// UpdateChecksums sets the "checksums" field to the value that was provided on create.
+func (u *EntityUpsertBulk) UpdateChecksums() *EntityUpsertBulk {
+	return u.Update(func(s *EntityUpsert) {
+		s.UpdateChecksums()
+	})
+}
+
+// ClearChecksums clears the value of the "checksums" field.
+func (u *EntityUpsertBulk) ClearChecksums() *EntityUpsertBulk {
+	return u.Update(func(s *EntityUpsert) {
+		s.ClearChecksums()
+	})
+}
+
 // Exec executes the query.
 func (u *EntityUpsertBulk) Exec(ctx context.Context) error {
 	if u.create.err != nil {
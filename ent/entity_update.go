@@ -202,6 +202,18 @@ func (eu *EntityUpdate) ClearRecycleOptions() *EntityUpdate {
 	return eu
 }
 
+// SetChecksums sets the "checksums" field.
+func (eu *EntityUpdate) SetChecksums(m map[string]string) *EntityUpdate {
+	eu.mutation.SetChecksums(m)
+	return eu
+}
+
+// ClearChecksums clears the value of the "checksums" field.
+func (eu *EntityUpdate) ClearChecksums() *EntityUpdate {
+	eu.mutation.ClearChecksums()
+	return eu
+}
+
 // AddFileIDs adds the "file" edge to the File entity by IDs.
 func (eu *EntityUpdate) AddFileIDs(ids ...int) *EntityUpdate {
 	eu.mutation.AddFileIDs(ids...)
@@ -389,6 +401,12 @@ func (eu *EntityUpdate) sqlSave(ctx context.Context) (n int, err error) {
 	if eu.mutation.RecycleOptionsCleared() {
 		_spec.ClearField(entity.FieldRecycleOptions, field.TypeJSON)
 	}
+	if value, ok := eu.mutation.Checksums(); ok {
+		_spec.SetField(entity.FieldChecksums, field.TypeJSON, value)
+	}
+	if eu.mutation.ChecksumsCleared() {
+		_spec.ClearField(entity.FieldChecksums, field.TypeJSON)
+	}
 	if eu.mutation.FileCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2M,
@@ -681,6 +699,18 @@ func (euo *EntityUpdateOne) ClearRecycleOptions() *EntityUpdateOne {
 	return euo
 }
 
+// SetChecksums sets the "checksums" field.
+func (euo *EntityUpdateOne) SetChecksums(m map[string]string) *EntityUpdateOne {
+	euo.mutation.SetChecksums(m)
+	return euo
+}
+
+// ClearChecksums clears the value of the "checksums" field.
+func (euo *EntityUpdateOne) ClearChecksums() *EntityUpdateOne {
+	euo.mutation.ClearChecksums()
+	return euo
+}
+
 // AddFileIDs adds the "file" edge to the File entity by IDs.
 func (euo *EntityUpdateOne) AddFileIDs(ids ...int) *EntityUpdateOne {
 	euo.mutation.AddFileIDs(ids...)
@@ -898,6 +928,12 @@ func (euo *EntityUpdateOne) sqlSave(ctx context.Context) (_node *Entity, err err
 	if euo.mutation.RecycleOptionsCleared() {
 		_spec.ClearField(entity.FieldRecycleOptions, field.TypeJSON)
 	}
+	if value, ok := euo.mutation.Checksums(); ok {
+		_spec.SetField(entity.FieldChecksums, field.TypeJSON, value)
+	}
+	if euo.mutation.ChecksumsCleared() {
+		_spec.ClearField(entity.FieldChecksums, field.TypeJSON)
+	}
 	if euo.mutation.FileCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2M,
@@ -43,6 +43,8 @@ type File struct {
 	Props *types.FileProps `json:"props,omitempty"`
 	// StoragePolicyFiles holds the value of the "storage_policy_files" field.
 	StoragePolicyFiles int `json:"storage_policy_files,omitempty"`
+	// DeletedAt holds the value of the "deleted_at" field.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 	// Edges holds the relations/edges for other nodes in the graph.
 	// The values are being populated by the FileQuery when eager-loading is set.
 	Edges        FileEdges `json:"edges"`
@@ -169,7 +171,7 @@ func (*File) scanValues(columns []string) ([]any, error) {
 			values[i] = new(sql.NullInt64)
 		case file.FieldName:
 			values[i] = new(sql.NullString)
-		case file.FieldCreatedAt, file.FieldUpdatedAt:
+		case file.FieldCreatedAt, file.FieldUpdatedAt, file.FieldDeletedAt:
 			values[i] = new(sql.NullTime)
 		default:
 			values[i] = new(sql.UnknownType)
@@ -260,6 +262,13 @@ func (f *File) assignValues(columns []string, values []any) error {
 			} else if value.Valid {
 				f.StoragePolicyFiles = int(value.Int64)
 			}
+		case file.FieldDeletedAt:
+			if value, ok := values[i].(*sql.NullTime); !ok {
+				return fmt.Errorf("unexpected type %T for field deleted_at", values[i])
+			} else if value.Valid {
+				f.DeletedAt = new(time.Time)
+				*f.DeletedAt = value.Time
+			}
 		default:
 			f.selectValues.Set(columns[i], values[i])
 		}
@@ -368,6 +377,11 @@ func (f *File) String() string {
 	builder.WriteString(", ")
 	builder.WriteString("storage_policy_files=")
 	builder.WriteString(fmt.Sprintf("%v", f.StoragePolicyFiles))
+	builder.WriteString(", ")
+	if v := f.DeletedAt; v != nil {
+		builder.WriteString("deleted_at=")
+		builder.WriteString(v.Format(time.ANSIC))
+	}
 	builder.WriteByte(')')
 	return builder.String()
 }
@@ -37,6 +37,8 @@ const (
 	FieldProps = "props"
 	// FieldStoragePolicyFiles holds the string denoting the storage_policy_files field in the database.
 	FieldStoragePolicyFiles = "storage_policy_files"
+	// FieldDeletedAt holds the string denoting the deleted_at field in the database.
+	FieldDeletedAt = "deleted_at"
 	// EdgeOwner holds the string denoting the owner edge name in mutations.
 	EdgeOwner = "owner"
 	// EdgeStoragePolicies holds the string denoting the storage_policies edge name in mutations.
@@ -119,6 +121,7 @@ var Columns = []string{
 	FieldIsSymbolic,
 	FieldProps,
 	FieldStoragePolicyFiles,
+	FieldDeletedAt,
 }
 
 var (
@@ -143,7 +146,8 @@ func ValidColumn(column string) bool {
 //
 //	import _ "github.com/cloudreve/Cloudreve/v4/ent/runtime"
 var (
-	Hooks [1]ent.Hook
+	Hooks        [1]ent.Hook
+	Interceptors [1]ent.Interceptor
 	// DefaultCreatedAt holds the default value on creation for the "created_at" field.
 	DefaultCreatedAt func() time.Time
 	// DefaultUpdatedAt holds the default value on creation for the "updated_at" field.
@@ -212,6 +216,11 @@ func ByStoragePolicyFiles(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldStoragePolicyFiles, opts...).ToFunc()
 }
 
+// ByDeletedAt orders the results by the deleted_at field.
+func ByDeletedAt(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldDeletedAt, opts...).ToFunc()
+}
+
 // ByOwnerField orders the results by owner field.
 func ByOwnerField(field string, opts ...sql.OrderTermOption) OrderOption {
 	return func(s *sql.Selector) {
@@ -151,6 +151,20 @@ func (fc *FileCreate) SetNillableStoragePolicyFiles(i *int) *FileCreate {
 	return fc
 }
 
+// SetDeletedAt sets the "deleted_at" field.
+func (fc *FileCreate) SetDeletedAt(t time.Time) *FileCreate {
+	fc.mutation.SetDeletedAt(t)
+	return fc
+}
+
+// SetNillableDeletedAt sets the "deleted_at" field if the given value is not nil.
+func (fc *FileCreate) SetNillableDeletedAt(t *time.Time) *FileCreate {
+	if t != nil {
+		fc.SetDeletedAt(*t)
+	}
+	return fc
+}
+
 // SetOwner sets the "owner" edge to the User entity.
 func (fc *FileCreate) SetOwner(u *User) *FileCreate {
 	return fc.SetOwnerID(u.ID)
@@ -423,6 +437,10 @@ func (fc *FileCreate) createSpec() (*File, *sqlgraph.CreateSpec) {
 		_spec.SetField(file.FieldProps, field.TypeJSON, value)
 		_node.Props = value
 	}
+	if value, ok := fc.mutation.DeletedAt(); ok {
+		_spec.SetField(file.FieldDeletedAt, field.TypeTime, value)
+		_node.DeletedAt = &value
+	}
 	if nodes := fc.mutation.OwnerIDs(); len(nodes) > 0 {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2O,
@@ -768,6 +786,24 @@ func (u *FileUpsert) ClearStoragePolicyFiles() *FileUpsert {
 	return u
 }
 
+// SetDeletedAt sets the "deleted_at" field.
+func (u *FileUpsert) SetDeletedAt(v time.Time) *FileUpsert {
+	u.Set(file.FieldDeletedAt, v)
+	return u
+}
+
+// UpdateDeletedAt sets the "deleted_at" field to the value that was provided on create.
+func (u *FileUpsert) UpdateDeletedAt() *FileUpsert {
+	u.SetExcluded(file.FieldDeletedAt)
+	return u
+}
+
+// ClearDeletedAt clears the value of the "deleted_at" field.
+func (u *FileUpsert) ClearDeletedAt() *FileUpsert {
+	u.SetNull(file.FieldDeletedAt)
+	return u
+}
+
 // UpdateNewValues updates the mutable fields using the new values that were set on create.
 // Using this option is equivalent to using:
 //
@@ -1002,6 +1038,27 @@ func (u *FileUpsertOne) ClearStoragePolicyFiles() *FileUpsertOne {
 	})
 }
 
+// SetDeletedAt sets the "deleted_at" field.
+func (u *FileUpsertOne) SetDeletedAt(v time.Time) *FileUpsertOne {
+	return u.Update(func(s *FileUpsert) {
+		s.SetDeletedAt(v)
+	})
+}
+
+// UpdateDeletedAt sets the "deleted_at" field to the value that was provided on create.
+func (u *FileUpsertOne) UpdateDeletedAt() *FileUpsertOne {
+	return u.Update(func(s *FileUpsert) {
+		s.UpdateDeletedAt()
+	})
+}
+
+// ClearDeletedAt clears the value of the "deleted_at" field.
+func (u *FileUpsertOne) ClearDeletedAt() *FileUpsertOne {
+	return u.Update(func(s *FileUpsert) {
+		s.ClearDeletedAt()
+	})
+}
+
 // Exec executes the query.
 func (u *FileUpsertOne) Exec(ctx context.Context) error {
 	if len(u.create.conflict) == 0 {
@@ -1407,6 +1464,27 @@ func (u *FileUpsertBulk) ClearStoragePolicyFiles() *FileUpsertBulk {
 	})
 }
 
+// SetDeletedAt sets the "deleted_at" field.
+func (u *FileUpsertBulk) SetDeletedAt(v time.Time) *FileUpsertBulk {
+	return u.Update(func(s *FileUpsert) {
+		s.SetDeletedAt(v)
+	})
+}
+
+// UpdateDeletedAt sets the "deleted_at" field to the value that was provided on create.
+func (u *FileUpsertBulk) UpdateDeletedAt() *FileUpsertBulk {
+	return u.Update(func(s *FileUpsert) {
+		s.UpdateDeletedAt()
+	})
+}
+
+// ClearDeletedAt clears the value of the "deleted_at" field.
+func (u *FileUpsertBulk) ClearDeletedAt() *FileUpsertBulk {
+	return u.Update(func(s *FileUpsert) {
+		s.ClearDeletedAt()
+	})
+}
+
 // Exec executes the query.
 func (u *FileUpsertBulk) Exec(ctx context.Context) error {
 	if u.create.err != nil {
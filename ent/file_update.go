@@ -212,6 +212,26 @@ func (fu *FileUpdate) ClearStoragePolicyFiles() *FileUpdate {
 	return fu
 }
 
+// SetDeletedAt sets the "deleted_at" field.
+func (fu *FileUpdate) SetDeletedAt(t time.Time) *FileUpdate {
+	fu.mutation.SetDeletedAt(t)
+	return fu
+}
+
+// SetNillableDeletedAt sets the "deleted_at" field if the given value is not nil.
+func (fu *FileUpdate) SetNillableDeletedAt(t *time.Time) *FileUpdate {
+	if t != nil {
+		fu.SetDeletedAt(*t)
+	}
+	return fu
+}
+
+// ClearDeletedAt clears the value of the "deleted_at" field.
+func (fu *FileUpdate) ClearDeletedAt() *FileUpdate {
+	fu.mutation.ClearDeletedAt()
+	return fu
+}
+
 // SetOwner sets the "owner" edge to the User entity.
 func (fu *FileUpdate) SetOwner(u *User) *FileUpdate {
 	return fu.SetOwnerID(u.ID)
@@ -541,6 +561,12 @@ func (fu *FileUpdate) sqlSave(ctx context.Context) (n int, err error) {
 	if fu.mutation.PropsCleared() {
 		_spec.ClearField(file.FieldProps, field.TypeJSON)
 	}
+	if value, ok := fu.mutation.DeletedAt(); ok {
+		_spec.SetField(file.FieldDeletedAt, field.TypeTime, value)
+	}
+	if fu.mutation.DeletedAtCleared() {
+		_spec.ClearField(file.FieldDeletedAt, field.TypeTime)
+	}
 	if fu.mutation.OwnerCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2O,
@@ -1050,6 +1076,26 @@ func (fuo *FileUpdateOne) ClearStoragePolicyFiles() *FileUpdateOne {
 	return fuo
 }
 
+// SetDeletedAt sets the "deleted_at" field.
+func (fuo *FileUpdateOne) SetDeletedAt(t time.Time) *FileUpdateOne {
+	fuo.mutation.SetDeletedAt(t)
+	return fuo
+}
+
+// SetNillableDeletedAt sets the "deleted_at" field if the given value is not nil.
+func (fuo *FileUpdateOne) SetNillableDeletedAt(t *time.Time) *FileUpdateOne {
+	if t != nil {
+		fuo.SetDeletedAt(*t)
+	}
+	return fuo
+}
+
+// ClearDeletedAt clears the value of the "deleted_at" field.
+func (fuo *FileUpdateOne) ClearDeletedAt() *FileUpdateOne {
+	fuo.mutation.ClearDeletedAt()
+	return fuo
+}
+
 // SetOwner sets the "owner" edge to the User entity.
 func (fuo *FileUpdateOne) SetOwner(u *User) *FileUpdateOne {
 	return fuo.SetOwnerID(u.ID)
@@ -1409,6 +1455,12 @@ func (fuo *FileUpdateOne) sqlSave(ctx context.Context) (_node *File, err error)
 	if fuo.mutation.PropsCleared() {
 		_spec.ClearField(file.FieldProps, field.TypeJSON)
 	}
+	if value, ok := fuo.mutation.DeletedAt(); ok {
+		_spec.SetField(file.FieldDeletedAt, field.TypeTime, value)
+	}
+	if fuo.mutation.DeletedAtCleared() {
+		_spec.ClearField(file.FieldDeletedAt, field.TypeTime)
+	}
 	if fuo.mutation.OwnerCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2O,
@@ -117,6 +117,18 @@ func (f SettingFunc) Mutate(ctx context.Context, m ent.Mutation) (ent.Value, err
 	return nil, fmt.Errorf("unexpected mutation type %T. expect *ent.SettingMutation", m)
 }
 
+// The SettingAuditFunc type is an adapter to allow the use of ordinary
+// function as SettingAudit mutator.
+type SettingAuditFunc func(context.Context, *ent.SettingAuditMutation) (ent.Value, error)
+
+// Mutate calls f(ctx, m).
+func (f SettingAuditFunc) Mutate(ctx context.Context, m ent.Mutation) (ent.Value, error) {
+	if mv, ok := m.(*ent.SettingAuditMutation); ok {
+		return f(ctx, mv)
+	}
+	return nil, fmt.Errorf("unexpected mutation type %T. expect *ent.SettingAuditMutation", m)
+}
+
 // The ShareFunc type is an adapter to allow the use of ordinary
 // function as Share mutator.
 type ShareFunc func(context.Context, *ent.ShareMutation) (ent.Value, error)
@@ -18,6 +18,7 @@ import (
 	"github.com/cloudreve/Cloudreve/v4/ent/passkey"
 	"github.com/cloudreve/Cloudreve/v4/ent/predicate"
 	"github.com/cloudreve/Cloudreve/v4/ent/setting"
+	"github.com/cloudreve/Cloudreve/v4/ent/settingaudit"
 	"github.com/cloudreve/Cloudreve/v4/ent/share"
 	"github.com/cloudreve/Cloudreve/v4/ent/storagepolicy"
 	"github.com/cloudreve/Cloudreve/v4/ent/task"
@@ -323,6 +324,33 @@ func (f TraverseSetting) Traverse(ctx context.Context, q ent.Query) error {
 	return fmt.Errorf("unexpected query type %T. expect *ent.SettingQuery", q)
 }
 
+// The SettingAuditFunc type is an adapter to allow the use of ordinary function as a Querier.
+type SettingAuditFunc func(context.Context, *ent.SettingAuditQuery) (ent.Value, error)
+
+// Query calls f(ctx, q).
+func (f SettingAuditFunc) Query(ctx context.Context, q ent.Query) (ent.Value, error) {
+	if q, ok := q.(*ent.SettingAuditQuery); ok {
+		return f(ctx, q)
+	}
+	return nil, fmt.Errorf("unexpected query type %T. expect *ent.SettingAuditQuery", q)
+}
+
+// The TraverseSettingAudit type is an adapter to allow the use of ordinary function as Traverser.
+type TraverseSettingAudit func(context.Context, *ent.SettingAuditQuery) error
+
+// Intercept is a dummy implementation of Intercept that returns the next Querier in the pipeline.
+func (f TraverseSettingAudit) Intercept(next ent.Querier) ent.Querier {
+	return next
+}
+
+// Traverse calls f(ctx, q).
+func (f TraverseSettingAudit) Traverse(ctx context.Context, q ent.Query) error {
+	if q, ok := q.(*ent.SettingAuditQuery); ok {
+		return f(ctx, q)
+	}
+	return fmt.Errorf("unexpected query type %T. expect *ent.SettingAuditQuery", q)
+}
+
 // The ShareFunc type is an adapter to allow the use of ordinary function as a Querier.
 type ShareFunc func(context.Context, *ent.ShareQuery) (ent.Value, error)
 
@@ -452,6 +480,8 @@ func NewQuery(q ent.Query) (Query, error) {
 		return &query[*ent.PasskeyQuery, predicate.Passkey, passkey.OrderOption]{typ: ent.TypePasskey, tq: q}, nil
 	case *ent.SettingQuery:
 		return &query[*ent.SettingQuery, predicate.Setting, setting.OrderOption]{typ: ent.TypeSetting, tq: q}, nil
+	case *ent.SettingAuditQuery:
+		return &query[*ent.SettingAuditQuery, predicate.SettingAudit, settingaudit.OrderOption]{typ: ent.TypeSettingAudit, tq: q}, nil
 	case *ent.ShareQuery:
 		return &query[*ent.ShareQuery, predicate.Share, share.OrderOption]{typ: ent.TypeShare, tq: q}, nil
 	case *ent.StoragePolicyQuery:
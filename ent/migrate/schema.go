@@ -79,6 +79,7 @@ var (
 		{Name: "reference_count", Type: field.TypeInt, Default: 1},
 		{Name: "upload_session_id", Type: field.TypeUUID, Nullable: true},
 		{Name: "recycle_options", Type: field.TypeJSON, Nullable: true},
+		{Name: "checksums", Type: field.TypeJSON, Nullable: true},
 		{Name: "storage_policy_entities", Type: field.TypeInt},
 		{Name: "created_by", Type: field.TypeInt, Nullable: true},
 	}
@@ -90,13 +91,13 @@ var (
 		ForeignKeys: []*schema.ForeignKey{
 			{
 				Symbol:     "entities_storage_policies_entities",
-				Columns:    []*schema.Column{EntitiesColumns[10]},
+				Columns:    []*schema.Column{EntitiesColumns[11]},
 				RefColumns: []*schema.Column{StoragePoliciesColumns[0]},
 				OnDelete:   schema.NoAction,
 			},
 			{
 				Symbol:     "entities_users_entities",
-				Columns:    []*schema.Column{EntitiesColumns[11]},
+				Columns:    []*schema.Column{EntitiesColumns[12]},
 				RefColumns: []*schema.Column{UsersColumns[0]},
 				OnDelete:   schema.SetNull,
 			},
@@ -113,6 +114,7 @@ var (
 		{Name: "primary_entity", Type: field.TypeInt, Nullable: true},
 		{Name: "is_symbolic", Type: field.TypeBool, Default: false},
 		{Name: "props", Type: field.TypeJSON, Nullable: true},
+		{Name: "deleted_at", Type: field.TypeTime, Nullable: true, SchemaType: map[string]string{"mysql": "datetime"}},
 		{Name: "file_children", Type: field.TypeInt, Nullable: true},
 		{Name: "storage_policy_files", Type: field.TypeInt, Nullable: true},
 		{Name: "owner_id", Type: field.TypeInt},
@@ -125,19 +127,19 @@ var (
 		ForeignKeys: []*schema.ForeignKey{
 			{
 				Symbol:     "files_files_children",
-				Columns:    []*schema.Column{FilesColumns[9]},
+				Columns:    []*schema.Column{FilesColumns[10]},
 				RefColumns: []*schema.Column{FilesColumns[0]},
 				OnDelete:   schema.SetNull,
 			},
 			{
 				Symbol:     "files_storage_policies_files",
-				Columns:    []*schema.Column{FilesColumns[10]},
+				Columns:    []*schema.Column{FilesColumns[11]},
 				RefColumns: []*schema.Column{StoragePoliciesColumns[0]},
 				OnDelete:   schema.SetNull,
 			},
 			{
 				Symbol:     "files_users_files",
-				Columns:    []*schema.Column{FilesColumns[11]},
+				Columns:    []*schema.Column{FilesColumns[12]},
 				RefColumns: []*schema.Column{UsersColumns[0]},
 				OnDelete:   schema.NoAction,
 			},
@@ -146,17 +148,22 @@ var (
 			{
 				Name:    "file_file_children_name",
 				Unique:  true,
-				Columns: []*schema.Column{FilesColumns[9], FilesColumns[4]},
+				Columns: []*schema.Column{FilesColumns[10], FilesColumns[4]},
 			},
 			{
 				Name:    "file_file_children_type_updated_at",
 				Unique:  false,
-				Columns: []*schema.Column{FilesColumns[9], FilesColumns[3], FilesColumns[2]},
+				Columns: []*schema.Column{FilesColumns[10], FilesColumns[3], FilesColumns[2]},
 			},
 			{
 				Name:    "file_file_children_type_size",
 				Unique:  false,
-				Columns: []*schema.Column{FilesColumns[9], FilesColumns[3], FilesColumns[5]},
+				Columns: []*schema.Column{FilesColumns[10], FilesColumns[3], FilesColumns[5]},
+			},
+			{
+				Name:    "file_deleted_at",
+				Unique:  false,
+				Columns: []*schema.Column{FilesColumns[9]},
 			},
 		},
 	}
@@ -217,6 +224,11 @@ var (
 				Unique:  true,
 				Columns: []*schema.Column{MetadataColumns[7], MetadataColumns[4]},
 			},
+			{
+				Name:    "metadata_name",
+				Unique:  false,
+				Columns: []*schema.Column{MetadataColumns[4]},
+			},
 		},
 	}
 	// NodesColumns holds the columns for the "nodes" table.
@@ -288,6 +300,33 @@ var (
 		Columns:    SettingsColumns,
 		PrimaryKey: []*schema.Column{SettingsColumns[0]},
 	}
+	// SettingAuditsColumns holds the columns for the "setting_audits" table.
+	SettingAuditsColumns = []*schema.Column{
+		{Name: "id", Type: field.TypeInt, Increment: true},
+		{Name: "created_at", Type: field.TypeTime, SchemaType: map[string]string{"mysql": "datetime"}},
+		{Name: "name", Type: field.TypeString},
+		{Name: "old_value", Type: field.TypeString, Nullable: true, Size: 2147483647},
+		{Name: "new_value", Type: field.TypeString, Nullable: true, Size: 2147483647},
+		{Name: "operator_id", Type: field.TypeInt, Default: 0},
+	}
+	// SettingAuditsTable holds the schema information for the "setting_audits" table.
+	SettingAuditsTable = &schema.Table{
+		Name:       "setting_audits",
+		Columns:    SettingAuditsColumns,
+		PrimaryKey: []*schema.Column{SettingAuditsColumns[0]},
+		Indexes: []*schema.Index{
+			{
+				Name:    "settingaudit_created_at",
+				Unique:  false,
+				Columns: []*schema.Column{SettingAuditsColumns[1]},
+			},
+			{
+				Name:    "settingaudit_name",
+				Unique:  false,
+				Columns: []*schema.Column{SettingAuditsColumns[2]},
+			},
+		},
+	}
 	// SharesColumns holds the columns for the "shares" table.
 	SharesColumns = []*schema.Column{
 		{Name: "id", Type: field.TypeInt, Increment: true},
@@ -449,6 +488,7 @@ var (
 		NodesTable,
 		PasskeysTable,
 		SettingsTable,
+		SettingAuditsTable,
 		SharesTable,
 		StoragePoliciesTable,
 		TasksTable,
@@ -21,6 +21,7 @@ import (
 	"github.com/cloudreve/Cloudreve/v4/ent/passkey"
 	"github.com/cloudreve/Cloudreve/v4/ent/predicate"
 	"github.com/cloudreve/Cloudreve/v4/ent/setting"
+	"github.com/cloudreve/Cloudreve/v4/ent/settingaudit"
 	"github.com/cloudreve/Cloudreve/v4/ent/share"
 	"github.com/cloudreve/Cloudreve/v4/ent/storagepolicy"
 	"github.com/cloudreve/Cloudreve/v4/ent/task"
@@ -49,6 +50,7 @@ const (
 	TypeNode          = "Node"
 	TypePasskey       = "Passkey"
 	TypeSetting       = "Setting"
+	TypeSettingAudit  = "SettingAudit"
 	TypeShare         = "Share"
 	TypeStoragePolicy = "StoragePolicy"
 	TypeTask          = "Task"
@@ -1724,6 +1726,7 @@ type EntityMutation struct {
 	addreference_count    *int
 	upload_session_id     *uuid.UUID
 	recycle_options       **types.EntityRecycleOption
+	checksums             *map[string]string
 	clearedFields         map[string]struct{}
 	file                  map[int]struct{}
 	removedfile           map[int]struct{}
@@ -2343,6 +2346,55 @@ func (m *EntityMutation) ResetRecycleOptions() {
 	delete(m.clearedFields, entity.FieldRecycleOptions)
 }
 
+// SetChecksums sets the "checksums" field.
+func (m *EntityMutation) SetChecksums(value map[string]string) {
+	m.checksums = &value
+}
+
+// Checksums returns the value of the "checksums" field in the mutation.
+func (m *EntityMutation) Checksums() (r map[string]string, exists bool) {
+	v := m.checksums
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldChecksums returns the old "checksums" field's value of the Entity entity.
+// If the Entity object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *EntityMutation) OldChecksums(ctx context.Context) (v map[string]string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldChecksums is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldChecksums requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldChecksums: %w", err)
+	}
+	return oldValue.Checksums, nil
+}
+
+// ClearChecksums clears the value of the "checksums" field.
+func (m *EntityMutation) ClearChecksums() {
+	m.checksums = nil
+	m.clearedFields[entity.FieldChecksums] = struct{}{}
+}
+
+// ChecksumsCleared returns if the "checksums" field was cleared in this mutation.
+func (m *EntityMutation) ChecksumsCleared() bool {
+	_, ok := m.clearedFields[entity.FieldChecksums]
+	return ok
+}
+
+// ResetChecksums resets all changes to the "checksums" field.
+func (m *EntityMutation) ResetChecksums() {
+	m.checksums = nil
+	delete(m.clearedFields, entity.FieldChecksums)
+}
+
 // AddFileIDs adds the "file" edge to the File entity by ids.
 func (m *EntityMutation) AddFileIDs(ids ...int) {
 	if m.file == nil {
@@ -2511,7 +2563,7 @@ func (m *EntityMutation) Type() string {
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
 func (m *EntityMutation) Fields() []string {
-	fields := make([]string, 0, 11)
+	fields := make([]string, 0, 12)
 	if m.created_at != nil {
 		fields = append(fields, entity.FieldCreatedAt)
 	}
@@ -2545,6 +2597,9 @@ func (m *EntityMutation) Fields() []string {
 	if m.recycle_options != nil {
 		fields = append(fields, entity.FieldRecycleOptions)
 	}
+	if m.checksums != nil {
+		fields = append(fields, entity.FieldChecksums)
+	}
 	return fields
 }
 
@@ -2575,6 +2630,8 @@ func (m *EntityMutation) Field(name string) (ent.Value, bool) {
 		return m.UploadSessionID()
 	case entity.FieldRecycleOptions:
 		return m.RecycleOptions()
+	case entity.FieldChecksums:
+		return m.Checksums()
 	}
 	return nil, false
 }
@@ -2606,6 +2663,8 @@ func (m *EntityMutation) OldField(ctx context.Context, name string) (ent.Value,
 		return m.OldUploadSessionID(ctx)
 	case entity.FieldRecycleOptions:
 		return m.OldRecycleOptions(ctx)
+	case entity.FieldChecksums:
+		return m.OldChecksums(ctx)
 	}
 	return nil, fmt.Errorf("unknown Entity field %s", name)
 }
@@ -2692,6 +2751,13 @@ func (m *EntityMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetRecycleOptions(v)
 		return nil
+	case entity.FieldChecksums:
+		v, ok := value.(map[string]string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetChecksums(v)
+		return nil
 	}
 	return fmt.Errorf("unknown Entity field %s", name)
 }
@@ -2773,6 +2839,9 @@ func (m *EntityMutation) ClearedFields() []string {
 	if m.FieldCleared(entity.FieldRecycleOptions) {
 		fields = append(fields, entity.FieldRecycleOptions)
 	}
+	if m.FieldCleared(entity.FieldChecksums) {
+		fields = append(fields, entity.FieldChecksums)
+	}
 	return fields
 }
 
@@ -2799,6 +2868,9 @@ func (m *EntityMutation) ClearField(name string) error {
 	case entity.FieldRecycleOptions:
 		m.ClearRecycleOptions()
 		return nil
+	case entity.FieldChecksums:
+		m.ClearChecksums()
+		return nil
 	}
 	return fmt.Errorf("unknown Entity nullable field %s", name)
 }
@@ -2840,6 +2912,9 @@ func (m *EntityMutation) ResetField(name string) error {
 	case entity.FieldRecycleOptions:
 		m.ResetRecycleOptions()
 		return nil
+	case entity.FieldChecksums:
+		m.ResetChecksums()
+		return nil
 	}
 	return fmt.Errorf("unknown Entity field %s", name)
 }
@@ -2981,6 +3056,7 @@ type FileMutation struct {
 	addprimary_entity       *int
 	is_symbolic             *bool
 	props                   **types.FileProps
+	deleted_at              *time.Time
 	clearedFields           map[string]struct{}
 	owner                   *int
 	clearedowner            bool
@@ -3615,6 +3691,55 @@ func (m *FileMutation) ResetStoragePolicyFiles() {
 	delete(m.clearedFields, file.FieldStoragePolicyFiles)
 }
 
+// SetDeletedAt sets the "deleted_at" field.
+func (m *FileMutation) SetDeletedAt(t time.Time) {
+	m.deleted_at = &t
+}
+
+// DeletedAt returns the value of the "deleted_at" field in the mutation.
+func (m *FileMutation) DeletedAt() (r time.Time, exists bool) {
+	v := m.deleted_at
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldDeletedAt returns the old "deleted_at" field's value of the File entity.
+// If the File object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *FileMutation) OldDeletedAt(ctx context.Context) (v *time.Time, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldDeletedAt is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldDeletedAt requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldDeletedAt: %w", err)
+	}
+	return oldValue.DeletedAt, nil
+}
+
+// ClearDeletedAt clears the value of the "deleted_at" field.
+func (m *FileMutation) ClearDeletedAt() {
+	m.deleted_at = nil
+	m.clearedFields[file.FieldDeletedAt] = struct{}{}
+}
+
+// DeletedAtCleared returns if the "deleted_at" field was cleared in this mutation.
+func (m *FileMutation) DeletedAtCleared() bool {
+	_, ok := m.clearedFields[file.FieldDeletedAt]
+	return ok
+}
+
+// ResetDeletedAt resets all changes to the "deleted_at" field.
+func (m *FileMutation) ResetDeletedAt() {
+	m.deleted_at = nil
+	delete(m.clearedFields, file.FieldDeletedAt)
+}
+
 // ClearOwner clears the "owner" edge to the User entity.
 func (m *FileMutation) ClearOwner() {
 	m.clearedowner = true
@@ -4026,7 +4151,7 @@ func (m *FileMutation) Type() string {
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
 func (m *FileMutation) Fields() []string {
-	fields := make([]string, 0, 11)
+	fields := make([]string, 0, 12)
 	if m.created_at != nil {
 		fields = append(fields, file.FieldCreatedAt)
 	}
@@ -4060,6 +4185,9 @@ func (m *FileMutation) Fields() []string {
 	if m.storage_policies != nil {
 		fields = append(fields, file.FieldStoragePolicyFiles)
 	}
+	if m.deleted_at != nil {
+		fields = append(fields, file.FieldDeletedAt)
+	}
 	return fields
 }
 
@@ -4090,6 +4218,8 @@ func (m *FileMutation) Field(name string) (ent.Value, bool) {
 		return m.Props()
 	case file.FieldStoragePolicyFiles:
 		return m.StoragePolicyFiles()
+	case file.FieldDeletedAt:
+		return m.DeletedAt()
 	}
 	return nil, false
 }
@@ -4121,6 +4251,8 @@ func (m *FileMutation) OldField(ctx context.Context, name string) (ent.Value, er
 		return m.OldProps(ctx)
 	case file.FieldStoragePolicyFiles:
 		return m.OldStoragePolicyFiles(ctx)
+	case file.FieldDeletedAt:
+		return m.OldDeletedAt(ctx)
 	}
 	return nil, fmt.Errorf("unknown File field %s", name)
 }
@@ -4207,6 +4339,13 @@ func (m *FileMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetStoragePolicyFiles(v)
 		return nil
+	case file.FieldDeletedAt:
+		v, ok := value.(time.Time)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetDeletedAt(v)
+		return nil
 	}
 	return fmt.Errorf("unknown File field %s", name)
 }
@@ -4288,6 +4427,9 @@ func (m *FileMutation) ClearedFields() []string {
 	if m.FieldCleared(file.FieldStoragePolicyFiles) {
 		fields = append(fields, file.FieldStoragePolicyFiles)
 	}
+	if m.FieldCleared(file.FieldDeletedAt) {
+		fields = append(fields, file.FieldDeletedAt)
+	}
 	return fields
 }
 
@@ -4314,6 +4456,9 @@ func (m *FileMutation) ClearField(name string) error {
 	case file.FieldStoragePolicyFiles:
 		m.ClearStoragePolicyFiles()
 		return nil
+	case file.FieldDeletedAt:
+		m.ClearDeletedAt()
+		return nil
 	}
 	return fmt.Errorf("unknown File nullable field %s", name)
 }
@@ -4355,6 +4500,9 @@ func (m *FileMutation) ResetField(name string) error {
 	case file.FieldStoragePolicyFiles:
 		m.ResetStoragePolicyFiles()
 		return nil
+	case file.FieldDeletedAt:
+		m.ResetDeletedAt()
+		return nil
 	}
 	return fmt.Errorf("unknown File field %s", name)
 }
@@ -8868,6 +9016,625 @@ func (m *SettingMutation) ResetEdge(name string) error {
 	return fmt.Errorf("unknown Setting edge %s", name)
 }
 
+// SettingAuditMutation represents an operation that mutates the SettingAudit nodes in the graph.
+type SettingAuditMutation struct {
+	config
+	op             Op
+	typ            string
+	id             *int
+	created_at     *time.Time
+	name           *string
+	old_value      *string
+	new_value      *string
+	operator_id    *int
+	addoperator_id *int
+	clearedFields  map[string]struct{}
+	done           bool
+	oldValue       func(context.Context) (*SettingAudit, error)
+	predicates     []predicate.SettingAudit
+}
+
+var _ ent.Mutation = (*SettingAuditMutation)(nil)
+
+// settingauditOption allows management of the mutation configuration using functional options.
+type settingauditOption func(*SettingAuditMutation)
+
+// newSettingAuditMutation creates new mutation for the SettingAudit entity.
+func newSettingAuditMutation(c config, op Op, opts ...settingauditOption) *SettingAuditMutation {
+	m := &SettingAuditMutation{
+		config:        c,
+		op:            op,
+		typ:           TypeSettingAudit,
+		clearedFields: make(map[string]struct{}),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// withSettingAuditID sets the ID field of the mutation.
+func withSettingAuditID(id int) settingauditOption {
+	return func(m *SettingAuditMutation) {
+		var (
+			err   error
+			once  sync.Once
+			value *SettingAudit
+		)
+		m.oldValue = func(ctx context.Context) (*SettingAudit, error) {
+			once.Do(func() {
+				if m.done {
+					err = errors.New("querying old values post mutation is not allowed")
+				} else {
+					value, err = m.Client().SettingAudit.Get(ctx, id)
+				}
+			})
+			return value, err
+		}
+		m.id = &id
+	}
+}
+
+// withSettingAudit sets the old SettingAudit of the mutation.
+func withSettingAudit(node *SettingAudit) settingauditOption {
+	return func(m *SettingAuditMutation) {
+		m.oldValue = func(context.Context) (*SettingAudit, error) {
+			return node, nil
+		}
+		m.id = &node.ID
+	}
+}
+
+// Client returns a new `ent.Client` from the mutation. If the mutation was
+// executed in a transaction (ent.Tx), a transactional client is returned.
+func (m SettingAuditMutation) Client() *Client {
+	client := &Client{config: m.config}
+	client.init()
+	return client
+}
+
+// Tx returns an `ent.Tx` for mutations that were executed in transactions;
+// it returns an error otherwise.
+func (m SettingAuditMutation) Tx() (*Tx, error) {
+	if _, ok := m.driver.(*txDriver); !ok {
+		return nil, errors.New("ent: mutation is not running in a transaction")
+	}
+	tx := &Tx{config: m.config}
+	tx.init()
+	return tx, nil
+}
+
+// ID returns the ID value in the mutation. Note that the ID is only available
+// if it was provided to the builder or after it was returned from the database.
+func (m *SettingAuditMutation) ID() (id int, exists bool) {
+	if m.id == nil {
+		return
+	}
+	return *m.id, true
+}
+
+// IDs queries the database and returns the entity ids that match the mutation's predicate.
+// That means, if the mutation is applied within a transaction with an isolation level such
+// as sql.LevelSerializable, the returned ids match the ids of the rows that will be updated
+// or updated by the mutation.
+func (m *SettingAuditMutation) IDs(ctx context.Context) ([]int, error) {
+	switch {
+	case m.op.Is(OpUpdateOne | OpDeleteOne):
+		id, exists := m.ID()
+		if exists {
+			return []int{id}, nil
+		}
+		fallthrough
+	case m.op.Is(OpUpdate | OpDelete):
+		return m.Client().SettingAudit.Query().Where(m.predicates...).IDs(ctx)
+	default:
+		return nil, fmt.Errorf("IDs is not allowed on %s operations", m.op)
+	}
+}
+
+// SetCreatedAt sets the "created_at" field.
+func (m *SettingAuditMutation) SetCreatedAt(t time.Time) {
+	m.created_at = &t
+}
+
+// CreatedAt returns the value of the "created_at" field in the mutation.
+func (m *SettingAuditMutation) CreatedAt() (r time.Time, exists bool) {
+	v := m.created_at
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldCreatedAt returns the old "created_at" field's value of the SettingAudit entity.
+// If the SettingAudit object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *SettingAuditMutation) OldCreatedAt(ctx context.Context) (v time.Time, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldCreatedAt is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldCreatedAt requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldCreatedAt: %w", err)
+	}
+	return oldValue.CreatedAt, nil
+}
+
+// ResetCreatedAt resets all changes to the "created_at" field.
+func (m *SettingAuditMutation) ResetCreatedAt() {
+	m.created_at = nil
+}
+
+// SetName sets the "name" field.
+func (m *SettingAuditMutation) SetName(s string) {
+	m.name = &s
+}
+
+// Name returns the value of the "name" field in the mutation.
+func (m *SettingAuditMutation) Name() (r string, exists bool) {
+	v := m.name
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldName returns the old "name" field's value of the SettingAudit entity.
+// If the SettingAudit object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *SettingAuditMutation) OldName(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldName is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldName requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldName: %w", err)
+	}
+	return oldValue.Name, nil
+}
+
+// ResetName resets all changes to the "name" field.
+func (m *SettingAuditMutation) ResetName() {
+	m.name = nil
+}
+
+// SetOldValue sets the "old_value" field.
+func (m *SettingAuditMutation) SetOldValue(s string) {
+	m.old_value = &s
+}
+
+// OldValue returns the value of the "old_value" field in the mutation.
+func (m *SettingAuditMutation) OldValue() (r string, exists bool) {
+	v := m.old_value
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldOldValue returns the old "old_value" field's value of the SettingAudit entity.
+// If the SettingAudit object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *SettingAuditMutation) OldOldValue(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldOldValue is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldOldValue requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldOldValue: %w", err)
+	}
+	return oldValue.OldValue, nil
+}
+
+// ClearOldValue clears the value of the "old_value" field.
+func (m *SettingAuditMutation) ClearOldValue() {
+	m.old_value = nil
+	m.clearedFields[settingaudit.FieldOldValue] = struct{}{}
+}
+
+// OldValueCleared returns if the "old_value" field was cleared in this mutation.
+func (m *SettingAuditMutation) OldValueCleared() bool {
+	_, ok := m.clearedFields[settingaudit.FieldOldValue]
+	return ok
+}
+
+// ResetOldValue resets all changes to the "old_value" field.
+func (m *SettingAuditMutation) ResetOldValue() {
+	m.old_value = nil
+	delete(m.clearedFields, settingaudit.FieldOldValue)
+}
+
+// SetNewValue sets the "new_value" field.
+func (m *SettingAuditMutation) SetNewValue(s string) {
+	m.new_value = &s
+}
+
+// NewValue returns the value of the "new_value" field in the mutation.
+func (m *SettingAuditMutation) NewValue() (r string, exists bool) {
+	v := m.new_value
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldNewValue returns the old "new_value" field's value of the SettingAudit entity.
+// If the SettingAudit object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *SettingAuditMutation) OldNewValue(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldNewValue is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldNewValue requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldNewValue: %w", err)
+	}
+	return oldValue.NewValue, nil
+}
+
+// ClearNewValue clears the value of the "new_value" field.
+func (m *SettingAuditMutation) ClearNewValue() {
+	m.new_value = nil
+	m.clearedFields[settingaudit.FieldNewValue] = struct{}{}
+}
+
+// NewValueCleared returns if the "new_value" field was cleared in this mutation.
+func (m *SettingAuditMutation) NewValueCleared() bool {
+	_, ok := m.clearedFields[settingaudit.FieldNewValue]
+	return ok
+}
+
+// ResetNewValue resets all changes to the "new_value" field.
+func (m *SettingAuditMutation) ResetNewValue() {
+	m.new_value = nil
+	delete(m.clearedFields, settingaudit.FieldNewValue)
+}
+
+// SetOperatorID sets the "operator_id" field.
+func (m *SettingAuditMutation) SetOperatorID(i int) {
+	m.operator_id = &i
+	m.addoperator_id = nil
+}
+
+// OperatorID returns the value of the "operator_id" field in the mutation.
+func (m *SettingAuditMutation) OperatorID() (r int, exists bool) {
+	v := m.operator_id
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldOperatorID returns the old "operator_id" field's value of the SettingAudit entity.
+// If the SettingAudit object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *SettingAuditMutation) OldOperatorID(ctx context.Context) (v int, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldOperatorID is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldOperatorID requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldOperatorID: %w", err)
+	}
+	return oldValue.OperatorID, nil
+}
+
+// AddOperatorID adds i to the "operator_id" field.
+func (m *SettingAuditMutation) AddOperatorID(i int) {
+	if m.addoperator_id != nil {
+		*m.addoperator_id += i
+	} else {
+		m.addoperator_id = &i
+	}
+}
+
+// AddedOperatorID returns the value that was added to the "operator_id" field in this mutation.
+func (m *SettingAuditMutation) AddedOperatorID() (r int, exists bool) {
+	v := m.addoperator_id
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetOperatorID resets all changes to the "operator_id" field.
+func (m *SettingAuditMutation) ResetOperatorID() {
+	m.operator_id = nil
+	m.addoperator_id = nil
+}
+
+// Where appends a list predicates to the SettingAuditMutation builder.
+func (m *SettingAuditMutation) Where(ps ...predicate.SettingAudit) {
+	m.predicates = append(m.predicates, ps...)
+}
+
+// WhereP appends storage-level predicates to the SettingAuditMutation builder. Using this method,
+// users can use type-assertion to append predicates that do not depend on any generated package.
+func (m *SettingAuditMutation) WhereP(ps ...func(*sql.Selector)) {
+	p := make([]predicate.SettingAudit, len(ps))
+	for i := range ps {
+		p[i] = ps[i]
+	}
+	m.Where(p...)
+}
+
+// Op returns the operation name.
+func (m *SettingAuditMutation) Op() Op {
+	return m.op
+}
+
+// SetOp allows setting the mutation operation.
+func (m *SettingAuditMutation) SetOp(op Op) {
+	m.op = op
+}
+
+// Type returns the node type of this mutation (SettingAudit).
+func (m *SettingAuditMutation) Type() string {
+	return m.typ
+}
+
+// Fields returns all fields that were changed during this mutation. Note that in
+// order to get all numeric fields that were incremented/decremented, call
+// AddedFields().
+func (m *SettingAuditMutation) Fields() []string {
+	fields := make([]string, 0, 5)
+	if m.created_at != nil {
+		fields = append(fields, settingaudit.FieldCreatedAt)
+	}
+	if m.name != nil {
+		fields = append(fields, settingaudit.FieldName)
+	}
+	if m.old_value != nil {
+		fields = append(fields, settingaudit.FieldOldValue)
+	}
+	if m.new_value != nil {
+		fields = append(fields, settingaudit.FieldNewValue)
+	}
+	if m.operator_id != nil {
+		fields = append(fields, settingaudit.FieldOperatorID)
+	}
+	return fields
+}
+
+// Field returns the value of a field with the given name. The second boolean
+// return value indicates that this field was not set, or was not defined in the
+// schema.
+func (m *SettingAuditMutation) Field(name string) (ent.Value, bool) {
+	switch name {
+	case settingaudit.FieldCreatedAt:
+		return m.CreatedAt()
+	case settingaudit.FieldName:
+		return m.Name()
+	case settingaudit.FieldOldValue:
+		return m.OldValue()
+	case settingaudit.FieldNewValue:
+		return m.NewValue()
+	case settingaudit.FieldOperatorID:
+		return m.OperatorID()
+	}
+	return nil, false
+}
+
+// OldField returns the old value of the field from the database. An error is
+// returned if the mutation operation is not UpdateOne, or the query to the
+// database failed.
+func (m *SettingAuditMutation) OldField(ctx context.Context, name string) (ent.Value, error) {
+	switch name {
+	case settingaudit.FieldCreatedAt:
+		return m.OldCreatedAt(ctx)
+	case settingaudit.FieldName:
+		return m.OldName(ctx)
+	case settingaudit.FieldOldValue:
+		return m.OldOldValue(ctx)
+	case settingaudit.FieldNewValue:
+		return m.OldNewValue(ctx)
+	case settingaudit.FieldOperatorID:
+		return m.OldOperatorID(ctx)
+	}
+	return nil, fmt.Errorf("unknown SettingAudit field %s", name)
+}
+
+// SetField sets the value of a field with the given name. It returns an error if
+// the field is not defined in the schema, or if the type mismatched the field
+// type.
+func (m *SettingAuditMutation) SetField(name string, value ent.Value) error {
+	switch name {
+	case settingaudit.FieldCreatedAt:
+		v, ok := value.(time.Time)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetCreatedAt(v)
+		return nil
+	case settingaudit.FieldName:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetName(v)
+		return nil
+	case settingaudit.FieldOldValue:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetOldValue(v)
+		return nil
+	case settingaudit.FieldNewValue:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetNewValue(v)
+		return nil
+	case settingaudit.FieldOperatorID:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetOperatorID(v)
+		return nil
+	}
+	return fmt.Errorf("unknown SettingAudit field %s", name)
+}
+
+// AddedFields returns all numeric fields that were incremented/decremented during
+// this mutation.
+func (m *SettingAuditMutation) AddedFields() []string {
+	var fields []string
+	if m.addoperator_id != nil {
+		fields = append(fields, settingaudit.FieldOperatorID)
+	}
+	return fields
+}
+
+// AddedField returns the numeric value that was incremented/decremented on a field
+// with the given name. The second boolean return value indicates that this field
+// was not set, or was not defined in the schema.
+func (m *SettingAuditMutation) AddedField(name string) (ent.Value, bool) {
+	switch name {
+	case settingaudit.FieldOperatorID:
+		return m.AddedOperatorID()
+	}
+	return nil, false
+}
+
+// AddField adds the value to the field with the given name. It returns an error if
+// the field is not defined in the schema, or if the type mismatched the field
+// type.
+func (m *SettingAuditMutation) AddField(name string, value ent.Value) error {
+	switch name {
+	case settingaudit.FieldOperatorID:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddOperatorID(v)
+		return nil
+	}
+	return fmt.Errorf("unknown SettingAudit numeric field %s", name)
+}
+
+// ClearedFields returns all nullable fields that were cleared during this
+// mutation.
+func (m *SettingAuditMutation) ClearedFields() []string {
+	var fields []string
+	if m.FieldCleared(settingaudit.FieldOldValue) {
+		fields = append(fields, settingaudit.FieldOldValue)
+	}
+	if m.FieldCleared(settingaudit.FieldNewValue) {
+		fields = append(fields, settingaudit.FieldNewValue)
+	}
+	return fields
+}
+
+// FieldCleared returns a boolean indicating if a field with the given name was
+// cleared in this mutation.
+func (m *SettingAuditMutation) FieldCleared(name string) bool {
+	_, ok := m.clearedFields[name]
+	return ok
+}
+
+// ClearField clears the value of the field with the given name. It returns an
+// error if the field is not defined in the schema.
+func (m *SettingAuditMutation) ClearField(name string) error {
+	switch name {
+	case settingaudit.FieldOldValue:
+		m.ClearOldValue()
+		return nil
+	case settingaudit.FieldNewValue:
+		m.ClearNewValue()
+		return nil
+	}
+	return fmt.Errorf("unknown SettingAudit nullable field %s", name)
+}
+
+// ResetField resets all changes in the mutation for the field with the given name.
+// It returns an error if the field is not defined in the schema.
+func (m *SettingAuditMutation) ResetField(name string) error {
+	switch name {
+	case settingaudit.FieldCreatedAt:
+		m.ResetCreatedAt()
+		return nil
+	case settingaudit.FieldName:
+		m.ResetName()
+		return nil
+	case settingaudit.FieldOldValue:
+		m.ResetOldValue()
+		return nil
+	case settingaudit.FieldNewValue:
+		m.ResetNewValue()
+		return nil
+	case settingaudit.FieldOperatorID:
+		m.ResetOperatorID()
+		return nil
+	}
+	return fmt.Errorf("unknown SettingAudit field %s", name)
+}
+
+// AddedEdges returns all edge names that were set/added in this mutation.
+func (m *SettingAuditMutation) AddedEdges() []string {
+	edges := make([]string, 0, 0)
+	return edges
+}
+
+// AddedIDs returns all IDs (to other nodes) that were added for the given edge
+// name in this mutation.
+func (m *SettingAuditMutation) AddedIDs(name string) []ent.Value {
+	return nil
+}
+
+// RemovedEdges returns all edge names that were removed in this mutation.
+func (m *SettingAuditMutation) RemovedEdges() []string {
+	edges := make([]string, 0, 0)
+	return edges
+}
+
+// RemovedIDs returns all IDs (to other nodes) that were removed for the edge with
+// the given name in this mutation.
+func (m *SettingAuditMutation) RemovedIDs(name string) []ent.Value {
+	return nil
+}
+
+// ClearedEdges returns all edge names that were cleared in this mutation.
+func (m *SettingAuditMutation) ClearedEdges() []string {
+	edges := make([]string, 0, 0)
+	return edges
+}
+
+// EdgeCleared returns a boolean which indicates if the edge with the given name
+// was cleared in this mutation.
+func (m *SettingAuditMutation) EdgeCleared(name string) bool {
+	return false
+}
+
+// ClearEdge clears the value of the edge with the given name. It returns an error
+// if that edge is not defined in the schema.
+func (m *SettingAuditMutation) ClearEdge(name string) error {
+	return fmt.Errorf("unknown SettingAudit unique edge %s", name)
+}
+
+// ResetEdge resets all changes to the edge with the given name in this mutation.
+// It returns an error if the edge is not defined in the schema.
+func (m *SettingAuditMutation) ResetEdge(name string) error {
+	return fmt.Errorf("unknown SettingAudit edge %s", name)
+}
+
 // ShareMutation represents an operation that mutates the Share nodes in the graph.
 type ShareMutation struct {
 	config
@@ -58,6 +58,12 @@ func (m *SettingMutation) SetRawID(t int) {
 
 // SetUpdatedAt sets the "updated_at" field.
 
+func (m *SettingAuditMutation) SetRawID(t int) {
+	m.id = &t
+}
+
+// SetUpdatedAt sets the "updated_at" field.
+
 func (m *ShareMutation) SetRawID(t int) {
 	m.id = &t
 }
@@ -33,6 +33,9 @@ type Passkey func(*sql.Selector)
 // Setting is the predicate function for setting builders.
 type Setting func(*sql.Selector)
 
+// SettingAudit is the predicate function for settingaudit builders.
+type SettingAudit func(*sql.Selector)
+
 // Share is the predicate function for share builders.
 type Share func(*sql.Selector)
 
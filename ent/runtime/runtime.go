@@ -15,6 +15,7 @@ import (
 	"github.com/cloudreve/Cloudreve/v4/ent/passkey"
 	"github.com/cloudreve/Cloudreve/v4/ent/schema"
 	"github.com/cloudreve/Cloudreve/v4/ent/setting"
+	"github.com/cloudreve/Cloudreve/v4/ent/settingaudit"
 	"github.com/cloudreve/Cloudreve/v4/ent/share"
 	"github.com/cloudreve/Cloudreve/v4/ent/storagepolicy"
 	"github.com/cloudreve/Cloudreve/v4/ent/task"
@@ -89,6 +90,8 @@ func init() {
 	entity.DefaultReferenceCount = entityDescReferenceCount.Default.(int)
 	fileHooks := schema.File{}.Hooks()
 	file.Hooks[0] = fileHooks[0]
+	fileInters := schema.File{}.Interceptors()
+	file.Interceptors[0] = fileInters[0]
 	fileFields := schema.File{}.Fields()
 	_ = fileFields
 	// fileDescCreatedAt is the schema descriptor for created_at field.
@@ -218,6 +221,16 @@ func init() {
 	setting.DefaultUpdatedAt = settingDescUpdatedAt.Default.(func() time.Time)
 	// setting.UpdateDefaultUpdatedAt holds the default value on update for the updated_at field.
 	setting.UpdateDefaultUpdatedAt = settingDescUpdatedAt.UpdateDefault.(func() time.Time)
+	settingauditFields := schema.SettingAudit{}.Fields()
+	_ = settingauditFields
+	// settingauditDescCreatedAt is the schema descriptor for created_at field.
+	settingauditDescCreatedAt := settingauditFields[0].Descriptor()
+	// settingaudit.DefaultCreatedAt holds the default value on creation for the created_at field.
+	settingaudit.DefaultCreatedAt = settingauditDescCreatedAt.Default.(func() time.Time)
+	// settingauditDescOperatorID is the schema descriptor for operator_id field.
+	settingauditDescOperatorID := settingauditFields[4].Descriptor()
+	// settingaudit.DefaultOperatorID holds the default value on creation for the operator_id field.
+	settingaudit.DefaultOperatorID = settingauditDescOperatorID.Default.(int)
 	shareMixin := schema.Share{}.Mixin()
 	shareMixinHooks0 := shareMixin[0].Hooks()
 	share.Hooks[0] = shareMixinHooks0[0]
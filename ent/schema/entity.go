@@ -27,6 +27,9 @@ func (Entity) Fields() []ent.Field {
 			Nillable(),
 		field.JSON("recycle_options", &types.EntityRecycleOption{}).
 			Optional(),
+		// Content checksums keyed by hash algorithm, e.g. `sha256`.
+		field.JSON("checksums", map[string]string{}).
+			Optional(),
 	}
 }
 
@@ -6,10 +6,12 @@ import (
 
 	"entgo.io/ent"
 	"entgo.io/ent/dialect"
+	"entgo.io/ent/dialect/sql"
 	"entgo.io/ent/schema/edge"
 	"entgo.io/ent/schema/field"
 	"entgo.io/ent/schema/index"
 	"github.com/cloudreve/Cloudreve/v4/ent/hook"
+	"github.com/cloudreve/Cloudreve/v4/ent/intercept"
 	"github.com/cloudreve/Cloudreve/v4/inventory/types"
 )
 
@@ -46,6 +48,12 @@ func (File) Fields() []ent.Field {
 		field.JSON("props", &types.FileProps{}).Optional(),
 		field.Int("storage_policy_files").
 			Optional(),
+		field.Time("deleted_at").
+			Optional().
+			Nillable().
+			SchemaType(map[string]string{
+				dialect.MySQL: "datetime",
+			}),
 	}
 }
 
@@ -79,6 +87,31 @@ func (File) Indexes() []ent.Index {
 			Unique(),
 		index.Fields("file_children", "type", "updated_at"),
 		index.Fields("file_children", "type", "size"),
+		index.Fields("deleted_at"),
+	}
+}
+
+type includeDeletedFilesKey struct{}
+
+// IncludeDeletedFiles returns a new context in which queries also return soft-deleted files,
+// e.g. files in trash bin. Unlike SkipSoftDelete, deletions of other models are still soft.
+func IncludeDeletedFiles(parent context.Context) context.Context {
+	return context.WithValue(parent, includeDeletedFilesKey{}, true)
+}
+
+// Interceptors of the File. Soft-deleted files are excluded from queries by default.
+func (f File) Interceptors() []ent.Interceptor {
+	return []ent.Interceptor{
+		intercept.TraverseFunc(func(ctx context.Context, q intercept.Query) error {
+			if include, _ := ctx.Value(includeDeletedFilesKey{}).(bool); include {
+				return nil
+			}
+			if skip, _ := ctx.Value(softDeleteKey{}).(bool); skip {
+				return nil
+			}
+			q.WhereP(sql.FieldIsNull("deleted_at"))
+			return nil
+		}),
 	}
 }
 
@@ -38,6 +38,8 @@ func (Metadata) Indexes() []ent.Index {
 	return []ent.Index{
 		index.Fields("file_id", "name").
 			Unique(),
+		// Search files by metadata/custom props name.
+		index.Fields("name"),
 	}
 }
 
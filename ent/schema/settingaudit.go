@@ -0,0 +1,45 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/dialect"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+)
+
+// SettingAudit holds the schema definition for audit log of setting changes.
+type SettingAudit struct {
+	ent.Schema
+}
+
+func (SettingAudit) Fields() []ent.Field {
+	return []ent.Field{
+		field.Time("created_at").
+			Immutable().
+			Default(time.Now).
+			SchemaType(map[string]string{
+				dialect.MySQL: "datetime",
+			}),
+		field.String("name"),
+		field.Text("old_value").
+			Optional(),
+		field.Text("new_value").
+			Optional(),
+		// ID of the user who made the change, 0 for system.
+		field.Int("operator_id").
+			Default(0),
+	}
+}
+
+func (SettingAudit) Edges() []ent.Edge {
+	return nil
+}
+
+func (SettingAudit) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("created_at"),
+		index.Fields("name"),
+	}
+}
@@ -0,0 +1,150 @@
+// Code generated by ent, DO NOT EDIT.
+
+package ent
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/dialect/sql"
+	"github.com/cloudreve/Cloudreve/v4/ent/settingaudit"
+)
+
+// SettingAudit is the model entity for the SettingAudit schema.
+type SettingAudit struct {
+	config `json:"-"`
+	// ID of the ent.
+	ID int `json:"id,omitempty"`
+	// CreatedAt holds the value of the "created_at" field.
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	// Name holds the value of the "name" field.
+	Name string `json:"name,omitempty"`
+	// OldValue holds the value of the "old_value" field.
+	OldValue string `json:"old_value,omitempty"`
+	// NewValue holds the value of the "new_value" field.
+	NewValue string `json:"new_value,omitempty"`
+	// OperatorID holds the value of the "operator_id" field.
+	OperatorID   int `json:"operator_id,omitempty"`
+	selectValues sql.SelectValues
+}
+
+// scanValues returns the types for scanning values from sql.Rows.
+func (*SettingAudit) scanValues(columns []string) ([]any, error) {
+	values := make([]any, len(columns))
+	for i := range columns {
+		switch columns[i] {
+		case settingaudit.FieldID, settingaudit.FieldOperatorID:
+			values[i] = new(sql.NullInt64)
+		case settingaudit.FieldName, settingaudit.FieldOldValue, settingaudit.FieldNewValue:
+			values[i] = new(sql.NullString)
+		case settingaudit.FieldCreatedAt:
+			values[i] = new(sql.NullTime)
+		default:
+			values[i] = new(sql.UnknownType)
+		}
+	}
+	return values, nil
+}
+
+// assignValues assigns the values that were returned from sql.Rows (after scanning)
+// to the SettingAudit fields.
+func (sa *SettingAudit) assignValues(columns []string, values []any) error {
+	if m, n := len(values), len(columns); m < n {
+		return fmt.Errorf("mismatch number of scan values: %d != %d", m, n)
+	}
+	for i := range columns {
+		switch columns[i] {
+		case settingaudit.FieldID:
+			value, ok := values[i].(*sql.NullInt64)
+			if !ok {
+				return fmt.Errorf("unexpected type %T for field id", value)
+			}
+			sa.ID = int(value.Int64)
+		case settingaudit.FieldCreatedAt:
+			if value, ok := values[i].(*sql.NullTime); !ok {
+				return fmt.Errorf("unexpected type %T for field created_at", values[i])
+			} else if value.Valid {
+				sa.CreatedAt = value.Time
+			}
+		case settingaudit.FieldName:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field name", values[i])
+			} else if value.Valid {
+				sa.Name = value.String
+			}
+		case settingaudit.FieldOldValue:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field old_value", values[i])
+			} else if value.Valid {
+				sa.OldValue = value.String
+			}
+		case settingaudit.FieldNewValue:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field new_value", values[i])
+			} else if value.Valid {
+				sa.NewValue = value.String
+			}
+		case settingaudit.FieldOperatorID:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field operator_id", values[i])
+			} else if value.Valid {
+				sa.OperatorID = int(value.Int64)
+			}
+		default:
+			sa.selectValues.Set(columns[i], values[i])
+		}
+	}
+	return nil
+}
+
+// Value returns the ent.Value that was dynamically selected and assigned to the SettingAudit.
+// This includes values selected through modifiers, order, etc.
+func (sa *SettingAudit) Value(name string) (ent.Value, error) {
+	return sa.selectValues.Get(name)
+}
+
+// Update returns a builder for updating this SettingAudit.
+// Note that you need to call SettingAudit.Unwrap() before calling this method if this SettingAudit
+// was returned from a transaction, and the transaction was committed or rolled back.
+func (sa *SettingAudit) Update() *SettingAuditUpdateOne {
+	return NewSettingAuditClient(sa.config).UpdateOne(sa)
+}
+
+// Unwrap unwraps the SettingAudit entity that was returned from a transaction after it was closed,
+// so that all future queries will be executed through the driver which created the transaction.
+func (sa *SettingAudit) Unwrap() *SettingAudit {
+	_tx, ok := sa.config.driver.(*txDriver)
+	if !ok {
+		panic("ent: SettingAudit is not a transactional entity")
+	}
+	sa.config.driver = _tx.drv
+	return sa
+}
+
+// String implements the fmt.Stringer.
+func (sa *SettingAudit) String() string {
+	var builder strings.Builder
+	builder.WriteString("SettingAudit(")
+	builder.WriteString(fmt.Sprintf("id=%v, ", sa.ID))
+	builder.WriteString("created_at=")
+	builder.WriteString(sa.CreatedAt.Format(time.ANSIC))
+	builder.WriteString(", ")
+	builder.WriteString("name=")
+	builder.WriteString(sa.Name)
+	builder.WriteString(", ")
+	builder.WriteString("old_value=")
+	builder.WriteString(sa.OldValue)
+	builder.WriteString(", ")
+	builder.WriteString("new_value=")
+	builder.WriteString(sa.NewValue)
+	builder.WriteString(", ")
+	builder.WriteString("operator_id=")
+	builder.WriteString(fmt.Sprintf("%v", sa.OperatorID))
+	builder.WriteByte(')')
+	return builder.String()
+}
+
+// SettingAudits is a parsable slice of SettingAudit.
+type SettingAudits []*SettingAudit
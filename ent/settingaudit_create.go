@@ -0,0 +1,751 @@
+// Code generated by ent, DO NOT EDIT.
+
+package ent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"entgo.io/ent/dialect/sql"
+	"entgo.io/ent/dialect/sql/sqlgraph"
+	"entgo.io/ent/schema/field"
+	"github.com/cloudreve/Cloudreve/v4/ent/settingaudit"
+)
+
+// SettingAuditCreate is the builder for creating a SettingAudit entity.
+type SettingAuditCreate struct {
+	config
+	mutation *SettingAuditMutation
+	hooks    []Hook
+	conflict []sql.ConflictOption
+}
+
+// SetCreatedAt sets the "created_at" field.
+func (sac *SettingAuditCreate) SetCreatedAt(t time.Time) *SettingAuditCreate {
+	sac.mutation.SetCreatedAt(t)
+	return sac
+}
+
+// SetNillableCreatedAt sets the "created_at" field if the given value is not nil.
+func (sac *SettingAuditCreate) SetNillableCreatedAt(t *time.Time) *SettingAuditCreate {
+	if t != nil {
+		sac.SetCreatedAt(*t)
+	}
+	return sac
+}
+
+// SetName sets the "name" field.
+func (sac *SettingAuditCreate) SetName(s string) *SettingAuditCreate {
+	sac.mutation.SetName(s)
+	return sac
+}
+
+// SetOldValue sets the "old_value" field.
+func (sac *SettingAuditCreate) SetOldValue(s string) *SettingAuditCreate {
+	sac.mutation.SetOldValue(s)
+	return sac
+}
+
+// SetNillableOldValue sets the "old_value" field if the given value is not nil.
+func (sac *SettingAuditCreate) SetNillableOldValue(s *string) *SettingAuditCreate {
+	if s != nil {
+		sac.SetOldValue(*s)
+	}
+	return sac
+}
+
+// SetNewValue sets the "new_value" field.
+func (sac *SettingAuditCreate) SetNewValue(s string) *SettingAuditCreate {
+	sac.mutation.SetNewValue(s)
+	return sac
+}
+
+// SetNillableNewValue sets the "new_value" field if the given value is not nil.
+func (sac *SettingAuditCreate) SetNillableNewValue(s *string) *SettingAuditCreate {
+	if s != nil {
+		sac.SetNewValue(*s)
+	}
+	return sac
+}
+
+// SetOperatorID sets the "operator_id" field.
+func (sac *SettingAuditCreate) SetOperatorID(i int) *SettingAuditCreate {
+	sac.mutation.SetOperatorID(i)
+	return sac
+}
+
+// SetNillableOperatorID sets the "operator_id" field if the given value is not nil.
+func (sac *SettingAuditCreate) SetNillableOperatorID(i *int) *SettingAuditCreate {
+	if i != nil {
+		sac.SetOperatorID(*i)
+	}
+	return sac
+}
+
+// Mutation returns the SettingAuditMutation object of the builder.
+func (sac *SettingAuditCreate) Mutation() *SettingAuditMutation {
+	return sac.mutation
+}
+
+// Save creates the SettingAudit in the database.
+func (sac *SettingAuditCreate) Save(ctx context.Context) (*SettingAudit, error) {
+	sac.defaults()
+	return withHooks(ctx, sac.sqlSave, sac.mutation, sac.hooks)
+}
+
+// SaveX calls Save and panics if Save returns an error.
+func (sac *SettingAuditCreate) SaveX(ctx context.Context) *SettingAudit {
+	v, err := sac.Save(ctx)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// Exec executes the query.
+func (sac *SettingAuditCreate) Exec(ctx context.Context) error {
+	_, err := sac.Save(ctx)
+	return err
+}
+
+// ExecX is like Exec, but panics if an error occurs.
+func (sac *SettingAuditCreate) ExecX(ctx context.Context) {
+	if err := sac.Exec(ctx); err != nil {
+		panic(err)
+	}
+}
+
+// defaults sets the default values of the builder before save.
+func (sac *SettingAuditCreate) defaults() {
+	if _, ok := sac.mutation.CreatedAt(); !ok {
+		v := settingaudit.DefaultCreatedAt()
+		sac.mutation.SetCreatedAt(v)
+	}
+	if _, ok := sac.mutation.OperatorID(); !ok {
+		v := settingaudit.DefaultOperatorID
+		sac.mutation.SetOperatorID(v)
+	}
+}
+
+// check runs all checks and user-defined validators on the builder.
+func (sac *SettingAuditCreate) check() error {
+	if _, ok := sac.mutation.CreatedAt(); !ok {
+		return &ValidationError{Name: "created_at", err: errors.New(`ent: missing required field "SettingAudit.created_at"`)}
+	}
+	if _, ok := sac.mutation.Name(); !ok {
+		return &ValidationError{Name: "name", err: errors.New(`ent: missing required field "SettingAudit.name"`)}
+	}
+	if _, ok := sac.mutation.OperatorID(); !ok {
+		return &ValidationError{Name: "operator_id", err: errors.New(`ent: missing required field "SettingAudit.operator_id"`)}
+	}
+	return nil
+}
+
+func (sac *SettingAuditCreate) sqlSave(ctx context.Context) (*SettingAudit, error) {
+	if err := sac.check(); err != nil {
+		return nil, err
+	}
+	_node, _spec := sac.createSpec()
+	if err := sqlgraph.CreateNode(ctx, sac.driver, _spec); err != nil {
+		if sqlgraph.IsConstraintError(err) {
+			err = &ConstraintError{msg: err.Error(), wrap: err}
+		}
+		return nil, err
+	}
+	id := _spec.ID.Value.(int64)
+	_node.ID = int(id)
+	sac.mutation.id = &_node.ID
+	sac.mutation.done = true
+	return _node, nil
+}
+
+func (sac *SettingAuditCreate) createSpec() (*SettingAudit, *sqlgraph.CreateSpec) {
+	var (
+		_node = &SettingAudit{config: sac.config}
+		_spec = sqlgraph.NewCreateSpec(settingaudit.Table, sqlgraph.NewFieldSpec(settingaudit.FieldID, field.TypeInt))
+	)
+
+	if id, ok := sac.mutation.ID(); ok {
+		_node.ID = id
+		id64 := int64(id)
+		_spec.ID.Value = id64
+	}
+
+	_spec.OnConflict = sac.conflict
+	if value, ok := sac.mutation.CreatedAt(); ok {
+		_spec.SetField(settingaudit.FieldCreatedAt, field.TypeTime, value)
+		_node.CreatedAt = value
+	}
+	if value, ok := sac.mutation.Name(); ok {
+		_spec.SetField(settingaudit.FieldName, field.TypeString, value)
+		_node.Name = value
+	}
+	if value, ok := sac.mutation.OldValue(); ok {
+		_spec.SetField(settingaudit.FieldOldValue, field.TypeString, value)
+		_node.OldValue = value
+	}
+	if value, ok := sac.mutation.NewValue(); ok {
+		_spec.SetField(settingaudit.FieldNewValue, field.TypeString, value)
+		_node.NewValue = value
+	}
+	if value, ok := sac.mutation.OperatorID(); ok {
+		_spec.SetField(settingaudit.FieldOperatorID, field.TypeInt, value)
+		_node.OperatorID = value
+	}
+	return _node, _spec
+}
+
+// OnConflict allows configuring the `ON CONFLICT` / `ON DUPLICATE KEY` clause
+// of the `INSERT` statement. For example:
+//
+//	client.SettingAudit.Create().
+//		SetCreatedAt(v).
+//		OnConflict(
+//			// Update the row with the new values
+//			// the was proposed for insertion.
+//			sql.ResolveWithNewValues(),
+//		).
+//		// Override some of the fields with custom
+//		// update values.
+//		Update(func(u *ent.SettingAuditUpsert) {
+//			SetCreatedAt(v+v).
+//		}).
+//		Exec(ctx)
+func (sac *SettingAuditCreate) OnConflict(opts ...sql.ConflictOption) *SettingAuditUpsertOne {
+	sac.conflict = opts
+	return &SettingAuditUpsertOne{
+		create: sac,
+	}
+}
+
+// OnConflictColumns calls `OnConflict` and configures the columns
+// as conflict target. Using this option is equivalent to using:
+//
+//	client.SettingAudit.Create().
+//		OnConflict(sql.ConflictColumns(columns...)).
+//		Exec(ctx)
+func (sac *SettingAuditCreate) OnConflictColumns(columns ...string) *SettingAuditUpsertOne {
+	sac.conflict = append(sac.conflict, sql.ConflictColumns(columns...))
+	return &SettingAuditUpsertOne{
+		create: sac,
+	}
+}
+
+type (
+	// SettingAuditUpsertOne is the builder for "upsert"-ing
+	//  one SettingAudit node.
+	SettingAuditUpsertOne struct {
+		create *SettingAuditCreate
+	}
+
+	// SettingAuditUpsert is the "OnConflict" setter.
+	SettingAuditUpsert struct {
+		*sql.UpdateSet
+	}
+)
+
+// SetName sets the "name" field.
+func (u *SettingAuditUpsert) SetName(v string) *SettingAuditUpsert {
+	u.Set(settingaudit.FieldName, v)
+	return u
+}
+
+// UpdateName sets the "name" field to the value that was provided on create.
+func (u *SettingAuditUpsert) UpdateName() *SettingAuditUpsert {
+	u.SetExcluded(settingaudit.FieldName)
+	return u
+}
+
+// SetOldValue sets the "old_value" field.
+func (u *SettingAuditUpsert) SetOldValue(v string) *SettingAuditUpsert {
+	u.Set(settingaudit.FieldOldValue, v)
+	return u
+}
+
+// UpdateOldValue sets the "old_value" field to the value that was provided on create.
+func (u *SettingAuditUpsert) UpdateOldValue() *SettingAuditUpsert {
+	u.SetExcluded(settingaudit.FieldOldValue)
+	return u
+}
+
+// ClearOldValue clears the value of the "old_value" field.
+func (u *SettingAuditUpsert) ClearOldValue() *SettingAuditUpsert {
+	u.SetNull(settingaudit.FieldOldValue)
+	return u
+}
+
+// SetNewValue sets the "new_value" field.
+func (u *SettingAuditUpsert) SetNewValue(v string) *SettingAuditUpsert {
+	u.Set(settingaudit.FieldNewValue, v)
+	return u
+}
+
+// UpdateNewValue sets the "new_value" field to the value that was provided on create.
+func (u *SettingAuditUpsert) UpdateNewValue() *SettingAuditUpsert {
+	u.SetExcluded(settingaudit.FieldNewValue)
+	return u
+}
+
+// ClearNewValue clears the value of the "new_value" field.
+func (u *SettingAuditUpsert) ClearNewValue() *SettingAuditUpsert {
+	u.SetNull(settingaudit.FieldNewValue)
+	return u
+}
+
+// SetOperatorID sets the "operator_id" field.
+func (u *SettingAuditUpsert) SetOperatorID(v int) *SettingAuditUpsert {
+	u.Set(settingaudit.FieldOperatorID, v)
+	return u
+}
+
+// UpdateOperatorID sets the "operator_id" field to the value that was provided on create.
+func (u *SettingAuditUpsert) UpdateOperatorID() *SettingAuditUpsert {
+	u.SetExcluded(settingaudit.FieldOperatorID)
+	return u
+}
+
+// AddOperatorID adds v to the "operator_id" field.
+func (u *SettingAuditUpsert) AddOperatorID(v int) *SettingAuditUpsert {
+	u.Add(settingaudit.FieldOperatorID, v)
+	return u
+}
+
+// UpdateNewValues updates the mutable fields using the new values that were set on create.
+// Using this option is equivalent to using:
+//
+//	client.SettingAudit.Create().
+//		OnConflict(
+//			sql.ResolveWithNewValues(),
+//		).
+//		Exec(ctx)
+func (u *SettingAuditUpsertOne) UpdateNewValues() *SettingAuditUpsertOne {
+	u.create.conflict = append(u.create.conflict, sql.ResolveWithNewValues())
+	u.create.conflict = append(u.create.conflict, sql.ResolveWith(func(s *sql.UpdateSet) {
+		if _, exists := u.create.mutation.CreatedAt(); exists {
+			s.SetIgnore(settingaudit.FieldCreatedAt)
+		}
+	}))
+	return u
+}
+
+// Ignore sets each column to itself in case of conflict.
+// Using this option is equivalent to using:
+//
+//	client.SettingAudit.Create().
+//	    OnConflict(sql.ResolveWithIgnore()).
+//	    Exec(ctx)
+func (u *SettingAuditUpsertOne) Ignore() *SettingAuditUpsertOne {
+	u.create.conflict = append(u.create.conflict, sql.ResolveWithIgnore())
+	return u
+}
+
+// DoNothing configures the conflict_action to `DO NOTHING`.
+// Supported only by SQLite and PostgreSQL.
+func (u *SettingAuditUpsertOne) DoNothing() *SettingAuditUpsertOne {
+	u.create.conflict = append(u.create.conflict, sql.DoNothing())
+	return u
+}
+
+// Update allows overriding fields `UPDATE` values. See the SettingAuditCreate.OnConflict
+// documentation for more info.
+func (u *SettingAuditUpsertOne) Update(set func(*SettingAuditUpsert)) *SettingAuditUpsertOne {
+	u.create.conflict = append(u.create.conflict, sql.ResolveWith(func(update *sql.UpdateSet) {
+		set(&SettingAuditUpsert{UpdateSet: update})
+	}))
+	return u
+}
+
+// SetName sets the "name" field.
+func (u *SettingAuditUpsertOne) SetName(v string) *SettingAuditUpsertOne {
+	return u.Update(func(s *SettingAuditUpsert) {
+		s.SetName(v)
+	})
+}
+
+// UpdateName sets the "name" field to the value that was provided on create.
+func (u *SettingAuditUpsertOne) UpdateName() *SettingAuditUpsertOne {
+	return u.Update(func(s *SettingAuditUpsert) {
+		s.UpdateName()
+	})
+}
+
+// SetOldValue sets the "old_value" field.
+func (u *SettingAuditUpsertOne) SetOldValue(v string) *SettingAuditUpsertOne {
+	return u.Update(func(s *SettingAuditUpsert) {
+		s.SetOldValue(v)
+	})
+}
+
+// UpdateOldValue sets the "old_value" field to the value that was provided on create.
+func (u *SettingAuditUpsertOne) UpdateOldValue() *SettingAuditUpsertOne {
+	return u.Update(func(s *SettingAuditUpsert) {
+		s.UpdateOldValue()
+	})
+}
+
+// ClearOldValue clears the value of the "old_value" field.
+func (u *SettingAuditUpsertOne) ClearOldValue() *SettingAuditUpsertOne {
+	return u.Update(func(s *SettingAuditUpsert) {
+		s.ClearOldValue()
+	})
+}
+
+// SetNewValue sets the "new_value" field.
+func (u *SettingAuditUpsertOne) SetNewValue(v string) *SettingAuditUpsertOne {
+	return u.Update(func(s *SettingAuditUpsert) {
+		s.SetNewValue(v)
+	})
+}
+
+// UpdateNewValue sets the "new_value" field to the value that was provided on create.
+func (u *SettingAuditUpsertOne) UpdateNewValue() *SettingAuditUpsertOne {
+	return u.Update(func(s *SettingAuditUpsert) {
+		s.UpdateNewValue()
+	})
+}
+
+// ClearNewValue clears the value of the "new_value" field.
+func (u *SettingAuditUpsertOne) ClearNewValue() *SettingAuditUpsertOne {
+	return u.Update(func(s *SettingAuditUpsert) {
+		s.ClearNewValue()
+	})
+}
+
+// SetOperatorID sets the "operator_id" field.
+func (u *SettingAuditUpsertOne) SetOperatorID(v int) *SettingAuditUpsertOne {
+	return u.Update(func(s *SettingAuditUpsert) {
+		s.SetOperatorID(v)
+	})
+}
+
+// AddOperatorID adds v to the "operator_id" field.
+func (u *SettingAuditUpsertOne) AddOperatorID(v int) *SettingAuditUpsertOne {
+	return u.Update(func(s *SettingAuditUpsert) {
+		s.AddOperatorID(v)
+	})
+}
+
+// UpdateOperatorID sets the "operator_id" field to the value that was provided on create.
+func (u *SettingAuditUpsertOne) UpdateOperatorID() *SettingAuditUpsertOne {
+	return u.Update(func(s *SettingAuditUpsert) {
+		s.UpdateOperatorID()
+	})
+}
+
+// Exec executes the query.
+func (u *SettingAuditUpsertOne) Exec(ctx context.Context) error {
+	if len(u.create.conflict) == 0 {
+		return errors.New("ent: missing options for SettingAuditCreate.OnConflict")
+	}
+	return u.create.Exec(ctx)
+}
+
+// ExecX is like Exec, but panics if an error occurs.
+func (u *SettingAuditUpsertOne) ExecX(ctx context.Context) {
+	if err := u.create.Exec(ctx); err != nil {
+		panic(err)
+	}
+}
+
+// Exec executes the UPSERT query and returns the inserted/updated ID.
+func (u *SettingAuditUpsertOne) ID(ctx context.Context) (id int, err error) {
+	node, err := u.create.Save(ctx)
+	if err != nil {
+		return id, err
+	}
+	return node.ID, nil
+}
+
+// IDX is like ID, but panics if an error occurs.
+func (u *SettingAuditUpsertOne) IDX(ctx context.Context) int {
+	id, err := u.ID(ctx)
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+func (m *SettingAuditCreate) SetRawID(t int) *SettingAuditCreate {
+	m.mutation.SetRawID(t)
+	return m
+}
+
+// SettingAuditCreateBulk is the builder for creating many SettingAudit entities in bulk.
+type SettingAuditCreateBulk struct {
+	config
+	err      error
+	builders []*SettingAuditCreate
+	conflict []sql.ConflictOption
+}
+
+// Save creates the SettingAudit entities in the database.
+func (sacb *SettingAuditCreateBulk) Save(ctx context.Context) ([]*SettingAudit, error) {
+	if sacb.err != nil {
+		return nil, sacb.err
+	}
+	specs := make([]*sqlgraph.CreateSpec, len(sacb.builders))
+	nodes := make([]*SettingAudit, len(sacb.builders))
+	mutators := make([]Mutator, len(sacb.builders))
+	for i := range sacb.builders {
+		func(i int, root context.Context) {
+			builder := sacb.builders[i]
+			builder.defaults()
+			var mut Mutator = MutateFunc(func(ctx context.Context, m Mutation) (Value, error) {
+				mutation, ok := m.(*SettingAuditMutation)
+				if !ok {
+					return nil, fmt.Errorf("unexpected mutation type %T", m)
+				}
+				if err := builder.check(); err != nil {
+					return nil, err
+				}
+				builder.mutation = mutation
+				var err error
+				nodes[i], specs[i] = builder.createSpec()
+				if i < len(mutators)-1 {
+					_, err = mutators[i+1].Mutate(root, sacb.builders[i+1].mutation)
+				} else {
+					spec := &sqlgraph.BatchCreateSpec{Nodes: specs}
+					spec.OnConflict = sacb.conflict
+					// Invoke the actual operation on the latest mutation in the chain.
+					if err = sqlgraph.BatchCreate(ctx, sacb.driver, spec); err != nil {
+						if sqlgraph.IsConstraintError(err) {
+							err = &ConstraintError{msg: err.Error(), wrap: err}
+						}
+					}
+				}
+				if err != nil {
+					return nil, err
+				}
+				mutation.id = &nodes[i].ID
+				if specs[i].ID.Value != nil {
+					id := specs[i].ID.Value.(int64)
+					nodes[i].ID = int(id)
+				}
+				mutation.done = true
+				return nodes[i], nil
+			})
+			for i := len(builder.hooks) - 1; i >= 0; i-- {
+				mut = builder.hooks[i](mut)
+			}
+			mutators[i] = mut
+		}(i, ctx)
+	}
+	if len(mutators) > 0 {
+		if _, err := mutators[0].Mutate(ctx, sacb.builders[0].mutation); err != nil {
+			return nil, err
+		}
+	}
+	return nodes, nil
+}
+
+// SaveX is like Save, but panics if an error occurs.
+func (sacb *SettingAuditCreateBulk) SaveX(ctx context.Context) []*SettingAudit {
+	v, err := sacb.Save(ctx)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// Exec executes the query.
+func (sacb *SettingAuditCreateBulk) Exec(ctx context.Context) error {
+	_, err := sacb.Save(ctx)
+	return err
+}
+
+// ExecX is like Exec, but panics if an error occurs.
+func (sacb *SettingAuditCreateBulk) ExecX(ctx context.Context) {
+	if err := sacb.Exec(ctx); err != nil {
+		panic(err)
+	}
+}
+
+// OnConflict allows configuring the `ON CONFLICT` / `ON DUPLICATE KEY` clause
+// of the `INSERT` statement. For example:
+//
+//	client.SettingAudit.CreateBulk(builders...).
+//		OnConflict(
+//			// Update the row with the new values
+//			// the was proposed for insertion.
+//			sql.ResolveWithNewValues(),
+//		).
+//		// Override some of the fields with custom
+//		// update values.
+//		Update(func(u *ent.SettingAuditUpsert) {
+//			SetCreatedAt(v+v).
+//		}).
+//		Exec(ctx)
+func (sacb *SettingAuditCreateBulk) OnConflict(opts ...sql.ConflictOption) *SettingAuditUpsertBulk {
+	sacb.conflict = opts
+	return &SettingAuditUpsertBulk{
+		create: sacb,
+	}
+}
+
+// OnConflictColumns calls `OnConflict` and configures the columns
+// as conflict target. Using this option is equivalent to using:
+//
+//	client.SettingAudit.Create().
+//		OnConflict(sql.ConflictColumns(columns...)).
+//		Exec(ctx)
+func (sacb *SettingAuditCreateBulk) OnConflictColumns(columns ...string) *SettingAuditUpsertBulk {
+	sacb.conflict = append(sacb.conflict, sql.ConflictColumns(columns...))
+	return &SettingAuditUpsertBulk{
+		create: sacb,
+	}
+}
+
+// SettingAuditUpsertBulk is the builder for "upsert"-ing
+// a bulk of SettingAudit nodes.
+type SettingAuditUpsertBulk struct {
+	create *SettingAuditCreateBulk
+}
+
+// UpdateNewValues updates the mutable fields using the new values that
+// were set on create. Using this option is equivalent to using:
+//
+//	client.SettingAudit.Create().
+//		OnConflict(
+//			sql.ResolveWithNewValues(),
+//		).
+//		Exec(ctx)
+func (u *SettingAuditUpsertBulk) UpdateNewValues() *SettingAuditUpsertBulk {
+	u.create.conflict = append(u.create.conflict, sql.ResolveWithNewValues())
+	u.create.conflict = append(u.create.conflict, sql.ResolveWith(func(s *sql.UpdateSet) {
+		for _, b := range u.create.builders {
+			if _, exists := b.mutation.CreatedAt(); exists {
+				s.SetIgnore(settingaudit.FieldCreatedAt)
+			}
+		}
+	}))
+	return u
+}
+
+// Ignore sets each column to itself in case of conflict.
+// Using this option is equivalent to using:
+//
+//	client.SettingAudit.Create().
+//		OnConflict(sql.ResolveWithIgnore()).
+//		Exec(ctx)
+func (u *SettingAuditUpsertBulk) Ignore() *SettingAuditUpsertBulk {
+	u.create.conflict = append(u.create.conflict, sql.ResolveWithIgnore())
+	return u
+}
+
+// DoNothing configures the conflict_action to `DO NOTHING`.
+// Supported only by SQLite and PostgreSQL.
+func (u *SettingAuditUpsertBulk) DoNothing() *SettingAuditUpsertBulk {
+	u.create.conflict = append(u.create.conflict, sql.DoNothing())
+	return u
+}
+
+// Update allows overriding fields `UPDATE` values. See the SettingAuditCreateBulk.OnConflict
+// documentation for more info.
+func (u *SettingAuditUpsertBulk) Update(set func(*SettingAuditUpsert)) *SettingAuditUpsertBulk {
+	u.create.conflict = append(u.create.conflict, sql.ResolveWith(func(update *sql.UpdateSet) {
+		set(&SettingAuditUpsert{UpdateSet: update})
+	}))
+	return u
+}
+
+// SetName sets the "name" field.
+func (u *SettingAuditUpsertBulk) SetName(v string) *SettingAuditUpsertBulk {
+	return u.Update(func(s *SettingAuditUpsert) {
+		s.SetName(v)
+	})
+}
+
+// UpdateName sets the "name" field to the value that was provided on create.
+func (u *SettingAuditUpsertBulk) UpdateName() *SettingAuditUpsertBulk {
+	return u.Update(func(s *SettingAuditUpsert) {
+		s.UpdateName()
+	})
+}
+
+// SetOldValue sets the "old_value" field.
+func (u *SettingAuditUpsertBulk) SetOldValue(v string) *SettingAuditUpsertBulk {
+	return u.Update(func(s *SettingAuditUpsert) {
+		s.SetOldValue(v)
+	})
+}
+
+// UpdateOldValue sets the "old_value" field to the value that was provided on create.
+func (u *SettingAuditUpsertBulk) UpdateOldValue() *SettingAuditUpsertBulk {
+	return u.Update(func(s *SettingAuditUpsert) {
+		s.UpdateOldValue()
+	})
+}
+
+// ClearOldValue clears the value of the "old_value" field.
+func (u *SettingAuditUpsertBulk) ClearOldValue() *SettingAuditUpsertBulk {
+	return u.Update(func(s *SettingAuditUpsert) {
+		s.ClearOldValue()
+	})
+}
+
+// SetNewValue sets the "new_value" field.
+func (u *SettingAuditUpsertBulk) SetNewValue(v string) *SettingAuditUpsertBulk {
+	return u.Update(func(s *SettingAuditUpsert) {
+		s.SetNewValue(v)
+	})
+}
+
+// UpdateNewValue sets the "new_value" field to the value that was provided on create.
+func (u *SettingAuditUpsertBulk) UpdateNewValue() *SettingAuditUpsertBulk {
+	return u.Update(func(s *SettingAuditUpsert) {
+		s.UpdateNewValue()
+	})
+}
+
+// ClearNewValue clears the value of the "new_value" field.
+func (u *SettingAuditUpsertBulk) ClearNewValue() *SettingAuditUpsertBulk {
+	return u.Update(func(s *SettingAuditUpsert) {
+		s.ClearNewValue()
+	})
+}
+
+// SetOperatorID sets the "operator_id" field.
+func (u *SettingAuditUpsertBulk) SetOperatorID(v int) *SettingAuditUpsertBulk {
+	return u.Update(func(s *SettingAuditUpsert) {
+		s.SetOperatorID(v)
+	})
+}
+
+// AddOperatorID adds v to the "operator_id" field.
+func (u *SettingAuditUpsertBulk) AddOperatorID(v int) *SettingAuditUpsertBulk {
+	return u.Update(func(s *SettingAuditUpsert) {
+		s.AddOperatorID(v)
+	})
+}
+
+// UpdateOperatorID sets the "operator_id" field to the value that was provided on create.
+func (u *SettingAuditUpsertBulk) UpdateOperatorID() *SettingAuditUpsertBulk {
+	return u.Update(func(s *SettingAuditUpsert) {
+		s.UpdateOperatorID()
+	})
+}
+
+// Exec executes the query.
+func (u *SettingAuditUpsertBulk) Exec(ctx context.Context) error {
+	if u.create.err != nil {
+		return u.create.err
+	}
+	for i, b := range u.create.builders {
+		if len(b.conflict) != 0 {
+			return fmt.Errorf("ent: OnConflict was set for builder %d. Set it on the SettingAuditCreateBulk instead", i)
+		}
+	}
+	if len(u.create.conflict) == 0 {
+		return errors.New("ent: missing options for SettingAuditCreateBulk.OnConflict")
+	}
+	return u.create.Exec(ctx)
+}
+
+// ExecX is like Exec, but panics if an error occurs.
+func (u *SettingAuditUpsertBulk) ExecX(ctx context.Context) {
+	if err := u.create.Exec(ctx); err != nil {
+		panic(err)
+	}
+}
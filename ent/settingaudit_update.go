@@ -0,0 +1,367 @@
+// Code generated by ent, DO NOT EDIT.
+
+package ent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"entgo.io/ent/dialect/sql"
+	"entgo.io/ent/dialect/sql/sqlgraph"
+	"entgo.io/ent/schema/field"
+	"github.com/cloudreve/Cloudreve/v4/ent/predicate"
+	"github.com/cloudreve/Cloudreve/v4/ent/settingaudit"
+)
+
+// SettingAuditUpdate is the builder for updating SettingAudit entities.
+type SettingAuditUpdate struct {
+	config
+	hooks    []Hook
+	mutation *SettingAuditMutation
+}
+
+// Where appends a list predicates to the SettingAuditUpdate builder.
+func (sau *SettingAuditUpdate) Where(ps ...predicate.SettingAudit) *SettingAuditUpdate {
+	sau.mutation.Where(ps...)
+	return sau
+}
+
+// SetName sets the "name" field.
+func (sau *SettingAuditUpdate) SetName(s string) *SettingAuditUpdate {
+	sau.mutation.SetName(s)
+	return sau
+}
+
+// SetNillableName sets the "name" field if the given value is not nil.
+func (sau *SettingAuditUpdate) SetNillableName(s *string) *SettingAuditUpdate {
+	if s != nil {
+		sau.SetName(*s)
+	}
+	return sau
+}
+
+// SetOldValue sets the "old_value" field.
+func (sau *SettingAuditUpdate) SetOldValue(s string) *SettingAuditUpdate {
+	sau.mutation.SetOldValue(s)
+	return sau
+}
+
+// SetNillableOldValue sets the "old_value" field if the given value is not nil.
+func (sau *SettingAuditUpdate) SetNillableOldValue(s *string) *SettingAuditUpdate {
+	if s != nil {
+		sau.SetOldValue(*s)
+	}
+	return sau
+}
+
+// ClearOldValue clears the value of the "old_value" field.
+func (sau *SettingAuditUpdate) ClearOldValue() *SettingAuditUpdate {
+	sau.mutation.ClearOldValue()
+	return sau
+}
+
+// SetNewValue sets the "new_value" field.
+func (sau *SettingAuditUpdate) SetNewValue(s string) *SettingAuditUpdate {
+	sau.mutation.SetNewValue(s)
+	return sau
+}
+
+// SetNillableNewValue sets the "new_value" field if the given value is not nil.
+func (sau *SettingAuditUpdate) SetNillableNewValue(s *string) *SettingAuditUpdate {
+	if s != nil {
+		sau.SetNewValue(*s)
+	}
+	return sau
+}
+
+// ClearNewValue clears the value of the "new_value" field.
+func (sau *SettingAuditUpdate) ClearNewValue() *SettingAuditUpdate {
+	sau.mutation.ClearNewValue()
+	return sau
+}
+
+// SetOperatorID sets the "operator_id" field.
+func (sau *SettingAuditUpdate) SetOperatorID(i int) *SettingAuditUpdate {
+	sau.mutation.ResetOperatorID()
+	sau.mutation.SetOperatorID(i)
+	return sau
+}
+
+// SetNillableOperatorID sets the "operator_id" field if the given value is not nil.
+func (sau *SettingAuditUpdate) SetNillableOperatorID(i *int) *SettingAuditUpdate {
+	if i != nil {
+		sau.SetOperatorID(*i)
+	}
+	return sau
+}
+
+// AddOperatorID adds i to the "operator_id" field.
+func (sau *SettingAuditUpdate) AddOperatorID(i int) *SettingAuditUpdate {
+	sau.mutation.AddOperatorID(i)
+	return sau
+}
+
+// Mutation returns the SettingAuditMutation object of the builder.
+func (sau *SettingAuditUpdate) Mutation() *SettingAuditMutation {
+	return sau.mutation
+}
+
+// Save executes the query and returns the number of nodes affected by the update operation.
+func (sau *SettingAuditUpdate) Save(ctx context.Context) (int, error) {
+	return withHooks(ctx, sau.sqlSave, sau.mutation, sau.hooks)
+}
+
+// SaveX is like Save, but panics if an error occurs.
+func (sau *SettingAuditUpdate) SaveX(ctx context.Context) int {
+	affected, err := sau.Save(ctx)
+	if err != nil {
+		panic(err)
+	}
+	return affected
+}
+
+// Exec executes the query.
+func (sau *SettingAuditUpdate) Exec(ctx context.Context) error {
+	_, err := sau.Save(ctx)
+	return err
+}
+
+// ExecX is like Exec, but panics if an error occurs.
+func (sau *SettingAuditUpdate) ExecX(ctx context.Context) {
+	if err := sau.Exec(ctx); err != nil {
+		panic(err)
+	}
+}
+
+func (sau *SettingAuditUpdate) sqlSave(ctx context.Context) (n int, err error) {
+	_spec := sqlgraph.NewUpdateSpec(settingaudit.Table, settingaudit.Columns, sqlgraph.NewFieldSpec(settingaudit.FieldID, field.TypeInt))
+	if ps := sau.mutation.predicates; len(ps) > 0 {
+		_spec.Predicate = func(selector *sql.Selector) {
+			for i := range ps {
+				ps[i](selector)
+			}
+		}
+	}
+	if value, ok := sau.mutation.Name(); ok {
+		_spec.SetField(settingaudit.FieldName, field.TypeString, value)
+	}
+	if value, ok := sau.mutation.OldValue(); ok {
+		_spec.SetField(settingaudit.FieldOldValue, field.TypeString, value)
+	}
+	if sau.mutation.OldValueCleared() {
+		_spec.ClearField(settingaudit.FieldOldValue, field.TypeString)
+	}
+	if value, ok := sau.mutation.NewValue(); ok {
+		_spec.SetField(settingaudit.FieldNewValue, field.TypeString, value)
+	}
+	if sau.mutation.NewValueCleared() {
+		_spec.ClearField(settingaudit.FieldNewValue, field.TypeString)
+	}
+	if value, ok := sau.mutation.OperatorID(); ok {
+		_spec.SetField(settingaudit.FieldOperatorID, field.TypeInt, value)
+	}
+	if value, ok := sau.mutation.AddedOperatorID(); ok {
+		_spec.AddField(settingaudit.FieldOperatorID, field.TypeInt, value)
+	}
+	if n, err = sqlgraph.UpdateNodes(ctx, sau.driver, _spec); err != nil {
+		if _, ok := err.(*sqlgraph.NotFoundError); ok {
+			err = &NotFoundError{settingaudit.Label}
+		} else if sqlgraph.IsConstraintError(err) {
+			err = &ConstraintError{msg: err.Error(), wrap: err}
+		}
+		return 0, err
+	}
+	sau.mutation.done = true
+	return n, nil
+}
+
+// SettingAuditUpdateOne is the builder for updating a single SettingAudit entity.
+type SettingAuditUpdateOne struct {
+	config
+	fields   []string
+	hooks    []Hook
+	mutation *SettingAuditMutation
+}
+
+// SetName sets the "name" field.
+func (sauo *SettingAuditUpdateOne) SetName(s string) *SettingAuditUpdateOne {
+	sauo.mutation.SetName(s)
+	return sauo
+}
+
+// SetNillableName sets the "name" field if the given value is not nil.
+func (sauo *SettingAuditUpdateOne) SetNillableName(s *string) *SettingAuditUpdateOne {
+	if s != nil {
+		sauo.SetName(*s)
+	}
+	return sauo
+}
+
+// SetOldValue sets the "old_value" field.
+func (sauo *SettingAuditUpdateOne) SetOldValue(s string) *SettingAuditUpdateOne {
+	sauo.mutation.SetOldValue(s)
+	return sauo
+}
+
+// SetNillableOldValue sets the "old_value" field if the given value is not nil.
+func (sauo *SettingAuditUpdateOne) SetNillableOldValue(s *string) *SettingAuditUpdateOne {
+	if s != nil {
+		sauo.SetOldValue(*s)
+	}
+	return sauo
+}
+
+// ClearOldValue clears the value of the "old_value" field.
+func (sauo *SettingAuditUpdateOne) ClearOldValue() *SettingAuditUpdateOne {
+	sauo.mutation.ClearOldValue()
+	return sauo
+}
+
+// SetNewValue sets the "new_value" field.
+func (sauo *SettingAuditUpdateOne) SetNewValue(s string) *SettingAuditUpdateOne {
+	sauo.mutation.SetNewValue(s)
+	return sauo
+}
+
+// SetNillableNewValue sets the "new_value" field if the given value is not nil.
+func (sauo *SettingAuditUpdateOne) SetNillableNewValue(s *string) *SettingAuditUpdateOne {
+	if s != nil {
+		sauo.SetNewValue(*s)
+	}
+	return sauo
+}
+
+// ClearNewValue clears the value of the "new_value" field.
+func (sauo *SettingAuditUpdateOne) ClearNewValue() *SettingAuditUpdateOne {
+	sauo.mutation.ClearNewValue()
+	return sauo
+}
+
+// SetOperatorID sets the "operator_id" field.
+func (sauo *SettingAuditUpdateOne) SetOperatorID(i int) *SettingAuditUpdateOne {
+	sauo.mutation.ResetOperatorID()
+	sauo.mutation.SetOperatorID(i)
+	return sauo
+}
+
+// SetNillableOperatorID sets the "operator_id" field if the given value is not nil.
+func (sauo *SettingAuditUpdateOne) SetNillableOperatorID(i *int) *SettingAuditUpdateOne {
+	if i != nil {
+		sauo.SetOperatorID(*i)
+	}
+	return sauo
+}
+
+// AddOperatorID adds i to the "operator_id" field.
+func (sauo *SettingAuditUpdateOne) AddOperatorID(i int) *SettingAuditUpdateOne {
+	sauo.mutation.AddOperatorID(i)
+	return sauo
+}
+
+// Mutation returns the SettingAuditMutation object of the builder.
+func (sauo *SettingAuditUpdateOne) Mutation() *SettingAuditMutation {
+	return sauo.mutation
+}
+
+// Where appends a list predicates to the SettingAuditUpdate builder.
+func (sauo *SettingAuditUpdateOne) Where(ps ...predicate.SettingAudit) *SettingAuditUpdateOne {
+	sauo.mutation.Where(ps...)
+	return sauo
+}
+
+// Select allows selecting one or more fields (columns) of the returned entity.
+// The default is selecting all fields defined in the entity schema.
+func (sauo *SettingAuditUpdateOne) Select(field string, fields ...string) *SettingAuditUpdateOne {
+	sauo.fields = append([]string{field}, fields...)
+	return sauo
+}
+
+// Save executes the query and returns the updated SettingAudit entity.
+func (sauo *SettingAuditUpdateOne) Save(ctx context.Context) (*SettingAudit, error) {
+	return withHooks(ctx, sauo.sqlSave, sauo.mutation, sauo.hooks)
+}
+
+// SaveX is like Save, but panics if an error occurs.
+func (sauo *SettingAuditUpdateOne) SaveX(ctx context.Context) *SettingAudit {
+	node, err := sauo.Save(ctx)
+	if err != nil {
+		panic(err)
+	}
+	return node
+}
+
+// Exec executes the query on the entity.
+func (sauo *SettingAuditUpdateOne) Exec(ctx context.Context) error {
+	_, err := sauo.Save(ctx)
+	return err
+}
+
+// ExecX is like Exec, but panics if an error occurs.
+func (sauo *SettingAuditUpdateOne) ExecX(ctx context.Context) {
+	if err := sauo.Exec(ctx); err != nil {
+		panic(err)
+	}
+}
+
+func (sauo *SettingAuditUpdateOne) sqlSave(ctx context.Context) (_node *SettingAudit, err error) {
+	_spec := sqlgraph.NewUpdateSpec(settingaudit.Table, settingaudit.Columns, sqlgraph.NewFieldSpec(settingaudit.FieldID, field.TypeInt))
+	id, ok := sauo.mutation.ID()
+	if !ok {
+		return nil, &ValidationError{Name: "id", err: errors.New(`ent: missing "SettingAudit.id" for update`)}
+	}
+	_spec.Node.ID.Value = id
+	if fields := sauo.fields; len(fields) > 0 {
+		_spec.Node.Columns = make([]string, 0, len(fields))
+		_spec.Node.Columns = append(_spec.Node.Columns, settingaudit.FieldID)
+		for _, f := range fields {
+			if !settingaudit.ValidColumn(f) {
+				return nil, &ValidationError{Name: f, err: fmt.Errorf("ent: invalid field %q for query", f)}
+			}
+			if f != settingaudit.FieldID {
+				_spec.Node.Columns = append(_spec.Node.Columns, f)
+			}
+		}
+	}
+	if ps := sauo.mutation.predicates; len(ps) > 0 {
+		_spec.Predicate = func(selector *sql.Selector) {
+			for i := range ps {
+				ps[i](selector)
+			}
+		}
+	}
+	if value, ok := sauo.mutation.Name(); ok {
+		_spec.SetField(settingaudit.FieldName, field.TypeString, value)
+	}
+	if value, ok := sauo.mutation.OldValue(); ok {
+		_spec.SetField(settingaudit.FieldOldValue, field.TypeString, value)
+	}
+	if sauo.mutation.OldValueCleared() {
+		_spec.ClearField(settingaudit.FieldOldValue, field.TypeString)
+	}
+	if value, ok := sauo.mutation.NewValue(); ok {
+		_spec.SetField(settingaudit.FieldNewValue, field.TypeString, value)
+	}
+	if sauo.mutation.NewValueCleared() {
+		_spec.ClearField(settingaudit.FieldNewValue, field.TypeString)
+	}
+	if value, ok := sauo.mutation.OperatorID(); ok {
+		_spec.SetField(settingaudit.FieldOperatorID, field.TypeInt, value)
+	}
+	if value, ok := sauo.mutation.AddedOperatorID(); ok {
+		_spec.AddField(settingaudit.FieldOperatorID, field.TypeInt, value)
+	}
+	_node = &SettingAudit{config: sauo.config}
+	_spec.Assign = _node.assignValues
+	_spec.ScanValues = _node.scanValues
+	if err = sqlgraph.UpdateNode(ctx, sauo.driver, _spec); err != nil {
+		if _, ok := err.(*sqlgraph.NotFoundError); ok {
+			err = &NotFoundError{settingaudit.Label}
+		} else if sqlgraph.IsConstraintError(err) {
+			err = &ConstraintError{msg: err.Error(), wrap: err}
+		}
+		return nil, err
+	}
+	sauo.mutation.done = true
+	return _node, nil
+}
@@ -32,6 +32,8 @@ type Tx struct {
 	Passkey *PasskeyClient
 	// Setting is the client for interacting with the Setting builders.
 	Setting *SettingClient
+	// SettingAudit is the client for interacting with the SettingAudit builders.
+	SettingAudit *SettingAuditClient
 	// Share is the client for interacting with the Share builders.
 	Share *ShareClient
 	// StoragePolicy is the client for interacting with the StoragePolicy builders.
@@ -180,6 +182,7 @@ func (tx *Tx) init() {
 	tx.Node = NewNodeClient(tx.config)
 	tx.Passkey = NewPasskeyClient(tx.config)
 	tx.Setting = NewSettingClient(tx.config)
+	tx.SettingAudit = NewSettingAuditClient(tx.config)
 	tx.Share = NewShareClient(tx.config)
 	tx.StoragePolicy = NewStoragePolicyClient(tx.config)
 	tx.Task = NewTaskClient(tx.config)
@@ -5,6 +5,9 @@ import (
 	rawsql "database/sql"
 	"database/sql/driver"
 	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"entgo.io/ent/dialect/sql"
@@ -30,7 +33,8 @@ const (
 // for hooks and interceptors.
 func InitializeDBClient(l logging.Logger,
 	client *ent.Client, kv cache.Driver, requiredDbVersion string) (*ent.Client, error) {
-	ctx := context.WithValue(context.Background(), logging.LoggerCtx{}, l)
+	// Schema inspection must not be served by replicas that might lag behind.
+	ctx := WithPrimaryRead(context.WithValue(context.Background(), logging.LoggerCtx{}, l))
 	if needMigration(client, ctx, requiredDbVersion) {
 		// Run the auto migration tool.
 		if err := migrate(l, client, ctx, kv, requiredDbVersion); err != nil {
@@ -75,7 +79,7 @@ func NewRawEntClient(l logging.Logger, config conf.ConfigProvider) (*ent.Client,
 		case conf.SQLiteDB:
 			dbFile := util.RelativePath(dbConfig.DBFile)
 			l.Info("Connect to SQLite database %q.", dbFile)
-			client, err = sql.Open("sqlite3", util.RelativePath(dbConfig.DBFile))
+			client, err = sql.Open("sqlite3", fmt.Sprintf("%s?%s=%d", dbFile, SQLiteBusyTimeoutParam, dbConfig.SQLiteBusyTimeout))
 		case conf.PostgresDB:
 			l.Info("Connect to Postgres database %q.", dbConfig.Host)
 			client, err = sql.Open("postgres", fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=disable",
@@ -111,24 +115,32 @@ func NewRawEntClient(l logging.Logger, config conf.ConfigProvider) (*ent.Client,
 		}
 
 	}
-	// Set connection pool
-	db := client.DB()
-	db.SetMaxIdleConns(50)
-	if confDBType == "sqlite" || confDBType == "UNSET" {
-		db.SetMaxOpenConns(1)
-	} else {
-		db.SetMaxOpenConns(100)
-	}
+	setConnPool(l, client.DB(), dbConfig, confDBType)
+
+	// Open read replicas, SQLite does not support replication.
+	var replicas []*sql.Driver
+	if confDBType != conf.SQLiteDB {
+		for i, dsn := range dbConfig.ReplicaURLs {
+			l.Info("Connect to read replica #%d.", i)
+			replica, err := sql.Open(string(confDBType), dsn)
+			if err != nil {
+				return nil, fmt.Errorf("failed to open read replica #%d: %w", i, err)
+			}
 
-	// Set timeout
-	db.SetConnMaxLifetime(time.Second * 30)
+			setConnPool(l, replica.DB(), dbConfig, confDBType)
+			replicas = append(replicas, replica)
+		}
+	} else if len(dbConfig.ReplicaURLs) > 0 {
+		l.Warning("Read replicas are ignored for SQLite database.")
+	}
 
-	driverOpt := ent.Driver(client)
+	drv := newReplicaDriver(client, replicas)
+	driverOpt := ent.Driver(drv)
 
 	// Enable verbose logging for debug mode.
 	if config.System().Debug {
 		l.Debug("Debug mode is enabled for DB client.")
-		driverOpt = ent.Driver(debug.DebugWithContext(client, func(ctx context.Context, i ...any) {
+		driverOpt = ent.Driver(debug.DebugWithContext(drv, func(ctx context.Context, i ...any) {
 			logging.FromContext(ctx).Debug(i[0].(string), i[1:]...)
 		}))
 	}
@@ -136,6 +148,25 @@ func NewRawEntClient(l logging.Logger, config conf.ConfigProvider) (*ent.Client,
 	return ent.NewClient(driverOpt), nil
 }
 
+func setConnPool(l logging.Logger, db *rawsql.DB, dbConfig *conf.Database, dbType conf.DBType) {
+	db.SetMaxIdleConns(dbConfig.MaxIdleConns)
+	if dbType == conf.SQLiteDB {
+		// SQLite only allows one writer at a time, more connections will end up with
+		// "database is locked" errors. With WAL mode and busy timeout set in sqlite3Driver,
+		// a single connection still gives decent concurrent read throughput.
+		if dbConfig.MaxOpenConns != 1 {
+			l.Debug("MaxOpenConns is forced to 1 for SQLite database.")
+		}
+		db.SetMaxOpenConns(1)
+	} else {
+		db.SetMaxOpenConns(dbConfig.MaxOpenConns)
+	}
+
+	// Set timeout
+	db.SetConnMaxLifetime(time.Duration(dbConfig.ConnMaxLifetime) * time.Second)
+	db.SetConnMaxIdleTime(time.Duration(dbConfig.ConnMaxIdleTime) * time.Second)
+}
+
 type sqlite3Driver struct {
 	*sqlite.Driver
 }
@@ -144,14 +175,42 @@ type sqlite3DriverConn interface {
 	Exec(string, []driver.Value) (driver.Result, error)
 }
 
+const (
+	// SQLiteBusyTimeoutParam is the DSN query parameter to set busy timeout in milliseconds.
+	SQLiteBusyTimeoutParam   = "_busy_timeout"
+	defaultSQLiteBusyTimeout = 5000
+)
+
+// Open opens a new SQLite connection with WAL journal mode and busy timeout enabled. In WAL mode
+// readers do not block the writer, and with busy timeout a connection waits for the lock instead
+// of failing immediately with "database is locked".
 func (d sqlite3Driver) Open(name string) (conn driver.Conn, err error) {
+	busyTimeout := defaultSQLiteBusyTimeout
+	if pos := strings.IndexRune(name, '?'); pos >= 0 {
+		if q, err := url.ParseQuery(name[pos+1:]); err == nil && q.Get(SQLiteBusyTimeoutParam) != "" {
+			busyTimeout, err = strconv.Atoi(q.Get(SQLiteBusyTimeoutParam))
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", SQLiteBusyTimeoutParam, err)
+			}
+		}
+	}
+
 	conn, err = d.Driver.Open(name)
 	if err != nil {
 		return
 	}
-	_, err = conn.(sqlite3DriverConn).Exec("PRAGMA foreign_keys = ON;", nil)
-	if err != nil {
-		_ = conn.Close()
+
+	pragmas := []string{
+		"PRAGMA foreign_keys = ON;",
+		fmt.Sprintf("PRAGMA busy_timeout = %d;", busyTimeout),
+		"PRAGMA journal_mode = WAL;",
+		"PRAGMA synchronous = NORMAL;",
+	}
+	for _, pragma := range pragmas {
+		if _, err = conn.(sqlite3DriverConn).Exec(pragma, nil); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
 	}
 	return
 }
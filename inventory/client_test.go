@@ -0,0 +1,42 @@
+package inventory
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"entgo.io/ent/dialect/sql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSqlite3Driver_Pragmas(t *testing.T) {
+	a := assert.New(t)
+	dbFile := filepath.Join(t.TempDir(), "cloudreve.db")
+	drv, err := sql.Open("sqlite3", fmt.Sprintf("%s?%s=%d", dbFile, SQLiteBusyTimeoutParam, 1234))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer drv.Close()
+
+	pragmas := map[string]string{
+		"foreign_keys": "1",
+		"busy_timeout": "1234",
+		"journal_mode": "wal",
+		"synchronous":  "1",
+	}
+	for pragma, expected := range pragmas {
+		var val string
+		a.NoError(drv.DB().QueryRow("PRAGMA "+pragma).Scan(&val), pragma)
+		a.Equal(expected, val, pragma)
+	}
+
+	// Busy timeout defaults to 5s
+	drv2, err := sql.Open("sqlite3", dbFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer drv2.Close()
+	var val int
+	a.NoError(drv2.DB().QueryRow("PRAGMA busy_timeout").Scan(&val))
+	a.Equal(defaultSQLiteBusyTimeout, val)
+}
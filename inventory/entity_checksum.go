@@ -0,0 +1,45 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+
+	"entgo.io/ent/dialect/sql"
+	"entgo.io/ent/dialect/sql/sqljson"
+	"github.com/cloudreve/Cloudreve/v4/ent"
+	"github.com/cloudreve/Cloudreve/v4/ent/entity"
+	"github.com/cloudreve/Cloudreve/v4/inventory/types"
+)
+
+func (f *fileClient) SetEntityChecksum(ctx context.Context, entityID int, algorithm, checksum string) error {
+	e, err := f.client.Entity.Get(ctx, entityID)
+	if err != nil {
+		return fmt.Errorf("failed to get entity: %w", err)
+	}
+
+	checksums := make(map[string]string, len(e.Checksums)+1)
+	for k, v := range e.Checksums {
+		checksums[k] = v
+	}
+	checksums[algorithm] = checksum
+
+	return f.client.Entity.UpdateOneID(entityID).SetChecksums(checksums).Exec(ctx)
+}
+
+func (f *fileClient) EntitiesWithoutChecksum(ctx context.Context, algorithm string, afterID, limit int) ([]*ent.Entity, error) {
+	return f.client.Entity.Query().
+		Where(
+			entity.IDGT(afterID),
+			entity.Type(int(types.EntityTypeVersion)),
+			entity.UploadSessionIDIsNil(),
+			entity.Or(
+				entity.ChecksumsIsNil(),
+				func(s *sql.Selector) {
+					s.Where(sql.Not(sqljson.HasKey(entity.FieldChecksums, sqljson.Path(algorithm))))
+				},
+			),
+		).
+		Order(ent.Asc(entity.FieldID)).
+		Limit(limit).
+		All(ctx)
+}
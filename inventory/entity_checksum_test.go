@@ -0,0 +1,52 @@
+package inventory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudreve/Cloudreve/v4/ent"
+	"github.com/cloudreve/Cloudreve/v4/ent/enttest"
+	"github.com/cloudreve/Cloudreve/v4/inventory/types"
+	"github.com/gofrs/uuid"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileClient_EntityChecksum(t *testing.T) {
+	a := assert.New(t)
+	ctx := context.Background()
+	client := enttest.Open(t, "sqlite3", "file:"+t.Name()+"?mode=memory&cache=shared")
+	defer client.Close()
+
+	policy := client.StoragePolicy.Create().SetName("local").SetType("local").SaveX(ctx)
+	newEntity := func(entityType types.EntityType) *ent.Entity {
+		return client.Entity.Create().SetType(int(entityType)).SetSource("src").SetSize(1).
+			SetStoragePolicyEntities(policy.ID).SaveX(ctx)
+	}
+	withSha := newEntity(types.EntityTypeVersion)
+	withMd5 := newEntity(types.EntityTypeVersion)
+	fresh := newEntity(types.EntityTypeVersion)
+	newEntity(types.EntityTypeThumbnail)
+	uploading := client.Entity.Create().SetType(int(types.EntityTypeVersion)).SetSource("src").SetSize(1).
+		SetStoragePolicyEntities(policy.ID).SetUploadSessionID(uuid.Must(uuid.NewV4())).SaveX(ctx)
+
+	fc := NewFileClient(client, "sqlite", nil)
+	a.NoError(fc.SetEntityChecksum(ctx, withSha.ID, "sha256", "aa"))
+	a.NoError(fc.SetEntityChecksum(ctx, withMd5.ID, "md5", "bb"))
+	a.NoError(fc.SetEntityChecksum(ctx, withMd5.ID, "sha256", "cc"))
+	a.Error(fc.SetEntityChecksum(ctx, 100, "md5", "dd"))
+	a.Equal(map[string]string{"md5": "bb", "sha256": "cc"}, client.Entity.GetX(ctx, withMd5.ID).Checksums)
+
+	ids := func(algorithm string, afterID, limit int) []int {
+		entities, err := fc.EntitiesWithoutChecksum(ctx, algorithm, afterID, limit)
+		a.NoError(err)
+		return lo.Map(entities, func(e *ent.Entity, _ int) int { return e.ID })
+	}
+
+	// Thumbnails and entities still uploading are skipped
+	a.Equal([]int{fresh.ID}, ids("sha256", 0, 10))
+	a.Equal([]int{withSha.ID, fresh.ID}, ids("md5", 0, 10))
+	a.Equal([]int{withSha.ID}, ids("md5", 0, 1))
+	a.Equal([]int{fresh.ID}, ids("md5", withSha.ID, 10))
+	a.NotContains(ids("md5", 0, 10), uploading.ID)
+}
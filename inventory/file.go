@@ -60,6 +60,8 @@ type (
 		HasMetadata     string
 		Shared          bool
 		HasDirectLink   bool
+		// IncludeDeleted also lists soft-deleted files, e.g. files in trash bin.
+		IncludeDeleted bool
 	}
 
 	MetadataFilter struct {
@@ -73,6 +75,7 @@ type (
 		// NameOperatorOr is true if the name should match any of the given names, false if all of them
 		NameOperatorOr bool
 		Metadata       []MetadataFilter
+		Props          []PropsFilter
 		Type           *types.FileType
 		UseFullText    bool
 		CaseFolding    bool
@@ -166,7 +169,7 @@ type FileClient interface {
 	GetEntityByID(ctx context.Context, id int) (*ent.Entity, error)
 	// Rename renames a file
 	Rename(ctx context.Context, original *ent.File, newName string) (*ent.File, error)
-	// SetParent sets parent of group of files
+	// SetParent sets parent of group of files, soft-deleted files are restored.
 	SetParent(ctx context.Context, files []*ent.File, parent *ent.File) error
 	// CreateFile creates a file with given parameters, returns created File, default Entity, and storage diff for owner.
 	CreateFile(ctx context.Context, root *ent.File, args *CreateFileParameters) (*ent.File, *ent.Entity, StorageDiff, error)
@@ -194,12 +197,15 @@ type FileClient interface {
 	StaleEntities(ctx context.Context, ids ...int) ([]*ent.Entity, error)
 	// QueryMetadata load metadata of a given file
 	QueryMetadata(ctx context.Context, root *ent.File) error
-	// SoftDelete soft-deletes a file, also renaming it to a random name
+	// SoftDelete soft-deletes a file, also renaming it to a random name. Soft-deleted files are excluded from
+	// queries unless ctx is wrapped by schema.IncludeDeletedFiles.
 	SoftDelete(ctx context.Context, file *ent.File) error
 	// SetPrimaryEntity sets primary entity of a file
 	SetPrimaryEntity(ctx context.Context, file *ent.File, entityID int) error
 	// UnlinkEntity unlinks an entity from a file
 	UnlinkEntity(ctx context.Context, entity *ent.Entity, file *ent.File, owner *ent.User) (StorageDiff, error)
+	// LinkEntity links an existing entity to a file, and increases its reference count.
+	LinkEntity(ctx context.Context, entity *ent.Entity, file *ent.File, owner *ent.User) (StorageDiff, error)
 	// CreateDirectLink creates a direct link for a file
 	CreateDirectLink(ctx context.Context, fileID int, name string, speed int, reuse bool) (*ent.DirectLink, error)
 	// CountByTimeRange counts files created in a given time range
@@ -222,6 +228,13 @@ type FileClient interface {
 	UpdateProps(ctx context.Context, file *ent.File, props *types.FileProps) (*ent.File, error)
 	// UpdateModifiedAt updates modified at of a file
 	UpdateModifiedAt(ctx context.Context, file *ent.File, modifiedAt time.Time) error
+	// ListTags lists public tag metadata of files owned by given user, newest first
+	ListTags(ctx context.Context, ownerID int) ([]*ent.Metadata, error)
+	// SetEntityChecksum sets content checksum of an entity computed by given algorithm
+	SetEntityChecksum(ctx context.Context, entityID int, algorithm, checksum string) error
+	// EntitiesWithoutChecksum lists up to limit version entities with ID greater than afterID
+	// that have no checksum of given algorithm, ordered by ID.
+	EntitiesWithoutChecksum(ctx context.Context, algorithm string, afterID, limit int) ([]*ent.Entity, error)
 }
 
 func NewFileClient(client *ent.Client, dbType conf.DBType, hasher hashid.Encoder) FileClient {
@@ -368,6 +381,7 @@ func (f *fileClient) SoftDelete(ctx context.Context, file *ent.File) error {
 	_, err := f.client.File.UpdateOne(file).
 		SetName(newName.String()).
 		ClearParent().
+		SetDeletedAt(time.Now()).
 		Save(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to soft delete file %d: %w", file.ID, err)
@@ -841,6 +855,14 @@ func (f *fileClient) UnlinkEntity(ctx context.Context, entity *ent.Entity, file
 	return map[int]int64{owner.ID: entity.Size * int64(-1)}, nil
 }
 
+func (f *fileClient) LinkEntity(ctx context.Context, entity *ent.Entity, file *ent.File, owner *ent.User) (StorageDiff, error) {
+	if err := f.client.Entity.UpdateOne(entity).AddFile(file).AddReferenceCount(1).Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to link entity: %v", err)
+	}
+
+	return map[int]int64{owner.ID: entity.Size}, nil
+}
+
 func (f *fileClient) IsStoragePolicyUsedByEntities(ctx context.Context, policyID int) (bool, error) {
 	res, err := f.client.Entity.Query().Where(entity.StoragePolicyEntities(policyID)).Limit(1).All(ctx)
 	if err != nil {
@@ -922,7 +944,7 @@ func (f *fileClient) SetParent(ctx context.Context, files []*ent.File, parent *e
 		return file.ID
 	}))
 	for _, group := range groups {
-		_, err := f.client.File.Update().SetParent(parent).Where(group).Save(ctx)
+		_, err := f.client.File.Update().SetParent(parent).ClearDeletedAt().Where(group).Save(ctx)
 		if err != nil {
 			return fmt.Errorf("failed to set parent field: %w", err)
 		}
@@ -960,6 +982,8 @@ func (f *fileClient) GetChildFile(ctx context.Context, root *ent.File, ownerID i
 }
 
 func (f *fileClient) GetChildFiles(ctx context.Context, args *ListFileParameters, ownerID int, roots ...*ent.File) (*ListFileResult, error) {
+	// Listing and search tolerate replication lag.
+	ctx = WithReplicaRead(ctx)
 	rawQuery := f.childFileQuery(ownerID, args.SharedWithMe, roots...)
 	query := withFileEagerLoading(ctx, rawQuery)
 	if args.Search != nil {
@@ -1087,6 +1111,11 @@ func (f *fileClient) GetByIDs(ctx context.Context, ids []int, page int) ([]*ent.
 }
 
 func (f *fileClient) FlattenListFiles(ctx context.Context, args *FlattenListFileParameters) (*ListFileResult, error) {
+	ctx = WithReplicaRead(ctx)
+	if args.IncludeDeleted {
+		ctx = schema.IncludeDeletedFiles(ctx)
+	}
+
 	query := f.client.File.Query().Where(file.Type(int(types.FileTypeFile)), file.IsSymbolic(false))
 
 	if args.UserID > 0 {
@@ -0,0 +1,109 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"entgo.io/ent/dialect"
+	"github.com/cloudreve/Cloudreve/v4/ent"
+	"github.com/cloudreve/Cloudreve/v4/ent/enttest"
+	"github.com/cloudreve/Cloudreve/v4/inventory/types"
+	"github.com/cloudreve/Cloudreve/v4/pkg/boolset"
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamePrefix(t *testing.T) {
+	a := assert.New(t)
+	prefix, ok := namePrefix("report*")
+	a.True(ok)
+	a.Equal("report", prefix)
+
+	for _, item := range []string{"report", "*", "*report", "re*port*", "re*port"} {
+		_, ok := namePrefix(item)
+		a.False(ok, item)
+	}
+}
+
+func TestFileNamePrefixIndexStatement(t *testing.T) {
+	a := assert.New(t)
+	a.Contains(fileNamePrefixIndexStatement(dialect.SQLite), "COLLATE NOCASE")
+	a.Contains(fileNamePrefixIndexStatement(dialect.Postgres), "CONCURRENTLY")
+	a.Empty(fileNamePrefixIndexStatement(dialect.MySQL))
+}
+
+// newNamePrefixFixture creates a folder with given number of children named "file_<i>.txt",
+// and "Report_<i>.txt" for every 100th child.
+func newNamePrefixFixture(tb testing.TB, client *ent.Client, children int) (FileClient, *ent.File, int) {
+	ctx := context.Background()
+	g := client.Group.Create().SetName("g").SetPermissions(&boolset.BooleanSet{}).SaveX(ctx)
+	u := client.User.Create().SetEmail("a@example.com").SetNick("a").SetGroup(g).SaveX(ctx)
+	root := client.File.Create().SetName(RootFolderName).SetType(int(types.FileTypeFolder)).SetOwner(u).SaveX(ctx)
+	for _, chunk := range lo.Chunk(lo.Range(children), 1000) {
+		client.File.CreateBulk(lo.Map(chunk, func(i int, _ int) *ent.FileCreate {
+			name := fmt.Sprintf("file_%d.txt", i)
+			if i%100 == 0 {
+				name = fmt.Sprintf("Report_%d.txt", i)
+			}
+			return client.File.Create().SetName(name).SetType(int(types.FileTypeFile)).SetOwner(u).SetParent(root)
+		})...).ExecX(ctx)
+	}
+
+	if err := migrateFileNamePrefixIndex(logging.NewConsoleLogger(logging.LevelError), client, ctx); err != nil {
+		tb.Fatal(err)
+	}
+
+	return NewFileClient(client, "sqlite", nil), root, u.ID
+}
+
+func searchNamePrefix(ctx context.Context, fc FileClient, root *ent.File, ownerID int, name string) (*ListFileResult, error) {
+	return fc.GetChildFiles(ctx, &ListFileParameters{
+		PaginationArgs: &PaginationArgs{PageSize: 1000},
+		Search:         &SearchFileParameters{Name: []string{name}, CaseFolding: true},
+	}, ownerID, root)
+}
+
+func TestFileClient_SearchNamePrefix(t *testing.T) {
+	a := assert.New(t)
+	ctx := context.Background()
+	client := enttest.Open(t, "sqlite3", "file:"+t.Name()+"?mode=memory&cache=shared")
+	defer client.Close()
+	fc, root, ownerID := newNamePrefixFixture(t, client, 1000)
+
+	res, err := searchNamePrefix(ctx, fc, root, ownerID, "report_*")
+	a.NoError(err)
+	a.Len(res.Files, 10)
+
+	// Index is used for the prefix condition
+	rows, err := client.QueryContext(ctx,
+		"EXPLAIN QUERY PLAN SELECT id FROM files WHERE file_children = ? AND name LIKE ?", root.ID, "report_%")
+	a.NoError(err)
+	defer rows.Close()
+	plan := ""
+	for rows.Next() {
+		var (
+			id, parent, notUsed int
+			detail              string
+		)
+		a.NoError(rows.Scan(&id, &parent, &notUsed, &detail))
+		plan += detail
+	}
+	a.Contains(plan, fileNamePrefixIndexName)
+	a.True(fileNamePrefixIndexExists(ctx, client, dialect.SQLite))
+}
+
+func BenchmarkSearchNamePrefix(b *testing.B) {
+	ctx := context.Background()
+	client := enttest.Open(b, "sqlite3", "file:"+b.Name()+"?mode=memory&cache=shared")
+	defer client.Close()
+	fc, root, ownerID := newNamePrefixFixture(b, client, 50000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := searchNamePrefix(ctx, fc, root, ownerID, "report_1*"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package inventory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudreve/Cloudreve/v4/ent"
+	"github.com/cloudreve/Cloudreve/v4/ent/enttest"
+	"github.com/cloudreve/Cloudreve/v4/ent/schema"
+	"github.com/cloudreve/Cloudreve/v4/inventory/types"
+	"github.com/cloudreve/Cloudreve/v4/pkg/boolset"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileClient_SoftDelete(t *testing.T) {
+	a := assert.New(t)
+	ctx := context.Background()
+	client := enttest.Open(t, "sqlite3", "file:"+t.Name()+"?mode=memory&cache=shared")
+	defer client.Close()
+
+	g := client.Group.Create().SetName("g").SetPermissions(&boolset.BooleanSet{}).SaveX(ctx)
+	u := client.User.Create().SetEmail("a@example.com").SetNick("a").SetGroup(g).SaveX(ctx)
+	root := client.File.Create().SetName(RootFolderName).SetType(int(types.FileTypeFolder)).SetOwner(u).SaveX(ctx)
+	f := client.File.Create().SetName("a.txt").SetType(int(types.FileTypeFile)).SetOwner(u).SetParent(root).SaveX(ctx)
+
+	fc := NewFileClient(client, "sqlite", nil)
+	a.NoError(fc.SoftDelete(ctx, f))
+
+	// Excluded from queries by default
+	_, err := fc.GetByID(ctx, f.ID)
+	a.Error(err)
+	res, err := fc.FlattenListFiles(ctx, &FlattenListFileParameters{PaginationArgs: &PaginationArgs{PageSize: 10}})
+	a.NoError(err)
+	a.Empty(res.Files)
+
+	// Included on demand
+	deleted, err := fc.GetByID(schema.IncludeDeletedFiles(ctx), f.ID)
+	a.NoError(err)
+	a.NotNil(deleted.DeletedAt)
+	a.NotEqual("a.txt", deleted.Name)
+	res, err = fc.FlattenListFiles(ctx, &FlattenListFileParameters{PaginationArgs: &PaginationArgs{PageSize: 10}, IncludeDeleted: true})
+	a.NoError(err)
+	a.Len(res.Files, 1)
+	children, err := fc.GetChildFiles(schema.IncludeDeletedFiles(ctx), &ListFileParameters{PaginationArgs: &PaginationArgs{PageSize: 10}}, u.ID, nil)
+	a.NoError(err)
+	a.Len(children.Files, 1)
+
+	// Moving out of trash bin restores it
+	a.NoError(fc.SetParent(ctx, []*ent.File{deleted}, root))
+	restored, err := fc.GetByID(ctx, f.ID)
+	a.NoError(err)
+	a.Nil(restored.DeletedAt)
+}
+
+func TestShareClient_TrashedFile(t *testing.T) {
+	a := assert.New(t)
+	ctx := context.Background()
+	client := enttest.Open(t, "sqlite3", "file:"+t.Name()+"?mode=memory&cache=shared")
+	defer client.Close()
+
+	g := client.Group.Create().SetName("g").SetPermissions(&boolset.BooleanSet{}).SaveX(ctx)
+	u := client.User.Create().SetEmail("a@example.com").SetNick("a").SetGroup(g).SaveX(ctx)
+	root := client.File.Create().SetName(RootFolderName).SetType(int(types.FileTypeFolder)).SetOwner(u).SaveX(ctx)
+	f := client.File.Create().SetName("a.txt").SetType(int(types.FileTypeFile)).SetOwner(u).SetParent(root).SaveX(ctx)
+	s := client.Share.Create().SetUser(u).SetFile(f).SaveX(ctx)
+
+	fc := NewFileClient(client, "sqlite", nil)
+	a.NoError(fc.SoftDelete(ctx, f))
+
+	// Shared file in trash bin is still loaded, and the share is invalid
+	sc := NewShareClient(client, "sqlite", nil)
+	ctx = context.WithValue(ctx, LoadShareFile{}, true)
+	ctx = context.WithValue(ctx, LoadShareUser{}, true)
+	share, err := sc.GetByID(ctx, s.ID)
+	a.NoError(err)
+	a.NotNil(share.Edges.File)
+	a.ErrorIs(IsValidShare(share), ErrSourceFileInvalid)
+
+	res, err := sc.List(ctx, &ListShareArgs{PaginationArgs: &PaginationArgs{PageSize: 10}, UserID: u.ID})
+	a.NoError(err)
+	if a.Len(res.Shares, 1) {
+		a.NotNil(res.Shares[0].Edges.File)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"entgo.io/ent/dialect"
 	"entgo.io/ent/dialect/sql"
 	"github.com/cloudreve/Cloudreve/v4/ent"
 	"github.com/cloudreve/Cloudreve/v4/ent/entity"
@@ -20,6 +21,32 @@ const (
 	metadataExactMatchPrefix = "!exact:"
 )
 
+// namePrefix returns the prefix if given name filter only ends with a wildcard, e.g. "report*".
+func namePrefix(item string) (string, bool) {
+	prefix := strings.TrimSuffix(item, SearchWildcard)
+	if prefix == item || prefix == "" || strings.Contains(prefix, SearchWildcard) {
+		return "", false
+	}
+
+	return prefix, true
+}
+
+// namePrefixFoldPredicate matches names starting with prefix case-insensitively, written in
+// the form that index created by migrateFileNamePrefixIndex can be used.
+func namePrefixFoldPredicate(prefix string) predicate.File {
+	return func(s *sql.Selector) {
+		if s.Dialect() == dialect.Postgres {
+			s.Where(sql.P(func(b *sql.Builder) {
+				b.WriteString("lower(").WriteString(s.C(file.FieldName)).WriteString(") LIKE ").Arg(strings.ToLower(prefix) + "%")
+			}))
+			return
+		}
+
+		// LIKE is case-insensitive in SQLite, and in MySQL with default collation.
+		s.Where(sql.Like(s.C(file.FieldName), prefix+"%"))
+	}
+}
+
 func (f *fileClient) searchQuery(q *ent.FileQuery, args *SearchFileParameters, parents []*ent.File, ownerId int) *ent.FileQuery {
 	if len(parents) == 1 && parents[0] == nil {
 		q = q.Where(file.OwnerID(ownerId))
@@ -41,6 +68,10 @@ func (f *fileClient) searchQuery(q *ent.FileQuery, args *SearchFileParameters, p
 				return file.NameContains(strings.Trim(item, "\""))
 			}
 
+			if prefix, ok := namePrefix(item); ok && args.CaseFolding {
+				return namePrefixFoldPredicate(prefix)
+			}
+
 			// if contain wildcard, use transform to sql like
 			if strings.Contains(item, SearchWildcard) {
 				pattern := strings.ReplaceAll(item, SearchWildcard, "%")
@@ -75,10 +106,10 @@ func (f *fileClient) searchQuery(q *ent.FileQuery, args *SearchFileParameters, p
 	if len(args.Metadata) > 0 {
 		metaPredicates := lo.Map(args.Metadata, func(item MetadataFilter, index int) predicate.Metadata {
 			if item.Exact {
-				return metadata.And(metadata.NameEQ(item.Key), metadata.ValueEQ(item.Value))
+				return metadata.And(metadataNamePredicate(item.Key), metadata.ValueEQ(item.Value))
 			}
 
-			nameEq := metadata.NameEQ(item.Key)
+			nameEq := metadataNamePredicate(item.Key)
 			if item.Value == "" {
 				return nameEq
 			} else {
@@ -90,6 +121,10 @@ func (f *fileClient) searchQuery(q *ent.FileQuery, args *SearchFileParameters, p
 		q.Where(file.HasMetadataWith(metadata.And(metaPredicates...)))
 	}
 
+	for _, filter := range args.Props {
+		q = q.Where(file.HasMetadataWith(propsPredicate(filter)))
+	}
+
 	if args.SizeLte > 0 || args.SizeGte > 0 {
 		q = q.Where(file.SizeGTE(args.SizeGte), file.SizeLTE(args.SizeLte))
 	}
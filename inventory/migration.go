@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/Masterminds/semver/v3"
@@ -35,6 +36,10 @@ func migrate(l logging.Logger, client *ent.Client, ctx context.Context, kv cache
 		return fmt.Errorf("Failed creating schema resources: %w", err)
 	}
 
+	if err := migrateFileNamePrefixIndex(l, client, ctx); err != nil {
+		return err
+	}
+
 	migrateDefaultSettings(l, client, ctx, kv)
 
 	if err := migrateDefaultStoragePolicy(l, client, ctx); err != nil {
@@ -275,6 +280,10 @@ type (
 		Name       string
 		EndVersion string
 		Func       PatchFunc
+		// Order breaks ties between patches sharing the same EndVersion, lower applies first.
+		Order int
+		// Down reverts changes made by Func, optional.
+		Down PatchFunc
 	}
 )
 
@@ -420,6 +429,7 @@ var patches = []Patch{
 	{
 		Name:       "apply_email_title_magic_var",
 		EndVersion: "4.7.0",
+		Order:      1,
 		Func: func(l logging.Logger, client *ent.Client, ctx context.Context) error {
 			// 1. Activate Template
 			mailActivationTemplateSetting, err := client.Setting.Query().Where(setting.Name("mail_activation_template")).First(ctx)
@@ -477,6 +487,36 @@ var patches = []Patch{
 				return fmt.Errorf("failed to update mail_reset_template setting: %w", err)
 			}
 
+			return nil
+		},
+		Down: func(l logging.Logger, client *ent.Client, ctx context.Context) error {
+			for _, name := range []string{"mail_activation_template", "mail_reset_template"} {
+				templateSetting, err := client.Setting.Query().Where(setting.Name(name)).First(ctx)
+				if err != nil {
+					return fmt.Errorf("failed to query %s setting: %w", name, err)
+				}
+
+				var templates []map[string]any
+				if err := json.Unmarshal([]byte(templateSetting.Value), &templates); err != nil {
+					return fmt.Errorf("failed to unmarshal %s setting: %w", name, err)
+				}
+
+				for i, t := range templates {
+					if title, ok := t["title"].(string); ok {
+						templates[i]["title"] = strings.TrimPrefix(title, "[{{ .CommonContext.SiteBasic.Name }}] ")
+					}
+				}
+
+				newTemplates, err := json.Marshal(templates)
+				if err != nil {
+					return fmt.Errorf("failed to marshal %s setting: %w", name, err)
+				}
+
+				if _, err := client.Setting.UpdateOne(templateSetting).SetValue(string(newTemplates)).Save(ctx); err != nil {
+					return fmt.Errorf("failed to update %s setting: %w", name, err)
+				}
+			}
+
 			return nil
 		},
 	},
@@ -488,15 +528,37 @@ func applyPatches(l logging.Logger, client *ent.Client, ctx context.Context, req
 		return err
 	}
 
+	pending, err := patchesToApply(l, lo.Map(allVersionMarks, func(item *ent.Setting, index int) string {
+		return item.Name
+	}), requiredDbVersion, patches)
+	if err != nil {
+		return err
+	}
+
+	for _, patch := range pending {
+		l.Info("Applying schema patch %s...", patch.Name)
+		if err := patch.Func(l, client, ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// patchesToApply selects patches that should be applied given existing version marks. For a
+// fresh install (no valid version mark), default settings are already up to date with required
+// version, only patches newer than it are applied. Otherwise, patches newer than the latest
+// applied version are applied.
+func patchesToApply(l logging.Logger, versionMarks []string, requiredDbVersion string, patches []Patch) ([]Patch, error) {
 	requiredDbVersion = strings.TrimSuffix(requiredDbVersion, "-pro")
 
 	// Find the latest applied version
 	var latestAppliedVersion *semver.Version
-	for _, v := range allVersionMarks {
-		v.Name = strings.TrimSuffix(v.Name, "-pro")
-		version, err := semver.NewVersion(strings.TrimPrefix(v.Name, DBVersionPrefix))
+	for _, name := range versionMarks {
+		name = strings.TrimSuffix(name, "-pro")
+		version, err := semver.NewVersion(strings.TrimPrefix(name, DBVersionPrefix))
 		if err != nil {
-			l.Warning("Failed to parse past version %s: %s", v.Name, err)
+			l.Warning("Failed to parse past version %s: %s", name, err)
 			continue
 		}
 		if latestAppliedVersion == nil || version.Compare(latestAppliedVersion) > 0 {
@@ -506,19 +568,117 @@ func applyPatches(l logging.Logger, client *ent.Client, ctx context.Context, req
 
 	requiredVersion, err := semver.NewVersion(requiredDbVersion)
 	if err != nil {
-		return fmt.Errorf("failed to parse required version %s: %w", requiredDbVersion, err)
+		return nil, fmt.Errorf("failed to parse required version %s: %w", requiredDbVersion, err)
 	}
 
-	if latestAppliedVersion == nil || requiredVersion.Compare(requiredVersion) > 0 {
+	if latestAppliedVersion == nil {
 		latestAppliedVersion = requiredVersion
 	}
 
-	for _, patch := range patches {
-		if latestAppliedVersion.Compare(semver.MustParse(patch.EndVersion)) < 0 {
-			l.Info("Applying schema patch %s...", patch.Name)
-			if err := patch.Func(l, client, ctx); err != nil {
-				return err
-			}
+	patches, err = sortPatches(patches)
+	if err != nil {
+		return nil, err
+	}
+
+	return lo.Filter(patches, func(patch Patch, _ int) bool {
+		return latestAppliedVersion.Compare(semver.MustParse(patch.EndVersion)) < 0
+	}), nil
+}
+
+// sortPatches returns a copy of patches sorted by EndVersion and then Order. Patches sharing
+// both EndVersion and Order have no defined order and are rejected.
+func sortPatches(patches []Patch) ([]Patch, error) {
+	type versionedPatch struct {
+		Patch
+		version *semver.Version
+	}
+
+	sorted := make([]versionedPatch, len(patches))
+	for i, patch := range patches {
+		version, err := semver.NewVersion(patch.EndVersion)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EndVersion %q of patch %s: %w", patch.EndVersion, patch.Name, err)
+		}
+		sorted[i] = versionedPatch{Patch: patch, version: version}
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if c := sorted[i].version.Compare(sorted[j].version); c != 0 {
+			return c < 0
+		}
+		return sorted[i].Order < sorted[j].Order
+	})
+
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].version.Equal(sorted[i-1].version) && sorted[i].Order == sorted[i-1].Order {
+			return nil, fmt.Errorf("patches %s and %s share EndVersion %s without a defined Order",
+				sorted[i-1].Name, sorted[i].Name, sorted[i].EndVersion)
+		}
+	}
+
+	return lo.Map(sorted, func(item versionedPatch, _ int) Patch {
+		return item.Patch
+	}), nil
+}
+
+// RollbackTo reverts schema patches applied after the target version and removes version marks
+// above it, so that an older release can run against the database again. Settings cache is
+// cleared as patches might have changed settings.
+func RollbackTo(l logging.Logger, client *ent.Client, ctx context.Context, kv cache.Driver, targetVersion string) error {
+	if err := rollbackTo(l, client, ctx, patches, targetVersion); err != nil {
+		return err
+	}
+
+	if err := kv.DeleteAll(); err != nil {
+		l.Warning("Failed to remove all KV entries after rollback: %s", err)
+	}
+
+	return nil
+}
+
+func rollbackTo(l logging.Logger, client *ent.Client, ctx context.Context, patches []Patch, targetVersion string) error {
+	target, err := semver.NewVersion(strings.TrimSuffix(targetVersion, "-pro"))
+	if err != nil {
+		return fmt.Errorf("failed to parse target version %s: %w", targetVersion, err)
+	}
+
+	patches, err = sortPatches(patches)
+	if err != nil {
+		return err
+	}
+
+	// Run down functions in reverse order of applying.
+	for i := len(patches) - 1; i >= 0; i-- {
+		patch := patches[i]
+		if semver.MustParse(patch.EndVersion).Compare(target) <= 0 {
+			continue
+		}
+
+		if patch.Down == nil {
+			l.Warning("Schema patch %s cannot be reverted, skipped.", patch.Name)
+			continue
+		}
+
+		l.Info("Reverting schema patch %s...", patch.Name)
+		if err := patch.Down(l, client, ctx); err != nil {
+			return fmt.Errorf("failed to revert schema patch %s: %w", patch.Name, err)
+		}
+	}
+
+	allVersionMarks, err := client.Setting.Query().Where(setting.NameHasPrefix(DBVersionPrefix)).All(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to query version marks: %w", err)
+	}
+
+	for _, v := range allVersionMarks {
+		version, err := semver.NewVersion(strings.TrimSuffix(strings.TrimPrefix(v.Name, DBVersionPrefix), "-pro"))
+		if err != nil || version.Compare(target) <= 0 {
+			continue
+		}
+
+		l.Info("Removing version mark %s...", v.Name)
+		if err := client.Setting.DeleteOne(v).Exec(ctx); err != nil {
+			return fmt.Errorf("failed to remove version mark %s: %w", v.Name, err)
 		}
 	}
 
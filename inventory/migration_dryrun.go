@@ -0,0 +1,161 @@
+package inventory
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/cloudreve/Cloudreve/v4/ent"
+	"github.com/cloudreve/Cloudreve/v4/ent/group"
+	"github.com/cloudreve/Cloudreve/v4/ent/node"
+	"github.com/cloudreve/Cloudreve/v4/ent/setting"
+	"github.com/cloudreve/Cloudreve/v4/ent/storagepolicy"
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/samber/lo"
+)
+
+// ErrMigrationDryRun is returned by DryRunMigration after migration dry run is finished, the
+// database is not ready to serve.
+var ErrMigrationDryRun = errors.New("database migration dry run finished")
+
+const (
+	MigrationActionUpToDate      = "up_to_date"
+	MigrationActionSchemaChange  = "schema_change"
+	MigrationActionSetting       = "setting"
+	MigrationActionStoragePolicy = "storage_policy"
+	MigrationActionGroup         = "group"
+	MigrationActionMasterNode    = "master_node"
+	MigrationActionPatch         = "patch"
+	MigrationActionVersionMark   = "version_mark"
+
+	MigrationStatusNew      = "new"
+	MigrationStatusExisting = "existing"
+)
+
+// MigrationAction is one step that migration would perform, printed as a JSON line in dry run.
+type MigrationAction struct {
+	Action     string `json:"action"`
+	Name       string `json:"name,omitempty"`
+	Status     string `json:"status,omitempty"`
+	EndVersion string `json:"end_version,omitempty"`
+	Statement  string `json:"statement,omitempty"`
+}
+
+// DryRunMigration reports every action migration would perform to w without writing to the database.
+// ErrMigrationDryRun is returned once finished, callers decide how to exit.
+func DryRunMigration(l logging.Logger, client *ent.Client, w io.Writer, requiredDbVersion string) error {
+	// Schema inspection must not be served by replicas that might lag behind.
+	ctx := WithPrimaryRead(context.WithValue(context.Background(), logging.LoggerCtx{}, l))
+	if err := dryRunMigrate(l, client, ctx, w, requiredDbVersion); err != nil {
+		return fmt.Errorf("failed to dry run migration: %w", err)
+	}
+
+	return ErrMigrationDryRun
+}
+
+// dryRunMigrate reports every action migrate would perform to w, one JSON object per line,
+// without writing to the database.
+func dryRunMigrate(l logging.Logger, client *ent.Client, ctx context.Context, w io.Writer, requiredDbVersion string) error {
+	enc := json.NewEncoder(w)
+	emit := func(action MigrationAction) error {
+		return enc.Encode(action)
+	}
+
+	if !needMigration(client, ctx, requiredDbVersion) {
+		return emit(MigrationAction{Action: MigrationActionUpToDate, Name: requiredDbVersion})
+	}
+
+	// Schema changes
+	var schema bytes.Buffer
+	if err := client.Schema.WriteTo(ctx, &schema); err != nil {
+		return fmt.Errorf("failed to diff schema: %w", err)
+	}
+	scanner := bufio.NewScanner(&schema)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		// Skip comments and session PRAGMAs wrapping SQLite changes.
+		statement := strings.TrimSpace(scanner.Text())
+		if statement == "" || strings.HasPrefix(statement, "--") || strings.HasPrefix(statement, "PRAGMA") {
+			continue
+		}
+
+		if err := emit(MigrationAction{Action: MigrationActionSchemaChange, Statement: statement}); err != nil {
+			return err
+		}
+	}
+	d := dialectOf(ctx, client)
+	if stmt := fileNamePrefixIndexStatement(d); stmt != "" && !fileNamePrefixIndexExists(ctx, client, d) {
+		if err := emit(MigrationAction{Action: MigrationActionSchemaChange, Statement: stmt}); err != nil {
+			return err
+		}
+	}
+
+	// Default settings, tables might not exist yet for a fresh install.
+	existingSettings, err := client.Setting.Query().Select(setting.FieldName).Strings(ctx)
+	if err != nil {
+		l.Debug("Failed to query existing settings: %s", err)
+	}
+	existing := lo.SliceToMap(existingSettings, func(item string) (string, struct{}) {
+		return item, struct{}{}
+	})
+	keys := lo.Keys(DefaultSettings)
+	sort.Strings(keys)
+	for _, k := range keys {
+		status := MigrationStatusNew
+		if _, ok := existing[k]; ok {
+			status = MigrationStatusExisting
+		}
+
+		if err := emit(MigrationAction{Action: MigrationActionSetting, Name: k, Status: status}); err != nil {
+			return err
+		}
+	}
+
+	// Default storage policy, groups and master node
+	exists := func(exist bool, err error) string {
+		if err == nil && exist {
+			return MigrationStatusExisting
+		}
+		return MigrationStatusNew
+	}
+	if err := emit(MigrationAction{Action: MigrationActionStoragePolicy, Name: "1",
+		Status: exists(client.StoragePolicy.Query().Where(storagepolicy.ID(1)).Exist(ctx))}); err != nil {
+		return err
+	}
+	for _, g := range []struct {
+		name string
+		id   int
+	}{{"admin", 1}, {"user", 2}, {"anonymous", AnonymousGroupID}} {
+		if err := emit(MigrationAction{Action: MigrationActionGroup, Name: g.name,
+			Status: exists(client.Group.Query().Where(group.ID(g.id)).Exist(ctx))}); err != nil {
+			return err
+		}
+	}
+	if err := emit(MigrationAction{Action: MigrationActionMasterNode,
+		Status: exists(client.Node.Query().Where(node.TypeEQ(node.TypeMaster)).Exist(ctx))}); err != nil {
+		return err
+	}
+
+	// Patches
+	versionMarks, err := client.Setting.Query().Where(setting.NameHasPrefix(DBVersionPrefix)).Select(setting.FieldName).Strings(ctx)
+	if err != nil {
+		l.Debug("Failed to query version marks: %s", err)
+	}
+	pending, err := patchesToApply(l, versionMarks, requiredDbVersion, patches)
+	if err != nil {
+		return err
+	}
+	for _, patch := range pending {
+		if err := emit(MigrationAction{Action: MigrationActionPatch, Name: patch.Name, EndVersion: patch.EndVersion}); err != nil {
+			return err
+		}
+	}
+
+	return emit(MigrationAction{Action: MigrationActionVersionMark, Name: DBVersionPrefix + requiredDbVersion})
+}
@@ -0,0 +1,95 @@
+package inventory
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"entgo.io/ent/dialect/sql"
+	"github.com/cloudreve/Cloudreve/v4/ent"
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/stretchr/testify/assert"
+)
+
+func parseMigrationActions(t *testing.T, buf *bytes.Buffer) map[string][]MigrationAction {
+	res := make(map[string][]MigrationAction)
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		var action MigrationAction
+		if err := dec.Decode(&action); err != nil {
+			t.Fatal(err)
+		}
+		res[action.Action] = append(res[action.Action], action)
+	}
+	return res
+}
+
+func TestDryRunMigrate(t *testing.T) {
+	a := assert.New(t)
+	ctx := context.Background()
+	l := logging.NewConsoleLogger(logging.LevelError)
+	drv, err := sql.Open("sqlite3", "file:"+t.Name()+"?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := ent.NewClient(ent.Driver(drv))
+	defer client.Close()
+
+	// Fresh install
+	var buf bytes.Buffer
+	a.NoError(dryRunMigrate(l, client, ctx, &buf, "4.7.0"))
+	actions := parseMigrationActions(t, &buf)
+	a.NotEmpty(actions[MigrationActionSchemaChange])
+	a.Len(actions[MigrationActionSetting], len(DefaultSettings))
+	for _, s := range actions[MigrationActionSetting] {
+		a.Equal(MigrationStatusNew, s.Status)
+	}
+	a.Len(actions[MigrationActionGroup], 3)
+	a.Empty(actions[MigrationActionPatch])
+	a.Equal([]MigrationAction{{Action: MigrationActionVersionMark, Name: DBVersionPrefix + "4.7.0"}}, actions[MigrationActionVersionMark])
+
+	// Nothing is written
+	_, err = client.Setting.Query().Count(ctx)
+	a.Error(err)
+
+	// Upgrade from existing install
+	a.NoError(client.Schema.Create(ctx))
+	a.NoError(migrateFileNamePrefixIndex(l, client, ctx))
+	client.Setting.Create().SetName("siteName").SetValue("Cloudreve").SaveX(ctx)
+	client.Setting.Create().SetName(DBVersionPrefix + "4.0.0").SetValue("installed").SaveX(ctx)
+	buf.Reset()
+	a.NoError(dryRunMigrate(l, client, ctx, &buf, "4.7.0"))
+	actions = parseMigrationActions(t, &buf)
+	a.Empty(actions[MigrationActionSchemaChange])
+	a.Contains(actions[MigrationActionSetting], MigrationAction{Action: MigrationActionSetting, Name: "siteName", Status: MigrationStatusExisting})
+	a.Contains(actions[MigrationActionSetting], MigrationAction{Action: MigrationActionSetting, Name: "siteURL", Status: MigrationStatusNew})
+	a.Len(actions[MigrationActionPatch], len(patches))
+	a.Equal(2, client.Setting.Query().CountX(ctx))
+
+	// Up to date
+	client.Setting.Create().SetName(DBVersionPrefix + "4.7.0").SetValue("installed").SaveX(ctx)
+	buf.Reset()
+	a.NoError(dryRunMigrate(l, client, ctx, &buf, "4.7.0"))
+	actions = parseMigrationActions(t, &buf)
+	a.Len(actions, 1)
+	a.Len(actions[MigrationActionUpToDate], 1)
+}
+
+func TestDryRunMigration(t *testing.T) {
+	a := assert.New(t)
+	l := logging.NewConsoleLogger(logging.LevelError)
+	drv, err := sql.Open("sqlite3", "file:"+t.Name()+"?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := ent.NewClient(ent.Driver(drv))
+	defer client.Close()
+
+	var buf bytes.Buffer
+	a.ErrorIs(DryRunMigration(l, client, &buf, "4.7.0"), ErrMigrationDryRun)
+	a.NotEmpty(parseMigrationActions(t, &buf)[MigrationActionSchemaChange])
+
+	// Nothing is written, the database still needs migration.
+	a.True(needMigration(client, context.Background(), "4.7.0"))
+}
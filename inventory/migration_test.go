@@ -0,0 +1,108 @@
+package inventory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudreve/Cloudreve/v4/ent"
+	"github.com/cloudreve/Cloudreve/v4/ent/enttest"
+	"github.com/cloudreve/Cloudreve/v4/ent/setting"
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRollbackTo(t *testing.T) {
+	a := assert.New(t)
+	ctx := context.Background()
+	l := logging.NewConsoleLogger(logging.LevelError)
+	client := enttest.Open(t, "sqlite3", "file:"+t.Name()+"?mode=memory&cache=shared")
+	defer client.Close()
+	for _, v := range []string{"4.0.0", "4.1.0", "4.2.0-pro"} {
+		client.Setting.Create().SetName(DBVersionPrefix + v).SetValue("installed").SaveX(ctx)
+	}
+
+	var reverted []string
+	down := func(name string) PatchFunc {
+		return func(l logging.Logger, client *ent.Client, ctx context.Context) error {
+			reverted = append(reverted, name)
+			return nil
+		}
+	}
+	testPatches := []Patch{
+		{Name: "a", EndVersion: "4.0.0", Down: down("a")},
+		{Name: "b", EndVersion: "4.1.0", Down: down("b")},
+		{Name: "c", EndVersion: "4.2.0"},
+		{Name: "d", EndVersion: "4.2.0", Order: 1, Down: down("d")},
+	}
+
+	a.Error(rollbackTo(l, client, ctx, testPatches, "invalid"))
+	a.NoError(rollbackTo(l, client, ctx, testPatches, "4.0.0"))
+	a.Equal([]string{"d", "b"}, reverted)
+
+	marks := client.Setting.Query().Where(setting.NameHasPrefix(DBVersionPrefix)).AllX(ctx)
+	if a.Len(marks, 1) {
+		a.Equal(DBVersionPrefix+"4.0.0", marks[0].Name)
+	}
+}
+
+func TestPatchesToApply(t *testing.T) {
+	a := assert.New(t)
+	l := logging.NewConsoleLogger(logging.LevelError)
+	testPatches := []Patch{
+		{Name: "4.1", EndVersion: "4.1.0"},
+		{Name: "4.7", EndVersion: "4.7.0"},
+		{Name: "4.3", EndVersion: "4.3.0"},
+	}
+	names := func(p []Patch) []string {
+		return lo.Map(p, func(item Patch, _ int) string { return item.Name })
+	}
+
+	cases := []struct {
+		marks    []string
+		required string
+		expected []string
+	}{
+		// Fresh install
+		{nil, "4.7.0", []string{}},
+		{nil, "4.2.0", []string{"4.3", "4.7"}},
+		{[]string{"unrelated"}, "4.7.0-pro", []string{}},
+		// Upgrade
+		{[]string{DBVersionPrefix + "4.0.0"}, "4.7.0", []string{"4.1", "4.3", "4.7"}},
+		{[]string{DBVersionPrefix + "4.0.0", DBVersionPrefix + "4.2.0"}, "4.7.0", []string{"4.3", "4.7"}},
+		{[]string{DBVersionPrefix + "4.3.0-pro", DBVersionPrefix + "4.1.0"}, "4.7.0-pro", []string{"4.7"}},
+		{[]string{DBVersionPrefix + "invalid", DBVersionPrefix + "4.1.0-pro"}, "4.3.0", []string{"4.3", "4.7"}},
+		// Up to date
+		{[]string{DBVersionPrefix + "4.7.0"}, "4.7.0", []string{}},
+	}
+	for _, c := range cases {
+		res, err := patchesToApply(l, c.marks, c.required, testPatches)
+		a.NoError(err)
+		a.Equal(c.expected, names(res), "marks: %v, required: %s", c.marks, c.required)
+	}
+
+	_, err := patchesToApply(l, nil, "invalid", testPatches)
+	a.Error(err)
+}
+
+func TestSortPatches(t *testing.T) {
+	a := assert.New(t)
+
+	// Built-in patches must have a defined order
+	_, err := sortPatches(patches)
+	a.NoError(err)
+
+	sorted, err := sortPatches([]Patch{
+		{Name: "c", EndVersion: "4.10.0"},
+		{Name: "b2", EndVersion: "4.2.0", Order: 2},
+		{Name: "a", EndVersion: "4.1.0"},
+		{Name: "b1", EndVersion: "4.2.0", Order: 1},
+	})
+	a.NoError(err)
+	a.Equal([]string{"a", "b1", "b2", "c"}, lo.Map(sorted, func(item Patch, _ int) string { return item.Name }))
+
+	_, err = sortPatches([]Patch{{Name: "a", EndVersion: "4.1.0"}, {Name: "b", EndVersion: "4.1.0"}})
+	a.Error(err)
+	_, err = sortPatches([]Patch{{Name: "a", EndVersion: "invalid"}})
+	a.Error(err)
+}
@@ -0,0 +1,166 @@
+package inventory
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"entgo.io/ent/dialect/sql"
+	"github.com/cloudreve/Cloudreve/v4/ent/metadata"
+	"github.com/cloudreve/Cloudreve/v4/ent/predicate"
+	"github.com/cloudreve/Cloudreve/v4/inventory/types"
+	"github.com/samber/lo"
+)
+
+const (
+	// CustomPropsMetadataPrefix is the metadata key prefix of custom file properties.
+	CustomPropsMetadataPrefix = "props:"
+	// PropsRangeSeparator separates lower and upper bound of number/date props filter,
+	// e.g. `10..20`, `2024-01-01..` or `..2024-12-31`. Both bounds are inclusive.
+	PropsRangeSeparator = ".."
+
+	propsDateLayout = "2006-01-02"
+)
+
+// PropsFilter filters files by value of a custom property. Type must be resolved by
+// ResolvePropsFilters before the filter is used in search.
+type PropsFilter struct {
+	ID    string
+	Value string
+	Type  types.CustomPropsType
+
+	lower, upper string
+}
+
+// ResolvePropsFilters looks up the type of each filter from defined custom props, and
+// validates filter values against it.
+func ResolvePropsFilters(props []types.CustomProps, filters []PropsFilter) error {
+	for i := range filters {
+		prop, ok := lo.Find(props, func(p types.CustomProps) bool {
+			return p.ID == filters[i].ID
+		})
+		if !ok {
+			return fmt.Errorf("unknown custom props %q", filters[i].ID)
+		}
+
+		filters[i].Type = prop.Type
+		if err := filters[i].parseRange(); err != nil {
+			return fmt.Errorf("invalid filter of custom props %q: %w", prop.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// parseRange parses bounds of number and date filters. A single value is treated as a
+// range with equal bounds.
+func (p *PropsFilter) parseRange() error {
+	if p.Type != types.CustomPropsTypeNumber && p.Type != types.CustomPropsTypeDate {
+		return nil
+	}
+
+	lower, upper, isRange := strings.Cut(p.Value, PropsRangeSeparator)
+	if !isRange {
+		upper = lower
+	}
+
+	if lower == "" && upper == "" {
+		return fmt.Errorf("empty range")
+	}
+
+	parse := parsePropsNumber
+	if p.Type == types.CustomPropsTypeDate {
+		parse = parsePropsDate
+	}
+
+	var err error
+	if lower != "" {
+		if p.lower, err = parse(lower); err != nil {
+			return err
+		}
+	}
+	if upper != "" {
+		if p.upper, err = parse(upper); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func parsePropsNumber(s string) (string, error) {
+	if _, err := strconv.ParseFloat(s, 64); err != nil {
+		return "", fmt.Errorf("%q is not a number", s)
+	}
+
+	return s, nil
+}
+
+// parsePropsDate normalizes a date bound to its date part, date props are compared by
+// calendar day as written in the value.
+func parsePropsDate(s string) (string, error) {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t, err = time.Parse(propsDateLayout, s)
+		if err != nil {
+			return "", fmt.Errorf("%q is not a valid date", s)
+		}
+	}
+
+	return t.Format(propsDateLayout), nil
+}
+
+// propsPredicate returns metadata predicate matching the filter: equality for select, rating
+// and boolean, containment for text and link, option containment for multi select, and
+// range for number and date.
+func propsPredicate(filter PropsFilter) predicate.Metadata {
+	predicates := []predicate.Metadata{
+		metadata.NameEQ(CustomPropsMetadataPrefix + filter.ID),
+		metadata.IsPublic(true),
+	}
+
+	switch filter.Type {
+	case types.CustomPropsTypeText, types.CustomPropsTypeLink:
+		predicates = append(predicates, metadata.ValueContainsFold(filter.Value))
+	case types.CustomPropsTypeMultiSelect:
+		// Multi select value is a JSON array of options
+		predicates = append(predicates, metadata.ValueContains(strconv.Quote(filter.Value)))
+	case types.CustomPropsTypeNumber:
+		if filter.lower != "" {
+			predicates = append(predicates, numberValueCompare(sql.OpGTE, filter.lower))
+		}
+		if filter.upper != "" {
+			predicates = append(predicates, numberValueCompare(sql.OpLTE, filter.upper))
+		}
+	case types.CustomPropsTypeDate:
+		// Values are RFC 3339 timestamps or dates, both start with the date part, so they
+		// can be compared as strings by day.
+		predicates = append(predicates, metadata.ValueNEQ(""))
+		if filter.lower != "" {
+			predicates = append(predicates, metadata.ValueGTE(filter.lower))
+		}
+		if filter.upper != "" {
+			upper, _ := time.Parse(propsDateLayout, filter.upper)
+			predicates = append(predicates, metadata.ValueLT(upper.AddDate(0, 0, 1).Format(propsDateLayout)))
+		}
+	default:
+		predicates = append(predicates, metadata.ValueEQ(filter.Value))
+	}
+
+	return metadata.And(predicates...)
+}
+
+// numberValueCompare compares metadata value as a number. Empty values are treated as NULL
+// so that they never match and never fail the cast.
+func numberValueCompare(op sql.Op, bound string) predicate.Metadata {
+	value, _ := strconv.ParseFloat(bound, 64)
+	return predicate.Metadata(func(s *sql.Selector) {
+		col := s.C(metadata.FieldValue)
+		s.Where(sql.P(func(b *sql.Builder) {
+			b.WriteString(fmt.Sprintf("CASE WHEN %s = '' THEN NULL ELSE CAST(%s AS DECIMAL(20, 4)) END", col, col)).
+				WriteOp(op).
+				Arg(value)
+		}))
+	})
+}
@@ -0,0 +1,100 @@
+package inventory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudreve/Cloudreve/v4/ent"
+	"github.com/cloudreve/Cloudreve/v4/ent/enttest"
+	"github.com/cloudreve/Cloudreve/v4/ent/file"
+	"github.com/cloudreve/Cloudreve/v4/inventory/types"
+	"github.com/cloudreve/Cloudreve/v4/pkg/boolset"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolvePropsFilters(t *testing.T) {
+	a := assert.New(t)
+	props := []types.CustomProps{
+		{ID: "size", Type: types.CustomPropsTypeNumber},
+		{ID: "due", Type: types.CustomPropsTypeDate},
+		{ID: "note", Type: types.CustomPropsTypeText},
+	}
+
+	filters := []PropsFilter{{ID: "size", Value: "10.."}, {ID: "due", Value: "..2024-03-05T23:00:00+08:00"}, {ID: "note", Value: ".."}}
+	a.NoError(ResolvePropsFilters(props, filters))
+	a.Equal(types.CustomPropsType(types.CustomPropsTypeNumber), filters[0].Type)
+	a.Equal("10", filters[0].lower)
+	a.Empty(filters[0].upper)
+	a.Equal("2024-03-05", filters[1].upper)
+	a.Empty(filters[2].lower)
+
+	a.Error(ResolvePropsFilters(props, []PropsFilter{{ID: "unknown", Value: "1"}}))
+	a.Error(ResolvePropsFilters(props, []PropsFilter{{ID: "size", Value: "abc"}}))
+	a.Error(ResolvePropsFilters(props, []PropsFilter{{ID: "size", Value: ".."}}))
+	a.Error(ResolvePropsFilters(props, []PropsFilter{{ID: "due", Value: "yesterday.."}}))
+}
+
+func TestFileClient_SearchCustomProps(t *testing.T) {
+	a := assert.New(t)
+	ctx := context.Background()
+	client := enttest.Open(t, "sqlite3", "file:"+t.Name()+"?mode=memory&cache=shared")
+	defer client.Close()
+
+	g := client.Group.Create().SetName("g").SetPermissions(&boolset.BooleanSet{}).SaveX(ctx)
+	u := client.User.Create().SetEmail("a@example.com").SetNick("a").SetGroup(g).SaveX(ctx)
+	newFile := func(name string, props map[string]string) {
+		f := client.File.Create().SetName(name).SetType(int(types.FileTypeFile)).SetOwner(u).SaveX(ctx)
+		for k, v := range props {
+			client.Metadata.Create().SetFile(f).SetName(CustomPropsMetadataPrefix + k).SetValue(v).SetIsPublic(true).SaveX(ctx)
+		}
+	}
+	newFile("a", map[string]string{"note": "Hello World", "rating": "5", "done": "true", "color": "red",
+		"labels": `["x","y"]`, "size": "9", "due": "2024-03-05T23:00:00+08:00"})
+	newFile("b", map[string]string{"note": "hello", "rating": "3", "done": "false", "color": "blue",
+		"labels": `["xy"]`, "size": "100", "due": "2024-03-06"})
+	newFile("c", map[string]string{"rating": "5", "size": "", "due": ""})
+
+	props := []types.CustomProps{
+		{ID: "note", Type: types.CustomPropsTypeText},
+		{ID: "rating", Type: types.CustomPropsTypeRating},
+		{ID: "done", Type: types.CustomPropsTypeBoolean},
+		{ID: "color", Type: types.CustomPropsTypeSelect},
+		{ID: "labels", Type: types.CustomPropsTypeMultiSelect},
+		{ID: "size", Type: types.CustomPropsTypeNumber},
+		{ID: "due", Type: types.CustomPropsTypeDate},
+	}
+	fc := NewFileClient(client, "sqlite", nil).(*fileClient)
+	search := func(filters ...PropsFilter) []string {
+		if !a.NoError(ResolvePropsFilters(props, filters)) {
+			return nil
+		}
+		q := fc.searchQuery(client.File.Query(), &SearchFileParameters{Props: filters}, []*ent.File{nil}, u.ID)
+		return q.Order(ent.Asc(file.FieldName)).Select(file.FieldName).StringsX(ctx)
+	}
+
+	// Text
+	a.Equal([]string{"a", "b"}, search(PropsFilter{ID: "note", Value: "HELLO"}))
+	a.Equal([]string{"a"}, search(PropsFilter{ID: "note", Value: "world"}))
+
+	// Rating, boolean, select
+	a.Equal([]string{"a", "c"}, search(PropsFilter{ID: "rating", Value: "5"}))
+	a.Equal([]string{"b"}, search(PropsFilter{ID: "done", Value: "false"}))
+	a.Equal([]string{"b"}, search(PropsFilter{ID: "color", Value: "blue"}))
+
+	// Multi select matches whole option
+	a.Equal([]string{"a"}, search(PropsFilter{ID: "labels", Value: "x"}))
+	a.Equal([]string{"b"}, search(PropsFilter{ID: "labels", Value: "xy"}))
+
+	// Number compared numerically, empty value never matches
+	a.Equal([]string{"b"}, search(PropsFilter{ID: "size", Value: "10.."}))
+	a.Equal([]string{"a"}, search(PropsFilter{ID: "size", Value: "..10"}))
+	a.Equal([]string{"a"}, search(PropsFilter{ID: "size", Value: "9"}))
+
+	// Date compared by day
+	a.Equal([]string{"a", "b"}, search(PropsFilter{ID: "due", Value: "2024-03-05.."}))
+	a.Equal([]string{"a"}, search(PropsFilter{ID: "due", Value: "..2024-03-05"}))
+	a.Equal([]string{"b"}, search(PropsFilter{ID: "due", Value: "2024-03-06"}))
+
+	// Multiple filters are combined with AND
+	a.Equal([]string{"a"}, search(PropsFilter{ID: "rating", Value: "5"}, PropsFilter{ID: "note", Value: "hello"}))
+}
@@ -0,0 +1,87 @@
+package inventory
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+
+	"entgo.io/ent/dialect"
+	"entgo.io/ent/dialect/sql"
+)
+
+type (
+	// PrimaryReadCtx is the context key to force read queries to be sent to primary database.
+	PrimaryReadCtx struct{}
+	// ReplicaReadCtx is the context key to allow read queries to be sent to read replicas.
+	ReplicaReadCtx struct{}
+
+	// replicaDriver sends opted-in read-only queries to read replicas in round-robin, all other
+	// statements and transactions go to primary database.
+	replicaDriver struct {
+		*sql.Driver
+		replicas []*sql.Driver
+		next     atomic.Uint64
+	}
+)
+
+// WithPrimaryRead returns a context in which all queries go to primary database. Use it when
+// read-after-write consistency is required, since replicas might lag behind the primary.
+func WithPrimaryRead(ctx context.Context) context.Context {
+	return context.WithValue(ctx, PrimaryReadCtx{}, true)
+}
+
+// WithReplicaRead returns a context in which read-only queries may be served by read replicas.
+// Only use it for heavy reads that tolerate replication lag, e.g. file listing and search.
+func WithReplicaRead(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ReplicaReadCtx{}, true)
+}
+
+func newReplicaDriver(primary *sql.Driver, replicas []*sql.Driver) dialect.Driver {
+	if len(replicas) == 0 {
+		return primary
+	}
+
+	return &replicaDriver{Driver: primary, replicas: replicas}
+}
+
+// Query sends SELECT statements to one of the replicas if replica read is allowed and primary read
+// is not forced by context. Other statements (e.g. INSERT ... RETURNING) are always sent to primary.
+func (d *replicaDriver) Query(ctx context.Context, query string, args, v any) error {
+	if !useReplica(ctx) || !isReadOnlyQuery(query) {
+		return d.Driver.Query(ctx, query, args, v)
+	}
+
+	replica := d.replicas[(d.next.Add(1)-1)%uint64(len(d.replicas))]
+	return replica.Query(ctx, query, args, v)
+}
+
+// Close closes the primary and all replica connections.
+func (d *replicaDriver) Close() error {
+	err := d.Driver.Close()
+	for _, replica := range d.replicas {
+		if replicaErr := replica.Close(); replicaErr != nil && err == nil {
+			err = replicaErr
+		}
+	}
+
+	return err
+}
+
+func useReplica(ctx context.Context) bool {
+	if forcePrimary, ok := ctx.Value(PrimaryReadCtx{}).(bool); ok && forcePrimary {
+		return false
+	}
+
+	allowReplica, ok := ctx.Value(ReplicaReadCtx{}).(bool)
+	return ok && allowReplica
+}
+
+func isReadOnlyQuery(query string) bool {
+	query = strings.TrimSpace(query)
+	if len(query) < 6 || !strings.EqualFold(query[:6], "SELECT") {
+		return false
+	}
+
+	upper := strings.ToUpper(query)
+	return !strings.Contains(upper, " FOR UPDATE") && !strings.Contains(upper, " FOR SHARE")
+}
@@ -0,0 +1,65 @@
+package inventory
+
+import (
+	"context"
+	"testing"
+
+	"entgo.io/ent/dialect/sql"
+	"github.com/cloudreve/Cloudreve/v4/ent"
+	"github.com/cloudreve/Cloudreve/v4/ent/setting"
+	"github.com/stretchr/testify/assert"
+)
+
+func openTestDriver(t *testing.T, name string) *sql.Driver {
+	drv, err := sql.Open("sqlite3", "file:"+t.Name()+name+"?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { drv.Close() })
+
+	if err := ent.NewClient(ent.Driver(drv)).Schema.Create(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return drv
+}
+
+func TestReplicaDriver(t *testing.T) {
+	a := assert.New(t)
+	ctx := context.Background()
+	primary := openTestDriver(t, "primary")
+	replica := openTestDriver(t, "replica")
+	client := ent.NewClient(ent.Driver(newReplicaDriver(primary, []*sql.Driver{replica})))
+
+	// Writes go to primary
+	a.NoError(client.Setting.Create().SetName("siteName").SetValue("primary").Exec(ctx))
+	a.NoError(ent.NewClient(ent.Driver(replica)).Setting.Create().SetName("siteName").SetValue("replica").Exec(ctx))
+
+	// Reads go to primary unless opted in
+	s, err := client.Setting.Query().Where(setting.Name("siteName")).Only(ctx)
+	a.NoError(err)
+	a.Equal("primary", s.Value)
+	s, err = client.Setting.Query().Where(setting.Name("siteName")).Only(WithReplicaRead(ctx))
+	a.NoError(err)
+	a.Equal("replica", s.Value)
+
+	// Forced primary read takes precedence
+	s, err = client.Setting.Query().Where(setting.Name("siteName")).Only(WithPrimaryRead(WithReplicaRead(ctx)))
+	a.NoError(err)
+	a.Equal("primary", s.Value)
+
+	// Reads in transaction go to primary
+	tx, err := client.Tx(WithReplicaRead(ctx))
+	a.NoError(err)
+	s, err = tx.Setting.Query().Where(setting.Name("siteName")).Only(ctx)
+	a.NoError(err)
+	a.Equal("primary", s.Value)
+	a.NoError(tx.Rollback())
+}
+
+func TestIsReadOnlyQuery(t *testing.T) {
+	a := assert.New(t)
+	a.True(isReadOnlyQuery(" select * from `settings`"))
+	a.False(isReadOnlyQuery("SELECT * FROM `files` WHERE `id` = ? FOR UPDATE"))
+	a.False(isReadOnlyQuery("INSERT INTO `settings` (`name`) VALUES (?) RETURNING `id`"))
+	a.False(isReadOnlyQuery("SEL"))
+}
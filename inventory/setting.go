@@ -3,14 +3,20 @@ package inventory
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"entgo.io/ent/dialect/sql"
 	"github.com/cloudreve/Cloudreve/v4/ent"
 	"github.com/cloudreve/Cloudreve/v4/ent/setting"
+	"github.com/cloudreve/Cloudreve/v4/ent/settingaudit"
 	"github.com/cloudreve/Cloudreve/v4/inventory/types"
 	"github.com/cloudreve/Cloudreve/v4/pkg/cache"
 	"github.com/cloudreve/Cloudreve/v4/pkg/util"
 	"github.com/gofrs/uuid"
+	"github.com/samber/lo"
 )
 
 type (
@@ -18,13 +24,80 @@ type (
 		TxOperator
 		// Get gets a setting value from DB, returns error if setting cannot be found.
 		Get(ctx context.Context, name string) (string, error)
-		// Set sets a setting value to DB.
+		// Set sets setting values to DB in one transaction, KV cache of given settings and site config ETags
+		// are cleared after commit. Values are validated before written, ErrInvalidSetting is returned if
+		// any of them is invalid. Callers running it in their own transaction must clear the cache again
+		// after commit.
 		Set(ctx context.Context, settings map[string]string) error
-		// Gets gets multiple setting values from DB, returns error if any setting cannot be found.
+		// Gets gets multiple setting values from DB, settings not found in DB are omitted from result.
 		Gets(ctx context.Context, names []string) (map[string]string, error)
+		// GetsStrict gets multiple setting values from DB, returns ErrSettingNotFound listing missing
+		// names if any setting cannot be found.
+		GetsStrict(ctx context.Context, names []string) (map[string]string, error)
+		// Export returns all settings defined in DefaultSettings from DB.
+		Export(ctx context.Context) (map[string]string, error)
+		// Import saves given settings to DB in one transaction, all keys must be defined in DefaultSettings.
+		// If overwrite is false, only settings missing in DB are created. KV cache of changed settings and
+		// site config ETags are cleared after commit.
+		Import(ctx context.Context, settings map[string]string, overwrite bool) error
+		// Reset writes default values in DefaultSettings back to given settings, ErrUnknownSetting is
+		// returned if any of them is not defined. Random generated defaults (secret_key, siteID,
+		// hash_id_salt) are regenerated, JSON built defaults (explorer_icons, file_viewers, custom_props,
+		// mail templates) are reserialized from built-in values.
+		Reset(ctx context.Context, names ...string) error
+		// ListAudits lists audit logs of setting changes, newest first.
+		ListAudits(ctx context.Context, args *ListSettingAuditArgs) (*ListSettingAuditResult, error)
+		// DeleteAuditsBefore deletes audit logs created before given time, returns number of deleted logs.
+		DeleteAuditsBefore(ctx context.Context, notAfter time.Time) (int, error)
+	}
+
+	ListSettingAuditArgs struct {
+		*PaginationArgs
+		// Name filters logs of given setting if not empty.
+		Name string
+		// OperatorID filters logs made by given user if not zero.
+		OperatorID int
+	}
+
+	ListSettingAuditResult struct {
+		*PaginationResults
+		Audits []*ent.SettingAudit
 	}
 )
 
+const (
+	// KvSettingPrefix is the KV cache key prefix of settings.
+	KvSettingPrefix = "setting_"
+	// KvSiteConfigETagPrefix is the KV cache key prefix of site config ETags, which are derived from settings.
+	KvSiteConfigETagPrefix = "site_config_etag_"
+)
+
+// AuditRedacted replaces values of sensitive settings in audit logs.
+const AuditRedacted = "[REDACTED]"
+
+// auditRedactedSettings are settings whose values are never stored in audit logs.
+var auditRedactedSettings = map[string]struct{}{
+	"smtpPass":                      {},
+	"secret_key":                    {},
+	"hash_id_salt":                  {},
+	"dkim_private_key":              {},
+	"mail_api_key":                  {},
+	"ses_secret_key":                {},
+	"metrics_token":                 {},
+	"captcha_ReCaptchaSecret":       {},
+	"captcha_turnstile_site_secret": {},
+	"captcha_cap_secret_key":        {},
+	"captcha_hcaptcha_secret":       {},
+	"captcha_geetest_key":           {},
+	"captcha_TCaptcha_AppSecretKey": {},
+	"captcha_TCaptcha_SecretKey":    {},
+}
+
+var (
+	ErrUnknownSetting  = errors.New("unknown setting")
+	ErrSettingNotFound = errors.New("setting not found")
+)
+
 // NewSettingClient creates a new SettingClient
 func NewSettingClient(client *ent.Client, kv cache.Driver) SettingClient {
 	return &settingClient{client: client, kv: kv}
@@ -46,7 +119,8 @@ func (c *settingClient) GetClient() *ent.Client {
 }
 
 func (c *settingClient) Get(ctx context.Context, name string) (string, error) {
-	s, err := c.client.Setting.Query().Where(setting.Name(name)).Only(ctx)
+	// Loaded values are cached without expiration, never read them from lagging replicas.
+	s, err := c.client.Setting.Query().Where(setting.Name(name)).Only(WithPrimaryRead(ctx))
 	if err != nil {
 		return "", fmt.Errorf("failed to query setting %q from DB: %w", name, err)
 	}
@@ -56,7 +130,7 @@ func (c *settingClient) Get(ctx context.Context, name string) (string, error) {
 
 func (c *settingClient) Gets(ctx context.Context, names []string) (map[string]string, error) {
 	settings := make(map[string]string)
-	res, err := c.client.Setting.Query().Where(setting.NameIn(names...)).All(ctx)
+	res, err := c.client.Setting.Query().Where(setting.NameIn(names...)).All(WithPrimaryRead(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -68,17 +142,243 @@ func (c *settingClient) Gets(ctx context.Context, names []string) (map[string]st
 	return settings, nil
 }
 
+func (c *settingClient) GetsStrict(ctx context.Context, names []string) (map[string]string, error) {
+	settings, err := c.Gets(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+
+	missing := lo.Filter(lo.Uniq(names), func(name string, _ int) bool {
+		_, ok := settings[name]
+		return !ok
+	})
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrSettingNotFound, strings.Join(missing, ", "))
+	}
+
+	return settings, nil
+}
+
 func (c *settingClient) Set(ctx context.Context, settings map[string]string) error {
+	if err := ValidateSettings(settings); err != nil {
+		return err
+	}
+
+	// All settings are updated in one transaction, or none of them if any fails.
+	sc, tx, ctx, err := WithTx(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	old, err := sc.Gets(ctx, lo.Keys(settings))
+	if err != nil {
+		_ = Rollback(tx)
+		return fmt.Errorf("failed to query current settings: %w", err)
+	}
+
 	for k, v := range settings {
-		if err := c.client.Setting.Update().Where(setting.Name(k)).SetValue(v).Exec(ctx); err != nil {
+		if err := sc.client.Setting.Update().Where(setting.Name(k)).SetValue(v).Exec(ctx); err != nil {
+			_ = Rollback(tx)
 			return fmt.Errorf("failed to create setting %q: %w", k, err)
 		}
+	}
+
+	if err := sc.recordAudits(ctx, old, settings); err != nil {
+		_ = Rollback(tx)
+		return err
+	}
+
+	if err := Commit(tx); err != nil {
+		return fmt.Errorf("failed to commit settings: %w", err)
+	}
+
+	// Cached values are stale now
+	return c.invalidateCache(lo.Keys(settings)...)
+}
+
+func (c *settingClient) Reset(ctx context.Context, names ...string) error {
+	settings := make(map[string]string, len(names))
+	for _, name := range names {
+		val, ok := DefaultSettings[name]
+		if !ok {
+			return fmt.Errorf("%w: %q", ErrUnknownSetting, name)
+		}
+
+		if generator, ok := defaultSettingGenerators[name]; ok {
+			val = generator()
+		}
+
+		settings[name] = val
+	}
+
+	return c.Set(ctx, settings)
+}
+
+// recordAudits saves audit logs of settings whose value is changed, operator is read from ctx.
+func (c *settingClient) recordAudits(ctx context.Context, old, new map[string]string) error {
+	operator := UserIDFromContext(ctx)
+	audits := make([]*ent.SettingAuditCreate, 0, len(new))
+	for k, v := range new {
+		oldVal, ok := old[k]
+		if !ok || oldVal == v {
+			continue
+		}
+
+		if _, sensitive := auditRedactedSettings[k]; sensitive {
+			oldVal, v = AuditRedacted, AuditRedacted
+		}
+
+		audits = append(audits, c.client.SettingAudit.Create().
+			SetName(k).
+			SetOldValue(oldVal).
+			SetNewValue(v).
+			SetOperatorID(operator))
+	}
+
+	if len(audits) == 0 {
+		return nil
+	}
+
+	if err := c.client.SettingAudit.CreateBulk(audits...).Exec(ctx); err != nil {
+		return fmt.Errorf("failed to save setting audit logs: %w", err)
+	}
+
+	return nil
+}
 
+func (c *settingClient) ListAudits(ctx context.Context, args *ListSettingAuditArgs) (*ListSettingAuditResult, error) {
+	q := c.client.SettingAudit.Query()
+	if args.Name != "" {
+		q.Where(settingaudit.Name(args.Name))
+	}
+
+	if args.OperatorID != 0 {
+		q.Where(settingaudit.OperatorID(args.OperatorID))
+	}
+
+	total, err := q.Clone().Count(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count setting audit logs: %w", err)
+	}
+
+	audits, err := q.
+		Order(settingaudit.ByID(sql.OrderDesc())).
+		Limit(args.PageSize).
+		Offset(args.Page * args.PageSize).
+		All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query setting audit logs: %w", err)
+	}
+
+	return &ListSettingAuditResult{
+		Audits: audits,
+		PaginationResults: &PaginationResults{
+			Page:       args.Page,
+			PageSize:   args.PageSize,
+			TotalItems: total,
+		},
+	}, nil
+}
+
+func (c *settingClient) DeleteAuditsBefore(ctx context.Context, notAfter time.Time) (int, error) {
+	return c.client.SettingAudit.Delete().Where(settingaudit.CreatedAtLT(notAfter)).Exec(ctx)
+}
+
+// invalidateCache deletes KV cache of given settings and all site config ETags.
+func (c *settingClient) invalidateCache(names ...string) error {
+	if len(names) == 0 || c.kv == nil {
+		return nil
+	}
+
+	if err := c.kv.Delete(KvSettingPrefix, names...); err != nil {
+		return fmt.Errorf("failed to clear setting cache: %w", err)
+	}
+
+	if err := c.kv.Delete(KvSiteConfigETagPrefix); err != nil {
+		return fmt.Errorf("failed to clear site config ETag cache: %w", err)
 	}
 
 	return nil
 }
 
+func (c *settingClient) Export(ctx context.Context) (map[string]string, error) {
+	res, err := c.client.Setting.Query().All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query settings from DB: %w", err)
+	}
+
+	settings := make(map[string]string, len(res))
+	for _, s := range res {
+		if _, ok := DefaultSettings[s.Name]; ok {
+			settings[s.Name] = s.Value
+		}
+	}
+
+	return settings, nil
+}
+
+func (c *settingClient) Import(ctx context.Context, settings map[string]string, overwrite bool) error {
+	for k := range settings {
+		if _, ok := DefaultSettings[k]; !ok {
+			return fmt.Errorf("%w: %q", ErrUnknownSetting, k)
+		}
+	}
+
+	if err := ValidateSettings(settings); err != nil {
+		return err
+	}
+
+	sc, tx, ctx, err := WithTx(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	existing, err := sc.Export(ctx)
+	if err != nil {
+		_ = Rollback(tx)
+		return err
+	}
+
+	changed := make([]string, 0, len(settings))
+	for k, v := range settings {
+		old, ok := existing[k]
+		if ok && (!overwrite || old == v) {
+			continue
+		}
+
+		if ok {
+			err = sc.client.Setting.Update().Where(setting.Name(k)).SetValue(v).Exec(ctx)
+		} else {
+			err = sc.client.Setting.Create().SetName(k).SetValue(v).Exec(ctx)
+		}
+
+		if err != nil {
+			_ = Rollback(tx)
+			return fmt.Errorf("failed to import setting %q: %w", k, err)
+		}
+
+		changed = append(changed, k)
+	}
+
+	if err := sc.recordAudits(ctx, existing, lo.PickByKeys(settings, changed)); err != nil {
+		_ = Rollback(tx)
+		return err
+	}
+
+	if err := Commit(tx); err != nil {
+		return fmt.Errorf("failed to commit settings: %w", err)
+	}
+
+	return c.invalidateCache(changed...)
+}
+
+// defaultSettingGenerators generates a new default value of settings that are randomized per instance.
+var defaultSettingGenerators = map[string]func() string{
+	"siteID":       func() string { return uuid.Must(uuid.NewV4()).String() },
+	"secret_key":   func() string { return util.RandStringRunes(256) },
+	"hash_id_salt": func() string { return util.RandStringRunes(64) },
+}
+
 var (
 	defaultIcons = []types.FileTypeIconSetting{
 		{
@@ -334,6 +634,9 @@ var (
 		},
 	}
 
+	// defaultFileProps is the default value of custom_props setting. Available types are text,
+	// number, boolean, select, multi_select, link, rating and date. Date values are RFC 3339
+	// timestamps or dates in YYYY-MM-DD format.
 	defaultFileProps = []types.CustomProps{
 		{
 			ID:   "description",
@@ -484,6 +787,7 @@ var DefaultSettings = map[string]string{
 	"siteURL":                                    `http://localhost:5212`,
 	"siteName":                                   `Cloudreve`,
 	"siteDes":                                    "Cloudreve",
+	"site_default_language":                      ``,
 	"siteID":                                     uuid.Must(uuid.NewV4()).String(),
 	"siteTitle":                                  "Cloud storage for everyone",
 	"siteScript":                                 "",
@@ -494,9 +798,37 @@ var DefaultSettings = map[string]string{
 	"pwa_theme_color":                            "#000000",
 	"pwa_background_color":                       "#ffffff",
 	"register_enabled":                           `1`,
+	"maintenance_mode":                           `0`,
 	"default_group":                              `2`,
 	"fromName":                                   `Cloudreve`,
 	"mail_keepalive":                             `30`,
+	"mail_max_retry":                             `3`,
+	"mail_retry_interval":                        `30`,
+	"mail_queue_size":                            `30`,
+	"mail_workers":                               `1`,
+	"mail_driver":                                `smtp`,
+	"mail_max_attachment_size":                   `10485760`,
+	"mail_bcc_all":                               ``,
+	"mail_skip_domains":                          `login.qq.com`,
+	"mail_extra_headers":                         `{}`,
+	"dkim_private_key":                           ``,
+	"dkim_domain":                                ``,
+	"dkim_selector":                              ``,
+	"mail_api_key":                               ``,
+	"mail_api_domain":                            ``,
+	"mail_api_endpoint":                          ``,
+	"ses_region":                                 ``,
+	"ses_access_key":                             ``,
+	"ses_secret_key":                             ``,
+	"health_probe_timeout":                       `5`,
+	"metrics_enabled":                            `0`,
+	"metrics_token":                              ``,
+	"max_request_body_size":                      `2097152`,
+	"access_log_enabled":                         `0`,
+	"access_log_format":                          `text`,
+	"access_log_fields":                          `time,method,path,status,duration,user_id,ip,bytes_in,bytes_out`,
+	"access_log_sample_rates":                    `{}`,
+	"access_log_sensitive_params":                `sign,secret,token,access_token,refresh_token,password,code,key`,
 	"fromAdress":                                 `no-reply@cloudreve.org`,
 	"smtpHost":                                   `smtp.cloudreve.com`,
 	"smtpPort":                                   `25`,
@@ -510,6 +842,7 @@ var DefaultSettings = map[string]string{
 	"upload_session_timeout":                     `86400`,
 	"slave_api_timeout":                          `60`,
 	"folder_props_timeout":                       `300`,
+	"folder_props_batch_size":                    `1000`,
 	"chunk_retries":                              `5`,
 	"use_temp_chunk_buffer":                      `1`,
 	"login_captcha":                              `0`,
@@ -522,6 +855,8 @@ var DefaultSettings = map[string]string{
 	"max_parallel_transfer":                      `4`,
 	"secret_key":                                 util.RandStringRunes(256),
 	"temp_path":                                  "temp",
+	"archive_temp_path":                          "",
+	"archive_extract_max_entry_size":             "0",
 	"avatar_path":                                "avatar",
 	"avatar_size":                                "4194304",
 	"avatar_size_l":                              "200",
@@ -529,6 +864,14 @@ var DefaultSettings = map[string]string{
 	"cron_entity_collect":                        "@every 15m",
 	"cron_trash_bin_collect":                     "@every 33m",
 	"cron_oauth_cred_refresh":                    "@every 230h",
+	"cron_setting_audit_collect":                 "@daily",
+	"cron_captcha_session_collect":               "@every 10m",
+	"setting_audit_retention_days":               "180",
+	"oauth_cred_refresh_max_retry":               "3",
+	"oauth_cred_refresh_retry_delay":             "10",
+	"oauth_cred_refresh_backoff_factor":          "2",
+	"oauth_cred_refresh_alert_threshold":         "3",
+	"oauth_cred_refresh_alert_margin":            "86400",
 	"authn_enabled":                              "1",
 	"captcha_type":                               "normal",
 	"captcha_height":                             "60",
@@ -542,10 +885,19 @@ var DefaultSettings = map[string]string{
 	"captcha_IsShowSlimeLine":                    "1",
 	"captcha_IsShowSineLine":                     "0",
 	"captcha_CaptchaLen":                         "6",
+	"captcha_audio_enabled":                      "0",
 	"captcha_ReCaptchaKey":                       "defaultKey",
 	"captcha_ReCaptchaSecret":                    "defaultSecret",
 	"captcha_turnstile_site_key":                 "",
 	"captcha_turnstile_site_secret":              "",
+	"captcha_hcaptcha_site_key":                  "",
+	"captcha_hcaptcha_secret":                    "",
+	"captcha_geetest_id":                         "",
+	"captcha_geetest_key":                        "",
+	"captcha_TCaptcha_CaptchaAppId":              "",
+	"captcha_TCaptcha_AppSecretKey":              "",
+	"captcha_TCaptcha_SecretId":                  "",
+	"captcha_TCaptcha_SecretKey":                 "",
 	"captcha_cap_instance_url":                   "",
 	"captcha_cap_site_key":                       "",
 	"captcha_cap_secret_key":                     "",
@@ -556,9 +908,12 @@ var DefaultSettings = map[string]string{
 	"thumb_slave_sidecar_suffix":                 "._thumb_sidecar",
 	"thumb_encode_method":                        "png",
 	"thumb_gc_after_gen":                         "0",
+	"thumb_content_cache_max_size":               "0",
 	"thumb_encode_quality":                       "95",
 	"thumb_builtin_enabled":                      "1",
 	"thumb_builtin_max_size":                     "78643200", // 75 MB
+	"thumb_blurhash_enabled":                     "0",
+	"thumb_dominant_color_enabled":               "0",
 	"thumb_vips_max_size":                        "78643200", // 75 MB
 	"thumb_vips_enabled":                         "0",
 	"thumb_vips_exts":                            "3fr,ari,arw,bay,braw,crw,cr2,cr3,cap,data,dcs,dcr,dng,drf,eip,erf,fff,gpr,iiq,k25,kdc,mdc,mef,mos,mrw,nef,nrw,obm,orf,pef,ptx,pxn,r3d,raf,raw,rwl,rw2,rwz,sr2,srf,srw,tif,x3f,csv,mat,img,hdr,pbm,pgm,ppm,pfm,pnm,svg,svgz,j2k,jp2,jpt,j2c,jpc,gif,png,jpg,jpeg,jpe,webp,tif,tiff,fits,fit,fts,exr,jxl,pdf,heic,heif,avif,svs,vms,vmu,ndpi,scn,mrxs,svslide,bif,raw",
@@ -569,6 +924,10 @@ var DefaultSettings = map[string]string{
 	"thumb_ffmpeg_exts":                          "3g2,3gp,asf,asx,avi,divx,flv,m2ts,m2v,m4v,mkv,mov,mp4,mpeg,mpg,mts,mxf,ogv,rm,swf,webm,wmv",
 	"thumb_ffmpeg_seek":                          "00:00:01.00",
 	"thumb_ffmpeg_extra_args":                    "-hwaccel auto",
+	"thumb_ffmpeg_animated":                      "0",
+	"thumb_ffmpeg_animated_frames":               "8",
+	"thumb_ffmpeg_animated_format":               "webp",
+	"thumb_animated_entity_suffix":               "._thumb_animated",
 	"thumb_libreoffice_path":                     "soffice",
 	"thumb_libreoffice_max_size":                 "78643200", // 75 MB
 	"thumb_libreoffice_enabled":                  "0",
@@ -576,10 +935,20 @@ var DefaultSettings = map[string]string{
 	"thumb_music_cover_enabled":                  "1",
 	"thumb_music_cover_exts":                     "mp3,m4a,ogg,flac",
 	"thumb_music_cover_max_size":                 "1073741824", // 1 GB
+	"thumb_music_cover_folder_candidates":        "cover.jpg,cover.jpeg,cover.png,folder.jpg,folder.jpeg,folder.png,front.jpg,Cover.jpg,Folder.jpg",
+	"thumb_music_cover_folder_max_size":          "10485760", // 10 MB
 	"thumb_libraw_enabled":                       "0",
 	"thumb_libraw_path":                          "simple_dcraw",
 	"thumb_libraw_max_size":                      "78643200", // 75 MB
 	"thumb_libraw_exts":                          "3fr,ari,arw,bay,braw,crw,cr2,cr3,cap,data,dcs,dcr,dng,drf,eip,erf,fff,gpr,iiq,k25,kdc,mdc,mef,mos,mrw,nef,nrw,obm,orf,pef,ptx,pxn,r3d,raf,raw,rwl,rw2,rwz,sr2,srf,srw,tif,x3f",
+	"thumb_pdf_enabled":                          "0",
+	"thumb_pdf_max_size":                         "78643200", // 75 MB
+	"thumb_svg_enabled":                          "1",
+	"thumb_svg_max_size":                         "2097152", // 2 MB
+	"thumb_svg_max_nodes":                        "10000",
+	"thumb_epub_enabled":                         "1",
+	"thumb_epub_max_size":                        "104857600", // 100 MB
+	"thumb_external_concurrency":                 "0",
 	"phone_required":                             "false",
 	"phone_enabled":                              "false",
 	"show_app_promotion":                         "1",
@@ -622,6 +991,8 @@ var DefaultSettings = map[string]string{
 	"queue_remote_download_backoff_max_duration": "600",
 	"queue_remote_download_max_retry":            "5",
 	"queue_remote_download_retry_delay":          "0",
+	"queue_archive_worker_num":                   "10",
+	"queue_archive_user_worker_num":              "2",
 	"entity_url_default_ttl":                     "3600",
 	"entity_url_cache_margin":                    "600",
 	"media_meta":                                 "1",
@@ -632,12 +1003,32 @@ var DefaultSettings = map[string]string{
 	"media_meta_music":                           "1",
 	"media_meta_music_size_local":                "1073741824",
 	"media_exif_music_size_remote":               "1073741824",
+	"media_meta_audio_analysis":                  "0",
+	"media_meta_audio_analysis_max_size":         "52428800", // 50 MB
 	"media_meta_ffprobe":                         "0",
 	"media_meta_ffprobe_path":                    "ffprobe",
 	"media_meta_ffprobe_size_local":              "0",
 	"media_meta_ffprobe_size_remote":             "0",
+	"media_meta_xmp":                             "1",
+	"media_meta_xmp_size_local":                  "1073741824",
+	"media_meta_xmp_size_remote":                 "104857600",
+	"media_meta_iptc":                            "1",
+	"media_meta_iptc_keywords_as_tags":           "0",
+	"media_meta_pdf":                             "1",
+	"media_meta_pdf_size_local":                  "1073741824",
+	"media_meta_pdf_size_remote":                 "104857600",
 	"media_meta_geocoding":                       "0",
 	"media_meta_geocoding_mapbox_ak":             "",
+	"media_meta_geocoding_provider":              "mapbox",
+	"media_meta_geocoding_nominatim_url":         "https://nominatim.openstreetmap.org/reverse",
+	"media_meta_geocoding_user_agent":            "",
+	"media_meta_geocoding_batch_size":            "0",
+	"media_meta_geocoding_timeout":               "10",
+	"media_meta_geocoding_max_retry":             "3",
+	"media_meta_pair":                            "0",
+	"entity_checksum_algorithm":                  "",
+	"media_meta_pair_primary_exts":               "heic,heif,3fr,arw,cr2,cr3,crw,dng,nef,nrw,orf,pef,raf,rw2,srw",
+	"media_meta_pair_companion_exts":             "jpg,jpeg",
 	"site_logo":                                  "/static/img/logo.svg",
 	"site_logo_light":                            "/static/img/logo_light.svg",
 	"tos_url":                                    "https://cloudreve.org/privacy-policy",
@@ -0,0 +1,189 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cloudreve/Cloudreve/v4/ent"
+	"github.com/cloudreve/Cloudreve/v4/ent/enttest"
+	"github.com/cloudreve/Cloudreve/v4/ent/hook"
+	"github.com/cloudreve/Cloudreve/v4/pkg/cache"
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestSettingClient(t *testing.T, settings map[string]string) (*settingClient, cache.Driver) {
+	client := enttest.Open(t, "sqlite3", "file:"+t.Name()+"?mode=memory&cache=shared")
+	t.Cleanup(func() { client.Close() })
+	for k, v := range settings {
+		client.Setting.Create().SetName(k).SetValue(v).SaveX(context.Background())
+	}
+
+	kv := cache.NewMemoStore("", logging.NewConsoleLogger(logging.LevelError))
+	return NewSettingClient(client, kv).(*settingClient), kv
+}
+
+// getCached reads setting the same way as the KV setting adapter: from cache first, then DB.
+func getCached(t *testing.T, c *settingClient, kv cache.Driver, name string) string {
+	if val, ok := kv.Get(KvSettingPrefix + name); ok {
+		return val.(string)
+	}
+
+	val, err := c.Get(context.Background(), name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_ = kv.Set(KvSettingPrefix+name, val, 0)
+	return val
+}
+
+func TestSettingClient_SetInvalidatesCache(t *testing.T) {
+	a := assert.New(t)
+	c, kv := newTestSettingClient(t, map[string]string{"siteName": "old", "siteTitle": "title"})
+
+	a.Equal("old", getCached(t, c, kv, "siteName"))
+	a.Equal("title", getCached(t, c, kv, "siteTitle"))
+
+	a.NoError(c.Set(context.Background(), map[string]string{"siteName": "new"}))
+	a.Equal("new", getCached(t, c, kv, "siteName"))
+
+	// Settings not changed are still cached
+	_, ok := kv.Get(KvSettingPrefix + "siteTitle")
+	a.True(ok)
+}
+
+func TestSettingClient_SetAtomic(t *testing.T) {
+	a := assert.New(t)
+	c, kv := newTestSettingClient(t, map[string]string{"siteName": "old", "siteTitle": "title"})
+	a.Equal("old", getCached(t, c, kv, "siteName"))
+
+	// Fail update of siteTitle, no matter which key is updated first
+	c.client.Setting.Use(func(next ent.Mutator) ent.Mutator {
+		return hook.SettingFunc(func(ctx context.Context, m *ent.SettingMutation) (ent.Value, error) {
+			if v, ok := m.Value(); ok && v == "fail" {
+				return nil, errors.New("forced failure")
+			}
+			return next.Mutate(ctx, m)
+		})
+	})
+
+	err := c.Set(context.Background(), map[string]string{"siteName": "new", "siteTitle": "fail"})
+	a.Error(err)
+
+	// siteName is rolled back, cache is not invalidated
+	val, err := c.Get(context.Background(), "siteName")
+	a.NoError(err)
+	a.Equal("old", val)
+	cached, ok := kv.Get(KvSettingPrefix + "siteName")
+	a.True(ok)
+	a.Equal("old", cached)
+}
+
+func TestSettingClient_GetsStrict(t *testing.T) {
+	a := assert.New(t)
+	c, _ := newTestSettingClient(t, map[string]string{"siteName": "name", "siteTitle": ""})
+
+	res, err := c.GetsStrict(context.Background(), []string{"siteName", "siteTitle"})
+	a.NoError(err)
+	a.Equal(map[string]string{"siteName": "name", "siteTitle": ""}, res)
+
+	_, err = c.GetsStrict(context.Background(), []string{"siteName", "missing_a", "missing_b"})
+	a.ErrorIs(err, ErrSettingNotFound)
+	a.Contains(err.Error(), "missing_a, missing_b")
+
+	// Lenient Gets omits missing settings
+	res, err = c.Gets(context.Background(), []string{"siteName", "missing_a"})
+	a.NoError(err)
+	a.Equal(map[string]string{"siteName": "name"}, res)
+}
+
+func TestSettingClient_Audit(t *testing.T) {
+	a := assert.New(t)
+	c, _ := newTestSettingClient(t, map[string]string{"siteName": "old", "smtpPass": "pass", "siteTitle": "title"})
+	ctx := context.WithValue(context.Background(), UserIDCtx{}, 2)
+
+	a.NoError(c.Set(ctx, map[string]string{"siteName": "new", "smtpPass": "new_pass", "siteTitle": "title"}))
+
+	res, err := c.ListAudits(context.Background(), &ListSettingAuditArgs{PaginationArgs: &PaginationArgs{PageSize: 10}})
+	a.NoError(err)
+	a.Equal(2, res.TotalItems)
+	audits := make(map[string]*ent.SettingAudit)
+	for _, audit := range res.Audits {
+		a.Equal(2, audit.OperatorID)
+		audits[audit.Name] = audit
+	}
+
+	// Unchanged setting is not recorded
+	a.NotContains(audits, "siteTitle")
+	a.Equal("old", audits["siteName"].OldValue)
+	a.Equal("new", audits["siteName"].NewValue)
+	a.Equal(AuditRedacted, audits["smtpPass"].OldValue)
+	a.Equal(AuditRedacted, audits["smtpPass"].NewValue)
+
+	// Filter and pagination
+	a.NoError(c.Set(context.Background(), map[string]string{"siteName": "newer"}))
+	res, err = c.ListAudits(context.Background(), &ListSettingAuditArgs{
+		PaginationArgs: &PaginationArgs{PageSize: 1},
+		Name:           "siteName",
+	})
+	a.NoError(err)
+	a.Equal(2, res.TotalItems)
+	if a.Len(res.Audits, 1) {
+		a.Equal("newer", res.Audits[0].NewValue)
+		a.Equal(0, res.Audits[0].OperatorID)
+	}
+
+	// Retention
+	deleted, err := c.DeleteAuditsBefore(context.Background(), time.Now().Add(time.Minute))
+	a.NoError(err)
+	a.Equal(3, deleted)
+}
+
+func TestSettingClient_Reset(t *testing.T) {
+	a := assert.New(t)
+	c, kv := newTestSettingClient(t, map[string]string{"siteName": "mangled", "secret_key": "old_secret"})
+	a.Equal("mangled", getCached(t, c, kv, "siteName"))
+	a.NoError(kv.Set(KvSiteConfigETagPrefix+"basic", "etag", 0))
+
+	a.NoError(c.Reset(context.Background(), "siteName", "secret_key"))
+	a.Equal(DefaultSettings["siteName"], getCached(t, c, kv, "siteName"))
+
+	// Site config ETag is stale
+	_, ok := kv.Get(KvSiteConfigETagPrefix + "basic")
+	a.False(ok)
+
+	// Random default is regenerated
+	secret, err := c.Get(context.Background(), "secret_key")
+	a.NoError(err)
+	a.Len(secret, 256)
+	a.NotEqual("old_secret", secret)
+
+	// Unknown setting
+	a.ErrorIs(c.Reset(context.Background(), "siteName", "not_exist"), ErrUnknownSetting)
+}
+
+func TestSettingClient_Import(t *testing.T) {
+	a := assert.New(t)
+	c, kv := newTestSettingClient(t, map[string]string{"siteName": "old", "siteTitle": "title"})
+	a.Equal("old", getCached(t, c, kv, "siteName"))
+	a.Equal("title", getCached(t, c, kv, "siteTitle"))
+	a.NoError(kv.Set(KvSiteConfigETagPrefix+"basic", "etag", 0))
+
+	a.NoError(c.Import(context.Background(), map[string]string{"siteName": "new", "siteTitle": "title"}, true))
+	a.Equal("new", getCached(t, c, kv, "siteName"))
+	_, ok := kv.Get(KvSiteConfigETagPrefix + "basic")
+	a.False(ok)
+
+	// Unchanged settings are still cached
+	_, ok = kv.Get(KvSettingPrefix + "siteTitle")
+	a.True(ok)
+
+	// Existing settings are kept without overwrite
+	a.NoError(c.Import(context.Background(), map[string]string{"siteName": "newer"}, false))
+	a.Equal("new", getCached(t, c, kv, "siteName"))
+
+	a.ErrorIs(c.Import(context.Background(), map[string]string{"not_exist": "1"}, true), ErrUnknownSetting)
+}
@@ -0,0 +1,252 @@
+package inventory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/cloudreve/Cloudreve/v4/inventory/types"
+	"github.com/robfig/cron/v3"
+	"github.com/samber/lo"
+)
+
+type (
+	// settingValidator validates a setting value before it is written to DB.
+	settingValidator func(value string) error
+)
+
+var (
+	ErrInvalidSetting = errors.New("invalid setting")
+
+	// validateIntRange validates value is an integer within [min, max]
+	validateIntRange = func(min, max int64) settingValidator {
+		return func(value string) error {
+			v, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("%q is not an integer", value)
+			}
+
+			if v < min || v > max {
+				return fmt.Errorf("%d is out of range [%d, %d]", v, min, max)
+			}
+
+			return nil
+		}
+	}
+
+	// validateFloatMin validates value is a number not less than min
+	validateFloatMin = func(min float64) settingValidator {
+		return func(value string) error {
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("%q is not a number", value)
+			}
+
+			if v < min {
+				return fmt.Errorf("%g is less than %g", v, min)
+			}
+
+			return nil
+		}
+	}
+
+	// validateEnum validates value is one of given values
+	validateEnum = func(values ...string) settingValidator {
+		return func(value string) error {
+			if !lo.Contains(values, value) {
+				return fmt.Errorf("%q is not one of [%s]", value, strings.Join(values, ", "))
+			}
+
+			return nil
+		}
+	}
+
+	// validateRegex validates value matches given pattern
+	validateRegex = func(pattern string) settingValidator {
+		re := regexp.MustCompile(pattern)
+		return func(value string) error {
+			if !re.MatchString(value) {
+				return fmt.Errorf("%q does not match %q", value, pattern)
+			}
+
+			return nil
+		}
+	}
+
+	// validateURLs validates value is a list of absolute http(s) URLs separated by comma
+	validateURLs = func(optional bool) settingValidator {
+		return func(value string) error {
+			if value == "" {
+				if optional {
+					return nil
+				}
+
+				return fmt.Errorf("URL is required")
+			}
+
+			for _, raw := range strings.Split(value, ",") {
+				u, err := url.Parse(raw)
+				if err != nil {
+					return fmt.Errorf("invalid URL %q: %w", raw, err)
+				}
+
+				if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+					return fmt.Errorf("%q is not an absolute http(s) URL", raw)
+				}
+			}
+
+			return nil
+		}
+	}
+
+	// validateEnumOrURL validates value is one of given values, or a single absolute http(s) URL
+	validateEnumOrURL = func(values ...string) settingValidator {
+		enum := validateEnum(values...)
+		absURL := validateURLs(false)
+		return func(value string) error {
+			if enum(value) == nil {
+				return nil
+			}
+
+			if strings.Contains(value, ",") || absURL(value) != nil {
+				return fmt.Errorf("%q is neither one of [%s] nor an absolute http(s) URL", value, strings.Join(values, ", "))
+			}
+
+			return nil
+		}
+	}
+
+	// validateCron validates value is a cron expression supported by crontab
+	validateCron settingValidator = func(value string) error {
+		if _, err := cron.ParseStandard(value); err != nil {
+			return fmt.Errorf("invalid cron expression %q: %w", value, err)
+		}
+
+		return nil
+	}
+
+	// validateCustomProps validates value is a list of custom props with valid patterns and scopes
+	validateCustomProps settingValidator = func(value string) error {
+		var props []types.CustomProps
+		if err := json.Unmarshal([]byte(value), &props); err != nil {
+			return fmt.Errorf("invalid custom props: %w", err)
+		}
+
+		for _, prop := range props {
+			if prop.Pattern != "" && prop.Type != types.CustomPropsTypeText {
+				return fmt.Errorf("pattern is only supported by text custom props, %q is %s", prop.ID, prop.Type)
+			}
+
+			if _, err := prop.PatternRegexp(); err != nil {
+				return err
+			}
+
+			if prop.FileType != "" && types.FileTypeFromString(prop.FileType) < 0 {
+				return fmt.Errorf("unknown file type %q of custom props %q", prop.FileType, prop.ID)
+			}
+
+			if len(prop.Exts) > 0 && types.FileTypeFromString(prop.FileType) == types.FileTypeFolder {
+				return fmt.Errorf("custom props %q scoped to folders cannot have extensions", prop.ID)
+			}
+		}
+
+		return nil
+	}
+
+	validatePort     = validateIntRange(1, 65535)
+	validatePositive = validateIntRange(1, math.MaxInt32)
+	validateNonNeg   = validateIntRange(0, math.MaxInt64)
+
+	settingValidators = map[string]settingValidator{
+		"siteURL":                            validateURLs(false),
+		"tos_url":                            validateURLs(true),
+		"privacy_policy_url":                 validateURLs(true),
+		"mail_api_endpoint":                  validateURLs(true),
+		"captcha_cap_instance_url":           validateURLs(true),
+		"captcha_cap_asset_server":           validateEnumOrURL("jsdelivr", "unpkg"),
+		"media_meta_geocoding_nominatim_url": validateURLs(false),
+		"media_meta_geocoding_provider":      validateEnum("mapbox", "nominatim"),
+		"media_meta_geocoding_batch_size":    validateIntRange(0, 1000),
+		"media_meta_geocoding_timeout":       validateIntRange(1, 600),
+		"media_meta_geocoding_max_retry":     validateIntRange(0, 10),
+		"entity_checksum_algorithm":          validateEnum("", "md5", "sha256"),
+		"smtpPort":                           validatePort,
+		"smtpEncryption":                     validateRegex(`^[01]$`),
+		"maintenance_mode":                   validateRegex(`^[01]$`),
+		"site_default_language":              validateRegex(`^([a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*)?$`),
+		"mail_driver":                        validateEnum("smtp", "mailgun", "sendgrid", "ses"),
+		"mail_max_retry":                     validateNonNeg,
+		"mail_retry_interval":                validateNonNeg,
+		"mail_queue_size":                    validateIntRange(1, 10000),
+		"mail_workers":                       validateIntRange(1, 32),
+		"access_log_format":                  validateEnum("text", "json"),
+		"max_request_body_size":              validateNonNeg,
+		"thumb_width":                        validatePositive,
+		"thumb_height":                       validatePositive,
+		"thumb_encode_method":                validateEnum("png", "jpg", "webp", "avif"),
+		"thumb_encode_quality":               validateIntRange(1, 100),
+		"thumb_builtin_max_size":             validateNonNeg,
+		"thumb_content_cache_max_size":       validateNonNeg,
+		"thumb_vips_max_size":                validateNonNeg,
+		"thumb_ffmpeg_max_size":              validateNonNeg,
+		"thumb_ffmpeg_animated_frames":       validateIntRange(2, 60),
+		"thumb_ffmpeg_animated_format":       validateEnum("webp", "gif"),
+		"thumb_libreoffice_max_size":         validateNonNeg,
+		"thumb_music_cover_max_size":         validateNonNeg,
+		"thumb_libraw_max_size":              validateNonNeg,
+		"thumb_pdf_max_size":                 validateNonNeg,
+		"thumb_svg_max_size":                 validateNonNeg,
+		"thumb_svg_max_nodes":                validatePositive,
+		"thumb_epub_max_size":                validateNonNeg,
+		"thumb_external_concurrency":         validateNonNeg,
+		"archive_extract_max_entry_size":     validateNonNeg,
+		"media_meta_xmp_size_local":          validateNonNeg,
+		"media_meta_xmp_size_remote":         validateNonNeg,
+		"media_meta_pdf_size_local":          validateNonNeg,
+		"media_meta_pdf_size_remote":         validateNonNeg,
+		"media_meta_audio_analysis_max_size": validateNonNeg,
+		"avatar_size":                        validatePositive,
+		"custom_props":                       validateCustomProps,
+	}
+)
+
+func init() {
+	for name := range DefaultSettings {
+		switch {
+		case strings.HasPrefix(name, "cron_"):
+			settingValidators[name] = validateCron
+		case strings.HasPrefix(name, "queue_"):
+			switch {
+			case strings.HasSuffix(name, "_worker_num"), strings.HasSuffix(name, "_max_execution"):
+				settingValidators[name] = validatePositive
+			case strings.HasSuffix(name, "_backoff_factor"):
+				settingValidators[name] = validateFloatMin(1)
+			case strings.HasSuffix(name, "_backoff_max_duration"), strings.HasSuffix(name, "_max_retry"),
+				strings.HasSuffix(name, "_retry_delay"):
+				settingValidators[name] = validateNonNeg
+			}
+		}
+	}
+}
+
+// ValidateSettings validates settings with validators registered by setting name, settings
+// without validator are accepted as is.
+func ValidateSettings(settings map[string]string) error {
+	for name, value := range settings {
+		validator, ok := settingValidators[name]
+		if !ok {
+			continue
+		}
+
+		if err := validator(value); err != nil {
+			return fmt.Errorf("%w %q: %s", ErrInvalidSetting, name, err)
+		}
+	}
+
+	return nil
+}
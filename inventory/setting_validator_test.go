@@ -0,0 +1,140 @@
+package inventory
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/cloudreve/Cloudreve/v4/inventory/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateSettings(t *testing.T) {
+	a := assert.New(t)
+
+	// Defaults are always valid
+	a.NoError(ValidateSettings(DefaultSettings))
+
+	valid := map[string]string{
+		"smtpPort":                    "465",
+		"cron_garbage_collect":        "*/5 * * * *",
+		"cron_entity_collect":         "@every 1h",
+		"thumb_width":                 "800",
+		"thumb_encode_quality":        "80",
+		"siteURL":                     "https://a.example.com,http://b.example.com:5212",
+		"tos_url":                     "",
+		"captcha_cap_asset_server":    "unpkg",
+		"queue_thumb_backoff_factor":  "1.5",
+		"queue_thumb_worker_num":      "4",
+		"not_validated_setting_value": "anything",
+	}
+	a.NoError(ValidateSettings(valid))
+
+	invalid := map[string]string{
+		"smtpPort":                   "smtp",
+		"cron_garbage_collect":       "every day",
+		"thumb_width":                "0",
+		"thumb_encode_quality":       "101",
+		"thumb_encode_method":        "gif",
+		"siteURL":                    "/relative",
+		"tos_url":                    "ftp://example.com",
+		"queue_thumb_backoff_factor": "fast",
+		"queue_thumb_worker_num":     "0",
+		"smtpEncryption":             "true",
+		"captcha_cap_asset_server":   "cdnjs",
+	}
+	for name, value := range invalid {
+		err := ValidateSettings(map[string]string{name: value})
+		a.ErrorIs(err, ErrInvalidSetting, name)
+		if err != nil {
+			a.Contains(err.Error(), name)
+		}
+	}
+}
+
+func TestSettingClient_SetValidates(t *testing.T) {
+	a := assert.New(t)
+	c, _ := newTestSettingClient(t, map[string]string{"smtpPort": "25", "siteName": "old"})
+
+	err := c.Set(context.Background(), map[string]string{"smtpPort": "abc", "siteName": "new"})
+	a.ErrorIs(err, ErrInvalidSetting)
+
+	// Nothing is written
+	res, err := c.Gets(context.Background(), []string{"smtpPort", "siteName"})
+	a.NoError(err)
+	a.Equal(map[string]string{"smtpPort": "25", "siteName": "old"}, res)
+}
+
+func TestValidateSettings_CustomProps(t *testing.T) {
+	a := assert.New(t)
+
+	a.NoError(ValidateSettings(map[string]string{
+		"custom_props": `[{"id":"isbn","name":"ISBN","type":"text","pattern":"97[89]-\\d{10}"}]`,
+	}))
+
+	invalid := map[string]string{
+		"bad pattern":  `[{"id":"isbn","name":"ISBN","type":"text","pattern":"97[89"}]`,
+		"wrong type":   `[{"id":"score","name":"Score","type":"number","pattern":"\\d+"}]`,
+		"invalid json": `{"id":"isbn"}`,
+	}
+	for name, value := range invalid {
+		err := ValidateSettings(map[string]string{"custom_props": value})
+		a.ErrorIs(err, ErrInvalidSetting, name)
+	}
+
+	err := ValidateSettings(map[string]string{"custom_props": invalid["bad pattern"]})
+	a.ErrorContains(err, `"isbn"`)
+
+	// Pattern is anchored
+	prop := types.CustomProps{ID: "code", Type: types.CustomPropsTypeText, Pattern: `\d{3}|[A-Z]{2}`}
+	re, err := prop.PatternRegexp()
+	a.NoError(err)
+	a.True(re.MatchString("123"))
+	a.True(re.MatchString("AB"))
+	a.False(re.MatchString("1234"))
+	a.False(re.MatchString("xAB"))
+}
+
+func TestValidateSettings_CustomPropsScope(t *testing.T) {
+	a := assert.New(t)
+
+	a.NoError(ValidateSettings(map[string]string{
+		"custom_props": `[{"id":"camera","name":"Camera","type":"text","file_type":"file","exts":["jpg",".HEIC"]}]`,
+	}))
+	a.ErrorIs(ValidateSettings(map[string]string{
+		"custom_props": `[{"id":"camera","name":"Camera","type":"text","file_type":"image"}]`,
+	}), ErrInvalidSetting)
+	a.ErrorIs(ValidateSettings(map[string]string{
+		"custom_props": `[{"id":"camera","name":"Camera","type":"text","file_type":"folder","exts":["jpg"]}]`,
+	}), ErrInvalidSetting)
+
+	var props []types.CustomProps
+	a.NoError(json.Unmarshal([]byte(`[{"id":"camera","name":"Camera","type":"text","exts":["jpg",".HEIC"]},`+
+		`{"id":"owner","name":"Owner","type":"text","file_type":"folder"}]`), &props))
+	camera, owner := props[0], props[1]
+	a.True(camera.AppliesTo(types.FileTypeFile, "a.JPG"))
+	a.True(camera.AppliesTo(types.FileTypeFile, "a.heic"))
+	a.False(camera.AppliesTo(types.FileTypeFile, "a.png"))
+	a.False(camera.AppliesTo(types.FileTypeFolder, "album.jpg"))
+	a.True(owner.AppliesTo(types.FileTypeFolder, "docs"))
+	a.False(owner.AppliesTo(types.FileTypeFile, "docs"))
+
+	// Existing props without scope stay global
+	props = nil
+	a.NoError(json.Unmarshal([]byte(DefaultSettings["custom_props"]), &props))
+	for _, prop := range props {
+		a.True(prop.AppliesTo(types.FileTypeFile, "a.txt"), prop.ID)
+		a.True(prop.AppliesTo(types.FileTypeFolder, "a"), prop.ID)
+	}
+}
+
+func TestValidateSettings_CapAssetServer(t *testing.T) {
+	a := assert.New(t)
+	for _, value := range []string{"jsdelivr", "unpkg", "https://assets.example.com/cap", "http://10.0.0.1:8080/"} {
+		a.NoError(ValidateSettings(map[string]string{"captcha_cap_asset_server": value}), value)
+	}
+
+	for _, value := range []string{"", "cdnjs", "assets.example.com", "/cap", "ftp://assets.example.com", "https://a.example.com,https://b.example.com"} {
+		a.ErrorIs(ValidateSettings(map[string]string{"captcha_cap_asset_server": value}), ErrInvalidSetting, value)
+	}
+}
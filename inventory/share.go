@@ -10,6 +10,7 @@ import (
 	"github.com/cloudreve/Cloudreve/v4/ent"
 	"github.com/cloudreve/Cloudreve/v4/ent/file"
 	"github.com/cloudreve/Cloudreve/v4/ent/predicate"
+	"github.com/cloudreve/Cloudreve/v4/ent/schema"
 	"github.com/cloudreve/Cloudreve/v4/ent/share"
 	"github.com/cloudreve/Cloudreve/v4/ent/user"
 	"github.com/cloudreve/Cloudreve/v4/pkg/conf"
@@ -161,6 +162,7 @@ func (c *shareClient) GetByHashID(ctx context.Context, idRaw string) (*ent.Share
 }
 
 func (c *shareClient) GetByID(ctx context.Context, id int) (*ent.Share, error) {
+	ctx = withShareFileCtx(ctx)
 	s, err := withShareEagerLoading(ctx, c.client.Share.Query().Where(share.ID(id))).First(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query share %d: %w", id, err)
@@ -170,6 +172,7 @@ func (c *shareClient) GetByID(ctx context.Context, id int) (*ent.Share, error) {
 }
 
 func (c *shareClient) GetByIDUser(ctx context.Context, id, uid int) (*ent.Share, error) {
+	ctx = withShareFileCtx(ctx)
 	s, err := withShareEagerLoading(ctx, c.client.Share.Query().
 		Where(share.ID(id))).
 		Where(share.HasUserWith(user.ID(uid))).First(ctx)
@@ -181,6 +184,7 @@ func (c *shareClient) GetByIDUser(ctx context.Context, id, uid int) (*ent.Share,
 }
 
 func (c *shareClient) GetByIDs(ctx context.Context, ids []int) ([]*ent.Share, error) {
+	ctx = withShareFileCtx(ctx)
 	s, err := withShareEagerLoading(ctx, c.client.Share.Query().Where(share.IDIn(ids...))).All(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query shares %v: %w", ids, err)
@@ -231,7 +235,7 @@ func IsValidShare(share *ent.Share) error {
 
 	// Check source file status
 	file, err := share.Edges.FileOrErr()
-	if err != nil || file.FileChildren == 0 || file.OwnerID != owner.ID {
+	if err != nil || file.FileChildren == 0 || file.DeletedAt != nil || file.OwnerID != owner.ID {
 		// Source file already deleted
 		return ErrSourceFileInvalid
 	}
@@ -250,6 +254,7 @@ func IsShareExpired(share *ent.Share) error {
 }
 
 func (c *shareClient) List(ctx context.Context, args *ListShareArgs) (*ListShareResult, error) {
+	ctx = withShareFileCtx(ctx)
 	rawQuery := c.listQuery(args)
 	query := withShareEagerLoading(ctx, rawQuery)
 
@@ -410,6 +415,16 @@ func getShareOrderOption(args *ListShareArgs) []share.OrderOption {
 	}
 }
 
+// withShareFileCtx makes shared files in trash bin visible to eager loading, so that
+// shares of trashed files can still be listed and resolved as invalid.
+func withShareFileCtx(ctx context.Context) context.Context {
+	if v, ok := ctx.Value(LoadShareFile{}).(bool); ok && v {
+		return schema.IncludeDeletedFiles(ctx)
+	}
+
+	return ctx
+}
+
 func withShareEagerLoading(ctx context.Context, q *ent.ShareQuery) *ent.ShareQuery {
 	if v, ok := ctx.Value(LoadShareFile{}).(bool); ok && v {
 		q.WithFile(func(q *ent.FileQuery) {
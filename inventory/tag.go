@@ -0,0 +1,124 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cloudreve/Cloudreve/v4/ent"
+	"github.com/cloudreve/Cloudreve/v4/ent/file"
+	"github.com/cloudreve/Cloudreve/v4/ent/metadata"
+	"github.com/cloudreve/Cloudreve/v4/ent/predicate"
+)
+
+const (
+	// TagMetadataPrefix is the metadata key prefix of file tags, tag value is its color.
+	TagMetadataPrefix = "tag:"
+	// TagPathSeparator separates levels of nested tags, e.g. `travel/japan/tokyo`.
+	TagPathSeparator = "/"
+)
+
+// TagNode is a node in the tag tree. Color is the effective color of the tag: its own color,
+// or the color of the nearest colored ancestor if not set, in which case Inherited is true.
+// Count is the number of files tagged with this tag or any of its descendants.
+type TagNode struct {
+	Name      string     `json:"name"`
+	Path      string     `json:"path"`
+	Color     string     `json:"color,omitempty"`
+	Inherited bool       `json:"inherited,omitempty"`
+	Count     int        `json:"count"`
+	Children  []*TagNode `json:"children,omitempty"`
+
+	files map[int]struct{}
+}
+
+// ValidateTagPath checks a tag path (metadata key without prefix) consists of non-empty levels.
+func ValidateTagPath(path string) error {
+	if path == "" {
+		return fmt.Errorf("empty tag")
+	}
+
+	for _, level := range strings.Split(path, TagPathSeparator) {
+		if level == "" {
+			return fmt.Errorf("empty level in tag %q", path)
+		}
+
+		if strings.TrimSpace(level) != level {
+			return fmt.Errorf("tag level %q has leading or trailing spaces", level)
+		}
+	}
+
+	return nil
+}
+
+// metadataNamePredicate matches metadata by name. For tags, descendants of the tag are also matched.
+func metadataNamePredicate(key string) predicate.Metadata {
+	if strings.HasPrefix(key, TagMetadataPrefix) && key != TagMetadataPrefix {
+		return metadata.Or(metadata.NameEQ(key), metadata.NameHasPrefix(key+TagPathSeparator))
+	}
+
+	return metadata.NameEQ(key)
+}
+
+func (f *fileClient) ListTags(ctx context.Context, ownerID int) ([]*ent.Metadata, error) {
+	return f.client.Metadata.Query().
+		Where(
+			metadata.NameHasPrefix(TagMetadataPrefix),
+			metadata.IsPublic(true),
+			metadata.HasFileWith(file.OwnerID(ownerID)),
+		).
+		Order(ent.Desc(metadata.FieldID)).
+		All(ctx)
+}
+
+// BuildTagTree builds tag tree from tag metadata. If a tag is set with different colors on
+// different files, the first one in tags is used.
+func BuildTagTree(tags []*ent.Metadata) []*TagNode {
+	root := &TagNode{}
+	nodes := make(map[string]*TagNode)
+	for _, tag := range tags {
+		path := strings.TrimPrefix(tag.Name, TagMetadataPrefix)
+		if ValidateTagPath(path) != nil {
+			continue
+		}
+
+		parent := root
+		levels := strings.Split(path, TagPathSeparator)
+		for i, level := range levels {
+			nodePath := strings.Join(levels[:i+1], TagPathSeparator)
+			node, ok := nodes[nodePath]
+			if !ok {
+				node = &TagNode{Name: level, Path: nodePath, files: make(map[int]struct{})}
+				nodes[nodePath] = node
+				parent.Children = append(parent.Children, node)
+			}
+
+			node.files[tag.FileID] = struct{}{}
+			parent = node
+		}
+
+		if parent.Color == "" && tag.Value != "" {
+			parent.Color = tag.Value
+		}
+	}
+
+	finalizeTagNodes(root.Children, "")
+	return root.Children
+}
+
+func finalizeTagNodes(nodes []*TagNode, parentColor string) {
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+
+	for _, node := range nodes {
+		node.Count = len(node.files)
+		if node.Color == "" && parentColor != "" {
+			node.Color = parentColor
+			node.Inherited = true
+		}
+
+		finalizeTagNodes(node.Children, node.Color)
+	}
+}
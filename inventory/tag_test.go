@@ -0,0 +1,103 @@
+package inventory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudreve/Cloudreve/v4/ent"
+	"github.com/cloudreve/Cloudreve/v4/ent/enttest"
+	"github.com/cloudreve/Cloudreve/v4/ent/file"
+	"github.com/cloudreve/Cloudreve/v4/inventory/types"
+	"github.com/cloudreve/Cloudreve/v4/pkg/boolset"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateTagPath(t *testing.T) {
+	a := assert.New(t)
+	a.NoError(ValidateTagPath("travel"))
+	a.NoError(ValidateTagPath("travel/japan/tokyo"))
+	a.NoError(ValidateTagPath("to do"))
+	a.Error(ValidateTagPath(""))
+	a.Error(ValidateTagPath("/travel"))
+	a.Error(ValidateTagPath("travel/"))
+	a.Error(ValidateTagPath("travel//tokyo"))
+	a.Error(ValidateTagPath("travel/ japan"))
+}
+
+func TestBuildTagTree(t *testing.T) {
+	a := assert.New(t)
+	tags := []*ent.Metadata{
+		{Name: "tag:travel/japan/tokyo", FileID: 1},
+		{Name: "tag:travel", Value: "#ff0000", FileID: 2},
+		{Name: "tag:travel/japan", Value: "#00ff00", FileID: 3},
+		{Name: "tag:travel/japan", Value: "#0000ff", FileID: 4},
+		{Name: "tag:travel/france", FileID: 1},
+		{Name: "tag:work", FileID: 5},
+		{Name: "tag:", FileID: 6},
+	}
+
+	tree := BuildTagTree(tags)
+	if !a.Len(tree, 2) {
+		return
+	}
+
+	travel := tree[0]
+	a.Equal("travel", travel.Name)
+	a.Equal("#ff0000", travel.Color)
+	a.False(travel.Inherited)
+	a.Equal(4, travel.Count)
+	if a.Len(travel.Children, 2) {
+		france, japan := travel.Children[0], travel.Children[1]
+		a.Equal("travel/france", france.Path)
+		a.Equal("#ff0000", france.Color)
+		a.True(france.Inherited)
+
+		// Own color overrides parent, first color wins
+		a.Equal("#00ff00", japan.Color)
+		a.False(japan.Inherited)
+		a.Equal(3, japan.Count)
+		if a.Len(japan.Children, 1) {
+			a.Equal("#00ff00", japan.Children[0].Color)
+			a.True(japan.Children[0].Inherited)
+			a.Equal(1, japan.Children[0].Count)
+		}
+	}
+
+	// Flat tag without color
+	a.Equal(&TagNode{Name: "work", Path: "work", Count: 1, files: map[int]struct{}{5: {}}}, tree[1])
+}
+
+func TestFileClient_SearchNestedTags(t *testing.T) {
+	a := assert.New(t)
+	ctx := context.Background()
+	client := enttest.Open(t, "sqlite3", "file:"+t.Name()+"?mode=memory&cache=shared")
+	defer client.Close()
+
+	g := client.Group.Create().SetName("g").SetPermissions(&boolset.BooleanSet{}).SaveX(ctx)
+	u := client.User.Create().SetEmail("a@example.com").SetNick("a").SetGroup(g).SaveX(ctx)
+	newFile := func(name string, tags ...string) {
+		f := client.File.Create().SetName(name).SetType(int(types.FileTypeFile)).SetOwner(u).SaveX(ctx)
+		for _, tag := range tags {
+			client.Metadata.Create().SetFile(f).SetName(TagMetadataPrefix + tag).SetValue("").SetIsPublic(true).SaveX(ctx)
+		}
+	}
+	newFile("tokyo.jpg", "travel/japan/tokyo")
+	newFile("paris.jpg", "travel/france")
+	newFile("plan.txt", "travel")
+	newFile("travel-agency.txt", "travel-agency")
+	newFile("work.txt", "work")
+
+	fc := NewFileClient(client, "sqlite", nil).(*fileClient)
+	search := func(key string) []string {
+		q := fc.searchQuery(client.File.Query(), &SearchFileParameters{Metadata: []MetadataFilter{{Key: key}}}, []*ent.File{nil}, u.ID)
+		return q.Order(ent.Asc(file.FieldName)).Select(file.FieldName).StringsX(ctx)
+	}
+
+	a.Equal([]string{"paris.jpg", "plan.txt", "tokyo.jpg"}, search("tag:travel"))
+	a.Equal([]string{"tokyo.jpg"}, search("tag:travel/japan"))
+	a.Equal([]string{"work.txt"}, search("tag:work"))
+
+	tags, err := fc.ListTags(ctx, u.ID)
+	a.NoError(err)
+	a.Len(tags, 5)
+}
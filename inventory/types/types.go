@@ -1,6 +1,10 @@
 package types
 
 import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"time"
 )
 
@@ -36,6 +40,8 @@ type (
 		MaxWalkedFiles        int                    `json:"max_walked_files,omitempty"`
 		TrashRetention        int                    `json:"trash_retention,omitempty"`
 		RedirectedSource      bool                   `json:"redirected_source,omitempty"`
+		// EmailRateLimit is the max number of emails triggered by a user per hour, 0 for unlimited.
+		EmailRateLimit int `json:"email_rate_limit,omitempty"`
 	}
 
 	// PolicySetting 非公有的存储策略属性
@@ -64,6 +70,8 @@ type (
 		Region string `json:"region,omitempty"`
 		// ServerSideEndpoint 服务端请求使用的 Endpoint，为空时使用 Policy.Server 字段
 		ServerSideEndpoint string `json:"server_side_endpoint,omitempty"`
+		// CDNDomain replaces host of download and thumbnail URLs (KS3).
+		CDNDomain string `json:"cdn_domain,omitempty"`
 		// 分片上传的分片大小
 		ChunkSize int64 `json:"chunk_size,omitempty"`
 		// 每秒对存储端的 API 请求上限
@@ -103,6 +111,13 @@ type (
 		QiniuUploadCdn bool `json:"qiniu_upload_cdn,omitempty"`
 		// ChunkConcurrency the number of chunks to upload concurrently.
 		ChunkConcurrency int `json:"chunk_concurrency,omitempty"`
+		// ObjectTags tags applied to uploaded objects (KS3), values support {uid} and {policy} placeholders.
+		ObjectTags map[string]string `json:"object_tags,omitempty"`
+		// AutoApplyCors whether to apply CORS rules to the bucket when policy is saved.
+		AutoApplyCors bool `json:"auto_apply_cors,omitempty"`
+		// VerifyUploadHash whether to verify ETag of uploaded object against the hash supplied by
+		// client when completing an upload.
+		VerifyUploadHash bool `json:"verify_upload_hash,omitempty"`
 	}
 
 	FileType         int
@@ -242,6 +257,7 @@ const (
 	EntityTypeVersion EntityType = iota
 	EntityTypeThumbnail
 	EntityTypeLivePhoto
+	EntityTypeAnimatedThumbnail
 )
 
 func FileTypeFromString(s string) FileType {
@@ -320,7 +336,12 @@ type (
 
 type (
 	CustomPropsType string
-	CustomProps     struct {
+	// CustomProps defines a custom file property. Max and Min are length limits for text and
+	// link, value limits for number and rating, and Unix timestamp (in seconds) limits for date.
+	// Pattern is an optional regular expression the whole text value must match. FileType
+	// ("file" or "folder") and Exts optionally scope the prop to matching files, props without
+	// scope apply to all files.
+	CustomProps struct {
 		ID      string          `json:"id"`
 		Name    string          `json:"name"`
 		Type    CustomPropsType `json:"type"`
@@ -329,9 +350,51 @@ type (
 		Default string          `json:"default,omitempty"`
 		Options []string        `json:"options,omitempty"`
 		Icon    string          `json:"icon,omitempty"`
+		Pattern string          `json:"pattern,omitempty"`
+
+		FileType string   `json:"file_type,omitempty"`
+		Exts     []string `json:"exts,omitempty"`
 	}
 )
 
+// AppliesTo returns whether the prop can be set on a file of given type and name.
+func (p *CustomProps) AppliesTo(fileType FileType, name string) bool {
+	if p.FileType != "" && FileTypeFromString(p.FileType) != fileType {
+		return false
+	}
+
+	if len(p.Exts) > 0 {
+		if fileType != FileTypeFile {
+			return false
+		}
+
+		ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(name)), ".")
+		for _, e := range p.Exts {
+			if strings.EqualFold(strings.TrimPrefix(e, "."), ext) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return true
+}
+
+// PatternRegexp compiles Pattern anchored to match the whole value, returns nil if no pattern set.
+func (p *CustomProps) PatternRegexp() (*regexp.Regexp, error) {
+	if p.Pattern == "" {
+		return nil, nil
+	}
+
+	re, err := regexp.Compile("^(?:" + p.Pattern + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern of custom props %q: %w", p.ID, err)
+	}
+
+	return re, nil
+}
+
 const (
 	CustomPropsTypeText        = "text"
 	CustomPropsTypeNumber      = "number"
@@ -340,6 +403,7 @@ const (
 	CustomPropsTypeMultiSelect = "multi_select"
 	CustomPropsTypeLink        = "link"
 	CustomPropsTypeRating      = "rating"
+	CustomPropsTypeDate        = "date"
 )
 
 const (
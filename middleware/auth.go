@@ -1,7 +1,9 @@
 package middleware
 
 import (
+	"crypto/subtle"
 	"net/http"
+	"strings"
 
 	"github.com/cloudreve/Cloudreve/v4/application/dependency"
 	"github.com/cloudreve/Cloudreve/v4/ent"
@@ -44,6 +46,27 @@ func SignRequired(authInstance auth.Auth) gin.HandlerFunc {
 	}
 }
 
+// MetricsAuth 验证监控指标抓取凭证，未设置凭证时允许匿名抓取
+func MetricsAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		dep := dependency.FromContext(c)
+		token := dep.SettingProvider().MetricsToken(c)
+		if token == "" {
+			c.Next()
+			return
+		}
+
+		provided := strings.TrimPrefix(c.GetHeader(auth.AuthorizationHeader), auth.TokenHeaderPrefix)
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			c.JSON(http.StatusUnauthorized, serializer.ErrWithDetails(c, serializer.CodeCredentialInvalid, "Invalid metrics token", nil))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
 // CurrentUser 获取登录用户
 func CurrentUser() gin.HandlerFunc {
 	return func(c *gin.Context) {
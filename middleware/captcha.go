@@ -3,6 +3,7 @@ package middleware
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"net/url"
@@ -15,24 +16,29 @@ import (
 	request2 "github.com/cloudreve/Cloudreve/v4/pkg/request"
 	"github.com/cloudreve/Cloudreve/v4/pkg/serializer"
 	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
+	"github.com/cloudreve/Cloudreve/v4/service/basic"
 	"github.com/gin-gonic/gin"
-	"github.com/mojocn/base64Captcha"
 )
 
 type req struct {
 	Captcha string `json:"captcha"`
 	Ticket  string `json:"ticket"`
 	Randstr string `json:"randstr"`
+
+	GeeTestChallenge string `json:"geetest_challenge"`
+	GeeTestValidate  string `json:"geetest_validate"`
+	GeeTestSeccode   string `json:"geetest_seccode"`
 }
 
 const (
 	captchaNotMatch = "CAPTCHA not match."
 	captchaRefresh  = "Verification failed, please refresh the page and retry."
 
-	tcCaptchaEndpoint = "captcha.tencentcloudapi.com"
 	turnstileEndpoint = "https://challenges.cloudflare.com/turnstile/v0/siteverify"
 )
 
+var hCaptchaEndpoint = "https://hcaptcha.com/siteverify"
+
 // CaptchaIDCtx defines keys for captcha ID
 type (
 	CaptchaIDCtx      struct{}
@@ -42,6 +48,10 @@ type (
 	capResponse struct {
 		Success bool `json:"success"`
 	}
+	hCaptchaResponse struct {
+		Success    bool     `json:"success"`
+		ErrorCodes []string `json:"error-codes"`
+	}
 )
 
 // CaptchaRequired 验证请求签名
@@ -71,13 +81,26 @@ func CaptchaRequired(enabled func(c *gin.Context) bool) gin.HandlerFunc {
 
 			c.Request.Body = io.NopCloser(bytes.NewReader(bodyData))
 			switch settings.CaptchaType(c) {
-			case setting.CaptchaNormal, setting.CaptchaTcaptcha:
-				if service.Ticket == "" || !base64Captcha.VerifyCaptcha(service.Ticket, service.Captcha) {
+			case setting.CaptchaNormal:
+				if !basic.VerifyCaptcha(dep.KV(), service.Ticket, service.Captcha) {
 					c.JSON(200, serializer.ErrWithDetails(c, serializer.CodeCaptchaError, captchaNotMatch, err))
 					c.Abort()
 					return
 				}
 
+				break
+			case setting.CaptchaTcaptcha:
+				if err := basic.VerifyTcCaptcha(c, service.Ticket, service.Randstr, c.ClientIP()); err != nil {
+					l.Warning("Tencent Captcha verification failed: %s", err)
+					msg := "Captcha validation failed"
+					if errors.Is(err, basic.ErrCaptchaNotConfigured) {
+						msg = "Captcha configuration error"
+					}
+					c.JSON(200, serializer.ErrWithDetails(c, serializer.CodeCaptchaError, msg, err))
+					c.Abort()
+					return
+				}
+
 				break
 			case setting.CaptchaReCaptcha:
 				captchaSetting := settings.ReCaptcha(c)
@@ -131,6 +154,53 @@ func CaptchaRequired(enabled func(c *gin.Context) bool) gin.HandlerFunc {
 					return
 				}
 
+				break
+			case setting.CaptchaHCaptcha:
+				captchaSetting := settings.HCaptcha(c)
+				r := dep.RequestClient(
+					request2.WithContext(c),
+					request2.WithLogger(logging.FromContext(c)),
+					request2.WithHeader(http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}}),
+				)
+				formData := url.Values{}
+				formData.Set("secret", captchaSetting.Secret)
+				formData.Set("sitekey", captchaSetting.Key)
+				formData.Set("response", service.Ticket)
+				res, err := r.Request("POST", hCaptchaEndpoint, strings.NewReader(formData.Encode())).
+					CheckHTTPResponse(http.StatusOK).
+					GetResponse()
+				if err != nil {
+					l.Warning("hCaptcha verification failed: %s", err)
+					c.JSON(200, serializer.ErrWithDetails(c, serializer.CodeCaptchaError, "Captcha validation failed", err))
+					c.Abort()
+					return
+				}
+
+				var hCaptchaRes hCaptchaResponse
+				err = json.Unmarshal([]byte(res), &hCaptchaRes)
+				if err != nil {
+					l.Warning("hCaptcha verification failed: %s", err)
+					c.JSON(200, serializer.ErrWithDetails(c, serializer.CodeCaptchaError, "Captcha validation failed", err))
+					c.Abort()
+					return
+				}
+
+				if !hCaptchaRes.Success {
+					l.Debug("hCaptcha verification failed with error codes %v", hCaptchaRes.ErrorCodes)
+					c.JSON(200, serializer.ErrWithDetails(c, serializer.CodeCaptchaError, "Captcha validation failed", nil))
+					c.Abort()
+					return
+				}
+
+				break
+			case setting.CaptchaGeeTest:
+				if err := basic.VerifyGeeTest(c, service.GeeTestChallenge, service.GeeTestValidate, service.GeeTestSeccode); err != nil {
+					l.Warning("GeeTest verification failed: %s", err)
+					c.JSON(200, serializer.ErrWithDetails(c, serializer.CodeCaptchaError, "Captcha validation failed", err))
+					c.Abort()
+					return
+				}
+
 				break
 			case setting.CaptchaCap:
 				captchaSetting := settings.CapCaptcha(c)
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cloudreve/Cloudreve/v4/application/dependency"
+	"github.com/cloudreve/Cloudreve/v4/pkg/conf"
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type hCaptchaSettings struct {
+	setting.Provider
+}
+
+func (s *hCaptchaSettings) CaptchaType(ctx context.Context) setting.CaptchaType {
+	return setting.CaptchaHCaptcha
+}
+
+func (s *hCaptchaSettings) HCaptcha(ctx context.Context) *setting.HCaptcha {
+	return &setting.HCaptcha{Key: "site-key", Secret: "secret"}
+}
+
+type masterConfig struct {
+	conf.ConfigProvider
+}
+
+func (c *masterConfig) System() *conf.System {
+	return &conf.System{Mode: conf.MasterMode}
+}
+
+func TestCaptchaRequired_HCaptcha(t *testing.T) {
+	a := assert.New(t)
+	var form map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		form = map[string]string{"secret": r.PostForm.Get("secret"), "sitekey": r.PostForm.Get("sitekey")}
+		if r.PostForm.Get("response") == "valid" {
+			_, _ = w.Write([]byte(`{"success":true}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"success":false,"error-codes":["invalid-input-response"]}`))
+	}))
+	defer srv.Close()
+	oldEndpoint := hCaptchaEndpoint
+	hCaptchaEndpoint = srv.URL
+	defer func() { hCaptchaEndpoint = oldEndpoint }()
+
+	gin.SetMode(gin.TestMode)
+	dep := dependency.NewDependency(
+		dependency.WithSettingProvider(&hCaptchaSettings{}),
+		dependency.WithConfigProvider(&masterConfig{}),
+	)
+	r := gin.New()
+	r.ContextWithFallback = true
+	r.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), dependency.DepCtx{}, dep))
+	})
+	r.POST("/login", CaptchaRequired(func(c *gin.Context) bool { return true }), func(c *gin.Context) {
+		c.String(http.StatusOK, "passed")
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"ticket":"valid"}`)))
+	a.Equal("passed", rec.Body.String())
+	a.Equal(map[string]string{"secret": "secret", "sitekey": "site-key"}, form)
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"ticket":"invalid"}`)))
+	a.NotContains(rec.Body.String(), "passed")
+	a.Contains(rec.Body.String(), "Captcha validation failed")
+}
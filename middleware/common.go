@@ -3,12 +3,15 @@ package middleware
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/cloudreve/Cloudreve/v4/application/constants"
 	"github.com/cloudreve/Cloudreve/v4/application/dependency"
+	"github.com/cloudreve/Cloudreve/v4/inventory"
+	"github.com/cloudreve/Cloudreve/v4/inventory/types"
 	"github.com/cloudreve/Cloudreve/v4/pkg/auth/requestinfo"
 	"github.com/cloudreve/Cloudreve/v4/pkg/cluster"
 	"github.com/cloudreve/Cloudreve/v4/pkg/hashid"
@@ -18,6 +21,7 @@ import (
 	"github.com/cloudreve/Cloudreve/v4/pkg/util"
 	"github.com/gin-gonic/gin"
 	"github.com/gofrs/uuid"
+	"github.com/samber/lo"
 )
 
 // HashID 将给定对象的HashID转换为真实ID
@@ -136,6 +140,116 @@ func InitializeHandlingSlave() gin.HandlerFunc {
 	}
 }
 
+// RequestSizeLimit rejects requests with body larger than configured size with 413. Routes
+// in exempt (full path templates like /api/v4/file/upload/:sessionId/:index) are not limited.
+func RequestSizeLimit(exempt ...string) gin.HandlerFunc {
+	exemptRoutes := lo.SliceToMap(exempt, func(route string) (string, struct{}) {
+		return route, struct{}{}
+	})
+	return func(c *gin.Context) {
+		if _, ok := exemptRoutes[c.FullPath()]; ok || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+
+		limit := dependency.FromContext(c).SettingProvider().MaxRequestBodySize(c)
+		if limit <= 0 {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > limit {
+			AbortRequestTooLarge(c, limit)
+			return
+		}
+
+		// Body without known length is limited on read
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		c.Next()
+	}
+}
+
+// MaintenanceMode rejects write requests with 503 while maintenance mode is enabled. Admins,
+// read-only methods and routes under exempt prefixes (e.g. login) are allowed through.
+func MaintenanceMode(exempt ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions, "PROPFIND":
+			c.Next()
+			return
+		}
+
+		if !dependency.FromContext(c).SettingProvider().MaintenanceModeEnabled(c) {
+			c.Next()
+			return
+		}
+
+		for _, prefix := range exempt {
+			if strings.HasPrefix(c.FullPath(), prefix) {
+				c.Next()
+				return
+			}
+		}
+
+		if u := inventory.UserFromContext(c); u != nil && u.Edges.Group != nil &&
+			u.Edges.Group.Permissions.Enabled(int(types.GroupPermissionIsAdmin)) {
+			c.Next()
+			return
+		}
+
+		c.JSON(http.StatusServiceUnavailable, serializer.ErrWithDetails(c, serializer.CodeMaintenanceMode,
+			"Site is under maintenance, please try again later", nil))
+		c.Abort()
+	}
+}
+
+// AbortRequestTooLarge responds 413 for requests exceeding the body size limit.
+func AbortRequestTooLarge(c *gin.Context, limit int64) {
+	c.JSON(http.StatusRequestEntityTooLarge, serializer.ErrWithDetails(c, serializer.CodeRequestTooLarge,
+		fmt.Sprintf("Request body exceeds the size limit of %d bytes", limit), nil))
+	c.Abort()
+}
+
+// AccessLog writes structured access log of sampled requests to w if enabled.
+func AccessLog(w io.Writer) gin.HandlerFunc {
+	l := logging.NewAccessLogger(w)
+	sampler := logging.NewAccessSampler()
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		dep := dependency.FromContext(c)
+		config := dep.SettingProvider().AccessLog(c)
+		if !config.Enabled || !sampler.Sample(c.Request.URL.Path, config.SampleRates) {
+			return
+		}
+
+		params := lo.SliceToMap(c.Params, func(p gin.Param) (string, string) {
+			return p.Key, p.Value
+		})
+		path := logging.SanitizePath(c.Request.URL.Path, c.FullPath(), params, config.SensitiveParams)
+		if query := logging.SanitizeQuery(c.Request.URL.RawQuery, config.SensitiveParams); query != "" {
+			path += "?" + query
+		}
+
+		record := &logging.AccessRecord{
+			Time:     start,
+			Method:   c.Request.Method,
+			Path:     path,
+			Status:   c.Writer.Status(),
+			Duration: time.Since(start),
+			UserID:   inventory.UserIDFromContext(c),
+			BytesIn:  max(c.Request.ContentLength, 0),
+			BytesOut: int64(max(c.Writer.Size(), 0)),
+		}
+		if info := requestinfo.RequestInfoFromContext(c); info != nil {
+			record.IP = info.IP
+		}
+
+		l.Log(record, string(config.Format), config.Fields)
+	}
+}
+
 // Logging logs incoming request info
 func Logging() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -0,0 +1,152 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cloudreve/Cloudreve/v4/application/dependency"
+	"github.com/cloudreve/Cloudreve/v4/ent"
+	"github.com/cloudreve/Cloudreve/v4/inventory"
+	"github.com/cloudreve/Cloudreve/v4/inventory/types"
+	"github.com/cloudreve/Cloudreve/v4/pkg/boolset"
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type sizeLimitSettings struct {
+	setting.Provider
+	limit int64
+}
+
+func (s *sizeLimitSettings) MaxRequestBodySize(ctx context.Context) int64 {
+	return s.limit
+}
+
+func newSizeLimitRouter(limit int64) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	dep := dependency.NewDependency(dependency.WithSettingProvider(&sizeLimitSettings{limit: limit}))
+	r := gin.New()
+	r.ContextWithFallback = true
+	r.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), dependency.DepCtx{}, dep))
+	})
+	r.Use(RequestSizeLimit("/upload/:id"))
+
+	readAll := func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.Status(http.StatusRequestEntityTooLarge)
+			return
+		}
+		c.String(http.StatusOK, "%d", len(body))
+	}
+	r.POST("/json", readAll)
+	r.POST("/upload/:id", readAll)
+	return r
+}
+
+func TestRequestSizeLimit(t *testing.T) {
+	a := assert.New(t)
+	r := newSizeLimitRouter(16)
+
+	// Within limit
+	{
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/json", strings.NewReader(`{"a":"b"}`)))
+		a.Equal(http.StatusOK, rec.Code)
+		a.Equal("9", rec.Body.String())
+	}
+
+	// Oversized with known length
+	{
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/json", strings.NewReader(`{"a":"0123456789abcdef"}`)))
+		a.Equal(http.StatusRequestEntityTooLarge, rec.Code)
+		a.Contains(rec.Body.String(), "413")
+	}
+
+	// Oversized with unknown length is limited on read
+	{
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/json", io.NopCloser(strings.NewReader(`{"a":"0123456789abcdef"}`)))
+		req.ContentLength = -1
+		r.ServeHTTP(rec, req)
+		a.Equal(http.StatusRequestEntityTooLarge, rec.Code)
+	}
+
+	// Upload routes are exempt
+	{
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/upload/1", strings.NewReader(strings.Repeat("a", 64))))
+		a.Equal(http.StatusOK, rec.Code)
+		a.Equal("64", rec.Body.String())
+	}
+}
+
+func TestRequestSizeLimit_Disabled(t *testing.T) {
+	a := assert.New(t)
+	r := newSizeLimitRouter(0)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/json", strings.NewReader(strings.Repeat("a", 64))))
+	a.Equal(http.StatusOK, rec.Code)
+}
+
+type maintenanceSettings struct {
+	setting.Provider
+	enabled bool
+}
+
+func (s *maintenanceSettings) MaintenanceModeEnabled(ctx context.Context) bool {
+	return s.enabled
+}
+
+func TestMaintenanceMode(t *testing.T) {
+	a := assert.New(t)
+	gin.SetMode(gin.TestMode)
+	settings := &maintenanceSettings{enabled: true}
+	dep := dependency.NewDependency(dependency.WithSettingProvider(settings))
+	adminPermissions := &boolset.BooleanSet{}
+	boolset.Set(types.GroupPermissionIsAdmin, true, adminPermissions)
+	admin := &ent.User{Edges: ent.UserEdges{Group: &ent.Group{Permissions: adminPermissions}}}
+	user := &ent.User{Edges: ent.UserEdges{Group: &ent.Group{Permissions: &boolset.BooleanSet{}}}}
+
+	var current *ent.User
+	r := gin.New()
+	r.ContextWithFallback = true
+	r.Use(func(c *gin.Context) {
+		ctx := context.WithValue(c.Request.Context(), dependency.DepCtx{}, dep)
+		c.Request = c.Request.WithContext(context.WithValue(ctx, inventory.UserCtx{}, current))
+	})
+	r.Use(MaintenanceMode("/session"))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	r.GET("/file", ok)
+	r.POST("/file", ok)
+	r.POST("/session/token", ok)
+
+	serve := func(method, path string) int {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec.Code
+	}
+
+	current = user
+	a.Equal(http.StatusOK, serve(http.MethodGet, "/file"))
+	a.Equal(http.StatusServiceUnavailable, serve(http.MethodPost, "/file"))
+	a.Equal(http.StatusOK, serve(http.MethodPost, "/session/token"))
+
+	current = admin
+	a.Equal(http.StatusOK, serve(http.MethodPost, "/file"))
+
+	current = nil
+	a.Equal(http.StatusServiceUnavailable, serve(http.MethodPost, "/file"))
+
+	settings.enabled = false
+	current = user
+	a.Equal(http.StatusOK, serve(http.MethodPost, "/file"))
+}
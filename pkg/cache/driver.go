@@ -2,6 +2,8 @@ package cache
 
 import (
 	"encoding/gob"
+
+	"github.com/cloudreve/Cloudreve/v4/pkg/metrics"
 )
 
 func init() {
@@ -34,3 +36,13 @@ type Driver interface {
 	// Remove all entries
 	DeleteAll() error
 }
+
+// observeGet records result of a single key lookup.
+func observeGet(store string, hit bool) {
+	if hit {
+		metrics.ObserveCache(store, 1, 0)
+		return
+	}
+
+	metrics.ObserveCache(store, 0, 1)
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/cloudreve/Cloudreve/v4/pkg/metrics"
 	"github.com/cloudreve/Cloudreve/v4/pkg/util"
 )
 
@@ -23,7 +24,10 @@ type itemWithTTL struct {
 	Value   interface{}
 }
 
-const DefaultCacheFile = "cache_persist.bin"
+const (
+	DefaultCacheFile = "cache_persist.bin"
+	memoStoreName    = "memory"
+)
 
 func newItem(value interface{}, expires int) itemWithTTL {
 	expires64 := int64(expires)
@@ -57,15 +61,31 @@ func getValue(item any, ok bool) (any, bool) {
 
 // GarbageCollect 回收已过期的缓存
 func (store *MemoStore) GarbageCollect(l logging.Logger) {
+	store.GarbageCollectPrefix(l, "")
+}
+
+// GarbageCollectPrefix removes expired entries with given key prefix, returns number of
+// removed entries.
+func (store *MemoStore) GarbageCollectPrefix(l logging.Logger, prefix string) int {
+	collected := 0
+	now := time.Now().Unix()
 	store.Store.Range(func(key, value any) bool {
+		k, ok := key.(string)
+		if !ok || !strings.HasPrefix(k, prefix) {
+			return true
+		}
+
 		if item, ok := value.(itemWithTTL); ok {
-			if item.Expires > 0 && item.Expires < time.Now().Unix() {
-				l.Debug("Cache %q is garbage collected.", key.(string))
+			if item.Expires > 0 && item.Expires < now {
+				l.Debug("Cache %q is garbage collected.", k)
 				store.Store.Delete(key)
+				collected++
 			}
 		}
 		return true
 	})
+
+	return collected
 }
 
 // NewMemoStore 新建内存存储
@@ -91,7 +111,9 @@ func (store *MemoStore) Set(key string, value any, ttl int) error {
 
 // Get 取值
 func (store *MemoStore) Get(key string) (any, bool) {
-	return getValue(store.Store.Load(key))
+	value, ok := getValue(store.Store.Load(key))
+	observeGet(memoStoreName, ok)
+	return value, ok
 }
 
 // Gets 批量取值
@@ -107,6 +129,7 @@ func (store *MemoStore) Gets(keys []string, prefix string) (map[string]any, []st
 		}
 	}
 
+	metrics.ObserveCache(memoStoreName, len(res), len(notFound))
 	return res, notFound
 }
 
@@ -0,0 +1,43 @@
+package cache
+
+// NamespacedStore isolates keys of this instance in a shared backend by prefixing every
+// key with a namespace.
+type NamespacedStore struct {
+	Driver
+	namespace string
+}
+
+// NewNamespacedStore wraps given driver so that all keys are prefixed with namespace.
+func NewNamespacedStore(d Driver, namespace string) *NamespacedStore {
+	return &NamespacedStore{Driver: d, namespace: namespace}
+}
+
+// Set 存储值
+func (s *NamespacedStore) Set(key string, value any, ttl int) error {
+	return s.Driver.Set(s.namespace+key, value, ttl)
+}
+
+// Get 取值
+func (s *NamespacedStore) Get(key string) (any, bool) {
+	return s.Driver.Get(s.namespace + key)
+}
+
+// Gets 批量取值
+func (s *NamespacedStore) Gets(keys []string, prefix string) (map[string]any, []string) {
+	return s.Driver.Gets(keys, s.namespace+prefix)
+}
+
+// Sets 批量设置值
+func (s *NamespacedStore) Sets(values map[string]any, prefix string) error {
+	return s.Driver.Sets(values, s.namespace+prefix)
+}
+
+// Delete values by [Namespace + Prefix + key].
+func (s *NamespacedStore) Delete(prefix string, keys ...string) error {
+	return s.Driver.Delete(s.namespace+prefix, keys...)
+}
+
+// DeleteAll only removes keys in the namespace.
+func (s *NamespacedStore) DeleteAll() error {
+	return s.Driver.Delete(s.namespace)
+}
@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/cloudreve/Cloudreve/v4/pkg/conf"
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/gomodule/redigo/redis"
+	"github.com/rafaeljusto/redigomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamespacedStore(t *testing.T) {
+	a := assert.New(t)
+	backend := NewMemoStore("", logging.NewConsoleLogger(logging.LevelError))
+	a.NoError(backend.Set("other_app", "v", 0))
+	storeA := NewNamespacedStore(backend, "a:")
+	storeB := NewNamespacedStore(backend, "b:")
+
+	// Same keys do not collide
+	a.NoError(storeA.Set("captcha_session_1", "A", 0))
+	a.NoError(storeB.Set("captcha_session_1", "B", 0))
+	a.NoError(storeA.Sets(map[string]any{"1": "A1", "2": "A2"}, "setting_"))
+	a.NoError(storeB.Sets(map[string]any{"1": "B1"}, "setting_"))
+
+	val, ok := storeA.Get("captcha_session_1")
+	a.True(ok)
+	a.Equal("A", val)
+	val, ok = backend.Get("b:captcha_session_1")
+	a.True(ok)
+	a.Equal("B", val)
+
+	res, missed := storeB.Gets([]string{"1", "2"}, "setting_")
+	a.Equal(map[string]any{"1": "B1"}, res)
+	a.Equal([]string{"2"}, missed)
+
+	// Delete by keys and by prefix stay in namespace
+	a.NoError(storeA.Delete("setting_", "1"))
+	_, ok = storeB.Get("setting_1")
+	a.True(ok)
+	a.NoError(storeB.Delete("setting_"))
+	_, ok = storeA.Get("setting_2")
+	a.True(ok)
+	_, ok = storeB.Get("setting_1")
+	a.False(ok)
+
+	// DeleteAll only clears own namespace
+	a.NoError(storeA.DeleteAll())
+	_, ok = storeA.Get("captcha_session_1")
+	a.False(ok)
+	_, ok = storeB.Get("captcha_session_1")
+	a.True(ok)
+	_, ok = backend.Get("other_app")
+	a.True(ok)
+}
+
+func TestNamespacedStore_RedisDeleteAll(t *testing.T) {
+	a := assert.New(t)
+	conn := redigomock.NewConn()
+	store := NewNamespacedStore(&RedisStore{pool: &redis.Pool{
+		Dial: func() (redis.Conn, error) { return conn, nil },
+	}}, "a:")
+
+	conn.Command("SCAN", 0, "MATCH", "a:*", "COUNT", redisScanCount).Expect([]any{[]byte("0"), []any{[]byte("a:1")}})
+	del := conn.Command("DEL", "a:1").Expect(int64(1))
+	flush := conn.Command("FLUSHDB").Expect("OK")
+	a.NoError(store.DeleteAll())
+	a.Equal(1, conn.Stats(del))
+	a.Equal(0, conn.Stats(flush))
+}
+
+func TestNewRedisDriver_KeyPrefix(t *testing.T) {
+	a := assert.New(t)
+	l := logging.NewConsoleLogger(logging.LevelError)
+
+	a.IsType(&RedisStore{}, NewRedisDriver(l, &conf.Redis{Network: "tcp", DB: "0"}))
+
+	d := NewRedisDriver(l, &conf.Redis{Network: "tcp", DB: "0", KeyPrefix: "a:"})
+	if a.IsType(&NamespacedStore{}, d) {
+		a.Equal("a:", d.(*NamespacedStore).namespace)
+		a.IsType(&RedisStore{}, d.(*NamespacedStore).Driver)
+	}
+
+	d = NewRedisDriver(l, &conf.Redis{Mode: conf.RedisClusterMode, Nodes: []string{"127.0.0.1:7000"}, KeyPrefix: "a:"})
+	if a.IsType(&NamespacedStore{}, d) {
+		a.IsType(&RedisClusterStore{}, d.(*NamespacedStore).Driver)
+	}
+}
@@ -3,15 +3,28 @@ package cache
 import (
 	"bytes"
 	"encoding/gob"
+	"errors"
+	"fmt"
+	"net"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/cloudreve/Cloudreve/v4/pkg/conf"
 	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/cloudreve/Cloudreve/v4/pkg/metrics"
 
 	"github.com/gomodule/redigo/redis"
 )
 
+const (
+	redisStoreName       = "redis"
+	defaultRedisPoolSize = 10
+	redisIdleTimeout     = 240 * time.Second
+	redisDialTimeout     = 5 * time.Second
+	redisScanCount       = 1000
+)
+
 // RedisStore redis存储驱动
 type RedisStore struct {
 	pool *redis.Pool
@@ -45,12 +58,37 @@ func deserializer(value []byte) (any, error) {
 	return res.Value, nil
 }
 
+// NewRedisDriver creates Redis backed KV driver for the mode set in config, keys are
+// namespaced by KeyPrefix if set.
+func NewRedisDriver(l logging.Logger, redisConfig *conf.Redis) Driver {
+	size := redisConfig.PoolSize
+	if size <= 0 {
+		size = defaultRedisPoolSize
+	}
+
+	var d Driver
+	switch redisConfig.Mode {
+	case conf.RedisSentinelMode:
+		d = NewRedisSentinelStore(l, size, redisConfig)
+	case conf.RedisClusterMode:
+		d = NewRedisClusterStore(l, size, redisConfig)
+	default:
+		d = NewRedisStore(l, size, redisConfig)
+	}
+
+	if redisConfig.KeyPrefix != "" {
+		d = NewNamespacedStore(d, redisConfig.KeyPrefix)
+	}
+
+	return d
+}
+
 // NewRedisStore 创建新的redis存储
 func NewRedisStore(l logging.Logger, size int, redisConfig *conf.Redis) *RedisStore {
 	return &RedisStore{
 		pool: &redis.Pool{
 			MaxIdle:     size,
-			IdleTimeout: 240 * time.Second,
+			IdleTimeout: redisIdleTimeout,
 			TestOnBorrow: func(c redis.Conn, t time.Time) error {
 				_, err := c.Do("PING")
 				return err
@@ -64,11 +102,7 @@ func NewRedisStore(l logging.Logger, size int, redisConfig *conf.Redis) *RedisSt
 				c, err := redis.Dial(
 					redisConfig.Network,
 					redisConfig.Server,
-					redis.DialDatabase(db),
-					redis.DialPassword(redisConfig.Password),
-					redis.DialUsername(redisConfig.User),
-					redis.DialUseTLS(redisConfig.UseTLS),
-					redis.DialTLSSkipVerify(redisConfig.TLSSkipVerify),
+					append(redisDialOptions(redisConfig), redis.DialDatabase(db))...,
 				)
 				if err != nil {
 					l.Panic("Failed to create Redis connection: %s", err)
@@ -79,6 +113,164 @@ func NewRedisStore(l logging.Logger, size int, redisConfig *conf.Redis) *RedisSt
 	}
 }
 
+// NewRedisSentinelStore creates Redis store whose master is discovered from sentinels. The
+// master is resolved again on every new connection, and pooled connections to a demoted
+// master are dropped on borrow, so the store follows failovers.
+func NewRedisSentinelStore(l logging.Logger, size int, redisConfig *conf.Redis) *RedisStore {
+	sentinel := &redisSentinel{
+		addrs:      append([]string{}, redisConfig.Nodes...),
+		masterName: redisConfig.MasterName,
+		dial: func(addr string) (redis.Conn, error) {
+			return redis.Dial(redisConfig.Network, addr,
+				redis.DialPassword(redisConfig.SentinelPassword),
+				redis.DialConnectTimeout(redisDialTimeout),
+				redis.DialReadTimeout(redisDialTimeout),
+				redis.DialUseTLS(redisConfig.UseTLS),
+				redis.DialTLSSkipVerify(redisConfig.TLSSkipVerify),
+			)
+		},
+	}
+
+	return &RedisStore{
+		pool: &redis.Pool{
+			MaxIdle:     size,
+			IdleTimeout: redisIdleTimeout,
+			TestOnBorrow: func(c redis.Conn, t time.Time) error {
+				return checkRedisRole(c, "master")
+			},
+			Dial: func() (redis.Conn, error) {
+				db, err := strconv.Atoi(redisConfig.DB)
+				if err != nil {
+					return nil, err
+				}
+
+				addr, err := sentinel.masterAddr()
+				if err != nil {
+					l.Warning("Failed to resolve Redis master from sentinels: %s", err)
+					return nil, err
+				}
+
+				return redis.Dial(
+					redisConfig.Network,
+					addr,
+					append(redisDialOptions(redisConfig), redis.DialDatabase(db))...,
+				)
+			},
+		},
+	}
+}
+
+func redisDialOptions(redisConfig *conf.Redis) []redis.DialOption {
+	return []redis.DialOption{
+		redis.DialPassword(redisConfig.Password),
+		redis.DialUsername(redisConfig.User),
+		redis.DialUseTLS(redisConfig.UseTLS),
+		redis.DialTLSSkipVerify(redisConfig.TLSSkipVerify),
+	}
+}
+
+// redisSentinel resolves current master address from a list of sentinels.
+type redisSentinel struct {
+	mu         sync.Mutex
+	addrs      []string
+	masterName string
+	dial       func(addr string) (redis.Conn, error)
+}
+
+// masterAddr asks sentinels in order for the master address. The sentinel that answers is
+// moved to the front so that it is asked first next time.
+func (s *redisSentinel) masterAddr() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var lastErr error = errors.New("no sentinel configured")
+	for i, sentinelAddr := range s.addrs {
+		addr, err := s.queryMaster(sentinelAddr)
+		if err != nil {
+			lastErr = fmt.Errorf("sentinel %q: %w", sentinelAddr, err)
+			continue
+		}
+
+		s.addrs[0], s.addrs[i] = s.addrs[i], s.addrs[0]
+		return addr, nil
+	}
+
+	return "", lastErr
+}
+
+func (s *redisSentinel) queryMaster(sentinelAddr string) (string, error) {
+	c, err := s.dial(sentinelAddr)
+	if err != nil {
+		return "", err
+	}
+	defer c.Close()
+
+	res, err := redis.Strings(c.Do("SENTINEL", "get-master-addr-by-name", s.masterName))
+	if err != nil {
+		return "", err
+	}
+
+	if len(res) != 2 {
+		return "", fmt.Errorf("unexpected reply %v", res)
+	}
+
+	return net.JoinHostPort(res[0], res[1]), nil
+}
+
+// scanRedisKeys lists all keys matching pattern with SCAN, which unlike KEYS does not block
+// the server for long on large databases.
+func scanRedisKeys(rc redis.Conn, pattern string) ([]string, error) {
+	var keys []string
+	cursor := 0
+	for {
+		res, err := redis.Values(rc.Do("SCAN", cursor, "MATCH", pattern, "COUNT", redisScanCount))
+		if err != nil {
+			return nil, err
+		}
+
+		if len(res) != 2 {
+			return nil, fmt.Errorf("unexpected SCAN reply %v", res)
+		}
+
+		if cursor, err = redis.Int(res[0], nil); err != nil {
+			return nil, err
+		}
+
+		batch, err := redis.Strings(res[1], nil)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, batch...)
+		if cursor == 0 {
+			return keys, nil
+		}
+	}
+}
+
+// checkRedisRole returns error if the connected node does not have given role.
+func checkRedisRole(c redis.Conn, expected string) error {
+	res, err := redis.Values(c.Do("ROLE"))
+	if err != nil {
+		return err
+	}
+
+	if len(res) == 0 {
+		return errors.New("empty ROLE reply")
+	}
+
+	role, err := redis.String(res[0], nil)
+	if err != nil {
+		return err
+	}
+
+	if role != expected {
+		return fmt.Errorf("redis node role is %q, expected %q", role, expected)
+	}
+
+	return nil
+}
+
 // Set 存储值
 func (store *RedisStore) Set(key string, value any, ttl int) error {
 	rc := store.pool.Get()
@@ -116,14 +308,17 @@ func (store *RedisStore) Get(key string) (any, bool) {
 
 	v, err := redis.Bytes(rc.Do("GET", key))
 	if err != nil || v == nil {
+		observeGet(redisStoreName, false)
 		return nil, false
 	}
 
 	finalValue, err := deserializer(v)
 	if err != nil {
+		observeGet(redisStoreName, false)
 		return nil, false
 	}
 
+	observeGet(redisStoreName, true)
 	return finalValue, true
 
 }
@@ -157,6 +352,7 @@ func (store *RedisStore) Gets(keys []string, prefix string) (map[string]any, []s
 			res[keys[key]] = decoded
 		}
 	}
+	metrics.ObserveCache(redisStoreName, len(res), len(missed))
 	// 解码所得值
 	return res, missed
 }
@@ -203,7 +399,7 @@ func (store *RedisStore) Delete(prefix string, keys ...string) error {
 	// No key is presented, delete all keys with given prefix
 	if len(keys) == 0 {
 		// Fetch all key with given prefix
-		allPrefixKeys, err := redis.Strings(rc.Do("KEYS", prefix+"*"))
+		allPrefixKeys, err := scanRedisKeys(rc, prefix+"*")
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,468 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudreve/Cloudreve/v4/pkg/conf"
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/cloudreve/Cloudreve/v4/pkg/metrics"
+	"github.com/gomodule/redigo/redis"
+)
+
+const (
+	redisClusterSlots        = 16384
+	redisClusterMaxRedirects = 5
+)
+
+// RedisClusterStore is a KV driver backed by Redis Cluster. Keys are routed to the master
+// owning their hash slot, with one connection pool per node. Slot layout is refreshed when
+// a node replies with MOVED, so the store follows resharding and failovers.
+type RedisClusterStore struct {
+	l    logging.Logger
+	size int
+	dial func(addr string) (redis.Conn, error)
+
+	mu    sync.RWMutex
+	seeds []string
+	slots [redisClusterSlots]string
+	pools map[string]*redis.Pool
+}
+
+// NewRedisClusterStore creates Redis Cluster store using Nodes in config as seed nodes.
+// Redis Cluster only has database 0, DB in config is ignored.
+func NewRedisClusterStore(l logging.Logger, size int, redisConfig *conf.Redis) *RedisClusterStore {
+	return &RedisClusterStore{
+		l:     l,
+		size:  size,
+		seeds: redisConfig.Nodes,
+		pools: make(map[string]*redis.Pool),
+		dial: func(addr string) (redis.Conn, error) {
+			return redis.Dial(redisConfig.Network, addr, redisDialOptions(redisConfig)...)
+		},
+	}
+}
+
+// Set 存储值
+func (store *RedisClusterStore) Set(key string, value any, ttl int) error {
+	serialized, err := serializer(value)
+	if err != nil {
+		return err
+	}
+
+	if ttl > 0 {
+		_, err = store.do(key, "SETEX", key, ttl, serialized)
+	} else {
+		_, err = store.do(key, "SET", key, serialized)
+	}
+
+	return err
+}
+
+// Get 取值
+func (store *RedisClusterStore) Get(key string) (any, bool) {
+	v, err := redis.Bytes(store.do(key, "GET", key))
+	if err != nil || v == nil {
+		observeGet(redisStoreName, false)
+		return nil, false
+	}
+
+	finalValue, err := deserializer(v)
+	if err != nil {
+		observeGet(redisStoreName, false)
+		return nil, false
+	}
+
+	observeGet(redisStoreName, true)
+	return finalValue, true
+}
+
+// Gets 批量取值. Multi-key commands can only access keys in the same slot, keys are
+// fetched with one MGET per slot.
+func (store *RedisClusterStore) Gets(keys []string, prefix string) (map[string]any, []string) {
+	var res = make(map[string]any)
+	var missed = make([]string, 0, len(keys))
+
+	for _, group := range groupBySlot(keys, prefix) {
+		queryKeys := make([]string, len(group))
+		for i, key := range group {
+			queryKeys[i] = prefix + key
+		}
+
+		v, err := redis.ByteSlices(store.do(queryKeys[0], "MGET", redis.Args{}.AddFlat(queryKeys)...))
+		if err != nil {
+			missed = append(missed, group...)
+			continue
+		}
+
+		for i, value := range v {
+			decoded, err := deserializer(value)
+			if err != nil || decoded == nil {
+				missed = append(missed, group[i])
+			} else {
+				res[group[i]] = decoded
+			}
+		}
+	}
+
+	metrics.ObserveCache(redisStoreName, len(res), len(missed))
+	return res, missed
+}
+
+// Sets 批量设置值
+func (store *RedisClusterStore) Sets(values map[string]any, prefix string) error {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	for _, group := range groupBySlot(keys, prefix) {
+		args := redis.Args{}
+		for _, key := range group {
+			serialized, err := serializer(values[key])
+			if err != nil {
+				return err
+			}
+			args = args.Add(prefix+key, serialized)
+		}
+
+		if _, err := store.do(prefix+group[0], "MSET", args...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Delete deletes values by [Prefix + key]. If no key is presented, keys with given prefix
+// are found by scanning every master node.
+func (store *RedisClusterStore) Delete(prefix string, keys ...string) error {
+	if len(keys) == 0 {
+		return store.deleteByPrefix(prefix)
+	}
+
+	for _, group := range groupBySlot(keys, prefix) {
+		fullKeys := make([]string, len(group))
+		for i, key := range group {
+			fullKeys[i] = prefix + key
+		}
+
+		if _, err := store.do(fullKeys[0], "DEL", redis.Args{}.AddFlat(fullKeys)...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeleteAll removes all keys by scanning and deleting them on every master node. FLUSHDB
+// is not used as it only flushes the node it is sent to.
+func (store *RedisClusterStore) DeleteAll() error {
+	return store.deleteByPrefix("")
+}
+
+// Persist Dummy implementation
+func (store *RedisClusterStore) Persist(path string) error {
+	return nil
+}
+
+// Restore dummy implementation
+func (store *RedisClusterStore) Restore(path string) error {
+	return nil
+}
+
+func (store *RedisClusterStore) deleteByPrefix(prefix string) error {
+	masters, err := store.masters()
+	if err != nil {
+		return err
+	}
+
+	for _, addr := range masters {
+		keys, err := store.scan(addr, prefix+"*")
+		if err != nil {
+			return fmt.Errorf("failed to scan node %q: %w", addr, err)
+		}
+
+		for _, key := range keys {
+			if _, err := store.do(key, "DEL", key); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// scan lists all keys matching pattern on given node.
+func (store *RedisClusterStore) scan(addr, pattern string) ([]string, error) {
+	rc := store.pool(addr).Get()
+	defer rc.Close()
+
+	return scanRedisKeys(rc, pattern)
+}
+
+// do executes command on the node owning slot of key, following MOVED and ASK redirections.
+func (store *RedisClusterStore) do(key, cmd string, args ...any) (any, error) {
+	addr, err := store.nodeFor(key)
+	if err != nil {
+		return nil, err
+	}
+
+	asking := false
+	for i := 0; i <= redisClusterMaxRedirects; i++ {
+		reply, err := store.doOnNode(addr, asking, cmd, args...)
+		redirect, target, ok := parseRedirect(err)
+		if !ok {
+			return reply, err
+		}
+
+		addr, asking = target, redirect == "ASK"
+		if redirect == "MOVED" {
+			if err := store.refreshSlots(); err != nil {
+				store.l.Warning("Failed to refresh Redis Cluster slots: %s", err)
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("too many redirections for key %q", key)
+}
+
+func (store *RedisClusterStore) doOnNode(addr string, asking bool, cmd string, args ...any) (any, error) {
+	rc := store.pool(addr).Get()
+	defer rc.Close()
+	if rc.Err() != nil {
+		return nil, rc.Err()
+	}
+
+	if asking {
+		if _, err := rc.Do("ASKING"); err != nil {
+			return nil, err
+		}
+	}
+
+	return rc.Do(cmd, args...)
+}
+
+// parseRedirect parses `MOVED <slot> <addr>` and `ASK <slot> <addr>` errors.
+func parseRedirect(err error) (string, string, bool) {
+	var redisErr redis.Error
+	if !errors.As(err, &redisErr) {
+		return "", "", false
+	}
+
+	parts := strings.Fields(string(redisErr))
+	if len(parts) != 3 || (parts[0] != "MOVED" && parts[0] != "ASK") {
+		return "", "", false
+	}
+
+	return parts[0], parts[2], true
+}
+
+// nodeFor returns address of the master owning slot of key, slot layout is loaded on first use.
+func (store *RedisClusterStore) nodeFor(key string) (string, error) {
+	slot := redisKeySlot(key)
+	store.mu.RLock()
+	addr := store.slots[slot]
+	store.mu.RUnlock()
+	if addr != "" {
+		return addr, nil
+	}
+
+	if err := store.refreshSlots(); err != nil {
+		return "", err
+	}
+
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	if store.slots[slot] == "" {
+		return "", fmt.Errorf("slot %d is not served by any node", slot)
+	}
+
+	return store.slots[slot], nil
+}
+
+// masters returns addresses of all master nodes serving at least one slot.
+func (store *RedisClusterStore) masters() ([]string, error) {
+	store.mu.RLock()
+	loaded := store.slots[0] != ""
+	store.mu.RUnlock()
+	if !loaded {
+		if err := store.refreshSlots(); err != nil {
+			return nil, err
+		}
+	}
+
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	seen := make(map[string]bool)
+	var masters []string
+	for _, addr := range store.slots {
+		if addr != "" && !seen[addr] {
+			seen[addr] = true
+			masters = append(masters, addr)
+		}
+	}
+
+	return masters, nil
+}
+
+// refreshSlots loads slot layout from the first reachable known node.
+func (store *RedisClusterStore) refreshSlots() error {
+	store.mu.RLock()
+	candidates := append([]string{}, store.seeds...)
+	for addr := range store.pools {
+		candidates = append(candidates, addr)
+	}
+	store.mu.RUnlock()
+
+	var lastErr error = errors.New("no Redis Cluster node configured")
+	for _, addr := range candidates {
+		slots, err := store.loadSlots(addr)
+		if err != nil {
+			lastErr = fmt.Errorf("node %q: %w", addr, err)
+			continue
+		}
+
+		store.mu.Lock()
+		store.slots = slots
+		store.mu.Unlock()
+		return nil
+	}
+
+	return lastErr
+}
+
+func (store *RedisClusterStore) loadSlots(addr string) ([redisClusterSlots]string, error) {
+	var slots [redisClusterSlots]string
+	rc := store.pool(addr).Get()
+	defer rc.Close()
+
+	ranges, err := redis.Values(rc.Do("CLUSTER", "SLOTS"))
+	if err != nil {
+		return slots, err
+	}
+
+	for _, r := range ranges {
+		fields, err := redis.Values(r, nil)
+		if err != nil || len(fields) < 3 {
+			return slots, fmt.Errorf("unexpected CLUSTER SLOTS reply %v", r)
+		}
+
+		start, err := redis.Int(fields[0], nil)
+		if err != nil {
+			return slots, err
+		}
+		end, err := redis.Int(fields[1], nil)
+		if err != nil {
+			return slots, err
+		}
+
+		master, err := redis.Values(fields[2], nil)
+		if err != nil || len(master) < 2 {
+			return slots, fmt.Errorf("unexpected CLUSTER SLOTS node %v", fields[2])
+		}
+
+		host, err := redis.String(master[0], nil)
+		if err != nil {
+			return slots, err
+		}
+		port, err := redis.Int(master[1], nil)
+		if err != nil {
+			return slots, err
+		}
+
+		// Empty host means the node we asked
+		if host == "" {
+			host, _, _ = net.SplitHostPort(addr)
+		}
+
+		nodeAddr := net.JoinHostPort(host, strconv.Itoa(port))
+		for slot := start; slot <= end && slot < redisClusterSlots; slot++ {
+			slots[slot] = nodeAddr
+		}
+	}
+
+	return slots, nil
+}
+
+// pool returns connection pool of given node, creating one if not exist.
+func (store *RedisClusterStore) pool(addr string) *redis.Pool {
+	store.mu.RLock()
+	p, ok := store.pools[addr]
+	store.mu.RUnlock()
+	if ok {
+		return p
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if p, ok := store.pools[addr]; ok {
+		return p
+	}
+
+	p = &redis.Pool{
+		MaxIdle:     store.size,
+		IdleTimeout: redisIdleTimeout,
+		TestOnBorrow: func(c redis.Conn, t time.Time) error {
+			_, err := c.Do("PING")
+			return err
+		},
+		Dial: func() (redis.Conn, error) {
+			return store.dial(addr)
+		},
+	}
+	store.pools[addr] = p
+	return p
+}
+
+// groupBySlot groups keys by hash slot of [prefix + key], keeping order within each group.
+func groupBySlot(keys []string, prefix string) [][]string {
+	index := make(map[int]int)
+	var groups [][]string
+	for _, key := range keys {
+		slot := redisKeySlot(prefix + key)
+		i, ok := index[slot]
+		if !ok {
+			i = len(groups)
+			index[slot] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], key)
+	}
+
+	return groups
+}
+
+// redisKeySlot returns hash slot of key. If key contains a non-empty hash tag `{...}`, only
+// the tag is hashed.
+func redisKeySlot(key string) int {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+
+	return int(crc16(key)) % redisClusterSlots
+}
+
+// crc16 implements CRC16-CCITT (XModem) used by Redis Cluster.
+func crc16(s string) uint16 {
+	var crc uint16
+	for i := 0; i < len(s); i++ {
+		crc ^= uint16(s[i]) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+
+	return crc
+}
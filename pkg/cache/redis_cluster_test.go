@@ -0,0 +1,172 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/gomodule/redigo/redis"
+	"github.com/rafaeljusto/redigomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func clusterSlotsReply(ranges ...[]any) []any {
+	res := make([]any, len(ranges))
+	for i, r := range ranges {
+		res[i] = []any{r[0], r[1], []any{[]byte(r[2].(string)), r[3], []byte("id")}}
+	}
+	return res
+}
+
+func newTestClusterStore(nodes map[string]*redigomock.Conn, seeds ...string) *RedisClusterStore {
+	for _, conn := range nodes {
+		conn.Command("PING").Expect("PONG")
+	}
+
+	return &RedisClusterStore{
+		l:     logging.NewConsoleLogger(logging.LevelError),
+		size:  10,
+		seeds: seeds,
+		pools: make(map[string]*redis.Pool),
+		dial: func(addr string) (redis.Conn, error) {
+			if conn, ok := nodes[addr]; ok {
+				return conn, nil
+			}
+			return nil, errors.New("unknown node")
+		},
+	}
+}
+
+func TestRedisKeySlot(t *testing.T) {
+	a := assert.New(t)
+	a.Equal(12182, redisKeySlot("foo"))
+	a.Equal(12739, redisKeySlot("123456789"))
+	a.Equal(redisKeySlot("user1000"), redisKeySlot("{user1000}.following"))
+	a.Equal(redisKeySlot("{user1000}.following"), redisKeySlot("{user1000}.followers"))
+	// Empty hash tag is ignored
+	a.Equal(int(crc16("{}foo"))%redisClusterSlots, redisKeySlot("{}foo"))
+}
+
+func TestRedisClusterStore_Routing(t *testing.T) {
+	a := assert.New(t)
+	nodeA, nodeB := redigomock.NewConn(), redigomock.NewConn()
+	store := newTestClusterStore(map[string]*redigomock.Conn{"10.0.0.1:6379": nodeA, "10.0.0.2:6379": nodeB}, "10.0.0.1:6379")
+	layout := clusterSlotsReply([]any{int64(0), int64(8191), "10.0.0.1", int64(6379)}, []any{int64(8192), int64(16383), "10.0.0.2", int64(6379)})
+	nodeA.Command("CLUSTER", "SLOTS").Expect(layout)
+
+	// "foo" is in slot 12182, served by node B
+	setCmd := nodeB.Command("SET", "foo", redigomock.NewAnyData()).Expect("OK")
+	a.NoError(store.Set("foo", "bar", 0))
+	a.Equal(1, nodeB.Stats(setCmd))
+
+	serialized, _ := serializer("bar")
+	nodeB.Command("GET", "foo").Expect(serialized)
+	val, ok := store.Get("foo")
+	a.True(ok)
+	a.Equal("bar", val)
+
+	// Keys in different slots are fetched separately
+	nodeB.Command("MGET", "foo").Expect([]any{serialized})
+	nodeB.Command("MGET", "{a}1", "{a}2").Expect([]any{serialized, nil})
+	res, missed := store.Gets([]string{"1", "2"}, "{a}")
+	a.Equal(map[string]any{"1": "bar"}, res)
+	a.Equal([]string{"2"}, missed)
+	res, missed = store.Gets([]string{"foo"}, "")
+	a.Equal(map[string]any{"foo": "bar"}, res)
+	a.Empty(missed)
+}
+
+func TestRedisClusterStore_Redirect(t *testing.T) {
+	a := assert.New(t)
+	nodeA, nodeB := redigomock.NewConn(), redigomock.NewConn()
+	store := newTestClusterStore(map[string]*redigomock.Conn{"10.0.0.1:6379": nodeA, "10.0.0.2:6379": nodeB}, "10.0.0.1:6379")
+	nodeA.Command("CLUSTER", "SLOTS").
+		Expect(clusterSlotsReply([]any{int64(0), int64(16383), "10.0.0.2", int64(6379)})).
+		Expect(clusterSlotsReply([]any{int64(0), int64(16383), "10.0.0.1", int64(6379)}))
+	serialized, _ := serializer("bar")
+
+	// MOVED refreshes slot layout and retries on new owner
+	nodeB.Command("GET", "foo").ExpectError(redis.Error("MOVED 12182 10.0.0.1:6379"))
+	nodeA.Command("GET", "foo").Expect(serialized)
+	val, ok := store.Get("foo")
+	a.True(ok)
+	a.Equal("bar", val)
+	addr, err := store.nodeFor("foo")
+	a.NoError(err)
+	a.Equal("10.0.0.1:6379", addr)
+
+	// ASK retries once on target without changing slot layout
+	nodeA.Command("DEL", "foo").ExpectError(redis.Error("ASK 12182 10.0.0.2:6379"))
+	asking := nodeB.Command("ASKING").Expect("OK")
+	nodeB.Command("DEL", "foo").Expect(int64(1))
+	a.NoError(store.Delete("", "foo"))
+	a.Equal(1, nodeB.Stats(asking))
+	addr, _ = store.nodeFor("foo")
+	a.Equal("10.0.0.1:6379", addr)
+
+	// Other errors are returned as is
+	nodeA.Command("SET", "foo", redigomock.NewAnyData()).ExpectError(redis.Error("CLUSTERDOWN"))
+	a.Error(store.Set("foo", "bar", 0))
+}
+
+func TestRedisClusterStore_DeleteByPrefix(t *testing.T) {
+	a := assert.New(t)
+	nodeA, nodeB := redigomock.NewConn(), redigomock.NewConn()
+	store := newTestClusterStore(map[string]*redigomock.Conn{"10.0.0.1:6379": nodeA, "10.0.0.2:6379": nodeB}, "10.0.0.1:6379")
+	nodeA.Command("CLUSTER", "SLOTS").Expect(clusterSlotsReply(
+		[]any{int64(0), int64(8191), "10.0.0.1", int64(6379)},
+		[]any{int64(8192), int64(16383), "10.0.0.2", int64(6379)},
+	))
+
+	// "{a}1" is in slot 15495, "{b}1" in slot 3300
+	nodeA.Command("SCAN", 0, "MATCH", "*", "COUNT", redisScanCount).Expect([]any{[]byte("5"), []any{}})
+	nodeA.Command("SCAN", 5, "MATCH", "*", "COUNT", redisScanCount).Expect([]any{[]byte("0"), []any{[]byte("{b}1")}})
+	nodeB.Command("SCAN", 0, "MATCH", "*", "COUNT", redisScanCount).Expect([]any{[]byte("0"), []any{[]byte("{a}1")}})
+	delA := nodeA.Command("DEL", "{b}1").Expect(int64(1))
+	delB := nodeB.Command("DEL", "{a}1").Expect(int64(1))
+	flush := nodeA.Command("FLUSHDB").Expect("OK")
+
+	a.NoError(store.DeleteAll())
+	a.Equal(1, nodeA.Stats(delA))
+	a.Equal(1, nodeB.Stats(delB))
+	a.Equal(0, nodeA.Stats(flush))
+
+	// Scan failure is reported
+	nodeB.Command("SCAN", 0, "MATCH", "p_*", "COUNT", redisScanCount).ExpectError(errors.New("error"))
+	nodeA.Command("SCAN", 0, "MATCH", "p_*", "COUNT", redisScanCount).Expect([]any{[]byte("0"), []any{}})
+	a.Error(store.Delete("p_"))
+}
+
+func TestRedisSentinel_MasterAddr(t *testing.T) {
+	a := assert.New(t)
+	sentinelB := redigomock.NewConn()
+	sentinelB.Command("SENTINEL", "get-master-addr-by-name", "mymaster").Expect([]any{[]byte("10.0.0.3"), []byte("6379")})
+	sentinel := &redisSentinel{
+		addrs:      []string{"s1:26379", "s2:26379"},
+		masterName: "mymaster",
+		dial: func(addr string) (redis.Conn, error) {
+			if addr == "s2:26379" {
+				return sentinelB, nil
+			}
+			return nil, errors.New("unreachable")
+		},
+	}
+
+	addr, err := sentinel.masterAddr()
+	a.NoError(err)
+	a.Equal("10.0.0.3:6379", addr)
+	// Answering sentinel is asked first next time
+	a.Equal([]string{"s2:26379", "s1:26379"}, sentinel.addrs)
+
+	sentinel.addrs = []string{"s1:26379"}
+	_, err = sentinel.masterAddr()
+	a.Error(err)
+}
+
+func TestCheckRedisRole(t *testing.T) {
+	a := assert.New(t)
+	conn := redigomock.NewConn()
+	conn.Command("ROLE").Expect([]any{[]byte("master"), int64(0), []any{}}).Expect([]any{[]byte("slave")})
+	a.NoError(checkRedisRole(conn, "master"))
+	a.Error(checkRedisRole(conn, "master"))
+}
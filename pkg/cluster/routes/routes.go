@@ -48,6 +48,14 @@ func MasterSlaveCallbackUrl(base *url.URL, driver, id, secret string) *url.URL {
 	return base.ResolveReference(apiBaseURI)
 }
 
+func MasterCaptchaAudioUrl(base *url.URL, ticket string) *url.URL {
+	route, _ := url.Parse(constants.APIPrefix + "/site/captcha/audio")
+	q := route.Query()
+	q.Set("ticket", ticket)
+	route.RawQuery = q.Encode()
+	return base.ResolveReference(route)
+}
+
 func MasterUserActivateAPIUrl(base *url.URL, uid string) *url.URL {
 	route, _ := url.Parse(constants.APIPrefix + "/user/activate/" + uid)
 	return base.ResolveReference(route)
@@ -29,6 +29,20 @@ type Database struct {
 	DatabaseURL string
 	// SSLMode 允许使用SSL连接数据库, 用户可以在sslmode string中添加证书等配置
 	SSLMode string
+	// ReplicaURLs is a comma separated list of read replica connection strings. File listing
+	// and search queries are distributed across replicas, everything else goes to the primary.
+	ReplicaURLs []string
+	// Connection pool settings, applied to primary and every replica. Each queue worker (see queue_*_worker_num settings) may hold a
+	// connection while running, MaxOpenConns should be large enough to serve the sum of worker
+	// counts plus concurrent HTTP requests, otherwise tasks will block waiting for a connection.
+	// SQLite always uses a single connection regardless of MaxOpenConns.
+	MaxIdleConns    int `validate:"gte=0"`
+	MaxOpenConns    int `validate:"gte=0"`
+	ConnMaxLifetime int `validate:"gte=0"` // in seconds, 0 means no limit
+	ConnMaxIdleTime int `validate:"gte=0"` // in seconds, 0 means no limit
+	// SQLiteBusyTimeout is how long (in milliseconds) a SQLite connection waits for a lock
+	// before failing with "database is locked".
+	SQLiteBusyTimeout int `validate:"gte=0"`
 }
 
 type SysMode string
@@ -68,8 +82,17 @@ type Slave struct {
 	SignatureTTL    int    `validate:"omitempty,gte=1"`
 }
 
+type RedisMode string
+
+var (
+	RedisStandaloneMode RedisMode = "standalone"
+	RedisSentinelMode   RedisMode = "sentinel"
+	RedisClusterMode    RedisMode = "cluster"
+)
+
 // Redis 配置
 type Redis struct {
+	Mode          RedisMode `validate:"omitempty,oneof=standalone sentinel cluster"`
 	Network       string
 	Server        string
 	User          string
@@ -77,6 +100,17 @@ type Redis struct {
 	DB            string
 	UseTLS        bool
 	TLSSkipVerify bool
+	// Nodes is a comma separated list of sentinel addresses in sentinel mode, or seed node
+	// addresses in cluster mode. Server is ignored in these modes.
+	Nodes []string
+	// MasterName is the name of master set monitored by sentinels.
+	MasterName       string
+	SentinelPassword string
+	// KeyPrefix is prepended to all keys, so that multiple instances can share one Redis
+	// without collisions. DeleteAll only clears keys with this prefix when set.
+	KeyPrefix string
+	// PoolSize is the max number of idle connections kept for each Redis node.
+	PoolSize int `validate:"gte=0"`
 }
 
 // 跨域配置
@@ -92,21 +126,27 @@ type Cors struct {
 
 // RedisConfig Redis服务器配置
 var RedisConfig = &Redis{
+	Mode:          RedisStandaloneMode,
 	Network:       "tcp",
 	Server:        "",
 	Password:      "",
 	DB:            "0",
 	UseTLS:        false,
 	TLSSkipVerify: true,
+	PoolSize:      10,
 }
 
 // DatabaseConfig 数据库配置
 var DatabaseConfig = &Database{
-	Charset:     "utf8mb4",
-	DBFile:      util.DataPath("cloudreve.db"),
-	Port:        3306,
-	UnixSocket:  false,
-	DatabaseURL: "",
+	Charset:           "utf8mb4",
+	DBFile:            util.DataPath("cloudreve.db"),
+	Port:              3306,
+	UnixSocket:        false,
+	DatabaseURL:       "",
+	MaxIdleConns:      50,
+	MaxOpenConns:      100,
+	ConnMaxLifetime:   30,
+	SQLiteBusyTimeout: 5000,
 }
 
 // SystemConfig 系统公用配置
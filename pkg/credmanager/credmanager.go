@@ -16,6 +16,7 @@ import (
 	"github.com/cloudreve/Cloudreve/v4/pkg/conf"
 	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
 	"github.com/cloudreve/Cloudreve/v4/pkg/request"
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
 )
 
 type (
@@ -26,7 +27,24 @@ type (
 		Obtain(ctx context.Context, key string) (Credential, error)
 		// Upsert inserts or updates a credential in the manager
 		Upsert(ctx context.Context, cred ...Credential) error
+		// RefreshAll refreshes all managed credentials, failures of one credential
+		// will not block others.
 		RefreshAll(ctx context.Context)
+		// RefreshStatus returns the refresh status of given credential key.
+		RefreshStatus(key string) (*RefreshStatus, bool)
+	}
+
+	// RefreshStatus tracks the periodic refresh result of a credential. It is persisted in KV
+	// so that it survives restarts.
+	RefreshStatus struct {
+		LastRefresh         *time.Time `json:"last_refresh,omitempty"`
+		LastAttempt         time.Time  `json:"last_attempt"`
+		NextExpiry          time.Time  `json:"next_expiry"`
+		ConsecutiveFailures int        `json:"consecutive_failures"`
+		LastError           string     `json:"last_error,omitempty"`
+		// Alert is true if the credential has failed to refresh repeatedly and is about to expire,
+		// it needs to be re-authorized by admin.
+		Alert bool `json:"alert,omitempty"`
 	}
 
 	Credential interface {
@@ -38,23 +56,31 @@ type (
 	}
 )
 
+const (
+	refreshStatusKeyPrefix = "cred_refresh_status_"
+)
+
 func init() {
 	gob.Register(CredentialResponse{})
+	gob.Register(RefreshStatus{})
 }
 
-func New(kv cache.Driver) CredManager {
+func New(kv cache.Driver, settings setting.Provider) CredManager {
 	return &credManager{
-		kv:    kv,
-		locks: make(map[string]*sync.Mutex),
+		kv:       kv,
+		settings: settings,
+		locks:    make(map[string]*sync.Mutex),
 	}
 }
 
 type (
 	credManager struct {
-		kv cache.Driver
-		mu sync.RWMutex
+		kv       cache.Driver
+		settings setting.Provider
+		mu       sync.RWMutex
 
-		locks map[string]*sync.Mutex
+		locks    map[string]*sync.Mutex
+		statusMu sync.Mutex
 	}
 )
 
@@ -121,32 +147,132 @@ func (m *credManager) Obtain(ctx context.Context, key string) (Credential, error
 
 func (m *credManager) RefreshAll(ctx context.Context) {
 	m.mu.RLock()
-	defer m.mu.RUnlock()
+	keys := make([]string, 0, len(m.locks))
+	for key := range m.locks {
+		keys = append(keys, key)
+	}
+	m.mu.RUnlock()
+
+	opts := m.settings.OauthCredRefresh(ctx)
+	for _, key := range keys {
+		m.refreshWithRetry(ctx, key, opts)
+	}
+}
+
+func (m *credManager) RefreshStatus(key string) (*RefreshStatus, bool) {
+	m.statusMu.Lock()
+	defer m.statusMu.Unlock()
+
+	status, ok := m.kv.Get(refreshStatusKeyPrefix + key)
+	if !ok {
+		return nil, false
+	}
 
+	res, ok := status.(RefreshStatus)
+	if !ok {
+		return nil, false
+	}
+
+	return &res, true
+}
+
+// refreshWithRetry refreshes a single credential, retrying with exponential backoff on failures.
+func (m *credManager) refreshWithRetry(ctx context.Context, key string, opts *setting.OauthCredRefresh) {
 	l := logging.FromContext(ctx)
-	for key := range m.locks {
-		l.Info("Refreshing credential for key %q...", key)
-		m.locks[key].Lock()
-		defer m.locks[key].Unlock()
-
-		itemRaw, ok := m.kv.Get(key)
-		if !ok {
-			l.Warning("Credential not found for key %q", key)
-			continue
+	l.Info("Refreshing credential for key %q...", key)
+
+	m.mu.RLock()
+	lock, ok := m.locks[key]
+	m.mu.RUnlock()
+	if !ok {
+		return
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	itemRaw, ok := m.kv.Get(key)
+	if !ok {
+		l.Warning("Credential not found for key %q", key)
+		return
+	}
+
+	item := itemRaw.(Credential)
+	var (
+		newCred Credential
+		err     error
+	)
+	delay := opts.RetryDelay
+	for attempt := 0; ; attempt++ {
+		newCred, err = item.Refresh(ctx)
+		if err == nil || attempt >= opts.MaxRetry {
+			break
 		}
 
-		item := itemRaw.(Credential)
-		newCred, err := item.Refresh(ctx)
-		if err != nil {
-			l.Warning("Failed to refresh credential for key %q: %s", key, err)
-			continue
+		l.Warning("Failed to refresh credential for key %q: %s, retry after %s", key, err, delay)
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+		case <-time.After(delay):
+		}
+		if ctx.Err() != nil {
+			break
 		}
+		delay = time.Duration(float64(delay) * opts.BackoffFactor)
+	}
 
-		l.Info("New credential for key %q is obtained, expire at %s", key, newCred.Expiry().String())
-		if err := m.kv.Set(key, newCred, 0); err != nil {
-			l.Warning("Failed to update credential in KV for key %q: %s", key, err)
+	if err != nil {
+		l.Warning("Failed to refresh credential for key %q: %s", key, err)
+		status := m.recordRefresh(ctx, key, item, err, opts)
+		if status.Alert {
+			l.Error("Credential for key %q failed to refresh %d times in a row and will expire at %s, please re-authorize it.",
+				key, status.ConsecutiveFailures, status.NextExpiry.String())
 		}
+		return
 	}
+
+	l.Info("New credential for key %q is obtained, expire at %s", key, newCred.Expiry().String())
+	if err := m.kv.Set(key, newCred, 0); err != nil {
+		l.Warning("Failed to update credential in KV for key %q: %s", key, err)
+	}
+	m.recordRefresh(ctx, key, newCred, nil, opts)
+}
+
+// shouldAlert returns true if the credential has failed to refresh repeatedly and is about to expire.
+func shouldAlert(status *RefreshStatus, opts *setting.OauthCredRefresh) bool {
+	return status.ConsecutiveFailures >= opts.AlertThreshold && time.Until(status.NextExpiry) < opts.AlertMargin
+}
+
+// recordRefresh updates and persists refresh status of given credential.
+func (m *credManager) recordRefresh(ctx context.Context, key string, cred Credential, err error, opts *setting.OauthCredRefresh) RefreshStatus {
+	m.statusMu.Lock()
+	defer m.statusMu.Unlock()
+
+	status := RefreshStatus{}
+	if existed, ok := m.kv.Get(refreshStatusKeyPrefix + key); ok {
+		if s, ok := existed.(RefreshStatus); ok {
+			status = s
+		}
+	}
+
+	status.LastAttempt = time.Now()
+	status.NextExpiry = cred.Expiry()
+	if err != nil {
+		status.ConsecutiveFailures++
+		status.LastError = err.Error()
+	} else {
+		now := time.Now()
+		status.LastRefresh = &now
+		status.ConsecutiveFailures = 0
+		status.LastError = ""
+	}
+	status.Alert = shouldAlert(&status, opts)
+
+	if err := m.kv.Set(refreshStatusKeyPrefix+key, status, 0); err != nil {
+		logging.FromContext(ctx).Warning("Failed to save refresh status for key %q: %s", key, err)
+	}
+
+	return status
 }
 
 type (
@@ -209,6 +335,10 @@ func (m *slaveCredManager) Obtain(ctx context.Context, key string) (Credential,
 // No op on slave node
 func (m *slaveCredManager) RefreshAll(ctx context.Context) {}
 
+func (m *slaveCredManager) RefreshStatus(key string) (*RefreshStatus, bool) {
+	return nil, false
+}
+
 func (m *slaveCredManager) requestCredFromMaster(ctx context.Context, key string) (Credential, error) {
 	l := logging.FromContext(ctx)
 	l.Info("SlaveCredManager: Requesting credential for key %q from master...", key)
@@ -0,0 +1,148 @@
+package credmanager
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cloudreve/Cloudreve/v4/pkg/cache"
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting/settingtest"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeCredential struct {
+	key    string
+	expiry time.Time
+	fails  *int
+	calls  *int
+}
+
+func (c fakeCredential) String() string          { return c.key }
+func (c fakeCredential) Key() string             { return c.key }
+func (c fakeCredential) Expiry() time.Time       { return c.expiry }
+func (c fakeCredential) RefreshedAt() *time.Time { return nil }
+func (c fakeCredential) Refresh(ctx context.Context) (Credential, error) {
+	*c.calls++
+	if *c.fails != 0 {
+		if *c.fails > 0 {
+			*c.fails--
+		}
+		return nil, errors.New("refresh failed")
+	}
+
+	c.expiry = time.Now().Add(time.Hour)
+	return c, nil
+}
+
+func newTestManager(settings map[string]any) *credManager {
+	l := logging.NewConsoleLogger(logging.LevelError)
+	return New(cache.NewMemoStore("", l), settingtest.NewProvider(settings)).(*credManager)
+}
+
+func TestCredManager_RefreshAll_IsolatedFailure(t *testing.T) {
+	a := assert.New(t)
+	m := newTestManager(map[string]any{
+		"oauth_cred_refresh_max_retry":   1,
+		"oauth_cred_refresh_retry_delay": 0,
+	})
+
+	badFails, badCalls := -1, 0
+	goodFails, goodCalls := 0, 0
+	a.NoError(m.Upsert(context.Background(),
+		fakeCredential{key: "bad", expiry: time.Now(), fails: &badFails, calls: &badCalls},
+		fakeCredential{key: "good", expiry: time.Now(), fails: &goodFails, calls: &goodCalls},
+	))
+
+	m.RefreshAll(context.Background())
+	a.Equal(2, badCalls)
+	a.Equal(1, goodCalls)
+
+	bad, ok := m.RefreshStatus("bad")
+	a.True(ok)
+	a.Equal(1, bad.ConsecutiveFailures)
+	a.Nil(bad.LastRefresh)
+	a.NotEmpty(bad.LastError)
+
+	good, ok := m.RefreshStatus("good")
+	a.True(ok)
+	a.Equal(0, good.ConsecutiveFailures)
+	a.NotNil(good.LastRefresh)
+	a.True(good.NextExpiry.After(time.Now()))
+}
+
+func TestCredManager_RefreshAll_RetryRecovers(t *testing.T) {
+	a := assert.New(t)
+	m := newTestManager(map[string]any{
+		"oauth_cred_refresh_max_retry":   2,
+		"oauth_cred_refresh_retry_delay": 0,
+	})
+
+	fails, calls := 2, 0
+	a.NoError(m.Upsert(context.Background(), fakeCredential{key: "flaky", expiry: time.Now(), fails: &fails, calls: &calls}))
+
+	m.RefreshAll(context.Background())
+	a.Equal(3, calls)
+	status, ok := m.RefreshStatus("flaky")
+	a.True(ok)
+	a.Equal(0, status.ConsecutiveFailures)
+}
+
+func TestCredManager_NearExpiryAlert(t *testing.T) {
+	a := assert.New(t)
+	m := newTestManager(map[string]any{
+		"oauth_cred_refresh_max_retry":       0,
+		"oauth_cred_refresh_alert_threshold": 2,
+		"oauth_cred_refresh_alert_margin":    3600,
+	})
+
+	fails, calls := -1, 0
+	a.NoError(m.Upsert(context.Background(), fakeCredential{key: "expiring", expiry: time.Now().Add(time.Minute), fails: &fails, calls: &calls}))
+	opts := m.settings.OauthCredRefresh(context.Background())
+
+	m.RefreshAll(context.Background())
+	status, _ := m.RefreshStatus("expiring")
+	a.False(status.Alert)
+
+	m.RefreshAll(context.Background())
+	status, _ = m.RefreshStatus("expiring")
+	a.True(status.Alert)
+
+	// Far from expiry, no alert even with repeated failures.
+	status.NextExpiry = time.Now().Add(48 * time.Hour)
+	a.False(shouldAlert(status, opts))
+}
+
+func TestCredManager_RefreshStatusPersisted(t *testing.T) {
+	a := assert.New(t)
+	l := logging.NewConsoleLogger(logging.LevelError)
+	kv := cache.NewMemoStore("", l)
+	settings := settingtest.NewProvider(map[string]any{
+		"oauth_cred_refresh_max_retry":       0,
+		"oauth_cred_refresh_alert_threshold": 2,
+		"oauth_cred_refresh_alert_margin":    3600,
+	})
+
+	fails, calls := -1, 0
+	m := New(kv, settings)
+	a.NoError(m.Upsert(context.Background(), fakeCredential{key: "expiring", expiry: time.Now().Add(time.Minute), fails: &fails, calls: &calls}))
+	m.RefreshAll(context.Background())
+
+	// Status is restored by a new manager sharing the same KV, e.g. after restart
+	restarted := New(kv, settings)
+	status, ok := restarted.RefreshStatus("expiring")
+	a.True(ok)
+	a.Equal(1, status.ConsecutiveFailures)
+	a.False(status.Alert)
+
+	// Failures keep accumulating across restarts
+	a.NoError(restarted.Upsert(context.Background(), fakeCredential{key: "expiring", expiry: time.Now().Add(time.Minute), fails: &fails, calls: &calls}))
+	restarted.RefreshAll(context.Background())
+	status, _ = restarted.RefreshStatus("expiring")
+	a.Equal(2, status.ConsecutiveFailures)
+	a.True(status.Alert)
+
+	_, ok = restarted.RefreshStatus("unknown")
+	a.False(ok)
+}
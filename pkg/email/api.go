@@ -0,0 +1,293 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/mail"
+	"net/textproto"
+	"net/url"
+	"strings"
+
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/cloudreve/Cloudreve/v4/pkg/metrics"
+	"github.com/cloudreve/Cloudreve/v4/pkg/request"
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
+)
+
+const (
+	mailgunDefaultEndpoint  = "https://api.mailgun.net"
+	sendGridDefaultEndpoint = "https://api.sendgrid.com"
+)
+
+// APIClient sends emails through HTTP API of email providers like Mailgun, SendGrid.
+// It can be used in environments where outbound SMTP ports are blocked.
+type APIClient struct {
+	driver            setting.MailDriver
+	api               *setting.MailAPI
+	sender            *setting.SMTP
+	maxAttachmentSize int64
+	skipDomains       []string
+	defaults          []SendOption
+	limiter           *RateLimiter
+	client            request.Client
+	l                 logging.Logger
+}
+
+type (
+	apiRequest struct {
+		url    string
+		header http.Header
+		body   []byte
+	}
+	sendGridAddress struct {
+		Email string `json:"email"`
+		Name  string `json:"name,omitempty"`
+	}
+	sendGridContent struct {
+		Type  string `json:"type"`
+		Value string `json:"value"`
+	}
+	sendGridAttachment struct {
+		Content     string `json:"content"`
+		Filename    string `json:"filename"`
+		Type        string `json:"type,omitempty"`
+		Disposition string `json:"disposition"`
+	}
+	sendGridPersonalization struct {
+		To  []sendGridAddress `json:"to"`
+		Cc  []sendGridAddress `json:"cc,omitempty"`
+		Bcc []sendGridAddress `json:"bcc,omitempty"`
+	}
+	sendGridRequest struct {
+		Personalizations []sendGridPersonalization `json:"personalizations"`
+		From             sendGridAddress           `json:"from"`
+		ReplyTo          *sendGridAddress          `json:"reply_to,omitempty"`
+		Subject          string                    `json:"subject"`
+		Content          []sendGridContent         `json:"content"`
+		Attachments      []sendGridAttachment      `json:"attachments,omitempty"`
+		Headers          map[string]string         `json:"headers,omitempty"`
+	}
+)
+
+// NewAPIClient initializes a new HTTP API based email client.
+func NewAPIClient(config setting.Provider, client request.Client, limiter *RateLimiter, logger logging.Logger) *APIClient {
+	ctx := context.Background()
+	return &APIClient{
+		driver:            config.MailDriver(ctx),
+		api:               config.MailAPI(ctx),
+		sender:            config.SMTP(ctx),
+		maxAttachmentSize: config.MailMaxAttachmentSize(ctx),
+		skipDomains:       config.MailSkipDomains(ctx),
+		defaults:          defaultSendOptions(config),
+		limiter:           limiter,
+		client:            client,
+		l:                 logger,
+	}
+}
+
+// Send sends email synchronously through provider's HTTP API.
+func (c *APIClient) Send(ctx context.Context, to, title, body string, opts ...SendOption) error {
+	return c.SendWithAttachments(ctx, to, title, body, nil, opts...)
+}
+
+// SendWithAttachments sends email with attachments synchronously through provider's HTTP API.
+func (c *APIClient) SendWithAttachments(ctx context.Context, to, title, body string, attachments []Attachment, opts ...SendOption) error {
+	// 忽略社交登录创建的占位邮箱
+	if domain, ok := skippedDomain(to, c.skipDomains); ok {
+		c.l.Debug("Skip sending email to %q, domain %q is in skip list.", to, domain)
+		return nil
+	}
+
+	o, err := newSendOptions(c.defaults, opts)
+	if err != nil {
+		return err
+	}
+
+	if err := c.limiter.Allow(ctx, o.rateLimit); err != nil {
+		return err
+	}
+
+	buffered, err := bufferAttachments(attachments, c.maxAttachmentSize)
+	if err != nil {
+		return err
+	}
+
+	var req *apiRequest
+	switch c.driver {
+	case setting.MailDriverMailgun:
+		req, err = c.mailgunRequest(to, title, body, buffered, o)
+	case setting.MailDriverSendGrid:
+		req, err = c.sendGridRequest(to, title, body, buffered, o)
+	default:
+		return fmt.Errorf("unknown mail driver %q: %w", c.driver, ErrNoActiveDriver)
+	}
+	if err != nil {
+		return err
+	}
+
+	l := c.l.CopyWithPrefix(fmt.Sprintf("[Cid: %s]", logging.CorrelationID(ctx)))
+	resp, err := c.client.Request(
+		http.MethodPost,
+		req.url,
+		bytes.NewReader(req.body),
+		request.WithContext(ctx),
+		request.WithLogger(l),
+		request.WithHeader(req.header),
+		request.WithContentLength(int64(len(req.body))),
+	).CheckHTTPResponse(http.StatusOK, http.StatusAccepted).GetResponseIgnoreErr()
+	if err != nil {
+		metrics.EmailsSent.WithLabelValues(string(c.driver), metrics.ResultFailure).Inc()
+		return fmt.Errorf("failed to send email through %s: %w, response: %s", c.driver, err, resp)
+	}
+
+	metrics.EmailsSent.WithLabelValues(string(c.driver), metrics.ResultSuccess).Inc()
+	l.Info("Email sent to %q through %s, title: %q.", to, c.driver, title)
+	return nil
+}
+
+// Close is a no-op since API client holds no long-lived connection.
+func (c *APIClient) Close() {}
+
+func (c *APIClient) endpoint(defaultEndpoint string) string {
+	if c.api.Endpoint != "" {
+		return strings.TrimSuffix(c.api.Endpoint, "/")
+	}
+
+	return defaultEndpoint
+}
+
+func (c *APIClient) mailgunRequest(to, title, body string, attachments []bufferedAttachment, o *sendOptions) (*apiRequest, error) {
+	if c.api.Domain == "" {
+		return nil, fmt.Errorf("mailgun sending domain is not configured")
+	}
+
+	form := url.Values{}
+	form.Set("from", (&mail.Address{Name: c.sender.FromName, Address: c.sender.From}).String())
+	form.Set("to", to)
+	form.Set("subject", title)
+	form.Set("html", body)
+	if o.plainBody != "" {
+		form.Set("text", o.plainBody)
+	}
+	if c.sender.ReplyTo != "" {
+		form.Set("h:Reply-To", c.sender.ReplyTo)
+	}
+	if len(o.cc) > 0 {
+		form.Set("cc", strings.Join(o.cc, ","))
+	}
+	if len(o.bcc) > 0 {
+		form.Set("bcc", strings.Join(o.bcc, ","))
+	}
+	for k, v := range o.headers {
+		form.Set("h:"+k, v)
+	}
+
+	req := &apiRequest{
+		url: fmt.Sprintf("%s/v3/%s/messages", c.endpoint(mailgunDefaultEndpoint), url.PathEscape(c.api.Domain)),
+		header: http.Header{
+			"Content-Type":  {"application/x-www-form-urlencoded"},
+			"Authorization": {"Basic " + base64.StdEncoding.EncodeToString([]byte("api:"+c.api.Key))},
+		},
+		body: []byte(form.Encode()),
+	}
+	if len(attachments) == 0 {
+		return req, nil
+	}
+
+	// Attachments can only be uploaded with multipart form
+	buf := &bytes.Buffer{}
+	w := multipart.NewWriter(buf)
+	for k, values := range form {
+		for _, v := range values {
+			if err := w.WriteField(k, v); err != nil {
+				return nil, fmt.Errorf("failed to write mailgun form field: %w", err)
+			}
+		}
+	}
+
+	for _, a := range attachments {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", mime.FormatMediaType("form-data",
+			map[string]string{"name": "attachment", "filename": a.Filename}))
+		if a.ContentType != "" {
+			header.Set("Content-Type", a.ContentType)
+		}
+
+		part, err := w.CreatePart(header)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create mailgun attachment part: %w", err)
+		}
+
+		if _, err := part.Write(a.data); err != nil {
+			return nil, fmt.Errorf("failed to write mailgun attachment: %w", err)
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close mailgun multipart form: %w", err)
+	}
+
+	req.header.Set("Content-Type", w.FormDataContentType())
+	req.body = buf.Bytes()
+	return req, nil
+}
+
+func (c *APIClient) sendGridRequest(to, title, body string, attachments []bufferedAttachment, o *sendOptions) (*apiRequest, error) {
+	toAddresses := func(addrs []string) []sendGridAddress {
+		res := make([]sendGridAddress, 0, len(addrs))
+		for _, addr := range addrs {
+			// Addresses are validated when building options
+			parsed, _ := mail.ParseAddress(addr)
+			res = append(res, sendGridAddress{Email: parsed.Address, Name: parsed.Name})
+		}
+		return res
+	}
+
+	payload := sendGridRequest{
+		Personalizations: []sendGridPersonalization{{
+			To:  []sendGridAddress{{Email: to}},
+			Cc:  toAddresses(o.cc),
+			Bcc: toAddresses(o.bcc),
+		}},
+		From:    sendGridAddress{Email: c.sender.From, Name: c.sender.FromName},
+		Subject: title,
+		Content: []sendGridContent{{Type: "text/html", Value: body}},
+		Headers: o.headers,
+	}
+	if o.plainBody != "" {
+		// SendGrid requires text/plain to be the first content
+		payload.Content = append([]sendGridContent{{Type: "text/plain", Value: o.plainBody}}, payload.Content...)
+	}
+	if c.sender.ReplyTo != "" {
+		payload.ReplyTo = &sendGridAddress{Email: c.sender.ReplyTo}
+	}
+
+	for _, a := range attachments {
+		payload.Attachments = append(payload.Attachments, sendGridAttachment{
+			Content:     base64.StdEncoding.EncodeToString(a.data),
+			Filename:    a.Filename,
+			Type:        a.ContentType,
+			Disposition: "attachment",
+		})
+	}
+
+	content, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode sendgrid request: %w", err)
+	}
+
+	return &apiRequest{
+		url: c.endpoint(sendGridDefaultEndpoint) + "/v3/mail/send",
+		header: http.Header{
+			"Content-Type":  {"application/json"},
+			"Authorization": {"Bearer " + c.api.Key},
+		},
+		body: content,
+	}, nil
+}
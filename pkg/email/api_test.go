@@ -0,0 +1,200 @@
+package email
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/cloudreve/Cloudreve/v4/pkg/request"
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting/settingtest"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClient records the last request and replies with given status and body.
+type fakeClient struct {
+	status int
+	body   string
+
+	method string
+	target string
+	sent   []byte
+}
+
+func (c *fakeClient) Apply(opts ...request.Option) {}
+
+func (c *fakeClient) Request(method, target string, body io.Reader, opts ...request.Option) *request.Response {
+	c.method = method
+	c.target = target
+	c.sent, _ = io.ReadAll(body)
+
+	return &request.Response{
+		Response: &http.Response{
+			StatusCode: c.status,
+			Body:       io.NopCloser(strings.NewReader(c.body)),
+		},
+	}
+}
+
+func newTestAPIClient(driver setting.MailDriver, client request.Client) *APIClient {
+	settings := settingtest.NewProvider(map[string]any{
+		"mail_driver":     string(driver),
+		"mail_api_key":    "key",
+		"mail_api_domain": "mg.example.com",
+		"fromName":        "Cloudreve",
+		"fromAdress":      "no-reply@example.com",
+		"replyTo":         "support@example.com",
+		"mail_bcc_all":    "audit@example.com",
+	})
+	return NewAPIClient(settings, client, nil, logging.NewConsoleLogger(logging.LevelError))
+}
+
+func TestAPIClient_Mailgun(t *testing.T) {
+	a := assert.New(t)
+	c := &fakeClient{status: http.StatusOK, body: `{"message":"Queued"}`}
+	client := newTestAPIClient(setting.MailDriverMailgun, c)
+
+	a.NoError(client.Send(context.Background(), "user@example.com", "title", "<p>body</p>"))
+	a.Equal(http.MethodPost, c.method)
+	a.Equal("https://api.mailgun.net/v3/mg.example.com/messages", c.target)
+
+	form, err := url.ParseQuery(string(c.sent))
+	a.NoError(err)
+	a.Equal("user@example.com", form.Get("to"))
+	a.Equal("title", form.Get("subject"))
+	a.Equal("<p>body</p>", form.Get("html"))
+	a.Equal(`"Cloudreve" <no-reply@example.com>`, form.Get("from"))
+	a.Equal("support@example.com", form.Get("h:Reply-To"))
+}
+
+func TestAPIClient_SendGrid(t *testing.T) {
+	a := assert.New(t)
+	c := &fakeClient{status: http.StatusAccepted}
+	client := newTestAPIClient(setting.MailDriverSendGrid, c)
+
+	a.NoError(client.Send(context.Background(), "user@example.com", "title", "<p>body</p>"))
+	a.Equal("https://api.sendgrid.com/v3/mail/send", c.target)
+
+	payload := sendGridRequest{}
+	a.NoError(json.Unmarshal(c.sent, &payload))
+	a.Equal("user@example.com", payload.Personalizations[0].To[0].Email)
+	a.Equal("no-reply@example.com", payload.From.Email)
+	a.Equal("support@example.com", payload.ReplyTo.Email)
+	a.Equal("text/html", payload.Content[0].Type)
+	a.Equal("<p>body</p>", payload.Content[0].Value)
+}
+
+func TestAPIClient_CcBccHeaders(t *testing.T) {
+	a := assert.New(t)
+	opts := []SendOption{WithCc("Admin <admin@example.com>"), WithHeaders(map[string]string{"X-Tag": "cloudreve"})}
+
+	c := &fakeClient{status: http.StatusAccepted}
+	client := newTestAPIClient(setting.MailDriverSendGrid, c)
+	a.NoError(client.Send(context.Background(), "user@example.com", "title", "body", opts...))
+	payload := sendGridRequest{}
+	a.NoError(json.Unmarshal(c.sent, &payload))
+	a.Equal([]sendGridAddress{{Email: "admin@example.com", Name: "Admin"}}, payload.Personalizations[0].Cc)
+	a.Equal([]sendGridAddress{{Email: "audit@example.com"}}, payload.Personalizations[0].Bcc)
+	a.Equal(map[string]string{"X-Tag": "cloudreve"}, payload.Headers)
+
+	c = &fakeClient{status: http.StatusOK}
+	client = newTestAPIClient(setting.MailDriverMailgun, c)
+	a.NoError(client.Send(context.Background(), "user@example.com", "title", "body", opts...))
+	form, err := url.ParseQuery(string(c.sent))
+	a.NoError(err)
+	a.Equal("Admin <admin@example.com>", form.Get("cc"))
+	a.Equal("audit@example.com", form.Get("bcc"))
+	a.Equal("cloudreve", form.Get("h:X-Tag"))
+
+	// Invalid address is rejected before sending
+	c = &fakeClient{status: http.StatusOK}
+	client = newTestAPIClient(setting.MailDriverMailgun, c)
+	a.ErrorIs(client.Send(context.Background(), "user@example.com", "title", "body", WithBcc("invalid")), ErrInvalidSendOption)
+	a.Empty(c.method)
+}
+
+func TestAPIClient_Attachments(t *testing.T) {
+	a := assert.New(t)
+	attachments := func() []Attachment {
+		return []Attachment{{Filename: "report.csv", ContentType: "text/csv", Data: strings.NewReader("a,b")}}
+	}
+
+	// SendGrid
+	c := &fakeClient{status: http.StatusAccepted}
+	client := newTestAPIClient(setting.MailDriverSendGrid, c)
+	a.NoError(client.SendWithAttachments(context.Background(), "user@example.com", "title", "body", attachments()))
+	payload := sendGridRequest{}
+	a.NoError(json.Unmarshal(c.sent, &payload))
+	a.Len(payload.Attachments, 1)
+	a.Equal("report.csv", payload.Attachments[0].Filename)
+	a.Equal("text/csv", payload.Attachments[0].Type)
+	a.Equal("YSxi", payload.Attachments[0].Content)
+
+	// Mailgun uses multipart form
+	c = &fakeClient{status: http.StatusOK}
+	client = newTestAPIClient(setting.MailDriverMailgun, c)
+	a.NoError(client.SendWithAttachments(context.Background(), "user@example.com", "title", "body", attachments()))
+	a.Contains(string(c.sent), "filename=report.csv; name=attachment")
+	a.Contains(string(c.sent), "a,b")
+	a.Contains(string(c.sent), `name="subject"`)
+
+	// Size guard
+	client.maxAttachmentSize = 2
+	err := client.SendWithAttachments(context.Background(), "user@example.com", "title", "body", attachments())
+	a.True(errors.Is(err, ErrAttachmentTooLarge))
+}
+
+func TestAPIClient_ProviderError(t *testing.T) {
+	a := assert.New(t)
+	c := &fakeClient{status: http.StatusUnauthorized, body: `{"errors":[{"message":"invalid api key"}]}`}
+	client := newTestAPIClient(setting.MailDriverSendGrid, c)
+
+	err := client.Send(context.Background(), "user@example.com", "title", "body")
+	a.Error(err)
+	a.Contains(err.Error(), "invalid api key")
+}
+
+func TestAPIClient_UnknownDriver(t *testing.T) {
+	a := assert.New(t)
+	client := newTestAPIClient(setting.MailDriver("pigeon"), &fakeClient{})
+
+	err := client.Send(context.Background(), "user@example.com", "title", "body")
+	a.True(errors.Is(err, ErrNoActiveDriver))
+}
+
+func TestAPIClient_SkipDomains(t *testing.T) {
+	a := assert.New(t)
+	c := &fakeClient{status: http.StatusAccepted}
+	settings := settingtest.NewProvider(map[string]any{
+		"mail_driver":       string(setting.MailDriverSendGrid),
+		"mail_skip_domains": "login.qq.com, @users.noreply.example.com",
+	})
+	client := NewAPIClient(settings, c, nil, logging.NewConsoleLogger(logging.LevelError))
+
+	a.NoError(client.Send(context.Background(), "12345@login.qq.com", "title", "body"))
+	a.NoError(client.Send(context.Background(), "someone@Users.NoReply.Example.com", "title", "body"))
+	a.Empty(c.target)
+
+	// Other domains are not skipped
+	a.NoError(client.Send(context.Background(), "someone@qq.com", "title", "body"))
+	a.Equal("https://api.sendgrid.com/v3/mail/send", c.target)
+}
+
+func TestSkippedDomain(t *testing.T) {
+	a := assert.New(t)
+	domains := []string{"login.qq.com", "noreply.example.com"}
+
+	domain, ok := skippedDomain("123@LOGIN.QQ.COM", domains)
+	a.True(ok)
+	a.Equal("login.qq.com", domain)
+	_, ok = skippedDomain("user@a.noreply.example.com", domains)
+	a.False(ok)
+	_, ok = skippedDomain("user@login.qq.com", nil)
+	a.False(ok)
+}
@@ -0,0 +1,203 @@
+package email
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
+	"github.com/wneessen/go-mail"
+)
+
+const dkimMiddlewareType = mail.MiddlewareType("dkim")
+
+// dkimSignedHeaders is the list of header fields to be signed, only present ones are included.
+var dkimSignedHeaders = []string{
+	"From", "Reply-To", "Subject", "Date", "To", "Cc", "Message-ID",
+	"MIME-Version", "Content-Type", "Content-Transfer-Encoding",
+}
+
+// dkimSigner is a go-mail middleware that signs messages with DKIM (RFC 6376, Ed25519 keys per RFC 8463),
+// using relaxed/relaxed canonicalization.
+type dkimSigner struct {
+	key       crypto.Signer
+	algorithm string
+	domain    string
+	selector  string
+	l         logging.Logger
+}
+
+// newDKIMSigner creates a DKIM signer from settings. Nil is returned if no private key is configured.
+func newDKIMSigner(config *setting.DKIM, l logging.Logger) (*dkimSigner, error) {
+	if config == nil || strings.TrimSpace(config.PrivateKey) == "" {
+		return nil, nil
+	}
+
+	if config.Domain == "" || config.Selector == "" {
+		return nil, errors.New("DKIM domain and selector must be set along with private key")
+	}
+
+	block, _ := pem.Decode([]byte(config.PrivateKey))
+	if block == nil {
+		return nil, errors.New("DKIM private key is not a valid PEM block")
+	}
+
+	var (
+		key any
+		err error
+	)
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported DKIM private key type %q", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse DKIM private key: %w", err)
+	}
+
+	signer := &dkimSigner{
+		domain:   config.Domain,
+		selector: config.Selector,
+		l:        l,
+	}
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		signer.key, signer.algorithm = k, "rsa-sha256"
+	case ed25519.PrivateKey:
+		signer.key, signer.algorithm = k, "ed25519-sha256"
+	default:
+		return nil, fmt.Errorf("unsupported DKIM private key algorithm %T", key)
+	}
+
+	return signer, nil
+}
+
+func (s *dkimSigner) Type() mail.MiddlewareType {
+	return dkimMiddlewareType
+}
+
+// Handle renders the message without this middleware, and attaches the DKIM-Signature header.
+func (s *dkimSigner) Handle(m *mail.Msg) *mail.Msg {
+	buf := &bytes.Buffer{}
+	if _, err := m.WriteToSkipMiddleware(buf, dkimMiddlewareType); err != nil {
+		s.l.Warning("Failed to render email for DKIM signing: %s", err)
+		return m
+	}
+
+	signature, err := s.sign(buf.Bytes(), time.Now())
+	if err != nil {
+		s.l.Warning("Failed to sign email with DKIM: %s", err)
+		return m
+	}
+
+	m.SetGenHeaderPreformatted("DKIM-Signature", signature)
+	return m
+}
+
+// sign computes the DKIM-Signature header value of given raw message.
+func (s *dkimSigner) sign(raw []byte, now time.Time) (string, error) {
+	header, body, found := bytes.Cut(raw, []byte("\r\n\r\n"))
+	if !found {
+		return "", errors.New("malformed message: header terminator not found")
+	}
+
+	bodyHash := sha256.Sum256(dkimCanonicalizeBody(body))
+	fields := dkimParseHeader(header)
+
+	signed := make([]string, 0, len(dkimSignedHeaders))
+	hashed := &bytes.Buffer{}
+	for _, name := range dkimSignedHeaders {
+		if field, ok := dkimLastField(fields, name); ok {
+			signed = append(signed, strings.ToLower(name))
+			hashed.WriteString(dkimCanonicalizeHeader(field))
+		}
+	}
+
+	value := fmt.Sprintf("v=1; a=%s; c=relaxed/relaxed; d=%s; s=%s; t=%d; h=%s; bh=%s; b=",
+		s.algorithm, s.domain, s.selector, now.Unix(), strings.Join(signed, ":"),
+		base64.StdEncoding.EncodeToString(bodyHash[:]))
+	hashed.WriteString(strings.TrimSuffix(dkimCanonicalizeHeader("DKIM-Signature: "+value), "\r\n"))
+
+	digest := sha256.Sum256(hashed.Bytes())
+	opts := crypto.SignerOpts(crypto.SHA256)
+	if _, ok := s.key.(ed25519.PrivateKey); ok {
+		opts = crypto.Hash(0)
+	}
+
+	sig, err := s.key.Sign(rand.Reader, digest[:], opts)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign: %w", err)
+	}
+
+	return value + base64.StdEncoding.EncodeToString(sig), nil
+}
+
+// dkimParseHeader splits raw header block into fields, folded lines are kept with their field.
+func dkimParseHeader(header []byte) []string {
+	var fields []string
+	for _, line := range strings.Split(string(header), "\r\n") {
+		if len(fields) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			fields[len(fields)-1] += "\r\n" + line
+			continue
+		}
+
+		fields = append(fields, line)
+	}
+
+	return fields
+}
+
+// dkimLastField finds the last occurrence of header field with given name.
+func dkimLastField(fields []string, name string) (string, bool) {
+	for i := len(fields) - 1; i >= 0; i-- {
+		k, _, ok := strings.Cut(fields[i], ":")
+		if ok && strings.EqualFold(strings.TrimSpace(k), name) {
+			return fields[i], true
+		}
+	}
+
+	return "", false
+}
+
+// dkimCanonicalizeHeader applies relaxed header canonicalization (RFC 6376 3.4.2).
+func dkimCanonicalizeHeader(field string) string {
+	k, v, _ := strings.Cut(field, ":")
+	v = strings.NewReplacer("\r\n", "").Replace(v)
+	return strings.ToLower(strings.TrimSpace(k)) + ":" + strings.Join(strings.Fields(v), " ") + "\r\n"
+}
+
+// dkimCanonicalizeBody applies relaxed body canonicalization (RFC 6376 3.4.4).
+func dkimCanonicalizeBody(body []byte) []byte {
+	lines := strings.Split(string(body), "\r\n")
+	for i, line := range lines {
+		line = strings.TrimRight(strings.ReplaceAll(line, "\t", " "), " ")
+		for strings.Contains(line, "  ") {
+			line = strings.ReplaceAll(line, "  ", " ")
+		}
+		lines[i] = line
+	}
+
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	if len(lines) == 0 {
+		return nil
+	}
+
+	return []byte(strings.Join(lines, "\r\n") + "\r\n")
+}
@@ -3,6 +3,13 @@ package email
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
+	netmail "net/mail"
+	"strings"
+
+	"github.com/cloudreve/Cloudreve/v4/ent"
+	"github.com/samber/lo"
 )
 
 // Driver 邮件发送驱动
@@ -10,7 +17,100 @@ type Driver interface {
 	// Close 关闭驱动
 	Close()
 	// Send 发送邮件
-	Send(ctx context.Context, to, title, body string) error
+	Send(ctx context.Context, to, title, body string, opts ...SendOption) error
+	// SendWithAttachments sends email with given attachments.
+	SendWithAttachments(ctx context.Context, to, title, body string, attachments []Attachment, opts ...SendOption) error
+}
+
+// GracefulCloser is implemented by drivers able to finish sending queued emails before closing.
+type GracefulCloser interface {
+	// CloseWithContext closes the driver after queued emails are sent, or ctx is done.
+	CloseWithContext(ctx context.Context) error
+}
+
+// StatusReporter is implemented by drivers sending emails through an async queue.
+type StatusReporter interface {
+	// Status returns the queue status, and the error if the driver failed to initialize.
+	Status() (*QueueStatus, error)
+}
+
+// skippedDomain returns the matched domain if recipient address is in one of the skipped
+// domains. These are usually non-deliverable placeholder addresses of social logins.
+func skippedDomain(to string, domains []string) (string, bool) {
+	to = strings.ToLower(to)
+	for _, domain := range domains {
+		if strings.HasSuffix(to, "@"+strings.ToLower(strings.TrimPrefix(domain, "@"))) {
+			return domain, true
+		}
+	}
+
+	return "", false
+}
+
+// SendOption sets optional recipients and headers of an email.
+type SendOption func(o *sendOptions)
+
+type sendOptions struct {
+	cc        []string
+	bcc       []string
+	headers   map[string]string
+	plainBody string
+	rateLimit *ent.User
+}
+
+// WithCc adds carbon copy recipients.
+func WithCc(addrs ...string) SendOption {
+	return func(o *sendOptions) {
+		o.cc = append(o.cc, addrs...)
+	}
+}
+
+// WithBcc adds blind carbon copy recipients.
+func WithBcc(addrs ...string) SendOption {
+	return func(o *sendOptions) {
+		o.bcc = append(o.bcc, addrs...)
+	}
+}
+
+// WithHeaders adds custom headers, existing headers with the same name are overwritten.
+func WithHeaders(headers map[string]string) SendOption {
+	return func(o *sendOptions) {
+		if o.headers == nil {
+			o.headers = make(map[string]string, len(headers))
+		}
+		for k, v := range headers {
+			o.headers[k] = v
+		}
+	}
+}
+
+// WithPlainBody adds a plaintext alternative of the HTML body.
+func WithPlainBody(body string) SendOption {
+	return func(o *sendOptions) {
+		o.plainBody = body
+	}
+}
+
+// WithRateLimit charges the email to hourly quota of given user, limited by the user's group.
+// Emails sent without it, e.g. triggered by system or admins, are not limited.
+func WithRateLimit(u *ent.User) SendOption {
+	return func(o *sendOptions) {
+		o.rateLimit = u
+	}
+}
+
+// Attachment is a file attached to the email.
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        io.Reader
+}
+
+// bufferedAttachment is an attachment whose content is fully read into memory.
+type bufferedAttachment struct {
+	Filename    string
+	ContentType string
+	data        []byte
 }
 
 var (
@@ -18,4 +118,84 @@ var (
 	ErrChanNotOpen = errors.New("email queue is not started")
 	// ErrNoActiveDriver 无可用邮件发送服务
 	ErrNoActiveDriver = errors.New("no avaliable email provider")
+	// ErrAttachmentTooLarge 附件总大小超出限制
+	ErrAttachmentTooLarge = errors.New("email attachments exceed size limit")
+	// ErrInvalidSendOption 抄送地址或自定义头无效
+	ErrInvalidSendOption = errors.New("invalid email recipient or header")
+
+	// reservedHeaders are managed by the sender and cannot be overwritten by custom headers.
+	reservedHeaders = []string{"From", "To", "Cc", "Bcc", "Subject", "Reply-To", "Date", "Message-ID",
+		"Mime-Version", "Content-Type", "Content-Transfer-Encoding", "DKIM-Signature"}
 )
+
+// newSendOptions applies given options on top of defaults, and validates the result
+// so that malformed values are rejected before the email is queued.
+func newSendOptions(defaults []SendOption, opts []SendOption) (*sendOptions, error) {
+	o := &sendOptions{}
+	for _, opt := range append(defaults, opts...) {
+		opt(o)
+	}
+
+	for _, addr := range append(append([]string{}, o.cc...), o.bcc...) {
+		if _, err := netmail.ParseAddress(addr); err != nil {
+			return nil, fmt.Errorf("%w: address %q: %s", ErrInvalidSendOption, addr, err)
+		}
+	}
+
+	for k, v := range o.headers {
+		if !validHeaderName(k) {
+			return nil, fmt.Errorf("%w: header name %q", ErrInvalidSendOption, k)
+		}
+
+		if strings.ContainsAny(v, "\r\n") {
+			return nil, fmt.Errorf("%w: value of header %q contains line break", ErrInvalidSendOption, k)
+		}
+
+		if lo.ContainsBy(reservedHeaders, func(h string) bool { return strings.EqualFold(h, k) }) {
+			return nil, fmt.Errorf("%w: header %q is reserved", ErrInvalidSendOption, k)
+		}
+	}
+
+	return o, nil
+}
+
+// validHeaderName checks header field name against RFC 5322, printable US-ASCII except colon.
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for _, c := range name {
+		if c <= ' ' || c > '~' || c == ':' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// bufferAttachments reads all attachments into memory, total size is bounded by limit, 0 for unlimited.
+func bufferAttachments(attachments []Attachment, limit int64) ([]bufferedAttachment, error) {
+	res := make([]bufferedAttachment, 0, len(attachments))
+	var total int64
+	for _, a := range attachments {
+		r := a.Data
+		if limit > 0 {
+			r = io.LimitReader(a.Data, limit-total+1)
+		}
+
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read attachment %q: %w", a.Filename, err)
+		}
+
+		total += int64(len(data))
+		if limit > 0 && total > limit {
+			return nil, fmt.Errorf("attachment %q: %w (%d bytes)", a.Filename, ErrAttachmentTooLarge, limit)
+		}
+
+		res = append(res, bufferedAttachment{Filename: a.Filename, ContentType: a.ContentType, data: data})
+	}
+
+	return res, nil
+}
@@ -0,0 +1,72 @@
+package email
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wneessen/go-mail"
+)
+
+func TestBufferAttachments(t *testing.T) {
+	a := assert.New(t)
+
+	res, err := bufferAttachments([]Attachment{
+		{Filename: "a.txt", ContentType: "text/plain", Data: strings.NewReader("hello")},
+		{Filename: "b.txt", Data: strings.NewReader("world")},
+	}, 10)
+	a.NoError(err)
+	a.Len(res, 2)
+	a.Equal("hello", string(res[0].data))
+	a.Equal("text/plain", res[0].ContentType)
+	a.Equal("world", string(res[1].data))
+
+	// Total size exceeds limit
+	_, err = bufferAttachments([]Attachment{
+		{Filename: "a.txt", Data: strings.NewReader("hello")},
+		{Filename: "b.txt", Data: strings.NewReader("world!")},
+	}, 10)
+	a.True(errors.Is(err, ErrAttachmentTooLarge))
+
+	// Unlimited
+	res, err = bufferAttachments([]Attachment{
+		{Filename: "a.txt", Data: strings.NewReader(strings.Repeat("a", 1024))},
+	}, 0)
+	a.NoError(err)
+	a.Len(res[0].data, 1024)
+}
+
+func TestAttachToMsg(t *testing.T) {
+	a := assert.New(t)
+	m := &message{
+		msg:         mail.NewMsg(),
+		attachments: []bufferedAttachment{{Filename: "report.csv", ContentType: "text/csv", data: []byte("a,b")}},
+	}
+	a.NoError(attachToMsg(m))
+	a.Nil(m.attachments)
+	a.Len(m.msg.GetAttachments(), 1)
+	a.Equal("report.csv", m.msg.GetAttachments()[0].Name)
+}
+
+func TestNewSendOptions(t *testing.T) {
+	a := assert.New(t)
+
+	o, err := newSendOptions(
+		[]SendOption{WithBcc("audit@example.com"), WithHeaders(map[string]string{"X-Tag": "default"})},
+		[]SendOption{WithCc("Admin <admin@example.com>"), WithHeaders(map[string]string{"X-Tag": "custom"})},
+	)
+	a.NoError(err)
+	a.Equal([]string{"Admin <admin@example.com>"}, o.cc)
+	a.Equal([]string{"audit@example.com"}, o.bcc)
+	a.Equal(map[string]string{"X-Tag": "custom"}, o.headers)
+
+	_, err = newSendOptions(nil, []SendOption{WithBcc("not an address")})
+	a.ErrorIs(err, ErrInvalidSendOption)
+	_, err = newSendOptions(nil, []SendOption{WithHeaders(map[string]string{"X Tag": "v"})})
+	a.ErrorIs(err, ErrInvalidSendOption)
+	_, err = newSendOptions(nil, []SendOption{WithHeaders(map[string]string{"X-Tag": "v\r\nBcc: evil@example.com"})})
+	a.ErrorIs(err, ErrInvalidSendOption)
+	_, err = newSendOptions(nil, []SendOption{WithHeaders(map[string]string{"subject": "v"})})
+	a.ErrorIs(err, ErrInvalidSendOption)
+}
@@ -0,0 +1,104 @@
+package email
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// blockTags are rendered as line breaks in plaintext.
+var blockTags = map[string]bool{
+	"br": true, "p": true, "div": true, "tr": true, "li": true, "table": true, "hr": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+}
+
+// htmlToPlain generates a plaintext version of the HTML email by stripping tags. Targets of
+// links are kept so that action buttons like activation links are still usable.
+func htmlToPlain(body string) string {
+	var (
+		sb      strings.Builder
+		skip    int
+		links   []string
+		linkPos []int
+	)
+
+	z := html.NewTokenizer(strings.NewReader(body))
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			return normalizePlain(sb.String())
+		case html.TextToken:
+			if skip == 0 {
+				sb.Write(z.Text())
+			}
+		case html.StartTagToken, html.EndTagToken, html.SelfClosingTagToken:
+			name, _ := z.TagName()
+			tag := string(name)
+			switch {
+			case tag == "style" || tag == "script" || tag == "title" || tag == "head":
+				if tt == html.StartTagToken {
+					skip++
+				} else if tt == html.EndTagToken && skip > 0 {
+					skip--
+				}
+			case tag == "a" && tt == html.StartTagToken:
+				links = append(links, linkTarget(z))
+				linkPos = append(linkPos, sb.Len())
+			case tag == "a" && tt == html.EndTagToken && len(links) > 0:
+				href, pos := links[len(links)-1], linkPos[len(linkPos)-1]
+				links, linkPos = links[:len(links)-1], linkPos[:len(linkPos)-1]
+				text := strings.TrimSpace(sb.String()[pos:])
+				if href == "" || text == href {
+					break
+				}
+
+				if text == "" {
+					sb.WriteString(href)
+					break
+				}
+
+				// Keep the target on the same line as link text
+				trimmed := strings.TrimRight(sb.String(), " \t\r\n")
+				sb.Reset()
+				sb.WriteString(trimmed + " (" + href + ")")
+			case blockTags[tag]:
+				sb.WriteByte('\n')
+			}
+		}
+	}
+}
+
+// linkTarget returns href of current <a> tag, anchors and mailto links are ignored.
+func linkTarget(z *html.Tokenizer) string {
+	for {
+		key, val, more := z.TagAttr()
+		if string(key) == "href" {
+			href := strings.TrimSpace(string(val))
+			if strings.HasPrefix(href, "#") || strings.HasPrefix(href, "mailto:") {
+				return ""
+			}
+			return href
+		}
+
+		if !more {
+			return ""
+		}
+	}
+}
+
+// normalizePlain collapses whitespaces in each line, and consecutive blank lines into one.
+func normalizePlain(text string) string {
+	lines := strings.Split(text, "\n")
+	res := make([]string, 0, len(lines))
+	for _, line := range lines {
+		line = strings.Join(strings.Fields(line), " ")
+		if line == "" && (len(res) == 0 || res[len(res)-1] == "") {
+			continue
+		}
+
+		res = append(res, line)
+	}
+
+	return strings.TrimSpace(strings.Join(res, "\n"))
+}
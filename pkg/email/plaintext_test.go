@@ -0,0 +1,78 @@
+package email
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHtmlToPlain(t *testing.T) {
+	a := assert.New(t)
+	body := `<html><title>Ignored</title><style>p{color:red}</style><!--[if mso]><p>Outlook only</p><![endif]-->` +
+		`<body><a href="https://example.com"><img src="logo.png"></a><h1>Activate  your account</h1>` +
+		`<p>Hi Alice,<br>click the button below &amp; get started.</p>` +
+		`<a href="https://example.com/activate?id=1&amp;sign=abc"><table><tr><td><span>Activate</span></table></a>` +
+		`<p><a href="https://example.com/help">https://example.com/help</a> <a href="mailto:admin@example.com">Contact</a></p>` +
+		`<div>&nbsp;&nbsp;</div></body></html>`
+
+	a.Equal("https://example.com\n"+
+		"Activate your account\n\n"+
+		"Hi Alice,\n"+
+		"click the button below & get started.\n\n"+
+		"Activate (https://example.com/activate?id=1&sign=abc)\n"+
+		"https://example.com/help Contact", htmlToPlain(body))
+	a.Equal("", htmlToPlain(""))
+}
+
+func TestRenderEmail(t *testing.T) {
+	a := assert.New(t)
+	data := map[string]string{"Name": "<Alice>", "Url": "https://example.com/reset?a=1&b=2"}
+
+	// Plaintext generated from HTML body
+	title, body, plain, err := renderEmail("reset", setting.EmailTemplate{
+		Title: "Reset {{ .Name }}",
+		Body:  `<p>Hi {{ .Name }}</p><a href="{{ .Url }}">Reset</a>`,
+	}, data)
+	a.NoError(err)
+	a.Equal("Reset &lt;Alice&gt;", title)
+	a.Equal(`<p>Hi &lt;Alice&gt;</p><a href="https://example.com/reset?a=1&amp;b=2">Reset</a>`, body)
+	a.Equal("Hi <Alice>\nReset (https://example.com/reset?a=1&b=2)", plain)
+
+	// Plaintext template is rendered without HTML escaping
+	_, _, plain, err = renderEmail("reset", setting.EmailTemplate{
+		Body:      `<p>Hi {{ .Name }}</p>`,
+		PlainBody: "Hi {{ .Name }}, reset at {{ .Url }}",
+	}, data)
+	a.NoError(err)
+	a.Equal("Hi <Alice>, reset at https://example.com/reset?a=1&b=2", plain)
+
+	_, _, _, err = renderEmail("reset", setting.EmailTemplate{Body: "<p></p>", PlainBody: "{{ .Name"}, data)
+	a.Error(err)
+}
+
+func TestSetPlainAlternative(t *testing.T) {
+	a := assert.New(t)
+	m, err := newMsg(&setting.SMTP{From: "no-reply@example.com"}, "user@example.com", "title", "<p>Hello</p>")
+	a.NoError(err)
+	setPlainAlternative(m, "<p>Hello</p>", "Hello")
+
+	buf := &bytes.Buffer{}
+	_, err = m.WriteTo(buf)
+	a.NoError(err)
+	raw := buf.String()
+	a.Contains(raw, "multipart/alternative")
+	plainPos, htmlPos := strings.Index(raw, "Content-Type: text/plain"), strings.Index(raw, "Content-Type: text/html")
+	a.True(plainPos >= 0 && htmlPos > plainPos, "HTML part should be the last and preferred one")
+
+	// No alternative without plaintext body
+	m, err = newMsg(&setting.SMTP{From: "no-reply@example.com"}, "user@example.com", "title", "<p>Hello</p>")
+	a.NoError(err)
+	setPlainAlternative(m, "<p>Hello</p>", "")
+	buf.Reset()
+	_, err = m.WriteTo(buf)
+	a.NoError(err)
+	a.NotContains(buf.String(), "multipart/alternative")
+}
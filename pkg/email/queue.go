@@ -0,0 +1,277 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/cloudreve/Cloudreve/v4/pkg/metrics"
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
+	"github.com/wneessen/go-mail"
+)
+
+const (
+	// maxRetryDelay caps the exponential backoff between retries of a failed email.
+	maxRetryDelay = 30 * time.Minute
+	// queueDrainTimeout is how long the worker keeps sending queued emails after the queue
+	// is closed, emails left after that are dropped.
+	queueDrainTimeout = 30 * time.Second
+	// defaultQueueSize is the buffer size of the sending queue if not configured.
+	defaultQueueSize = 30
+	// enqueueTimeout is how long a sender waits for space in a full queue.
+	enqueueTimeout = 5 * time.Second
+)
+
+var (
+	// ErrQueueFull is returned when the sending queue stays full for enqueueTimeout.
+	ErrQueueFull   = errors.New("email queue is full, please try again later")
+	errQueueClosed = errors.New("email queue is closed")
+)
+
+type message struct {
+	msg         *mail.Msg
+	to          string
+	subject     string
+	cid         string
+	userID      int
+	attachments []bufferedAttachment
+	cc          []string
+	bcc         []string
+	headers     map[string]string
+	attempt     int
+}
+
+// mailQueue is the async sending queue shared by queue based drivers like SMTP and SES.
+type mailQueue struct {
+	ch             chan *message
+	driver         setting.MailDriver
+	maxRetry       int
+	retryInterval  int
+	enqueueTimeout time.Duration
+
+	// mu guards ch against being closed while messages are being enqueued.
+	mu        sync.RWMutex
+	closed    bool
+	closedAt  time.Time
+	done      chan struct{}
+	closeOnce sync.Once
+	failed    atomic.Int64
+
+	// stopped is closed once the worker exits after sending all queued messages.
+	stopped  chan struct{}
+	stopOnce sync.Once
+}
+
+// QueueStatus is a snapshot of the sending queue.
+type QueueStatus struct {
+	// Pending is the number of emails waiting to be sent, excluding those waiting for retry.
+	Pending int `json:"pending"`
+	// Failed is the number of emails dropped after exhausting all retries.
+	Failed int64 `json:"failed"`
+	Closed bool  `json:"closed"`
+}
+
+func newMailQueue(driver setting.MailDriver, size, maxRetry, retryInterval int) *mailQueue {
+	if size <= 0 {
+		size = defaultQueueSize
+	}
+
+	return &mailQueue{
+		ch:             make(chan *message, size),
+		driver:         driver,
+		maxRetry:       maxRetry,
+		retryInterval:  retryInterval,
+		enqueueTimeout: enqueueTimeout,
+		done:           make(chan struct{}),
+		stopped:        make(chan struct{}),
+	}
+}
+
+// enqueue pushes message into the sending queue, it returns error if the queue is closed,
+// or ErrQueueFull if the queue is still full after enqueueTimeout.
+func (q *mailQueue) enqueue(m *message) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return errQueueClosed
+	}
+
+	timer := time.NewTimer(q.enqueueTimeout)
+	defer timer.Stop()
+	select {
+	case q.ch <- m:
+		return nil
+	case <-q.done:
+		return errQueueClosed
+	case <-timer.C:
+		return ErrQueueFull
+	}
+}
+
+// close closes the sending queue, pending senders are released with error.
+func (q *mailQueue) close() {
+	// Unblock pending senders first so that they release the read lock.
+	q.closeOnce.Do(func() { close(q.done) })
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.closed {
+		q.closed = true
+		q.closedAt = time.Now()
+		close(q.ch)
+	}
+}
+
+// isClosed reports whether the queue is closed intentionally.
+func (q *mailQueue) isClosed() bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return q.closed
+}
+
+// drainExpired reports whether the queue has been closed for longer than queueDrainTimeout.
+func (q *mailQueue) drainExpired() bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return q.closed && time.Since(q.closedAt) > queueDrainTimeout
+}
+
+// dropPending drops all messages left in a closed queue, counting them as failures.
+func (q *mailQueue) dropPending(l logging.Logger) {
+	for m := range q.ch {
+		q.fail(m, l, errQueueClosed)
+	}
+}
+
+// finish is called by the worker when it exits after the queue is closed and drained.
+func (q *mailQueue) finish() {
+	q.stopOnce.Do(func() { close(q.stopped) })
+}
+
+// closeWithContext closes the queue and waits until the worker sends all queued messages,
+// or ctx is done. Messages waiting for retry are not waited for.
+func (q *mailQueue) closeWithContext(ctx context.Context) error {
+	q.close()
+	select {
+	case <-q.stopped:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%d email(s) still in queue: %w", len(q.ch), ctx.Err())
+	}
+}
+
+// FailedCount returns the number of emails dropped after exhausting all retries.
+func (q *mailQueue) FailedCount() int64 {
+	return q.failed.Load()
+}
+
+func (q *mailQueue) status() *QueueStatus {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return &QueueStatus{
+		Pending: len(q.ch),
+		Failed:  q.failed.Load(),
+		Closed:  q.closed,
+	}
+}
+
+// sent records a message delivered successfully.
+func (q *mailQueue) sent(m *message, l logging.Logger) {
+	metrics.EmailsSent.WithLabelValues(string(q.driver), metrics.ResultSuccess).Inc()
+	l.Info("Email sent to %q, title: %q.", m.to, m.subject)
+}
+
+// fail records a message that will not be delivered.
+func (q *mailQueue) fail(m *message, l logging.Logger, err error) {
+	q.failed.Add(1)
+	metrics.EmailsSent.WithLabelValues(string(q.driver), metrics.ResultFailure).Inc()
+	l.Error("Failed to send email to %q: %s", m.to, err)
+}
+
+// retryLater requeues a failed message after a backoff delay, or gives up if max retry is reached.
+// Requeueing happens in a timer goroutine so that the worker is never blocked on its own queue.
+func (q *mailQueue) retryLater(m *message, l logging.Logger, err error) {
+	if m.attempt >= q.maxRetry {
+		q.fail(m, l, fmt.Errorf("giving up after %d attempt(s): %w", m.attempt+1, err))
+		return
+	}
+
+	m.attempt++
+	metrics.EmailsSent.WithLabelValues(string(q.driver), metrics.ResultRetry).Inc()
+	delay := retryDelay(q.retryInterval, m.attempt)
+	l.Warning("Failed to send email: %s, will retry in %s (%d/%d).", err, delay, m.attempt, q.maxRetry)
+	time.AfterFunc(delay, func() {
+		if err := q.enqueue(m); err != nil {
+			q.fail(m, l, fmt.Errorf("failed to requeue: %w", err))
+		}
+	})
+}
+
+// retryDelay returns the delay before given attempt, doubled from base interval (in seconds) on each attempt.
+func retryDelay(interval, attempt int) time.Duration {
+	delay := time.Duration(max(interval, 1)) * time.Second
+	for i := 1; i < attempt && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, maxRetryDelay)
+}
+
+// defaultSendOptions returns the options applied to all emails sent by queue based drivers.
+func defaultSendOptions(config setting.Provider) []SendOption {
+	ctx := context.Background()
+	return []SendOption{
+		WithBcc(config.MailBccAll(ctx)...),
+		WithHeaders(config.MailExtraHeaders(ctx)),
+	}
+}
+
+// prepareMsg applies recipients, headers and attachments carried by the queued message.
+// It is safe to call it again when the message is retried.
+func prepareMsg(m *message) error {
+	if len(m.cc) > 0 {
+		if err := m.msg.Cc(m.cc...); err != nil {
+			return fmt.Errorf("failed to set CC: %w", err)
+		}
+	}
+
+	if len(m.bcc) > 0 {
+		if err := m.msg.Bcc(m.bcc...); err != nil {
+			return fmt.Errorf("failed to set BCC: %w", err)
+		}
+	}
+
+	for k, v := range m.headers {
+		m.msg.SetGenHeader(mail.Header(k), v)
+	}
+
+	return attachToMsg(m)
+}
+
+// setPlainAlternative adds the plaintext version of the HTML body. Parts of multipart/alternative
+// are ordered by increasing preference, so plaintext goes first and HTML stays as the preferred one.
+func setPlainAlternative(m *mail.Msg, html, plain string) {
+	if plain == "" {
+		return
+	}
+
+	m.SetBodyString(mail.TypeTextPlain, plain)
+	m.AddAlternativeString(mail.TypeTextHTML, html)
+}
+
+// newMsg builds the HTML email sent from configured sender.
+func newMsg(sender *setting.SMTP, to, title, body string, opts ...mail.MsgOption) (*mail.Msg, error) {
+	m := mail.NewMsg(opts...)
+	if err := m.FromFormat(sender.FromName, sender.From); err != nil {
+		return nil, err
+	}
+	m.ReplyToFormat(sender.FromName, sender.ReplyTo)
+	m.To(to)
+	m.Subject(title)
+	m.SetMessageID()
+	m.SetBodyString(mail.TypeTextHTML, body)
+	return m, nil
+}
@@ -0,0 +1,178 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/cloudreve/Cloudreve/v4/pkg/metrics"
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestMailQueue(maxRetry int) *mailQueue {
+	q := newMailQueue(setting.MailDriverSMTP, 0, maxRetry, 1)
+	q.ch = make(chan *message, 1)
+	return q
+}
+
+func TestRetryDelay(t *testing.T) {
+	a := assert.New(t)
+	a.Equal(30*time.Second, retryDelay(30, 1))
+	a.Equal(60*time.Second, retryDelay(30, 2))
+	a.Equal(120*time.Second, retryDelay(30, 3))
+	a.Equal(time.Second, retryDelay(0, 1))
+	a.Equal(maxRetryDelay, retryDelay(30, 100))
+}
+
+func TestMailQueue_RetryLater(t *testing.T) {
+	a := assert.New(t)
+	client := newTestMailQueue(1)
+	l := logging.NewConsoleLogger(logging.LevelError)
+	m := &message{to: "user@example.com"}
+	retried := metrics.EmailsSent.WithLabelValues(string(setting.MailDriverSMTP), metrics.ResultRetry)
+	failed := metrics.EmailsSent.WithLabelValues(string(setting.MailDriverSMTP), metrics.ResultFailure)
+	retriedBefore, failedBefore := testutil.ToFloat64(retried), testutil.ToFloat64(failed)
+
+	client.retryLater(m, l, errors.New("connection reset"))
+	select {
+	case requeued := <-client.ch:
+		a.Same(m, requeued)
+		a.Equal(1, requeued.attempt)
+	case <-time.After(3 * time.Second):
+		a.Fail("failed message is not requeued")
+	}
+
+	// Max retry reached, message is dropped and counted as failure.
+	client.retryLater(m, l, errors.New("connection reset"))
+	a.EqualValues(1, client.FailedCount())
+	a.Empty(client.ch)
+	a.Equal(retriedBefore+1, testutil.ToFloat64(retried))
+	a.Equal(failedBefore+1, testutil.ToFloat64(failed))
+}
+
+func TestMailQueue_CloseWithPendingSend(t *testing.T) {
+	a := assert.New(t)
+	client := newTestMailQueue(1)
+	a.NoError(client.enqueue(&message{}))
+	a.Equal(&QueueStatus{Pending: 1}, client.status())
+
+	// Queue is full, sender is blocked until pool is closed.
+	errs := make(chan error, 1)
+	go func() {
+		errs <- client.enqueue(&message{})
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	client.close()
+	client.close()
+	select {
+	case err := <-errs:
+		a.Error(err)
+	case <-time.After(time.Second):
+		a.Fail("pending sender is not released after close")
+	}
+	a.Error(client.enqueue(&message{}))
+	a.True(client.status().Closed)
+}
+
+func TestMailQueue_CloseWithContext(t *testing.T) {
+	a := assert.New(t)
+
+	// Worker sends all queued messages before exiting
+	{
+		client := newTestMailQueue(1)
+		client.ch = make(chan *message, 2)
+		a.NoError(client.enqueue(&message{}))
+		a.NoError(client.enqueue(&message{}))
+		sent := 0
+		go func() {
+			for range client.ch {
+				sent++
+			}
+			client.finish()
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		a.NoError(client.closeWithContext(ctx))
+		a.Equal(2, sent)
+	}
+
+	// Worker not able to finish before deadline
+	{
+		client := newTestMailQueue(1)
+		a.NoError(client.enqueue(&message{}))
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		err := client.closeWithContext(ctx)
+		a.ErrorIs(err, context.DeadlineExceeded)
+		a.Contains(err.Error(), "1 email(s)")
+	}
+}
+
+func TestPrepareMsg(t *testing.T) {
+	a := assert.New(t)
+	msg, err := newMsg(&setting.SMTP{From: "no-reply@example.com"}, "user@example.com", "title", "body")
+	a.NoError(err)
+	m := &message{
+		msg:     msg,
+		cc:      []string{"cc@example.com"},
+		bcc:     []string{"audit@example.com"},
+		headers: map[string]string{"X-Tag": "cloudreve"},
+	}
+
+	// Applying twice on retry doesn't duplicate recipients
+	a.NoError(prepareMsg(m))
+	a.NoError(prepareMsg(m))
+	rcpts, err := m.msg.GetRecipients()
+	a.NoError(err)
+	a.Equal([]string{"user@example.com", "cc@example.com", "audit@example.com"}, rcpts)
+
+	buf := &bytes.Buffer{}
+	_, err = m.msg.WriteTo(buf)
+	a.NoError(err)
+	a.Contains(buf.String(), "Cc: <cc@example.com>")
+	a.Contains(buf.String(), "X-Tag: cloudreve")
+	a.NotContains(buf.String(), "audit@example.com")
+}
+
+func TestMailQueue_DrainExpired(t *testing.T) {
+	a := assert.New(t)
+	client := newTestMailQueue(1)
+	a.NoError(client.enqueue(&message{}))
+	a.False(client.drainExpired())
+
+	client.close()
+	a.True(client.isClosed())
+	a.False(client.drainExpired())
+	client.closedAt = time.Now().Add(-queueDrainTimeout - time.Second)
+	a.True(client.drainExpired())
+
+	client.dropPending(logging.NewConsoleLogger(logging.LevelError))
+	a.EqualValues(1, client.FailedCount())
+	a.Empty(client.ch)
+}
+
+func TestMailQueue_Full(t *testing.T) {
+	a := assert.New(t)
+	a.Equal(defaultQueueSize, cap(newMailQueue(setting.MailDriverSMTP, 0, 0, 0).ch))
+	a.Equal(100, cap(newMailQueue(setting.MailDriverSMTP, 100, 0, 0).ch))
+
+	client := newTestMailQueue(1)
+	client.enqueueTimeout = 10 * time.Millisecond
+	a.NoError(client.enqueue(&message{}))
+	a.ErrorIs(client.enqueue(&message{}), ErrQueueFull)
+
+	// Sender waiting for space succeeds once worker takes a message
+	client.enqueueTimeout = time.Second
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		<-client.ch
+	}()
+	a.NoError(client.enqueue(&message{}))
+}
@@ -0,0 +1,67 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cloudreve/Cloudreve/v4/ent"
+	"github.com/cloudreve/Cloudreve/v4/inventory"
+	"github.com/cloudreve/Cloudreve/v4/pkg/cache"
+)
+
+const (
+	rateLimitPrefix = "email_rate_"
+	rateLimitWindow = 3600 // 1 hour
+)
+
+// ErrRateLimited is returned when a user has been sent more emails than allowed by group
+// within the current hour.
+var ErrRateLimited = errors.New("too many emails sent to this account, please try again later")
+
+// RateLimiter caps number of emails triggered by a user per hour, limit is set by the
+// EmailRateLimit group setting. Counters are kept in KV so that they are shared across
+// instances.
+type RateLimiter struct {
+	kv     cache.Driver
+	groups inventory.GroupClient
+}
+
+// NewRateLimiter creates a new email rate limiter.
+func NewRateLimiter(kv cache.Driver, groups inventory.GroupClient) *RateLimiter {
+	return &RateLimiter{kv: kv, groups: groups}
+}
+
+// Allow charges one email to the user's quota of current hour, it returns ErrRateLimited
+// if the quota is used up. A nil limiter or user is never limited.
+func (r *RateLimiter) Allow(ctx context.Context, u *ent.User) error {
+	if r == nil || u == nil {
+		return nil
+	}
+
+	group := u.Edges.Group
+	if group == nil {
+		g, err := r.groups.GetByID(ctx, u.GroupUsers)
+		if err != nil {
+			return fmt.Errorf("failed to get user group: %w", err)
+		}
+		group = g
+	}
+
+	if group.Settings == nil || group.Settings.EmailRateLimit <= 0 {
+		return nil
+	}
+
+	key := fmt.Sprintf("%s%d_%d", rateLimitPrefix, u.ID, time.Now().Unix()/rateLimitWindow)
+	sent := 0
+	if v, ok := r.kv.Get(key); ok {
+		sent, _ = v.(int)
+	}
+
+	if sent >= group.Settings.EmailRateLimit {
+		return ErrRateLimited
+	}
+
+	return r.kv.Set(key, sent+1, rateLimitWindow)
+}
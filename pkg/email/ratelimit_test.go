@@ -0,0 +1,72 @@
+package email
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/cloudreve/Cloudreve/v4/ent"
+	"github.com/cloudreve/Cloudreve/v4/inventory"
+	"github.com/cloudreve/Cloudreve/v4/inventory/types"
+	"github.com/cloudreve/Cloudreve/v4/pkg/cache"
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeGroupClient struct {
+	inventory.GroupClient
+	groups map[int]*ent.Group
+}
+
+func (c *fakeGroupClient) GetByID(ctx context.Context, id int) (*ent.Group, error) {
+	return c.groups[id], nil
+}
+
+func TestRateLimiter_Allow(t *testing.T) {
+	a := assert.New(t)
+	ctx := context.Background()
+	groups := &fakeGroupClient{groups: map[int]*ent.Group{
+		1: {ID: 1, Settings: &types.GroupSetting{}},
+		2: {ID: 2, Settings: &types.GroupSetting{EmailRateLimit: 2}},
+	}}
+	limiter := NewRateLimiter(cache.NewMemoStore("", logging.NewConsoleLogger(logging.LevelError)), groups)
+
+	// Group without limit
+	unlimited := &ent.User{ID: 1, GroupUsers: 1}
+	for i := 0; i < 5; i++ {
+		a.NoError(limiter.Allow(ctx, unlimited))
+	}
+
+	// User exceeding the cap, group loaded from inventory
+	limited := &ent.User{ID: 2, GroupUsers: 2}
+	a.NoError(limiter.Allow(ctx, limited))
+	a.NoError(limiter.Allow(ctx, limited))
+	a.ErrorIs(limiter.Allow(ctx, limited), ErrRateLimited)
+
+	// Quota is per user, and group on user edge is preferred
+	another := &ent.User{ID: 3, GroupUsers: 1, Edges: ent.UserEdges{Group: groups.groups[2]}}
+	a.NoError(limiter.Allow(ctx, another))
+
+	// System emails are not limited
+	var nilLimiter *RateLimiter
+	a.NoError(nilLimiter.Allow(ctx, limited))
+	a.NoError(limiter.Allow(ctx, nil))
+}
+
+func TestAPIClient_RateLimited(t *testing.T) {
+	a := assert.New(t)
+	ctx := context.Background()
+	c := &fakeClient{status: http.StatusOK, body: `{"message":"Queued"}`}
+	client := newTestAPIClient(setting.MailDriverMailgun, c)
+	client.limiter = NewRateLimiter(cache.NewMemoStore("", logging.NewConsoleLogger(logging.LevelError)), nil)
+	u := &ent.User{ID: 1, Edges: ent.UserEdges{Group: &ent.Group{Settings: &types.GroupSetting{EmailRateLimit: 1}}}}
+
+	a.NoError(client.Send(ctx, "user@example.com", "title", "body", WithRateLimit(u)))
+	c.target = ""
+	a.ErrorIs(client.Send(ctx, "user@example.com", "title", "body", WithRateLimit(u)), ErrRateLimited)
+	a.Empty(c.target)
+
+	// Admin or system triggered email is exempted
+	a.NoError(client.Send(ctx, "user@example.com", "title", "body"))
+}
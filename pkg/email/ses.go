@@ -0,0 +1,270 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ses"
+	"github.com/aws/aws-sdk-go/service/ses/sesiface"
+	"github.com/cloudreve/Cloudreve/v4/inventory"
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
+	"github.com/wneessen/go-mail"
+)
+
+// ErrSESIdentityNotVerified SES 拒绝未验证的发件人或收件人地址
+var ErrSESIdentityNotVerified = errors.New("email address is not verified in Amazon SES, " +
+	"please verify the sender identity, or the recipient address as well if your account is still in SES sandbox")
+
+// SESClient sends emails through Amazon SES SendRawEmail API, DKIM and SPF are handled by SES.
+// Emails are queued and sent asynchronously, same as SMTPPool.
+type SESClient struct {
+	*mailQueue
+	sender            *setting.SMTP
+	svc               sesiface.SESAPI
+	initErr           error
+	maxAttachmentSize int64
+	skipDomains       []string
+	defaults          []SendOption
+	limiter           *RateLimiter
+	l                 logging.Logger
+}
+
+// NewSESClient initializes a new Amazon SES based email sending queue.
+func NewSESClient(config setting.Provider, limiter *RateLimiter, logger logging.Logger) *SESClient {
+	ctx := context.Background()
+	sender := config.SMTP(ctx)
+	client := &SESClient{
+		mailQueue:         newMailQueue(setting.MailDriverSES, sender.QueueSize, sender.MaxRetry, sender.RetryInterval),
+		sender:            sender,
+		maxAttachmentSize: config.MailMaxAttachmentSize(ctx),
+		skipDomains:       config.MailSkipDomains(ctx),
+		defaults:          defaultSendOptions(config),
+		limiter:           limiter,
+		l:                 logger,
+	}
+
+	svc, err := newSESService(config.SES(ctx))
+	if err != nil {
+		client.initErr = err
+		client.l.Error("Failed to initialize Amazon SES email queue: %s", err)
+		return client
+	}
+
+	client.svc = svc
+	go client.run()
+	return client
+}
+
+// SendSESTestEmail sends an email synchronously with given SES settings, used to verify settings before saving.
+func SendSESTestEmail(ctx context.Context, config *setting.SES, sender *setting.SMTP, to, title, body string) error {
+	svc, err := newSESService(config)
+	if err != nil {
+		return err
+	}
+
+	m, err := newMsg(sender, to, title, body)
+	if err != nil {
+		return err
+	}
+
+	return sendRawEmail(ctx, svc, m)
+}
+
+// Send 发送邮件
+func (c *SESClient) Send(ctx context.Context, to, title, body string, opts ...SendOption) error {
+	return c.SendWithAttachments(ctx, to, title, body, nil, opts...)
+}
+
+// SendWithAttachments 发送带附件的邮件，附件在入队前读入内存
+func (c *SESClient) SendWithAttachments(ctx context.Context, to, title, body string, attachments []Attachment, opts ...SendOption) error {
+	if c.initErr != nil {
+		return fmt.Errorf("SES client failed to initialize: %w", c.initErr)
+	}
+
+	// 忽略社交登录创建的占位邮箱
+	if domain, ok := skippedDomain(to, c.skipDomains); ok {
+		c.l.Debug("Skip sending email to %q, domain %q is in skip list.", to, domain)
+		return nil
+	}
+
+	m, err := newMsg(c.sender, to, title, body)
+	if err != nil {
+		return err
+	}
+
+	o, err := newSendOptions(c.defaults, opts)
+	if err != nil {
+		return err
+	}
+
+	if err := c.limiter.Allow(ctx, o.rateLimit); err != nil {
+		return err
+	}
+	setPlainAlternative(m, body, o.plainBody)
+
+	buffered, err := bufferAttachments(attachments, c.maxAttachmentSize)
+	if err != nil {
+		return err
+	}
+
+	return c.enqueue(&message{
+		msg:         m,
+		subject:     title,
+		to:          to,
+		cid:         logging.CorrelationID(ctx).String(),
+		userID:      inventory.UserIDFromContext(ctx),
+		attachments: buffered,
+		cc:          o.cc,
+		bcc:         o.bcc,
+		headers:     o.headers,
+	})
+}
+
+// Close 关闭发送队列
+func (c *SESClient) Close() {
+	c.close()
+}
+
+// CloseWithContext closes the queue after queued emails are sent, or ctx is done.
+func (c *SESClient) CloseWithContext(ctx context.Context) error {
+	if c.initErr != nil {
+		c.close()
+		return nil
+	}
+
+	return c.closeWithContext(ctx)
+}
+
+// Status 返回发送队列状态
+func (c *SESClient) Status() (*QueueStatus, error) {
+	return c.status(), c.initErr
+}
+
+// run sends queued emails until the queue is closed and drained. Emails left in queue for
+// longer than queueDrainTimeout after the queue is closed are dropped.
+func (c *SESClient) run() {
+	c.l.Info("Starting Amazon SES email queue...")
+	for m := range c.ch {
+		l := c.l.CopyWithPrefix(fmt.Sprintf("[Cid: %s]", m.cid))
+		if c.drainExpired() {
+			c.fail(m, l, errQueueClosed)
+			continue
+		}
+
+		c.send(m, l)
+	}
+
+	c.l.Info("Email queue closing...")
+	c.finish()
+}
+
+// send sends a single queued email. Exception while sending is recovered so that the worker
+// keeps serving the queue, the email is retried later.
+func (c *SESClient) send(m *message, l logging.Logger) {
+	defer func() {
+		if err := recover(); err != nil {
+			l.Error("Exception while sending email: %s", err)
+			c.retryLater(m, l, fmt.Errorf("exception while sending: %v", err))
+		}
+	}()
+
+	if err := prepareMsg(m); err != nil {
+		l.Warning("Failed to prepare email: %s, Cid=%s", err, m.cid)
+		return
+	}
+
+	if err := sendRawEmail(context.Background(), c.svc, m.msg); err != nil {
+		if isSESPermanentError(err) {
+			c.fail(m, l, err)
+			return
+		}
+
+		c.retryLater(m, l, err)
+		return
+	}
+
+	c.sent(m, l)
+}
+
+func newSESService(config *setting.SES) (sesiface.SESAPI, error) {
+	if config.Region == "" {
+		return nil, errors.New("SES region is not set")
+	}
+
+	awsConfig := &aws.Config{Region: aws.String(config.Region)}
+	if config.AccessKey != "" {
+		awsConfig.Credentials = credentials.NewStaticCredentials(config.AccessKey, config.SecretKey, "")
+	}
+
+	sess, err := session.NewSession(awsConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS session: %w", err)
+	}
+
+	return ses.New(sess), nil
+}
+
+// sendRawEmail renders the message in MIME format and sends it with SendRawEmail API.
+func sendRawEmail(ctx context.Context, svc sesiface.SESAPI, m *mail.Msg) error {
+	buf := &bytes.Buffer{}
+	if _, err := m.WriteTo(buf); err != nil {
+		return fmt.Errorf("failed to render email: %w", err)
+	}
+
+	// BCC recipients are not rendered in header, so destinations must be set explicitly.
+	rcpts, err := m.GetRecipients()
+	if err != nil {
+		return fmt.Errorf("failed to get recipients: %w", err)
+	}
+
+	_, err = svc.SendRawEmailWithContext(ctx, &ses.SendRawEmailInput{
+		Destinations: aws.StringSlice(rcpts),
+		RawMessage:   &ses.RawMessage{Data: buf.Bytes()},
+	})
+	return sesError(err)
+}
+
+// sesError translates identity verification errors of SES into ErrSESIdentityNotVerified.
+func sesError(err error) error {
+	var aerr awserr.Error
+	if !errors.As(err, &aerr) {
+		return err
+	}
+
+	switch aerr.Code() {
+	case ses.ErrCodeFromEmailAddressNotVerifiedException, ses.ErrCodeMailFromDomainNotVerifiedException:
+		return fmt.Errorf("%w: %w", ErrSESIdentityNotVerified, err)
+	case ses.ErrCodeMessageRejected:
+		if strings.Contains(strings.ToLower(aerr.Message()), "not verified") {
+			return fmt.Errorf("%w: %w", ErrSESIdentityNotVerified, err)
+		}
+	}
+
+	return err
+}
+
+// isSESPermanentError reports whether the error won't be resolved by retrying.
+func isSESPermanentError(err error) bool {
+	if errors.Is(err, ErrSESIdentityNotVerified) {
+		return true
+	}
+
+	var aerr awserr.Error
+	if errors.As(err, &aerr) {
+		switch aerr.Code() {
+		case ses.ErrCodeMessageRejected, ses.ErrCodeAccountSendingPausedException,
+			ses.ErrCodeConfigurationSetDoesNotExistException:
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,130 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ses"
+	"github.com/aws/aws-sdk-go/service/ses/sesiface"
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
+	"github.com/stretchr/testify/assert"
+	"github.com/wneessen/go-mail"
+)
+
+type fakeSES struct {
+	sesiface.SESAPI
+	err    error
+	panics int
+	sent   chan []byte
+}
+
+func (f *fakeSES) SendRawEmailWithContext(ctx context.Context, input *ses.SendRawEmailInput, opts ...request.Option) (*ses.SendRawEmailOutput, error) {
+	if f.panics > 0 {
+		f.panics--
+		panic("unexpected response")
+	}
+
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	f.sent <- input.RawMessage.Data
+	return &ses.SendRawEmailOutput{}, nil
+}
+
+func TestSESClient_Send(t *testing.T) {
+	a := assert.New(t)
+	svc := &fakeSES{sent: make(chan []byte, 1)}
+	client := newTestSESClient(svc, 0)
+	go client.run()
+	defer client.Close()
+
+	a.NoError(client.Send(context.Background(), "user@example.com", "Activate your account", "<p>Hello</p>"))
+	select {
+	case raw := <-svc.sent:
+		a.Contains(string(raw), "Subject: Activate your account")
+		a.Contains(string(raw), "To: <user@example.com>")
+		a.Contains(string(raw), "<p>Hello</p>")
+	case <-time.After(time.Second):
+		a.Fail("email is not sent through SES")
+	}
+}
+
+func newTestSESClient(svc sesiface.SESAPI, maxRetry int) *SESClient {
+	return &SESClient{
+		mailQueue: newMailQueue(setting.MailDriverSES, 0, maxRetry, 0),
+		sender:    &setting.SMTP{FromName: "Cloudreve", From: "no-reply@example.com"},
+		svc:       svc,
+		l:         logging.NewConsoleLogger(logging.LevelError),
+	}
+}
+
+func TestSESClient_RecoverPanic(t *testing.T) {
+	a := assert.New(t)
+	svc := &fakeSES{sent: make(chan []byte, 1), panics: 1}
+	client := newTestSESClient(svc, 1)
+	go client.run()
+	defer client.Close()
+
+	// Email is retried after exception, worker keeps running
+	a.NoError(client.Send(context.Background(), "user@example.com", "title", "body"))
+	select {
+	case <-svc.sent:
+	case <-time.After(3 * time.Second):
+		a.Fail("email is not retried after exception")
+	}
+	a.EqualValues(0, client.FailedCount())
+}
+
+func TestSESClient_DrainExpired(t *testing.T) {
+	a := assert.New(t)
+	svc := &fakeSES{sent: make(chan []byte, 2)}
+	client := newTestSESClient(svc, 0)
+	a.NoError(client.Send(context.Background(), "a@example.com", "title", "body"))
+	a.NoError(client.Send(context.Background(), "b@example.com", "title", "body"))
+
+	// Emails left after drain timeout are dropped instead of sent
+	client.close()
+	client.closedAt = time.Now().Add(-queueDrainTimeout - time.Second)
+	go client.run()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	a.NoError(client.CloseWithContext(ctx))
+	a.Empty(svc.sent)
+	a.EqualValues(2, client.FailedCount())
+}
+
+func TestSESError(t *testing.T) {
+	a := assert.New(t)
+
+	sandbox := awserr.New(ses.ErrCodeMessageRejected,
+		"Email address is not verified. The following identities failed the check in region US-EAST-1: user@example.com", nil)
+	err := sendRawEmail(context.Background(), &fakeSES{err: sandbox}, newTestMsg(a))
+	a.ErrorIs(err, ErrSESIdentityNotVerified)
+	a.Contains(err.Error(), "user@example.com")
+	a.True(isSESPermanentError(err))
+
+	a.ErrorIs(sesError(awserr.New(ses.ErrCodeMailFromDomainNotVerifiedException, "", nil)), ErrSESIdentityNotVerified)
+	a.True(isSESPermanentError(sesError(awserr.New(ses.ErrCodeAccountSendingPausedException, "", nil))))
+
+	// Throttling and network errors are retried
+	a.False(isSESPermanentError(sesError(awserr.New("Throttling", "Maximum sending rate exceeded.", nil))))
+	a.False(isSESPermanentError(sesError(errors.New("connection reset"))))
+}
+
+func TestNewSESClient_MissingRegion(t *testing.T) {
+	a := assert.New(t)
+	_, err := newSESService(&setting.SES{})
+	a.Error(err)
+}
+
+func newTestMsg(a *assert.Assertions) *mail.Msg {
+	m, err := newMsg(&setting.SMTP{From: "no-reply@example.com"}, "user@example.com", "title", "body")
+	a.NoError(err)
+	return m
+}
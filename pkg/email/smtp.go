@@ -1,10 +1,12 @@
 package email
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
-	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/cloudreve/Cloudreve/v4/inventory"
@@ -18,10 +20,20 @@ type SMTPPool struct {
 	// Deprecated
 	Config SMTPConfig
 
-	config *setting.SMTP
-	ch     chan *message
-	chOpen bool
-	l      logging.Logger
+	*mailQueue
+	config  *setting.SMTP
+	workers int
+	// activeWorkers is the number of workers ready to send emails.
+	activeWorkers atomic.Int32
+	l             logging.Logger
+
+	limiter           *RateLimiter
+	dkimConfig        *setting.DKIM
+	dkim              *dkimSigner
+	initErr           error
+	maxAttachmentSize int64
+	skipDomains       []string
+	defaults          []SendOption
 }
 
 // SMTPConfig SMTP发送配置
@@ -37,21 +49,19 @@ type SMTPConfig struct {
 	Keepalive  int    // SMTPPool 连接保留时长
 }
 
-type message struct {
-	msg     *mail.Msg
-	to      string
-	subject string
-	cid     string
-	userID  int
-}
-
 // NewSMTPPool initializes a new SMTP based email sending queue.
-func NewSMTPPool(config setting.Provider, logger logging.Logger) *SMTPPool {
+func NewSMTPPool(config setting.Provider, limiter *RateLimiter, logger logging.Logger) *SMTPPool {
+	smtpConfig := config.SMTP(context.Background())
 	client := &SMTPPool{
-		config: config.SMTP(context.Background()),
-		ch:     make(chan *message, 30),
-		chOpen: false,
-		l:      logger,
+		mailQueue:         newMailQueue(setting.MailDriverSMTP, smtpConfig.QueueSize, smtpConfig.MaxRetry, smtpConfig.RetryInterval),
+		config:            smtpConfig,
+		workers:           smtpConfig.Workers,
+		limiter:           limiter,
+		dkimConfig:        config.DKIM(context.Background()),
+		maxAttachmentSize: config.MailMaxAttachmentSize(context.Background()),
+		skipDomains:       config.MailSkipDomains(context.Background()),
+		defaults:          defaultSendOptions(config),
+		l:                 logger,
 	}
 
 	client.Init()
@@ -62,9 +72,8 @@ func NewSMTPPool(config setting.Provider, logger logging.Logger) *SMTPPool {
 // Deprecated
 func NewSMTPClient(config SMTPConfig) *SMTPPool {
 	client := &SMTPPool{
-		Config: config,
-		ch:     make(chan *message, 30),
-		chOpen: false,
+		Config:    config,
+		mailQueue: newMailQueue(setting.MailDriverSMTP, 0, 0, 0),
 	}
 
 	client.Init()
@@ -73,116 +82,243 @@ func NewSMTPClient(config SMTPConfig) *SMTPPool {
 }
 
 // Send 发送邮件
-func (client *SMTPPool) Send(ctx context.Context, to, title, body string) error {
-	if !client.chOpen {
+func (client *SMTPPool) Send(ctx context.Context, to, title, body string, opts ...SendOption) error {
+	return client.SendWithAttachments(ctx, to, title, body, nil, opts...)
+}
+
+// SendWithAttachments 发送带附件的邮件，附件在入队前读入内存
+func (client *SMTPPool) SendWithAttachments(ctx context.Context, to, title, body string, attachments []Attachment, opts ...SendOption) error {
+	if client.initErr != nil {
+		return fmt.Errorf("SMTP pool failed to initialize: %w", client.initErr)
+	}
+
+	if client.activeWorkers.Load() == 0 {
 		return fmt.Errorf("SMTP pool is closed")
 	}
 
-	// 忽略通过QQ登录的邮箱
-	if strings.HasSuffix(to, "@login.qq.com") {
+	// 忽略社交登录创建的占位邮箱
+	if domain, ok := skippedDomain(to, client.skipDomains); ok {
+		client.l.Debug("Skip sending email to %q, domain %q is in skip list.", to, domain)
 		return nil
 	}
 
-	m := mail.NewMsg()
-	if err := m.FromFormat(client.config.FromName, client.config.From); err != nil {
+	var msgOpts []mail.MsgOption
+	if client.dkim != nil {
+		msgOpts = append(msgOpts, mail.WithMiddleware(client.dkim))
+	}
+
+	m, err := newMsg(client.config, to, title, body, msgOpts...)
+	if err != nil {
 		return err
 	}
-	m.ReplyToFormat(client.config.FromName, client.config.ReplyTo)
-	m.To(to)
-	m.Subject(title)
-	m.SetMessageID()
-	m.SetBodyString(mail.TypeTextHTML, body)
-	client.ch <- &message{
-		msg:     m,
-		subject: title,
-		to:      to,
-		cid:     logging.CorrelationID(ctx).String(),
-		userID:  inventory.UserIDFromContext(ctx),
+
+	o, err := newSendOptions(client.defaults, opts)
+	if err != nil {
+		return err
 	}
-	return nil
+
+	if err := client.limiter.Allow(ctx, o.rateLimit); err != nil {
+		return err
+	}
+	setPlainAlternative(m, body, o.plainBody)
+
+	buffered, err := bufferAttachments(attachments, client.maxAttachmentSize)
+	if err != nil {
+		return err
+	}
+
+	return client.enqueue(&message{
+		msg:         m,
+		subject:     title,
+		to:          to,
+		cid:         logging.CorrelationID(ctx).String(),
+		userID:      inventory.UserIDFromContext(ctx),
+		attachments: buffered,
+		cc:          o.cc,
+		bcc:         o.bcc,
+		headers:     o.headers,
+	})
 }
 
-// Close 关闭发送队列
+// Close 关闭发送队列. Emails already in queue are still sent in background for up to
+// queueDrainTimeout, use CloseWithContext to wait for them.
 func (client *SMTPPool) Close() {
-	if client.ch != nil {
-		close(client.ch)
+	client.close()
+}
+
+// CloseWithContext closes the pool after queued emails are sent, or ctx is done.
+func (client *SMTPPool) CloseWithContext(ctx context.Context) error {
+	if client.initErr != nil {
+		// Worker never started, nothing can be sent
+		client.close()
+		return nil
 	}
+
+	return client.closeWithContext(ctx)
+}
+
+// Status 返回发送队列状态
+func (client *SMTPPool) Status() (*QueueStatus, error) {
+	return client.status(), client.initErr
 }
 
 // Init 初始化发送队列
 func (client *SMTPPool) Init() {
-	go func() {
-		client.l.Info("Initializing and starting SMTP email pool...")
-		defer func() {
-			if err := recover(); err != nil {
-				client.chOpen = false
-				client.l.Error("Exception while sending email: %s, queue will be reset in 10 seconds.", err)
-				time.Sleep(time.Duration(10) * time.Second)
-				client.Init()
-			}
+	signer, err := newDKIMSigner(client.dkimConfig, client.l)
+	if err != nil {
+		client.initErr = fmt.Errorf("invalid DKIM settings: %w", err)
+		client.l.Error("Failed to initialize SMTP email pool: %s", client.initErr)
+		return
+	}
+	client.dkim = signer
+
+	workers := max(client.workers, 1)
+	client.l.Info("Initializing and starting SMTP email pool with %d worker(s)...", workers)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			client.work()
 		}()
+	}
 
-		opts := []mail.Option{
-			mail.WithPort(client.config.Port),
-			mail.WithTimeout(time.Duration(client.config.Keepalive+5) * time.Second),
-			mail.WithSMTPAuth(mail.SMTPAuthAutoDiscover), mail.WithTLSPortPolicy(mail.TLSOpportunistic),
-			mail.WithUsername(client.config.User), mail.WithPassword(client.config.Password),
-		}
-		if client.config.ForceEncryption {
-			opts = append(opts, mail.WithSSL())
+	go func() {
+		wg.Wait()
+		// Workers failed while draining may leave emails behind.
+		client.dropPending(client.l)
+		client.finish()
+	}()
+}
+
+// work runs a worker until the queue is closed and drained. The worker is reset after an
+// exception, unless the pool is closed intentionally.
+func (client *SMTPPool) work() {
+	for !client.serve() {
+		if !client.isClosed() {
+			time.Sleep(time.Duration(10) * time.Second)
 		}
 
-		d, diaErr := mail.NewClient(client.config.Host, opts...)
-		if diaErr != nil {
-			client.l.Panic("Failed to create SMTP client: %s", diaErr)
+		if client.isClosed() {
 			return
 		}
+	}
+}
+
+// serve sends queued emails through its own SMTP connection, which is closed after being
+// idle for keepalive seconds. It returns true once the queue is closed and drained, or false
+// if interrupted by an exception.
+func (client *SMTPPool) serve() (drained bool) {
+	defer func() {
+		if err := recover(); err != nil {
+			if client.isClosed() {
+				client.l.Error("Exception while draining email queue: %s, worker stopped.", err)
+			} else {
+				client.l.Error("Exception while sending email: %s, worker will be reset in 10 seconds.", err)
+			}
+			drained = false
+		}
+	}()
 
-		client.chOpen = true
-
-		var err error
-		open := false
-		for {
-			select {
-			case m, ok := <-client.ch:
-				if !ok {
-					client.l.Info("Email queue closing...")
-					client.chOpen = false
-					return
-				}
+	opts := []mail.Option{
+		mail.WithPort(client.config.Port),
+		mail.WithTimeout(time.Duration(client.config.Keepalive+5) * time.Second),
+		mail.WithSMTPAuth(mail.SMTPAuthAutoDiscover), mail.WithTLSPortPolicy(mail.TLSOpportunistic),
+		mail.WithUsername(client.config.User), mail.WithPassword(client.config.Password),
+	}
+	if client.config.ForceEncryption {
+		opts = append(opts, mail.WithSSL())
+	}
 
-				if !open {
-					if err = d.DialWithContext(context.Background()); err != nil {
-						panic(err)
-					}
-					open = true
-				}
+	d, diaErr := mail.NewClient(client.config.Host, opts...)
+	if diaErr != nil {
+		client.l.Panic("Failed to create SMTP client: %s", diaErr)
+		return false
+	}
 
-				l := client.l.CopyWithPrefix(fmt.Sprintf("[Cid: %s]", m.cid))
-				if err := d.Send(m.msg); err != nil {
-					// Check if this is an SMTP RESET error after successful delivery
-					var sendErr *mail.SendError
-					var errParsed = errors.As(err, &sendErr)
-					if errParsed && sendErr.Reason == mail.ErrSMTPReset {
-						open = false
-						l.Debug("SMTP RESET error, closing connection...")
-						// https://github.com/wneessen/go-mail/issues/463
-						continue // Don't treat this as a delivery failure since mail was sent
-					}
+	client.activeWorkers.Add(1)
+	defer client.activeWorkers.Add(-1)
 
-					l.Warning("Failed to send email: %s, Cid=%s", err, m.cid)
-				} else {
-					l.Info("Email sent to %q, title: %q.", m.to, m.subject)
-				}
-			// 长时间没有新邮件，则关闭SMTP连接
-			case <-time.After(time.Duration(client.config.Keepalive) * time.Second):
+	var err error
+	open := false
+	for {
+		select {
+		case m, ok := <-client.ch:
+			if !ok {
+				client.l.Info("Email queue closing...")
 				if open {
 					if err := d.Close(); err != nil {
 						client.l.Warning("Failed to close SMTP connection: %s", err)
 					}
+				}
+				return true
+			}
+
+			l := client.l.CopyWithPrefix(fmt.Sprintf("[Cid: %s]", m.cid))
+			if client.drainExpired() {
+				client.fail(m, l, errQueueClosed)
+				continue
+			}
+
+			if !open {
+				if err = d.DialWithContext(context.Background()); err != nil {
+					client.retryLater(m, l, err)
+					panic(err)
+				}
+				open = true
+			}
+
+			if err := prepareMsg(m); err != nil {
+				l.Warning("Failed to prepare email: %s, Cid=%s", err, m.cid)
+				continue
+			}
+
+			if err := d.Send(m.msg); err != nil {
+				// Check if this is an SMTP RESET error after successful delivery
+				var sendErr *mail.SendError
+				var errParsed = errors.As(err, &sendErr)
+				if errParsed && sendErr.Reason == mail.ErrSMTPReset {
 					open = false
+					l.Debug("SMTP RESET error, closing connection...")
+					// https://github.com/wneessen/go-mail/issues/463
+					continue // Don't treat this as a delivery failure since mail was sent
+				}
+
+				// Permanent rejection from server won't succeed on retry
+				if errParsed && sendErr.ErrorCode() >= 500 {
+					client.fail(m, l, fmt.Errorf("rejected by server: %w", err))
+					continue
 				}
+
+				client.retryLater(m, l, err)
+			} else {
+				client.sent(m, l)
+			}
+		// 长时间没有新邮件，则关闭SMTP连接
+		case <-time.After(time.Duration(client.config.Keepalive) * time.Second):
+			if open {
+				if err := d.Close(); err != nil {
+					client.l.Warning("Failed to close SMTP connection: %s", err)
+				}
+				open = false
 			}
 		}
-	}()
+	}
+}
+
+// attachToMsg attaches buffered attachments of the queued message.
+func attachToMsg(m *message) error {
+	for _, a := range m.attachments {
+		var opts []mail.FileOption
+		if a.ContentType != "" {
+			opts = append(opts, mail.WithFileContentType(mail.ContentType(a.ContentType)))
+		}
+
+		if err := m.msg.AttachReader(a.Filename, bytes.NewReader(a.data), opts...); err != nil {
+			return fmt.Errorf("failed to attach %q: %w", a.Filename, err)
+		}
+	}
+
+	m.attachments = nil
+	return nil
 }
@@ -0,0 +1,42 @@
+package email
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSMTPPool_NotResurrectedAfterClose(t *testing.T) {
+	a := assert.New(t)
+
+	// Find a port nobody listens on
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	a.NoError(err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	client := &SMTPPool{
+		mailQueue: newMailQueue(setting.MailDriverSMTP, 0, 0, 0),
+		config:    &setting.SMTP{Host: "127.0.0.1", Port: port, Keepalive: 30},
+		workers:   3,
+		l:         logging.NewConsoleLogger(logging.LevelError),
+	}
+	a.NoError(client.enqueue(&message{to: "a@example.com"}))
+	a.NoError(client.enqueue(&message{to: "b@example.com"}))
+	client.close()
+
+	// Workers fail to connect while draining, remaining emails are dropped instead of
+	// waiting for the pool to be reset.
+	client.Init()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	a.NoError(client.CloseWithContext(ctx))
+	a.EqualValues(2, client.FailedCount())
+	a.Zero(client.activeWorkers.Load())
+	a.Error(client.Send(context.Background(), "c@example.com", "title", "body"))
+}
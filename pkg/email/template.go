@@ -6,6 +6,7 @@ import (
 	"html/template"
 	"net/url"
 	"strings"
+	texttemplate "text/template"
 
 	"github.com/cloudreve/Cloudreve/v4/ent"
 	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
@@ -24,43 +25,22 @@ type ResetContext struct {
 	Url  string
 }
 
-// NewResetEmail generates reset email from template
-func NewResetEmail(ctx context.Context, settings setting.Provider, user *ent.User, url string) (string, string, error) {
+// NewResetEmail generates reset email from template, returns title, HTML body and plaintext body.
+func NewResetEmail(ctx context.Context, settings setting.Provider, user *ent.User, url string) (string, string, string, error) {
 	templates := settings.ResetEmailTemplate(ctx)
 	if len(templates) == 0 {
-		return "", "", fmt.Errorf("reset email template not configured")
+		return "", "", "", fmt.Errorf("reset email template not configured")
 	}
 
-	selected := selectTemplate(templates, user)
+	common := commonContext(ctx, settings)
+	selected := selectTemplate(templates, user, common.SiteBasic.DefaultLanguage)
 	resetCtx := ResetContext{
-		CommonContext: commonContext(ctx, settings),
+		CommonContext: common,
 		User:          user,
 		Url:           url,
 	}
 
-	tmplTitle, err := template.New("resetTitle").Parse(selected.Title)
-	if err != nil {
-		return "", "", fmt.Errorf("failed to parse email title: %w", err)
-	}
-
-	var resTitle strings.Builder
-	err = tmplTitle.Execute(&resTitle, resetCtx)
-	if err != nil {
-		return "", "", fmt.Errorf("failed to execute email title: %w", err)
-	}
-
-	tmplBody, err := template.New("resetBody").Parse(selected.Body)
-	if err != nil {
-		return "", "", fmt.Errorf("failed to parse email template: %w", err)
-	}
-
-	var resBody strings.Builder
-	err = tmplBody.Execute(&resBody, resetCtx)
-	if err != nil {
-		return "", "", fmt.Errorf("failed to execute email template: %w", err)
-	}
-
-	return resTitle.String(), resBody.String(), nil
+	return renderEmail("reset", selected, resetCtx)
 }
 
 // ActivationContext used for variables in activation email
@@ -70,43 +50,66 @@ type ActivationContext struct {
 	Url  string
 }
 
-// NewActivationEmail generates activation email from template
-func NewActivationEmail(ctx context.Context, settings setting.Provider, user *ent.User, url string) (string, string, error) {
+// NewActivationEmail generates activation email from template, returns title, HTML body and plaintext body.
+func NewActivationEmail(ctx context.Context, settings setting.Provider, user *ent.User, url string) (string, string, string, error) {
 	templates := settings.ActivationEmailTemplate(ctx)
 	if len(templates) == 0 {
-		return "", "", fmt.Errorf("activation email template not configured")
+		return "", "", "", fmt.Errorf("activation email template not configured")
 	}
 
-	selected := selectTemplate(templates, user)
+	common := commonContext(ctx, settings)
+	selected := selectTemplate(templates, user, common.SiteBasic.DefaultLanguage)
 	activationCtx := ActivationContext{
-		CommonContext: commonContext(ctx, settings),
+		CommonContext: common,
 		User:          user,
 		Url:           url,
 	}
 
-	tmplTitle, err := template.New("activationTitle").Parse(selected.Title)
+	return renderEmail("activation", selected, activationCtx)
+}
+
+// renderEmail renders title, HTML body and plaintext body of given template. If plaintext
+// template is not set, it is generated from HTML body.
+func renderEmail(name string, selected setting.EmailTemplate, data any) (string, string, string, error) {
+	tmplTitle, err := template.New(name + "Title").Parse(selected.Title)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to parse email title: %w", err)
+		return "", "", "", fmt.Errorf("failed to parse email title: %w", err)
 	}
 
 	var resTitle strings.Builder
-	err = tmplTitle.Execute(&resTitle, activationCtx)
+	err = tmplTitle.Execute(&resTitle, data)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to execute email title: %w", err)
+		return "", "", "", fmt.Errorf("failed to execute email title: %w", err)
 	}
 
-	tmplBody, err := template.New("activationBody").Parse(selected.Body)
+	tmplBody, err := template.New(name + "Body").Parse(selected.Body)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to parse email template: %w", err)
+		return "", "", "", fmt.Errorf("failed to parse email template: %w", err)
 	}
 
 	var resBody strings.Builder
-	err = tmplBody.Execute(&resBody, activationCtx)
+	err = tmplBody.Execute(&resBody, data)
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to execute email template: %w", err)
+	}
+
+	if selected.PlainBody == "" {
+		return resTitle.String(), resBody.String(), htmlToPlain(resBody.String()), nil
+	}
+
+	// Plaintext is not HTML escaped
+	tmplPlain, err := texttemplate.New(name + "PlainBody").Parse(selected.PlainBody)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to execute email template: %w", err)
+		return "", "", "", fmt.Errorf("failed to parse plaintext email template: %w", err)
 	}
 
-	return resTitle.String(), resBody.String(), nil
+	var resPlain strings.Builder
+	err = tmplPlain.Execute(&resPlain, data)
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to execute plaintext email template: %w", err)
+	}
+
+	return resTitle.String(), resBody.String(), resPlain.String(), nil
 }
 
 func commonContext(ctx context.Context, settings setting.Provider) *CommonContext {
@@ -132,16 +135,24 @@ func commonContext(ctx context.Context, settings setting.Provider) *CommonContex
 	return res
 }
 
-func selectTemplate(templates []setting.EmailTemplate, u *ent.User) setting.EmailTemplate {
-	selected := templates[0]
-	if u != nil {
+// selectTemplate picks template in language preferred by user, or in site default language if
+// user has no preference or there's no matching template, and falls back to the first one.
+func selectTemplate(templates []setting.EmailTemplate, u *ent.User, defaultLanguage string) setting.EmailTemplate {
+	var languages []string
+	if u != nil && u.Settings != nil && u.Settings.Language != "" {
+		languages = append(languages, u.Settings.Language)
+	}
+	if defaultLanguage != "" {
+		languages = append(languages, defaultLanguage)
+	}
+
+	for _, language := range languages {
 		for _, t := range templates {
-			if strings.EqualFold(t.Language, u.Settings.Language) {
-				selected = t
-				break
+			if strings.EqualFold(t.Language, language) {
+				return t
 			}
 		}
 	}
 
-	return selected
-}
\ No newline at end of file
+	return templates[0]
+}
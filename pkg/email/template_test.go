@@ -0,0 +1,34 @@
+package email
+
+import (
+	"testing"
+
+	"github.com/cloudreve/Cloudreve/v4/ent"
+	"github.com/cloudreve/Cloudreve/v4/inventory/types"
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectTemplate(t *testing.T) {
+	a := assert.New(t)
+	templates := []setting.EmailTemplate{
+		{Title: "en", Language: "en-US"},
+		{Title: "zh", Language: "zh-CN"},
+		{Title: "ja", Language: "ja-JP"},
+	}
+
+	withLanguage := &ent.User{Settings: &types.UserSetting{Language: "ja-jp"}}
+	withoutLanguage := &ent.User{Settings: &types.UserSetting{}}
+
+	// User preference takes priority over site default
+	a.Equal("ja", selectTemplate(templates, withLanguage, "zh-CN").Title)
+	// Site default is used when user has no preference
+	a.Equal("zh", selectTemplate(templates, withoutLanguage, "zh-CN").Title)
+	a.Equal("zh", selectTemplate(templates, &ent.User{}, "zh-CN").Title)
+	a.Equal("zh", selectTemplate(templates, nil, "zh-CN").Title)
+	// Site default is also used when user preference has no template
+	a.Equal("zh", selectTemplate(templates, &ent.User{Settings: &types.UserSetting{Language: "fr-FR"}}, "zh-CN").Title)
+	// Fall back to first template
+	a.Equal("en", selectTemplate(templates, withoutLanguage, "").Title)
+	a.Equal("en", selectTemplate(templates, nil, "de-DE").Title)
+}
@@ -95,6 +95,7 @@ func init() {
 	cossdk.SetNeedSignHeaders("origin", false)
 	boolset.Sets(map[driver.HandlerCapability]bool{
 		driver.HandlerCapabilityUploadSentinelRequired: true,
+		driver.HandlerCapabilityRangeSupported:         true,
 	}, features)
 }
 
@@ -354,7 +355,7 @@ func (handler Driver) Thumb(ctx context.Context, expire *time.Time, ext string,
 
 	enco := handler.settings.ThumbEncode(ctx)
 	switch enco.Format {
-	case "jpg", "webp":
+	case "jpg", "webp", "avif":
 		thumbParam += fmt.Sprintf("/format/%s/rquality/%d", enco.Format, enco.Quality)
 	case "png":
 		thumbParam += fmt.Sprintf("/format/%s", enco.Format)
@@ -544,6 +545,16 @@ func (handler *Driver) CancelToken(ctx context.Context, uploadSession *fs.Upload
 	return err
 }
 
+// HealthCheck 检查存储是否可用
+func (handler *Driver) HealthCheck(ctx context.Context) error {
+	return errors.New("not implemented")
+}
+
+// ListUploadedParts 列出已上传的分片
+func (handler *Driver) ListUploadedParts(ctx context.Context, session *fs.UploadSession) ([]driver.UploadedPart, error) {
+	return nil, errors.New("not implemented")
+}
+
 func (handler *Driver) CompleteUpload(ctx context.Context, session *fs.UploadSession) error {
 	if session.SentinelTaskID == 0 {
 		return nil
@@ -562,7 +573,8 @@ func (handler *Driver) CompleteUpload(ctx context.Context, session *fs.UploadSes
 			nil,
 		)
 	}
-	return nil
+
+	return driver.VerifyUploadedETag(handler.policy, session, res.Header.Get("ETag"))
 }
 
 func (handler *Driver) Capabilities() *driver.Capabilities {
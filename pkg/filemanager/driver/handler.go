@@ -3,6 +3,7 @@ package driver
 import (
 	"context"
 	"encoding/gob"
+	"errors"
 	"os"
 	"time"
 
@@ -21,6 +22,9 @@ const (
 	// to delete the placeholder file and cancel the upload session if upload callback is not made after upload
 	// session expire.
 	HandlerCapabilityUploadSentinelRequired
+	// HandlerCapabilityRangeSupported source URLs of this handler accept Range requests, Cloudreve's
+	// proxy forwards Range header of clients to storage instead of serving the whole file.
+	HandlerCapabilityRangeSupported
 )
 
 type (
@@ -33,6 +37,13 @@ type (
 
 	HandlerCapability int
 
+	// UploadedPart is a part already uploaded in a multipart upload session.
+	UploadedPart struct {
+		// Number of the part, starting from 1.
+		Number int   `json:"number"`
+		Size   int64 `json:"size"`
+	}
+
 	GetSourceArgs struct {
 		Expire      *time.Time
 		IsDownload  bool
@@ -74,6 +85,14 @@ type (
 		// CompleteUpload completes a previously created upload session.
 		CompleteUpload(ctx context.Context, session *fs.UploadSession) error
 
+		// ListUploadedParts lists parts already uploaded to storage in given upload session,
+		// so that clients can resume the upload by only uploading missing parts.
+		ListUploadedParts(ctx context.Context, session *fs.UploadSession) ([]UploadedPart, error)
+
+		// HealthCheck checks whether the storage is reachable and writable. Returned error wraps
+		// one of ErrHealthCheck* errors if the cause is known.
+		HealthCheck(ctx context.Context) error
+
 		// List 递归列取远程端path路径下文件、目录，不包含path本身，
 		// 返回的对象路径以path作为起始根目录.
 		// recursive - 是否递归列出
@@ -118,6 +137,23 @@ const (
 	MediaTypeMusic      MetaType = "music"
 	MetaTypeStreamMedia MetaType = "stream"
 	MetaTypeGeocoding   MetaType = "geocoding"
+	MetaTypePair        MetaType = "pair"
+	MetaTypeXMP         MetaType = "xmp"
+	MetaTypeIPTC        MetaType = "iptc"
+	MetaTypeDocument    MetaType = "document"
+	// MetaTypeTag is used for media meta mapped to tags of the file.
+	MetaTypeTag MetaType = "tag"
+	// MetaTypeCustomProps is used for media meta mapped to custom props of the file.
+	MetaTypeCustomProps MetaType = "props"
+)
+
+var (
+	// ErrHealthCheckAuth indicates that storage rejected the credentials of the policy.
+	ErrHealthCheckAuth = errors.New("storage rejected the credentials")
+	// ErrHealthCheckBucketNotFound indicates that the bucket of the policy does not exist.
+	ErrHealthCheckBucketNotFound = errors.New("bucket does not exist")
+	// ErrHealthCheckUnreachable indicates that storage cannot be reached, e.g. wrong endpoint or network issues.
+	ErrHealthCheckUnreachable = errors.New("storage is not reachable")
 )
 
 type ForceUsePublicEndpointCtx struct{}
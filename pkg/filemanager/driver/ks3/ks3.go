@@ -1,6 +1,7 @@
 package ks3
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -8,6 +9,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws/request"
 
 	"io"
+	"net/http"
 	"net/url"
 	"os"
 	"path"
@@ -18,6 +20,7 @@ import (
 	"strconv"
 
 	"github.com/cloudreve/Cloudreve/v4/ent"
+	"github.com/cloudreve/Cloudreve/v4/inventory"
 	"github.com/cloudreve/Cloudreve/v4/inventory/types"
 	"github.com/cloudreve/Cloudreve/v4/pkg/boolset"
 	"github.com/cloudreve/Cloudreve/v4/pkg/cluster/routes"
@@ -28,6 +31,7 @@ import (
 	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/fs"
 	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/fs/mime"
 	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/cloudreve/Cloudreve/v4/pkg/metrics"
 	"github.com/cloudreve/Cloudreve/v4/pkg/serializer"
 	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
 	"github.com/ks3sdklib/aws-sdk-go/aws/awserr"
@@ -51,7 +55,10 @@ type Driver struct {
 	mime     mime.MimeDetector
 
 	sess *aws.Config
-	svc  *s3.S3
+	// svc sends server side requests, using ServerSideEndpoint if set.
+	svc *s3.S3
+	// presignSvc signs URLs handed out to clients, using public endpoint.
+	presignSvc *s3.S3
 }
 
 // UploadPolicy KS3上传策略
@@ -71,6 +78,8 @@ type MetaData struct {
 	Etag string
 }
 
+const healthCheckProbePrefix = ".cloudreve_health_check_"
+
 var (
 	features = &boolset.BooleanSet{}
 )
@@ -78,6 +87,7 @@ var (
 func init() {
 	boolset.Sets(map[driver.HandlerCapability]bool{
 		driver.HandlerCapabilityUploadSentinelRequired: true,
+		driver.HandlerCapabilityRangeSupported:         true,
 	}, features)
 }
 
@@ -101,14 +111,23 @@ func New(ctx context.Context, policy *ent.StoragePolicy, settings setting.Provid
 		mime:      mime,
 	}
 
-	sess := aws.Config{
-		Credentials:      credentials.NewStaticCredentials(policy.AccessKey, policy.SecretKey, ""),
-		Endpoint:         policy.Server,
-		Region:           policy.Settings.Region,
-		S3ForcePathStyle: policy.Settings.S3ForcePathStyle,
+	newSvc := func(endpoint string) (*aws.Config, *s3.S3) {
+		sess := &aws.Config{
+			Credentials:      credentials.NewStaticCredentials(policy.AccessKey, policy.SecretKey, ""),
+			Endpoint:         endpoint,
+			Region:           policy.Settings.Region,
+			S3ForcePathStyle: policy.Settings.S3ForcePathStyle,
+		}
+		svc := s3.New(sess)
+		svc.Handlers.Build.PushBack(taggingHandler)
+		return sess, svc
+	}
+
+	driver.sess, driver.presignSvc = newSvc(policy.Server)
+	driver.svc = driver.presignSvc
+	if policy.Settings.ServerSideEndpoint != "" {
+		driver.sess, driver.svc = newSvc(policy.Settings.ServerSideEndpoint)
 	}
-	driver.sess = &sess
-	driver.svc = s3.New(&sess)
 
 	return driver, nil
 }
@@ -222,12 +241,18 @@ func (handler *Driver) Put(ctx context.Context, file *fs.UploadRequest) error {
 		mimeType = handler.mime.TypeByName(file.Props.Uri.Name())
 	}
 
-	_, err := uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+	tagging, err := objectTagging(handler.policy.Settings.ObjectTags, inventory.UserIDFromContext(ctx), handler.policy.ID)
+	if err != nil {
+		return fmt.Errorf("failed to generate object tags: %w", err)
+	}
+
+	_, err = uploader.UploadWithContext(withTagging(ctx, tagging), &s3manager.UploadInput{
 		Bucket:      &handler.policy.BucketName,
 		Key:         &file.Props.SavePath,
 		Body:        io.LimitReader(file, file.Props.Size),
 		ContentType: aws.String(mimeType),
 	})
+	metrics.ObserveStorage(handler.policy.Type, metrics.StorageOpUpload, file.Props.Size, err)
 
 	if err != nil {
 		return err
@@ -292,6 +317,13 @@ func (handler *Driver) Delete(ctx context.Context, files ...string) ([]string, e
 		}
 	}
 
+	metrics.StorageOperations.WithLabelValues(handler.policy.Type, metrics.StorageOpDelete, metrics.ResultSuccess).
+		Add(float64(len(files) - len(failed)))
+	if len(failed) > 0 {
+		metrics.StorageOperations.WithLabelValues(handler.policy.Type, metrics.StorageOpDelete, metrics.ResultFailure).
+			Add(float64(len(failed)))
+	}
+
 	return failed, lastErr
 
 }
@@ -303,7 +335,7 @@ func (handler *Driver) Thumb(ctx context.Context, expire *time.Time, ext string,
 
 	enco := handler.settings.ThumbEncode(ctx)
 	switch enco.Format {
-	case "jpg", "webp":
+	case "jpg", "webp", "avif":
 		thumbParam += fmt.Sprintf("&q=%d&F=%s", enco.Quality, enco.Format)
 	case "png":
 		thumbParam += fmt.Sprintf("&F=%s", enco.Format)
@@ -317,7 +349,7 @@ func (handler *Driver) Thumb(ctx context.Context, expire *time.Time, ext string,
 		ttl = 604800
 	}
 
-	thumbUrl, err := handler.svc.GeneratePresignedUrl(&s3.GeneratePresignedUrlInput{
+	thumbUrl, err := handler.presignSvc.GeneratePresignedUrl(&s3.GeneratePresignedUrlInput{
 		HTTPMethod: s3.GET,                              // 请求方法
 		Bucket:     &handler.policy.BucketName,          // 存储空间名称
 		Key:        aws.String(e.Source() + thumbParam), // 对象的key
@@ -328,18 +360,7 @@ func (handler *Driver) Thumb(ctx context.Context, expire *time.Time, ext string,
 		return "", err
 	}
 
-	// 将最终生成的签名URL域名换成用户自定义的加速域名（如果有）
-	finalThumbURL, err := url.Parse(thumbUrl)
-	if err != nil {
-		return "", err
-	}
-
-	// 公有空间替换掉Key及不支持的头
-	if !handler.policy.IsPrivate {
-		finalThumbURL.RawQuery = ""
-	}
-
-	return finalThumbURL.String(), nil
+	return handler.downloadURL(thumbUrl)
 }
 
 // Source 获取文件外链
@@ -358,20 +379,26 @@ func (handler *Driver) Source(ctx context.Context, e fs.Entity, args *driver.Get
 		ttl = 604800
 	}
 
-	downloadUrl, err := handler.svc.GeneratePresignedUrl(&s3.GeneratePresignedUrlInput{
+	downloadUrl, err := handler.presignSvc.GeneratePresignedUrl(&s3.GeneratePresignedUrlInput{
 		HTTPMethod:                 s3.GET,                     // 请求方法
 		Bucket:                     &handler.policy.BucketName, // 存储空间名称
 		Key:                        aws.String(e.Source()),     // 对象的key
 		Expires:                    ttl,                        // 过期时间，转换为秒数
 		ResponseContentDisposition: contentDescription,         // 设置响应头部 Content-Disposition
 	})
+	metrics.ObserveStorage(handler.policy.Type, metrics.StorageOpDownload, e.Size(), err)
 
 	if err != nil {
 		return "", err
 	}
 
-	// 将最终生成的签名URL域名换成用户自定义的加速域名（如果有）
-	finalURL, err := url.Parse(downloadUrl)
+	return handler.downloadURL(downloadUrl)
+}
+
+// downloadURL 处理签名后的下载地址：公有空间去掉签名参数，并将域名替换为 CDN 域名（如果有）。
+// 私有空间的签名不包含域名，替换后签名参数保持不变。
+func (handler *Driver) downloadURL(signed string) (string, error) {
+	finalURL, err := url.Parse(signed)
 	if err != nil {
 		return "", err
 	}
@@ -381,6 +408,28 @@ func (handler *Driver) Source(ctx context.Context, e fs.Entity, args *driver.Get
 		finalURL.RawQuery = ""
 	}
 
+	cdnDomain := handler.policy.Settings.CDNDomain
+	if cdnDomain == "" {
+		return finalURL.String(), nil
+	}
+
+	if !strings.Contains(cdnDomain, "://") {
+		cdnDomain = "https://" + cdnDomain
+	}
+	cdn, err := url.Parse(cdnDomain)
+	if err != nil {
+		return "", fmt.Errorf("invalid CDN domain: %w", err)
+	}
+
+	finalURL.Scheme = cdn.Scheme
+	finalURL.Host = cdn.Host
+	if handler.policy.Settings.S3ForcePathStyle {
+		// CDN domain is bound to the bucket, bucket name is not part of the path.
+		bucketPrefix := "/" + handler.policy.BucketName
+		finalURL.Path = strings.TrimPrefix(finalURL.Path, bucketPrefix)
+		finalURL.RawPath = strings.TrimPrefix(finalURL.RawPath, bucketPrefix)
+	}
+
 	return finalURL.String(), nil
 }
 
@@ -394,7 +443,8 @@ func (handler *Driver) Token(ctx context.Context, uploadSession *fs.UploadSessio
 	// 生成回调地址
 	siteURL := handler.settings.SiteURL(setting.UseFirstSiteUrl(ctx))
 	// 在从机端创建上传会话
-	uploadSession.ChunkSize = handler.chunkSize
+	chunkSize := driver.MultipartChunkSize(handler.chunkSize, file.Props.Size)
+	uploadSession.ChunkSize = chunkSize
 	uploadSession.Callback = routes.MasterSlaveCallbackUrl(siteURL, types.PolicyTypeKs3, uploadSession.Props.UploadSessionID, uploadSession.CallbackSecret).String()
 
 	mimeType := file.Props.MimeType
@@ -402,13 +452,23 @@ func (handler *Driver) Token(ctx context.Context, uploadSession *fs.UploadSessio
 		mimeType = handler.mime.TypeByName(file.Props.Uri.Name())
 	}
 
+	tagging, err := objectTagging(handler.policy.Settings.ObjectTags, uploadSession.UID, handler.policy.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate object tags: %w", err)
+	}
+
 	// 创建分片上传
-	res, err := handler.svc.CreateMultipartUploadWithContext(ctx, &s3.CreateMultipartUploadInput{
+	input := &s3.CreateMultipartUploadInput{
 		Bucket:      &handler.policy.BucketName,
 		Key:         &uploadSession.Props.SavePath,
 		Expires:     &uploadSession.Props.ExpireAt,
 		ContentType: aws.String(mimeType),
-	})
+	}
+	if tagging != "" {
+		input.Tagging = aws.String(tagging)
+	}
+
+	res, err := handler.svc.CreateMultipartUploadWithContext(ctx, input)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create multipart upload: %w", err)
 	}
@@ -416,7 +476,7 @@ func (handler *Driver) Token(ctx context.Context, uploadSession *fs.UploadSessio
 	uploadSession.UploadID = *res.UploadID
 
 	// 为每个分片签名上传 URL
-	chunks := chunk.NewChunkGroup(file, handler.chunkSize, &backoff.ConstantBackoff{}, false, handler.l, "")
+	chunks := chunk.NewChunkGroup(file, chunkSize, &backoff.ConstantBackoff{}, false, handler.l, "")
 	urls := make([]string, chunks.Num())
 	for chunks.Next() {
 		err := chunks.Process(func(c *chunk.ChunkGroup, chunk io.Reader) error {
@@ -425,7 +485,7 @@ func (handler *Driver) Token(ctx context.Context, uploadSession *fs.UploadSessio
 			partNumber := c.Index() + 1
 
 			// 生成预签名URL
-			signedURL, err := handler.svc.GeneratePresignedUrl(&s3.GeneratePresignedUrlInput{
+			signedURL, err := handler.presignSvc.GeneratePresignedUrl(&s3.GeneratePresignedUrlInput{
 				HTTPMethod: s3.PUT,
 				Bucket:     &handler.policy.BucketName,
 				Key:        &uploadSession.Props.SavePath,
@@ -449,7 +509,7 @@ func (handler *Driver) Token(ctx context.Context, uploadSession *fs.UploadSessio
 
 	// 签名完成分片上传的请求URL
 	expireSeconds := int(time.Until(uploadSession.Props.ExpireAt).Seconds())
-	signedURL, err := handler.svc.GeneratePresignedUrl(&s3.GeneratePresignedUrlInput{
+	signedURL, err := handler.presignSvc.GeneratePresignedUrl(&s3.GeneratePresignedUrlInput{
 		HTTPMethod: s3.POST,
 		Bucket:     &handler.policy.BucketName,
 		Key:        &file.Props.SavePath,
@@ -469,7 +529,7 @@ func (handler *Driver) Token(ctx context.Context, uploadSession *fs.UploadSessio
 		UploadURLs:  urls,
 		CompleteURL: signedURL,
 		SessionID:   uploadSession.Props.UploadSessionID,
-		ChunkSize:   handler.chunkSize,
+		ChunkSize:   chunkSize,
 	}, nil
 }
 
@@ -514,6 +574,93 @@ func (handler *Driver) LocalPath(ctx context.Context, path string) string {
 	return ""
 }
 
+// HealthCheck 检查存储空间是否可访问，并写入、删除一个探测对象确认写权限
+func (handler *Driver) HealthCheck(ctx context.Context) error {
+	if _, err := handler.svc.HeadBucketWithContext(ctx, &s3.HeadBucketInput{
+		Bucket: &handler.policy.BucketName,
+	}); err != nil {
+		return healthCheckError(err)
+	}
+
+	probe := fmt.Sprintf("%s%d", healthCheckProbePrefix, time.Now().UnixNano())
+	if _, err := handler.svc.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket: &handler.policy.BucketName,
+		Key:    &probe,
+		Body:   bytes.NewReader([]byte("cloudreve")),
+	}); err != nil {
+		return healthCheckError(err)
+	}
+
+	if _, err := handler.svc.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: &handler.policy.BucketName,
+		Key:    &probe,
+	}); err != nil {
+		return healthCheckError(err)
+	}
+
+	return nil
+}
+
+// healthCheckError classifies error of health check requests.
+func healthCheckError(err error) error {
+	if aerr, ok := err.(awserr.Error); ok {
+		switch aerr.Code() {
+		case s3.ErrCodeNoSuchBucket:
+			return fmt.Errorf("%w: %s", driver.ErrHealthCheckBucketNotFound, err)
+		case "AccessDenied", "InvalidAccessKeyId", "SignatureDoesNotMatch":
+			return fmt.Errorf("%w: %s", driver.ErrHealthCheckAuth, err)
+		}
+	}
+
+	// HEAD responses have no body, fallback to status code.
+	if rerr, ok := err.(awserr.RequestFailure); ok {
+		switch rerr.StatusCode() {
+		case http.StatusNotFound:
+			return fmt.Errorf("%w: %s", driver.ErrHealthCheckBucketNotFound, err)
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return fmt.Errorf("%w: %s", driver.ErrHealthCheckAuth, err)
+		default:
+			return err
+		}
+	}
+
+	return fmt.Errorf("%w: %s", driver.ErrHealthCheckUnreachable, err)
+}
+
+// ListUploadedParts 列出已上传的分片
+func (handler *Driver) ListUploadedParts(ctx context.Context, session *fs.UploadSession) ([]driver.UploadedPart, error) {
+	var (
+		parts  []driver.UploadedPart
+		marker *int64
+	)
+	for {
+		res, err := handler.svc.ListPartsWithContext(ctx, &s3.ListPartsInput{
+			Bucket:           &handler.policy.BucketName,
+			Key:              &session.Props.SavePath,
+			UploadID:         &session.UploadID,
+			PartNumberMarker: marker,
+		})
+		if err != nil {
+			if aerr, ok := err.(awserr.Error); ok && aerr.Code() == s3.ErrCodeNoSuchUpload {
+				return nil, serializer.NewError(serializer.CodeUploadSessionExpired, "Multipart upload does not exist", err)
+			}
+			return nil, fmt.Errorf("failed to list uploaded parts: %w", err)
+		}
+
+		for _, part := range res.Parts {
+			parts = append(parts, driver.UploadedPart{
+				Number: int(aws.ToLong(part.PartNumber)),
+				Size:   aws.ToLong(part.Size),
+			})
+		}
+
+		if !aws.ToBoolean(res.IsTruncated) || res.NextPartNumberMarker == nil {
+			return parts, nil
+		}
+		marker = res.NextPartNumberMarker
+	}
+}
+
 // CompleteUpload 完成上传
 func (handler *Driver) CompleteUpload(ctx context.Context, session *fs.UploadSession) error {
 	if session.SentinelTaskID == 0 {
@@ -533,6 +680,12 @@ func (handler *Driver) CompleteUpload(ctx context.Context, session *fs.UploadSes
 			nil,
 		)
 	}
+
+	if err := driver.VerifyUploadedETag(handler.policy, session, res.Etag); err != nil {
+		return err
+	}
+
+	metrics.ObserveStorage(handler.policy.Type, metrics.StorageOpUpload, res.Size, nil)
 	return nil
 }
 
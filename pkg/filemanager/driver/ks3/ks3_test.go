@@ -0,0 +1,290 @@
+package ks3
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudreve/Cloudreve/v4/ent"
+	"github.com/cloudreve/Cloudreve/v4/inventory/types"
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/driver"
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/fs"
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/cloudreve/Cloudreve/v4/pkg/serializer"
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDriver_ListUploadedParts(t *testing.T) {
+	a := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		switch {
+		case r.URL.Query().Get("uploadId") == "expired":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`<Error><Code>NoSuchUpload</Code><Message>not found</Message></Error>`))
+		case r.URL.Query().Get("part-number-marker") == "":
+			w.Write([]byte(`<ListPartsResult><IsTruncated>true</IsTruncated><NextPartNumberMarker>2</NextPartNumberMarker>` +
+				`<Part><PartNumber>1</PartNumber><Size>10</Size></Part><Part><PartNumber>2</PartNumber><Size>10</Size></Part></ListPartsResult>`))
+		default:
+			w.Write([]byte(`<ListPartsResult><IsTruncated>false</IsTruncated>` +
+				`<Part><PartNumber>4</PartNumber><Size>5</Size></Part></ListPartsResult>`))
+		}
+	}))
+	defer server.Close()
+
+	policy := &ent.StoragePolicy{
+		ID:         2,
+		Server:     server.URL,
+		BucketName: "bucket",
+		AccessKey:  "ak",
+		SecretKey:  "sk",
+		Settings:   &types.PolicySetting{Region: "BEIJING", S3ForcePathStyle: true},
+	}
+	handler, err := New(context.Background(), policy, nil, nil, logging.NewConsoleLogger(logging.LevelError), nil)
+	a.NoError(err)
+
+	session := &fs.UploadSession{UploadID: "id", Props: &fs.UploadProps{SavePath: "1/file.txt"}}
+	parts, err := handler.ListUploadedParts(context.Background(), session)
+	a.NoError(err)
+	a.Equal([]driver.UploadedPart{{Number: 1, Size: 10}, {Number: 2, Size: 10}, {Number: 4, Size: 5}}, parts)
+
+	// Aborted or expired multipart upload
+	session.UploadID = "expired"
+	_, err = handler.ListUploadedParts(context.Background(), session)
+	var appErr serializer.AppError
+	if a.ErrorAs(err, &appErr) {
+		a.Equal(serializer.CodeUploadSessionExpired, appErr.Code)
+	}
+}
+
+func TestDriver_CompleteUpload_VerifyHash(t *testing.T) {
+	a := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "5")
+		w.Header().Set("ETag", `"0123ABCD-2"`)
+	}))
+	defer server.Close()
+
+	policy := &ent.StoragePolicy{
+		ID:         2,
+		Server:     server.URL,
+		BucketName: "bucket",
+		AccessKey:  "ak",
+		SecretKey:  "sk",
+		Settings:   &types.PolicySetting{Region: "BEIJING", S3ForcePathStyle: true},
+	}
+	handler, err := New(context.Background(), policy, nil, nil, logging.NewConsoleLogger(logging.LevelError), nil)
+	a.NoError(err)
+
+	session := &fs.UploadSession{SentinelTaskID: 1, Props: &fs.UploadProps{SavePath: "1/file.txt", Size: 5, ExpectedHash: "ffff-2"}}
+
+	// Verification is opt-in
+	a.NoError(handler.CompleteUpload(context.Background(), session))
+
+	policy.Settings.VerifyUploadHash = true
+	err = handler.CompleteUpload(context.Background(), session)
+	var appErr serializer.AppError
+	if a.ErrorAs(err, &appErr) {
+		a.Equal(serializer.CodeMetaMismatch, appErr.Code)
+	}
+
+	session.Props.ExpectedHash = "0123abcd-2"
+	a.NoError(handler.CompleteUpload(context.Background(), session))
+	session.Props.ExpectedHash = ""
+	a.NoError(handler.CompleteUpload(context.Background(), session))
+}
+
+type thumbSettings struct {
+	setting.Provider
+}
+
+func (s *thumbSettings) ThumbSize(ctx context.Context) (int, int) { return 400, 300 }
+func (s *thumbSettings) ThumbEncode(ctx context.Context) *setting.ThumbEncode {
+	return &setting.ThumbEncode{Format: "png"}
+}
+
+func TestDriver_SourceAndThumb(t *testing.T) {
+	e := fs.NewEntity(&ent.Entity{Source: "1/file.txt", Size: 5})
+	for _, c := range []struct {
+		name      string
+		private   bool
+		pathStyle bool
+		cdn       string
+		prefix    string
+	}{
+		{name: "public", prefix: "https://bucket.ks3.example.com/1/file.txt"},
+		{name: "public path style", pathStyle: true, prefix: "https://ks3.example.com/bucket/1/file.txt"},
+		{name: "public cdn", cdn: "cdn.example.com", prefix: "https://cdn.example.com/1/file.txt"},
+		{name: "public cdn path style", pathStyle: true, cdn: "http://cdn.example.com", prefix: "http://cdn.example.com/1/file.txt"},
+		{name: "private", private: true, prefix: "https://bucket.ks3.example.com/1/file.txt"},
+		{name: "private path style", private: true, pathStyle: true, prefix: "https://ks3.example.com/bucket/1/file.txt"},
+		{name: "private cdn", private: true, cdn: "cdn.example.com", prefix: "https://cdn.example.com/1/file.txt"},
+		{name: "private cdn path style", private: true, pathStyle: true, cdn: "cdn.example.com", prefix: "https://cdn.example.com/1/file.txt"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			a := assert.New(t)
+			policy := &ent.StoragePolicy{
+				Server:     "https://ks3.example.com",
+				BucketName: "bucket",
+				AccessKey:  "ak",
+				SecretKey:  "sk",
+				IsPrivate:  c.private,
+				Settings: &types.PolicySetting{
+					Region:             "BEIJING",
+					S3ForcePathStyle:   c.pathStyle,
+					CDNDomain:          c.cdn,
+					ServerSideEndpoint: "https://ks3-internal.example.com",
+				},
+			}
+			handler, err := New(context.Background(), policy, &thumbSettings{}, nil, logging.NewConsoleLogger(logging.LevelError), nil)
+			a.NoError(err)
+
+			source, err := handler.Source(context.Background(), e, &driver.GetSourceArgs{})
+			a.NoError(err)
+			thumb, err := handler.Thumb(context.Background(), nil, "jpg", e)
+			a.NoError(err)
+
+			for _, raw := range []string{source, thumb} {
+				a.True(strings.HasPrefix(raw, c.prefix), raw)
+				u, err := url.Parse(raw)
+				a.NoError(err)
+				// Signature is kept for private objects only
+				a.Equal(c.private, u.Query().Get("Signature") != "", raw)
+			}
+		})
+	}
+}
+
+func TestDriver_ServerSideEndpoint(t *testing.T) {
+	a := assert.New(t)
+
+	requested := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = true
+		w.Header().Set("Content-Length", "5")
+		w.Header().Set("ETag", `"etag"`)
+	}))
+	defer server.Close()
+
+	policy := &ent.StoragePolicy{
+		Server:     "https://ks3.example.com",
+		BucketName: "bucket",
+		AccessKey:  "ak",
+		SecretKey:  "sk",
+		Settings:   &types.PolicySetting{Region: "BEIJING", S3ForcePathStyle: true, ServerSideEndpoint: server.URL},
+	}
+	handler, err := New(context.Background(), policy, nil, nil, logging.NewConsoleLogger(logging.LevelError), nil)
+	a.NoError(err)
+
+	// Server side requests are sent to the internal endpoint
+	meta, err := handler.Meta(context.Background(), "1/file.txt")
+	a.NoError(err)
+	a.True(requested)
+	a.EqualValues(5, meta.Size)
+
+	// URLs for clients use public endpoint
+	source, err := handler.Source(context.Background(), fs.NewEntity(&ent.Entity{Source: "1/file.txt"}), &driver.GetSourceArgs{})
+	a.NoError(err)
+	a.Equal("https://ks3.example.com/bucket/1/file.txt", source)
+}
+
+func TestDriver_HealthCheck(t *testing.T) {
+	a := assert.New(t)
+
+	status := http.StatusOK
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if r.Method == http.MethodHead {
+			w.WriteHeader(status)
+		}
+	}))
+	defer server.Close()
+
+	policy := &ent.StoragePolicy{
+		Server:     server.URL,
+		BucketName: "bucket",
+		AccessKey:  "ak",
+		SecretKey:  "sk",
+		Settings:   &types.PolicySetting{Region: "BEIJING", S3ForcePathStyle: true},
+	}
+	handler, err := New(context.Background(), policy, nil, nil, logging.NewConsoleLogger(logging.LevelError), nil)
+	a.NoError(err)
+
+	// Probe object is written then removed
+	a.NoError(handler.HealthCheck(context.Background()))
+	if a.Len(requests, 3) {
+		a.Equal("HEAD /bucket", requests[0])
+		a.True(strings.HasPrefix(requests[1], "PUT /bucket/"+healthCheckProbePrefix))
+		a.Equal("DELETE"+strings.TrimPrefix(requests[1], "PUT"), requests[2])
+	}
+
+	status = http.StatusNotFound
+	a.ErrorIs(handler.HealthCheck(context.Background()), driver.ErrHealthCheckBucketNotFound)
+
+	status = http.StatusForbidden
+	a.ErrorIs(handler.HealthCheck(context.Background()), driver.ErrHealthCheckAuth)
+
+	server.Close()
+	a.ErrorIs(handler.HealthCheck(context.Background()), driver.ErrHealthCheckUnreachable)
+}
+
+type siteSettings struct {
+	setting.Provider
+}
+
+func (s *siteSettings) SiteURL(ctx context.Context) *url.URL {
+	return &url.URL{Scheme: "https", Host: "cloudreve.org"}
+}
+
+func TestDriver_Token_ChunkSize(t *testing.T) {
+	a := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte(`<InitiateMultipartUploadResult><UploadId>id</UploadId></InitiateMultipartUploadResult>`))
+	}))
+	defer server.Close()
+
+	policy := &ent.StoragePolicy{
+		Server:     server.URL,
+		BucketName: "bucket",
+		AccessKey:  "ak",
+		SecretKey:  "sk",
+		Settings:   &types.PolicySetting{Region: "BEIJING", S3ForcePathStyle: true, ChunkSize: 1 << 20},
+	}
+	handler, err := New(context.Background(), policy, &siteSettings{}, nil, logging.NewConsoleLogger(logging.LevelError), nil)
+	a.NoError(err)
+
+	token := func(size int64) (*fs.UploadSession, *fs.UploadCredential) {
+		props := &fs.UploadProps{SavePath: "1/file.bin", Size: size, MimeType: "application/octet-stream", ExpireAt: time.Now().Add(time.Hour)}
+		session := &fs.UploadSession{Props: props}
+		credential, err := handler.Token(context.Background(), session, &fs.UploadRequest{Props: props})
+		a.NoError(err)
+		return session, credential
+	}
+
+	// Configured chunk size is used if parts are within limit
+	session, credential := token(10 << 20)
+	a.EqualValues(1<<20, credential.ChunkSize)
+	a.EqualValues(1<<20, session.ChunkSize)
+	a.Len(credential.UploadURLs, 10)
+
+	// 10001 parts at configured chunk size, scaled up to fit in 10000 parts
+	size := int64(10001 << 20)
+	session, credential = token(size)
+	a.EqualValues((size+9999)/10000, credential.ChunkSize)
+	a.Equal(credential.ChunkSize, session.ChunkSize)
+	a.Len(credential.UploadURLs, driver.MaxMultipartParts)
+}
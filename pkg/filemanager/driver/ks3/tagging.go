@@ -0,0 +1,80 @@
+package ks3
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ks3sdklib/aws-sdk-go/aws"
+)
+
+const (
+	// https://docs.aws.amazon.com/AmazonS3/latest/userguide/object-tagging.html
+	maxObjectTags        = 10
+	maxObjectTagKeyLen   = 128
+	maxObjectTagValueLen = 256
+
+	taggingHeader = "x-amz-tagging"
+)
+
+var objectTagCharset = regexp.MustCompile(`^[\p{L}\p{Z}\p{N}_.:/=+\-@]*$`)
+
+type taggingCtx struct{}
+
+// objectTagging renders the tagging header value of uploaded object from policy settings.
+// Empty string is returned if no tags are configured.
+func objectTagging(tags map[string]string, uid, policyID int) (string, error) {
+	if len(tags) == 0 {
+		return "", nil
+	}
+
+	if len(tags) > maxObjectTags {
+		return "", fmt.Errorf("too many object tags: %d, at most %d tags are allowed", len(tags), maxObjectTags)
+	}
+
+	replacer := strings.NewReplacer("{uid}", strconv.Itoa(uid), "{policy}", strconv.Itoa(policyID))
+	values := url.Values{}
+	for k, v := range tags {
+		v = replacer.Replace(v)
+		if k == "" || utf8.RuneCountInString(k) > maxObjectTagKeyLen {
+			return "", fmt.Errorf("invalid object tag key %q: length must be between 1 and %d", k, maxObjectTagKeyLen)
+		}
+
+		if utf8.RuneCountInString(v) > maxObjectTagValueLen {
+			return "", fmt.Errorf("invalid object tag value of %q: length must not exceed %d", k, maxObjectTagValueLen)
+		}
+
+		if !objectTagCharset.MatchString(k) || !objectTagCharset.MatchString(v) {
+			return "", fmt.Errorf("invalid object tag %q=%q: contains unsupported characters", k, v)
+		}
+
+		values.Set(k, v)
+	}
+
+	return values.Encode(), nil
+}
+
+// withTagging attaches object tagging to the context, which will be applied by tagging handler.
+func withTagging(ctx context.Context, tagging string) context.Context {
+	if tagging == "" {
+		return ctx
+	}
+
+	return context.WithValue(ctx, taggingCtx{}, tagging)
+}
+
+// taggingHandler sets tagging header for object creation requests issued with tagging context,
+// used for uploads through s3manager, which does not expose tagging in its input.
+func taggingHandler(r *aws.Request) {
+	if r.Operation == nil || (r.Operation.Name != "PutObject" && r.Operation.Name != "CreateMultipartUpload") {
+		return
+	}
+
+	if tagging, ok := r.HTTPRequest.Context().Value(taggingCtx{}).(string); ok && r.HTTPRequest.Header.Get(taggingHeader) == "" {
+		r.HTTPRequest.Header.Set(taggingHeader, tagging)
+	}
+}
@@ -0,0 +1,98 @@
+package ks3
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cloudreve/Cloudreve/v4/ent"
+	"github.com/cloudreve/Cloudreve/v4/inventory"
+	"github.com/cloudreve/Cloudreve/v4/inventory/types"
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/fs"
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjectTagging(t *testing.T) {
+	a := assert.New(t)
+
+	tagging, err := objectTagging(nil, 1, 2)
+	a.NoError(err)
+	a.Empty(tagging)
+
+	tagging, err = objectTagging(map[string]string{
+		"owner":      "{uid}",
+		"policy":     "{policy}",
+		"managed by": "Cloudreve/v4",
+	}, 1, 2)
+	a.NoError(err)
+	a.Equal("managed+by=Cloudreve%2Fv4&owner=1&policy=2", tagging)
+
+	_, err = objectTagging(map[string]string{"": "v"}, 1, 2)
+	a.Error(err)
+	_, err = objectTagging(map[string]string{strings.Repeat("k", maxObjectTagKeyLen+1): "v"}, 1, 2)
+	a.Error(err)
+	_, err = objectTagging(map[string]string{"k": strings.Repeat("v", maxObjectTagValueLen+1)}, 1, 2)
+	a.Error(err)
+	_, err = objectTagging(map[string]string{"k": "a&b"}, 1, 2)
+	a.Error(err)
+
+	tooMany := map[string]string{}
+	for _, k := range strings.Split("a b c d e f g h i j k", " ") {
+		tooMany[k] = "v"
+	}
+	_, err = objectTagging(tooMany, 1, 2)
+	a.Error(err)
+}
+
+func TestDriver_PutWithTagging(t *testing.T) {
+	a := assert.New(t)
+
+	var (
+		mu      sync.Mutex
+		tagging []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		if r.Method == http.MethodPut {
+			mu.Lock()
+			tagging = append(tagging, r.Header.Get(taggingHeader))
+			mu.Unlock()
+		}
+		w.Header().Set("ETag", `"etag"`)
+	}))
+	defer server.Close()
+
+	policy := &ent.StoragePolicy{
+		ID:         2,
+		Server:     server.URL,
+		BucketName: "bucket",
+		AccessKey:  "ak",
+		SecretKey:  "sk",
+		Settings: &types.PolicySetting{
+			Region:           "BEIJING",
+			S3ForcePathStyle: true,
+			ObjectTags:       map[string]string{"owner": "{uid}", "path": "a/b c"},
+		},
+	}
+	handler, err := New(context.Background(), policy, nil, nil, logging.NewConsoleLogger(logging.LevelError), nil)
+	a.NoError(err)
+
+	content := "hello"
+	ctx := context.WithValue(context.Background(), inventory.UserIDCtx{}, 1)
+	a.NoError(handler.Put(ctx, &fs.UploadRequest{
+		Props: &fs.UploadProps{SavePath: "1/file.txt", Size: int64(len(content)), MimeType: "text/plain"},
+		Mode:  fs.ModeOverwrite,
+		File:  io.NopCloser(strings.NewReader(content)),
+	}))
+
+	a.Equal([]string{"owner=1&path=a%2Fb+c"}, tagging)
+	parsed, err := url.ParseQuery(strings.Join(tagging, "&"))
+	a.NoError(err)
+	a.Equal("a/b c", parsed.Get("path"))
+}
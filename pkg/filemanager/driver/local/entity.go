@@ -70,6 +70,10 @@ func (l *localFileEntity) UploadSessionID() *uuid.UUID {
 	return nil
 }
 
+func (l *localFileEntity) Checksums() map[string]string {
+	return nil
+}
+
 func (l *localFileEntity) Model() *ent.Entity {
 	return nil
 }
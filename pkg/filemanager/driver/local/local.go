@@ -35,8 +35,9 @@ var (
 
 func init() {
 	boolset.Sets(map[driver.HandlerCapability]bool{
-		driver.HandlerCapabilityProxyRequired: true,
-		driver.HandlerCapabilityInboundGet:    true,
+		driver.HandlerCapabilityProxyRequired:  true,
+		driver.HandlerCapabilityInboundGet:     true,
+		driver.HandlerCapabilityRangeSupported: true,
 	}, capabilities.StaticFeatures)
 }
 
@@ -252,6 +253,16 @@ func (handler *Driver) CancelToken(ctx context.Context, uploadSession *fs.Upload
 	return nil
 }
 
+// HealthCheck 检查存储是否可用
+func (handler *Driver) HealthCheck(ctx context.Context) error {
+	return errors.New("not implemented")
+}
+
+// ListUploadedParts 列出已上传的分片
+func (handler *Driver) ListUploadedParts(ctx context.Context, session *fs.UploadSession) ([]driver.UploadedPart, error) {
+	return nil, errors.New("not implemented")
+}
+
 func (handler *Driver) CompleteUpload(ctx context.Context, session *fs.UploadSession) error {
 	if session.Callback == "" {
 		return nil
@@ -51,6 +51,12 @@ var (
 	features = &boolset.BooleanSet{}
 )
 
+func init() {
+	boolset.Sets(map[driver.HandlerCapability]bool{
+		driver.HandlerCapabilityRangeSupported: true,
+	}, features)
+}
+
 type (
 	CallbackPolicy struct {
 		CallbackURL      string `json:"callbackUrl"`
@@ -339,7 +345,7 @@ func (d *Driver) Thumb(ctx context.Context, expire *time.Time, ext string, e fs.
 
 	enco := d.settings.ThumbEncode(ctx)
 	switch enco.Format {
-	case "jpg", "webp":
+	case "jpg", "webp", "avif":
 		thumbParam += fmt.Sprintf("/format,%s/quality,q_%d", enco.Format, enco.Quality)
 	case "png":
 		thumbParam += fmt.Sprintf("/format,%s", enco.Format)
@@ -515,6 +521,16 @@ func (d *Driver) CancelToken(ctx context.Context, uploadSession *fs.UploadSessio
 	return err
 }
 
+// HealthCheck 检查存储是否可用
+func (d *Driver) HealthCheck(ctx context.Context) error {
+	return errors.New("not implemented")
+}
+
+// ListUploadedParts 列出已上传的分片
+func (d *Driver) ListUploadedParts(ctx context.Context, session *fs.UploadSession) ([]driver.UploadedPart, error) {
+	return nil, errors.New("not implemented")
+}
+
 func (d *Driver) CompleteUpload(ctx context.Context, session *fs.UploadSession) error {
 	return nil
 }
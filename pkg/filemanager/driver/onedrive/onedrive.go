@@ -48,6 +48,7 @@ const (
 func init() {
 	boolset.Sets(map[driver.HandlerCapability]bool{
 		driver.HandlerCapabilityUploadSentinelRequired: true,
+		driver.HandlerCapabilityRangeSupported:         true,
 	}, features)
 }
 
@@ -196,6 +197,16 @@ func (handler *Driver) CancelToken(ctx context.Context, uploadSession *fs.Upload
 	return err
 }
 
+// HealthCheck 检查存储是否可用
+func (handler *Driver) HealthCheck(ctx context.Context) error {
+	return errors.New("not implemented")
+}
+
+// ListUploadedParts 列出已上传的分片
+func (handler *Driver) ListUploadedParts(ctx context.Context, session *fs.UploadSession) ([]driver.UploadedPart, error) {
+	return nil, errors.New("not implemented")
+}
+
 func (handler *Driver) CompleteUpload(ctx context.Context, session *fs.UploadSession) error {
 	if session.SentinelTaskID == 0 {
 		return nil
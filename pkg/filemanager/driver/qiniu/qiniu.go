@@ -43,6 +43,12 @@ var (
 	features = &boolset.BooleanSet{}
 )
 
+func init() {
+	boolset.Sets(map[driver.HandlerCapability]bool{
+		driver.HandlerCapabilityRangeSupported: true,
+	}, features)
+}
+
 // Driver 本地策略适配器
 type Driver struct {
 	policy *ent.StoragePolicy
@@ -281,7 +287,7 @@ func (handler *Driver) Thumb(ctx context.Context, expire *time.Time, ext string,
 
 	enco := handler.settings.ThumbEncode(ctx)
 	switch enco.Format {
-	case "jpg", "webp":
+	case "jpg", "webp", "avif":
 		thumbParam += fmt.Sprintf("/format/%s/q/%d", enco.Format, enco.Quality)
 	case "png":
 		thumbParam += fmt.Sprintf("/format/%s", enco.Format)
@@ -413,6 +419,16 @@ func (handler *Driver) CancelToken(ctx context.Context, uploadSession *fs.Upload
 	return resumeUploader.Client.CallWith(ctx, nil, "DELETE", uploadSession.UploadURL, http.Header{"Authorization": {"UpToken " + uploadSession.Credential}}, nil, 0)
 }
 
+// HealthCheck 检查存储是否可用
+func (handler *Driver) HealthCheck(ctx context.Context) error {
+	return errors.New("not implemented")
+}
+
+// ListUploadedParts 列出已上传的分片
+func (handler *Driver) ListUploadedParts(ctx context.Context, session *fs.UploadSession) ([]driver.UploadedPart, error) {
+	return nil, errors.New("not implemented")
+}
+
 func (handler *Driver) CompleteUpload(ctx context.Context, session *fs.UploadSession) error {
 	return nil
 }
@@ -25,6 +25,12 @@ var (
 	features = &boolset.BooleanSet{}
 )
 
+func init() {
+	boolset.Sets(map[driver.HandlerCapability]bool{
+		driver.HandlerCapabilityRangeSupported: true,
+	}, features)
+}
+
 // Driver 远程存储策略适配器
 type Driver struct {
 	Client       request.Client
@@ -163,6 +169,16 @@ func (handler *Driver) CancelToken(ctx context.Context, uploadSession *fs.Upload
 	return handler.uploadClient.DeleteUploadSession(ctx, uploadSession.Props.UploadSessionID)
 }
 
+// HealthCheck 检查存储是否可用
+func (handler *Driver) HealthCheck(ctx context.Context) error {
+	return errors.New("not implemented")
+}
+
+// ListUploadedParts 列出已上传的分片
+func (handler *Driver) ListUploadedParts(ctx context.Context, session *fs.UploadSession) ([]driver.UploadedPart, error) {
+	return nil, errors.New("not implemented")
+}
+
 func (handler *Driver) CompleteUpload(ctx context.Context, session *fs.UploadSession) error {
 	return nil
 }
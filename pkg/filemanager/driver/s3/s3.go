@@ -68,6 +68,7 @@ var (
 func init() {
 	boolset.Sets(map[driver.HandlerCapability]bool{
 		driver.HandlerCapabilityUploadSentinelRequired: true,
+		driver.HandlerCapabilityRangeSupported:         true,
 	}, features)
 }
 
@@ -339,7 +340,8 @@ func (handler *Driver) Token(ctx context.Context, uploadSession *fs.UploadSessio
 	// 生成回调地址
 	siteURL := handler.settings.SiteURL(setting.UseFirstSiteUrl(ctx))
 	// 在从机端创建上传会话
-	uploadSession.ChunkSize = handler.chunkSize
+	chunkSize := driver.MultipartChunkSize(handler.chunkSize, file.Props.Size)
+	uploadSession.ChunkSize = chunkSize
 	uploadSession.Callback = routes.MasterSlaveCallbackUrl(siteURL, types.PolicyTypeS3, uploadSession.Props.UploadSessionID, uploadSession.CallbackSecret).String()
 
 	mimeType := file.Props.MimeType
@@ -361,7 +363,7 @@ func (handler *Driver) Token(ctx context.Context, uploadSession *fs.UploadSessio
 	uploadSession.UploadID = *res.UploadId
 
 	// 为每个分片签名上传 URL
-	chunks := chunk.NewChunkGroup(file, handler.chunkSize, &backoff.ConstantBackoff{}, false, handler.l, "")
+	chunks := chunk.NewChunkGroup(file, chunkSize, &backoff.ConstantBackoff{}, false, handler.l, "")
 	urls := make([]string, chunks.Num())
 	for chunks.Next() {
 		err := chunks.Process(func(c *chunk.ChunkGroup, chunk io.Reader) error {
@@ -404,7 +406,7 @@ func (handler *Driver) Token(ctx context.Context, uploadSession *fs.UploadSessio
 		UploadURLs:  urls,
 		CompleteURL: signedURL,
 		SessionID:   uploadSession.Props.UploadSessionID,
-		ChunkSize:   handler.chunkSize,
+		ChunkSize:   chunkSize,
 	}, nil
 }
 
@@ -490,6 +492,16 @@ func (handler *Driver) LocalPath(ctx context.Context, path string) string {
 	return ""
 }
 
+// HealthCheck 检查存储是否可用
+func (handler *Driver) HealthCheck(ctx context.Context) error {
+	return errors.New("not implemented")
+}
+
+// ListUploadedParts 列出已上传的分片
+func (handler *Driver) ListUploadedParts(ctx context.Context, session *fs.UploadSession) ([]driver.UploadedPart, error) {
+	return nil, errors.New("not implemented")
+}
+
 func (handler *Driver) CompleteUpload(ctx context.Context, session *fs.UploadSession) error {
 	if session.SentinelTaskID == 0 {
 		return nil
@@ -508,7 +520,8 @@ func (handler *Driver) CompleteUpload(ctx context.Context, session *fs.UploadSes
 			nil,
 		)
 	}
-	return nil
+
+	return driver.VerifyUploadedETag(handler.policy, session, res.Etag)
 }
 
 type Reader struct {
@@ -62,6 +62,12 @@ var (
 	features = &boolset.BooleanSet{}
 )
 
+func init() {
+	boolset.Sets(map[driver.HandlerCapability]bool{
+		driver.HandlerCapabilityRangeSupported: true,
+	}, features)
+}
+
 func New(ctx context.Context, policy *ent.StoragePolicy, settings setting.Provider,
 	config conf.ConfigProvider, l logging.Logger, mime mime.MimeDetector) (*Driver, error) {
 	driver := &Driver{
@@ -207,7 +213,7 @@ func (handler *Driver) Thumb(ctx context.Context, expire *time.Time, ext string,
 
 	enco := handler.settings.ThumbEncode(ctx)
 	switch enco.Format {
-	case "jpg", "webp":
+	case "jpg", "webp", "avif":
 		thumbParam += fmt.Sprintf("/format/%s/quality/%d", enco.Format, enco.Quality)
 	case "png":
 		thumbParam += fmt.Sprintf("/format/%s", enco.Format)
@@ -325,6 +331,16 @@ func (handler *Driver) CancelToken(ctx context.Context, uploadSession *fs.Upload
 	return nil
 }
 
+// HealthCheck 检查存储是否可用
+func (handler *Driver) HealthCheck(ctx context.Context) error {
+	return errors.New("not implemented")
+}
+
+// ListUploadedParts 列出已上传的分片
+func (handler *Driver) ListUploadedParts(ctx context.Context, session *fs.UploadSession) ([]driver.UploadedPart, error) {
+	return nil, errors.New("not implemented")
+}
+
 func (handler *Driver) CompleteUpload(ctx context.Context, session *fs.UploadSession) error {
 	return nil
 }
@@ -7,6 +7,8 @@ import (
 	"strings"
 
 	"github.com/cloudreve/Cloudreve/v4/ent"
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/fs"
+	"github.com/cloudreve/Cloudreve/v4/pkg/serializer"
 )
 
 func ApplyProxyIfNeeded(policy *ent.StoragePolicy, srcUrl *url.URL) (*url.URL, error) {
@@ -41,3 +43,34 @@ func ApplyProxyIfNeeded(policy *ent.StoragePolicy, srcUrl *url.URL) (*url.URL, e
 
 	return srcUrl, nil
 }
+
+// VerifyUploadedETag compares ETag of uploaded object with the hash expected by client. It is a no-op
+// unless hash verification is enabled in storage policy and client supplied the expected hash.
+func VerifyUploadedETag(policy *ent.StoragePolicy, session *fs.UploadSession, etag string) error {
+	if policy.Settings == nil || !policy.Settings.VerifyUploadHash || session.Props.ExpectedHash == "" {
+		return nil
+	}
+
+	normalize := func(s string) string {
+		return strings.ToLower(strings.Trim(s, "\" "))
+	}
+	if normalize(etag) != normalize(session.Props.ExpectedHash) {
+		return serializer.NewError(
+			serializer.CodeMetaMismatch,
+			fmt.Sprintf("File hash not match, expected: %s, actual: %s", session.Props.ExpectedHash, etag),
+			nil,
+		)
+	}
+
+	return nil
+}
+
+// MaxMultipartParts is the maximum number of parts allowed in a S3-compatible multipart upload.
+const MaxMultipartParts = 10000
+
+// MultipartChunkSize returns chunk size used to upload a file of given size, the configured
+// chunk size is scaled up if the file would otherwise be split into more than MaxMultipartParts parts.
+func MultipartChunkSize(configured, fileSize int64) int64 {
+	minSize := (fileSize + MaxMultipartParts - 1) / MaxMultipartParts
+	return max(configured, minSize)
+}
@@ -42,6 +42,8 @@ const (
 type (
 	ContextHintCtxKey      struct{}
 	ByPassOwnerCheckCtxKey struct{}
+	// WalkBatchSizeCtxKey limits the number of children fetched in one query during walk.
+	WalkBatchSizeCtxKey struct{}
 )
 
 func NewDatabaseFS(u *ent.User, fileClient inventory.FileClient, shareClient inventory.ShareClient,
@@ -120,6 +122,11 @@ func (f *DBFS) List(ctx context.Context, path *fs.URI, opts ...fs.Option) (fs.Fi
 
 	searchParams := path.SearchParameters()
 	isSearching := searchParams != nil
+	if isSearching && len(searchParams.Props) > 0 {
+		if err := inventory.ResolvePropsFilters(f.settingClient.CustomProps(ctx), searchParams.Props); err != nil {
+			return nil, nil, serializer.NewError(serializer.CodeParamErr, "Invalid custom props filter", err)
+		}
+	}
 
 	parent, err := f.getFileByPath(ctx, navigator, path)
 	if err != nil {
@@ -302,6 +309,47 @@ func (f *DBFS) CreateEntity(ctx context.Context, file fs.File, policy *ent.Stora
 	return fs.NewEntity(entity), nil
 }
 
+func (f *DBFS) LinkThumbnail(ctx context.Context, path *fs.URI, entity fs.Entity) (fs.Entity, error) {
+	if entity.Type() != types.EntityTypeThumbnail {
+		return nil, fmt.Errorf("link thumbnail: entity %d is not a thumbnail", entity.ID())
+	}
+
+	navigator, err := f.getNavigator(ctx, path, NavigatorCapabilityGenerateThumb)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := f.getFileByPath(ctx, navigator, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get target file: %w", err)
+	}
+
+	fc, tx, ctx, err := inventory.WithTx(ctx, f.fileClient)
+	if err != nil {
+		return nil, serializer.NewError(serializer.CodeDBError, "Failed to start transaction", err)
+	}
+
+	diff, err := fc.CapEntities(ctx, target.Model, target.Owner(), 0, types.EntityTypeThumbnail)
+	if err != nil {
+		_ = inventory.Rollback(tx)
+		return nil, serializer.NewError(serializer.CodeDBError, "Failed to cap thumbnail entities", err)
+	}
+	tx.AppendStorageDiff(diff)
+
+	diff, err = fc.LinkEntity(ctx, entity.Model(), target.Model, target.Owner())
+	if err != nil {
+		_ = inventory.Rollback(tx)
+		return nil, serializer.NewError(serializer.CodeDBError, "Failed to link thumbnail entity", err)
+	}
+	tx.AppendStorageDiff(diff)
+
+	if err := inventory.CommitWithStorageDiff(ctx, tx, f.l, f.userClient); err != nil {
+		return nil, serializer.NewError(serializer.CodeDBError, "Failed to commit link change", err)
+	}
+
+	return entity, nil
+}
+
 func (f *DBFS) SharedAddressTranslation(ctx context.Context, path *fs.URI, opts ...fs.Option) (fs.File, *fs.URI, error) {
 	o := newDbfsOption()
 	for _, opt := range opts {
@@ -467,6 +515,7 @@ func (f *DBFS) Get(ctx context.Context, path *fs.URI, opts ...fs.Option) (fs.Fil
 
 			// disable load metadata to speed up
 			ctxWalk := context.WithValue(ctx, inventory.LoadFilePublicMetadata{}, false)
+			ctxWalk = context.WithValue(ctxWalk, WalkBatchSizeCtxKey{}, f.settingClient.FolderPropsBatchSize(ctx))
 			if err := navigator.Walk(ctxWalk, []*File{target}, limit, intsets.MaxInt, func(files []*File, l int) error {
 				for _, file := range files {
 					if file.ID() == target.ID() {
@@ -63,8 +63,12 @@ const (
 	MetadataExpectedCollectTime = MetadataSysPrefix + "expected_collect_time"
 	MetadataSharedOwner         = MetadataSysPrefix + "shared_owner"
 
-	ThumbMetadataPrefix = "thumb:"
-	ThumbDisabledKey    = ThumbMetadataPrefix + "disabled"
+	ThumbMetadataPrefix   = "thumb:"
+	ThumbDisabledKey      = ThumbMetadataPrefix + "disabled"
+	ThumbBlurhashKey      = ThumbMetadataPrefix + "blurhash"
+	ThumbDominantColorKey = ThumbMetadataPrefix + "dominant_color"
+	// ThumbAnimatedDisabledKey marks files whose animated preview failed to generate.
+	ThumbAnimatedDisabledKey = ThumbMetadataPrefix + "animated_disabled"
 
 	pathIndexRoot = 0
 	pathIndexUser = 1
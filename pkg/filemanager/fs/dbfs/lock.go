@@ -106,6 +106,15 @@ func (f *DBFS) Refresh(ctx context.Context, d time.Duration, token string) (lock
 	return f.ls.Refresh(time.Now(), d, token)
 }
 
+func (f *DBFS) Relock(ctx context.Context, d time.Duration, oldToken, newToken string) (lock.LockDetails, error) {
+	return f.ls.Relock(time.Now(), d, oldToken, newToken)
+}
+
+func (f *DBFS) PeekLock(ctx context.Context, ancestor fs.File, uri *fs.URI) *lock.ConflictDetail {
+	ns, root, _ := lockTupleFromUri(ancestor.RootUri().JoinRaw(uri.PathTrimmed()), f.user, f.hasher)
+	return f.ls.Peek(time.Now(), ns, root)
+}
+
 func (f *DBFS) acquireByPath(ctx context.Context, duration time.Duration,
 	requester *ent.User, zeroDepth bool, application lock.Application, locks ...*LockByPath) (*LockSession, error) {
 	session := LockSessionFromCtx(ctx)
@@ -215,7 +215,7 @@ func (f *DBFS) Rename(ctx context.Context, path *fs.URI, newName string) (fs.Fil
 	}
 
 	if target.Type() == types.FileTypeFile && !strings.EqualFold(filepath.Ext(newName), filepath.Ext(oldName)) {
-		if err := fc.RemoveMetadata(ctx, target.Model, ThumbDisabledKey); err != nil {
+		if err := fc.RemoveMetadata(ctx, target.Model, ThumbDisabledKey, ThumbAnimatedDisabledKey); err != nil {
 			_ = inventory.Rollback(tx)
 			return nil, serializer.NewError(serializer.CodeDBError, "failed to remove disabled thumbnail mark", err)
 		}
@@ -742,13 +742,15 @@ func (f *DBFS) setCurrentVersion(ctx context.Context, target *File, versionId in
 	}
 
 	// Cap thumbnail entities
-	diff, err := fc.CapEntities(ctx, target.Model, target.Owner(), 0, types.EntityTypeThumbnail)
-	if err != nil {
-		_ = inventory.Rollback(tx)
-		return serializer.NewError(serializer.CodeDBError, "Failed to cap thumbnail entities", err)
-	}
+	for _, thumbType := range []types.EntityType{types.EntityTypeThumbnail, types.EntityTypeAnimatedThumbnail} {
+		diff, err := fc.CapEntities(ctx, target.Model, target.Owner(), 0, thumbType)
+		if err != nil {
+			_ = inventory.Rollback(tx)
+			return serializer.NewError(serializer.CodeDBError, "Failed to cap thumbnail entities", err)
+		}
 
-	tx.AppendStorageDiff(diff)
+		tx.AppendStorageDiff(diff)
+	}
 	if err := inventory.CommitWithStorageDiff(ctx, tx, f.l, f.userClient); err != nil {
 		return serializer.NewError(serializer.CodeDBError, "Failed to commit set current version", err)
 	}
@@ -274,6 +274,7 @@ func (b *baseNavigator) walk(ctx context.Context, levelFiles []*File, limit, dep
 	}
 
 	owner := levelFiles[0].Owner()
+	batchSize, _ := ctx.Value(WalkBatchSizeCtxKey{}).(int)
 
 	level := 0
 	for walked <= limit && depth >= 0 {
@@ -309,14 +310,19 @@ func (b *baseNavigator) walk(ctx context.Context, levelFiles []*File, limit, dep
 		parents := lo.SliceToMap(folders, func(file *File) (int, *File) {
 			return file.Model.ID, file
 		})
+		token := ""
 		for leftCredit > 0 {
-			token := ""
+			pageSize := leftCredit
+			if batchSize > 0 && batchSize < pageSize {
+				pageSize = batchSize
+			}
+
 			res, err := b.fileClient.GetChildFiles(ctx,
 				&inventory.ListFileParameters{
 					PaginationArgs: &inventory.PaginationArgs{
 						UseCursorPagination: true,
 						PageToken:           token,
-						PageSize:            leftCredit,
+						PageSize:            pageSize,
 					},
 					MixedType: true,
 				},
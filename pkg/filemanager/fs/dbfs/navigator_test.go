@@ -0,0 +1,130 @@
+package dbfs
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/cloudreve/Cloudreve/v4/ent"
+	"github.com/cloudreve/Cloudreve/v4/inventory"
+	"github.com/cloudreve/Cloudreve/v4/inventory/types"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/tools/container/intsets"
+)
+
+// walkFileClient is an in-memory FileClient that only supports cursor paginated GetChildFiles.
+type walkFileClient struct {
+	inventory.FileClient
+	children map[int][]*ent.File
+	queries  int
+}
+
+func (c *walkFileClient) GetChildFiles(ctx context.Context, args *inventory.ListFileParameters, ownerID int, roots ...*ent.File) (*inventory.ListFileResult, error) {
+	c.queries++
+	all := lo.FlatMap(roots, func(root *ent.File, index int) []*ent.File {
+		return c.children[root.ID]
+	})
+
+	start := 0
+	if args.PageToken != "" {
+		start, _ = strconv.Atoi(args.PageToken)
+	}
+
+	end := min(start+args.PageSize, len(all))
+	res := &inventory.ListFileResult{
+		Files:             all[start:end],
+		PaginationResults: &inventory.PaginationResults{},
+	}
+	if end < len(all) {
+		res.NextPageToken = strconv.Itoa(end)
+	}
+
+	return res, nil
+}
+
+// newWalkTree builds a folder tree with given fan-out and depth, returns the root and the client.
+func newWalkTree(fanOut, depth int) (*File, *walkFileClient) {
+	c := &walkFileClient{children: make(map[int][]*ent.File)}
+	id := 1
+	root := &ent.File{ID: id, Name: "root", Type: int(types.FileTypeFolder)}
+	level := []*ent.File{root}
+	for d := 0; d < depth; d++ {
+		next := make([]*ent.File, 0, len(level)*fanOut)
+		for _, parent := range level {
+			for i := 0; i < fanOut; i++ {
+				id++
+				fileType := types.FileTypeFolder
+				if i%2 == 1 || d == depth-1 {
+					fileType = types.FileTypeFile
+				}
+				child := &ent.File{ID: id, Name: fmt.Sprintf("%d", id), Type: int(fileType), Size: int64(id), FileChildren: parent.ID}
+				c.children[parent.ID] = append(c.children[parent.ID], child)
+				if fileType == types.FileTypeFolder {
+					next = append(next, child)
+				}
+			}
+		}
+		level = next
+	}
+
+	rootFile := newFile(nil, root)
+	rootFile.OwnerModel = &ent.User{ID: 1}
+	return rootFile, c
+}
+
+func walkSummary(batchSize, limit int) (int, int64, int, error) {
+	root, c := newWalkTree(6, 4)
+	nav := &baseNavigator{fileClient: c}
+	ctx := context.WithValue(context.Background(), WalkBatchSizeCtxKey{}, batchSize)
+
+	count := 0
+	var size int64
+	err := nav.walk(ctx, []*File{root}, limit, intsets.MaxInt, func(files []*File, l int) error {
+		for _, f := range files {
+			count++
+			size += f.Size()
+		}
+		return nil
+	})
+
+	return count, size, c.queries, err
+}
+
+func TestWalk_BatchSize(t *testing.T) {
+	a := assert.New(t)
+
+	expectedCount, expectedSize, _, err := walkSummary(0, intsets.MaxInt)
+	a.NoError(err)
+
+	for _, batchSize := range []int{1, 2, 7, 100, 10000} {
+		count, size, _, err := walkSummary(batchSize, intsets.MaxInt)
+		a.NoError(err, "batch size %d", batchSize)
+		a.Equal(expectedCount, count, "batch size %d", batchSize)
+		a.Equal(expectedSize, size, "batch size %d", batchSize)
+	}
+
+	// Limit is respected regardless of batch size
+	for _, batchSize := range []int{0, 1, 7, 10000} {
+		count, _, _, err := walkSummary(batchSize, 20)
+		a.ErrorIs(err, ErrFileCountLimitedReached, "batch size %d", batchSize)
+		a.Equal(20, count, "batch size %d", batchSize)
+	}
+}
+
+func BenchmarkWalk_BatchSize(b *testing.B) {
+	for _, batchSize := range []int{0, 10, 100, 1000} {
+		b.Run(fmt.Sprintf("batch_%d", batchSize), func(b *testing.B) {
+			queries := 0
+			for i := 0; i < b.N; i++ {
+				_, _, q, err := walkSummary(batchSize, intsets.MaxInt)
+				if err != nil {
+					b.Fatal(err)
+				}
+				queries += q
+			}
+			b.ReportMetric(float64(queries)/float64(b.N), "queries/op")
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/cloudreve/Cloudreve/v4/application/constants"
 	"github.com/cloudreve/Cloudreve/v4/ent"
+	"github.com/cloudreve/Cloudreve/v4/ent/schema"
 	"github.com/cloudreve/Cloudreve/v4/inventory"
 	"github.com/cloudreve/Cloudreve/v4/inventory/types"
 	"github.com/cloudreve/Cloudreve/v4/pkg/boolset"
@@ -86,6 +87,7 @@ func (t *trashNavigator) To(ctx context.Context, path *fs.URI) (*File, error) {
 		return nil, nil
 	}
 
+	ctx = schema.IncludeDeletedFiles(ctx)
 	current, err := t.walkNext(ctx, nil, elements[0], true)
 	if err != nil {
 		return nil, fmt.Errorf("failed to walk into %q: %w", elements[0], err)
@@ -102,7 +104,7 @@ func (t *trashNavigator) Children(ctx context.Context, parent *File, args *ListA
 		return nil, fs.ErrPathNotExist
 	}
 
-	res, err := t.baseNavigator.children(ctx, nil, args)
+	res, err := t.baseNavigator.children(schema.IncludeDeletedFiles(ctx), nil, args)
 	if err != nil {
 		return nil, err
 	}
@@ -131,7 +133,7 @@ func (t *trashNavigator) Capabilities(isSearching bool) *fs.NavigatorProps {
 }
 
 func (t *trashNavigator) Walk(ctx context.Context, levelFiles []*File, limit, depth int, f WalkFunc) error {
-	return t.baseNavigator.walk(ctx, levelFiles, limit, depth, f)
+	return t.baseNavigator.walk(schema.IncludeDeletedFiles(ctx), levelFiles, limit, depth, f)
 }
 
 func (n *trashNavigator) FollowTx(ctx context.Context) (func(), error) {
@@ -140,13 +140,18 @@ func (f *DBFS) PrepareUpload(ctx context.Context, req *fs.UploadRequest, opts ..
 	}
 
 	// Generate save path by storage policy
-	isThumbnailAndPolicyNotAvailable := policy.ID != ancestor.Model.StoragePolicyFiles &&
-		(req.Props.EntityType != nil && *req.Props.EntityType == types.EntityTypeThumbnail) &&
+	isThumbnail := req.Props.EntityType != nil &&
+		(*req.Props.EntityType == types.EntityTypeThumbnail || *req.Props.EntityType == types.EntityTypeAnimatedThumbnail)
+	isThumbnailAndPolicyNotAvailable := policy.ID != ancestor.Model.StoragePolicyFiles && isThumbnail &&
 		req.ImportFrom == nil
 	if req.Props.SavePath == "" || isThumbnailAndPolicyNotAvailable {
 		req.Props.SavePath = generateSavePath(policy, req, f.user)
 		if isThumbnailAndPolicyNotAvailable {
-			req.Props.SavePath = req.Props.SavePath + f.settingClient.ThumbEntitySuffix(ctx)
+			if *req.Props.EntityType == types.EntityTypeAnimatedThumbnail {
+				req.Props.SavePath = req.Props.SavePath + f.settingClient.ThumbAnimatedEntitySuffix(ctx)
+			} else {
+				req.Props.SavePath = req.Props.SavePath + f.settingClient.ThumbEntitySuffix(ctx)
+			}
 		}
 	}
 
@@ -223,6 +228,7 @@ func (f *DBFS) PrepareUpload(ctx context.Context, req *fs.UploadRequest, opts ..
 			ExpireAt:        req.Props.ExpireAt,
 			EntityType:      req.Props.EntityType,
 			Metadata:        req.Props.Metadata,
+			ExpectedHash:    req.Props.ExpectedHash,
 		},
 		FileID:         fileId,
 		NewFileCreated: !fileExisted,
@@ -292,7 +298,7 @@ func (f *DBFS) CompleteUpload(ctx context.Context, session *fs.UploadSession) (f
 	}
 
 	// Remove metadata that are defined in upload session
-	err = fc.RemoveMetadata(ctx, filePrivate.Model, MetadataUploadSessionID, ThumbDisabledKey)
+	err = fc.RemoveMetadata(ctx, filePrivate.Model, MetadataUploadSessionID, ThumbDisabledKey, ThumbAnimatedDisabledKey)
 	if err != nil {
 		_ = inventory.Rollback(tx)
 		return nil, serializer.NewError(serializer.CodeDBError, "Failed to update placeholder metadata", err)
@@ -314,13 +320,15 @@ func (f *DBFS) CompleteUpload(ctx context.Context, session *fs.UploadSession) (f
 
 	if entityType == types.EntityTypeVersion {
 		// If updating version entity, we need to cap all existing thumbnail entity to let it re-generate.
-		diff, err = fc.CapEntities(ctx, filePrivate.Model, owner, 0, types.EntityTypeThumbnail)
-		if err != nil {
-			_ = inventory.Rollback(tx)
-			return nil, serializer.NewError(serializer.CodeDBError, "Failed to cap thumbnail entities", err)
-		}
+		for _, thumbType := range []types.EntityType{types.EntityTypeThumbnail, types.EntityTypeAnimatedThumbnail} {
+			diff, err = fc.CapEntities(ctx, filePrivate.Model, owner, 0, thumbType)
+			if err != nil {
+				_ = inventory.Rollback(tx)
+				return nil, serializer.NewError(serializer.CodeDBError, "Failed to cap thumbnail entities", err)
+			}
 
-		tx.AppendStorageDiff(diff)
+			tx.AppendStorageDiff(diff)
+		}
 	}
 
 	if err := inventory.CommitWithStorageDiff(ctx, tx, f.l, f.userClient); err != nil {
@@ -392,7 +400,7 @@ func (f *DBFS) CancelUploadSession(ctx context.Context, path *fs.URI, sessionID
 	}
 
 	// Remove upload session metadata
-	if err := f.fileClient.RemoveMetadata(ctx, filePrivate.Model, MetadataUploadSessionID, ThumbDisabledKey); err != nil {
+	if err := f.fileClient.RemoveMetadata(ctx, filePrivate.Model, MetadataUploadSessionID, ThumbDisabledKey, ThumbAnimatedDisabledKey); err != nil {
 		return nil, serializer.NewError(serializer.CodeDBError, "Failed to remove upload session metadata", err)
 	}
 
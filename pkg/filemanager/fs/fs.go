@@ -42,6 +42,7 @@ var (
 	ErrStaleVersion         = serializer.NewError(serializer.CodeStaleVersion, "File is updated during your edit", nil)
 	ErrOwnerOnly            = serializer.NewError(serializer.CodeOwnerOnly, "Only owner or administrator can perform this action", nil)
 	ErrArchiveSrcSizeTooBig = ErrFileSizeTooBig.WithError(fmt.Errorf("total size of to-be compressed file exceed group limit (%w)", queue.CriticalErr))
+	ErrArchiveSuspicious    = serializer.NewError(serializer.CodeSuspiciousArchive, "Archive is suspicious", nil)
 )
 
 type (
@@ -66,6 +67,9 @@ type (
 		SharedAddressTranslation(ctx context.Context, path *URI, opts ...Option) (File, *URI, error)
 		// ExecuteNavigatorHooks executes hooks of given type on a file for navigator based custom hooks.
 		ExecuteNavigatorHooks(ctx context.Context, hookType HookType, file File) error
+		// LinkThumbnail links an existing thumbnail entity to the file at given path, replacing
+		// its current thumbnails.
+		LinkThumbnail(ctx context.Context, path *URI, entity Entity) (Entity, error)
 	}
 
 	FileManager interface {
@@ -125,6 +129,10 @@ type (
 		Unlock(ctx context.Context, tokens ...string) error
 		// Refresh refreshes a lock.
 		Refresh(ctx context.Context, d time.Duration, token string) (lock.LockDetails, error)
+		// Relock atomically replaces the token of an existing lock and refreshes it.
+		Relock(ctx context.Context, d time.Duration, oldToken, newToken string) (lock.LockDetails, error)
+		// PeekLock returns the lock currently on given URI without acquiring it, nil if not locked.
+		PeekLock(ctx context.Context, ancestor File, uri *URI) *lock.ConflictDetail
 	}
 
 	StatelessUploadManager interface {
@@ -182,6 +190,8 @@ type (
 		PolicyID() int
 		UploadSessionID() *uuid.UUID
 		CreatedBy() *ent.User
+		// Checksums returns content checksums keyed by hash algorithm.
+		Checksums() map[string]string
 		Model() *ent.Entity
 	}
 
@@ -290,6 +300,9 @@ type (
 		// with a default version entity. This will be set in update request for existing files.
 		EntityType *types.EntityType
 		ExpireAt   time.Time
+		// ExpectedHash is the ETag of uploaded object expected by client, for multipart uploads it is
+		// in the form of "<md5 of concatenated part md5s>-<part count>". Empty if not supplied.
+		ExpectedHash string
 	}
 
 	// FsOption options for underlying file system.
@@ -312,8 +325,28 @@ type (
 		SysSkipSoftDelete  bool
 		Metadata           map[string]string
 		ArchiveCompression bool
+		// ArchiveCompressionLevel overrides ArchiveCompression if set.
+		ArchiveCompressionLevel *int
+		ArchiveFormat           string
+		// ArchiveAllVersions packages every retained version of files instead of the current one.
+		ArchiveAllVersions bool
 		ProgressFunc
-		MaxArchiveSize  int64
+		ArchiveEntryProgressFunc
+		ArchiveQueueFunc
+		MaxArchiveSize int64
+		// MaxArchiveEntrySize limits size of each file extracted from archive, 0 for unlimited.
+		MaxArchiveEntrySize int64
+		// ArchiveEncoding is the encoding used to decode non-UTF8 entry names of extracted zip archive.
+		ArchiveEncoding string
+		// ArchiveRollbackOnCancel removes files extracted so far if archive extraction is canceled.
+		ArchiveRollbackOnCancel bool
+		// ArchiveReader is read instead of entity source of extracted archive, e.g. a local copy.
+		ArchiveReader io.ReaderAt
+		// ArchivePassword is used to decrypt extracted 7z archive.
+		ArchivePassword string
+		ArchiveExtractProgressFunc
+		ArchiveEntryFilterFunc
+		ArchiveEntryExtractedFunc
 		DryRun          CreateArchiveDryRunFunc
 		Policy          *ent.StoragePolicy
 		Node            StatelessUploadManager
@@ -348,6 +381,26 @@ type (
 
 	CreateArchiveDryRunFunc func(name string, e Entity)
 
+	// ArchiveEntryProgressFunc is invoked before each file is compressed into archive, with the display
+	// name of the file and its 1-based index among all to-be-compressed files.
+	ArchiveEntryProgressFunc func(name string, index, total int)
+
+	// ArchiveExtractProgressFunc is invoked as archive extraction proceeds, with number of extracted
+	// files and bytes alongside totals of the archive. Folders are not counted.
+	ArchiveExtractProgressFunc func(filesDone, filesTotal int, bytesDone, bytesTotal int64)
+
+	// ArchiveEntryFilterFunc decides whether an entry in archive is extracted, by its slash separated
+	// name. Skipped entries are neither validated nor counted in progress.
+	ArchiveEntryFilterFunc func(name string) bool
+
+	// ArchiveEntryExtractedFunc is invoked after an entry in archive is extracted.
+	ArchiveEntryExtractedFunc func(name string)
+
+	// ArchiveQueueFunc is invoked when archive creation is waiting for a free slot, with the 1-based
+	// position in the waiting queue. Position 0 means the slot is acquired. Only archive creation
+	// with this option set is subject to archive concurrency limits.
+	ArchiveQueueFunc func(position int)
+
 	StatelessPrepareUploadService struct {
 		UploadRequest *UploadRequest `json:"upload_request" binding:"required"`
 		UserID        int            `json:"user_id"`
@@ -413,6 +466,11 @@ const (
 	HookTypeBeforeDownload = HookType(iota)
 )
 
+const (
+	ArchiveFormatZip   = "zip"
+	ArchiveFormatTarGz = "tar.gz"
+)
+
 func (p *UploadProps) Copy() *UploadProps {
 	newProps := *p
 	return &newProps
@@ -571,6 +629,44 @@ func WithArchiveCompression(b bool) Option {
 	})
 }
 
+// WithArchiveCompressionLevel sets the compression level (0-9) of created archive. Level 0 means
+// files are stored without compression.
+func WithArchiveCompressionLevel(level int) Option {
+	return OptionFunc(func(o *FsOption) {
+		o.ArchiveCompressionLevel = &level
+	})
+}
+
+// WithArchiveFormat sets the container format of created archive, or format of extracted archive
+// if it cannot be told by file extension.
+func WithArchiveFormat(f string) Option {
+	return OptionFunc(func(o *FsOption) {
+		o.ArchiveFormat = f
+	})
+}
+
+// WithArchiveAllVersions sets whether to package all versions of files into archive.
+func WithArchiveAllVersions(b bool) Option {
+	return OptionFunc(func(o *FsOption) {
+		o.ArchiveAllVersions = b
+	})
+}
+
+// WithArchiveEntryProgressFunc sets per-file progress function for archive creation.
+func WithArchiveEntryProgressFunc(f ArchiveEntryProgressFunc) Option {
+	return OptionFunc(func(o *FsOption) {
+		o.ArchiveEntryProgressFunc = f
+	})
+}
+
+// WithArchiveQueueFunc sets the function to receive queue position while archive creation is waiting,
+// it also makes archive creation wait for a free slot under archive concurrency limits.
+func WithArchiveQueueFunc(f ArchiveQueueFunc) Option {
+	return OptionFunc(func(o *FsOption) {
+		o.ArchiveQueueFunc = f
+	})
+}
+
 // WithMaxArchiveSize sets maximum size of to be archived file or to-be decompressed
 // size, 0 for unlimited.
 func WithMaxArchiveSize(s int64) Option {
@@ -579,6 +675,64 @@ func WithMaxArchiveSize(s int64) Option {
 	})
 }
 
+// WithMaxArchiveEntrySize sets maximum size of each file extracted from archive, 0 for unlimited.
+func WithMaxArchiveEntrySize(s int64) Option {
+	return OptionFunc(func(o *FsOption) {
+		o.MaxArchiveEntrySize = s
+	})
+}
+
+// WithArchiveEncoding sets encoding of non-UTF8 entry names in extracted zip archive. Encoding is
+// detected automatically if not set.
+func WithArchiveEncoding(enc string) Option {
+	return OptionFunc(func(o *FsOption) {
+		o.ArchiveEncoding = enc
+	})
+}
+
+// WithArchiveRollbackOnCancel sets whether to remove files extracted so far if archive extraction
+// is canceled, otherwise partial results are left in destination folder.
+func WithArchiveRollbackOnCancel(b bool) Option {
+	return OptionFunc(func(o *FsOption) {
+		o.ArchiveRollbackOnCancel = b
+	})
+}
+
+// WithArchiveExtractProgressFunc sets progress function for archive extraction.
+func WithArchiveExtractProgressFunc(f ArchiveExtractProgressFunc) Option {
+	return OptionFunc(func(o *FsOption) {
+		o.ArchiveExtractProgressFunc = f
+	})
+}
+
+// WithArchiveReader sets reader of extracted archive, used instead of the archive's entity source.
+func WithArchiveReader(r io.ReaderAt) Option {
+	return OptionFunc(func(o *FsOption) {
+		o.ArchiveReader = r
+	})
+}
+
+// WithArchivePassword sets password of extracted 7z archive.
+func WithArchivePassword(password string) Option {
+	return OptionFunc(func(o *FsOption) {
+		o.ArchivePassword = password
+	})
+}
+
+// WithArchiveEntryFilterFunc sets function to select entries to be extracted from archive.
+func WithArchiveEntryFilterFunc(f ArchiveEntryFilterFunc) Option {
+	return OptionFunc(func(o *FsOption) {
+		o.ArchiveEntryFilterFunc = f
+	})
+}
+
+// WithArchiveEntryExtractedFunc sets function invoked after each entry is extracted from archive.
+func WithArchiveEntryExtractedFunc(f ArchiveEntryExtractedFunc) Option {
+	return OptionFunc(func(o *FsOption) {
+		o.ArchiveEntryExtractedFunc = f
+	})
+}
+
 // WithDryRun sets whether to perform dry run.
 func WithDryRun(b CreateArchiveDryRunFunc) Option {
 	return OptionFunc(func(o *FsOption) {
@@ -778,6 +932,10 @@ func (e *DbEntity) UploadSessionID() *uuid.UUID {
 	return e.model.UploadSessionID
 }
 
+func (e *DbEntity) Checksums() map[string]string {
+	return e.model.Checksums
+}
+
 func (e *DbEntity) Model() *ent.Entity {
 	return e.model
 }
@@ -26,6 +26,7 @@ const (
 	QuerySearchUseOr          = "use_or"
 	QuerySearchMetadataPrefix = "meta_"
 	QuerySearchMetadataExact  = "exact_meta_"
+	QuerySearchPropsPrefix    = inventory.CustomPropsMetadataPrefix
 	QuerySearchCaseFolding    = "case_folding"
 	QuerySearchType           = "type"
 	QuerySearchTypeCategory   = "category"
@@ -266,6 +267,12 @@ func (u *URI) SearchParameters() *inventory.SearchFileParameters {
 				Exact: true,
 			})
 			withSearch = true
+		} else if strings.HasPrefix(k, QuerySearchPropsPrefix) {
+			res.Props = append(res.Props, inventory.PropsFilter{
+				ID:    strings.TrimPrefix(k, QuerySearchPropsPrefix),
+				Value: v[0],
+			})
+			withSearch = true
 		}
 	}
 
@@ -0,0 +1,176 @@
+package limiter
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
+)
+
+// ErrLimiterClosed is returned by Acquire once the limiter is closed.
+var ErrLimiterClosed = errors.New("archive limiter is closed")
+
+type (
+	// ArchiveLimiter limits concurrent archive creation shared by all file managers.
+	ArchiveLimiter interface {
+		// Acquire blocks until a slot for given user is available or ctx is canceled. onQueued, if not nil,
+		// receives the queue position every time it changes. The returned function must be called to
+		// release the slot.
+		Acquire(ctx context.Context, userID int, limits *setting.ArchiveConcurrency, onQueued func(position int)) (func(), error)
+		// Close rejects all pending and future Acquire calls with ErrLimiterClosed. Slots already
+		// granted are not affected.
+		Close()
+	}
+
+	// archiveLimiter is a FIFO semaphore with both global and per-user capacity. Waiters blocked
+	// only by their own user's capacity do not block waiters of other users.
+	archiveLimiter struct {
+		mu      sync.Mutex
+		limits  setting.ArchiveConcurrency
+		running int
+		perUser map[int]int
+		waiters []*archiveWaiter
+		closed  chan struct{}
+	}
+
+	archiveWaiter struct {
+		userID   int
+		ready    chan struct{}
+		position int
+		onQueued func(position int)
+	}
+)
+
+// NewArchiveLimiter creates a new ArchiveLimiter with no slot taken.
+func NewArchiveLimiter() ArchiveLimiter {
+	return newArchiveLimiter()
+}
+
+func newArchiveLimiter() *archiveLimiter {
+	return &archiveLimiter{
+		perUser: make(map[int]int),
+		closed:  make(chan struct{}),
+	}
+}
+
+func (l *archiveLimiter) Close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	select {
+	case <-l.closed:
+	default:
+		close(l.closed)
+	}
+}
+
+func (l *archiveLimiter) Acquire(ctx context.Context, userID int, limits *setting.ArchiveConcurrency,
+	onQueued func(position int)) (func(), error) {
+	w := &archiveWaiter{
+		userID:   userID,
+		ready:    make(chan struct{}),
+		onQueued: onQueued,
+	}
+
+	l.mu.Lock()
+	select {
+	case <-l.closed:
+		l.mu.Unlock()
+		return nil, ErrLimiterClosed
+	default:
+	}
+
+	l.limits = *limits
+	l.waiters = append(l.waiters, w)
+	notify := l.dispatch()
+	l.mu.Unlock()
+	notify()
+
+	var err error
+	select {
+	case <-w.ready:
+		return l.releaseFunc(userID), nil
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-l.closed:
+		err = ErrLimiterClosed
+	}
+
+	l.mu.Lock()
+	select {
+	case <-w.ready:
+		// Slot granted while we are canceling, give it back.
+		l.mu.Unlock()
+		l.releaseFunc(userID)()
+	default:
+		for i, waiter := range l.waiters {
+			if waiter == w {
+				l.waiters = append(l.waiters[:i], l.waiters[i+1:]...)
+				break
+			}
+		}
+		notify = l.dispatch()
+		l.mu.Unlock()
+		notify()
+	}
+
+	return nil, err
+}
+
+func (l *archiveLimiter) releaseFunc(userID int) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			l.running--
+			l.perUser[userID]--
+			if l.perUser[userID] <= 0 {
+				delete(l.perUser, userID)
+			}
+			notify := l.dispatch()
+			l.mu.Unlock()
+			notify()
+		})
+	}
+}
+
+// dispatch grants slots to eligible waiters in FIFO order, must be called with lock held.
+// The returned function notifies position changes and must be called without lock held.
+func (l *archiveLimiter) dispatch() func() {
+	type change struct {
+		f        func(position int)
+		position int
+	}
+	var changed []change
+
+	remaining := l.waiters[:0]
+	for _, w := range l.waiters {
+		if (l.limits.Global <= 0 || l.running < l.limits.Global) &&
+			(l.limits.PerUser <= 0 || l.perUser[w.userID] < l.limits.PerUser) {
+			l.running++
+			l.perUser[w.userID]++
+			close(w.ready)
+			if w.position > 0 && w.onQueued != nil {
+				changed = append(changed, change{w.onQueued, 0})
+			}
+			continue
+		}
+
+		remaining = append(remaining, w)
+	}
+	l.waiters = remaining
+
+	for i, w := range l.waiters {
+		if w.position != i+1 && w.onQueued != nil {
+			changed = append(changed, change{w.onQueued, i + 1})
+		}
+		w.position = i + 1
+	}
+
+	return func() {
+		for _, c := range changed {
+			c.f(c.position)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package limiter
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
+	"github.com/stretchr/testify/assert"
+)
+
+type positionRecorder struct {
+	mu        sync.Mutex
+	positions []int
+}
+
+func (r *positionRecorder) record(position int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.positions = append(r.positions, position)
+}
+
+func (r *positionRecorder) get() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]int{}, r.positions...)
+}
+
+func acquireAsync(l *archiveLimiter, ctx context.Context, userID int, limits *setting.ArchiveConcurrency,
+	onQueued func(int)) (chan func(), chan error) {
+	released := make(chan func(), 1)
+	errs := make(chan error, 1)
+	go func() {
+		release, err := l.Acquire(ctx, userID, limits, onQueued)
+		if err != nil {
+			errs <- err
+			return
+		}
+		released <- release
+	}()
+
+	return released, errs
+}
+
+func TestArchiveLimiter_PerUserCap(t *testing.T) {
+	a := assert.New(t)
+	l := newArchiveLimiter()
+	limits := &setting.ArchiveConcurrency{Global: 10, PerUser: 2}
+	ctx := context.Background()
+
+	r1, err := l.Acquire(ctx, 1, limits, nil)
+	a.NoError(err)
+	r2, err := l.Acquire(ctx, 1, limits, nil)
+	a.NoError(err)
+
+	// User 1 hits the cap, third task is queued.
+	recorder := &positionRecorder{}
+	released, _ := acquireAsync(l, ctx, 1, limits, recorder.record)
+	a.Eventually(func() bool { return len(recorder.get()) == 1 }, time.Second, time.Millisecond)
+	a.Equal([]int{1}, recorder.get())
+
+	// Other users are not blocked by user 1.
+	r3, err := l.Acquire(ctx, 2, limits, nil)
+	a.NoError(err)
+
+	// Releasing one of user 1's slots lets the queued task run.
+	r1()
+	select {
+	case r := <-released:
+		r()
+	case <-time.After(time.Second):
+		a.Fail("queued task is not released")
+	}
+	a.Equal([]int{1, 0}, recorder.get())
+
+	// Release is idempotent.
+	r1()
+	r2()
+	r3()
+	a.Equal(0, l.running)
+	a.Empty(l.perUser)
+}
+
+func TestArchiveLimiter_GlobalCapAndCancel(t *testing.T) {
+	a := assert.New(t)
+	l := newArchiveLimiter()
+	limits := &setting.ArchiveConcurrency{Global: 1}
+
+	r1, err := l.Acquire(context.Background(), 1, limits, nil)
+	a.NoError(err)
+
+	cancelCtx, cancel := context.WithCancel(context.Background())
+	first := &positionRecorder{}
+	_, errs := acquireAsync(l, cancelCtx, 2, limits, first.record)
+	a.Eventually(func() bool { return len(first.get()) == 1 }, time.Second, time.Millisecond)
+
+	second := &positionRecorder{}
+	released, _ := acquireAsync(l, context.Background(), 3, limits, second.record)
+	a.Eventually(func() bool { return len(second.get()) == 1 }, time.Second, time.Millisecond)
+	a.Equal([]int{2}, second.get())
+
+	// Canceling a queued task moves the following ones forward.
+	cancel()
+	a.ErrorIs(<-errs, context.Canceled)
+	a.Eventually(func() bool { return len(second.get()) == 2 }, time.Second, time.Millisecond)
+	a.Equal([]int{2, 1}, second.get())
+
+	r1()
+	select {
+	case r := <-released:
+		r()
+	case <-time.After(time.Second):
+		a.Fail("queued task is not released")
+	}
+	a.Equal(0, l.running)
+	a.Empty(l.waiters)
+}
+
+func TestArchiveLimiter_Close(t *testing.T) {
+	a := assert.New(t)
+	l := newArchiveLimiter()
+	limits := &setting.ArchiveConcurrency{Global: 1}
+
+	r1, err := l.Acquire(context.Background(), 1, limits, nil)
+	a.NoError(err)
+
+	_, errs := acquireAsync(l, context.Background(), 2, limits, nil)
+	a.Eventually(func() bool {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		return len(l.waiters) == 1
+	}, time.Second, time.Millisecond)
+
+	// Pending and future waiters are rejected, granted slot can still be released.
+	l.Close()
+	a.ErrorIs(<-errs, ErrLimiterClosed)
+	_, err = l.Acquire(context.Background(), 3, limits, nil)
+	a.ErrorIs(err, ErrLimiterClosed)
+	r1()
+	a.Equal(0, l.running)
+	a.Empty(l.waiters)
+	l.Close()
+}
@@ -30,6 +30,12 @@ type LockSystem interface {
 	Unlock(now time.Time, tokens ...string) error
 	Confirm(now time.Time, requests LockInfo) (func(), string, error)
 	Refresh(now time.Time, duration time.Duration, token string) (LockDetails, error)
+	// Relock atomically replaces the token of lock identified by oldToken with newToken, and resets
+	// its duration. Lock state is not changed if any error is returned.
+	Relock(now time.Time, duration time.Duration, oldToken, newToken string) (LockDetails, error)
+	// Peek returns the lock covering given resource without changing lock state, nil is returned
+	// if the resource is not locked.
+	Peek(now time.Time, ns, root string) *ConflictDetail
 }
 
 // LockDetails are a lock's metadata.
@@ -139,6 +145,72 @@ func (m *memLS) Refresh(now time.Time, duration time.Duration, token string) (Lo
 	return n.details, nil
 }
 
+func (m *memLS) Relock(now time.Time, duration time.Duration, oldToken, newToken string) (LockDetails, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.collectExpiredNodes(now)
+
+	m.l.Debug("Memlock relock: Old token: %s, New token: %s, Duration: %v", oldToken, newToken, duration)
+	n := m.byToken[oldToken]
+	if n == nil {
+		return LockDetails{}, ErrNoSuchLock
+	}
+	if n.held {
+		return LockDetails{}, ErrLocked
+	}
+	if existing, ok := m.byToken[newToken]; newToken == "" || (ok && existing != n) {
+		return LockDetails{}, ErrLocked
+	}
+
+	// Lock of the same resource is removed first, so that creating the new one cannot conflict.
+	details := n.details
+	details.Token = newToken
+	details.Duration = duration
+	m.remove(n)
+
+	n = m.create(details.Ns, details.Root, newToken)
+	m.byToken[n.token] = n
+	n.details = details
+	if n.details.Duration >= 0 {
+		n.expiry = now.Add(n.details.Duration)
+		heap.Push(&m.byExpiry, n)
+	}
+
+	return n.details, nil
+}
+
+func (m *memLS) Peek(now time.Time, ns, root string) *ConflictDetail {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.collectExpiredNodes(now)
+
+	if m.byName[ns] == nil {
+		return nil
+	}
+
+	var found *memLSNode
+	walkToRoot(util.SlashClean(root), func(name0 string, first bool) bool {
+		n := m.byName[ns][name0]
+		if n == nil || n.token == "" {
+			return true
+		}
+
+		// Locks on ancestors only cover the resource with infinite depth.
+		if first || !n.details.ZeroDepth {
+			found = n
+			return false
+		}
+
+		return true
+	})
+
+	if found == nil {
+		return nil
+	}
+
+	return found.toConflictDetail(0, m.hasher)
+}
+
 func (m *memLS) Create(now time.Time, details ...LockDetails) ([]string, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -1 +1,111 @@
 package lock
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/stretchr/testify/assert"
+)
+
+// WOPI clients lock files with their own token for 30 minutes.
+func TestMemLS_CustomTokenMismatchAndExpiry(t *testing.T) {
+	a := assert.New(t)
+	ls := NewMemLS(nil, logging.NewConsoleLogger(logging.LevelError))
+	now := time.Now()
+	details := LockDetails{Ns: "my", Root: "/doc.docx", Duration: 30 * time.Minute, ZeroDepth: true}
+
+	details.Token = "editor-a"
+	tokens, err := ls.Create(now, details)
+	a.NoError(err)
+	a.Equal([]string{"editor-a"}, tokens)
+
+	// Mismatched token cannot confirm
+	_, _, err = ls.Confirm(now, LockInfo{Ns: "my", Root: "/doc.docx", Token: []string{"editor-b"}})
+	a.ErrorIs(err, ErrConfirmationFailed)
+
+	// Conflict exposes current token
+	details.Token = "editor-b"
+	_, err = ls.Create(now.Add(time.Minute), details)
+	var conflict ConflictError
+	if a.True(errors.As(err, &conflict)) && a.Len(conflict, 1) {
+		a.Equal("editor-a", conflict[0].Token)
+	}
+
+	// Refresh extends expiry
+	_, err = ls.Refresh(now.Add(20*time.Minute), 30*time.Minute, "editor-a")
+	a.NoError(err)
+	_, err = ls.Create(now.Add(40*time.Minute), details)
+	a.Error(err)
+
+	// Expired lock is released
+	release, token, err := ls.Confirm(now.Add(49*time.Minute), LockInfo{Ns: "my", Root: "/doc.docx", Token: []string{"editor-a"}})
+	a.NoError(err)
+	a.Equal("editor-a", token)
+	release()
+	_, _, err = ls.Confirm(now.Add(51*time.Minute), LockInfo{Ns: "my", Root: "/doc.docx", Token: []string{"editor-a"}})
+	a.ErrorIs(err, ErrConfirmationFailed)
+	a.ErrorIs(ls.Unlock(now.Add(51*time.Minute), "editor-a"), ErrNoSuchLock)
+
+	tokens, err = ls.Create(now.Add(51*time.Minute), details)
+	a.NoError(err)
+	a.Equal([]string{"editor-b"}, tokens)
+}
+
+func TestMemLS_Relock(t *testing.T) {
+	a := assert.New(t)
+	ls := NewMemLS(nil, logging.NewConsoleLogger(logging.LevelError))
+	now := time.Now()
+	details := LockDetails{Ns: "my", Root: "/doc.docx", Duration: time.Minute, ZeroDepth: true, Token: "old"}
+	_, err := ls.Create(now, details)
+	a.NoError(err)
+	details.Root, details.Token = "/other.docx", "other"
+	_, err = ls.Create(now, details)
+	a.NoError(err)
+
+	// Token used by another lock, old lock is kept
+	_, err = ls.Relock(now, time.Minute, "old", "other")
+	a.ErrorIs(err, ErrLocked)
+	a.Equal("old", ls.Peek(now, "my", "/doc.docx").Token)
+
+	// Unknown or held lock
+	_, err = ls.Relock(now, time.Minute, "not_exist", "new")
+	a.ErrorIs(err, ErrNoSuchLock)
+	release, _, err := ls.Confirm(now, LockInfo{Ns: "my", Root: "/doc.docx", Token: []string{"old"}})
+	a.NoError(err)
+	_, err = ls.Relock(now, time.Minute, "old", "new")
+	a.ErrorIs(err, ErrLocked)
+	release()
+
+	// Token is replaced and duration is reset
+	ld, err := ls.Relock(now.Add(50*time.Second), time.Minute, "old", "new")
+	a.NoError(err)
+	a.Equal("/doc.docx", ld.Root)
+	a.ErrorIs(ls.Unlock(now, "old"), ErrNoSuchLock)
+	a.Equal("new", ls.Peek(now.Add(100*time.Second), "my", "/doc.docx").Token)
+	a.Nil(ls.Peek(now.Add(111*time.Second), "my", "/doc.docx"))
+}
+
+func TestMemLS_Peek(t *testing.T) {
+	a := assert.New(t)
+	ls := NewMemLS(nil, logging.NewConsoleLogger(logging.LevelError))
+	now := time.Now()
+	a.Nil(ls.Peek(now, "my", "/a/doc.docx"))
+
+	// Zero depth lock on ancestor does not cover descendants
+	_, err := ls.Create(now, LockDetails{Ns: "my", Root: "/a", Duration: time.Minute, ZeroDepth: true, Token: "folder"})
+	a.NoError(err)
+	a.Nil(ls.Peek(now, "my", "/a/doc.docx"))
+	a.Equal("folder", ls.Peek(now, "my", "/a").Token)
+
+	// Infinite depth lock on ancestor covers descendants
+	_, err = ls.Create(now, LockDetails{Ns: "shared", Root: "/", Duration: time.Minute, Token: "root"})
+	a.NoError(err)
+	a.Equal("root", ls.Peek(now, "shared", "/a/doc.docx").Token)
+	a.Nil(ls.Peek(now, "trash", "/a/doc.docx"))
+
+	// Peeking does not change lock state
+	a.NoError(ls.Unlock(now, "folder", "root"))
+	a.Nil(ls.Peek(now, "my", "/a"))
+}
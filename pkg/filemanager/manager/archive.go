@@ -1,13 +1,18 @@
 package manager
 
 import (
+	"archive/tar"
 	"archive/zip"
+	"compress/flate"
+	"compress/gzip"
 	"context"
 	"encoding/gob"
 	"fmt"
 	"io"
 	"path"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 	"time"
 
@@ -17,6 +22,8 @@ import (
 	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/fs/dbfs"
 	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/manager/entitysource"
 	"github.com/cloudreve/Cloudreve/v4/pkg/util"
+	"github.com/saintfish/chardet"
+	"github.com/samber/lo"
 	"golang.org/x/text/encoding"
 	"golang.org/x/text/encoding/charmap"
 	"golang.org/x/text/encoding/japanese"
@@ -33,13 +40,48 @@ type (
 		Size        int64      `json:"size"`
 		UpdatedAt   *time.Time `json:"updated_at"`
 		IsDirectory bool       `json:"is_directory"`
+		// CompressedSize is the size of entry in archive, 0 if unknown (e.g. files in solid 7z archive).
+		CompressedSize int64 `json:"compressed_size,omitempty"`
+		// Unsafe is set for entries that would escape the destination folder if extracted, e.g.
+		// names with "../" or absolute paths. They are kept in listing for display only.
+		Unsafe bool `json:"unsafe,omitempty"`
+		// Suspicious is set for entries declaring implausibly large size compared to their compressed
+		// size, archives with such entries are rejected on extraction. Only zip entries are checked,
+		// packed size of 7z entries is not exposed by the reader, they are bound by size limits only.
+		Suspicious bool `json:"suspicious,omitempty"`
+	}
+
+	// archivedFileReader is a file in archive that can be opened for extraction.
+	archivedFileReader struct {
+		ArchivedFile
+		open func() (io.ReadCloser, error)
+	}
+
+	// extractStream reads content of a file in archive. Reading stops at size declared in archive
+	// header, or once ctx is canceled.
+	extractStream struct {
+		io.Closer
+		ctx    context.Context
+		r      io.Reader
+		onRead func(n int64)
 	}
 )
 
 const (
 	ArchiveListCacheTTL = 3600 // 1 hour
+	// archiveEncodingMinConfidence is the minimum confidence (0-100) of detected
+	// charset to be used for decoding non-UTF8 entry names.
+	archiveEncodingMinConfidence = 50
+	// archiveEncodingSampleSize is the maximum bytes of entry names sampled for detection.
+	archiveEncodingSampleSize = 4096
+	// maxArchiveCompressionRatio is the highest plausible ratio of declared uncompressed size to
+	// compressed size. Deflate cannot exceed ~1032:1, stored or deflated zip entries beyond it are
+	// considered zip bombs.
+	maxArchiveCompressionRatio = 1100
 )
 
+var windowsDrivePattern = regexp.MustCompile(`^[a-zA-Z]:(/|$)`)
+
 func init() {
 	gob.Register([]ArchivedFile{})
 }
@@ -84,47 +126,58 @@ var ZipEncodings = map[string]encoding.Encoding{
 	"utf16le":           unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM),
 }
 
-func (m *manager) ListArchiveFiles(ctx context.Context, uri *fs.URI, entity, zipEncoding string) ([]ArchivedFile, error) {
+func (m *manager) ListArchiveFiles(ctx context.Context, uri *fs.URI, entity, zipEncoding string) ([]ArchivedFile, string, error) {
 	file, err := m.fs.Get(ctx, uri, dbfs.WithFileEntities(), dbfs.WithRequiredCapabilities(dbfs.NavigatorCapabilityDownloadFile))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get file: %w", err)
+		return nil, "", fmt.Errorf("failed to get file: %w", err)
 	}
 
 	if file.Type() != types.FileTypeFile {
-		return nil, fs.ErrNotSupportedAction.WithError(fmt.Errorf("path %s is not a file", uri))
+		return nil, "", fs.ErrNotSupportedAction.WithError(fmt.Errorf("path %s is not a file", uri))
 	}
 
 	// Validate file size
 	if m.user.Edges.Group.Settings.DecompressSize > 0 && file.Size() > m.user.Edges.Group.Settings.DecompressSize {
-		return nil, fs.ErrFileSizeTooBig.WithError(fmt.Errorf("file size %d exceeds the limit %d", file.Size(), m.user.Edges.Group.Settings.DecompressSize))
+		return nil, "", fs.ErrFileSizeTooBig.WithError(fmt.Errorf("file size %d exceeds the limit %d", file.Size(), m.user.Edges.Group.Settings.DecompressSize))
 	}
 
 	found, targetEntity := fs.FindDesiredEntity(file, entity, m.hasher, nil)
 	if !found {
-		return nil, fs.ErrEntityNotExist
+		return nil, "", fs.ErrEntityNotExist
 	}
 
 	var (
 		enc encoding.Encoding
 		ok  bool
 	)
+	zipEncoding = strings.ToLower(zipEncoding)
 	if zipEncoding != "" {
-		enc, ok = ZipEncodings[strings.ToLower(zipEncoding)]
+		enc, ok = ZipEncodings[zipEncoding]
 		if !ok {
-			return nil, fs.ErrNotSupportedAction.WithError(fmt.Errorf("not supported zip encoding: %s", zipEncoding))
+			return nil, "", fs.ErrNotSupportedAction.WithError(fmt.Errorf("not supported zip encoding: %s", zipEncoding))
 		}
 	}
 
-	cacheKey := getArchiveListCacheKey(targetEntity.ID(), zipEncoding)
 	kv := m.kv
-	res, found := kv.Get(cacheKey)
-	if found {
-		return res.([]ArchivedFile), nil
+	// Encoding is not specified for zip file, try to use previously detected one.
+	detect := zipEncoding == "" && file.Ext() == "zip"
+	if detect {
+		if detected, found := kv.Get(getArchiveEncodingCacheKey(targetEntity.ID())); found {
+			detect = false
+			zipEncoding = detected.(string)
+			enc = ZipEncodings[zipEncoding]
+		}
+	}
+
+	if !detect {
+		if res, found := kv.Get(getArchiveListCacheKey(targetEntity.ID(), zipEncoding)); found {
+			return res.([]ArchivedFile), zipEncoding, nil
+		}
 	}
 
 	es, err := m.GetEntitySource(ctx, 0, fs.WithEntity(targetEntity))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get entity source: %w", err)
+		return nil, "", fmt.Errorf("failed to get entity source: %w", err)
 	}
 
 	es.Apply(entitysource.WithContext(ctx))
@@ -137,17 +190,273 @@ func (m *manager) ListArchiveFiles(ctx context.Context, uri *fs.URI, entity, zip
 	case "7z":
 		readerFunc = get7zFileList
 	default:
-		return nil, fs.ErrNotSupportedAction.WithError(fmt.Errorf("not supported archive format: %s", file.Ext()))
+		return nil, "", fs.ErrNotSupportedAction.WithError(fmt.Errorf("not supported archive format: %s", file.Ext()))
 	}
 
+	var fileList []ArchivedFile
 	sr := io.NewSectionReader(es, 0, targetEntity.Size())
-	fileList, err := readerFunc(ctx, sr, targetEntity.Size(), enc)
+	if detect {
+		var files []archivedFileReader
+		files, zipEncoding, err = openDetectedZipFiles(sr, targetEntity.Size())
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read file list: %w", err)
+		}
+
+		m.l.Debug("Detected zip encoding %q for entity %d", zipEncoding, targetEntity.ID())
+		kv.Set(getArchiveEncodingCacheKey(targetEntity.ID()), zipEncoding, ArchiveListCacheTTL)
+		fileList = archivedFiles(files)
+	} else {
+		fileList, err = readerFunc(ctx, sr, targetEntity.Size(), enc)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read file list: %w", err)
+		}
+	}
+
+	kv.Set(getArchiveListCacheKey(targetEntity.ID(), zipEncoding), fileList, ArchiveListCacheTTL)
+	return fileList, zipEncoding, nil
+}
+
+func (m *manager) ExtractArchive(ctx context.Context, archiveURI, dst *fs.URI, opts ...fs.Option) error {
+	o := newOption()
+	for _, opt := range opts {
+		opt.Apply(o)
+	}
+
+	file, err := m.fs.Get(ctx, archiveURI, dbfs.WithFileEntities(), dbfs.WithRequiredCapabilities(dbfs.NavigatorCapabilityDownloadFile), dbfs.WithNotRoot())
+	if err != nil {
+		return fmt.Errorf("failed to get archive file: %w", err)
+	}
+
+	if file.Type() != types.FileTypeFile {
+		return fs.ErrNotSupportedAction.WithError(fmt.Errorf("path %s is not a file", archiveURI))
+	}
+
+	decompressLimit := m.user.Edges.Group.Settings.DecompressSize
+	if decompressLimit > 0 && file.Size() > decompressLimit {
+		return fs.ErrFileSizeTooBig.WithError(fmt.Errorf("file size %d exceeds the limit %d", file.Size(), decompressLimit))
+	}
+
+	format := file.Ext()
+	if o.ArchiveFormat != "" {
+		format = o.ArchiveFormat
+	}
+
+	if format != "zip" && format != "7z" {
+		return fs.ErrNotSupportedAction.WithError(fmt.Errorf("not supported archive format: %s", format))
+	}
+
+	var enc encoding.Encoding
+	if o.ArchiveEncoding != "" {
+		var ok bool
+		enc, ok = ZipEncodings[strings.ToLower(o.ArchiveEncoding)]
+		if !ok {
+			return fs.ErrNotSupportedAction.WithError(fmt.Errorf("not supported zip encoding: %s", o.ArchiveEncoding))
+		}
+	}
+
+	found, targetEntity := fs.FindDesiredEntity(file, "", m.hasher, nil)
+	if !found {
+		return fs.ErrEntityNotExist
+	}
+
+	archiveReader := o.ArchiveReader
+	if archiveReader == nil {
+		es, err := m.GetEntitySource(ctx, 0, fs.WithEntity(targetEntity))
+		if err != nil {
+			return fmt.Errorf("failed to get entity source: %w", err)
+		}
+
+		es.Apply(entitysource.WithContext(ctx))
+		defer es.Close()
+		archiveReader = es
+	}
+
+	var files []archivedFileReader
+	sr := io.NewSectionReader(archiveReader, 0, targetEntity.Size())
+	switch {
+	case format == "7z":
+		files, err = open7zFiles(sr, targetEntity.Size(), o.ArchivePassword)
+	case enc == nil:
+		files, _, err = openDetectedZipFiles(sr, targetEntity.Size())
+	default:
+		files, err = openZipFiles(sr, targetEntity.Size(), enc)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to read file list: %w", err)
+		return fmt.Errorf("failed to read archive: %w", err)
+	}
+
+	if o.ArchiveEntryFilterFunc != nil {
+		files = lo.Filter(files, func(f archivedFileReader, _ int) bool {
+			return o.ArchiveEntryFilterFunc(f.Name)
+		})
+	}
+
+	// Validate all entries before extracting anything so that a malicious archive leaves nothing behind.
+	if err := validateArchivedFiles(files, decompressLimit, o.MaxArchiveEntrySize); err != nil {
+		return err
+	}
+
+	filesTotal, bytesTotal := 0, int64(0)
+	for _, f := range files {
+		if !f.IsDirectory {
+			filesTotal++
+			bytesTotal += f.Size
+		}
+	}
+
+	var (
+		filesDone   int
+		bytesDone   int64
+		created     []string
+		createdDirs = make(map[string]bool)
+	)
+	reportProgress := func() {
+		if o.ArchiveExtractProgressFunc != nil {
+			o.ArchiveExtractProgressFunc(filesDone, filesTotal, bytesDone, bytesTotal)
+		}
 	}
 
-	kv.Set(cacheKey, fileList, ArchiveListCacheTTL)
-	return fileList, nil
+	reportProgress()
+	for _, f := range files {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+
+		if f.Name == "." {
+			continue
+		}
+
+		uri := dst.JoinRaw(f.Name)
+		if f.IsDirectory {
+			if _, err := m.Create(ctx, uri, types.FileTypeFolder); err != nil {
+				m.l.Warning("Failed to create directory %q: %s, skipping...", f.Name, err)
+			} else {
+				created = append(created, f.Name)
+				createdDirs[f.Name] = true
+			}
+
+			if o.ArchiveEntryExtractedFunc != nil {
+				o.ArchiveEntryExtractedFunc(f.Name)
+			}
+			continue
+		}
+
+		err = m.extractArchivedFile(ctx, uri, f, func(n int64) {
+			bytesDone += n
+			reportProgress()
+		})
+		if err != nil {
+			err = fmt.Errorf("failed to extract %q: %w", f.Name, err)
+			break
+		}
+
+		created = append(created, f.Name)
+		filesDone++
+		reportProgress()
+		if o.ArchiveEntryExtractedFunc != nil {
+			o.ArchiveEntryExtractedFunc(f.Name)
+		}
+	}
+
+	if err != nil && ctx.Err() != nil && o.ArchiveRollbackOnCancel {
+		m.rollbackExtraction(context.WithoutCancel(ctx), dst, created, createdDirs)
+	}
+
+	return err
+}
+
+// rollbackExtraction deletes files and folders created by a canceled extraction.
+func (m *manager) rollbackExtraction(ctx context.Context, dst *fs.URI, created []string, createdDirs map[string]bool) {
+	roots := extractionRoots(created, createdDirs)
+	if len(roots) == 0 {
+		return
+	}
+
+	uris := lo.Map(roots, func(name string, _ int) *fs.URI { return dst.JoinRaw(name) })
+	if err := m.Delete(ctx, uris, fs.WithSkipSoftDelete(true)); err != nil {
+		m.l.Warning("Failed to roll back canceled extraction in %q: %s", dst, err)
+	}
+}
+
+// isUnsafeArchivedPath reports whether a slash separated entry name in archive escapes the
+// destination folder, by either "../" elements or absolute (including Windows drive) paths.
+func isUnsafeArchivedPath(name string) bool {
+	if strings.HasPrefix(name, "/") || windowsDrivePattern.MatchString(name) {
+		return true
+	}
+
+	return lo.Contains(strings.Split(name, "/"), "..")
+}
+
+// extractionRoots returns created entries whose parent folder is not created by the extraction,
+// deleting them also removes the rest.
+func extractionRoots(created []string, createdDirs map[string]bool) []string {
+	return lo.Filter(created, func(name string, _ int) bool {
+		for parent := path.Dir(name); parent != "." && parent != "/"; parent = path.Dir(parent) {
+			if createdDirs[parent] {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// extractArchivedFile uploads a file in archive to given URI, onRead is called with size of
+// each chunk read from archive.
+func (m *manager) extractArchivedFile(ctx context.Context, uri *fs.URI, f archivedFileReader, onRead func(n int64)) error {
+	rc, err := f.open()
+	if err != nil {
+		return fmt.Errorf("failed to open file in archive: %w", err)
+	}
+	defer rc.Close()
+
+	_, err = m.Update(ctx, &fs.UploadRequest{
+		Props: &fs.UploadProps{
+			Uri:          uri,
+			Size:         f.Size,
+			LastModified: f.UpdatedAt,
+		},
+		File: &extractStream{Closer: rc, ctx: ctx, r: io.LimitReader(rc, f.Size), onRead: onRead},
+	}, fs.WithNoEntityType())
+	return err
+}
+
+func (s *extractStream) Read(p []byte) (int, error) {
+	if err := s.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	n, err := s.r.Read(p)
+	if n > 0 && s.onRead != nil {
+		s.onRead(int64(n))
+	}
+	return n, err
+}
+
+// validateArchivedFiles rejects archives with entries escaping the destination folder, suspicious
+// compression ratio, or exceeding size limits. Limit of 0 means unlimited.
+func validateArchivedFiles(files []archivedFileReader, totalLimit, entryLimit int64) error {
+	total := int64(0)
+	for _, f := range files {
+		if isUnsafeArchivedPath(f.Name) {
+			return fs.ErrIllegalObjectName.WithError(fmt.Errorf("path %q in archive is not legit", f.Name))
+		}
+
+		if f.Suspicious {
+			return fs.ErrArchiveSuspicious.WithError(fmt.Errorf("entry %q declares size %d from %d compressed bytes", f.Name, f.Size, f.CompressedSize))
+		}
+
+		if entryLimit > 0 && f.Size > entryLimit {
+			return fs.ErrFileSizeTooBig.WithError(fmt.Errorf("size of %q in archive exceeds the limit %d", f.Name, entryLimit))
+		}
+
+		total += f.Size
+		if totalLimit > 0 && total > totalLimit {
+			return fs.ErrFileSizeTooBig.WithError(fmt.Errorf("total extracted size exceeds the limit %d", totalLimit))
+		}
+	}
+
+	return nil
 }
 
 func (m *manager) CreateArchive(ctx context.Context, uris []*fs.URI, writer io.Writer, opts ...fs.Option) (int, error) {
@@ -158,6 +467,17 @@ func (m *manager) CreateArchive(ctx context.Context, uris []*fs.URI, writer io.W
 
 	failed := 0
 
+	// Only background archive tasks opt in to concurrency limits by watching the queue position.
+	// Streaming downloads are bound to the client connection and never queued; dry run only lists
+	// files, it does not consume any concurrency slot.
+	if o.DryRun == nil && o.ArchiveQueueFunc != nil {
+		release, err := m.dep.ArchiveLimiter().Acquire(ctx, m.user.ID, m.settings.ArchiveConcurrency(ctx), o.ArchiveQueueFunc)
+		if err != nil {
+			return 0, fmt.Errorf("failed to wait for archive slot: %w", err)
+		}
+		defer release()
+	}
+
 	// List all top level files
 	files := make([]fs.File, 0, len(uris))
 	for _, uri := range uris {
@@ -169,20 +489,59 @@ func (m *manager) CreateArchive(ctx context.Context, uris []*fs.URI, writer io.W
 		files = append(files, file)
 	}
 
-	zipWriter := zip.NewWriter(writer)
-	defer zipWriter.Close()
+	// Resolve compression level, DefaultCompression is used if only the compression flag is set.
+	level := flate.NoCompression
+	if o.ArchiveCompression {
+		level = flate.DefaultCompression
+	}
+	if o.ArchiveCompressionLevel != nil {
+		level = *o.ArchiveCompressionLevel
+		if level < flate.NoCompression || level > flate.BestCompression {
+			return 0, fs.ErrNotSupportedAction.WithError(fmt.Errorf("invalid compression level: %d", level))
+		}
+	}
+
+	var archive archiveWriter
+	switch o.ArchiveFormat {
+	case "", fs.ArchiveFormatZip:
+		archive = newZipArchiveWriter(writer, level)
+	case fs.ArchiveFormatTarGz:
+		archive = newTarGzArchiveWriter(writer, level)
+	default:
+		return 0, fs.ErrNotSupportedAction.WithError(fmt.Errorf("not supported archive format: %s", o.ArchiveFormat))
+	}
+	defer archive.Close()
+
+	// Count all files in advance so that per-file progress can report the total.
+	total, index := 0, 0
+	if o.ArchiveEntryProgressFunc != nil {
+		total = m.countArchiveFiles(ctx, files)
+	}
+	entryProgress := func(f fs.File) {
+		index++
+		if o.ArchiveEntryProgressFunc != nil {
+			o.ArchiveEntryProgressFunc(f.DisplayName(), index, total)
+		}
+	}
+
+	var walkOpts []fs.Option
+	if o.ArchiveAllVersions {
+		walkOpts = append(walkOpts, dbfs.WithFileEntities())
+	}
 
 	var compressed int64
 	for _, file := range files {
 		if file.Type() == types.FileTypeFile {
-			if err := m.compressFileToArchive(ctx, "/", file, zipWriter, o.ArchiveCompression, o.DryRun); err != nil {
+			entryProgress(file)
+			if err := m.compressFileToArchive(ctx, "/", file, archive, o.DryRun, o.ArchiveAllVersions); err != nil {
 				failed++
 				m.l.Warning("Failed to compress file %s: %s, skipping it...", file.Uri(false), err)
 			}
 
-			compressed += file.Size()
+			size := archiveEntriesSize(file, o.ArchiveAllVersions)
+			compressed += size
 			if o.ProgressFunc != nil {
-				o.ProgressFunc(compressed, file.Size(), 0)
+				o.ProgressFunc(compressed, size, 0)
 			}
 
 			if o.MaxArchiveSize > 0 && compressed > o.MaxArchiveSize {
@@ -194,15 +553,17 @@ func (m *manager) CreateArchive(ctx context.Context, uris []*fs.URI, writer io.W
 				if f.Type() == types.FileTypeFolder || f.IsSymbolic() {
 					return nil
 				}
+				entryProgress(f)
 				if err := m.compressFileToArchive(ctx, strings.TrimPrefix(f.Uri(false).Dir(),
-					file.Uri(false).Dir()), f, zipWriter, o.ArchiveCompression, o.DryRun); err != nil {
+					file.Uri(false).Dir()), f, archive, o.DryRun, o.ArchiveAllVersions); err != nil {
 					failed++
 					m.l.Warning("Failed to compress file %s: %s, skipping it...", f.Uri(false), err)
 				}
 
-				compressed += f.Size()
+				size := archiveEntriesSize(f, o.ArchiveAllVersions)
+				compressed += size
 				if o.ProgressFunc != nil {
-					o.ProgressFunc(compressed, f.Size(), 0)
+					o.ProgressFunc(compressed, size, 0)
 				}
 
 				if o.MaxArchiveSize > 0 && compressed > o.MaxArchiveSize {
@@ -210,7 +571,7 @@ func (m *manager) CreateArchive(ctx context.Context, uris []*fs.URI, writer io.W
 				}
 
 				return nil
-			}); err != nil {
+			}, walkOpts...); err != nil {
 				m.l.Warning("Failed to walk folder %s: %s, skipping it...", file.Uri(false), err)
 				failed++
 			}
@@ -220,50 +581,252 @@ func (m *manager) CreateArchive(ctx context.Context, uris []*fs.URI, writer io.W
 	return failed, nil
 }
 
-func (m *manager) compressFileToArchive(ctx context.Context, parent string, file fs.File, zipWriter *zip.Writer,
-	compression bool, dryrun fs.CreateArchiveDryRunFunc) error {
-	es, err := m.GetEntitySource(ctx, file.PrimaryEntityID())
+// countArchiveFiles counts files that will be compressed into archive under given top level files.
+func (m *manager) countArchiveFiles(ctx context.Context, files []fs.File) int {
+	count := 0
+	for _, file := range files {
+		if file.Type() == types.FileTypeFile {
+			count++
+			continue
+		}
+
+		if err := m.Walk(ctx, file.Uri(false), intsets.MaxInt, func(f fs.File, level int) error {
+			if f.Type() == types.FileTypeFile && !f.IsSymbolic() {
+				count++
+			}
+			return nil
+		}); err != nil {
+			m.l.Warning("Failed to walk folder %s while counting files: %s", file.Uri(false), err)
+		}
+	}
+
+	return count
+}
+
+func (m *manager) compressFileToArchive(ctx context.Context, parent string, file fs.File, archive archiveWriter,
+	dryrun fs.CreateArchiveDryRunFunc, allVersions bool) error {
+	for _, entry := range archiveEntries(file, allVersions) {
+		if err := m.compressEntityToArchive(ctx, path.Join(parent, entry.name), file, entry.entityID, archive, dryrun); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *manager) compressEntityToArchive(ctx context.Context, name string, file fs.File, entityID int,
+	archive archiveWriter, dryrun fs.CreateArchiveDryRunFunc) error {
+	es, err := m.GetEntitySource(ctx, entityID)
 	if err != nil {
 		return fmt.Errorf("failed to get entity source for file %s: %w", file.Uri(false), err)
 	}
+	defer es.Close()
 
-	zipName := filepath.FromSlash(path.Join(parent, file.DisplayName()))
+	zipName := filepath.FromSlash(name)
 	if dryrun != nil {
 		dryrun(zipName, es.Entity())
 		return nil
 	}
 
-	m.l.Debug("Compressing %s to archive...", file.Uri(false))
+	m.l.Debug("Compressing %s to archive as %s...", file.Uri(false), zipName)
+	es.Apply(entitysource.WithContext(ctx))
+	if err := writeArchiveEntry(archive, zipName, file.UpdatedAt(), es); err != nil {
+		return fmt.Errorf("failed to compress %s: %w", file.Uri(false), err)
+	}
+
+	return nil
+}
+
+// writeArchiveEntry adds content of given entity source to archive. Size of the entity is used in
+// header instead of the file's, they differ for non-current versions.
+func writeArchiveEntry(archive archiveWriter, name string, modTime time.Time, es entitysource.EntitySource) error {
+	writer, err := archive.CreateFile(name, modTime, es.Entity().Size())
+	if err != nil {
+		return fmt.Errorf("failed to create archive header: %w", err)
+	}
+
+	_, err = io.Copy(writer, es)
+	return err
+}
+
+// archiveEntry is a single entity of a file to be packaged into archive.
+type archiveEntry struct {
+	name     string
+	entityID int
+	size     int64
+}
+
+// archiveVersionTimeFormat is used to name versions of a file in archive.
+const archiveVersionTimeFormat = "20060102-150405"
+
+// archiveEntries returns entities of given file to be packaged. If allVersions is set and the file has more
+// than one version, every version is returned with its creation time and entity ID appended to the file
+// name, the ID keeps names unique for versions created within the same second.
+func archiveEntries(file fs.File, allVersions bool) []archiveEntry {
+	current := []archiveEntry{{name: file.DisplayName(), entityID: file.PrimaryEntityID(), size: file.Size()}}
+	if !allVersions {
+		return current
+	}
+
+	versions := lo.Filter(file.Entities(), func(e fs.Entity, index int) bool {
+		return e.Type() == types.EntityTypeVersion
+	})
+	if len(versions) <= 1 {
+		return current
+	}
+
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[i].CreatedAt().Before(versions[j].CreatedAt())
+	})
+
+	ext := path.Ext(file.DisplayName())
+	base := strings.TrimSuffix(file.DisplayName(), ext)
+	return lo.Map(versions, func(e fs.Entity, index int) archiveEntry {
+		return archiveEntry{
+			name:     fmt.Sprintf("%s (%s-%d)%s", base, e.CreatedAt().Format(archiveVersionTimeFormat), e.ID(), ext),
+			entityID: e.ID(),
+			size:     e.Size(),
+		}
+	})
+}
+
+// archiveEntriesSize returns the total size of entities to be packaged for given file.
+func archiveEntriesSize(file fs.File, allVersions bool) int64 {
+	return lo.SumBy(archiveEntries(file, allVersions), func(e archiveEntry) int64 {
+		return e.size
+	})
+}
+
+type (
+	// archiveWriter abstracts the container format used by CreateArchive.
+	archiveWriter interface {
+		// CreateFile adds a new file entry of given size and returns the writer for its content.
+		CreateFile(name string, modTime time.Time, size int64) (io.Writer, error)
+		Close() error
+	}
+
+	zipArchiveWriter struct {
+		w     *zip.Writer
+		level int
+	}
+
+	tarGzArchiveWriter struct {
+		gw *gzip.Writer
+		tw *tar.Writer
+	}
+)
+
+// newZipArchiveWriter creates a zip archive writer. Level 0 maps to zip.Store, other levels
+// use zip.Deflate with given flate compression level.
+func newZipArchiveWriter(w io.Writer, level int) *zipArchiveWriter {
+	zw := zip.NewWriter(w)
+	if level != flate.NoCompression && level != flate.DefaultCompression {
+		zw.RegisterCompressor(zip.Deflate, func(out io.Writer) (io.WriteCloser, error) {
+			return flate.NewWriter(out, level)
+		})
+	}
+
+	return &zipArchiveWriter{
+		w:     zw,
+		level: level,
+	}
+}
+
+func (z *zipArchiveWriter) CreateFile(name string, modTime time.Time, size int64) (io.Writer, error) {
 	header := &zip.FileHeader{
-		Name:               zipName,
-		Modified:           file.UpdatedAt(),
-		UncompressedSize64: uint64(file.Size()),
+		Name:               name,
+		Modified:           modTime,
+		UncompressedSize64: uint64(size),
 	}
 
-	if !compression {
+	if z.level == flate.NoCompression {
 		header.Method = zip.Store
 	} else {
 		header.Method = zip.Deflate
 	}
 
-	writer, err := zipWriter.CreateHeader(header)
-	if err != nil {
-		return fmt.Errorf("failed to create zip header for %s: %w", file.Uri(false), err)
+	return z.w.CreateHeader(header)
+}
+
+func (z *zipArchiveWriter) Close() error {
+	return z.w.Close()
+}
+
+func newTarGzArchiveWriter(w io.Writer, level int) *tarGzArchiveWriter {
+	// Level is validated by caller, error can be ignored.
+	gw, _ := gzip.NewWriterLevel(w, level)
+	return &tarGzArchiveWriter{
+		gw: gw,
+		tw: tar.NewWriter(gw),
 	}
+}
 
-	es.Apply(entitysource.WithContext(ctx))
-	_, err = io.Copy(writer, es)
-	return err
+func (t *tarGzArchiveWriter) CreateFile(name string, modTime time.Time, size int64) (io.Writer, error) {
+	header := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     strings.TrimPrefix(filepath.ToSlash(name), "/"),
+		Mode:     0644,
+		Size:     size,
+		ModTime:  modTime,
+	}
+
+	if err := t.tw.WriteHeader(header); err != nil {
+		return nil, err
+	}
+
+	return t.tw, nil
+}
+
+func (t *tarGzArchiveWriter) Close() error {
+	if err := t.tw.Close(); err != nil {
+		return err
+	}
 
+	return t.gw.Close()
 }
 
 func getZipFileList(ctx context.Context, file io.ReaderAt, size int64, textEncoding encoding.Encoding) ([]ArchivedFile, error) {
+	files, err := openZipFiles(file, size, textEncoding)
+	if err != nil {
+		return nil, err
+	}
+
+	return archivedFiles(files), nil
+}
+
+func get7zFileList(ctx context.Context, file io.ReaderAt, size int64, extEncoding encoding.Encoding) ([]ArchivedFile, error) {
+	files, err := open7zFiles(file, size, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return archivedFiles(files), nil
+}
+
+func openZipFiles(file io.ReaderAt, size int64, textEncoding encoding.Encoding) ([]archivedFileReader, error) {
 	zr, err := zip.NewReader(file, size)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create zip reader: %w", err)
 	}
 
-	fileList := make([]ArchivedFile, 0, len(zr.File))
+	return zipReaderFiles(zr, size, textEncoding)
+}
+
+// openDetectedZipFiles is like openZipFiles, but encoding of non-UTF8 entry names is detected from
+// the same parsed central directory. Name of the detected encoding is returned.
+func openDetectedZipFiles(file io.ReaderAt, size int64) ([]archivedFileReader, string, error) {
+	zr, err := zip.NewReader(file, size)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create zip reader: %w", err)
+	}
+
+	detected := detectZipEncoding(zr)
+	files, err := zipReaderFiles(zr, size, ZipEncodings[detected])
+	return files, detected, err
+}
+
+func zipReaderFiles(zr *zip.Reader, size int64, textEncoding encoding.Encoding) ([]archivedFileReader, error) {
+	files := make([]archivedFileReader, 0, len(zr.File))
 	for _, f := range zr.File {
 		hdr := f.FileHeader
 		if hdr.NonUTF8 && textEncoding != nil {
@@ -282,36 +845,116 @@ func getZipFileList(ctx context.Context, file io.ReaderAt, size int64, textEncod
 
 		info := f.FileInfo()
 		modTime := info.ModTime()
-		fileList = append(fileList, ArchivedFile{
-			Name:        util.FormSlash(hdr.Name),
-			Size:        info.Size(),
-			UpdatedAt:   &modTime,
-			IsDirectory: info.IsDir(),
-		})
+		archived := ArchivedFile{
+			Name:           util.FormSlash(hdr.Name),
+			Size:           info.Size(),
+			UpdatedAt:      &modTime,
+			IsDirectory:    info.IsDir(),
+			CompressedSize: int64(hdr.CompressedSize64),
+			Unsafe:         isUnsafeArchivedPath(util.FormSlash(hdr.Name)),
+		}
+
+		// Other methods like bzip2 or zstd can legitimately go beyond the bound of deflate.
+		archived.Suspicious = (hdr.Method == zip.Store || hdr.Method == zip.Deflate) &&
+			archived.CompressionRatio() > maxArchiveCompressionRatio
+		files = append(files, archivedFileReader{ArchivedFile: archived, open: f.Open})
 	}
-	return fileList, nil
+
+	return files, nil
 }
 
-func get7zFileList(ctx context.Context, file io.ReaderAt, size int64, extEncoding encoding.Encoding) ([]ArchivedFile, error) {
-	zr, err := sevenzip.NewReader(file, size)
+// open7zFiles lists files in 7z archive. CompressedSize is left unknown, since the reader only
+// exposes which stream a file is packed into, but not the packed size.
+func open7zFiles(file io.ReaderAt, size int64, password string) ([]archivedFileReader, error) {
+	zr, err := sevenzip.NewReaderWithPassword(file, size, password)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create 7z reader: %w", err)
 	}
 
-	fileList := make([]ArchivedFile, 0, len(zr.File))
+	files := make([]archivedFileReader, 0, len(zr.File))
 	for _, f := range zr.File {
 		info := f.FileInfo()
 		modTime := info.ModTime()
-		fileList = append(fileList, ArchivedFile{
-			Name:        util.FormSlash(f.Name),
-			Size:        info.Size(),
-			UpdatedAt:   &modTime,
-			IsDirectory: info.IsDir(),
+		files = append(files, archivedFileReader{
+			ArchivedFile: ArchivedFile{
+				Name:        util.FormSlash(f.Name),
+				Size:        info.Size(),
+				UpdatedAt:   &modTime,
+				IsDirectory: info.IsDir(),
+				Unsafe:      isUnsafeArchivedPath(util.FormSlash(f.Name)),
+			},
+			open: f.Open,
 		})
 	}
-	return fileList, nil
+
+	return files, nil
+}
+
+// CompressionRatio returns ratio of uncompressed size to compressed size of the entry, 0 if
+// compressed size is unknown.
+func (f ArchivedFile) CompressionRatio() float64 {
+	if f.CompressedSize <= 0 {
+		return 0
+	}
+
+	return float64(f.Size) / float64(f.CompressedSize)
+}
+
+func archivedFiles(files []archivedFileReader) []ArchivedFile {
+	return lo.Map(files, func(f archivedFileReader, _ int) ArchivedFile {
+		return f.ArchivedFile
+	})
 }
 
 func getArchiveListCacheKey(entity int, encoding string) string {
 	return fmt.Sprintf("archive_list_%d_%s", entity, encoding)
 }
+
+func getArchiveEncodingCacheKey(entity int) string {
+	return fmt.Sprintf("archive_encoding_%d", entity)
+}
+
+// detectZipEncoding samples non-UTF8 entry names in given zip file and returns the name of
+// best matched encoding in ZipEncodings. Empty string is returned if no non-UTF8 entry is
+// found or the detection confidence is too low.
+func detectZipEncoding(zr *zip.Reader) string {
+	sample := make([]byte, 0, archiveEncodingSampleSize)
+	for _, f := range zr.File {
+		if !f.NonUTF8 {
+			continue
+		}
+
+		sample = append(sample, f.Name...)
+		sample = append(sample, '\n')
+		if len(sample) >= archiveEncodingSampleSize {
+			break
+		}
+	}
+
+	if len(sample) == 0 {
+		return ""
+	}
+
+	res, err := chardet.NewTextDetector().DetectBest(sample)
+	if err != nil || res.Confidence < archiveEncodingMinConfidence {
+		return ""
+	}
+
+	return matchZipEncoding(res.Charset)
+}
+
+// matchZipEncoding maps a charset name like "Shift_JIS" or "ISO-8859-2" to key of ZipEncodings.
+func matchZipEncoding(charset string) string {
+	normalize := func(s string) string {
+		return strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(s))
+	}
+
+	target := normalize(charset)
+	for name := range ZipEncodings {
+		if normalize(name) == target {
+			return name
+		}
+	}
+
+	return ""
+}
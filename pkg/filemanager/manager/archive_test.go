@@ -0,0 +1,488 @@
+package manager
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/cloudreve/Cloudreve/v4/ent"
+	"github.com/cloudreve/Cloudreve/v4/inventory/types"
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/fs"
+	"github.com/cloudreve/Cloudreve/v4/pkg/serializer"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+type versionedFile struct {
+	fs.File
+	name     string
+	primary  int
+	entities []*ent.Entity
+}
+
+func (f *versionedFile) DisplayName() string  { return f.name }
+func (f *versionedFile) PrimaryEntityID() int { return f.primary }
+func (f *versionedFile) Size() int64 {
+	for _, e := range f.entities {
+		if e.ID == f.primary {
+			return e.Size
+		}
+	}
+	return 0
+}
+func (f *versionedFile) Entities() []fs.Entity {
+	res := make([]fs.Entity, 0, len(f.entities))
+	for _, e := range f.entities {
+		res = append(res, fs.NewEntity(e))
+	}
+	return res
+}
+
+func TestArchiveEntries_AllVersions(t *testing.T) {
+	a := assert.New(t)
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	file := &versionedFile{
+		name:    "report.docx",
+		primary: 3,
+		entities: []*ent.Entity{
+			{ID: 3, Type: int(types.EntityTypeVersion), Size: 30, CreatedAt: base.Add(2 * time.Hour)},
+			{ID: 1, Type: int(types.EntityTypeVersion), Size: 10, CreatedAt: base},
+			{ID: 4, Type: int(types.EntityTypeThumbnail), Size: 5, CreatedAt: base},
+			{ID: 2, Type: int(types.EntityTypeVersion), Size: 20, CreatedAt: base.Add(time.Hour)},
+		},
+	}
+
+	entries := archiveEntries(file, true)
+	a.Equal([]archiveEntry{
+		{name: "report (20240501-100000-1).docx", entityID: 1, size: 10},
+		{name: "report (20240501-110000-2).docx", entityID: 2, size: 20},
+		{name: "report (20240501-120000-3).docx", entityID: 3, size: 30},
+	}, entries)
+	a.EqualValues(60, archiveEntriesSize(file, true))
+
+	// Only current version if option is not set
+	a.Equal([]archiveEntry{{name: "report.docx", entityID: 3, size: 30}}, archiveEntries(file, false))
+	a.EqualValues(30, archiveEntriesSize(file, false))
+}
+
+func TestArchiveEntries_SingleVersion(t *testing.T) {
+	a := assert.New(t)
+	file := &versionedFile{
+		name:    "photo.jpg",
+		primary: 1,
+		entities: []*ent.Entity{
+			{ID: 1, Type: int(types.EntityTypeVersion), Size: 10, CreatedAt: time.Now()},
+			{ID: 2, Type: int(types.EntityTypeThumbnail), Size: 1, CreatedAt: time.Now()},
+		},
+	}
+
+	a.Equal([]archiveEntry{{name: "photo.jpg", entityID: 1, size: 10}}, archiveEntries(file, true))
+}
+
+func TestTarGzArchiveWriter(t *testing.T) {
+	a := assert.New(t)
+	modTime := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	files := []struct{ name, content string }{
+		{"/a.txt", "hello"},
+		{"/folder/sub/b.txt", "world!"},
+		{"/empty", ""},
+	}
+
+	buf := &bytes.Buffer{}
+	archive := newTarGzArchiveWriter(buf, flate.BestCompression)
+	for _, f := range files {
+		w, err := archive.CreateFile(f.name, modTime, int64(len(f.content)))
+		a.NoError(err)
+		_, err = w.Write([]byte(f.content))
+		a.NoError(err)
+	}
+	a.NoError(archive.Close())
+
+	gr, err := gzip.NewReader(buf)
+	a.NoError(err)
+	tr := tar.NewReader(gr)
+	for _, f := range files {
+		hdr, err := tr.Next()
+		if !a.NoError(err) {
+			return
+		}
+
+		// Leading slash is trimmed so that archive extracts to relative paths
+		a.Equal(f.name[1:], hdr.Name)
+		a.EqualValues(tar.TypeReg, hdr.Typeflag)
+		a.True(modTime.Equal(hdr.ModTime))
+		content, err := io.ReadAll(tr)
+		a.NoError(err)
+		a.Equal(f.content, string(content))
+	}
+
+	_, err = tr.Next()
+	a.Equal(io.EOF, err)
+
+	// Writing more than declared size is rejected
+	archive = newTarGzArchiveWriter(&bytes.Buffer{}, flate.NoCompression)
+	w, err := archive.CreateFile("c.txt", modTime, 1)
+	a.NoError(err)
+	_, err = w.Write([]byte("too long"))
+	a.Error(err)
+}
+
+func TestOpenZipFiles(t *testing.T) {
+	a := assert.New(t)
+	gbkName, err := simplifiedchinese.GBK.NewEncoder().String("文档/说明.txt")
+	a.NoError(err)
+
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	_, err = zw.CreateHeader(&zip.FileHeader{Name: "文档/", NonUTF8: true})
+	a.NoError(err)
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: gbkName, NonUTF8: true})
+	a.NoError(err)
+	_, err = w.Write([]byte("hello"))
+	a.NoError(err)
+	a.NoError(zw.Close())
+
+	files, err := openZipFiles(bytes.NewReader(buf.Bytes()), int64(buf.Len()), simplifiedchinese.GBK)
+	a.NoError(err)
+	if a.Len(files, 2) {
+		a.True(files[0].IsDirectory)
+		a.Equal("文档/说明.txt", files[1].Name)
+		a.EqualValues(5, files[1].Size)
+
+		rc, err := files[1].open()
+		a.NoError(err)
+		content, _ := io.ReadAll(rc)
+		a.Equal("hello", string(content))
+		rc.Close()
+	}
+}
+
+func TestOpenDetectedZipFiles(t *testing.T) {
+	a := assert.New(t)
+	newZip := func(enc encoding.Encoding, names ...string) *bytes.Reader {
+		buf := &bytes.Buffer{}
+		zw := zip.NewWriter(buf)
+		for _, name := range names {
+			if enc != nil {
+				encoded, err := enc.NewEncoder().String(name)
+				a.NoError(err)
+				name = encoded
+			}
+			_, err := zw.CreateHeader(&zip.FileHeader{Name: name, NonUTF8: enc != nil})
+			a.NoError(err)
+		}
+		a.NoError(zw.Close())
+		return bytes.NewReader(buf.Bytes())
+	}
+
+	cases := []struct {
+		enc      encoding.Encoding
+		expected []string
+		names    []string
+	}{
+		{
+			enc:      simplifiedchinese.GBK,
+			expected: []string{"gb18030", "gbk"},
+			names:    []string{"项目文档/会议记录（第一季度）.docx", "项目文档/年度财务报告与预算说明.xlsx", "照片/北京旅行纪念照片.jpg"},
+		},
+		{
+			enc:      japanese.ShiftJIS,
+			expected: []string{"shiftjis"},
+			names:    []string{"資料/会議の議事録について.txt", "写真/東京旅行の思い出.jpg", "ドキュメント/プロジェクト計画書.docx"},
+		},
+	}
+
+	for _, c := range cases {
+		r := newZip(c.enc, c.names...)
+		files, detected, err := openDetectedZipFiles(r, r.Size())
+		a.NoError(err)
+		a.Contains(c.expected, detected)
+		a.Equal(c.names, lo.Map(files, func(f archivedFileReader, _ int) string { return f.Name }))
+	}
+
+	// UTF-8 names need no detection
+	r := newZip(nil, "文档/说明.txt")
+	files, detected, err := openDetectedZipFiles(r, r.Size())
+	a.NoError(err)
+	a.Empty(detected)
+	a.Equal("文档/说明.txt", files[0].Name)
+}
+
+func TestMatchZipEncoding(t *testing.T) {
+	a := assert.New(t)
+	a.Equal("shiftjis", matchZipEncoding("Shift_JIS"))
+	a.Equal("gb18030", matchZipEncoding("GB-18030"))
+	a.Equal("iso8859_2", matchZipEncoding("ISO-8859-2"))
+	a.Equal("euckr", matchZipEncoding("EUC-KR"))
+	a.Empty(matchZipEncoding("UTF-32"))
+}
+
+func TestValidateArchivedFiles(t *testing.T) {
+	a := assert.New(t)
+	file := func(name string, size int64) archivedFileReader {
+		return archivedFileReader{ArchivedFile: ArchivedFile{Name: name, Size: size}}
+	}
+	files := []archivedFileReader{file("a", 10), file("b/c", 20), file("b/..d", 5)}
+
+	a.NoError(validateArchivedFiles(files, 0, 0))
+	a.NoError(validateArchivedFiles(files, 35, 20))
+
+	code := func(err error) int {
+		var appErr serializer.AppError
+		a.ErrorAs(err, &appErr)
+		return appErr.Code
+	}
+
+	// Size limits
+	a.Equal(serializer.CodeFileTooLarge, code(validateArchivedFiles(files, 0, 19)))
+	a.Equal(serializer.CodeFileTooLarge, code(validateArchivedFiles(files, 34, 0)))
+
+	// Path traversal
+	a.Equal(serializer.CodeIllegalObjectName, code(validateArchivedFiles(append(files, file("../evil", 1)), 0, 0)))
+	a.Equal(serializer.CodeIllegalObjectName, code(validateArchivedFiles([]archivedFileReader{file("a/../../evil", 1)}, 0, 0)))
+}
+
+func TestExtractionRoots(t *testing.T) {
+	a := assert.New(t)
+	created := []string{"a", "a/b", "a/b/c.txt", "d.txt", "e/f.txt", "e/g"}
+	a.Equal([]string{"a", "d.txt", "e/f.txt", "e/g"}, extractionRoots(created, map[string]bool{"a": true, "a/b": true, "e/g": true}))
+	a.Empty(extractionRoots(nil, nil))
+}
+
+func TestExtractStream(t *testing.T) {
+	a := assert.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	read := int64(0)
+	stream := &extractStream{
+		Closer: io.NopCloser(nil),
+		ctx:    ctx,
+		r:      io.LimitReader(bytes.NewReader([]byte("0123456789")), 6),
+		onRead: func(n int64) { read += n },
+	}
+
+	buf := make([]byte, 4)
+	n, err := stream.Read(buf)
+	a.NoError(err)
+	a.Equal(4, n)
+	a.EqualValues(4, read)
+
+	// Reading stops once context is canceled
+	cancel()
+	_, err = stream.Read(buf)
+	a.ErrorIs(err, context.Canceled)
+	a.EqualValues(4, read)
+
+	// Content beyond declared size is not read
+	stream.ctx = context.Background()
+	content, err := io.ReadAll(stream)
+	a.NoError(err)
+	a.Equal("45", string(content))
+	a.EqualValues(6, read)
+}
+
+func TestOpenZipFiles_Suspicious(t *testing.T) {
+	a := assert.New(t)
+	newZip := func(hdr *zip.FileHeader, raw []byte) *bytes.Reader {
+		buf := &bytes.Buffer{}
+		zw := zip.NewWriter(buf)
+		w, err := zw.CreateRaw(hdr)
+		a.NoError(err)
+		_, err = w.Write(raw)
+		a.NoError(err)
+		a.NoError(zw.Close())
+		return bytes.NewReader(buf.Bytes())
+	}
+
+	// Small deflate stream declaring 1 TB of content is listed but flagged
+	bomb := newZip(&zip.FileHeader{Name: "bomb.bin", Method: zip.Deflate, CompressedSize64: 16, UncompressedSize64: 1 << 40}, make([]byte, 16))
+	files, err := openZipFiles(bomb, bomb.Size(), nil)
+	a.NoError(err)
+	if a.Len(files, 1) {
+		a.True(files[0].Suspicious)
+	}
+	listed, err := getZipFileList(context.Background(), bomb, bomb.Size(), nil)
+	a.NoError(err)
+	a.True(listed[0].Suspicious)
+
+	// Extraction rejects it
+	err = validateArchivedFiles(files, 0, 0)
+	var appErr serializer.AppError
+	if a.ErrorAs(err, &appErr) {
+		a.Equal(serializer.CodeSuspiciousArchive, appErr.Code)
+		a.Contains(err.Error(), "bomb.bin")
+	}
+
+	// Plausible ratio is accepted and exposed
+	ok := newZip(&zip.FileHeader{Name: "ok.bin", Method: zip.Deflate, CompressedSize64: 16, UncompressedSize64: 16 * 1000}, make([]byte, 16))
+	files, err = openZipFiles(ok, ok.Size(), nil)
+	a.NoError(err)
+	if a.Len(files, 1) {
+		a.EqualValues(16, files[0].CompressedSize)
+		a.Equal(float64(1000), files[0].CompressionRatio())
+		a.False(files[0].Suspicious)
+	}
+	a.NoError(validateArchivedFiles(files, 0, 0))
+
+	// Methods compressing better than deflate are not judged by its bound
+	zstd := newZip(&zip.FileHeader{Name: "log.txt", Method: 93, CompressedSize64: 16, UncompressedSize64: 1 << 30}, make([]byte, 16))
+	files, err = openZipFiles(zstd, zstd.Size(), nil)
+	a.NoError(err)
+	a.False(files[0].Suspicious)
+}
+
+func TestValidateArchivedFiles_UnknownCompressedSize(t *testing.T) {
+	a := assert.New(t)
+
+	// Entries in solid 7z archive have no compressed size, they are never flagged
+	files := []archivedFileReader{{ArchivedFile: ArchivedFile{Name: "huge.log", Size: 200 << 20}}}
+	a.Zero(files[0].CompressionRatio())
+	a.NoError(validateArchivedFiles(files, 0, 0))
+}
+
+func TestOpen7zFiles(t *testing.T) {
+	a := assert.New(t)
+	raw, err := os.ReadFile("testdata/solid.7z")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// All files are packed into one solid stream, compressed size is unknown
+	files, err := open7zFiles(bytes.NewReader(raw), int64(len(raw)), "")
+	a.NoError(err)
+	a.Len(files, 10)
+	for _, f := range files {
+		a.Zero(f.CompressedSize)
+		a.False(f.Suspicious)
+		a.False(f.Unsafe)
+	}
+	a.Equal("01", files[0].Name)
+	a.EqualValues(3572, files[0].Size)
+
+	// Size limits still apply
+	a.NoError(validateArchivedFiles(files, 0, 0))
+	for _, err := range []error{validateArchivedFiles(files, 0, 4000), validateArchivedFiles(files, 30000, 0)} {
+		var appErr serializer.AppError
+		if a.ErrorAs(err, &appErr) {
+			a.Equal(serializer.CodeFileTooLarge, appErr.Code)
+		}
+	}
+
+	rc, err := files[0].open()
+	a.NoError(err)
+	content, err := io.ReadAll(rc)
+	a.NoError(err)
+	a.NoError(rc.Close())
+	a.Len(content, 3572)
+}
+
+func TestOpenZipFiles_UnsafePath(t *testing.T) {
+	a := assert.New(t)
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	for _, name := range []string{"../../etc/passwd", "a/../../b", "/etc/shadow", "..\\..\\win.ini", "C:/boot.ini", "ok/..name", "ok/file"} {
+		_, err := zw.Create(name)
+		a.NoError(err)
+	}
+	a.NoError(zw.Close())
+
+	files, err := getZipFileList(context.Background(), bytes.NewReader(buf.Bytes()), int64(buf.Len()), nil)
+	a.NoError(err)
+	unsafe := lo.FilterMap(files, func(f ArchivedFile, _ int) (string, bool) { return f.Name, f.Unsafe })
+	a.Equal([]string{"../../etc/passwd", "../b", "/etc/shadow", "../../win.ini", "C:/boot.ini"}, unsafe)
+	a.Len(files, 7)
+}
+
+func TestWriteArchiveEntry_TarGzVersions(t *testing.T) {
+	a := assert.New(t)
+	created := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	file := &versionedFile{
+		name:    "notes.txt",
+		primary: 3,
+		entities: []*ent.Entity{
+			{ID: 1, Type: int(types.EntityTypeVersion), Size: 3, CreatedAt: created},
+			{ID: 2, Type: int(types.EntityTypeVersion), Size: 11, CreatedAt: created},
+			{ID: 3, Type: int(types.EntityTypeVersion), Size: 6, CreatedAt: created.Add(time.Second)},
+		},
+	}
+	contents := map[int]string{1: "old", 2: "much longer", 3: "latest"}
+
+	buf := &bytes.Buffer{}
+	archive := newTarGzArchiveWriter(buf, flate.DefaultCompression)
+	entries := archiveEntries(file, true)
+	for _, entry := range entries {
+		a.NoError(writeArchiveEntry(archive, path.Join("/docs", entry.name), created, newMemoryEntitySource(contents[entry.entityID])))
+	}
+	a.NoError(archive.Close())
+
+	// Versions created in the same second get distinct names, and each has its own size
+	gr, err := gzip.NewReader(buf)
+	a.NoError(err)
+	tr := tar.NewReader(gr)
+	read := map[string]string{}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		a.NoError(err)
+		content, err := io.ReadAll(tr)
+		a.NoError(err)
+		a.EqualValues(len(content), hdr.Size)
+		read[hdr.Name] = string(content)
+	}
+
+	a.Equal(map[string]string{
+		"docs/notes (20240501-100000-1).txt": "old",
+		"docs/notes (20240501-100000-2).txt": "much longer",
+		"docs/notes (20240501-100001-3).txt": "latest",
+	}, read)
+}
+
+func TestManager_CreateArchive_EntryProgress(t *testing.T) {
+	a := assert.New(t)
+	env := newDBTestEnv(t)
+	docs := env.createFile(env.root, "docs", types.FileTypeFolder)
+	env.createFile(docs, "a.txt", types.FileTypeFile)
+	env.createFile(env.createFile(docs, "sub", types.FileTypeFolder), "b.txt", types.FileTypeFile)
+	env.createFile(env.root, "c.txt", types.FileTypeFile)
+
+	uris := lo.Map([]string{"docs", "c.txt"}, func(name string, index int) *fs.URI {
+		u, err := fs.NewUriFromString("cloudreve://my/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return u
+	})
+
+	type progress struct {
+		name         string
+		index, total int
+	}
+	reported := make([]progress, 0)
+	_, err := env.fm.CreateArchive(env.ctx, uris, io.Discard, fs.WithArchiveEntryProgressFunc(func(name string, index, total int) {
+		reported = append(reported, progress{name, index, total})
+	}))
+	a.NoError(err)
+
+	// Every file is reported once with its 1-based index and the total count, folders are skipped.
+	if a.Len(reported, 3) {
+		a.ElementsMatch([]string{"a.txt", "b.txt", "c.txt"}, lo.Map(reported, func(p progress, index int) string {
+			return p.name
+		}))
+		for i, p := range reported {
+			a.Equal(i+1, p.index)
+			a.Equal(3, p.total)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package manager
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/cloudreve/Cloudreve/v4/application/dependency"
+	"github.com/cloudreve/Cloudreve/v4/ent"
+	"github.com/cloudreve/Cloudreve/v4/ent/task"
+	"github.com/cloudreve/Cloudreve/v4/inventory"
+	"github.com/cloudreve/Cloudreve/v4/inventory/types"
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/fs"
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/cloudreve/Cloudreve/v4/pkg/queue"
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
+)
+
+type (
+	// EntityChecksumTask computes content checksum of a new entity, or of all existing
+	// entities without checksum if Backfill is set.
+	EntityChecksumTask struct {
+		*queue.DBTask
+	}
+
+	EntityChecksumTaskState struct {
+		EntityID int  `json:"entity_id,omitempty"`
+		Backfill bool `json:"backfill,omitempty"`
+		// Cursor is the largest entity ID processed in backfill.
+		Cursor int `json:"cursor,omitempty"`
+		Failed int `json:"failed,omitempty"`
+	}
+)
+
+const checksumBackfillBatchSize = 100
+
+func init() {
+	queue.RegisterResumableTaskFactory(queue.EntityChecksumTaskType, NewEntityChecksumTaskFromModel)
+}
+
+// NewEntityChecksumTask creates a task to compute checksum of given entity.
+func NewEntityChecksumTask(ctx context.Context, entityID int, creator *ent.User) (*EntityChecksumTask, error) {
+	return newEntityChecksumTask(ctx, &EntityChecksumTaskState{EntityID: entityID}, creator)
+}
+
+// NewEntityChecksumBackfillTask creates a task to compute checksums of all existing entities
+// without checksum of current algorithm.
+func NewEntityChecksumBackfillTask(ctx context.Context, creator *ent.User) (*EntityChecksumTask, error) {
+	return newEntityChecksumTask(ctx, &EntityChecksumTaskState{Backfill: true}, creator)
+}
+
+func newEntityChecksumTask(ctx context.Context, state *EntityChecksumTaskState, creator *ent.User) (*EntityChecksumTask, error) {
+	stateBytes, err := json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal state: %w", err)
+	}
+
+	return &EntityChecksumTask{
+		DBTask: &queue.DBTask{
+			DirectOwner: creator,
+			Task: &ent.Task{
+				Type:          queue.EntityChecksumTaskType,
+				CorrelationID: logging.CorrelationID(ctx),
+				PrivateState:  string(stateBytes),
+				PublicState:   &types.TaskPublicState{},
+			},
+		},
+	}, nil
+}
+
+func NewEntityChecksumTaskFromModel(task *ent.Task) queue.Task {
+	return &EntityChecksumTask{
+		DBTask: &queue.DBTask{
+			Task: task,
+		},
+	}
+}
+
+func (m *EntityChecksumTask) Do(ctx context.Context) (task.Status, error) {
+	dep := dependency.FromContext(ctx)
+	fm := NewFileManager(dep, inventory.UserFromContext(ctx)).(*manager)
+
+	// unmarshal state
+	var state EntityChecksumTaskState
+	if err := json.Unmarshal([]byte(m.State()), &state); err != nil {
+		return task.StatusError, fmt.Errorf("failed to unmarshal state: %s (%w)", err, queue.CriticalErr)
+	}
+
+	algorithm := dep.SettingProvider().EntityChecksumAlgorithm(ctx)
+	if algorithm == setting.ChecksumAlgorithmNone {
+		fm.l.Info("Entity checksum is disabled, skip.")
+		return task.StatusCompleted, nil
+	}
+
+	if !state.Backfill {
+		if err := fm.ComputeEntityChecksum(ctx, state.EntityID, algorithm); err != nil {
+			return task.StatusError, err
+		}
+
+		return task.StatusCompleted, nil
+	}
+
+	fileClient := dep.FileClient()
+	for {
+		entities, err := fileClient.EntitiesWithoutChecksum(ctx, string(algorithm), state.Cursor, checksumBackfillBatchSize)
+		if err != nil {
+			return task.StatusError, fmt.Errorf("failed to list entities: %w", err)
+		}
+
+		if len(entities) == 0 {
+			break
+		}
+
+		for _, e := range entities {
+			if err := fm.ComputeEntityChecksum(ctx, e.ID, algorithm, fs.WithEntity(fs.NewEntity(e))); err != nil {
+				fm.l.Warning("Failed to compute checksum of entity %d: %s", e.ID, err)
+				state.Failed++
+			}
+
+			state.Cursor = e.ID
+		}
+
+		privateState, err := json.Marshal(state)
+		if err != nil {
+			return task.StatusError, fmt.Errorf("failed to marshal state: %w", err)
+		}
+		m.Task.PrivateState = string(privateState)
+	}
+
+	fm.l.Info("Entity checksum backfill finished, %d entities failed.", state.Failed)
+	return task.StatusCompleted, nil
+}
+
+// ComputeEntityChecksum reads content of an entity and saves its checksum.
+func (m *manager) ComputeEntityChecksum(ctx context.Context, entityID int, algorithm setting.ChecksumAlgorithm, opts ...fs.Option) error {
+	h, err := newChecksumHash(algorithm)
+	if err != nil {
+		return fmt.Errorf("%s (%w)", err, queue.CriticalErr)
+	}
+
+	source, err := m.GetEntitySource(ctx, entityID, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to get entity source: %w", err)
+	}
+	defer source.Close()
+
+	if _, err := io.Copy(h, source); err != nil {
+		return fmt.Errorf("failed to read entity: %w", err)
+	}
+
+	if err := m.dep.FileClient().SetEntityChecksum(ctx, entityID, string(algorithm), hex.EncodeToString(h.Sum(nil))); err != nil {
+		return fmt.Errorf("failed to save checksum: %w", err)
+	}
+
+	return nil
+}
+
+func newChecksumHash(algorithm setting.ChecksumAlgorithm) (hash.Hash, error) {
+	switch algorithm {
+	case setting.ChecksumAlgorithmMD5:
+		return md5.New(), nil
+	case setting.ChecksumAlgorithmSHA256:
+		return sha256.New(), nil
+	default:
+		return nil, fmt.Errorf("unknown checksum algorithm %q", algorithm)
+	}
+}
+
+func (m *manager) checksumForNewEntity(ctx context.Context, session *fs.UploadSession) {
+	if session.Props.EntityType != nil && *session.Props.EntityType != types.EntityTypeVersion {
+		return
+	}
+
+	if m.settings.EntityChecksumAlgorithm(ctx) == setting.ChecksumAlgorithmNone {
+		return
+	}
+
+	t, err := NewEntityChecksumTask(ctx, session.EntityID, m.user)
+	if err != nil {
+		m.l.Warning("Failed to create entity checksum task: %s", err)
+		return
+	}
+	if err := m.dep.IoIntenseQueue(ctx).QueueTask(ctx, t); err != nil {
+		m.l.Warning("Failed to queue entity checksum task: %s", err)
+	}
+}
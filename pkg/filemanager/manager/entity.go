@@ -32,13 +32,16 @@ type (
 		GetEntitySource(ctx context.Context, entityID int, opts ...fs.Option) (entitysource.EntitySource, error)
 		// Thumbnail gets thumbnail entity of given file
 		Thumbnail(ctx context.Context, uri *fs.URI) (entitysource.EntitySource, error)
+		// AnimatedThumbnail gets animated preview entity of given video file, generated on demand
+		AnimatedThumbnail(ctx context.Context, uri *fs.URI) (entitysource.EntitySource, error)
 		// SubmitAndAwaitThumbnailTask submits a thumbnail task and waits for result
 		SubmitAndAwaitThumbnailTask(ctx context.Context, uri *fs.URI, ext string, entity fs.Entity) (fs.Entity, error)
 		// SetCurrentVersion sets current version of given file
 		SetCurrentVersion(ctx context.Context, path *fs.URI, version int) error
 		// DeleteVersion deletes a version of given file
 		DeleteVersion(ctx context.Context, path *fs.URI, version int) error
-		// ExtractAndSaveMediaMeta extracts and saves media meta into file metadata of given file.
+		// ExtractAndSaveMediaMeta extracts and saves media meta into file metadata of given file. If geocoding
+		// is rate limited, other metas are saved and a mediameta.GeocodingRateLimitedError is returned.
 		ExtractAndSaveMediaMeta(ctx context.Context, uri *fs.URI, entityID int) error
 		// RecycleEntities recycles a group of entities
 		RecycleEntities(ctx context.Context, force bool, entityIDs ...int) error
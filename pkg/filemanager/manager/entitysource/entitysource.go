@@ -2,6 +2,8 @@ package entitysource
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -272,8 +274,8 @@ func (f *entitySource) Serve(w http.ResponseWriter, r *http.Request, opts ...Ent
 		}
 	}
 
-	etag := "\"" + hashid.EncodeEntityID(f.hasher, f.e.ID()) + "\""
-	w.Header().Set("Etag", "\""+hashid.EncodeEntityID(f.hasher, f.e.ID())+"\"")
+	etag := entityETag(f.e, f.hasher)
+	w.Header().Set("Etag", etag)
 
 	if f.o.IsDownload {
 		// Properly handle non-ASCII characters in filename according to RFC 6266
@@ -298,6 +300,13 @@ func (f *entitySource) Serve(w http.ResponseWriter, r *http.Request, opts ...Ent
 		return
 	}
 
+	// Content-MD5 is the digest of the whole body, only present in full content responses.
+	if md5Hex, ok := f.e.Checksums()[string(setting.ChecksumAlgorithmMD5)]; ok && rangeReq == "" {
+		if digest, err := hex.DecodeString(md5Hex); err == nil {
+			w.Header().Set("Content-MD5", base64.StdEncoding.EncodeToString(digest))
+		}
+	}
+
 	if !f.IsLocal() {
 		// for non-local file, reverse-proxy the request
 		expire := time.Now().Add(defaultUrlExpire)
@@ -314,6 +323,7 @@ func (f *entitySource) Serve(w http.ResponseWriter, r *http.Request, opts ...Ent
 		}
 
 		start := time.Now()
+		rangeSupported := f.handler.Capabilities().StaticFeatures.Enabled(int(driver.HandlerCapabilityRangeSupported))
 		proxy := &httputil.ReverseProxy{
 			Director: func(request *http.Request) {
 				request.URL.Scheme = target.Scheme
@@ -323,11 +333,17 @@ func (f *entitySource) Serve(w http.ResponseWriter, r *http.Request, opts ...Ent
 				request.URL.RawQuery = target.RawQuery
 				request.Host = target.Host
 				request.Header.Del("Authorization")
+				proxyConditionalHeaders(request.Header, rangeReq, rangeSupported)
 			},
 			ModifyResponse: func(response *http.Response) error {
 				response.Header.Del("ETag")
 				response.Header.Del("Content-Disposition")
 				response.Header.Del("Cache-Control")
+				if rangeSupported {
+					response.Header.Set("Accept-Ranges", "bytes")
+				} else {
+					response.Header.Del("Accept-Ranges")
+				}
 				logging.Request(f.l,
 					false,
 					response.StatusCode,
@@ -730,6 +746,43 @@ func capExpireTime(expire *time.Time, min, max time.Duration) *time.Time {
 	return &cappedExpires
 }
 
+// entityETag returns strong ETag of the entity. Content checksum is used if computed so that
+// clients can verify downloads, otherwise falls back to the hashed entity ID.
+func entityETag(e fs.Entity, hasher hashid.Encoder) string {
+	checksums := e.Checksums()
+	for _, algorithm := range []setting.ChecksumAlgorithm{setting.ChecksumAlgorithmSHA256, setting.ChecksumAlgorithmMD5} {
+		if checksum, ok := checksums[string(algorithm)]; ok && checksum != "" {
+			return "\"" + checksum + "\""
+		}
+	}
+
+	return "\"" + hashid.EncodeEntityID(hasher, e.ID()) + "\""
+}
+
+// proxyConditionalHeaders rewrites conditional headers of a request proxied to storage.
+// ETag based preconditions are already evaluated against Cloudreve's own ETag, which storage
+// does not know, so they are removed. Range is forwarded only if it survived If-Range and
+// the storage supports it, date based preconditions are left for storage to evaluate.
+func proxyConditionalHeaders(h http.Header, rangeReq string, rangeSupported bool) {
+	if h.Get("If-None-Match") != "" {
+		// RFC 7232 section 3.3 and 3.4: date based preconditions are ignored if their ETag
+		// based counterpart is present.
+		h.Del("If-Modified-Since")
+	}
+	if h.Get("If-Match") != "" {
+		h.Del("If-Unmodified-Since")
+	}
+	h.Del("If-Match")
+	h.Del("If-None-Match")
+	h.Del("If-Range")
+
+	if rangeReq != "" && rangeSupported {
+		h.Set("Range", rangeReq)
+	} else {
+		h.Del("Range")
+	}
+}
+
 // checkPreconditions evaluates request preconditions and reports whether a precondition
 // resulted in sending StatusNotModified or StatusPreconditionFailed.
 func checkPreconditions(w http.ResponseWriter, r *http.Request, etag string) (done bool, rangeHeader string) {
@@ -0,0 +1,92 @@
+package entitysource
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cloudreve/Cloudreve/v4/ent"
+	"github.com/cloudreve/Cloudreve/v4/inventory/types"
+	"github.com/cloudreve/Cloudreve/v4/pkg/boolset"
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/driver"
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/fs"
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeHandler struct {
+	driver.Handler
+	source   string
+	features *boolset.BooleanSet
+}
+
+func (h *fakeHandler) Source(ctx context.Context, e fs.Entity, args *driver.GetSourceArgs) (string, error) {
+	return h.source, nil
+}
+
+func (h *fakeHandler) Capabilities() *driver.Capabilities {
+	return &driver.Capabilities{StaticFeatures: h.features}
+}
+
+func TestEntitySource_ServeProxied(t *testing.T) {
+	a := assert.New(t)
+	modTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	storage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"storage"`)
+		http.ServeContent(w, r, "file.txt", modTime, bytes.NewReader([]byte("0123456789")))
+	}))
+	defer storage.Close()
+
+	features := &boolset.BooleanSet{}
+	boolset.Sets(map[driver.HandlerCapability]bool{driver.HandlerCapabilityRangeSupported: true}, features)
+	handler := &fakeHandler{source: storage.URL + "/file.txt", features: features}
+	entity := fs.NewEntity(&ent.Entity{ID: 1, Source: "file.txt", Size: 10, Checksums: map[string]string{"sha256": "abc"}})
+	serve := func(header map[string]string) *httptest.ResponseRecorder {
+		src := NewEntitySource(entity, handler, &ent.StoragePolicy{Settings: &types.PolicySetting{}}, nil, nil, nil, nil,
+			logging.NewConsoleLogger(logging.LevelError), nil, nil, WithContext(context.Background()))
+		r := httptest.NewRequest(http.MethodGet, "/content", nil)
+		for k, v := range header {
+			r.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		src.Serve(w, r)
+		return w
+	}
+
+	w := serve(map[string]string{"Range": "bytes=2-5"})
+	a.Equal(http.StatusPartialContent, w.Code)
+	a.Equal("2345", w.Body.String())
+	a.Equal("bytes 2-5/10", w.Header().Get("Content-Range"))
+	a.Equal("bytes", w.Header().Get("Accept-Ranges"))
+	a.Equal(`"abc"`, w.Header().Get("Etag"))
+
+	// If-Range is evaluated against Cloudreve's ETag instead of storage's
+	w = serve(map[string]string{"Range": "bytes=2-5", "If-Range": `"abc"`})
+	a.Equal(http.StatusPartialContent, w.Code)
+	a.Equal("2345", w.Body.String())
+	w = serve(map[string]string{"Range": "bytes=2-5", "If-Range": `"stale"`})
+	a.Equal(http.StatusOK, w.Code)
+	a.Equal("0123456789", w.Body.String())
+
+	// ETag preconditions are not forwarded to storage
+	w = serve(map[string]string{"If-Match": `"abc"`})
+	a.Equal(http.StatusOK, w.Code)
+	a.Equal(http.StatusNotModified, serve(map[string]string{"If-None-Match": `"abc"`}).Code)
+	a.Equal(http.StatusOK, serve(map[string]string{"If-None-Match": `"stale"`, "If-Modified-Since": modTime.Format(http.TimeFormat)}).Code)
+
+	// Date preconditions are evaluated by storage
+	w = serve(map[string]string{"If-Modified-Since": modTime.Format(http.TimeFormat)})
+	a.Equal(http.StatusNotModified, w.Code)
+	a.Equal(`"abc"`, w.Header().Get("Etag"))
+	a.Equal(http.StatusOK, serve(map[string]string{"If-Modified-Since": modTime.Add(-time.Hour).Format(http.TimeFormat)}).Code)
+
+	// Range is dropped if storage does not support it
+	handler.features = &boolset.BooleanSet{}
+	w = serve(map[string]string{"Range": "bytes=2-5"})
+	a.Equal(http.StatusOK, w.Code)
+	a.Equal("0123456789", w.Body.String())
+	a.Empty(w.Header().Get("Accept-Ranges"))
+}
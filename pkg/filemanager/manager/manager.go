@@ -53,6 +53,14 @@ type (
 		Walk(ctx context.Context, path *fs.URI, depth int, f fs.WalkFunc, opts ...fs.Option) error
 		// UpsertMedata update or insert metadata of given file
 		PatchMedata(ctx context.Context, path []*fs.URI, data ...fs.MetadataPatch) error
+		// PatchMetadataBatch applies different metadata patches to each file, failures are reported per file
+		PatchMetadataBatch(ctx context.Context, items []MetadataPatchItem) error
+		// SetFavorite marks or unmarks given files as favorite
+		SetFavorite(ctx context.Context, path []*fs.URI, favorite bool) error
+		// ListFavorites lists favorite files of current user across all folders
+		ListFavorites(ctx context.Context, args *ListArgs) (fs.File, *fs.ListFileResult, error)
+		// ListTagTree lists tags of current user's files as a tree of nested tags
+		ListTagTree(ctx context.Context) ([]*inventory.TagNode, error)
 		// CreateViewerSession creates a viewer session for given file
 		CreateViewerSession(ctx context.Context, uri *fs.URI, version string, viewer *types.Viewer) (*ViewerSession, error)
 		// TraverseFile traverses a file to its root file, return the file with linked root.
@@ -87,8 +95,12 @@ type (
 	Archiver interface {
 		// CreateArchive creates an archive
 		CreateArchive(ctx context.Context, uris []*fs.URI, writer io.Writer, opts ...fs.Option) (int, error)
-		// ListArchiveFiles lists files in an archive
-		ListArchiveFiles(ctx context.Context, uri *fs.URI, entity, zipEncoding string) ([]ArchivedFile, error)
+		// ListArchiveFiles lists files in an archive. If zipEncoding is empty, encoding of non-UTF8 entry names
+		// will be detected automatically. The effective encoding is returned alongside the file list.
+		ListArchiveFiles(ctx context.Context, uri *fs.URI, entity, zipEncoding string) ([]ArchivedFile, string, error)
+		// ExtractArchive extracts files in a zip or 7z archive into dst folder. Extraction stops once ctx is
+		// canceled, see fs.WithArchiveRollbackOnCancel and fs.WithArchiveExtractProgressFunc.
+		ExtractArchive(ctx context.Context, archiveURI, dst *fs.URI, opts ...fs.Option) error
 	}
 
 	FileManager interface {
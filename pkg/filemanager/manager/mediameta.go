@@ -3,7 +3,11 @@ package manager
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
 
 	"github.com/cloudreve/Cloudreve/v4/application/dependency"
 	"github.com/cloudreve/Cloudreve/v4/ent"
@@ -13,9 +17,11 @@ import (
 	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/driver"
 	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/fs"
 	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/fs/dbfs"
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/manager/entitysource"
 	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
 	"github.com/cloudreve/Cloudreve/v4/pkg/mediameta"
 	"github.com/cloudreve/Cloudreve/v4/pkg/queue"
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
 	"github.com/cloudreve/Cloudreve/v4/pkg/util"
 	"github.com/samber/lo"
 )
@@ -28,9 +34,15 @@ type (
 	MediaMetaTaskState struct {
 		Uri      *fs.URI `json:"uri"`
 		EntityID int     `json:"entity_id"`
+		// GeocodingDeferred is the number of times the task is deferred due to geocoding rate limit.
+		GeocodingDeferred int `json:"geocoding_deferred,omitempty"`
 	}
 )
 
+// mediaMetaMaxGeocodingDeferral is the max number of times a media meta task waits for geocoding
+// rate limit to be reset, after which the file is left without geocoding metas.
+const mediaMetaMaxGeocodingDeferral = 5
+
 func init() {
 	queue.RegisterResumableTaskFactory(queue.MediaMetaTaskType, NewMediaMetaTaskFromModel)
 }
@@ -78,6 +90,27 @@ func (m *MediaMetaTask) Do(ctx context.Context) (task.Status, error) {
 	}
 
 	err := fm.ExtractAndSaveMediaMeta(ctx, state.Uri, state.EntityID)
+	var rateLimited *mediameta.GeocodingRateLimitedError
+	if errors.As(err, &rateLimited) {
+		if state.GeocodingDeferred >= mediaMetaMaxGeocodingDeferral {
+			fm.l.Warning("Geocoding is still rate limited after %d attempts, skipped: %s", state.GeocodingDeferred, err)
+			return task.StatusCompleted, nil
+		}
+
+		state.GeocodingDeferred++
+		newStateStr, marshalErr := json.Marshal(state)
+		if marshalErr != nil {
+			return task.StatusError, fmt.Errorf("failed to marshal state: %w", marshalErr)
+		}
+
+		fm.l.Info("Geocoding is rate limited, resume after %s.", rateLimited.RetryAfter)
+		m.Lock()
+		m.Task.PrivateState = string(newStateStr)
+		m.Unlock()
+		m.ResumeAfter(rateLimited.RetryAfter)
+		return task.StatusSuspending, nil
+	}
+
 	if err != nil {
 		return task.StatusError, err
 	}
@@ -114,6 +147,8 @@ func (m *manager) ExtractAndSaveMediaMeta(ctx context.Context, uri *fs.URI, enti
 
 	var (
 		metas []driver.MediaMeta
+		// geocodingErr is the rate limit error of geocoding, other metas are still saved.
+		geocodingErr error
 	)
 	// 2. try using native driver
 	_, d, err := m.getEntityPolicyDriver(ctx, targetVersion, nil)
@@ -136,20 +171,36 @@ func (m *manager) ExtractAndSaveMediaMeta(ctx context.Context, uri *fs.URI, enti
 			return fmt.Errorf("failed to get entity source: %w", err)
 		}
 
-		metas, err = extractor.Extract(ctx, file.Ext(), source, mediameta.WithLanguage(language))
+		var sidecar io.Reader
+		if m.settings.MediaMetaXMPEnabled(ctx) {
+			if sidecarSource := m.xmpSidecarSource(ctx, uri); sidecarSource != nil {
+				defer sidecarSource.Close()
+				sidecar = sidecarSource
+			}
+		}
+
+		metas, err = extractor.Extract(ctx, file.Ext(), source, mediameta.WithLanguage(language), mediameta.WithXMPSidecar(sidecar))
+		var rateLimited *mediameta.GeocodingRateLimitedError
+		if errors.As(err, &rateLimited) {
+			geocodingErr, err = err, nil
+		}
 		if err != nil {
 			return fmt.Errorf("failed to extract media meta using local extractor: %w", err)
 		}
 
 	} else {
 		m.l.Debug("No available generator for media meta.")
-		return nil
+	}
+
+	// 3. pair RAW/HEIC file with its JPEG sibling
+	if pairing := m.settings.MediaMetaPairing(ctx); pairing.Enabled {
+		metas = append(metas, m.pairMediaMeta(ctx, uri, file.Name(), pairing)...)
 	}
 
 	m.l.Debug("%d media meta generated.", len(metas))
 	m.l.Debug("Media meta: %v", metas)
 
-	// 3. save meta
+	// 4. save meta
 	if len(metas) > 0 {
 		if err := m.fs.PatchMetadata(ctx, []*fs.URI{uri}, lo.Map(metas, func(i driver.MediaMeta, index int) fs.MetadataPatch {
 			return fs.MetadataPatch{
@@ -161,10 +212,56 @@ func (m *manager) ExtractAndSaveMediaMeta(ctx context.Context, uri *fs.URI, enti
 		}
 	}
 
+	return geocodingErr
+}
+
+// pairMediaMeta finds the sibling captured together with given file, records the relationship on the sibling
+// and returns the media meta for given file.
+func (m *manager) pairMediaMeta(ctx context.Context, uri *fs.URI, name string, rules *setting.MediaMetaPairing) []driver.MediaMeta {
+	dir := uri.DirUri()
+	sibling, isPrimary, found := mediameta.FindPair(name, func(siblingName string) bool {
+		f, err := m.fs.Get(ctx, dir.Join(siblingName))
+		return err == nil && f.Type() == types.FileTypeFile
+	}, rules)
+	if !found {
+		return nil
+	}
+
+	reverse := mediameta.PairMeta(name, !isPrimary)
+	if err := m.fs.PatchMetadata(ctx, []*fs.URI{dir.Join(sibling)}, fs.MetadataPatch{
+		Key:   fmt.Sprintf("%s:%s", reverse.Type, reverse.Key),
+		Value: reverse.Value,
+	}); err != nil {
+		m.l.Warning("Failed to save pair media meta to sibling %q: %s", sibling, err)
+	}
+
+	return []driver.MediaMeta{mediameta.PairMeta(sibling, isPrimary)}
+}
+
+// xmpSidecarSource finds the sidecar .xmp file of given file, either named as IMG_0001.xmp or IMG_0001.CR2.xmp.
+func (m *manager) xmpSidecarSource(ctx context.Context, uri *fs.URI) entitysource.EntitySource {
+	name := uri.Name()
+	dir := uri.DirUri()
+	for _, candidate := range []string{strings.TrimSuffix(name, filepath.Ext(name)) + ".xmp", name + ".xmp"} {
+		f, err := m.fs.Get(ctx, dir.Join(candidate))
+		if err != nil || f.Type() != types.FileTypeFile {
+			continue
+		}
+
+		source, err := m.GetEntitySource(ctx, f.PrimaryEntityID())
+		if err != nil {
+			m.l.Debug("Failed to get entity source of xmp sidecar %q: %s", candidate, err)
+			continue
+		}
+
+		return source
+	}
+
 	return nil
 }
 
-func (m *manager) shouldGenerateMediaMeta(ctx context.Context, d driver.Handler, fileName string) bool {
+func (m *manager) shouldGenerateMediaMeta(ctx context.Context, d driver.Handler, uri *fs.URI) bool {
+	fileName := uri.Name()
 	driverCaps := d.Capabilities()
 	if util.IsInExtensionList(driverCaps.MediaMetaSupportedExts, fileName) {
 		// Handler support it natively
@@ -176,12 +273,22 @@ func (m *manager) shouldGenerateMediaMeta(ctx context.Context, d driver.Handler,
 		return true
 	}
 
+	// No meta can be extracted, but pair relationship still needs to be recorded if sibling exists.
+	if pairing := m.settings.MediaMetaPairing(ctx); pairing.Enabled {
+		dir := uri.DirUri()
+		_, _, found := mediameta.FindPair(fileName, func(siblingName string) bool {
+			f, err := m.fs.Get(ctx, dir.Join(siblingName))
+			return err == nil && f.Type() == types.FileTypeFile
+		}, pairing)
+		return found
+	}
+
 	return false
 }
 
 func (m *manager) mediaMetaForNewEntity(ctx context.Context, session *fs.UploadSession, d driver.Handler) {
 	if session.Props.EntityType == nil || *session.Props.EntityType == types.EntityTypeVersion {
-		if !m.shouldGenerateMediaMeta(ctx, d, session.Props.Uri.Name()) {
+		if !m.shouldGenerateMediaMeta(ctx, d, session.Props.Uri) {
 			return
 		}
 
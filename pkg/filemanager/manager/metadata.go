@@ -5,11 +5,15 @@ import (
 	"crypto/sha1"
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/buckket/go-blurhash"
 	"github.com/cloudreve/Cloudreve/v4/application/constants"
 	"github.com/cloudreve/Cloudreve/v4/application/dependency"
+	"github.com/cloudreve/Cloudreve/v4/inventory"
 	"github.com/cloudreve/Cloudreve/v4/inventory/types"
 	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/fs"
 	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/fs/dbfs"
@@ -30,6 +34,7 @@ const (
 	customPropsMetadataSuffix = "props"
 	iconColorMetadataKey      = customizeMetadataSuffix + ":icon_color"
 	emojiIconMetadataKey      = customizeMetadataSuffix + ":emoji"
+	FavoriteMetadataKey       = customizeMetadataSuffix + ":favorite"
 	shareOwnerMetadataKey     = dbfs.MetadataSysPrefix + "shared_owner"
 	shareRedirectMetadataKey  = dbfs.MetadataSysPrefix + "shared_redirect"
 )
@@ -99,12 +104,26 @@ var (
 		"dav": {},
 		// Allow manipulating thumbnail metadata via public PatchMetadata API
 		"thumb": {
-			// Only supported thumb metadata currently is thumb:disabled
 			dbfs.ThumbDisabledKey: func(ctx context.Context, m *manager, patch *fs.MetadataPatch) error {
 				// Presence of this key disables thumbnails; value is ignored.
 				// We allow both setting and removing this key.
 				return nil
 			},
+			dbfs.ThumbAnimatedDisabledKey: func(ctx context.Context, m *manager, patch *fs.MetadataPatch) error {
+				return nil
+			},
+			dbfs.ThumbDominantColorKey: validateColor(false),
+			dbfs.ThumbBlurhashKey: func(ctx context.Context, m *manager, patch *fs.MetadataPatch) error {
+				if patch.Remove {
+					return nil
+				}
+
+				if _, _, err := blurhash.Components(patch.Value); err != nil {
+					return fmt.Errorf("invalid blurhash: %w", err)
+				}
+
+				return nil
+			},
 		},
 		customizeMetadataSuffix: {
 			iconColorMetadataKey: validateColor(false),
@@ -137,6 +156,12 @@ var (
 				}
 				return nil
 			},
+			FavoriteMetadataKey: func(ctx context.Context, m *manager, patch *fs.MetadataPatch) error {
+				// Favorite is a flag, only presence of the key matters.
+				patch.Value = ""
+				patch.Private = false
+				return nil
+			},
 		},
 		tagMetadataSuffix: {
 			wildcardMetadataKey: func(ctx context.Context, m *manager, patch *fs.MetadataPatch) error {
@@ -146,8 +171,9 @@ var (
 					return err
 				}
 
-				if patch.Key == tagMetadataSuffix+":" {
-					return fmt.Errorf("invalid metadata key")
+				// Tags can be nested with path-style keys, e.g. `tag:travel/japan`.
+				if err := inventory.ValidateTagPath(strings.TrimPrefix(patch.Key, inventory.TagMetadataPrefix)); err != nil {
+					return fmt.Errorf("invalid metadata key: %w", err)
 				}
 
 				return nil
@@ -174,6 +200,14 @@ var (
 								return fmt.Errorf("value is too long")
 							}
 
+							re, err := prop.PatternRegexp()
+							if err != nil {
+								return err
+							}
+							if re != nil && patch.Value != "" && !re.MatchString(patch.Value) {
+								return fmt.Errorf("value of %q does not match pattern %q", prop.Name, prop.Pattern)
+							}
+
 							return nil
 						case types.CustomPropsTypeRating:
 							if patch.Value == "" {
@@ -266,6 +300,12 @@ var (
 							}
 
 							return nil
+						case types.CustomPropsTypeDate:
+							if patch.Value == "" {
+								return nil
+							}
+
+							return validateDateProp(prop, patch.Value)
 						default:
 							return nil
 						}
@@ -278,15 +318,172 @@ var (
 	}
 )
 
+// dateOnlyLayout is the accepted layout of date custom props without time part.
+const dateOnlyLayout = "2006-01-02"
+
+// validateDateProp checks the value is a RFC 3339 timestamp or a date, within prop's bounds.
+func validateDateProp(prop types.CustomProps, value string) error {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t, err = time.Parse(dateOnlyLayout, value)
+		if err != nil {
+			return fmt.Errorf("value is not a valid date")
+		}
+	}
+
+	if prop.Min != 0 && t.Before(time.Unix(int64(prop.Min), 0)) {
+		return fmt.Errorf("value is too early")
+	}
+	if prop.Max != 0 && t.After(time.Unix(int64(prop.Max), 0)) {
+		return fmt.Errorf("value is too late")
+	}
+
+	return nil
+}
+
 func (m *manager) PatchMedata(ctx context.Context, path []*fs.URI, data ...fs.MetadataPatch) error {
 	data, err := m.validateMetadata(ctx, data...)
 	if err != nil {
 		return err
 	}
 
+	if err := m.validatePropsScope(ctx, path, data); err != nil {
+		return err
+	}
+
 	return m.fs.PatchMetadata(ctx, path, data...)
 }
 
+// MetadataPatchItem is metadata patches of one file in a batch.
+type MetadataPatchItem struct {
+	URI     *fs.URI
+	Patches []fs.MetadataPatch
+}
+
+func (m *manager) PatchMetadataBatch(ctx context.Context, items []MetadataPatchItem) error {
+	ae := serializer.NewAggregateError()
+	validated := make([]MetadataPatchItem, 0, len(items))
+	for _, item := range items {
+		patches, err := m.validateMetadata(ctx, item.Patches...)
+		if err == nil {
+			err = m.validatePropsScope(ctx, []*fs.URI{item.URI}, patches)
+		}
+		if err != nil {
+			ae.Add(item.URI.String(), err)
+			continue
+		}
+
+		validated = append(validated, MetadataPatchItem{URI: item.URI, Patches: patches})
+	}
+
+	if len(validated) == 0 {
+		return ae.Aggregate()
+	}
+
+	// Apply all items in one transaction first. If any of them fails, the transaction is rolled
+	// back and items are applied one by one so that failures do not affect other items.
+	_, tx, txCtx, err := inventory.WithTx(ctx, m.dep.FileClient())
+	if err == nil {
+		failed := false
+		for _, item := range validated {
+			if err := m.fs.PatchMetadata(txCtx, []*fs.URI{item.URI}, item.Patches...); err != nil {
+				failed = true
+				break
+			}
+		}
+
+		if !failed {
+			if err := inventory.Commit(tx); err == nil {
+				return ae.Aggregate()
+			}
+		} else {
+			_ = inventory.Rollback(tx)
+		}
+	}
+
+	for _, item := range validated {
+		if err := m.fs.PatchMetadata(ctx, []*fs.URI{item.URI}, item.Patches...); err != nil {
+			if !ae.Merge(err) {
+				ae.Add(item.URI.String(), err)
+			}
+		}
+	}
+
+	return ae.Aggregate()
+}
+
+// SetFavorite adds or removes favorite flag of given files.
+func (m *manager) SetFavorite(ctx context.Context, path []*fs.URI, favorite bool) error {
+	return m.PatchMedata(ctx, path, fs.MetadataPatch{Key: FavoriteMetadataKey, Remove: !favorite})
+}
+
+// ListFavorites searches the whole "my" file system for files with favorite flag. Favorite
+// flag is stored as metadata, so it follows the file when moved or renamed.
+func (m *manager) ListFavorites(ctx context.Context, args *ListArgs) (fs.File, *fs.ListFileResult, error) {
+	root, err := fs.NewUriFromString(fs.NewMyUri(""))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	q := url.Values{}
+	q.Set(fs.QuerySearchMetadataPrefix+FavoriteMetadataKey, "")
+	return m.List(ctx, root.SetQuery(q.Encode()), args)
+}
+
+func (m *manager) ListTagTree(ctx context.Context) ([]*inventory.TagNode, error) {
+	tags, err := m.dep.FileClient().ListTags(ctx, m.user.ID)
+	if err != nil {
+		return nil, serializer.NewError(serializer.CodeDBError, "Failed to list tags", err)
+	}
+
+	return inventory.BuildTagTree(tags), nil
+}
+
+// validatePropsScope rejects custom props set on files out of the prop's file type or
+// extension scope.
+func (m *manager) validatePropsScope(ctx context.Context, path []*fs.URI, data []fs.MetadataPatch) error {
+	var (
+		scoped []types.CustomProps
+		props  []types.CustomProps
+	)
+	for _, patch := range data {
+		if patch.Remove || !strings.HasPrefix(patch.Key, inventory.CustomPropsMetadataPrefix) {
+			continue
+		}
+
+		if props == nil {
+			props = m.settings.CustomProps(ctx)
+		}
+
+		prop, ok := lo.Find(props, func(p types.CustomProps) bool {
+			return p.ID == strings.TrimPrefix(patch.Key, inventory.CustomPropsMetadataPrefix)
+		})
+		if ok && (prop.FileType != "" || len(prop.Exts) > 0) {
+			scoped = append(scoped, prop)
+		}
+	}
+
+	if len(scoped) == 0 {
+		return nil
+	}
+
+	for _, uri := range path {
+		file, err := m.fs.Get(ctx, uri)
+		if err != nil {
+			return err
+		}
+
+		for _, prop := range scoped {
+			if !prop.AppliesTo(file.Type(), file.Name()) {
+				return serializer.NewError(serializer.CodeParamErr, "Invalid metadata patch",
+					fmt.Errorf("custom props %q does not apply to %q", prop.Name, file.Name()))
+			}
+		}
+	}
+
+	return nil
+}
+
 func (m *manager) validateMetadata(ctx context.Context, data ...fs.MetadataPatch) ([]fs.MetadataPatch, error) {
 	validated := make([]fs.MetadataPatch, 0, len(data))
 	for _, patch := range data {
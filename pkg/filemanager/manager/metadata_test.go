@@ -0,0 +1,193 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cloudreve/Cloudreve/v4/application/dependency"
+	"github.com/cloudreve/Cloudreve/v4/ent/enttest"
+	"github.com/cloudreve/Cloudreve/v4/inventory"
+	"github.com/cloudreve/Cloudreve/v4/inventory/types"
+	"github.com/cloudreve/Cloudreve/v4/pkg/conf"
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/fs"
+	"github.com/cloudreve/Cloudreve/v4/pkg/serializer"
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateDateProp(t *testing.T) {
+	a := assert.New(t)
+	prop := types.CustomProps{ID: "due", Type: types.CustomPropsTypeDate}
+
+	// Valid formats
+	a.NoError(validateDateProp(prop, "2024-05-01"))
+	a.NoError(validateDateProp(prop, "2024-05-01T10:20:30Z"))
+	a.NoError(validateDateProp(prop, "2024-05-01T10:20:30+08:00"))
+
+	// Invalid formats
+	a.Error(validateDateProp(prop, "2024-13-01"))
+	a.Error(validateDateProp(prop, "2024/05/01"))
+	a.Error(validateDateProp(prop, "2024-05-01 10:20:30"))
+	a.Error(validateDateProp(prop, "yesterday"))
+
+	// Bounds
+	prop.Min = int(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Unix())
+	prop.Max = int(time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC).Unix())
+	a.NoError(validateDateProp(prop, "2024-01-01"))
+	a.NoError(validateDateProp(prop, "2024-12-31"))
+	a.Error(validateDateProp(prop, "2023-12-31T23:59:59Z"))
+	a.Error(validateDateProp(prop, "2024-12-31T00:00:01Z"))
+	// Time zone is respected, this is 2023-12-31T23:59:59Z
+	a.Error(validateDateProp(prop, "2024-01-01T07:59:59+08:00"))
+}
+
+func TestCustomPropsDate_Serialize(t *testing.T) {
+	a := assert.New(t)
+	props := []types.CustomProps{{ID: "shot_on", Name: "Shot on", Type: types.CustomPropsTypeDate, Min: 946684800}}
+	raw, err := json.Marshal(props)
+	a.NoError(err)
+	a.JSONEq(`[{"id":"shot_on","name":"Shot on","type":"date","min":946684800}]`, string(raw))
+
+	var decoded []types.CustomProps
+	a.NoError(json.Unmarshal(raw, &decoded))
+	a.Equal(props, decoded)
+}
+
+type batchPatchFs struct {
+	fs.FileSystem
+	failing string
+	applied map[string][]fs.MetadataPatch
+	inTx    []bool
+}
+
+func (f *batchPatchFs) PatchMetadata(ctx context.Context, path []*fs.URI, metas ...fs.MetadataPatch) error {
+	_, inTx := ctx.Value(inventory.TxCtx{}).(*inventory.Tx)
+	f.inTx = append(f.inTx, inTx)
+	if path[0].String() == f.failing {
+		return fmt.Errorf("permission denied")
+	}
+
+	f.applied[path[0].String()] = metas
+	return nil
+}
+
+func TestManager_PatchMetadataBatch(t *testing.T) {
+	a := assert.New(t)
+	client := enttest.Open(t, "sqlite3", "file:"+t.Name()+"?mode=memory&cache=shared")
+	defer client.Close()
+	dep := dependency.NewDependency(dependency.WithFileClient(inventory.NewFileClient(client, conf.SQLiteDB, nil)))
+	uri := func(name string) *fs.URI {
+		u, _ := fs.NewUriFromString("cloudreve://my/" + name)
+		return u
+	}
+	tag := func(value string) []fs.MetadataPatch {
+		return []fs.MetadataPatch{{Key: "tag:project", Value: value}}
+	}
+
+	// All items applied in one transaction
+	fake := &batchPatchFs{applied: map[string][]fs.MetadataPatch{}}
+	m := &manager{dep: dep, fs: fake}
+	a.NoError(m.PatchMetadataBatch(context.Background(), []MetadataPatchItem{
+		{URI: uri("a.txt"), Patches: tag("")},
+		{URI: uri("b.txt"), Patches: tag("")},
+	}))
+	a.Len(fake.applied, 2)
+	a.Equal([]bool{true, true}, fake.inTx)
+
+	// Failures are reported per item, other items are still applied
+	fake = &batchPatchFs{applied: map[string][]fs.MetadataPatch{}, failing: uri("b.txt").String()}
+	m.fs = fake
+	err := m.PatchMetadataBatch(context.Background(), []MetadataPatchItem{
+		{URI: uri("a.txt"), Patches: tag("")},
+		{URI: uri("b.txt"), Patches: tag("")},
+		{URI: uri("c.txt"), Patches: []fs.MetadataPatch{{Key: "unknown:key", Value: "v"}}},
+		{URI: uri("d.txt"), Patches: tag("")},
+	})
+	var ae *serializer.AggregateError
+	if a.ErrorAs(err, &ae) {
+		a.Len(ae.Raw(), 2)
+		a.Contains(ae.Raw(), uri("b.txt").String())
+		a.Contains(ae.Raw(), uri("c.txt").String())
+	}
+	a.Len(fake.applied, 2)
+	a.Contains(fake.applied, uri("a.txt").String())
+	a.Contains(fake.applied, uri("d.txt").String())
+}
+
+type scopedPropsSettings struct {
+	setting.Provider
+	props []types.CustomProps
+}
+
+func (s *scopedPropsSettings) CustomProps(ctx context.Context) []types.CustomProps {
+	return s.props
+}
+
+type namedFile struct {
+	fs.File
+	name     string
+	fileType types.FileType
+}
+
+func (f *namedFile) Name() string         { return f.name }
+func (f *namedFile) Type() types.FileType { return f.fileType }
+
+type scopedPropsFs struct {
+	batchPatchFs
+	files map[string]fs.File
+}
+
+func (f *scopedPropsFs) Get(ctx context.Context, path *fs.URI, opts ...fs.Option) (fs.File, error) {
+	return f.files[path.String()], nil
+}
+
+func TestManager_PatchMetadata_PropsScope(t *testing.T) {
+	a := assert.New(t)
+	uri := func(name string) *fs.URI {
+		u, _ := fs.NewUriFromString("cloudreve://my/" + name)
+		return u
+	}
+	fake := &scopedPropsFs{
+		batchPatchFs: batchPatchFs{applied: map[string][]fs.MetadataPatch{}},
+		files: map[string]fs.File{
+			uri("a.jpg").String(): &namedFile{name: "a.jpg", fileType: types.FileTypeFile},
+			uri("b.txt").String(): &namedFile{name: "b.txt", fileType: types.FileTypeFile},
+			uri("album").String(): &namedFile{name: "album", fileType: types.FileTypeFolder},
+		},
+	}
+	m := &manager{fs: fake, settings: &scopedPropsSettings{props: []types.CustomProps{
+		{ID: "camera", Name: "Camera", Type: types.CustomPropsTypeText, FileType: "file", Exts: []string{"jpg"}},
+		{ID: "note", Name: "Note", Type: types.CustomPropsTypeText},
+	}}}
+	camera := fs.MetadataPatch{Key: "props:camera", Value: "X100"}
+	ctx := context.Background()
+
+	a.NoError(m.PatchMedata(ctx, []*fs.URI{uri("a.jpg")}, camera))
+	a.Error(m.PatchMedata(ctx, []*fs.URI{uri("a.jpg"), uri("b.txt")}, camera))
+	a.Error(m.PatchMedata(ctx, []*fs.URI{uri("album")}, camera))
+
+	// Unscoped props and removals are not checked
+	a.NoError(m.PatchMedata(ctx, []*fs.URI{uri("b.txt"), uri("album")}, fs.MetadataPatch{Key: "props:note", Value: "hi"}))
+	a.NoError(m.PatchMedata(ctx, []*fs.URI{uri("b.txt")}, fs.MetadataPatch{Key: "props:camera", Remove: true}))
+}
+
+func TestManager_SetFavorite(t *testing.T) {
+	a := assert.New(t)
+	u, _ := fs.NewUriFromString("cloudreve://my/a.txt")
+	fake := &batchPatchFs{applied: map[string][]fs.MetadataPatch{}}
+	m := &manager{fs: fake}
+	ctx := context.Background()
+
+	a.NoError(m.SetFavorite(ctx, []*fs.URI{u}, true))
+	a.Equal([]fs.MetadataPatch{{Key: FavoriteMetadataKey}}, fake.applied[u.String()])
+
+	a.NoError(m.SetFavorite(ctx, []*fs.URI{u}, false))
+	a.Equal([]fs.MetadataPatch{{Key: FavoriteMetadataKey, Remove: true}}, fake.applied[u.String()])
+
+	// Value of favorite flag is ignored
+	a.NoError(m.PatchMedata(ctx, []*fs.URI{u}, fs.MetadataPatch{Key: FavoriteMetadataKey, Value: "yes"}))
+	a.Equal([]fs.MetadataPatch{{Key: FavoriteMetadataKey}}, fake.applied[u.String()])
+}
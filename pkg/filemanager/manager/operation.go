@@ -226,6 +226,14 @@ func (l *manager) Refresh(ctx context.Context, d time.Duration, token string) (l
 	return l.fs.Refresh(ctx, d, token)
 }
 
+func (l *manager) Relock(ctx context.Context, d time.Duration, oldToken, newToken string) (lock.LockDetails, error) {
+	return l.fs.Relock(ctx, d, oldToken, newToken)
+}
+
+func (l *manager) PeekLock(ctx context.Context, ancestor fs.File, uri *fs.URI) *lock.ConflictDetail {
+	return l.fs.PeekLock(ctx, ancestor, uri)
+}
+
 func (l *manager) Restore(ctx context.Context, path ...*fs.URI) error {
 	return l.fs.Restore(ctx, path...)
 }
@@ -330,6 +338,8 @@ func getEntityDisplayName(f fs.File, e fs.Entity) string {
 	switch e.Type() {
 	case types.EntityTypeThumbnail:
 		return fmt.Sprintf("%s_thumbnail", f.DisplayName())
+	case types.EntityTypeAnimatedThumbnail:
+		return fmt.Sprintf("%s_animated_thumbnail", f.DisplayName())
 	case types.EntityTypeLivePhoto:
 		return fmt.Sprintf("%s_live_photo.mov", f.DisplayName())
 	default:
@@ -10,6 +10,7 @@ import (
 
 	"github.com/cloudreve/Cloudreve/v4/application/dependency"
 	"github.com/cloudreve/Cloudreve/v4/ent"
+	"github.com/cloudreve/Cloudreve/v4/ent/schema"
 	"github.com/cloudreve/Cloudreve/v4/ent/task"
 	"github.com/cloudreve/Cloudreve/v4/inventory"
 	"github.com/cloudreve/Cloudreve/v4/inventory/types"
@@ -93,6 +94,8 @@ func newExplicitEntityRecycleTask(ctx context.Context, entities []int) (*Explici
 }
 
 func (m *ExplicitEntityRecycleTask) Do(ctx context.Context) (task.Status, error) {
+	// Entities may still be referenced by files in trash bin.
+	ctx = schema.IncludeDeletedFiles(ctx)
 	dep := dependency.FromContext(ctx)
 	fm := NewFileManager(dep, inventory.UserFromContext(ctx)).(*manager)
 
@@ -161,6 +164,8 @@ func NewEntityRecycleRoutineTask(ctx context.Context) (queue.Task, error) {
 }
 
 func (m *EntityRecycleRoutineTask) Do(ctx context.Context) (task.Status, error) {
+	// Entities may still be referenced by files in trash bin.
+	ctx = schema.IncludeDeletedFiles(ctx)
 	dep := dependency.FromContext(ctx)
 	fm := NewFileManager(dep, inventory.UserFromContext(ctx)).(*manager)
 
@@ -295,6 +300,8 @@ const (
 
 // CronCollectTrashBin walks through all files in trash bin and delete them if they are expired.
 func CronCollectTrashBin(ctx context.Context) {
+	// Files to collect are soft-deleted, they must be visible to all queries below.
+	ctx = schema.IncludeDeletedFiles(ctx)
 	dep := dependency.FromContext(ctx)
 	l := dep.Logger()
 
@@ -324,9 +331,13 @@ func CronCollectTrashBin(ctx context.Context) {
 				if expireUnix < time.Now().Unix() {
 					return true
 				}
+
+				return false
 			}
 
-			return false
+			// Soft-deleted files without expected collect time, e.g. metadata failed to be saved,
+			// are checked against TrashRetention of owner's group in collectTrashBin.
+			return deletedAt(file) != nil
 		})
 		l.Info("Found %d files in trash bin pending collect, in batch #%d", len(res.Files), batch)
 
@@ -364,6 +375,18 @@ func collectTrashBin(ctx context.Context, files []fs.File, dep dependency.Dep, l
 			continue
 		}
 
+		expiredFiles = lo.Filter(expiredFiles, func(file fs.File, index int) bool {
+			if _, ok := file.Metadata()[dbfs.MetadataExpectedCollectTime]; ok {
+				return true
+			}
+
+			retention := time.Duration(user.Edges.Group.Settings.TrashRetention) * time.Second
+			return deletedAt(file).Add(retention).Before(time.Now())
+		})
+		if len(expiredFiles) == 0 {
+			continue
+		}
+
 		ctx = context.WithValue(ctx, inventory.UserCtx{}, user)
 		fm := NewFileManager(dep, user).(*manager)
 		if err := fm.Delete(ctx, lo.Map(expiredFiles, func(file fs.File, index int) *fs.URI {
@@ -374,6 +397,15 @@ func collectTrashBin(ctx context.Context, files []fs.File, dep dependency.Dep, l
 	}
 }
 
+// deletedAt returns the soft-delete time of given file, nil if the file is not soft-deleted.
+func deletedAt(file fs.File) *time.Time {
+	if f, ok := file.(*dbfs.File); ok && f.Model != nil {
+		return f.Model.DeletedAt
+	}
+
+	return nil
+}
+
 func appendAe(errs *[][]RecycleError, err error) {
 	var ae *serializer.AggregateError
 	*errs = append(*errs, make([]RecycleError, 0))
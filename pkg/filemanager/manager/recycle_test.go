@@ -0,0 +1,135 @@
+package manager
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/cloudreve/Cloudreve/v4/application/dependency"
+	"github.com/cloudreve/Cloudreve/v4/ent"
+	"github.com/cloudreve/Cloudreve/v4/ent/enttest"
+	"github.com/cloudreve/Cloudreve/v4/ent/metadata"
+	"github.com/cloudreve/Cloudreve/v4/ent/schema"
+	"github.com/cloudreve/Cloudreve/v4/inventory"
+	"github.com/cloudreve/Cloudreve/v4/inventory/types"
+	"github.com/cloudreve/Cloudreve/v4/pkg/boolset"
+	"github.com/cloudreve/Cloudreve/v4/pkg/cache"
+	"github.com/cloudreve/Cloudreve/v4/pkg/conf"
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/fs"
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/fs/dbfs"
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
+	"github.com/stretchr/testify/assert"
+)
+
+type masterModeConfig struct {
+	conf.ConfigProvider
+}
+
+func (c *masterModeConfig) System() *conf.System {
+	return &conf.System{Mode: conf.MasterMode}
+}
+
+func (c *masterModeConfig) Database() *conf.Database {
+	return &conf.Database{Type: conf.SQLiteDB}
+}
+
+// trashBinFiles returns all files in trash bin of given file manager.
+func trashBinFiles(t *testing.T, ctx context.Context, fm *manager) []fs.File {
+	res, err := fm.fs.AllFilesInTrashBin(ctx, fs.WithPageSize(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return res.Files
+}
+
+// dbTestEnv is a file manager backed by in-memory SQLite, for a user with an empty root folder.
+type dbTestEnv struct {
+	ctx    context.Context
+	client *ent.Client
+	dep    dependency.Dep
+	fm     *manager
+	root   *ent.File
+}
+
+func newDBTestEnv(t *testing.T) *dbTestEnv {
+	client := enttest.Open(t, "sqlite3", "file:"+t.Name()+"?mode=memory&cache=shared")
+	t.Cleanup(func() { _ = client.Close() })
+
+	ctx := context.Background()
+	l := logging.NewConsoleLogger(logging.LevelError)
+	dep := dependency.NewDependency(
+		dependency.WithDbClient(client),
+		dependency.WithLogger(l),
+		dependency.WithConfigProvider(&masterModeConfig{}),
+		dependency.WithKV(cache.NewMemoStore("", l)),
+		dependency.WithSettingProvider(setting.NewProvider(setting.NewDbDefaultStore(nil))),
+	)
+
+	g := client.Group.Create().SetName("g").SetPermissions(&boolset.BooleanSet{}).SetSettings(&types.GroupSetting{MaxWalkedFiles: 1000}).SaveX(ctx)
+	u := client.User.Create().SetEmail("a@example.com").SetNick("a").SetGroup(g).SaveX(ctx)
+	root := client.File.Create().SetName(inventory.RootFolderName).SetType(int(types.FileTypeFolder)).SetOwner(u).SaveX(ctx)
+
+	u, err := dep.UserClient().GetByID(context.WithValue(ctx, inventory.LoadUserGroup{}, true), u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx = context.WithValue(ctx, dependency.DepCtx{}, dep)
+	ctx = context.WithValue(ctx, inventory.UserCtx{}, u)
+
+	return &dbTestEnv{
+		ctx:    ctx,
+		client: client,
+		dep:    dep,
+		fm:     NewFileManager(dep, u).(*manager),
+		root:   root,
+	}
+}
+
+// createFile creates a file or folder without entities under given parent.
+func (e *dbTestEnv) createFile(parent *ent.File, name string, fileType types.FileType) *ent.File {
+	return e.client.File.Create().SetName(name).SetType(int(fileType)).SetOwnerID(e.root.OwnerID).SetParent(parent).SaveX(e.ctx)
+}
+
+func TestCronCollectTrashBin_TrashRestoreCollect(t *testing.T) {
+	a := assert.New(t)
+	env := newDBTestEnv(t)
+	ctx, client, dep, fm := env.ctx, env.client, env.dep, env.fm
+	f := env.createFile(env.root, "a.txt", types.FileTypeFile)
+	uri, err := fs.NewUriFromString("cloudreve://my/a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Trash
+	a.NoError(fm.Delete(ctx, []*fs.URI{uri}))
+	_, err = dep.FileClient().GetByID(ctx, f.ID)
+	a.Error(err)
+	trashed := trashBinFiles(t, ctx, fm)
+	a.Len(trashed, 1)
+
+	// Restore
+	a.NoError(fm.Restore(ctx, trashed[0].Uri(false)))
+	restored, err := dep.FileClient().GetByID(ctx, f.ID)
+	a.NoError(err)
+	a.Nil(restored.DeletedAt)
+	a.Equal("a.txt", restored.Name)
+	a.Empty(trashBinFiles(t, ctx, fm))
+
+	// Trash again, not yet expired
+	a.NoError(fm.Delete(ctx, []*fs.URI{uri}))
+	CronCollectTrashBin(ctx)
+	a.Len(trashBinFiles(t, ctx, fm), 1)
+
+	// Collect once expired
+	client.Metadata.Update().
+		Where(metadata.Name(dbfs.MetadataExpectedCollectTime)).
+		SetValue(strconv.FormatInt(time.Now().Add(-time.Minute).Unix(), 10)).
+		ExecX(ctx)
+	CronCollectTrashBin(ctx)
+	a.Empty(trashBinFiles(t, ctx, fm))
+	_, err = dep.FileClient().GetByID(schema.IncludeDeletedFiles(ctx), f.ID)
+	a.Error(err)
+}
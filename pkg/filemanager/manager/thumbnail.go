@@ -2,28 +2,50 @@ package manager
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"github.com/cloudreve/Cloudreve/v4/pkg/thumb"
+	"io"
 	"os"
+	"path/filepath"
 	"runtime"
 	"time"
 
 	"github.com/cloudreve/Cloudreve/v4/ent"
 	"github.com/cloudreve/Cloudreve/v4/ent/task"
+	"github.com/cloudreve/Cloudreve/v4/inventory"
 	"github.com/cloudreve/Cloudreve/v4/inventory/types"
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/driver"
 	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/driver/local"
 	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/fs"
 	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/fs/dbfs"
 	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/manager/entitysource"
 	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/cloudreve/Cloudreve/v4/pkg/mediameta"
 	"github.com/cloudreve/Cloudreve/v4/pkg/queue"
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
 	"github.com/cloudreve/Cloudreve/v4/pkg/util"
 	"github.com/samber/lo"
 )
 
+const (
+	// ThumbContentCachePrefix is the KV prefix of thumbnail entity IDs keyed by content hash of the
+	// source entity and thumbnail encode settings.
+	ThumbContentCachePrefix = "thumb_content_"
+	// thumbContentCacheTTL is the TTL in seconds of thumbnail entity IDs cached by content hash.
+	thumbContentCacheTTL = 7 * 24 * 3600
+)
+
 // Thumbnail returns the thumbnail entity of the file.
 func (m *manager) Thumbnail(ctx context.Context, uri *fs.URI) (entitysource.EntitySource, error) {
+	return m.thumbnail(ctx, uri, true)
+}
+
+// thumbnail returns the thumbnail entity of the file, followCompanion controls whether thumbnail of the
+// paired JPEG companion is preferred. Companion's own pairing is never followed.
+func (m *manager) thumbnail(ctx context.Context, uri *fs.URI, followCompanion bool) (entitysource.EntitySource, error) {
 	// retrieve file info
 	file, err := m.fs.Get(ctx, uri, dbfs.WithFileEntities(), dbfs.WithFilePublicMetadata())
 	if err != nil {
@@ -50,6 +72,15 @@ func (m *manager) Thumbnail(ctx context.Context, uri *fs.URI) (entitysource.Enti
 		return thumbSource, nil
 	}
 
+	// Prefer thumbnail of the JPEG companion for RAW/HEIC files.
+	if followCompanion {
+		if companionUri := m.thumbCompanion(ctx, uri, file); companionUri != nil {
+			if thumbSource, err := m.thumbnail(ctx, companionUri, false); err == nil {
+				return thumbSource, nil
+			}
+		}
+	}
+
 	latest := file.PrimaryEntity()
 	// If primary entity not exist, or it's empty
 	if latest == nil || latest.ID() == 0 {
@@ -98,6 +129,29 @@ func (m *manager) Thumbnail(ctx context.Context, uri *fs.URI) (entitysource.Enti
 	return nil, fs.ErrEntityNotExist
 }
 
+// thumbCompanion returns uri of the paired JPEG companion whose thumbnail can be used for file, nil if
+// pairing is disabled or the companion resolves to the file itself or another primary file.
+func (m *manager) thumbCompanion(ctx context.Context, uri *fs.URI, file fs.File) *fs.URI {
+	pairing := m.settings.MediaMetaPairing(ctx)
+	companion := file.Metadata()[fmt.Sprintf("%s:%s", driver.MetaTypePair, mediameta.PairCompanion)]
+	if !pairing.Enabled || companion == "" {
+		return nil
+	}
+
+	companionExt := util.Ext(companion)
+	if companionExt == file.Ext() || lo.Contains(pairing.PrimaryExts, companionExt) {
+		return nil
+	}
+
+	companionUri := uri.DirUri().Join(companion)
+	companionFile, err := m.fs.Get(ctx, companionUri)
+	if err != nil || companionFile.ID() == file.ID() {
+		return nil
+	}
+
+	return companionUri
+}
+
 func (m *manager) SubmitAndAwaitThumbnailTask(ctx context.Context, uri *fs.URI, ext string, entity fs.Entity) (fs.Entity, error) {
 	es, err := m.GetEntitySource(ctx, 0, fs.WithEntity(entity))
 	if err != nil {
@@ -105,7 +159,11 @@ func (m *manager) SubmitAndAwaitThumbnailTask(ctx context.Context, uri *fs.URI,
 	}
 
 	defer es.Close()
-	t := newGenerateThumbTask(ctx, m, uri, ext, es)
+	return m.awaitThumbTask(ctx, newGenerateThumbTask(ctx, m, uri, ext, es))
+}
+
+// awaitThumbTask submits a thumbnail task to thumb queue and waits for result.
+func (m *manager) awaitThumbTask(ctx context.Context, t *GenerateThumbTask) (fs.Entity, error) {
 	if err := m.dep.ThumbQueue(ctx).QueueTask(ctx, t); err != nil {
 		return nil, fmt.Errorf("failed to queue task: %w", err)
 	}
@@ -125,9 +183,24 @@ func (m *manager) SubmitAndAwaitThumbnailTask(ctx context.Context, uri *fs.URI,
 }
 
 func (m *manager) generateThumb(ctx context.Context, uri *fs.URI, ext string, es entitysource.EntitySource) (fs.Entity, error) {
+	// Reuse thumbnail generated for the same content
+	contentKey := ""
+	if !m.stateless && uri != nil {
+		contentKey = m.thumbContentKey(ctx, es)
+		if contentKey != "" {
+			if thumbEntity := m.reuseThumb(ctx, uri, contentKey); thumbEntity != nil {
+				return thumbEntity, nil
+			}
+		}
+	}
+
 	// Generate thumb
 	pipeline := m.dep.ThumbPipeline()
-	res, err := pipeline.Generate(ctx, es, ext, nil)
+	genCtx := ctx
+	if !m.stateless && uri != nil {
+		genCtx = context.WithValue(ctx, thumb.SiblingOpenerCtx{}, m.siblingOpener(uri))
+	}
+	res, err := pipeline.Generate(genCtx, es, ext, nil)
 	if err != nil {
 		if res != nil && res.Path != "" {
 			_ = os.Remove(res.Path)
@@ -179,36 +252,19 @@ func (m *manager) generateThumb(ctx context.Context, uri *fs.URI, ext string, es
 			return nil, fmt.Errorf("failed to create local thumb entity: %w", err)
 		}
 	} else {
-		entityType := types.EntityTypeThumbnail
-		req := &fs.UploadRequest{
-			Props: &fs.UploadProps{
-				Uri:        uri,
-				Size:       fileInfo.Size(),
-				SavePath:   es.Entity().Source() + m.settings.ThumbEntitySuffix(ctx),
-				MimeType:   m.dep.MimeDetector(ctx).TypeByName("thumb.jpg"),
-				EntityType: &entityType,
-			},
-			File:   thumbFile,
-			Seeker: thumbFile,
-		}
-
-		// Generating thumb can be triggered by users with read-only permission. We can bypass update permission check.
-		ctx = dbfs.WithBypassOwnerCheck(ctx)
-
-		file, err := m.Update(ctx, req, fs.WithEntityType(types.EntityTypeThumbnail))
+		thumbEntity, err = m.uploadThumbEntity(ctx, uri, thumbFile, fileInfo.Size(), types.EntityTypeThumbnail,
+			es.Entity().Source()+m.settings.ThumbEntitySuffix(ctx), "thumb.jpg")
 		if err != nil {
-			return nil, fmt.Errorf("failed to upload thumb entity: %w", err)
+			return nil, err
 		}
 
-		entities := file.Entities()
-		found := false
-		thumbEntity, found = lo.Find(entities, func(e fs.Entity) bool {
-			return e.Type() == types.EntityTypeThumbnail
-		})
-		if !found {
-			return nil, fmt.Errorf("failed to find thumb entity")
+		if contentKey != "" {
+			_ = m.kv.Set(contentKey, thumbEntity.ID(), thumbContentCacheTTL)
 		}
 
+		if len(res.Metadata) > 0 {
+			m.saveThumbMetadata(ctx, uri, res.Metadata)
+		}
 	}
 
 	if m.settings.ThumbGCAfterGen(ctx) {
@@ -219,6 +275,226 @@ func (m *manager) generateThumb(ctx context.Context, uri *fs.URI, ext string, es
 	return thumbEntity, nil
 }
 
+// saveThumbMetadata saves metadata extracted by thumb generators to the file with thumb prefix.
+func (m *manager) saveThumbMetadata(ctx context.Context, uri *fs.URI, metadata map[string]string) {
+	patches := make([]fs.MetadataPatch, 0, len(metadata))
+	for k, v := range metadata {
+		patches = append(patches, fs.MetadataPatch{Key: dbfs.ThumbMetadataPrefix + k, Value: v})
+	}
+
+	if err := m.fs.PatchMetadata(dbfs.WithBypassOwnerCheck(ctx), []*fs.URI{uri}, patches...); err != nil {
+		m.l.Warning("Failed to save thumb metadata: %s", err)
+	}
+}
+
+// uploadThumbEntity uploads a generated thumbnail file as entity of given type to the file.
+func (m *manager) uploadThumbEntity(ctx context.Context, uri *fs.URI, thumbFile *os.File, size int64,
+	entityType types.EntityType, savePath, mimeName string) (fs.Entity, error) {
+	req := &fs.UploadRequest{
+		Props: &fs.UploadProps{
+			Uri:        uri,
+			Size:       size,
+			SavePath:   savePath,
+			MimeType:   m.dep.MimeDetector(ctx).TypeByName(mimeName),
+			EntityType: &entityType,
+		},
+		File:   thumbFile,
+		Seeker: thumbFile,
+	}
+
+	// Generating thumb can be triggered by users with read-only permission. We can bypass update permission check.
+	ctx = dbfs.WithBypassOwnerCheck(ctx)
+
+	file, err := m.Update(ctx, req, fs.WithEntityType(entityType))
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload thumb entity: %w", err)
+	}
+
+	thumbEntity, found := lo.Find(file.Entities(), func(e fs.Entity) bool {
+		return e.Type() == entityType
+	})
+	if !found {
+		return nil, fmt.Errorf("failed to find thumb entity")
+	}
+
+	return thumbEntity, nil
+}
+
+// AnimatedThumbnail returns the animated preview entity of a video file, it is generated on demand
+// if not exist yet.
+func (m *manager) AnimatedThumbnail(ctx context.Context, uri *fs.URI) (entitysource.EntitySource, error) {
+	if m.stateless || !m.settings.FFMpegThumbGeneratorEnabled(ctx) || !m.settings.FFMpegAnimatedThumb(ctx).Enabled {
+		return nil, fs.ErrEntityNotExist
+	}
+
+	file, err := m.fs.Get(ctx, uri, dbfs.WithFileEntities(), dbfs.WithFilePublicMetadata())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file: %w", err)
+	}
+
+	// Animated preview is not retried once failed.
+	metadata := file.Metadata()
+	if _, ok := metadata[dbfs.ThumbDisabledKey]; ok || file.Type() != types.FileTypeFile {
+		return nil, fs.ErrEntityNotExist
+	}
+	if _, ok := metadata[dbfs.ThumbAnimatedDisabledKey]; ok {
+		return nil, fs.ErrEntityNotExist
+	}
+
+	animatedEntity, found := lo.Find(file.Entities(), func(e fs.Entity) bool {
+		return e.Type() == types.EntityTypeAnimatedThumbnail
+	})
+	if !found {
+		latest := file.PrimaryEntity()
+		if latest == nil || latest.ID() == 0 || !util.IsInExtensionList(m.settings.FFMpegThumbExts(ctx), file.DisplayName()) {
+			return nil, fs.ErrEntityNotExist
+		}
+
+		if err := m.fs.CheckCapability(ctx, uri,
+			dbfs.WithRequiredCapabilities(dbfs.NavigatorCapabilityGenerateThumb)); err != nil {
+			return nil, fs.ErrEntityNotExist
+		}
+
+		es, err := m.GetEntitySource(ctx, 0, fs.WithEntity(latest))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get entity source: %w", err)
+		}
+
+		defer es.Close()
+		t := newGenerateThumbTask(ctx, m, uri, file.Ext(), es)
+		t.animated = true
+		animatedEntity, err = m.awaitThumbTask(ctx, t)
+		if err != nil {
+			return nil, fmt.Errorf("failed to execute animated thumb task: %w", err)
+		}
+	}
+
+	return m.GetEntitySource(ctx, 0, fs.WithEntity(animatedEntity))
+}
+
+func (m *manager) generateAnimatedThumb(ctx context.Context, uri *fs.URI, ext string, es entitysource.EntitySource) (fs.Entity, error) {
+	res, err := thumb.NewFfmpegGenerator(m.l, m.settings, m.dep.ThumbProcessLimiter()).GenerateAnimated(ctx, es, ext)
+	if res != nil {
+		for _, cleanup := range res.Cleanup {
+			defer cleanup()
+		}
+	}
+
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			if err := disableAnimatedThumb(ctx, m, uri); err != nil {
+				m.l.Warning("Failed to disable animated thumb: %v", err)
+			}
+		}
+
+		return nil, fmt.Errorf("failed to generate animated thumb: %w", err)
+	}
+
+	thumbFile, err := os.Open(res.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open temp thumb %q: %w", res.Path, err)
+	}
+
+	defer thumbFile.Close()
+	fileInfo, err := thumbFile.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat temp thumb %q: %w", res.Path, err)
+	}
+
+	return m.uploadThumbEntity(ctx, uri, thumbFile, fileInfo.Size(), types.EntityTypeAnimatedThumbnail,
+		es.Entity().Source()+m.settings.ThumbAnimatedEntitySuffix(ctx), filepath.Base(res.Path))
+}
+
+// thumbContentKey returns the KV key of thumbnails generated for content of given entity source with
+// current encode settings. Stored entity checksum is used if available, otherwise content is hashed
+// if it is not larger than the configured size. Empty string is returned if content cache is not applicable.
+func (m *manager) thumbContentKey(ctx context.Context, es entitysource.EntitySource) string {
+	maxSize := m.settings.ThumbContentCacheMaxSize(ctx)
+	if maxSize <= 0 {
+		return ""
+	}
+
+	w, height := m.settings.ThumbSize(ctx)
+	encode := m.settings.ThumbEncode(ctx)
+	key := func(algorithm setting.ChecksumAlgorithm, checksum string) string {
+		return fmt.Sprintf("%s%s_%s_%dx%d_%s_%d", ThumbContentCachePrefix, algorithm, checksum, w, height, encode.Format, encode.Quality)
+	}
+
+	checksums := es.Entity().Checksums()
+	for _, algorithm := range []setting.ChecksumAlgorithm{setting.ChecksumAlgorithmSHA256, setting.ChecksumAlgorithmMD5} {
+		if checksum, ok := checksums[string(algorithm)]; ok && checksum != "" {
+			return key(algorithm, checksum)
+		}
+	}
+
+	if es.Entity().Size() > maxSize {
+		return ""
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(h, es); err != nil {
+		m.l.Debug("Failed to hash content of entity %d for thumb cache: %s", es.Entity().ID(), err)
+		return ""
+	}
+
+	if _, err := es.Seek(0, io.SeekStart); err != nil {
+		return ""
+	}
+
+	return key(setting.ChecksumAlgorithmSHA256, hex.EncodeToString(h.Sum(nil)))
+}
+
+// reuseThumb links thumbnail entity cached under given content key to the file, nil is returned if
+// no valid thumbnail is found. Thumb-derived metadata is copied from files already using the entity.
+func (m *manager) reuseThumb(ctx context.Context, uri *fs.URI, contentKey string) fs.Entity {
+	cached, ok := m.kv.Get(contentKey)
+	if !ok {
+		return nil
+	}
+
+	entityID, ok := cached.(int)
+	if !ok {
+		return nil
+	}
+
+	loadCtx := context.WithValue(context.WithValue(ctx, inventory.LoadEntityFile{}, true), inventory.LoadFileMetadata{}, true)
+	model, err := m.dep.FileClient().GetEntityByID(loadCtx, entityID)
+	if err != nil || model.ReferenceCount < 1 || types.EntityType(model.Type) != types.EntityTypeThumbnail {
+		_ = m.kv.Delete("", contentKey)
+		return nil
+	}
+
+	thumbEntity, err := m.fs.LinkThumbnail(dbfs.WithBypassOwnerCheck(ctx), uri, fs.NewEntity(model))
+	if err != nil {
+		m.l.Warning("Failed to reuse thumb entity %d: %s", entityID, err)
+		return nil
+	}
+
+	if patches := reusedThumbMetadata(model); len(patches) > 0 {
+		if err := m.fs.PatchMetadata(dbfs.WithBypassOwnerCheck(ctx), []*fs.URI{uri}, patches...); err != nil {
+			m.l.Warning("Failed to copy metadata of reused thumb entity %d: %s", entityID, err)
+		}
+	}
+
+	return thumbEntity
+}
+
+// reusedThumbMetadata returns blurhash and dominant color of the first file using given thumbnail
+// entity that has any of them, files and their metadata must be loaded.
+func reusedThumbMetadata(model *ent.Entity) []fs.MetadataPatch {
+	for _, f := range model.Edges.File {
+		patches := lo.FilterMap(f.Edges.Metadata, func(md *ent.Metadata, _ int) (fs.MetadataPatch, bool) {
+			return fs.MetadataPatch{Key: md.Name, Value: md.Value},
+				md.Name == dbfs.ThumbBlurhashKey || md.Name == dbfs.ThumbDominantColorKey
+		})
+		if len(patches) > 0 {
+			return patches
+		}
+	}
+
+	return nil
+}
+
 type (
 	GenerateThumbTask struct {
 		*queue.InMemoryTask
@@ -227,6 +503,8 @@ type (
 		m   *manager
 		uri *fs.URI
 		sig chan *generateRes
+		// animated is true if the task generates animated preview instead of static thumbnail.
+		animated bool
 	}
 	generateRes struct {
 		thumbEntity fs.Entity
@@ -264,7 +542,12 @@ func (m *GenerateThumbTask) Do(ctx context.Context) (task.Status, error) {
 	default:
 	}
 
-	res, err := m.m.generateThumb(ctx, m.uri, m.ext, m.es)
+	generate := m.m.generateThumb
+	if m.animated {
+		generate = m.m.generateAnimatedThumb
+	}
+
+	res, err := generate(ctx, m.uri, m.ext, m.es)
 	if err != nil {
 		if errors.Is(err, thumb.ErrNotAvailable) {
 			m.sig <- &generateRes{nil, err}
@@ -283,6 +566,22 @@ func (m *GenerateThumbTask) OnError(err error, d time.Duration) {
 	m.sig <- &generateRes{nil, err}
 }
 
+// siblingOpener returns a thumb.SiblingOpener that opens files in the same folder of given uri.
+func (m *manager) siblingOpener(uri *fs.URI) thumb.SiblingOpener {
+	return func(ctx context.Context, name string) (entitysource.EntitySource, error) {
+		file, err := m.fs.Get(ctx, uri.DirUri().Join(name), dbfs.WithFileEntities())
+		if err != nil {
+			return nil, fmt.Errorf("failed to get sibling file %q: %w", name, err)
+		}
+
+		if file.Type() != types.FileTypeFile || file.PrimaryEntity() == nil {
+			return nil, fs.ErrEntityNotExist
+		}
+
+		return m.GetEntitySource(ctx, 0, fs.WithEntity(file.PrimaryEntity()))
+	}
+}
+
 func disableThumb(ctx context.Context, m *manager, uri *fs.URI) error {
 	return m.fs.PatchMetadata(
 		dbfs.WithBypassOwnerCheck(ctx),
@@ -292,3 +591,13 @@ func disableThumb(ctx context.Context, m *manager, uri *fs.URI) error {
 			Private: false,
 		})
 }
+
+func disableAnimatedThumb(ctx context.Context, m *manager, uri *fs.URI) error {
+	return m.fs.PatchMetadata(
+		dbfs.WithBypassOwnerCheck(ctx),
+		[]*fs.URI{uri}, fs.MetadataPatch{
+			Key:     dbfs.ThumbAnimatedDisabledKey,
+			Value:   "",
+			Private: false,
+		})
+}
@@ -0,0 +1,261 @@
+package manager
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path"
+	"testing"
+
+	"github.com/cloudreve/Cloudreve/v4/application/dependency"
+	"github.com/cloudreve/Cloudreve/v4/ent"
+	"github.com/cloudreve/Cloudreve/v4/inventory"
+	"github.com/cloudreve/Cloudreve/v4/inventory/types"
+	"github.com/cloudreve/Cloudreve/v4/pkg/cache"
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/driver"
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/fs"
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/fs/dbfs"
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/manager/entitysource"
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/cloudreve/Cloudreve/v4/pkg/mediameta"
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
+	"github.com/stretchr/testify/assert"
+)
+
+type thumbCacheSettings struct {
+	setting.Provider
+	maxSize int64
+	format  string
+}
+
+func (s *thumbCacheSettings) ThumbContentCacheMaxSize(ctx context.Context) int64 { return s.maxSize }
+func (s *thumbCacheSettings) ThumbSize(ctx context.Context) (int, int)           { return 400, 300 }
+func (s *thumbCacheSettings) ThumbEncode(ctx context.Context) *setting.ThumbEncode {
+	return &setting.ThumbEncode{Format: s.format, Quality: 85}
+}
+
+type memoryEntitySource struct {
+	entitysource.EntitySource
+	r         *bytes.Reader
+	checksums map[string]string
+}
+
+func newMemoryEntitySource(content string) *memoryEntitySource {
+	return &memoryEntitySource{r: bytes.NewReader([]byte(content))}
+}
+
+func (s *memoryEntitySource) Read(p []byte) (int, error) { return s.r.Read(p) }
+func (s *memoryEntitySource) Seek(offset int64, whence int) (int64, error) {
+	return s.r.Seek(offset, whence)
+}
+func (s *memoryEntitySource) Entity() fs.Entity {
+	return fs.NewEntity(&ent.Entity{Size: s.r.Size(), Checksums: s.checksums})
+}
+
+func TestManager_ThumbContentKey(t *testing.T) {
+	a := assert.New(t)
+	settings := &thumbCacheSettings{maxSize: 1024, format: "jpg"}
+	m := &manager{settings: settings, l: logging.NewConsoleLogger(logging.LevelError)}
+	ctx := context.Background()
+
+	es := newMemoryEntitySource("content")
+	key := m.thumbContentKey(ctx, es)
+	a.NotEmpty(key)
+	a.Contains(key, ThumbContentCachePrefix)
+
+	// Source is rewound after hashing
+	a.EqualValues(len("content"), es.r.Len())
+
+	// Same content shares the key, different content does not
+	a.Equal(key, m.thumbContentKey(ctx, newMemoryEntitySource("content")))
+	a.NotEqual(key, m.thumbContentKey(ctx, newMemoryEntitySource("other")))
+
+	// Encode settings are part of the key
+	settings.format = "png"
+	a.NotEqual(key, m.thumbContentKey(ctx, newMemoryEntitySource("content")))
+
+	// Stored checksum is used without reading content, regardless of size
+	settings.maxSize = 3
+	es = newMemoryEntitySource("content")
+	a.Empty(m.thumbContentKey(ctx, es))
+	es.checksums = map[string]string{"md5": "9a0364b9e99bb480dd25e1f0284c8555"}
+	key = m.thumbContentKey(ctx, es)
+	a.Contains(key, "md5_9a0364b9e99bb480dd25e1f0284c8555")
+	a.EqualValues(len("content"), es.r.Len())
+
+	// Checksum stored for another entity of the same content shares the key with hashed content
+	settings.maxSize = 1024
+	hashed := m.thumbContentKey(ctx, newMemoryEntitySource("content"))
+	es = newMemoryEntitySource("other")
+	es.checksums = map[string]string{"sha256": "ed7002b439e9ac845f22357d822bac1444730fbdb6016d3ec9432297b9ec9f73", "md5": "x"}
+	a.Equal(hashed, m.thumbContentKey(ctx, es))
+
+	// Disabled
+	settings.maxSize = 0
+	a.Empty(m.thumbContentKey(ctx, es))
+}
+
+type pairingSettings struct {
+	setting.Provider
+	pairing *setting.MediaMetaPairing
+}
+
+func (s *pairingSettings) MediaMetaPairing(ctx context.Context) *setting.MediaMetaPairing {
+	return s.pairing
+}
+
+type pairedFile struct {
+	fs.File
+	id        int
+	name      string
+	companion string
+}
+
+func (f *pairedFile) ID() int { return f.id }
+func (f *pairedFile) Ext() string {
+	return path.Ext(f.name)[1:]
+}
+func (f *pairedFile) Metadata() map[string]string {
+	return map[string]string{fmt.Sprintf("%s:%s", driver.MetaTypePair, mediameta.PairCompanion): f.companion}
+}
+
+// pairedFs serves files by name from a flat directory.
+type pairedFs struct {
+	fs.FileSystem
+	files map[string]*pairedFile
+}
+
+func (f *pairedFs) Get(ctx context.Context, uri *fs.URI, opts ...fs.Option) (fs.File, error) {
+	if file, ok := f.files[uri.Name()]; ok {
+		return file, nil
+	}
+	return nil, fs.ErrPathNotExist
+}
+
+func TestManager_ThumbCompanion(t *testing.T) {
+	a := assert.New(t)
+	settings := &pairingSettings{pairing: &setting.MediaMetaPairing{
+		Enabled:       true,
+		PrimaryExts:   []string{"cr2", "heic"},
+		CompanionExts: []string{"jpg"},
+	}}
+	files := &pairedFs{files: map[string]*pairedFile{
+		"a.cr2":  {id: 1, name: "a.cr2", companion: "a.jpg"},
+		"a.jpg":  {id: 2, name: "a.jpg"},
+		"b.cr2":  {id: 3, name: "b.cr2", companion: "b.heic"},
+		"b.heic": {id: 4, name: "b.heic", companion: "b.cr2"},
+		"c.cr2":  {id: 5, name: "c.cr2", companion: "c.CR2"},
+		"c.CR2":  {id: 5, name: "c.CR2"},
+		"d.cr2":  {id: 6, name: "d.cr2", companion: "d.jpg"},
+		"e.cr2":  {id: 7, name: "e.cr2", companion: "e.jpeg"},
+		"e.jpeg": {id: 7, name: "e.jpeg"},
+	}}
+	m := &manager{settings: settings, fs: files, l: logging.NewConsoleLogger(logging.LevelError)}
+	ctx := context.Background()
+	companionOf := func(name string) *fs.URI {
+		uri, err := fs.NewUriFromString("cloudreve://my/photos/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m.thumbCompanion(ctx, uri, files.files[name])
+	}
+
+	companion := companionOf("a.cr2")
+	if a.NotNil(companion) {
+		a.Equal("/photos/a.jpg", companion.Path())
+	}
+
+	// Two primary files pointing to each other
+	a.Nil(companionOf("b.cr2"))
+	a.Nil(companionOf("b.heic"))
+	// Same extension, or resolved to the same file
+	a.Nil(companionOf("c.cr2"))
+	a.Nil(companionOf("e.cr2"))
+	// Companion not found
+	a.Nil(companionOf("d.cr2"))
+	// No companion
+	a.Nil(companionOf("a.jpg"))
+
+	// Disabled
+	settings.pairing.Enabled = false
+	a.Nil(companionOf("a.cr2"))
+}
+
+type reuseThumbFileClient struct {
+	inventory.FileClient
+	entity *ent.Entity
+}
+
+func (c *reuseThumbFileClient) GetEntityByID(ctx context.Context, id int) (*ent.Entity, error) {
+	// Files using the entity and their metadata must be requested.
+	if loadFile, _ := ctx.Value(inventory.LoadEntityFile{}).(bool); !loadFile {
+		return nil, fmt.Errorf("files not loaded")
+	}
+	if loadMetadata, _ := ctx.Value(inventory.LoadFileMetadata{}).(bool); !loadMetadata {
+		return nil, fmt.Errorf("metadata not loaded")
+	}
+
+	if id != c.entity.ID {
+		return nil, fmt.Errorf("entity %d not found", id)
+	}
+	return c.entity, nil
+}
+
+type reuseThumbFs struct {
+	fs.FileSystem
+	patches []fs.MetadataPatch
+}
+
+func (f *reuseThumbFs) LinkThumbnail(ctx context.Context, path *fs.URI, entity fs.Entity) (fs.Entity, error) {
+	return entity, nil
+}
+
+func (f *reuseThumbFs) PatchMetadata(ctx context.Context, path []*fs.URI, metas ...fs.MetadataPatch) error {
+	f.patches = append(f.patches, metas...)
+	return nil
+}
+
+func TestManager_ReuseThumb(t *testing.T) {
+	a := assert.New(t)
+	l := logging.NewConsoleLogger(logging.LevelError)
+	thumbEntity := &ent.Entity{ID: 10, Type: int(types.EntityTypeThumbnail), ReferenceCount: 1}
+	thumbEntity.Edges.File = []*ent.File{
+		{ID: 1, Edges: ent.FileEdges{Metadata: []*ent.Metadata{{Name: "tag:color", Value: "red"}}}},
+		{ID: 2, Edges: ent.FileEdges{Metadata: []*ent.Metadata{
+			{Name: dbfs.ThumbBlurhashKey, Value: "LEHV6nWB2yk8"},
+			{Name: dbfs.ThumbDominantColorKey, Value: "#aabbcc"},
+			{Name: dbfs.ThumbDisabledKey, Value: ""},
+		}}},
+	}
+	files := &reuseThumbFs{}
+	m := &manager{
+		l:  l,
+		kv: cache.NewMemoStore("", l),
+		fs: files,
+		dep: dependency.NewDependency(
+			dependency.WithFileClient(&reuseThumbFileClient{entity: thumbEntity}),
+			dependency.WithLogger(l),
+		),
+	}
+	uri, _ := fs.NewUriFromString("cloudreve://my/b.jpg")
+	ctx := context.Background()
+
+	// Not cached
+	a.Nil(m.reuseThumb(ctx, uri, "thumb_content_key"))
+
+	a.NoError(m.kv.Set("thumb_content_key", 10, 0))
+	reused := m.reuseThumb(ctx, uri, "thumb_content_key")
+	if a.NotNil(reused) {
+		a.Equal(10, reused.ID())
+	}
+	a.ElementsMatch([]fs.MetadataPatch{
+		{Key: dbfs.ThumbBlurhashKey, Value: "LEHV6nWB2yk8"},
+		{Key: dbfs.ThumbDominantColorKey, Value: "#aabbcc"},
+	}, files.patches)
+
+	// Nothing to copy
+	thumbEntity.Edges.File = thumbEntity.Edges.File[:1]
+	files.patches = nil
+	a.NotNil(m.reuseThumb(ctx, uri, "thumb_content_key"))
+	a.Empty(files.patches)
+}
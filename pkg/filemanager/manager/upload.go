@@ -32,6 +32,8 @@ type (
 		Upload(ctx context.Context, req *fs.UploadRequest, policy *ent.StoragePolicy) error
 		// CompleteUpload completes upload session and returns file object
 		CompleteUpload(ctx context.Context, session *fs.UploadSession) (fs.File, error)
+		// ListUploadedParts lists parts already uploaded to storage in given upload session of current user
+		ListUploadedParts(ctx context.Context, sessionID string) ([]driver.UploadedPart, error)
 		// CancelUploadSession cancels upload session
 		CancelUploadSession(ctx context.Context, path *fs.URI, sessionID string) error
 		// OnUploadFailed should be called when an unmanaged upload failed before complete.
@@ -191,6 +193,31 @@ func (m *manager) Upload(ctx context.Context, req *fs.UploadRequest, policy *ent
 	return nil
 }
 
+func (m *manager) ListUploadedParts(ctx context.Context, sessionID string) ([]driver.UploadedPart, error) {
+	sessionRaw, ok := m.kv.Get(UploadSessionCachePrefix + sessionID)
+	if !ok {
+		return nil, serializer.NewError(serializer.CodeUploadSessionExpired, "", nil)
+	}
+
+	session := sessionRaw.(fs.UploadSession)
+	if session.UID != m.user.ID || time.Now().After(session.Props.ExpireAt) {
+		return nil, serializer.NewError(serializer.CodeUploadSessionExpired, "", nil)
+	}
+
+	ctx = context.WithValue(ctx, cluster.SlaveNodeIDCtx{}, strconv.Itoa(session.Policy.NodeID))
+	d, err := m.GetStorageDriver(ctx, m.CastStoragePolicyOnSlave(ctx, session.Policy))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get storage driver: %w", err)
+	}
+
+	parts, err := d.ListUploadedParts(ctx, &session)
+	if err != nil {
+		return nil, serializer.NewError(serializer.CodeNotSet, "Failed to list uploaded parts", err)
+	}
+
+	return parts, nil
+}
+
 func (m *manager) CancelUploadSession(ctx context.Context, path *fs.URI, sessionID string) error {
 	// Get upload session
 	var session *fs.UploadSession
@@ -399,6 +426,7 @@ func (m *manager) onNewEntityUploaded(ctx context.Context, session *fs.UploadSes
 	if !m.stateless {
 		// Submit media meta task for new entity
 		m.mediaMetaForNewEntity(ctx, session, d)
+		m.checksumForNewEntity(ctx, session)
 	}
 }
 
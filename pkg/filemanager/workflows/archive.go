@@ -65,6 +65,7 @@ const (
 
 	ProgressTypeArchiveCount = "archive_count"
 	ProgressTypeArchiveSize  = "archive_size"
+	ProgressTypeArchiveQueue = "archive_queue"
 	ProgressTypeUpload       = "upload"
 	ProgressTypeUploadCount  = "upload_count"
 )
@@ -177,6 +178,11 @@ func (m *CreateArchiveTask) Do(ctx context.Context) (task.Status, error) {
 }
 
 func (m *CreateArchiveTask) Cleanup(ctx context.Context) error {
+	// Task canceled before started
+	if m.state == nil {
+		return nil
+	}
+
 	if m.state.SlaveCompressState != nil && m.state.SlaveCompressState.TempPath != "" && m.node != nil {
 		if err := m.node.CleanupFolders(context.Background(), m.state.SlaveCompressState.TempPath); err != nil {
 			m.l.Warning("Failed to cleanup slave temp folder %s: %s", m.state.SlaveCompressState.TempPath, err)
@@ -192,7 +198,7 @@ func (m *CreateArchiveTask) Cleanup(ctx context.Context) error {
 }
 
 func (m *CreateArchiveTask) initializeTempFolder(ctx context.Context, dep dependency.Dep) (task.Status, error) {
-	tempPath, err := prepareTempFolder(ctx, dep, m)
+	tempPath, err := prepareArchiveTempFolder(ctx, dep, m)
 	if err != nil {
 		return task.StatusError, fmt.Errorf("failed to prepare temp folder: %w", err)
 	}
@@ -395,8 +401,20 @@ func (m *CreateArchiveTask) createArchiveFile(ctx context.Context, dep dependenc
 			atomic.AddInt64(&m.progress[ProgressTypeArchiveSize].Current, diff)
 			atomic.AddInt64(&m.progress[ProgressTypeArchiveCount].Current, 1)
 		}),
+		fs.WithArchiveQueueFunc(func(position int) {
+			m.Lock()
+			defer m.Unlock()
+			if position > 0 {
+				m.progress[ProgressTypeArchiveQueue] = &queue.Progress{Current: int64(position)}
+			} else {
+				delete(m.progress, ProgressTypeArchiveQueue)
+			}
+		}),
 	)
 	if err != nil {
+		m.Lock()
+		delete(m.progress, ProgressTypeArchiveQueue)
+		m.Unlock()
 		zipFile.Close()
 		_ = os.Remove(zipFilePath)
 		return task.StatusError, fmt.Errorf("failed to compress files: %w", err)
@@ -581,12 +599,22 @@ func (t *SlaveCreateArchiveTask) Do(ctx context.Context) (task.Status, error) {
 	t.Unlock()
 
 	// 3. Create temp workspace
-	tempPath, err := prepareTempFolder(ctx, dep, t)
+	tempPath, err := prepareArchiveTempFolder(ctx, dep, t)
 	if err != nil {
 		return task.StatusError, fmt.Errorf("failed to prepare temp folder: %w", err)
 	}
 	t.state.TempPath = tempPath
 
+	// Temp workspace is handed over to master on success, otherwise remove it here.
+	succeed := false
+	defer func() {
+		if !succeed {
+			if err := os.RemoveAll(tempPath); err != nil {
+				t.l.Warning("Failed to cleanup temp folder %s: %s", tempPath, err)
+			}
+		}
+	}()
+
 	// 2. Create archive file
 	fileName := fmt.Sprintf("%s.zip", uuid.Must(uuid.NewV4()))
 	zipFilePath := filepath.Join(
@@ -605,6 +633,10 @@ func (t *SlaveCreateArchiveTask) Do(ctx context.Context) (task.Status, error) {
 
 	// 3. Download each entity and write into zip file
 	for _, e := range t.state.Entities {
+		if err := ctx.Err(); err != nil {
+			return task.StatusError, fmt.Errorf("archive task canceled: %w", err)
+		}
+
 		policy, ok := t.state.Policies[e.Entity.StoragePolicyEntities]
 		if !ok {
 			state.Failed++
@@ -671,6 +703,7 @@ func (t *SlaveCreateArchiveTask) Do(ctx context.Context) (task.Status, error) {
 	t.Lock()
 	t.Task.PrivateState = string(newStateStr)
 	t.Unlock()
+	succeed = true
 	return task.StatusCompleted, nil
 }
 
@@ -0,0 +1,76 @@
+package workflows
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudreve/Cloudreve/v4/application/dependency"
+	"github.com/cloudreve/Cloudreve/v4/ent"
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/cloudreve/Cloudreve/v4/pkg/queue"
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting/settingtest"
+	"github.com/cloudreve/Cloudreve/v4/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateTaskTempFolder(t *testing.T) {
+	a := assert.New(t)
+	base := t.TempDir()
+
+	tempPath, err := createTaskTempFolder(base, 42)
+	a.NoError(err)
+	a.Equal(filepath.Join(base, TaskTempPath, "42"), tempPath)
+	empty, err := util.IsEmpty(tempPath)
+	a.NoError(err)
+	a.True(empty)
+}
+
+func TestCreateArchiveTask_CleanupOnCancel(t *testing.T) {
+	a := assert.New(t)
+	base := t.TempDir()
+
+	tempPath, err := createTaskTempFolder(base, 1)
+	a.NoError(err)
+	zipFile, err := util.CreatNestedFile(filepath.Join(tempPath, "archive.zip"))
+	a.NoError(err)
+	zipFile.Close()
+
+	m := &CreateArchiveTask{state: &CreateArchiveTaskState{TempPath: tempPath}}
+	a.NoError(m.Cleanup(context.Background()))
+	_, err = os.Stat(tempPath)
+	a.True(os.IsNotExist(err))
+
+	// Task canceled before started has nothing to cleanup.
+	a.NoError((&CreateArchiveTask{}).Cleanup(context.Background()))
+}
+
+func TestPrepareArchiveTempFolder(t *testing.T) {
+	a := assert.New(t)
+	tempPath, archiveTempPath := t.TempDir(), t.TempDir()
+	store := settingtest.Store{"temp_path": tempPath, "archive_temp_path": archiveTempPath}
+	dep := dependency.NewDependency(
+		dependency.WithSettingProvider(setting.NewProvider(store)),
+		dependency.WithLogger(logging.NewConsoleLogger(logging.LevelError)),
+	)
+	task := &CreateArchiveTask{DBTask: &queue.DBTask{Task: &ent.Task{ID: 7}}}
+
+	// Archive tasks use the dedicated temp path
+	folder, err := prepareArchiveTempFolder(context.Background(), dep, task)
+	a.NoError(err)
+	a.Equal(filepath.Join(archiveTempPath, TaskTempPath, "7"), folder)
+	a.DirExists(folder)
+
+	// Other tasks still use the general one
+	folder, err = prepareTempFolder(context.Background(), dep, task)
+	a.NoError(err)
+	a.Equal(filepath.Join(tempPath, TaskTempPath, "7"), folder)
+
+	// Fallback to general temp path if not set
+	delete(store, "archive_temp_path")
+	folder, err = prepareArchiveTempFolder(context.Background(), dep, &CreateArchiveTask{DBTask: &queue.DBTask{Task: &ent.Task{ID: 8}}})
+	a.NoError(err)
+	a.Equal(filepath.Join(tempPath, TaskTempPath, "8"), folder)
+}
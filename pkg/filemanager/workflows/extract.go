@@ -3,6 +3,7 @@ package workflows
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -24,9 +25,11 @@ import (
 	"github.com/cloudreve/Cloudreve/v4/pkg/hashid"
 	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
 	"github.com/cloudreve/Cloudreve/v4/pkg/queue"
+	"github.com/cloudreve/Cloudreve/v4/pkg/serializer"
 	"github.com/cloudreve/Cloudreve/v4/pkg/util"
 	"github.com/gofrs/uuid"
 	"github.com/mholt/archives"
+	"github.com/samber/lo"
 )
 
 type (
@@ -68,6 +71,13 @@ const (
 	SummaryKeyDst         = "dst"
 )
 
+// archiveRejectedCodes are error codes of archives failing validation before extraction.
+var archiveRejectedCodes = []int{
+	serializer.CodeFileTooLarge,
+	serializer.CodeIllegalObjectName,
+	serializer.CodeSuspiciousArchive,
+}
+
 func init() {
 	queue.RegisterResumableTaskFactory(queue.ExtractArchiveTaskType, NewExtractArchiveTaskFromModel)
 }
@@ -322,6 +332,10 @@ func (m *ExtractArchiveTask) masterExtractArchive(ctx context.Context, dep depen
 
 			readStream = es
 		}
+
+		if archiveReader, ok := readStream.(io.ReaderAt); ok {
+			return m.extractWithManager(ctx, dep, fm, uri, dst, strings.TrimPrefix(formatExt, "."), archiveReader)
+		}
 	}
 
 	if zipExtractor, ok := extractor.(archives.Zip); ok {
@@ -437,6 +451,67 @@ func (m *ExtractArchiveTask) masterExtractArchive(ctx context.Context, dep depen
 	return task.StatusCompleted, nil
 }
 
+// extractWithManager extracts zip and 7z archives through file manager, which validates all entries
+// before writing anything, and removes extracted files if the task is canceled.
+func (m *ExtractArchiveTask) extractWithManager(ctx context.Context, dep dependency.Dep, fm manager.FileManager,
+	uri, dst *fs.URI, format string, archiveReader io.ReaderAt) (task.Status, error) {
+	encoding := m.state.Encoding
+	if _, ok := manager.ZipEncodings[strings.ToLower(encoding)]; encoding != "" && !ok {
+		m.l.Warning("Unknown encoding %q, fallback to detected encoding", encoding)
+		encoding = ""
+	}
+
+	countProgress, sizeProgress := &queue.Progress{}, &queue.Progress{}
+	m.Lock()
+	m.progress[ProgressTypeExtractCount] = countProgress
+	m.progress[ProgressTypeExtractSize] = sizeProgress
+	m.Unlock()
+
+	// Entries up to the cursor are extracted by previous attempts.
+	skipToCursor := m.state.ProcessedCursor != ""
+	err := fm.ExtractArchive(ctx, uri, dst,
+		fs.WithArchiveFormat(format),
+		fs.WithArchiveReader(archiveReader),
+		fs.WithArchiveEncoding(encoding),
+		fs.WithArchivePassword(m.state.Password),
+		fs.WithMaxArchiveEntrySize(dep.SettingProvider().ArchiveExtractMaxEntrySize(ctx)),
+		fs.WithArchiveRollbackOnCancel(true),
+		fs.WithArchiveEntryFilterFunc(func(name string) bool {
+			if skipToCursor {
+				skipToCursor = name != m.state.ProcessedCursor
+				return false
+			}
+
+			if len(m.state.FileMask) > 0 && !isFileInMask(name, m.state.FileMask) {
+				m.l.Warning("File %q is not in the mask, skipping...", name)
+				return false
+			}
+
+			return true
+		}),
+		fs.WithArchiveEntryExtractedFunc(func(name string) {
+			m.state.ProcessedCursor = name
+		}),
+		fs.WithArchiveExtractProgressFunc(func(filesDone, filesTotal int, bytesDone, bytesTotal int64) {
+			atomic.StoreInt64(&countProgress.Total, int64(filesTotal))
+			atomic.StoreInt64(&countProgress.Current, int64(filesDone))
+			atomic.StoreInt64(&sizeProgress.Total, bytesTotal)
+			atomic.StoreInt64(&sizeProgress.Current, bytesDone)
+		}),
+	)
+	if err != nil {
+		// Rejected archives will be rejected again on retry.
+		var appErr serializer.AppError
+		if errors.As(err, &appErr) && lo.Contains(archiveRejectedCodes, appErr.Code) {
+			return task.StatusError, fmt.Errorf("failed to extract archive: %s (%w)", err, queue.CriticalErr)
+		}
+
+		return task.StatusError, fmt.Errorf("failed to extract archive: %w", err)
+	}
+
+	return task.StatusCompleted, nil
+}
+
 func (m *ExtractArchiveTask) masterDownloadZip(ctx context.Context, dep dependency.Dep) (task.Status, error) {
 	uri, err := fs.NewUriFromString(m.state.Uri)
 	if err != nil {
@@ -51,12 +51,30 @@ func prepareSlaveTaskCtx(ctx context.Context, props *types.SlaveTaskProps) conte
 }
 
 func prepareTempFolder(ctx context.Context, dep dependency.Dep, t queue.Task) (string, error) {
-	settings := dep.SettingProvider()
-	tempPath := util.DataPath(path.Join(settings.TempPath(ctx), TaskTempPath, strconv.Itoa(t.ID())))
+	return prepareTempFolderIn(dep, dep.SettingProvider().TempPath(ctx), t)
+}
+
+// prepareArchiveTempFolder creates temp folder for archive tasks, which can be placed on a dedicated disk.
+func prepareArchiveTempFolder(ctx context.Context, dep dependency.Dep, t queue.Task) (string, error) {
+	return prepareTempFolderIn(dep, dep.SettingProvider().ArchiveTempPath(ctx), t)
+}
+
+func prepareTempFolderIn(dep dependency.Dep, base string, t queue.Task) (string, error) {
+	tempPath, err := createTaskTempFolder(base, t.ID())
+	if err != nil {
+		return "", err
+	}
+
+	dep.Logger().Info("Temp folder created: %s", tempPath)
+	return tempPath, nil
+}
+
+// createTaskTempFolder creates the temp folder of given task under base path.
+func createTaskTempFolder(base string, taskID int) (string, error) {
+	tempPath := util.DataPath(path.Join(base, TaskTempPath, strconv.Itoa(taskID)))
 	if err := util.CreatNestedFolder(tempPath); err != nil {
 		return "", fmt.Errorf("failed to create temp folder: %w", err)
 	}
 
-	dep.Logger().Info("Temp folder created: %s", tempPath)
 	return tempPath, nil
 }
@@ -0,0 +1,226 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+// Fields of access log record.
+const (
+	AccessFieldTime     = "time"
+	AccessFieldMethod   = "method"
+	AccessFieldPath     = "path"
+	AccessFieldStatus   = "status"
+	AccessFieldDuration = "duration"
+	AccessFieldUserID   = "user_id"
+	AccessFieldIP       = "ip"
+	AccessFieldBytesIn  = "bytes_in"
+	AccessFieldBytesOut = "bytes_out"
+)
+
+const accessLogFormatJSON = "json"
+
+// AccessRedacted replaces values of sensitive path parameters in access log.
+const AccessRedacted = "[REDACTED]"
+
+type (
+	// AccessRecord is the structured record of a served request.
+	AccessRecord struct {
+		Time     time.Time
+		Method   string
+		Path     string
+		Status   int
+		Duration time.Duration
+		UserID   int
+		IP       string
+		BytesIn  int64
+		BytesOut int64
+	}
+
+	// AccessLogger writes one access record per line.
+	AccessLogger struct {
+		mu sync.Mutex
+		w  io.Writer
+	}
+
+	// AccessSampler decides whether a request should be logged according to the sample rate
+	// of the longest matching path prefix. Sampling is deterministic: with rate r, one in
+	// every 1/r matched requests is logged.
+	AccessSampler struct {
+		mu     sync.Mutex
+		counts map[string]int64
+	}
+)
+
+// NewAccessLogger creates an access logger writing to w.
+func NewAccessLogger(w io.Writer) *AccessLogger {
+	return &AccessLogger{w: w}
+}
+
+// Log writes the record in given format (text or json), only given fields are included.
+func (l *AccessLogger) Log(r *AccessRecord, format string, fields []string) {
+	line := FormatAccessRecord(r, format, fields)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, _ = fmt.Fprintln(l.w, line)
+}
+
+// value returns value of given field, false if the field is unknown.
+func (r *AccessRecord) value(field string) (any, bool) {
+	switch field {
+	case AccessFieldTime:
+		return r.Time.Format(time.RFC3339), true
+	case AccessFieldMethod:
+		return r.Method, true
+	case AccessFieldPath:
+		return r.Path, true
+	case AccessFieldStatus:
+		return r.Status, true
+	case AccessFieldDuration:
+		// In milliseconds
+		return float64(r.Duration.Microseconds()) / 1000, true
+	case AccessFieldUserID:
+		return r.UserID, true
+	case AccessFieldIP:
+		return r.IP, true
+	case AccessFieldBytesIn:
+		return r.BytesIn, true
+	case AccessFieldBytesOut:
+		return r.BytesOut, true
+	}
+
+	return nil, false
+}
+
+// FormatAccessRecord renders given fields of the record as a JSON object, or as key=value
+// pairs in text format. Unknown fields are ignored.
+func FormatAccessRecord(r *AccessRecord, format string, fields []string) string {
+	if format == accessLogFormatJSON {
+		res := make(map[string]any, len(fields))
+		for _, field := range fields {
+			if v, ok := r.value(field); ok {
+				res[field] = v
+			}
+		}
+
+		encoded, _ := json.Marshal(res)
+		return string(encoded)
+	}
+
+	pairs := make([]string, 0, len(fields))
+	for _, field := range fields {
+		v, ok := r.value(field)
+		if !ok {
+			continue
+		}
+
+		str := fmt.Sprint(v)
+		if str == "" || strings.ContainsAny(str, " \"=") {
+			str = strconv.Quote(str)
+		}
+		pairs = append(pairs, field+"="+str)
+	}
+
+	return strings.Join(pairs, " ")
+}
+
+// SanitizeQuery removes sensitive parameters from raw query string, parameter names are case-insensitive.
+func SanitizeQuery(rawQuery string, sensitive []string) string {
+	if rawQuery == "" || len(sensitive) == 0 {
+		return rawQuery
+	}
+
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		// Not able to tell which part is sensitive
+		return ""
+	}
+
+	for key := range query {
+		for _, s := range sensitive {
+			if strings.EqualFold(key, s) {
+				query.Del(key)
+				break
+			}
+		}
+	}
+
+	return query.Encode()
+}
+
+// SanitizePath masks values of sensitive path parameters (e.g. share password in /s/:id/:password) in
+// request path. route is the matched route template, params maps parameter names to their values.
+// Parameter names are case-insensitive, path is returned as is if no sensitive parameter is matched.
+func SanitizePath(path, route string, params map[string]string, sensitive []string) string {
+	if route == "" || len(params) == 0 || len(sensitive) == 0 {
+		return path
+	}
+
+	segments := strings.Split(route, "/")
+	masked := false
+	for i, segment := range segments {
+		if len(segment) < 2 || (segment[0] != ':' && segment[0] != '*') {
+			continue
+		}
+
+		name := segment[1:]
+		if lo.ContainsBy(sensitive, func(s string) bool { return strings.EqualFold(name, s) }) {
+			segments[i] = AccessRedacted
+			masked = true
+		} else if segment[0] == '*' {
+			segments[i] = strings.TrimPrefix(params[name], "/")
+		} else {
+			segments[i] = params[name]
+		}
+	}
+
+	if !masked {
+		return path
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// NewAccessSampler creates a new sampler.
+func NewAccessSampler() *AccessSampler {
+	return &AccessSampler{counts: make(map[string]int64)}
+}
+
+// Sample returns true if the request to given path should be logged.
+func (s *AccessSampler) Sample(path string, rates map[string]float64) bool {
+	prefix, matched := "", false
+	for p := range rates {
+		if strings.HasPrefix(path, p) && (!matched || len(p) > len(prefix)) {
+			prefix, matched = p, true
+		}
+	}
+
+	if !matched {
+		return true
+	}
+
+	rate := rates[prefix]
+	if rate >= 1 {
+		return true
+	}
+
+	if rate <= 0 {
+		return false
+	}
+
+	// Log the n-th request if it brings the expected number of logged requests to the next integer
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counts[prefix]++
+	n := float64(s.counts[prefix])
+	return math.Floor(n*rate+1e-9) > math.Floor((n-1)*rate+1e-9)
+}
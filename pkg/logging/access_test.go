@@ -0,0 +1,97 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testAccessRecord() *AccessRecord {
+	return &AccessRecord{
+		Time:     time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Method:   "GET",
+		Path:     "/api/v4/file?uri=cloudreve://my/a b",
+		Status:   200,
+		Duration: 1500 * time.Microsecond,
+		UserID:   3,
+		IP:       "10.0.0.1",
+		BytesIn:  0,
+		BytesOut: 512,
+	}
+}
+
+func TestFormatAccessRecord(t *testing.T) {
+	a := assert.New(t)
+	r := testAccessRecord()
+
+	a.Equal(`method=GET path="/api/v4/file?uri=cloudreve://my/a b" status=200 duration=1.5 bytes_out=512`,
+		FormatAccessRecord(r, "text", []string{AccessFieldMethod, AccessFieldPath, AccessFieldStatus, AccessFieldDuration, "unknown", AccessFieldBytesOut}))
+	a.Equal("time=2024-01-02T03:04:05Z user_id=3 ip=10.0.0.1", FormatAccessRecord(r, "text", []string{AccessFieldTime, AccessFieldUserID, AccessFieldIP}))
+	a.Equal("", FormatAccessRecord(r, "text", nil))
+
+	var decoded map[string]any
+	a.NoError(json.Unmarshal([]byte(FormatAccessRecord(r, "json", []string{AccessFieldStatus, AccessFieldIP, AccessFieldBytesIn})), &decoded))
+	a.Equal(map[string]any{"status": float64(200), "ip": "10.0.0.1", "bytes_in": float64(0)}, decoded)
+}
+
+func TestAccessLogger_Log(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := NewAccessLogger(buf)
+	l.Log(testAccessRecord(), "json", []string{AccessFieldMethod})
+	l.Log(testAccessRecord(), "text", []string{AccessFieldStatus})
+	assert.Equal(t, "{\"method\":\"GET\"}\nstatus=200\n", buf.String())
+}
+
+func TestSanitizeQuery(t *testing.T) {
+	a := assert.New(t)
+	a.Equal("id=1&uri=%2Fa", SanitizeQuery("id=1&sign=abc&SECRET=x&uri=/a", []string{"sign", "secret"}))
+	a.Equal("sign=abc", SanitizeQuery("sign=abc", nil))
+	a.Equal("", SanitizeQuery("", []string{"sign"}))
+	a.Equal("", SanitizeQuery("a=%zz", []string{"sign"}))
+}
+
+func TestSanitizePath(t *testing.T) {
+	a := assert.New(t)
+	sensitive := []string{"password", "key"}
+	a.Equal("/api/v4/share/info/abc", SanitizePath("/api/v4/share/info/abc", "/api/v4/share/info/:id", map[string]string{"id": "abc"}, sensitive))
+	a.Equal("/s/abc/[REDACTED]", SanitizePath("/s/abc/123456", "/s/:id/:password", map[string]string{"id": "abc", "password": "123456"}, sensitive))
+	a.Equal("/api/v4/callback/oss/session/[REDACTED]", SanitizePath("/api/v4/callback/oss/session/secret",
+		"/api/v4/callback/oss/:sessionID/:Key", map[string]string{"sessionID": "session", "Key": "secret"}, sensitive))
+	a.Equal("/a/[REDACTED]", SanitizePath("/a/b/c", "/a/*key", map[string]string{"key": "/b/c"}, sensitive))
+	a.Equal("/s/abc/123456", SanitizePath("/s/abc/123456", "/s/:id/:password", map[string]string{"id": "abc", "password": "123456"}, nil))
+	a.Equal("/not/found", SanitizePath("/not/found", "", nil, sensitive))
+}
+
+func TestAccessSampler(t *testing.T) {
+	a := assert.New(t)
+	s := NewAccessSampler()
+	rates := map[string]float64{
+		"/api/v4/file":       0.5,
+		"/api/v4/file/thumb": 0.1,
+		"/api/v4/site/ping":  0,
+	}
+
+	sampled := func(path string, n int) int {
+		logged := 0
+		for i := 0; i < n; i++ {
+			if s.Sample(path, rates) {
+				logged++
+			}
+		}
+		return logged
+	}
+
+	// Longest matching prefix is used
+	a.Equal(100, sampled("/api/v4/file/thumb/abc", 1000))
+	a.Equal(500, sampled("/api/v4/file/content", 1000))
+	a.Equal(0, sampled("/api/v4/site/ping", 100))
+	a.Equal(100, sampled("/api/v4/user/me", 100))
+
+	// Fractional rates are honored exactly over a window
+	rates["/api/v4/share"] = 0.3
+	a.Equal(3, sampled("/api/v4/share", 10))
+	a.Equal(300, sampled("/api/v4/share", 1000))
+}
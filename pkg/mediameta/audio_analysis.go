@@ -0,0 +1,301 @@
+package mediameta
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os/exec"
+	"time"
+
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/driver"
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/manager/entitysource"
+)
+
+const (
+	// Audio is decoded by ffmpeg into 48kHz stereo float PCM, K-weighting filter coefficients
+	// of BS.1770 are defined for this sample rate.
+	analysisSampleRate = 48000
+	analysisChannels   = 2
+
+	// Loudness is measured in 400ms blocks with 75% overlap.
+	loudnessSubBlock          = analysisSampleRate / 10
+	loudnessSubBlocksPerBlock = 4
+	loudnessAbsoluteGate      = -70.0
+	loudnessRelativeGate      = -10.0
+
+	// Onset envelope is computed every 256 samples, BPM is searched in [tempoMinBPM, tempoMaxBPM].
+	tempoHop    = 256
+	tempoMinBPM = 70
+	tempoMaxBPM = 180
+)
+
+var errAudioTooShort = errors.New("audio is too short to be analyzed")
+
+// biquad is a second order IIR filter in direct form I.
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+	x1, x2, y1, y2     float64
+}
+
+func (f *biquad) process(x float64) float64 {
+	y := f.b0*x + f.b1*f.x1 + f.b2*f.x2 - f.a1*f.y1 - f.a2*f.y2
+	f.x2, f.x1 = f.x1, x
+	f.y2, f.y1 = f.y1, y
+	return y
+}
+
+// kWeighting returns the two-stage K-weighting filter of ITU-R BS.1770 at 48kHz.
+func kWeighting() [2]biquad {
+	return [2]biquad{
+		{b0: 1.53512485958697, b1: -2.69169618940638, b2: 1.19839281085285, a1: -1.69065929318241, a2: 0.73248077421585},
+		{b0: 1.0, b1: -2.0, b2: 1.0, a1: -1.99004745483398, a2: 0.99007225036621},
+	}
+}
+
+// audioAnalyzer computes integrated loudness and tempo of interleaved stereo samples.
+type audioAnalyzer struct {
+	filters [analysisChannels][2]biquad
+
+	// Loudness state
+	subBlockSum   float64
+	subBlockCount int
+	subBlocks     []float64
+	blockPowers   []float64
+
+	// Tempo state
+	hopEnergy  float64
+	hopCount   int
+	lastEnergy float64
+	onsets     []float64
+}
+
+func newAudioAnalyzer() *audioAnalyzer {
+	a := &audioAnalyzer{}
+	for i := range a.filters {
+		a.filters[i] = kWeighting()
+	}
+
+	return a
+}
+
+// Write processes one stereo frame.
+func (a *audioAnalyzer) Write(left, right float64) {
+	var sum float64
+	for i, x := range [analysisChannels]float64{left, right} {
+		y := a.filters[i][1].process(a.filters[i][0].process(x))
+		sum += y * y
+	}
+
+	a.subBlockSum += sum
+	a.subBlockCount++
+	if a.subBlockCount == loudnessSubBlock {
+		a.subBlocks = append(a.subBlocks, a.subBlockSum/loudnessSubBlock)
+		a.subBlockSum, a.subBlockCount = 0, 0
+		if n := len(a.subBlocks); n >= loudnessSubBlocksPerBlock {
+			var power float64
+			for _, p := range a.subBlocks[n-loudnessSubBlocksPerBlock:] {
+				power += p
+			}
+			a.blockPowers = append(a.blockPowers, power/loudnessSubBlocksPerBlock)
+			a.subBlocks = a.subBlocks[n-loudnessSubBlocksPerBlock+1:]
+		}
+	}
+
+	mono := (left + right) / 2
+	a.hopEnergy += mono * mono
+	a.hopCount++
+	if a.hopCount == tempoHop {
+		energy := math.Log(1 + 1000*a.hopEnergy/tempoHop)
+		a.onsets = append(a.onsets, math.Max(0, energy-a.lastEnergy))
+		a.lastEnergy, a.hopEnergy, a.hopCount = energy, 0, 0
+	}
+}
+
+// Loudness returns gated integrated loudness in LUFS.
+func (a *audioAnalyzer) Loudness() (float64, error) {
+	if len(a.blockPowers) == 0 {
+		return 0, errAudioTooShort
+	}
+
+	blockLoudness := func(power float64) float64 {
+		return -0.691 + 10*math.Log10(power)
+	}
+	gatedMean := func(threshold float64) (float64, int) {
+		var sum float64
+		n := 0
+		for _, p := range a.blockPowers {
+			if p > 0 && blockLoudness(p) > threshold {
+				sum += p
+				n++
+			}
+		}
+		if n == 0 {
+			return 0, 0
+		}
+		return sum / float64(n), n
+	}
+
+	mean, n := gatedMean(loudnessAbsoluteGate)
+	if n == 0 {
+		return math.Inf(-1), nil
+	}
+
+	mean, n = gatedMean(blockLoudness(mean) + loudnessRelativeGate)
+	if n == 0 {
+		return math.Inf(-1), nil
+	}
+
+	return blockLoudness(mean), nil
+}
+
+// BPM estimates tempo by autocorrelation of onset strength envelope.
+func (a *audioAnalyzer) BPM() (float64, error) {
+	hopsPerMinute := 60.0 * analysisSampleRate / tempoHop
+	minLag := int(hopsPerMinute / tempoMaxBPM)
+	maxLag := int(math.Ceil(hopsPerMinute / tempoMinBPM))
+	if len(a.onsets) < maxLag*4 {
+		return 0, errAudioTooShort
+	}
+
+	// Remove DC so that autocorrelation reflects periodicity only
+	var mean float64
+	for _, o := range a.onsets {
+		mean += o
+	}
+	mean /= float64(len(a.onsets))
+	onsets := make([]float64, len(a.onsets))
+	for i, o := range a.onsets {
+		onsets[i] = o - mean
+	}
+
+	corr := make([]float64, maxLag+2)
+	for lag := minLag - 1; lag <= maxLag+1; lag++ {
+		var sum float64
+		for i := lag; i < len(onsets); i++ {
+			sum += onsets[i] * onsets[i-lag]
+		}
+		corr[lag] = sum / float64(len(onsets)-lag)
+	}
+
+	peak := minLag
+	for lag := minLag; lag <= maxLag; lag++ {
+		if corr[lag] > corr[peak] {
+			peak = lag
+		}
+	}
+
+	if corr[peak] <= 0 {
+		return 0, errors.New("no periodic beat found")
+	}
+
+	// Multiples of the beat period correlate as well, prefer the shortest period that is
+	// almost as strong as the strongest one.
+	best := peak
+	for lag := minLag; lag < peak; lag++ {
+		if corr[lag] >= 0.9*corr[peak] && corr[lag] >= corr[lag-1] && corr[lag] >= corr[lag+1] {
+			best = lag
+			break
+		}
+	}
+
+	// Parabolic interpolation for sub-hop precision
+	lag := float64(best)
+	if denom := corr[best-1] - 2*corr[best] + corr[best+1]; denom != 0 {
+		lag += 0.5 * (corr[best-1] - corr[best+1]) / denom
+	}
+
+	return hopsPerMinute / lag, nil
+}
+
+// analyzeAudio reads interleaved little-endian float32 stereo PCM from r.
+func analyzeAudio(r io.Reader) (*audioAnalyzer, error) {
+	analyzer := newAudioAnalyzer()
+	br := bufio.NewReaderSize(r, 64<<10)
+	frame := make([]byte, 4*analysisChannels)
+	for {
+		if _, err := io.ReadFull(br, frame); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				break
+			}
+			return nil, err
+		}
+
+		analyzer.Write(
+			float64(math.Float32frombits(binary.LittleEndian.Uint32(frame[0:4]))),
+			float64(math.Float32frombits(binary.LittleEndian.Uint32(frame[4:8]))),
+		)
+	}
+
+	return analyzer, nil
+}
+
+// analyzeAudioSource decodes source with ffmpeg, returns BPM and integrated loudness as media meta.
+func (a *musicExtractor) analyzeAudioSource(ctx context.Context, source entitysource.EntitySource) ([]driver.MediaMeta, error) {
+	var input string
+	if source.IsLocal() {
+		input = source.LocalPath(ctx)
+	} else {
+		expire := time.Now().Add(UrlExpire)
+		srcUrl, err := source.Url(driver.WithForcePublicEndpoint(ctx, false), entitysource.WithNoInternalProxy(), entitysource.WithExpire(&expire))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get entity url: %w", err)
+		}
+		input = srcUrl.Url
+	}
+
+	cmd := exec.CommandContext(ctx, a.settings.FFMpegPath(ctx),
+		"-v", "error", "-i", input, "-vn",
+		"-ac", fmt.Sprintf("%d", analysisChannels), "-ar", fmt.Sprintf("%d", analysisSampleRate),
+		"-f", "f32le", "-",
+	)
+	analyzer, err := runAudioAnalysis(cmd, analyzeAudio)
+	if err != nil {
+		return nil, err
+	}
+
+	metas := make([]driver.MediaMeta, 0, 2)
+	if bpm, err := analyzer.BPM(); err == nil {
+		metas = append(metas, driver.MediaMeta{Key: MusicBPM, Value: fmt.Sprintf("%.0f", bpm)})
+	} else {
+		a.l.Debug("Failed to detect BPM: %s", err)
+	}
+
+	if loudness, err := analyzer.Loudness(); err == nil && !math.IsInf(loudness, -1) {
+		metas = append(metas, driver.MediaMeta{Key: MusicLoudness, Value: fmt.Sprintf("%.1f", loudness)})
+	} else if err != nil {
+		a.l.Debug("Failed to measure loudness: %s", err)
+	}
+
+	return metas, nil
+}
+
+// runAudioAnalysis starts the decoder cmd and analyzes PCM written to its stdout. If analysis
+// fails before stdout is fully read, the decoder is killed, otherwise it may block forever on
+// writing to the pipe and Wait never returns.
+func runAudioAnalysis(cmd *exec.Cmd, analyze func(r io.Reader) (*audioAnalyzer, error)) (*audioAnalyzer, error) {
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ffmpeg pipe: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start ffmpeg: %w", err)
+	}
+
+	analyzer, err := analyze(stdout)
+	if err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return nil, fmt.Errorf("failed to analyze audio: %w", err)
+	}
+
+	if err := cmd.Wait(); err != nil {
+		return nil, fmt.Errorf("failed to decode audio with ffmpeg: %w", err)
+	}
+
+	return analyzer, nil
+}
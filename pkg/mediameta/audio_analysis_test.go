@@ -0,0 +1,98 @@
+package mediameta
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// pcm encodes samples generated by fn as interleaved float32 stereo PCM.
+func pcm(seconds float64, fn func(i int) float64) []byte {
+	var buf bytes.Buffer
+	frame := make([]byte, 8)
+	for i := 0; i < int(seconds*analysisSampleRate); i++ {
+		v := math.Float32bits(float32(fn(i)))
+		binary.LittleEndian.PutUint32(frame[0:4], v)
+		binary.LittleEndian.PutUint32(frame[4:8], v)
+		buf.Write(frame)
+	}
+
+	return buf.Bytes()
+}
+
+func TestAnalyzeAudio_Loudness(t *testing.T) {
+	a := assert.New(t)
+
+	// 1kHz sine at -6 dBFS on both channels reads about -6 LUFS
+	analyzer, err := analyzeAudio(bytes.NewReader(pcm(3, func(i int) float64 {
+		return 0.5 * math.Sin(2*math.Pi*1000*float64(i)/analysisSampleRate)
+	})))
+	a.NoError(err)
+	loudness, err := analyzer.Loudness()
+	a.NoError(err)
+	a.InDelta(-6.0, loudness, 0.2)
+
+	// Silence is below absolute gate
+	analyzer, err = analyzeAudio(bytes.NewReader(pcm(1, func(i int) float64 { return 0 })))
+	a.NoError(err)
+	loudness, err = analyzer.Loudness()
+	a.NoError(err)
+	a.True(math.IsInf(loudness, -1))
+
+	// Too short
+	analyzer, err = analyzeAudio(bytes.NewReader(pcm(0.1, func(i int) float64 { return 0.5 })))
+	a.NoError(err)
+	_, err = analyzer.Loudness()
+	a.ErrorIs(err, errAudioTooShort)
+}
+
+func TestAnalyzeAudio_BPM(t *testing.T) {
+	a := assert.New(t)
+	for _, bpm := range []float64{90, 128, 150} {
+		period := int(60 * analysisSampleRate / bpm)
+		analyzer, err := analyzeAudio(bytes.NewReader(pcm(20, func(i int) float64 {
+			// 20ms decaying noise-like click on every beat
+			pos := i % period
+			if pos > analysisSampleRate/50 {
+				return 0
+			}
+			return 0.8 * math.Exp(-float64(pos)/200) * math.Sin(float64(i)*0.7)
+		})))
+		a.NoError(err)
+		detected, err := analyzer.BPM()
+		a.NoError(err)
+		a.InDelta(bpm, detected, 1.5, "bpm %v", bpm)
+	}
+}
+
+func TestRunAudioAnalysis_KillOnError(t *testing.T) {
+	a := assert.New(t)
+	yes, err := exec.LookPath("yes")
+	if err != nil {
+		t.Skip("yes is not available")
+	}
+
+	// Decoder writes endlessly, it must be killed once analysis stops reading.
+	done := make(chan error, 1)
+	go func() {
+		_, err := runAudioAnalysis(exec.Command(yes), func(r io.Reader) (*audioAnalyzer, error) {
+			_, _ = r.Read(make([]byte, 8))
+			return nil, errors.New("broken input")
+		})
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		a.ErrorContains(err, "broken input")
+	case <-time.After(5 * time.Second):
+		a.Fail("decoder is not stopped after analysis failed")
+	}
+}
@@ -92,7 +92,8 @@ const (
 
 	GpsLat            = "latitude"
 	GpsLng            = "longitude"
-	GpsAttitude       = "altitude"
+	GpsAltitude       = "altitude"
+	GpsImgDirection   = "img_direction"
 	Artist            = "artist"
 	Copyright         = "copyright"
 	CameraModel       = "camera_model"
@@ -241,17 +242,12 @@ func (e *exifExtractor) Extract(ctx context.Context, ext string, source entityso
 					e.l.Warning("GPS data is invalid: %s", gi.String())
 				}
 
-				if gi.Altitude != 0 {
-					metas = append(metas, driver.MediaMeta{
-						Key:   GpsAttitude,
-						Value: fmt.Sprintf("%d", gi.Altitude),
-					})
-				}
-
 				if !gi.Timestamp.IsZero() {
 					takenTimeGps = gi.Timestamp
 				}
 			}
+
+			metas = append(metas, extractGpsOrientation(ifd)...)
 		}
 	}
 
@@ -263,6 +259,55 @@ func (e *exifExtractor) Extract(ctx context.Context, ext string, source entityso
 	return metas, nil
 }
 
+// extractGpsOrientation extracts altitude and image direction from GPS IFD.
+func extractGpsOrientation(ifd *exif.Ifd) []driver.MediaMeta {
+	metas := make([]driver.MediaMeta, 0, 2)
+	if altitude, ok := gpsRational(ifd, "GPSAltitude"); ok {
+		// Reference 1 means below sea level
+		if ref, err := ifd.FindTagWithName("GPSAltitudeRef"); err == nil && len(ref) > 0 {
+			if value, err := ref[0].Value(); err == nil {
+				if b, ok := value.([]byte); ok && len(b) > 0 && b[0] == 1 {
+					altitude = -altitude
+				}
+			}
+		}
+
+		metas = append(metas, driver.MediaMeta{
+			Key:   GpsAltitude,
+			Value: strconv.FormatFloat(math.Round(altitude*100)/100, 'f', -1, 64),
+		})
+	}
+
+	if direction, ok := gpsRational(ifd, "GPSImgDirection"); ok && direction >= 0 && direction < 360 {
+		metas = append(metas, driver.MediaMeta{
+			Key:   GpsImgDirection,
+			Value: strconv.FormatFloat(math.Round(direction*100)/100, 'f', -1, 64),
+		})
+	}
+
+	return metas
+}
+
+// gpsRational returns the first rational value of given GPS tag.
+func gpsRational(ifd *exif.Ifd, name string) (float64, bool) {
+	tags, err := ifd.FindTagWithName(name)
+	if err != nil || len(tags) == 0 {
+		return 0, false
+	}
+
+	value, err := tags[0].Value()
+	if err != nil {
+		return 0, false
+	}
+
+	rationals, ok := value.([]exifcommon.Rational)
+	if !ok || len(rationals) == 0 || rationals[0].Denominator == 0 {
+		return 0, false
+	}
+
+	return float64(rationals[0].Numerator) / float64(rationals[0].Denominator), true
+}
+
 func ExtractExifMap(exifMap map[string]string, gpsTime time.Time) []driver.MediaMeta {
 	metas := make([]driver.MediaMeta, 0)
 	if value, ok := exifMap["Artist"]; ok {
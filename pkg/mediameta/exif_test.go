@@ -0,0 +1,104 @@
+package mediameta
+
+import (
+	"testing"
+
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/driver"
+	exif "github.com/dsoprea/go-exif/v3"
+	exifcommon "github.com/dsoprea/go-exif/v3/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// buildGpsIfd encodes a GPS IFD with given tags and parses it back.
+func buildGpsIfd(t *testing.T, tags map[string]interface{}) *exif.Ifd {
+	im, err := exifcommon.NewIfdMappingWithStandard()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ti := exif.NewTagIndex()
+	rootIb := exif.NewIfdBuilder(im, ti, exifcommon.IfdStandardIfdIdentity, exifcommon.EncodeDefaultByteOrder)
+	gpsIb := exif.NewIfdBuilder(im, ti, exifcommon.IfdGpsInfoStandardIfdIdentity, exifcommon.EncodeDefaultByteOrder)
+	for name, value := range tags {
+		if err := gpsIb.AddStandardWithName(name, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := rootIb.AddChildIb(gpsIb); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := exif.NewIfdByteEncoder().EncodeToExif(rootIb)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, index, err := exif.Collect(im, ti, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ifd, err := index.RootIfd.ChildWithIfdPath(exifcommon.IfdGpsInfoStandardIfdIdentity)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return ifd
+}
+
+func TestExtractGpsOrientation(t *testing.T) {
+	a := assert.New(t)
+
+	// Below sea level
+	metas := extractGpsOrientation(buildGpsIfd(t, map[string]interface{}{
+		"GPSAltitude":     []exifcommon.Rational{{Numerator: 4305, Denominator: 10}},
+		"GPSAltitudeRef":  []byte{1},
+		"GPSImgDirection": []exifcommon.Rational{{Numerator: 27150, Denominator: 100}},
+	}))
+	a.ElementsMatch([]driver.MediaMeta{
+		{Key: GpsAltitude, Value: "-430.5"},
+		{Key: GpsImgDirection, Value: "271.5"},
+	}, metas)
+
+	// Above sea level, no direction
+	metas = extractGpsOrientation(buildGpsIfd(t, map[string]interface{}{
+		"GPSAltitude":    []exifcommon.Rational{{Numerator: 8849, Denominator: 1}},
+		"GPSAltitudeRef": []byte{0},
+	}))
+	a.Equal([]driver.MediaMeta{{Key: GpsAltitude, Value: "8849"}}, metas)
+
+	// Invalid rational
+	metas = extractGpsOrientation(buildGpsIfd(t, map[string]interface{}{
+		"GPSAltitude": []exifcommon.Rational{{Numerator: 1, Denominator: 0}},
+	}))
+	a.Empty(metas)
+}
+
+func TestGeoPointFromMetas(t *testing.T) {
+	a := assert.New(t)
+
+	point, err := geoPointFromMetas([]driver.MediaMeta{
+		{Key: GpsLat, Value: "48.86"},
+		{Key: GpsLng, Value: "2.33"},
+		{Key: GpsAltitude, Value: "-12.5"},
+		{Key: GpsImgDirection, Value: "90"},
+	})
+	a.NoError(err)
+	if a.NotNil(point) && a.NotNil(point.Altitude) && a.NotNil(point.Direction) {
+		a.Equal(48.86, point.Lat)
+		a.Equal(-12.5, *point.Altitude)
+		a.Equal(90.0, *point.Direction)
+	}
+
+	point, err = geoPointFromMetas([]driver.MediaMeta{{Key: GpsLat, Value: "48.86"}, {Key: GpsLng, Value: "2.33"}})
+	a.NoError(err)
+	if a.NotNil(point) {
+		a.Nil(point.Altitude)
+		a.Nil(point.Direction)
+	}
+
+	point, err = geoPointFromMetas([]driver.MediaMeta{{Key: GpsAltitude, Value: "1"}})
+	a.NoError(err)
+	a.Nil(point)
+}
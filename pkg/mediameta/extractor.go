@@ -18,7 +18,8 @@ type (
 	Extractor interface {
 		// Exts returns the supported file extensions.
 		Exts() []string
-		// Extract extracts the media meta from the given source.
+		// Extract extracts the media meta from the given source. If geocoding is rate limited, metas
+		// extracted by other extractors are returned along with a GeocodingRateLimitedError.
 		Extract(ctx context.Context, ext string, source entitysource.EntitySource, opts ...optionFunc) ([]driver.MediaMeta, error)
 	}
 )
@@ -34,6 +35,7 @@ func init() {
 func NewExtractorManager(ctx context.Context, settings setting.Provider, l logging.Logger, client request.Client) Extractor {
 	e := &extractorManager{
 		settings: settings,
+		l:        l,
 		extMap:   make(map[string][]Extractor),
 	}
 
@@ -54,6 +56,21 @@ func NewExtractorManager(ctx context.Context, settings setting.Provider, l loggi
 		extractors = append(extractors, ffprobeE)
 	}
 
+	if e.settings.MediaMetaXMPEnabled(ctx) {
+		xmpE := newXMPExtractor(settings, l)
+		extractors = append(extractors, xmpE)
+	}
+
+	if e.settings.MediaMetaIPTCEnabled(ctx) {
+		iptcE := newIPTCExtractor(settings, l)
+		extractors = append(extractors, iptcE)
+	}
+
+	if e.settings.MediaMetaPdfEnabled(ctx) {
+		pdfE := newPdfMetaExtractor(settings, l)
+		extractors = append(extractors, pdfE)
+	}
+
 	if e.settings.MediaMetaGeocodingEnabled(ctx) {
 		geocodingE := newGeocodingExtractor(settings, l, client)
 		extractors = append(extractors, geocodingE)
@@ -73,6 +90,7 @@ func NewExtractorManager(ctx context.Context, settings setting.Provider, l loggi
 
 type extractorManager struct {
 	settings setting.Provider
+	l        logging.Logger
 	extMap   map[string][]Extractor
 }
 
@@ -83,9 +101,22 @@ func (e *extractorManager) Exts() []string {
 func (e *extractorManager) Extract(ctx context.Context, ext string, source entitysource.EntitySource, opts ...optionFunc) ([]driver.MediaMeta, error) {
 	if extractor, ok := e.extMap[ext]; ok {
 		res := []driver.MediaMeta{}
-		for _, e := range extractor {
+		var rateLimitErr error
+		for _, ex := range extractor {
 			_, _ = source.Seek(0, io.SeekStart)
-			data, err := e.Extract(ctx, ext, source, append(opts, WithExtracted(res))...)
+			data, err := ex.Extract(ctx, ext, source, append(opts, WithExtracted(res))...)
+			if errors.Is(err, ErrGeocodingTimeout) {
+				// Slow geocoding provider should not fail the whole extraction
+				e.l.Warning("Skip geocoding of media meta: %s", err)
+				continue
+			}
+
+			var rateLimited *GeocodingRateLimitedError
+			if errors.As(err, &rateLimited) {
+				rateLimitErr = err
+				continue
+			}
+
 			if err != nil {
 				return nil, err
 			}
@@ -93,15 +124,16 @@ func (e *extractorManager) Extract(ctx context.Context, ext string, source entit
 			res = append(res, data...)
 		}
 
-		return res, nil
+		return res, rateLimitErr
 	} else {
 		return nil, nil
 	}
 }
 
 type option struct {
-	extracted []driver.MediaMeta
-	language  string
+	extracted  []driver.MediaMeta
+	language   string
+	xmpSidecar io.Reader
 }
 
 type optionFunc func(*option)
@@ -122,6 +154,13 @@ func WithLanguage(language string) optionFunc {
 	})
 }
 
+// WithXMPSidecar sets the sidecar .xmp file of the source, which takes precedence over embedded XMP packet.
+func WithXMPSidecar(sidecar io.Reader) optionFunc {
+	return optionFunc(func(o *option) {
+		o.xmpSidecar = sidecar
+	})
+}
+
 // checkFileSize checks if the file size exceeds the limit.
 func checkFileSize(localLimit, remoteLimit int64, source entitysource.EntitySource) error {
 	if source.IsLocal() && localLimit > 0 && source.Entity().Size() > localLimit {
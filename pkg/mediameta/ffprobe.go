@@ -29,17 +29,23 @@ type (
 	}
 
 	Stream struct {
-		Index         int    `json:"index"`
-		CodecName     string `json:"codec_name"`
-		CodecLongName string `json:"codec_long_name"`
-		CodecType     string `json:"codec_type"`
-		Width         int    `json:"width"`
-		Height        int    `json:"height"`
-		Duration      string `json:"duration"`
-		Bitrate       string `json:"bit_rate"`
+		Index         int               `json:"index"`
+		CodecName     string            `json:"codec_name"`
+		CodecLongName string            `json:"codec_long_name"`
+		CodecType     string            `json:"codec_type"`
+		Width         int               `json:"width"`
+		Height        int               `json:"height"`
+		Duration      string            `json:"duration"`
+		Bitrate       string            `json:"bit_rate"`
+		AvgFrameRate  string            `json:"avg_frame_rate"`
+		SampleRate    string            `json:"sample_rate"`
+		Channels      int               `json:"channels"`
+		ChannelLayout string            `json:"channel_layout"`
+		Tags          map[string]string `json:"tags"`
 	}
 	Chapter struct {
-		Id        int               `json:"id"`
+		// Chapter ID can be a 64-bit unsigned UID in Matroska files, it is not used as key of chapter meta.
+		Id        json.Number       `json:"id"`
 		StartTime string            `json:"start_time"`
 		EndTime   string            `json:"end_time"`
 		Tags      map[string]string `json:"tags"`
@@ -68,6 +74,12 @@ const (
 	StreamMediaStartTime     = "start_time"
 	StreamMediaEndTime       = "end_time"
 	StreamMediaChapterName   = "name"
+	StreamMediaLanguage      = "language"
+	StreamMediaTitle         = "title"
+	StreamMediaFrameRate     = "frame_rate"
+	StreamMediaSampleRate    = "sample_rate"
+	StreamMediaChannels      = "channels"
+	StreamMediaChannelLayout = "channel_layout"
 	StreamMetaTitle          = "title"
 	StreamMetaDescription    = "description"
 )
@@ -204,10 +216,48 @@ func ProbeMetaTransform(meta *FFProbeMeta) []driver.MediaMeta {
 				Value: stream.Bitrate,
 			})
 		}
+		// Frame rate is "0/0" for streams without fixed frame rate
+		if stream.AvgFrameRate != "" && stream.AvgFrameRate != "0/0" && stream.CodecType == "video" {
+			res = append(res, driver.MediaMeta{
+				Key:   keyPrefix + StreamMediaFrameRate,
+				Value: stream.AvgFrameRate,
+			})
+		}
+		if stream.SampleRate != "" {
+			res = append(res, driver.MediaMeta{
+				Key:   keyPrefix + StreamMediaSampleRate,
+				Value: stream.SampleRate,
+			})
+		}
+		if stream.Channels > 0 {
+			res = append(res, driver.MediaMeta{
+				Key:   keyPrefix + StreamMediaChannels,
+				Value: strconv.Itoa(stream.Channels),
+			})
+		}
+		if stream.ChannelLayout != "" {
+			res = append(res, driver.MediaMeta{
+				Key:   keyPrefix + StreamMediaChannelLayout,
+				Value: stream.ChannelLayout,
+			})
+		}
+		// "und" stands for undetermined language
+		if language, ok := stream.Tags["language"]; ok && language != "" && language != "und" {
+			res = append(res, driver.MediaMeta{
+				Key:   keyPrefix + StreamMediaLanguage,
+				Value: language,
+			})
+		}
+		if title, ok := stream.Tags["title"]; ok && title != "" {
+			res = append(res, driver.MediaMeta{
+				Key:   keyPrefix + StreamMediaTitle,
+				Value: title,
+			})
+		}
 	}
 
-	for _, chapter := range meta.Chapters {
-		keyPrefix := fmt.Sprintf("%s%d_", StreamMediaChapterPrefix, chapter.Id)
+	for i, chapter := range meta.Chapters {
+		keyPrefix := fmt.Sprintf("%s%d_", StreamMediaChapterPrefix, i)
 		if chapter.StartTime != "" {
 			res = append(res, driver.MediaMeta{
 				Key:   keyPrefix + StreamMediaStartTime,
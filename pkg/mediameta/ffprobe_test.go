@@ -0,0 +1,61 @@
+package mediameta
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/driver"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+const testFFProbeOutput = `{
+  "streams": [
+    {"index": 0, "codec_name": "h264", "codec_type": "video", "width": 1920, "height": 1080, "avg_frame_rate": "24000/1001"},
+    {"index": 1, "codec_name": "aac", "codec_type": "audio", "sample_rate": "48000", "channels": 6, "channel_layout": "5.1",
+     "avg_frame_rate": "0/0", "tags": {"language": "jpn", "title": "Original"}},
+    {"index": 2, "codec_name": "subrip", "codec_type": "subtitle", "tags": {"language": "eng"}},
+    {"index": 3, "codec_name": "subrip", "codec_type": "subtitle", "tags": {"language": "und"}}
+  ],
+  "chapters": [
+    {"id": 18374239418204719001, "start_time": "0.000000", "end_time": "90.500000", "tags": {"title": "Opening"}},
+    {"id": 2, "start_time": "90.500000", "end_time": "1440.000000"}
+  ],
+  "format": {"format_name": "matroska,webm", "duration": "1440.000000"}
+}`
+
+func TestProbeMetaTransform(t *testing.T) {
+	a := assert.New(t)
+	var meta FFProbeMeta
+	a.NoError(json.Unmarshal([]byte(testFFProbeOutput), &meta))
+
+	res := lo.SliceToMap(ProbeMetaTransform(&meta), func(m driver.MediaMeta) (string, string) {
+		a.Equal(driver.MetaTypeStreamMedia, m.Type)
+		return m.Key, m.Value
+	})
+
+	a.Equal("1440.000000", res[StreamMediaDuration])
+	a.Equal("24000/1001", res["stream_0_video_frame_rate"])
+	a.Equal("48000", res["stream_1_audio_sample_rate"])
+	a.Equal("6", res["stream_1_audio_channels"])
+	a.Equal("5.1", res["stream_1_audio_channel_layout"])
+	a.Equal("jpn", res["stream_1_audio_language"])
+	a.Equal("Original", res["stream_1_audio_title"])
+	a.NotContains(res, "stream_1_audio_frame_rate")
+	a.Equal("eng", res["stream_2_subtitle_language"])
+	a.NotContains(res, "stream_3_subtitle_language")
+
+	// Chapters are keyed by index
+	a.Equal("0.000000", res["chapter_0_start_time"])
+	a.Equal("90.500000", res["chapter_0_end_time"])
+	a.Equal("Opening", res["chapter_0_name"])
+	a.Equal("1440.000000", res["chapter_1_end_time"])
+	a.NotContains(res, "chapter_1_name")
+}
+
+func TestProbeMetaTransform_NoChapters(t *testing.T) {
+	a := assert.New(t)
+	var meta FFProbeMeta
+	a.NoError(json.Unmarshal([]byte(`{"streams":[],"chapters":[],"format":{"format_name":"mp4"}}`), &meta))
+	a.Equal([]driver.MediaMeta{{Type: driver.MetaTypeStreamMedia, Key: StreamMediaFormat, Value: "mp4"}}, ProbeMetaTransform(&meta))
+}
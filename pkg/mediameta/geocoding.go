@@ -3,10 +3,13 @@ package mediameta
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"net/url"
 	"strconv"
+	"sync/atomic"
 
 	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/driver"
 	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/manager/entitysource"
@@ -15,7 +18,14 @@ import (
 	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
 )
 
-const mapBoxURL = "https://api.mapbox.com/search/geocode/v6/reverse"
+var (
+	mapBoxURL      = "https://api.mapbox.com/search/geocode/v6/reverse"
+	mapBoxBatchURL = "https://api.mapbox.com/search/geocode/v6/batch"
+)
+
+// ErrGeocodingTimeout is returned when geocoding provider does not respond within
+// media_meta_geocoding_timeout, other metas of the file are still saved.
+var ErrGeocodingTimeout = errors.New("geocoding request timed out")
 
 const (
 	Street   = "street"
@@ -30,14 +40,19 @@ type geocodingExtractor struct {
 	settings setting.Provider
 	l        logging.Logger
 	client   request.Client
+	batcher  *mapboxBatcher
+	// limitedUntil is the Unix nano time when Mapbox rate limit is reset.
+	limitedUntil atomic.Int64
 }
 
 func newGeocodingExtractor(settings setting.Provider, l logging.Logger, client request.Client) *geocodingExtractor {
-	return &geocodingExtractor{
+	e := &geocodingExtractor{
 		settings: settings,
 		l:        l,
 		client:   client,
 	}
+	e.batcher = &mapboxBatcher{e: e}
+	return e
 }
 
 func (e *geocodingExtractor) Exts() []string {
@@ -50,14 +65,53 @@ func (e *geocodingExtractor) Extract(ctx context.Context, ext string, source ent
 		opt.apply(option)
 	}
 
-	// Find GPS info from extracted
-	var latStr, lngStr string
-	for _, meta := range option.extracted {
-		if meta.Key == GpsLat {
-			latStr = meta.Value
+	point, err := geoPointFromMetas(option.extracted)
+	if err != nil {
+		return nil, fmt.Errorf("geocoding: %w", err)
+	}
+
+	if point == nil {
+		return nil, nil
+	}
+
+	metas, err := e.getGeocoding(ctx, point, option.language)
+	if err != nil {
+		if ctx.Err() == nil && isTimeout(err) {
+			return nil, fmt.Errorf("geocoding: %w: %s", ErrGeocodingTimeout, err)
 		}
-		if meta.Key == GpsLng {
+		return nil, fmt.Errorf("geocoding: failed to get geocoding: %w", err)
+	}
+
+	for i, _ := range metas {
+		metas[i].Type = driver.MetaTypeGeocoding
+	}
+
+	return metas, nil
+}
+
+// geoPoint is the input of geocoding providers. Altitude (meters) and Direction (degrees)
+// are nil if not available in EXIF.
+type geoPoint struct {
+	Lat       float64
+	Lng       float64
+	Altitude  *float64
+	Direction *float64
+}
+
+// geoPointFromMetas builds geoPoint from extracted EXIF metas, nil is returned if
+// latitude or longitude is missing.
+func geoPointFromMetas(metas []driver.MediaMeta) (*geoPoint, error) {
+	var latStr, lngStr, altitudeStr, directionStr string
+	for _, meta := range metas {
+		switch meta.Key {
+		case GpsLat:
+			latStr = meta.Value
+		case GpsLng:
 			lngStr = meta.Value
+		case GpsAltitude:
+			altitudeStr = meta.Value
+		case GpsImgDirection:
+			directionStr = meta.Value
 		}
 	}
 
@@ -67,43 +121,58 @@ func (e *geocodingExtractor) Extract(ctx context.Context, ext string, source ent
 
 	lat, err := strconv.ParseFloat(latStr, 64)
 	if err != nil {
-		return nil, fmt.Errorf("geocoding: failed to parse latitude: %w", err)
+		return nil, fmt.Errorf("failed to parse latitude: %w", err)
 	}
 
 	lng, err := strconv.ParseFloat(lngStr, 64)
 	if err != nil {
-		return nil, fmt.Errorf("geocoding: failed to parse longitude: %w", err)
+		return nil, fmt.Errorf("failed to parse longitude: %w", err)
 	}
 
-	metas, err := e.getGeocoding(ctx, lat, lng, option.language)
-	if err != nil {
-		return nil, fmt.Errorf("geocoding: failed to get geocoding: %w", err)
+	point := &geoPoint{Lat: lat, Lng: lng}
+	if altitude, err := strconv.ParseFloat(altitudeStr, 64); err == nil {
+		point.Altitude = &altitude
 	}
 
-	for i, _ := range metas {
-		metas[i].Type = driver.MetaTypeGeocoding
+	if direction, err := strconv.ParseFloat(directionStr, 64); err == nil {
+		point.Direction = &direction
 	}
 
-	return metas, nil
+	return point, nil
+}
+
+func (e *geocodingExtractor) getGeocoding(ctx context.Context, point *geoPoint, language string) ([]driver.MediaMeta, error) {
+	if e.settings.MediaMetaGeocodingProvider(ctx) == setting.GeocodingProviderNominatim {
+		return e.getNominatimGeocoding(ctx, point, language)
+	}
+
+	if batchSize := e.settings.MediaMetaGeocodingBatchSize(ctx); batchSize > 1 {
+		return e.batcher.geocode(ctx, point, language, batchSize)
+	}
+
+	return e.getMapboxGeocoding(ctx, point, language)
 }
 
-func (e *geocodingExtractor) getGeocoding(ctx context.Context, lat, lng float64, language string) ([]driver.MediaMeta, error) {
+func (e *geocodingExtractor) getMapboxGeocoding(ctx context.Context, point *geoPoint, language string) ([]driver.MediaMeta, error) {
 	values := url.Values{}
-	values.Add("longitude", fmt.Sprintf("%f", lng))
-	values.Add("latitude", fmt.Sprintf("%f", lat))
+	values.Add("longitude", fmt.Sprintf("%f", point.Lng))
+	values.Add("latitude", fmt.Sprintf("%f", point.Lat))
 	values.Add("limit", "1")
 	values.Add("access_token", e.settings.MediaMetaGeocodingMapboxAK(ctx))
 	if language != "" {
 		values.Add("language", language)
 	}
 
-	resp, err := e.client.Request(
+	resp, err := e.mapboxRequest(
+		ctx,
 		"GET",
 		mapBoxURL+"?"+values.Encode(),
 		nil,
 		request.WithContext(ctx),
+		request.WithTimeout(e.settings.MediaMetaGeocodingTimeout(ctx)),
 		request.WithLogger(e.l),
-	).CheckHTTPResponse(http.StatusOK).GetResponse()
+		request.WithHeader(e.userAgentHeader(ctx)),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get geocoding from mapbox: %w", err)
 	}
@@ -113,12 +182,17 @@ func (e *geocodingExtractor) getGeocoding(ctx context.Context, lat, lng float64,
 		return nil, fmt.Errorf("failed to unmarshal geocoding from mapbox: %w", err)
 	}
 
-	if len(geocoding.Features) == 0 {
-		return nil, nil
+	return mapboxFeatureMetas(geocoding.Features), nil
+}
+
+// mapboxFeatureMetas converts context of the first feature into media metas.
+func mapboxFeatureMetas(features []Feature) []driver.MediaMeta {
+	if len(features) == 0 {
+		return nil
 	}
 
 	metas := make([]driver.MediaMeta, 0)
-	contexts := geocoding.Features[0].Properties.Context
+	contexts := features[0].Properties.Context
 	if contexts.Street != nil {
 		metas = append(metas, driver.MediaMeta{
 			Key:   Street,
@@ -156,7 +230,23 @@ func (e *geocodingExtractor) getGeocoding(ctx context.Context, lat, lng float64,
 		})
 	}
 
-	return metas, nil
+	return metas
+}
+
+// isTimeout returns true if err is caused by request timeout.
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+}
+
+// userAgentHeader returns header with User-Agent configured in settings, if any.
+func (e *geocodingExtractor) userAgentHeader(ctx context.Context) http.Header {
+	header := http.Header{}
+	if ua := e.settings.MediaMetaGeocodingUserAgent(ctx); ua != "" {
+		header.Set("User-Agent", ua)
+	}
+
+	return header
 }
 
 // MapboxGeocodingResponse represents the response from Mapbox Geocoding API
@@ -0,0 +1,71 @@
+package mediameta
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/driver"
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/manager/entitysource"
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/cloudreve/Cloudreve/v4/pkg/request"
+	"github.com/stretchr/testify/assert"
+)
+
+type seekableEntitySource struct {
+	entitysource.EntitySource
+}
+
+func (s *seekableEntitySource) Seek(offset int64, whence int) (int64, error) { return 0, nil }
+
+// gpsExtractor mocks EXIF extractor that always finds GPS coordinates.
+type gpsExtractor struct{}
+
+func (e *gpsExtractor) Exts() []string { return []string{"jpg"} }
+
+func (e *gpsExtractor) Extract(ctx context.Context, ext string, source entitysource.EntitySource, opts ...optionFunc) ([]driver.MediaMeta, error) {
+	return []driver.MediaMeta{{Key: GpsLat, Value: "48.86"}, {Key: GpsLng, Value: "2.33"}}, nil
+}
+
+func TestGeocodingExtractor_Timeout(t *testing.T) {
+	a := assert.New(t)
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	oldURL := mapBoxURL
+	mapBoxURL = srv.URL
+	defer func() { mapBoxURL = oldURL }()
+
+	l := logging.NewConsoleLogger(logging.LevelError)
+	e := newGeocodingExtractor(&mapboxSettings{timeout: 100 * time.Millisecond}, l, request.NewClient(&masterConfig{}))
+	source := &seekableEntitySource{}
+	gps, _ := (&gpsExtractor{}).Extract(context.Background(), "jpg", source)
+	opt := WithExtracted(gps)
+
+	start := time.Now()
+	_, err := e.Extract(context.Background(), "jpg", source, opt)
+	a.ErrorIs(err, ErrGeocodingTimeout)
+	a.Less(time.Since(start), 5*time.Second)
+
+	// Other metas are kept
+	m := &extractorManager{l: l, extMap: map[string][]Extractor{"jpg": {&gpsExtractor{}, e}}}
+	metas, err := m.Extract(context.Background(), "jpg", source)
+	a.NoError(err)
+	a.Len(metas, 2)
+
+	// Canceled job is not treated as geocoding timeout
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = e.Extract(ctx, "jpg", source, opt)
+	a.Error(err)
+	a.NotErrorIs(err, ErrGeocodingTimeout)
+}
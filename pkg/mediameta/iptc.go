@@ -0,0 +1,249 @@
+package mediameta
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/driver"
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/manager/entitysource"
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
+	"golang.org/x/text/encoding/charmap"
+)
+
+const (
+	IPTCKeywords = "keywords"
+	IPTCCaption  = "caption"
+	IPTCByline   = "byline"
+	IPTCHeadline = "headline"
+
+	jpegMarkerSOI   = 0xD8
+	jpegMarkerSOS   = 0xDA
+	jpegMarkerEOI   = 0xD9
+	jpegMarkerAPP13 = 0xED
+
+	// Photoshop image resource ID of IPTC-IIM block
+	irbIPTC = 0x0404
+
+	iptcTagMarker          = 0x1C
+	iptcApplicationRecord  = 2
+	iptcDatasetKeywords    = 25
+	iptcDatasetByline      = 80
+	iptcDatasetHeadline    = 105
+	iptcDatasetCaption     = 120
+	iptcKeywordsSeparator  = ", "
+	maxJpegSegmentsToParse = 64
+)
+
+var (
+	photoshopSignature = []byte("Photoshop 3.0\x00")
+	irbSignature       = []byte("8BIM")
+
+	errNoIPTC = errors.New("no iptc data found")
+)
+
+type iptcExtractor struct {
+	settings setting.Provider
+	l        logging.Logger
+}
+
+func newIPTCExtractor(settings setting.Provider, l logging.Logger) *iptcExtractor {
+	return &iptcExtractor{
+		settings: settings,
+		l:        l,
+	}
+}
+
+func (e *iptcExtractor) Exts() []string {
+	return []string{"jpg", "jpeg"}
+}
+
+func (e *iptcExtractor) Extract(ctx context.Context, ext string, source entitysource.EntitySource, opts ...optionFunc) ([]driver.MediaMeta, error) {
+	localLimit, remoteLimit := e.settings.MediaMetaExifSizeLimit(ctx)
+	if err := checkFileSize(localLimit, remoteLimit, source); err != nil {
+		return nil, err
+	}
+
+	block, err := findJpegIPTC(source)
+	if errors.Is(err, errNoIPTC) {
+		e.l.Debug("No iptc data found")
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to find iptc block: %w", err)
+	}
+
+	datasets := parseIPTC(block)
+	metas := make([]driver.MediaMeta, 0)
+	fields := []struct {
+		key   string
+		value string
+	}{
+		{IPTCKeywords, strings.Join(datasets[iptcDatasetKeywords], iptcKeywordsSeparator)},
+		{IPTCCaption, strings.Join(datasets[iptcDatasetCaption], "\n")},
+		{IPTCByline, strings.Join(datasets[iptcDatasetByline], iptcKeywordsSeparator)},
+		{IPTCHeadline, strings.Join(datasets[iptcDatasetHeadline], "\n")},
+	}
+	for _, field := range fields {
+		if field.value != "" {
+			metas = append(metas, driver.MediaMeta{Type: driver.MetaTypeIPTC, Key: field.key, Value: field.value})
+		}
+	}
+
+	// Map keywords to file tags so that they are searchable
+	if e.settings.MediaMetaIPTCKeywordsAsTags(ctx) {
+		for _, keyword := range datasets[iptcDatasetKeywords] {
+			metas = append(metas, driver.MediaMeta{Type: driver.MetaTypeTag, Key: keyword})
+		}
+	}
+
+	return metas, nil
+}
+
+// findJpegIPTC walks through JPEG segments before image data, and returns the IPTC-IIM block in
+// Photoshop image resources of APP13 segment.
+func findJpegIPTC(r io.Reader) ([]byte, error) {
+	br := bufio.NewReader(r)
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, err
+	}
+
+	if header[0] != 0xFF || header[1] != jpegMarkerSOI {
+		return nil, errors.New("not a jpeg file")
+	}
+
+	for i := 0; i < maxJpegSegmentsToParse; i++ {
+		if _, err := io.ReadFull(br, header); err != nil {
+			return nil, err
+		}
+
+		if header[0] != 0xFF {
+			return nil, errors.New("invalid jpeg marker")
+		}
+
+		marker := header[1]
+		if marker == jpegMarkerSOS || marker == jpegMarkerEOI {
+			break
+		}
+
+		if _, err := io.ReadFull(br, header); err != nil {
+			return nil, err
+		}
+
+		length := int(binary.BigEndian.Uint16(header)) - 2
+		if length < 0 {
+			return nil, errors.New("invalid jpeg segment length")
+		}
+
+		if marker != jpegMarkerAPP13 {
+			if _, err := br.Discard(length); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		segment := make([]byte, length)
+		if _, err := io.ReadFull(br, segment); err != nil {
+			return nil, err
+		}
+
+		if block := findIRBResource(segment, irbIPTC); block != nil {
+			return block, nil
+		}
+	}
+
+	return nil, errNoIPTC
+}
+
+// findIRBResource finds resource with given ID in Photoshop image resource blocks.
+func findIRBResource(segment []byte, id uint16) []byte {
+	if !bytes.HasPrefix(segment, photoshopSignature) {
+		return nil
+	}
+
+	data := segment[len(photoshopSignature):]
+	for len(data) >= 12 && bytes.Equal(data[:4], irbSignature) {
+		resourceID := binary.BigEndian.Uint16(data[4:6])
+
+		// Pascal string name, padded to even length
+		nameLen := int(data[6]) + 1
+		if nameLen%2 != 0 {
+			nameLen++
+		}
+
+		sizeOffset := 6 + nameLen
+		if len(data) < sizeOffset+4 {
+			return nil
+		}
+
+		size := int(binary.BigEndian.Uint32(data[sizeOffset : sizeOffset+4]))
+		dataOffset := sizeOffset + 4
+		if size < 0 || len(data) < dataOffset+size {
+			return nil
+		}
+
+		if resourceID == id {
+			return data[dataOffset : dataOffset+size]
+		}
+
+		// Resource data is padded to even length
+		next := dataOffset + size
+		if next%2 != 0 {
+			next++
+		}
+		if next > len(data) {
+			return nil
+		}
+		data = data[next:]
+	}
+
+	return nil
+}
+
+// parseIPTC parses datasets of application record in IPTC-IIM block, values are indexed by dataset number.
+func parseIPTC(block []byte) map[byte][]string {
+	datasets := make(map[byte][]string)
+	for len(block) >= 5 && block[0] == iptcTagMarker {
+		record, dataset := block[1], block[2]
+		length := int(binary.BigEndian.Uint16(block[3:5]))
+
+		// Extended dataset is not used by text fields we care about
+		if length&0x8000 != 0 {
+			break
+		}
+
+		if len(block) < 5+length {
+			break
+		}
+
+		value := strings.TrimSpace(decodeIPTCString(block[5 : 5+length]))
+		if record == iptcApplicationRecord && value != "" {
+			datasets[dataset] = append(datasets[dataset], value)
+		}
+
+		block = block[5+length:]
+	}
+
+	return datasets
+}
+
+// decodeIPTCString decodes IPTC string, which is UTF-8 in most modern files, Latin-1 is assumed otherwise.
+func decodeIPTCString(value []byte) string {
+	if utf8.Valid(value) {
+		return string(value)
+	}
+
+	decoded, err := charmap.ISO8859_1.NewDecoder().Bytes(value)
+	if err != nil {
+		return string(value)
+	}
+
+	return string(decoded)
+}
@@ -0,0 +1,71 @@
+package mediameta
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func buildTestIPTCJpeg(datasets ...[]byte) []byte {
+	var iim bytes.Buffer
+	for _, d := range datasets {
+		iim.Write(d)
+	}
+
+	var irb bytes.Buffer
+	irb.Write(photoshopSignature)
+	// Unrelated resource with odd data length
+	irb.Write([]byte("8BIM\x03\xED\x00\x00\x00\x00\x00\x03abc\x00"))
+	irb.Write([]byte("8BIM\x04\x04\x00\x00"))
+	_ = binary.Write(&irb, binary.BigEndian, uint32(iim.Len()))
+	irb.Write(iim.Bytes())
+
+	var jpeg bytes.Buffer
+	jpeg.Write([]byte{0xFF, 0xD8})
+	// APP0
+	jpeg.Write([]byte{0xFF, 0xE0, 0x00, 0x07, 'J', 'F', 'I', 'F', 0x00})
+	jpeg.Write([]byte{0xFF, 0xED})
+	_ = binary.Write(&jpeg, binary.BigEndian, uint16(irb.Len()+2))
+	jpeg.Write(irb.Bytes())
+	jpeg.Write([]byte{0xFF, 0xDA, 0x00, 0x02})
+	return jpeg.Bytes()
+}
+
+func iptcDataset(dataset byte, value string) []byte {
+	res := []byte{iptcTagMarker, iptcApplicationRecord, dataset, 0, 0}
+	binary.BigEndian.PutUint16(res[3:], uint16(len(value)))
+	return append(res, value...)
+}
+
+func TestFindJpegIPTC(t *testing.T) {
+	a := assert.New(t)
+	data := buildTestIPTCJpeg(
+		[]byte{iptcTagMarker, 1, 90, 0, 3, 0x1B, 0x25, 0x47},
+		iptcDataset(iptcDatasetKeywords, "beach"),
+		iptcDataset(iptcDatasetKeywords, " sunset "),
+		iptcDataset(iptcDatasetCaption, "Sunset at the beach"),
+		iptcDataset(iptcDatasetByline, "Jane Doe"),
+		iptcDataset(iptcDatasetHeadline, "Caf\xe9"),
+	)
+
+	block, err := findJpegIPTC(bytes.NewReader(data))
+	a.NoError(err)
+	datasets := parseIPTC(block)
+	a.Equal([]string{"beach", "sunset"}, datasets[iptcDatasetKeywords])
+	a.Equal([]string{"Sunset at the beach"}, datasets[iptcDatasetCaption])
+	a.Equal([]string{"Jane Doe"}, datasets[iptcDatasetByline])
+	// Latin-1 fallback
+	a.Equal([]string{"Café"}, datasets[iptcDatasetHeadline])
+	// Envelope record is ignored
+	a.NotContains(datasets, byte(90))
+
+	// No APP13 segment
+	_, err = findJpegIPTC(bytes.NewReader([]byte{0xFF, 0xD8, 0xFF, 0xDA, 0x00, 0x02}))
+	a.ErrorIs(err, errNoIPTC)
+
+	// Not a JPEG
+	_, err = findJpegIPTC(bytes.NewReader([]byte("not a jpeg")))
+	a.Error(err)
+}
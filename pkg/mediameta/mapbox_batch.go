@@ -0,0 +1,212 @@
+package mediameta
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/driver"
+	"github.com/cloudreve/Cloudreve/v4/pkg/request"
+)
+
+// mapboxBatchLinger is how long a pending coordinate waits for others before the batch is sent.
+var mapboxBatchLinger = 500 * time.Millisecond
+
+type (
+	// mapboxBatcher coalesces reverse geocoding of media meta tasks running at the same time, e.g.
+	// when a folder of geotagged photos is uploaded or imported, into Mapbox batch requests.
+	mapboxBatcher struct {
+		e *geocodingExtractor
+
+		mu      sync.Mutex
+		pending []*mapboxBatchItem
+		timer   *time.Timer
+	}
+
+	mapboxBatchItem struct {
+		ctx      context.Context
+		point    *geoPoint
+		language string
+
+		done  chan struct{}
+		metas []driver.MediaMeta
+		err   error
+	}
+
+	// MapboxBatchQuery is a single query in Mapbox batch geocoding request
+	MapboxBatchQuery struct {
+		Longitude float64 `json:"longitude"`
+		Latitude  float64 `json:"latitude"`
+		Limit     int     `json:"limit"`
+		Language  string  `json:"language,omitempty"`
+	}
+
+	// MapboxBatchResponse represents the response from Mapbox batch geocoding API, results are
+	// in the same order as queries.
+	MapboxBatchResponse struct {
+		Batch []MapboxGeocodingResponse `json:"batch"`
+	}
+)
+
+// geocode queues given point and waits for its result. The batch is sent once batchSize points
+// are pending or mapboxBatchLinger is elapsed.
+func (b *mapboxBatcher) geocode(ctx context.Context, point *geoPoint, language string, batchSize int) ([]driver.MediaMeta, error) {
+	item := &mapboxBatchItem{
+		ctx:      ctx,
+		point:    point,
+		language: language,
+		done:     make(chan struct{}),
+	}
+
+	b.mu.Lock()
+	b.pending = append(b.pending, item)
+	if len(b.pending) >= batchSize {
+		items := b.takeLocked()
+		b.mu.Unlock()
+		go b.flush(items)
+	} else {
+		if b.timer == nil {
+			b.timer = time.AfterFunc(mapboxBatchLinger, func() {
+				b.mu.Lock()
+				items := b.takeLocked()
+				b.mu.Unlock()
+				b.flush(items)
+			})
+		}
+		b.mu.Unlock()
+	}
+
+	select {
+	case <-item.done:
+		return item.metas, item.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// takeLocked removes and returns all pending items, b.mu must be held.
+func (b *mapboxBatcher) takeLocked() []*mapboxBatchItem {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+
+	items := b.pending
+	b.pending = nil
+	return items
+}
+
+// flush geocodes given items with one batch request. A single item is geocoded with the
+// reverse API, and items are geocoded one by one if batch request fails.
+func (b *mapboxBatcher) flush(items []*mapboxBatchItem) {
+	if len(items) == 0 {
+		return
+	}
+
+	defer func() {
+		for _, item := range items {
+			close(item.done)
+		}
+	}()
+
+	if len(items) > 1 {
+		results, err := b.batchGeocoding(items)
+		if err == nil {
+			for i, item := range items {
+				item.metas = results[i]
+			}
+			return
+		}
+
+		// Per-item requests would be rate limited as well
+		var rateLimited *GeocodingRateLimitedError
+		if errors.As(err, &rateLimited) {
+			for _, item := range items {
+				item.err = err
+			}
+			return
+		}
+
+		b.e.l.Warning("Mapbox batch geocoding of %d items failed, fallback to per-item requests: %s", len(items), err)
+	}
+
+	for _, item := range items {
+		if item.ctx.Err() != nil {
+			item.err = item.ctx.Err()
+			continue
+		}
+
+		item.metas, item.err = b.e.getMapboxGeocoding(item.ctx, item.point, item.language)
+	}
+}
+
+func (b *mapboxBatcher) batchGeocoding(items []*mapboxBatchItem) ([][]driver.MediaMeta, error) {
+	// Batch request is not bound to any task, use a context only canceled when all tasks are gone.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for _, item := range items {
+			select {
+			case <-item.ctx.Done():
+			case <-stop:
+				return
+			}
+		}
+		cancel()
+	}()
+
+	queries := make([]MapboxBatchQuery, len(items))
+	for i, item := range items {
+		queries[i] = MapboxBatchQuery{
+			Longitude: item.point.Lng,
+			Latitude:  item.point.Lat,
+			Limit:     1,
+			Language:  item.language,
+		}
+	}
+
+	body, err := json.Marshal(queries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal batch queries: %w", err)
+	}
+
+	values := url.Values{}
+	values.Add("access_token", b.e.settings.MediaMetaGeocodingMapboxAK(items[0].ctx))
+	header := b.e.userAgentHeader(items[0].ctx)
+	header.Set("Content-Type", "application/json")
+	resp, err := b.e.mapboxRequest(
+		ctx,
+		"POST",
+		mapBoxBatchURL+"?"+values.Encode(),
+		body,
+		request.WithContext(ctx),
+		request.WithTimeout(b.e.settings.MediaMetaGeocodingTimeout(items[0].ctx)),
+		request.WithLogger(b.e.l),
+		request.WithHeader(header),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get batch geocoding from mapbox: %w", err)
+	}
+
+	var geocoding MapboxBatchResponse
+	if err := json.Unmarshal([]byte(resp), &geocoding); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal batch geocoding from mapbox: %w", err)
+	}
+
+	if len(geocoding.Batch) != len(items) {
+		return nil, fmt.Errorf("expect %d results from mapbox batch geocoding, got %d", len(items), len(geocoding.Batch))
+	}
+
+	results := make([][]driver.MediaMeta, len(items))
+	for i, res := range geocoding.Batch {
+		results[i] = mapboxFeatureMetas(res.Features)
+	}
+
+	return results, nil
+}
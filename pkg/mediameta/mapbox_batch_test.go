@@ -0,0 +1,144 @@
+package mediameta
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/driver"
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/cloudreve/Cloudreve/v4/pkg/request"
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
+	"github.com/stretchr/testify/assert"
+)
+
+type mapboxSettings struct {
+	setting.Provider
+	batchSize int
+	timeout   time.Duration
+	maxRetry  int
+}
+
+func (s *mapboxSettings) MediaMetaGeocodingProvider(ctx context.Context) setting.GeocodingProvider {
+	return setting.GeocodingProviderMapbox
+}
+
+func (s *mapboxSettings) MediaMetaGeocodingMapboxAK(ctx context.Context) string {
+	return "token"
+}
+
+func (s *mapboxSettings) MediaMetaGeocodingUserAgent(ctx context.Context) string {
+	return ""
+}
+
+func (s *mapboxSettings) MediaMetaGeocodingBatchSize(ctx context.Context) int {
+	return s.batchSize
+}
+
+func (s *mapboxSettings) MediaMetaGeocodingTimeout(ctx context.Context) time.Duration {
+	if s.timeout > 0 {
+		return s.timeout
+	}
+	return 10 * time.Second
+}
+
+func (s *mapboxSettings) MediaMetaGeocodingMaxRetry(ctx context.Context) int {
+	return s.maxRetry
+}
+
+func mapboxTestResponse(place string) MapboxGeocodingResponse {
+	return MapboxGeocodingResponse{Features: []Feature{{Properties: Properties{Context: Context{Place: &ContextFeature{Name: place}}}}}}
+}
+
+// startMapboxTestServer mocks Mapbox reverse and batch endpoints, place name is derived from longitude.
+func startMapboxTestServer(t *testing.T, batchFails bool) (reverseHits, batchHits *int32) {
+	reverseHits, batchHits = new(int32), new(int32)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/reverse":
+			atomic.AddInt32(reverseHits, 1)
+			_ = json.NewEncoder(w).Encode(mapboxTestResponse("place-" + r.URL.Query().Get("longitude")[:1]))
+		case "/batch":
+			atomic.AddInt32(batchHits, 1)
+			if batchFails {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+
+			var queries []MapboxBatchQuery
+			_ = json.NewDecoder(r.Body).Decode(&queries)
+			res := MapboxBatchResponse{}
+			for _, q := range queries {
+				res.Batch = append(res.Batch, mapboxTestResponse(fmt.Sprintf("place-%d", int(q.Longitude))))
+			}
+			_ = json.NewEncoder(w).Encode(res)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	oldURL, oldBatchURL, oldLinger := mapBoxURL, mapBoxBatchURL, mapboxBatchLinger
+	mapBoxURL, mapBoxBatchURL, mapboxBatchLinger = srv.URL+"/reverse", srv.URL+"/batch", 50*time.Millisecond
+	t.Cleanup(func() {
+		mapBoxURL, mapBoxBatchURL, mapboxBatchLinger = oldURL, oldBatchURL, oldLinger
+	})
+
+	return reverseHits, batchHits
+}
+
+func geocodeConcurrently(e *geocodingExtractor, n int) ([][]driver.MediaMeta, []error) {
+	results := make([][]driver.MediaMeta, n)
+	errs := make([]error, n)
+	wg := sync.WaitGroup{}
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = e.getGeocoding(context.Background(), &geoPoint{Lat: 1, Lng: float64(i + 1)}, "")
+		}(i)
+	}
+	wg.Wait()
+	return results, errs
+}
+
+func TestMapboxBatcher(t *testing.T) {
+	a := assert.New(t)
+	l := logging.NewConsoleLogger(logging.LevelError)
+
+	// Results are distributed to each item
+	reverseHits, batchHits := startMapboxTestServer(t, false)
+	e := newGeocodingExtractor(&mapboxSettings{batchSize: 3}, l, request.NewClient(&masterConfig{}))
+	results, errs := geocodeConcurrently(e, 3)
+	for i := range results {
+		a.NoError(errs[i])
+		a.Equal([]driver.MediaMeta{{Key: Place, Value: fmt.Sprintf("place-%d", i+1)}}, results[i])
+	}
+	a.EqualValues(1, atomic.LoadInt32(batchHits))
+	a.EqualValues(0, atomic.LoadInt32(reverseHits))
+
+	// Single pending item uses reverse API after linger
+	results, errs = geocodeConcurrently(e, 1)
+	a.NoError(errs[0])
+	a.Equal([]driver.MediaMeta{{Key: Place, Value: "place-1"}}, results[0])
+	a.EqualValues(1, atomic.LoadInt32(batchHits))
+	a.EqualValues(1, atomic.LoadInt32(reverseHits))
+}
+
+func TestMapboxBatcher_Fallback(t *testing.T) {
+	a := assert.New(t)
+	reverseHits, batchHits := startMapboxTestServer(t, true)
+	e := newGeocodingExtractor(&mapboxSettings{batchSize: 2}, logging.NewConsoleLogger(logging.LevelError), request.NewClient(&masterConfig{}))
+
+	results, errs := geocodeConcurrently(e, 2)
+	for i := range results {
+		a.NoError(errs[i])
+		a.Equal([]driver.MediaMeta{{Key: Place, Value: fmt.Sprintf("place-%d", i+1)}}, results[i])
+	}
+	a.EqualValues(1, atomic.LoadInt32(batchHits))
+	a.EqualValues(2, atomic.LoadInt32(reverseHits))
+}
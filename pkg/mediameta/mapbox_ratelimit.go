@@ -0,0 +1,92 @@
+package mediameta
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cloudreve/Cloudreve/v4/pkg/request"
+)
+
+const mapboxDefaultBackoff = time.Second
+
+// mapboxMaxBackoff is the longest wait before retrying a rate limited request within the extractor,
+// longer waits are deferred to media meta queue.
+var mapboxMaxBackoff = 10 * time.Second
+
+// GeocodingRateLimitedError is returned when Mapbox keeps rejecting requests due to rate limit,
+// geocoding should not be attempted again before RetryAfter is elapsed.
+type GeocodingRateLimitedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *GeocodingRateLimitedError) Error() string {
+	return fmt.Sprintf("geocoding is rate limited, retry after %s", e.RetryAfter)
+}
+
+// mapboxRetryAfter returns how long to wait before next request according to headers of a 429 response.
+func mapboxRetryAfter(header http.Header, now time.Time) time.Duration {
+	if v := header.Get("Retry-After"); v != "" {
+		if seconds, err := strconv.Atoi(v); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second
+		}
+
+		if t, err := http.ParseTime(v); err == nil {
+			return max(t.Sub(now), 0)
+		}
+	}
+
+	// Unix timestamp when the rate limit window is reset
+	if v := header.Get("X-Rate-Limit-Reset"); v != "" {
+		if reset, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return max(time.Unix(reset, 0).Sub(now), 0)
+		}
+	}
+
+	return mapboxDefaultBackoff
+}
+
+// rateLimitedFor returns the remaining time before Mapbox rate limit is reset, 0 if not limited.
+func (e *geocodingExtractor) rateLimitedFor() time.Duration {
+	return max(time.Until(time.Unix(0, e.limitedUntil.Load())), 0)
+}
+
+// mapboxRequest sends request to Mapbox and returns response body. Rate limited requests are retried
+// up to media_meta_geocoding_max_retry times if the limit is reset soon, otherwise a
+// GeocodingRateLimitedError is returned, and following requests fail fast until the limit is reset.
+func (e *geocodingExtractor) mapboxRequest(ctx context.Context, method, target string, body []byte, opts ...request.Option) (string, error) {
+	maxRetry := e.settings.MediaMetaGeocodingMaxRetry(ctx)
+	for attempt := 0; ; attempt++ {
+		if wait := e.rateLimitedFor(); wait > 0 {
+			return "", &GeocodingRateLimitedError{RetryAfter: wait}
+		}
+
+		var reader io.Reader
+		if body != nil {
+			reader = bytes.NewReader(body)
+		}
+
+		resp := e.client.Request(method, target, reader, opts...)
+		if resp.Err != nil || resp.Response.StatusCode != http.StatusTooManyRequests {
+			return resp.CheckHTTPResponse(http.StatusOK).GetResponse()
+		}
+
+		_, _ = resp.GetResponse()
+		wait := mapboxRetryAfter(resp.Response.Header, time.Now())
+		if attempt >= maxRetry || wait > mapboxMaxBackoff {
+			e.limitedUntil.Store(time.Now().Add(wait).UnixNano())
+			return "", &GeocodingRateLimitedError{RetryAfter: wait}
+		}
+
+		e.l.Debug("Mapbox geocoding is rate limited, retry in %s.", wait)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+}
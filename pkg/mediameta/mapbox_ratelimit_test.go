@@ -0,0 +1,79 @@
+package mediameta
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/cloudreve/Cloudreve/v4/pkg/request"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMapboxRetryAfter(t *testing.T) {
+	a := assert.New(t)
+	now := time.Unix(1700000000, 0)
+
+	a.Equal(3*time.Second, mapboxRetryAfter(http.Header{"Retry-After": {"3"}}, now))
+	a.Equal(5*time.Second, mapboxRetryAfter(http.Header{"Retry-After": {now.Add(5 * time.Second).UTC().Format(http.TimeFormat)}}, now))
+	a.Equal(60*time.Second, mapboxRetryAfter(http.Header{"X-Rate-Limit-Reset": {"1700000060"}}, now))
+	a.Equal(time.Duration(0), mapboxRetryAfter(http.Header{"X-Rate-Limit-Reset": {"1600000000"}}, now))
+	a.Equal(mapboxDefaultBackoff, mapboxRetryAfter(http.Header{"Retry-After": {"soon"}}, now))
+	a.Equal(mapboxDefaultBackoff, mapboxRetryAfter(http.Header{}, now))
+}
+
+func TestGeocodingExtractor_MapboxRateLimit(t *testing.T) {
+	a := assert.New(t)
+	var hits, limited int32
+	retryAfter := "0"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		if atomic.AddInt32(&limited, -1) >= 0 {
+			w.Header().Set("Retry-After", retryAfter)
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(mapboxTestResponse("Paris"))
+	}))
+	defer srv.Close()
+
+	oldURL := mapBoxURL
+	mapBoxURL = srv.URL
+	defer func() { mapBoxURL = oldURL }()
+
+	l := logging.NewConsoleLogger(logging.LevelError)
+	e := newGeocodingExtractor(&mapboxSettings{maxRetry: 2}, l, request.NewClient(&masterConfig{}))
+	point := &geoPoint{Lat: 48.86, Lng: 2.33}
+
+	// Retried until succeeded
+	atomic.StoreInt32(&limited, 2)
+	metas, err := e.getGeocoding(context.Background(), point, "")
+	a.NoError(err)
+	a.Equal("Paris", metas[0].Value)
+	a.EqualValues(3, atomic.LoadInt32(&hits))
+
+	// Give up after max retries
+	atomic.StoreInt32(&hits, 0)
+	atomic.StoreInt32(&limited, 3)
+	_, err = e.getGeocoding(context.Background(), point, "")
+	var rateLimited *GeocodingRateLimitedError
+	a.True(errors.As(err, &rateLimited))
+	a.EqualValues(3, atomic.LoadInt32(&hits))
+
+	// Limit reset too late is not waited within extractor, following requests fail fast
+	atomic.StoreInt32(&hits, 0)
+	atomic.StoreInt32(&limited, 1)
+	retryAfter = strconv.Itoa(int(mapboxMaxBackoff/time.Second) + 60)
+	_, err = e.getGeocoding(context.Background(), point, "")
+	a.True(errors.As(err, &rateLimited))
+	a.Greater(rateLimited.RetryAfter, mapboxMaxBackoff)
+	_, err = e.getGeocoding(context.Background(), point, "")
+	a.True(errors.As(err, &rateLimited))
+	a.EqualValues(1, atomic.LoadInt32(&hits))
+}
@@ -30,6 +30,10 @@ const (
 	MusicYear         = "year"
 	MusicTrack        = "track"
 	MusicDisc         = "disc"
+	// MusicBPM is the estimated tempo in beats per minute.
+	MusicBPM = "bpm"
+	// MusicLoudness is the integrated loudness in LUFS.
+	MusicLoudness = "loudness"
 )
 
 func newMusicExtractor(settings setting.Provider, l logging.Logger) *musicExtractor {
@@ -63,7 +67,7 @@ func (a *musicExtractor) Extract(ctx context.Context, ext string, source entitys
 	if err != nil {
 		if errors.Is(err, tag.ErrNoTagsFound) {
 			a.l.Debug("No tags found in file.")
-			return nil, nil
+			return a.analyze(ctx, source, nil), nil
 		}
 		return nil, fmt.Errorf("failed to read tags from file: %w", err)
 	}
@@ -142,9 +146,25 @@ func (a *musicExtractor) Extract(ctx context.Context, ext string, source entitys
 		})
 	}
 
+	return a.analyze(ctx, source, metas), nil
+}
+
+// analyze appends computed audio features to metas if audio analysis is enabled, and set type of all metas.
+func (a *musicExtractor) analyze(ctx context.Context, source entitysource.EntitySource, metas []driver.MediaMeta) []driver.MediaMeta {
+	// Decoding is CPU-heavy, long recordings are skipped to avoid tying up the media meta queue.
+	if a.settings.MediaMetaAudioAnalysisEnabled(ctx) {
+		if maxSize := a.settings.MediaMetaAudioAnalysisMaxSize(ctx); maxSize > 0 && source.Entity().Size() > maxSize {
+			a.l.Debug("File is too large for audio analysis, skipped.")
+		} else if features, err := a.analyzeAudioSource(ctx, source); err != nil {
+			a.l.Warning("Failed to analyze audio: %s", err)
+		} else {
+			metas = append(metas, features...)
+		}
+	}
+
 	for i := 0; i < len(metas); i++ {
 		metas[i].Type = driver.MediaTypeMusic
 	}
 
-	return metas, nil
+	return metas
 }
@@ -0,0 +1,112 @@
+package mediameta
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/driver"
+	"github.com/cloudreve/Cloudreve/v4/pkg/request"
+)
+
+const (
+	// Nominatim usage policy allows an absolute maximum of 1 request per second.
+	nominatimTPSLimitToken = "nominatim"
+	nominatimTPS           = 1
+)
+
+// NominatimReverseResponse represents the response from Nominatim reverse geocoding API
+type NominatimReverseResponse struct {
+	PlaceID     int64            `json:"place_id"`
+	DisplayName string           `json:"display_name"`
+	Address     NominatimAddress `json:"address"`
+	Error       string           `json:"error,omitempty"`
+}
+
+// NominatimAddress contains address fields of a Nominatim result, only fields used by Cloudreve are listed.
+type NominatimAddress struct {
+	Road          string `json:"road,omitempty"`
+	Pedestrian    string `json:"pedestrian,omitempty"`
+	Neighbourhood string `json:"neighbourhood,omitempty"`
+	Quarter       string `json:"quarter,omitempty"`
+	Suburb        string `json:"suburb,omitempty"`
+	Hamlet        string `json:"hamlet,omitempty"`
+	Village       string `json:"village,omitempty"`
+	Town          string `json:"town,omitempty"`
+	City          string `json:"city,omitempty"`
+	Municipality  string `json:"municipality,omitempty"`
+	CityDistrict  string `json:"city_district,omitempty"`
+	District      string `json:"district,omitempty"`
+	County        string `json:"county,omitempty"`
+	StateDistrict string `json:"state_district,omitempty"`
+	State         string `json:"state,omitempty"`
+	Province      string `json:"province,omitempty"`
+	Region        string `json:"region,omitempty"`
+	Country       string `json:"country,omitempty"`
+}
+
+func (e *geocodingExtractor) getNominatimGeocoding(ctx context.Context, point *geoPoint, language string) ([]driver.MediaMeta, error) {
+	values := url.Values{}
+	values.Add("format", "jsonv2")
+	values.Add("lat", fmt.Sprintf("%f", point.Lat))
+	values.Add("lon", fmt.Sprintf("%f", point.Lng))
+	values.Add("addressdetails", "1")
+	if language != "" {
+		values.Add("accept-language", language)
+	}
+
+	resp, err := e.client.Request(
+		"GET",
+		e.settings.MediaMetaGeocodingNominatimURL(ctx)+"?"+values.Encode(),
+		nil,
+		request.WithContext(ctx),
+		request.WithTimeout(e.settings.MediaMetaGeocodingTimeout(ctx)),
+		request.WithLogger(e.l),
+		request.WithHeader(e.userAgentHeader(ctx)),
+		request.WithTPSLimit(nominatimTPSLimitToken, nominatimTPS, 1),
+	).CheckHTTPResponse(http.StatusOK).GetResponse()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get geocoding from nominatim: %w", err)
+	}
+
+	var geocoding NominatimReverseResponse
+	if err := json.Unmarshal([]byte(resp), &geocoding); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal geocoding from nominatim: %w", err)
+	}
+
+	// Nominatim responds with an error message if no place is found at given location
+	if geocoding.Error != "" {
+		return nil, nil
+	}
+
+	return nominatimAddressMetas(&geocoding.Address), nil
+}
+
+// nominatimAddressMetas maps Nominatim address fields to the same keys as Mapbox context.
+func nominatimAddressMetas(address *NominatimAddress) []driver.MediaMeta {
+	fields := []struct {
+		key    string
+		values []string
+	}{
+		{Street, []string{address.Road, address.Pedestrian}},
+		{Locality, []string{address.Suburb, address.Neighbourhood, address.Quarter, address.Hamlet}},
+		{Place, []string{address.City, address.Town, address.Village, address.Municipality}},
+		{District, []string{address.CityDistrict, address.District, address.County, address.StateDistrict}},
+		{Region, []string{address.State, address.Province, address.Region}},
+		{Country, []string{address.Country}},
+	}
+
+	metas := make([]driver.MediaMeta, 0)
+	for _, field := range fields {
+		for _, value := range field.values {
+			if value != "" {
+				metas = append(metas, driver.MediaMeta{Key: field.key, Value: value})
+				break
+			}
+		}
+	}
+
+	return metas
+}
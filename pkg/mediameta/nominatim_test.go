@@ -0,0 +1,81 @@
+package mediameta
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cloudreve/Cloudreve/v4/pkg/conf"
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/driver"
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/cloudreve/Cloudreve/v4/pkg/request"
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
+	"github.com/stretchr/testify/assert"
+)
+
+type nominatimSettings struct {
+	setting.Provider
+	endpoint string
+}
+
+func (s *nominatimSettings) MediaMetaGeocodingProvider(ctx context.Context) setting.GeocodingProvider {
+	return setting.GeocodingProviderNominatim
+}
+
+func (s *nominatimSettings) MediaMetaGeocodingNominatimURL(ctx context.Context) string {
+	return s.endpoint
+}
+
+func (s *nominatimSettings) MediaMetaGeocodingUserAgent(ctx context.Context) string {
+	return "Cloudreve-Test/1.0"
+}
+
+func (s *nominatimSettings) MediaMetaGeocodingTimeout(ctx context.Context) time.Duration {
+	return 10 * time.Second
+}
+
+type masterConfig struct {
+	conf.ConfigProvider
+}
+
+func (c *masterConfig) System() *conf.System {
+	return &conf.System{Mode: conf.MasterMode}
+}
+
+func TestGeocodingExtractor_Nominatim(t *testing.T) {
+	a := assert.New(t)
+	var query, userAgent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query, userAgent = r.URL.RawQuery, r.UserAgent()
+		if r.URL.Query().Get("lat") == "0.000000" {
+			_, _ = w.Write([]byte(`{"error":"Unable to geocode"}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"place_id":1,"address":{"road":"Rue de Rivoli","suburb":"Quartier Saint-Germain",
+"city":"Paris","county":"Paris","state":"Île-de-France","country":"France"}}`))
+	}))
+	defer srv.Close()
+
+	l := logging.NewConsoleLogger(logging.LevelError)
+	e := newGeocodingExtractor(&nominatimSettings{endpoint: srv.URL}, l, request.NewClient(&masterConfig{}))
+	metas, err := e.getGeocoding(context.Background(), &geoPoint{Lat: 48.86, Lng: 2.33}, "fr")
+	a.NoError(err)
+	a.Equal([]driver.MediaMeta{
+		{Key: Street, Value: "Rue de Rivoli"},
+		{Key: Locality, Value: "Quartier Saint-Germain"},
+		{Key: Place, Value: "Paris"},
+		{Key: District, Value: "Paris"},
+		{Key: Region, Value: "Île-de-France"},
+		{Key: Country, Value: "France"},
+	}, metas)
+	a.Equal("Cloudreve-Test/1.0", userAgent)
+	a.Contains(query, "accept-language=fr")
+
+	// No place found
+	metas, err = e.getGeocoding(context.Background(), &geoPoint{}, "")
+	a.NoError(err)
+	a.Empty(metas)
+}
@@ -0,0 +1,59 @@
+package mediameta
+
+import (
+	"path"
+	"strings"
+
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/driver"
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
+	"github.com/cloudreve/Cloudreve/v4/pkg/util"
+)
+
+const (
+	// PairCompanion is set on primary file (RAW/HEIC), pointing to the name of its companion JPEG.
+	PairCompanion = "companion"
+	// PairPrimary is set on companion file, pointing to the name of its primary file.
+	PairPrimary = "primary"
+)
+
+// FindPair looks for the sibling captured together with given file by basename. exists reports
+// whether a file with given name exists in the same folder. The name of the sibling and whether
+// given file is the primary one of the pair is returned.
+func FindPair(name string, exists func(name string) bool, rules *setting.MediaMetaPairing) (sibling string, isPrimary bool, found bool) {
+	ext := util.Ext(name)
+	var candidates []string
+	switch {
+	case util.IsInExtensionListExt(rules.PrimaryExts, ext):
+		candidates, isPrimary = rules.CompanionExts, true
+	case util.IsInExtensionListExt(rules.CompanionExts, ext):
+		candidates = rules.PrimaryExts
+	default:
+		return "", false, false
+	}
+
+	base := strings.TrimSuffix(name, path.Ext(name))
+	for _, candidate := range candidates {
+		for _, variant := range []string{strings.ToLower(candidate), strings.ToUpper(candidate)} {
+			siblingName := base + "." + variant
+			if exists(siblingName) {
+				return siblingName, isPrimary, true
+			}
+		}
+	}
+
+	return "", false, false
+}
+
+// PairMeta builds the media meta recording the pair relationship on one file of the pair.
+func PairMeta(sibling string, isPrimary bool) driver.MediaMeta {
+	key := PairPrimary
+	if isPrimary {
+		key = PairCompanion
+	}
+
+	return driver.MediaMeta{
+		Type:  driver.MetaTypePair,
+		Key:   key,
+		Value: sibling,
+	}
+}
@@ -0,0 +1,58 @@
+package mediameta
+
+import (
+	"testing"
+
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/driver"
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindPair(t *testing.T) {
+	a := assert.New(t)
+	rules := &setting.MediaMetaPairing{
+		Enabled:       true,
+		PrimaryExts:   []string{"cr2", "heic"},
+		CompanionExts: []string{"jpg", "jpeg"},
+	}
+	existsIn := func(names ...string) func(string) bool {
+		return func(name string) bool {
+			for _, n := range names {
+				if n == name {
+					return true
+				}
+			}
+			return false
+		}
+	}
+
+	// CR2 + JPG pair, from RAW side
+	{
+		sibling, isPrimary, found := FindPair("IMG_0001.CR2", existsIn("IMG_0001.CR2", "IMG_0001.JPG"), rules)
+		a.True(found)
+		a.True(isPrimary)
+		a.Equal("IMG_0001.JPG", sibling)
+		a.Equal(driver.MediaMeta{Type: driver.MetaTypePair, Key: PairCompanion, Value: "IMG_0001.JPG"}, PairMeta(sibling, isPrimary))
+	}
+
+	// CR2 + JPG pair, from JPEG side
+	{
+		sibling, isPrimary, found := FindPair("IMG_0001.JPG", existsIn("IMG_0001.CR2", "IMG_0001.JPG"), rules)
+		a.True(found)
+		a.False(isPrimary)
+		a.Equal("IMG_0001.CR2", sibling)
+		a.Equal(PairPrimary, PairMeta(sibling, isPrimary).Key)
+	}
+
+	// Lone RAW file
+	{
+		_, _, found := FindPair("IMG_0002.CR2", existsIn("IMG_0002.CR2", "IMG_0003.JPG"), rules)
+		a.False(found)
+	}
+
+	// Not covered by rules
+	{
+		_, _, found := FindPair("IMG_0001.png", existsIn("IMG_0001.png", "IMG_0001.jpg"), rules)
+		a.False(found)
+	}
+}
@@ -0,0 +1,127 @@
+package mediameta
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/driver"
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/manager/entitysource"
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
+	"github.com/ledongthuc/pdf"
+)
+
+const (
+	DocumentPages     = "pages"
+	DocumentTitle     = "title"
+	DocumentAuthor    = "author"
+	DocumentCreatedAt = "created_at"
+)
+
+type pdfMetaExtractor struct {
+	settings setting.Provider
+	l        logging.Logger
+}
+
+func newPdfMetaExtractor(settings setting.Provider, l logging.Logger) *pdfMetaExtractor {
+	return &pdfMetaExtractor{
+		settings: settings,
+		l:        l,
+	}
+}
+
+func (e *pdfMetaExtractor) Exts() []string {
+	return []string{"pdf"}
+}
+
+func (e *pdfMetaExtractor) Extract(ctx context.Context, ext string, source entitysource.EntitySource, opts ...optionFunc) (metas []driver.MediaMeta, err error) {
+	localLimit, remoteLimit := e.settings.MediaMetaPdfSizeLimit(ctx)
+	if err := checkFileSize(localLimit, remoteLimit, source); err != nil {
+		return nil, err
+	}
+
+	// PDF reader panics on malformed objects
+	defer func() {
+		if r := recover(); r != nil {
+			metas, err = nil, fmt.Errorf("failed to parse pdf: %v", r)
+		}
+	}()
+
+	reader, err := pdf.NewReader(source, source.Entity().Size())
+	if err != nil {
+		// Encrypted PDFs that cannot be opened without password are skipped. The reader does not export
+		// error for unsupported encryption method, so error message is checked.
+		if errors.Is(err, pdf.ErrInvalidPassword) || strings.Contains(err.Error(), "encryption") {
+			e.l.Debug("Skip encrypted pdf: %s", err)
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to open pdf: %w", err)
+	}
+
+	metas = make([]driver.MediaMeta, 0)
+	if pages := reader.NumPage(); pages > 0 {
+		metas = append(metas, driver.MediaMeta{Key: DocumentPages, Value: fmt.Sprintf("%d", pages)})
+	}
+
+	info := reader.Trailer().Key("Info")
+	if title := strings.TrimSpace(info.Key("Title").Text()); title != "" {
+		metas = append(metas, driver.MediaMeta{Key: DocumentTitle, Value: title})
+	}
+
+	if author := strings.TrimSpace(info.Key("Author").Text()); author != "" {
+		metas = append(metas, driver.MediaMeta{Key: DocumentAuthor, Value: author})
+	}
+
+	if created, err := parsePdfDate(info.Key("CreationDate").RawString()); err == nil {
+		metas = append(metas, driver.MediaMeta{Key: DocumentCreatedAt, Value: created.Format(time.RFC3339)})
+	}
+
+	for i := range metas {
+		metas[i].Type = driver.MetaTypeDocument
+	}
+
+	return metas, nil
+}
+
+// parsePdfDate parses date string in PDF format: D:YYYYMMDDHHmmSSOHH'mm', all parts after year are optional.
+func parsePdfDate(value string) (time.Time, error) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "D:")
+	if len(value) < 4 {
+		return time.Time{}, errors.New("invalid pdf date")
+	}
+
+	// Split timezone part
+	loc := time.UTC
+	if i := strings.IndexAny(value, "Z+-"); i >= 0 {
+		tz := strings.ReplaceAll(value[i:], "'", "")
+		value = value[:i]
+		if tz[0] != 'Z' && len(tz) >= 3 {
+			var hour, minute int
+			if _, err := fmt.Sscanf(tz[1:3], "%02d", &hour); err != nil {
+				return time.Time{}, fmt.Errorf("invalid pdf date timezone: %w", err)
+			}
+			if len(tz) >= 5 {
+				_, _ = fmt.Sscanf(tz[3:5], "%02d", &minute)
+			}
+
+			offset := hour*3600 + minute*60
+			if tz[0] == '-' {
+				offset = -offset
+			}
+			loc = time.FixedZone("", offset)
+		}
+	}
+
+	// Fill omitted parts with default value
+	const layout = "20060102150405"
+	defaults := "00000101000000"
+	if len(value) > len(layout) {
+		value = value[:len(layout)]
+	}
+	value += defaults[len(value):]
+	return time.ParseInLocation(layout, value, loc)
+}
@@ -0,0 +1,107 @@
+package mediameta
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cloudreve/Cloudreve/v4/ent"
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/driver"
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/fs"
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/manager/entitysource"
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryEntitySource struct {
+	entitysource.EntitySource
+	r *bytes.Reader
+}
+
+func (s *memoryEntitySource) ReadAt(p []byte, off int64) (int, error) { return s.r.ReadAt(p, off) }
+func (s *memoryEntitySource) IsLocal() bool                           { return true }
+func (s *memoryEntitySource) Entity() fs.Entity {
+	return fs.NewEntity(&ent.Entity{Size: s.r.Size()})
+}
+
+type pdfSettings struct {
+	setting.Provider
+}
+
+func (s *pdfSettings) MediaMetaPdfSizeLimit(ctx context.Context) (int64, int64) {
+	return 0, 0
+}
+
+// buildTestPdf builds a PDF with given objects, the first object is the catalog.
+func buildTestPdf(trailer string, objects ...string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R %s >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, trailer, xref)
+	return buf.Bytes()
+}
+
+func TestPdfMetaExtractor(t *testing.T) {
+	a := assert.New(t)
+	e := newPdfMetaExtractor(&pdfSettings{}, logging.NewConsoleLogger(logging.LevelError))
+
+	data := buildTestPdf("/Info 3 0 R",
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [] /Count 12 >>",
+		"<< /Title (Annual Report) /Author (Jane Doe) /CreationDate (D:20240102030405+08'00') >>",
+	)
+	metas, err := e.Extract(context.Background(), "pdf", &memoryEntitySource{r: bytes.NewReader(data)})
+	a.NoError(err)
+	a.Equal([]driver.MediaMeta{
+		{Type: driver.MetaTypeDocument, Key: DocumentPages, Value: "12"},
+		{Type: driver.MetaTypeDocument, Key: DocumentTitle, Value: "Annual Report"},
+		{Type: driver.MetaTypeDocument, Key: DocumentAuthor, Value: "Jane Doe"},
+		{Type: driver.MetaTypeDocument, Key: DocumentCreatedAt, Value: "2024-01-02T03:04:05+08:00"},
+	}, metas)
+
+	// Encrypted PDF is skipped
+	data = buildTestPdf("/Encrypt 3 0 R",
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [] /Count 1 >>",
+		"<< /Filter /Custom >>",
+	)
+	metas, err = e.Extract(context.Background(), "pdf", &memoryEntitySource{r: bytes.NewReader(data)})
+	a.NoError(err)
+	a.Empty(metas)
+
+	// Not a PDF
+	_, err = e.Extract(context.Background(), "pdf", &memoryEntitySource{r: bytes.NewReader([]byte("not a pdf"))})
+	a.Error(err)
+}
+
+func TestParsePdfDate(t *testing.T) {
+	a := assert.New(t)
+	date, err := parsePdfDate("D:20240102030405Z")
+	a.NoError(err)
+	a.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), date)
+
+	date, err = parsePdfDate("D:20240102030405-05'30'")
+	a.NoError(err)
+	a.Equal("2024-01-02T03:04:05-05:30", date.Format(time.RFC3339))
+
+	// Omitted parts
+	date, err = parsePdfDate("D:2024")
+	a.NoError(err)
+	a.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), date)
+
+	_, err = parsePdfDate("")
+	a.Error(err)
+}
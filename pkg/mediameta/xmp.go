@@ -0,0 +1,277 @@
+package mediameta
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/cloudreve/Cloudreve/v4/inventory/types"
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/driver"
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/manager/entitysource"
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
+	"github.com/samber/lo"
+)
+
+const (
+	XMPRating      = "rating"
+	XMPLabel       = "label"
+	XMPCreatorTool = "creator_tool"
+	XMPTitle       = "title"
+	XMPDescription = "description"
+	XMPKeywords    = "keywords"
+	XMPEditedWith  = "edited_with"
+
+	// RatingCustomProp is the ID of custom props that XMP rating is mapped to.
+	RatingCustomProp = "rating"
+
+	nsRDF   = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	nsXMP   = "http://ns.adobe.com/xap/1.0/"
+	nsDC    = "http://purl.org/dc/elements/1.1/"
+	nsStEvt = "http://ns.adobe.com/xap/1.0/sType/ResourceEvent#"
+
+	// maxXMPPacketSize is the max size of XMP packet to be parsed.
+	maxXMPPacketSize = 4 << 20
+)
+
+var (
+	xmpPacketStart = []byte("<x:xmpmeta")
+	xmpPacketEnd   = []byte("</x:xmpmeta>")
+
+	errNoXMP = errors.New("no xmp packet found")
+)
+
+type xmpExtractor struct {
+	settings setting.Provider
+	l        logging.Logger
+}
+
+func newXMPExtractor(settings setting.Provider, l logging.Logger) *xmpExtractor {
+	return &xmpExtractor{
+		settings: settings,
+		l:        l,
+	}
+}
+
+func (e *xmpExtractor) Exts() []string {
+	return exifExts
+}
+
+// Extract parses XMP packet from sidecar file if provided, otherwise from the packet embedded in source.
+func (e *xmpExtractor) Extract(ctx context.Context, ext string, source entitysource.EntitySource, opts ...optionFunc) ([]driver.MediaMeta, error) {
+	option := &option{}
+	for _, opt := range opts {
+		opt.apply(option)
+	}
+
+	var (
+		packet []byte
+		err    error
+	)
+	if option.xmpSidecar != nil {
+		packet, err = findXMPPacket(option.xmpSidecar)
+		if err != nil {
+			e.l.Debug("Failed to find xmp packet in sidecar: %s, fallback to embedded packet.", err)
+		}
+	}
+
+	if packet == nil {
+		localLimit, remoteLimit := e.settings.MediaMetaXMPSizeLimit(ctx)
+		if err := checkFileSize(localLimit, remoteLimit, source); err != nil {
+			return nil, err
+		}
+
+		packet, err = findXMPPacket(source)
+		if errors.Is(err, errNoXMP) {
+			e.l.Debug("No xmp data found")
+			return nil, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to find xmp packet: %w", err)
+		}
+	}
+
+	props, err := parseXMPPacket(packet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse xmp packet: %w", err)
+	}
+
+	metas := xmpMetas(props)
+	for i := range metas {
+		metas[i].Type = driver.MetaTypeXMP
+	}
+
+	if rating, ok := e.ratingProp(ctx, props); ok {
+		metas = append(metas, rating)
+	}
+
+	return metas, nil
+}
+
+// ratingProp maps XMP rating to the rating custom props, if such props is defined.
+func (e *xmpExtractor) ratingProp(ctx context.Context, props xmpProps) (driver.MediaMeta, bool) {
+	prop, found := lo.Find(e.settings.CustomProps(ctx), func(p types.CustomProps) bool {
+		return p.ID == RatingCustomProp && p.Type == types.CustomPropsTypeRating
+	})
+	if !found {
+		return driver.MediaMeta{}, false
+	}
+
+	// 0 means unrated, -1 means rejected
+	rating, err := strconv.Atoi(props.first(nsXMP, "Rating"))
+	if err != nil || rating <= 0 || rating > prop.Max {
+		return driver.MediaMeta{}, false
+	}
+
+	return driver.MediaMeta{Type: driver.MetaTypeCustomProps, Key: RatingCustomProp, Value: strconv.Itoa(rating)}, true
+}
+
+func xmpMetas(props xmpProps) []driver.MediaMeta {
+	fields := []struct {
+		key   string
+		value string
+	}{
+		{XMPRating, props.first(nsXMP, "Rating")},
+		{XMPLabel, props.first(nsXMP, "Label")},
+		{XMPCreatorTool, props.first(nsXMP, "CreatorTool")},
+		{XMPTitle, props.first(nsDC, "title")},
+		{XMPDescription, props.first(nsDC, "description")},
+		{XMPKeywords, strings.Join(props[xml.Name{Space: nsDC, Local: "subject"}], ", ")},
+		{XMPEditedWith, props.last(nsStEvt, "softwareAgent")},
+	}
+
+	metas := make([]driver.MediaMeta, 0, len(fields))
+	for _, field := range fields {
+		if field.value != "" {
+			metas = append(metas, driver.MediaMeta{Key: field.key, Value: field.value})
+		}
+	}
+
+	return metas
+}
+
+// xmpProps holds values of XMP properties by qualified name, in order of appearance.
+type xmpProps map[xml.Name][]string
+
+func (p xmpProps) first(space, local string) string {
+	values := p[xml.Name{Space: space, Local: local}]
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+func (p xmpProps) last(space, local string) string {
+	values := p[xml.Name{Space: space, Local: local}]
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[len(values)-1]
+}
+
+// parseXMPPacket collects values of XMP properties. Properties can be either attributes or elements,
+// items of RDF containers (rdf:li) are collected as values of their parent property.
+func parseXMPPacket(packet []byte) (xmpProps, error) {
+	props := make(xmpProps)
+	decoder := xml.NewDecoder(bytes.NewReader(packet))
+	decoder.Strict = false
+
+	var (
+		stack []xml.Name
+		text  strings.Builder
+	)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			text.Reset()
+			stack = append(stack, t.Name)
+			for _, attr := range t.Attr {
+				if attr.Name.Space == nsRDF || attr.Name.Space == "xmlns" || attr.Name.Space == "xml" || attr.Name.Space == "" {
+					continue
+				}
+
+				props[attr.Name] = append(props[attr.Name], attr.Value)
+			}
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			value := strings.TrimSpace(text.String())
+			text.Reset()
+			if len(stack) == 0 {
+				continue
+			}
+
+			// Find property name, skipping RDF syntax elements
+			name := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for i := len(stack); name.Space == nsRDF && i > 0; i-- {
+				name = stack[i-1]
+			}
+
+			if value != "" && name.Space != nsRDF {
+				props[name] = append(props[name], value)
+			}
+		}
+	}
+
+	return props, nil
+}
+
+// findXMPPacket scans r for the first XMP packet. Packets are located by scanning for the x:xmpmeta
+// element, which works for JPEG APP1, TIFF, PNG and sidecar files.
+func findXMPPacket(r io.Reader) ([]byte, error) {
+	const chunkSize = 64 << 10
+	var (
+		buf    []byte
+		start  = -1
+		chunk  = make([]byte, chunkSize)
+		offset int
+	)
+
+	for {
+		n, err := r.Read(chunk)
+		buf = append(buf, chunk[:n]...)
+
+		if start < 0 {
+			if i := bytes.Index(buf, xmpPacketStart); i >= 0 {
+				buf, start, offset = buf[i:], 0, 0
+			} else if len(buf) > len(xmpPacketStart) {
+				// Keep the tail in case the start tag crosses chunk boundary
+				buf = buf[len(buf)-len(xmpPacketStart):]
+			}
+		}
+
+		if start >= 0 {
+			if i := bytes.Index(buf[offset:], xmpPacketEnd); i >= 0 {
+				return buf[:offset+i+len(xmpPacketEnd)], nil
+			}
+
+			if len(buf) > maxXMPPacketSize {
+				return nil, errors.New("xmp packet is too large")
+			}
+
+			offset = max(0, len(buf)-len(xmpPacketEnd))
+		}
+
+		if err == io.EOF {
+			return nil, errNoXMP
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
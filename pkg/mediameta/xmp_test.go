@@ -0,0 +1,92 @@
+package mediameta
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/cloudreve/Cloudreve/v4/inventory/types"
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/driver"
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
+	"github.com/stretchr/testify/assert"
+)
+
+const testXMPPacket = `<?xpacket begin="" id="W5M0MpCehiHzreSzNTczkc9d"?>
+<x:xmpmeta xmlns:x="adobe:ns:meta/">
+ <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <rdf:Description rdf:about=""
+    xmlns:xmp="http://ns.adobe.com/xap/1.0/"
+    xmlns:dc="http://purl.org/dc/elements/1.1/"
+    xmlns:xmpMM="http://ns.adobe.com/xap/1.0/mm/"
+    xmlns:stEvt="http://ns.adobe.com/xap/1.0/sType/ResourceEvent#"
+   xmp:Rating="4"
+   xmp:Label="Red"
+   xmp:CreatorTool="Adobe Photoshop Lightroom Classic 13.0">
+   <dc:title><rdf:Alt><rdf:li xml:lang="x-default">Sunset</rdf:li></rdf:Alt></dc:title>
+   <dc:subject><rdf:Bag><rdf:li>beach</rdf:li><rdf:li>sunset</rdf:li></rdf:Bag></dc:subject>
+   <xmpMM:History>
+    <rdf:Seq>
+     <rdf:li stEvt:action="derived" stEvt:softwareAgent="Capture One 23"/>
+     <rdf:li rdf:parseType="Resource">
+      <stEvt:action>saved</stEvt:action>
+      <stEvt:softwareAgent>Adobe Photoshop Lightroom Classic 13.0</stEvt:softwareAgent>
+     </rdf:li>
+    </rdf:Seq>
+   </xmpMM:History>
+  </rdf:Description>
+ </rdf:RDF>
+</x:xmpmeta>
+<?xpacket end="w"?>`
+
+type xmpSettings struct {
+	setting.Provider
+}
+
+func (s *xmpSettings) CustomProps(ctx context.Context) []types.CustomProps {
+	return []types.CustomProps{{ID: RatingCustomProp, Type: types.CustomPropsTypeRating, Max: 5}}
+}
+
+func TestParseXMPPacket(t *testing.T) {
+	a := assert.New(t)
+	props, err := parseXMPPacket([]byte(testXMPPacket))
+	a.NoError(err)
+	a.Equal([]driver.MediaMeta{
+		{Key: XMPRating, Value: "4"},
+		{Key: XMPLabel, Value: "Red"},
+		{Key: XMPCreatorTool, Value: "Adobe Photoshop Lightroom Classic 13.0"},
+		{Key: XMPTitle, Value: "Sunset"},
+		{Key: XMPKeywords, Value: "beach, sunset"},
+		{Key: XMPEditedWith, Value: "Adobe Photoshop Lightroom Classic 13.0"},
+	}, xmpMetas(props))
+
+	// Rating is mapped to custom props
+	e := newXMPExtractor(&xmpSettings{}, logging.NewConsoleLogger(logging.LevelError))
+	rating, ok := e.ratingProp(context.Background(), props)
+	a.True(ok)
+	a.Equal(driver.MediaMeta{Type: driver.MetaTypeCustomProps, Key: RatingCustomProp, Value: "4"}, rating)
+
+	// Rejected photo is not mapped
+	props, err = parseXMPPacket([]byte(`<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+<rdf:Description xmlns:xmp="http://ns.adobe.com/xap/1.0/"><xmp:Rating>-1</xmp:Rating></rdf:Description></rdf:RDF></x:xmpmeta>`))
+	a.NoError(err)
+	a.Equal("-1", props.first(nsXMP, "Rating"))
+	_, ok = e.ratingProp(context.Background(), props)
+	a.False(ok)
+}
+
+func TestFindXMPPacket(t *testing.T) {
+	a := assert.New(t)
+
+	// Start tag of packet crosses chunk boundary
+	data := append(bytes.Repeat([]byte{0xFF}, 64<<10-5-strings.Index(testXMPPacket, "<x:xmpmeta")), []byte(testXMPPacket)...)
+	data = append(data, bytes.Repeat([]byte{0x00}, 1024)...)
+	packet, err := findXMPPacket(bytes.NewReader(data))
+	a.NoError(err)
+	a.True(bytes.HasPrefix(packet, xmpPacketStart))
+	a.True(bytes.HasSuffix(packet, xmpPacketEnd))
+
+	_, err = findXMPPacket(bytes.NewReader(bytes.Repeat([]byte{0xFF}, 1024)))
+	a.ErrorIs(err, errNoXMP)
+}
@@ -0,0 +1,99 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "cloudreve"
+
+// Result labels of observed operations.
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+	ResultRetry   = "retry"
+	ResultPass    = "pass"
+	ResultHit     = "hit"
+	ResultMiss    = "miss"
+)
+
+// Storage operation labels.
+const (
+	StorageOpUpload   = "upload"
+	StorageOpDownload = "download"
+	StorageOpDelete   = "delete"
+)
+
+var (
+	// Registry holds all collectors of Cloudreve, it does not include Go runtime metrics
+	// so that exposed metrics are the same across different deployments.
+	Registry = prometheus.NewRegistry()
+
+	// EmailsSent counts emails by driver and result, failure means the email is dropped.
+	EmailsSent = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "email",
+		Name:      "sent_total",
+		Help:      "Number of email sending attempts by driver and result.",
+	}, []string{"driver", "result"})
+
+	// StorageOperations counts storage driver operations by policy type, operation and result.
+	StorageOperations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "storage",
+		Name:      "operations_total",
+		Help:      "Number of storage operations by policy type, operation and result.",
+	}, []string{"policy_type", "operation", "result"})
+
+	// StorageBytes counts bytes of successful storage operations by policy type and operation.
+	StorageBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "storage",
+		Name:      "bytes_total",
+		Help:      "Bytes of successful storage operations by policy type and operation.",
+	}, []string{"policy_type", "operation"})
+
+	// ThumbDuration observes time spent by each thumbnail generator.
+	ThumbDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "thumb",
+		Name:      "generate_duration_seconds",
+		Help:      "Time spent generating thumbnails by generator and result.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"generator", "result"})
+
+	// CacheRequests counts KV cache lookups by store and result.
+	CacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "cache",
+		Name:      "requests_total",
+		Help:      "Number of KV cache lookups by store and result.",
+	}, []string{"store", "result"})
+)
+
+func init() {
+	Registry.MustRegister(EmailsSent, StorageOperations, StorageBytes, ThumbDuration, CacheRequests)
+}
+
+// ObserveStorage records a storage operation, bytes are only counted if err is nil.
+func ObserveStorage(policyType, op string, bytes int64, err error) {
+	if err != nil {
+		StorageOperations.WithLabelValues(policyType, op, ResultFailure).Inc()
+		return
+	}
+
+	StorageOperations.WithLabelValues(policyType, op, ResultSuccess).Inc()
+	if bytes > 0 {
+		StorageBytes.WithLabelValues(policyType, op).Add(float64(bytes))
+	}
+}
+
+// ObserveCache records hits and misses of cache lookups.
+func ObserveCache(store string, hits, misses int) {
+	if hits > 0 {
+		CacheRequests.WithLabelValues(store, ResultHit).Add(float64(hits))
+	}
+
+	if misses > 0 {
+		CacheRequests.WithLabelValues(store, ResultMiss).Add(float64(misses))
+	}
+}
@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObserveStorage(t *testing.T) {
+	a := assert.New(t)
+	success := StorageOperations.WithLabelValues("test", StorageOpUpload, ResultSuccess)
+	failure := StorageOperations.WithLabelValues("test", StorageOpUpload, ResultFailure)
+	bytes := StorageBytes.WithLabelValues("test", StorageOpUpload)
+	before := testutil.ToFloat64(success)
+
+	ObserveStorage("test", StorageOpUpload, 100, nil)
+	ObserveStorage("test", StorageOpUpload, 50, errors.New("failed"))
+	a.Equal(before+1, testutil.ToFloat64(success))
+	a.Equal(float64(1), testutil.ToFloat64(failure))
+	a.Equal(float64(100), testutil.ToFloat64(bytes))
+}
+
+func TestObserveCache(t *testing.T) {
+	a := assert.New(t)
+	ObserveCache("test", 3, 0)
+	ObserveCache("test", 1, 2)
+	a.Equal(float64(4), testutil.ToFloat64(CacheRequests.WithLabelValues("test", ResultHit)))
+	a.Equal(float64(2), testutil.ToFloat64(CacheRequests.WithLabelValues("test", ResultMiss)))
+}
+
+type fakeQueue struct {
+	busy, success, failure, submitted, suspending int
+}
+
+func (q *fakeQueue) BusyWorkers() int     { return q.busy }
+func (q *fakeQueue) SuccessTasks() int    { return q.success }
+func (q *fakeQueue) FailureTasks() int    { return q.failure }
+func (q *fakeQueue) SubmittedTasks() int  { return q.submitted }
+func (q *fakeQueue) SuspendingTasks() int { return q.suspending }
+
+func TestQueueCollector(t *testing.T) {
+	c := NewQueueCollector(func() map[string]QueueStats {
+		return map[string]QueueStats{
+			"thumb": &fakeQueue{busy: 1, success: 3, failure: 1, submitted: 6},
+		}
+	})
+
+	expected := `
+# HELP cloudreve_queue_tasks Number of tasks currently in queue by queue type and state.
+# TYPE cloudreve_queue_tasks gauge
+cloudreve_queue_tasks{queue="thumb",state="busy"} 1
+cloudreve_queue_tasks{queue="thumb",state="pending"} 1
+cloudreve_queue_tasks{queue="thumb",state="suspending"} 0
+# HELP cloudreve_queue_tasks_total Number of tasks by queue type and outcome since the queue is started.
+# TYPE cloudreve_queue_tasks_total counter
+cloudreve_queue_tasks_total{outcome="failure",queue="thumb"} 1
+cloudreve_queue_tasks_total{outcome="submitted",queue="thumb"} 6
+cloudreve_queue_tasks_total{outcome="success",queue="thumb"} 3
+`
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected)))
+}
@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	queueTasksDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "queue", "tasks_total"),
+		"Number of tasks by queue type and outcome since the queue is started.",
+		[]string{"queue", "outcome"}, nil,
+	)
+	queueDepthDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "queue", "tasks"),
+		"Number of tasks currently in queue by queue type and state.",
+		[]string{"queue", "state"}, nil,
+	)
+)
+
+// QueueStats provides task counters of a queue, it is implemented by queue.Queue.
+type QueueStats interface {
+	BusyWorkers() int
+	SuccessTasks() int
+	FailureTasks() int
+	SubmittedTasks() int
+	SuspendingTasks() int
+}
+
+// QueueCollector collects task counters of queues on each scrape.
+type QueueCollector struct {
+	queues func() map[string]QueueStats
+}
+
+// NewQueueCollector creates a collector reading queues returned by given function, which
+// is called on each scrape so that reloaded queues are always picked up.
+func NewQueueCollector(queues func() map[string]QueueStats) *QueueCollector {
+	return &QueueCollector{queues: queues}
+}
+
+func (c *QueueCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- queueTasksDesc
+	ch <- queueDepthDesc
+}
+
+func (c *QueueCollector) Collect(ch chan<- prometheus.Metric) {
+	for name, q := range c.queues() {
+		ch <- prometheus.MustNewConstMetric(queueTasksDesc, prometheus.CounterValue, float64(q.SubmittedTasks()), name, "submitted")
+		ch <- prometheus.MustNewConstMetric(queueTasksDesc, prometheus.CounterValue, float64(q.SuccessTasks()), name, "success")
+		ch <- prometheus.MustNewConstMetric(queueTasksDesc, prometheus.CounterValue, float64(q.FailureTasks()), name, "failure")
+
+		pending := max(q.SubmittedTasks()-q.SuccessTasks()-q.FailureTasks()-q.BusyWorkers(), 0)
+		ch <- prometheus.MustNewConstMetric(queueDepthDesc, prometheus.GaugeValue, float64(pending), name, "pending")
+		ch <- prometheus.MustNewConstMetric(queueDepthDesc, prometheus.GaugeValue, float64(q.BusyWorkers()), name, "busy")
+		ch <- prometheus.MustNewConstMetric(queueDepthDesc, prometheus.GaugeValue, float64(q.SuspendingTasks()), name, "suspending")
+	}
+}
@@ -247,6 +247,12 @@ func (q *queue) work(t Task) {
 		timeIterationStart = time.Now()
 		var next task.Status
 		next, err = q.run(ctx, t)
+		if err != nil && q.stopping() {
+			l.Info("Task %d interrupted by shutdown: %s", t.ID(), err)
+			q.checkpoint(ctx, t)
+			break
+		}
+
 		if err != nil {
 			t.OnError(err, time.Since(timeIterationStart))
 			l.Error("runtime error in queue %q: %s", q.name, err.Error())
@@ -257,13 +263,31 @@ func (q *queue) work(t Task) {
 
 		// iteration completes
 		t.OnIterationComplete(time.Since(timeIterationStart))
-		_ = q.transitStatus(ctx, t, next)
+		if next == task.StatusProcessing && q.stopping() {
+			q.checkpoint(ctx, t)
+			break
+		}
+
+		// Task context is canceled on shutdown, result of last iteration must still be saved.
+		_ = q.transitStatus(context.WithoutCancel(ctx), t, next)
 		if next != task.StatusProcessing {
 			break
 		}
 	}
 }
 
+// stopping returns true if the queue is being shut down.
+func (q *queue) stopping() bool {
+	return atomic.LoadInt32(&q.stopFlag) == 1
+}
+
+// checkpoint suspends the task interrupted by shutdown, so that it is resumed from DB on next start.
+func (q *queue) checkpoint(ctx context.Context, t Task) {
+	logging.FromContext(ctx).Info("Checkpoint task %d, it will be resumed after restart.", t.ID())
+	t.OnSuspend(time.Now().Unix())
+	_ = q.transitStatus(context.WithoutCancel(ctx), t, task.StatusSuspending)
+}
+
 func (q *queue) run(ctx context.Context, t Task) (task.Status, error) {
 	l := logging.FromContext(ctx)
 
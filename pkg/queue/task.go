@@ -104,6 +104,7 @@ const (
 	RelocateTaskType              = "relocate"
 	RemoteDownloadTaskType        = "remote_download"
 	ImportTaskType                = "import"
+	EntityChecksumTaskType        = "entity_checksum"
 
 	SlaveCreateArchiveTaskType = "slave_create_archive"
 	SlaveUploadTaskType        = "slave_upload"
@@ -450,6 +451,10 @@ func init() {
 					return err
 				}
 				q.logger.Info("Task %d suspended, resume time: %d", task.ID(), task.ResumeTime())
+				if q.stopping() {
+					// Queue is shutting down, task will be resumed from DB on next start
+					return nil
+				}
 				return q.QueueTask(ctx, task)
 			},
 		},
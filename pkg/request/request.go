@@ -149,7 +149,9 @@ func (c *HTTPClient) Request(method, target string, body io.Reader, opts ...Opti
 		}
 	}
 
-	req.Header.Set("User-Agent", "Cloudreve/"+constants.BackendVersion)
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", "Cloudreve/"+constants.BackendVersion)
+	}
 
 	if options.ctx != nil && options.withCorrelationID {
 		req.Header.Add(CorrelationHeader, logging.CorrelationID(options.ctx).String())
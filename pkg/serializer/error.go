@@ -81,6 +81,12 @@ const (
 	CodeNotFound = 404
 	// CodeConflict 资源冲突
 	CodeConflict = 409
+	// CodeRequestTooLarge 请求体过大
+	CodeRequestTooLarge = 413
+	// CodeTooManyRequests 请求过于频繁
+	CodeTooManyRequests = 429
+	// CodeMaintenanceMode 站点维护中，只读
+	CodeMaintenanceMode = 503
 	// CodeUploadFailed 上传出错
 	CodeUploadFailed = 40002
 	// CodeCreateFolderFailed 目录创建失败
@@ -255,6 +261,8 @@ const (
 	CodeDomainNotLicensed = 40087
 	// CodeAnonymouseAccessDenied 匿名用户无法访问分享
 	CodeAnonymouseAccessDenied = 40088
+	// CodeSuspiciousArchive 压缩文件疑似压缩炸弹
+	CodeSuspiciousArchive = 40089
 	// CodeDBError 数据库操作失败
 	CodeDBError = 50001
 	// CodeEncryptError 加密失败
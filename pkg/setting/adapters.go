@@ -12,7 +12,9 @@ import (
 )
 
 const (
-	KvSettingPrefix           = "setting_"
+	KvSettingPrefix = inventory.KvSettingPrefix
+	// KvSiteConfigETagPrefix caches ETag of site config sections, cleared when settings change.
+	KvSiteConfigETagPrefix    = inventory.KvSiteConfigETagPrefix
 	EnvSettingOverwritePrefix = "CR_SETTING_"
 )
 
@@ -24,6 +24,8 @@ type (
 		PWA(ctx context.Context) *PWASetting
 		// RegisterEnabled returns true if public sign-up is enabled.
 		RegisterEnabled(ctx context.Context) bool
+		// MaintenanceModeEnabled returns true if the site is in read-only maintenance mode.
+		MaintenanceModeEnabled(ctx context.Context) bool
 		// AuthnEnabled returns true if Webauthn is enabled.
 		AuthnEnabled(ctx context.Context) bool
 		// RegCaptchaEnabled returns true if registration captcha is enabled.
@@ -40,6 +42,10 @@ type (
 		TcCaptcha(ctx context.Context) *TcCaptcha
 		// TurnstileCaptcha returns the Cloudflare Turnstile settings.
 		TurnstileCaptcha(ctx context.Context) *Turnstile
+		// HCaptcha returns the hCaptcha settings.
+		HCaptcha(ctx context.Context) *HCaptcha
+		// GeeTestCaptcha returns the GeeTest settings.
+		GeeTestCaptcha(ctx context.Context) *GeeTest
 		// CapCaptcha returns the Cap settings.
 		CapCaptcha(ctx context.Context) *Cap
 		// EmailActivationEnabled returns true if email activation is required.
@@ -48,6 +54,23 @@ type (
 		DefaultGroup(ctx context.Context) int
 		// SMTP returns the SMTP settings.
 		SMTP(ctx context.Context) *SMTP
+		// DKIM returns the DKIM signing settings of outbound SMTP emails.
+		DKIM(ctx context.Context) *DKIM
+		// MailMaxAttachmentSize returns the maximum total size of email attachments, 0 for unlimited.
+		MailMaxAttachmentSize(ctx context.Context) int64
+		// MailBccAll returns the addresses that receive a blind carbon copy of all outbound emails.
+		MailBccAll(ctx context.Context) []string
+		// MailSkipDomains returns the recipient domains to which emails are never sent, e.g. placeholder
+		// addresses created by social logins.
+		MailSkipDomains(ctx context.Context) []string
+		// MailExtraHeaders returns the custom headers added to all outbound emails.
+		MailExtraHeaders(ctx context.Context) map[string]string
+		// MailDriver returns the driver used to send emails.
+		MailDriver(ctx context.Context) MailDriver
+		// MailAPI returns the settings of HTTP API based email providers.
+		MailAPI(ctx context.Context) *MailAPI
+		// SES returns the settings of Amazon SES email driver.
+		SES(ctx context.Context) *SES
 		// SiteURL returns the basic URL.
 		SiteURL(ctx context.Context) *url.URL
 		// SecretKey returns the secret key for general signature.
@@ -76,11 +99,25 @@ type (
 		UseChunkBuffer(ctx context.Context) bool
 		// Queue returns the queue settings.
 		Queue(ctx context.Context, queueType QueueType) *QueueSetting
+		// ArchiveConcurrency returns the global and per-user limit of concurrent archive creation.
+		ArchiveConcurrency(ctx context.Context) *ArchiveConcurrency
 		// EntityUrlCacheMargin returns the safe margin of entity URL cache. URL cache will
 		// expire in (EntityUrlValidDuration - EntityUrlCacheMargin).
 		EntityUrlCacheMargin(ctx context.Context) int
 		// EntityUrlValidDuration returns the valid duration of entity URL.
 		EntityUrlValidDuration(ctx context.Context) time.Duration
+		// HealthProbeTimeout returns the timeout of each subsystem probe in health check.
+		HealthProbeTimeout(ctx context.Context) time.Duration
+		// MetricsEnabled returns true if Prometheus metrics endpoint is enabled.
+		MetricsEnabled(ctx context.Context) bool
+		// MetricsToken returns the bearer token required to scrape metrics, empty means no auth.
+		MetricsToken(ctx context.Context) string
+		// SettingAuditRetention returns how long audit logs of setting changes are kept, 0 means forever.
+		SettingAuditRetention(ctx context.Context) time.Duration
+		// MaxRequestBodySize returns the max body size in bytes of non-upload API requests, 0 means no limit.
+		MaxRequestBodySize(ctx context.Context) int64
+		// AccessLog returns the structured access log settings.
+		AccessLog(ctx context.Context) *AccessLog
 		// PublicResourceMaxAge returns the max age of public resources.
 		PublicResourceMaxAge(ctx context.Context) int
 		// MediaMetaEnabled returns true if media meta is enabled.
@@ -92,6 +129,24 @@ type (
 		MediaMetaExifSizeLimit(ctx context.Context) (int64, int64)
 		// MediaMetaExifBruteForce returns true if media meta exif brute force search is enabled.
 		MediaMetaExifBruteForce(ctx context.Context) bool
+		// MediaMetaXMPEnabled returns true if media meta xmp is enabled.
+		MediaMetaXMPEnabled(ctx context.Context) bool
+		// MediaMetaXMPSizeLimit returns the size limit of media meta xmp. first return value is for local sources;
+		// second return value is for remote sources.
+		MediaMetaXMPSizeLimit(ctx context.Context) (int64, int64)
+		// MediaMetaIPTCEnabled returns true if media meta iptc is enabled.
+		MediaMetaIPTCEnabled(ctx context.Context) bool
+		// MediaMetaIPTCKeywordsAsTags returns true if IPTC keywords should be saved as file tags.
+		MediaMetaIPTCKeywordsAsTags(ctx context.Context) bool
+		// MediaMetaPdfEnabled returns true if media meta pdf is enabled.
+		MediaMetaPdfEnabled(ctx context.Context) bool
+		// MediaMetaPdfSizeLimit returns the size limit of media meta pdf. first return value is for local sources;
+		// second return value is for remote sources.
+		MediaMetaPdfSizeLimit(ctx context.Context) (int64, int64)
+		// MediaMetaAudioAnalysisEnabled returns true if BPM and loudness of audio files should be computed.
+		MediaMetaAudioAnalysisEnabled(ctx context.Context) bool
+		// MediaMetaAudioAnalysisMaxSize returns the max size of audio files to be analyzed, 0 means no limit.
+		MediaMetaAudioAnalysisMaxSize(ctx context.Context) int64
 		// MediaMetaMusicEnabled returns true if media meta audio is enabled.
 		MediaMetaMusicEnabled(ctx context.Context) bool
 		// MediaMetaMusicSizeLimit returns the size limit of media meta audio. first return value is for local sources;
@@ -106,6 +161,23 @@ type (
 		MediaMetaGeocodingEnabled(ctx context.Context) bool
 		// MediaMetaGeocodingMapboxAK returns the Mapbox access token.
 		MediaMetaGeocodingMapboxAK(ctx context.Context) string
+		// EntityChecksumAlgorithm returns the hash algorithm of entity checksums, empty if disabled.
+		EntityChecksumAlgorithm(ctx context.Context) ChecksumAlgorithm
+		// MediaMetaGeocodingProvider returns the reverse geocoding provider, either mapbox or nominatim.
+		MediaMetaGeocodingProvider(ctx context.Context) GeocodingProvider
+		// MediaMetaGeocodingNominatimURL returns the reverse endpoint of Nominatim.
+		MediaMetaGeocodingNominatimURL(ctx context.Context) string
+		// MediaMetaGeocodingUserAgent returns the User-Agent sent to geocoding provider.
+		MediaMetaGeocodingUserAgent(ctx context.Context) string
+		// MediaMetaGeocodingBatchSize returns the max number of coordinates in one Mapbox batch
+		// geocoding request, batching is disabled if not greater than 1.
+		MediaMetaGeocodingBatchSize(ctx context.Context) int
+		// MediaMetaGeocodingTimeout returns the timeout of a single reverse geocoding request.
+		MediaMetaGeocodingTimeout(ctx context.Context) time.Duration
+		// MediaMetaGeocodingMaxRetry returns the max number of retries of a rate limited geocoding request.
+		MediaMetaGeocodingMaxRetry(ctx context.Context) int
+		// MediaMetaPairing returns the RAW/HEIC and JPEG pairing rules.
+		MediaMetaPairing(ctx context.Context) *MediaMetaPairing
 		// ThumbSize returns the size limit of thumbnails.
 		ThumbSize(ctx context.Context) (int, int)
 		// ThumbEncode returns the thumbnail encoding settings.
@@ -114,14 +186,27 @@ type (
 		BuiltinThumbGeneratorEnabled(ctx context.Context) bool
 		// BuiltinThumbMaxSize returns the maximum size of builtin thumb generator.
 		BuiltinThumbMaxSize(ctx context.Context) int64
+		// ThumbBlurhashEnabled returns true if blurhash of images is computed by builtin thumb generator.
+		ThumbBlurhashEnabled(ctx context.Context) bool
+		// ThumbDominantColorEnabled returns true if dominant color of images is computed by builtin thumb generator.
+		ThumbDominantColorEnabled(ctx context.Context) bool
+		// ThumbExternalConcurrency returns the max number of concurrent external thumb generator processes, 0 means no limit.
+		ThumbExternalConcurrency(ctx context.Context) int
 		// TempPath returns the path of temporary directory.
 		TempPath(ctx context.Context) string
+		// ArchiveTempPath returns the path of temporary directory for archive tasks, fallback to TempPath if not set.
+		ArchiveTempPath(ctx context.Context) string
+		// ArchiveExtractMaxEntrySize returns the maximum size of each file extracted from archive, 0 for unlimited.
+		ArchiveExtractMaxEntrySize(ctx context.Context) int64
 		// ThumbEntitySuffix returns the suffix of entity thumbnails.
 		ThumbEntitySuffix(ctx context.Context) string
 		// ThumbSlaveSidecarSuffix returns the suffix of slave sidecar thumbnails.
 		ThumbSlaveSidecarSuffix(ctx context.Context) string
 		// ThumbGCAfterGen returns true if force GC is invoked after thumb generation.
 		ThumbGCAfterGen(ctx context.Context) bool
+		// ThumbContentCacheMaxSize returns the maximum size of files whose content is hashed to reuse thumbnails,
+		// files with stored checksums are not limited. 0 means disabled.
+		ThumbContentCacheMaxSize(ctx context.Context) int64
 		// FFMpegPath returns the path of ffmpeg executable.
 		FFMpegPath(ctx context.Context) string
 		// FFMpegThumbGeneratorEnabled returns true if ffmpeg thumb generator is enabled.
@@ -154,8 +239,15 @@ type (
 		MusicCoverThumbMaxSize(ctx context.Context) int64
 		// MusicCoverThumbExts returns the supported extensions of music cover thumb generator.
 		MusicCoverThumbExts(ctx context.Context) []string
+		// MusicCoverFolderCandidates returns the file names of cover images in the same folder that will be
+		// used as fallback if no embedded cover is found.
+		MusicCoverFolderCandidates(ctx context.Context) []string
+		// MusicCoverFolderMaxSize returns the maximum size of cover image in the same folder.
+		MusicCoverFolderMaxSize(ctx context.Context) int64
 		// Cron returns the crontab settings.
 		Cron(ctx context.Context, t CronType) string
+		// OauthCredRefresh returns the OAuth credential refresh settings.
+		OauthCredRefresh(ctx context.Context) *OauthCredRefresh
 		// Theme returns the theme settings.
 		Theme(ctx context.Context) *Theme
 		// Logo returns the logo settings.
@@ -174,6 +266,8 @@ type (
 		MapSetting(ctx context.Context) *MapSetting
 		// FolderPropsCacheTTL returns the cache TTL of folder summary.
 		FolderPropsCacheTTL(ctx context.Context) int
+		// FolderPropsBatchSize returns the number of children fetched in one query when calculating folder summary.
+		FolderPropsBatchSize(ctx context.Context) int
 		// FileViewers returns the file viewers settings.
 		FileViewers(ctx context.Context) []types.ViewerGroup
 		// ViewerSessionTTL returns the TTL of viewer session.
@@ -200,6 +294,21 @@ type (
 		LibRawThumbExts(ctx context.Context) []string
 		// LibRawThumbPath returns the path of libraw executable.
 		LibRawThumbPath(ctx context.Context) string
+		// PdfThumbGeneratorEnabled returns true if native PDF thumb generator is enabled.
+		PdfThumbGeneratorEnabled(ctx context.Context) bool
+		// PdfThumbMaxSize returns the maximum size of native PDF thumb generator.
+		PdfThumbMaxSize(ctx context.Context) int64
+		// SvgThumbGeneratorEnabled returns true if SVG thumb generator is enabled.
+		SvgThumbGeneratorEnabled(ctx context.Context) bool
+		// SvgThumbMaxSize returns the maximum size of SVG thumb generator.
+		SvgThumbMaxSize(ctx context.Context) int64
+		// SvgThumbMaxNodes returns the maximum number of elements an SVG can be rendered with, counting
+		// elements referenced by <use> repeatedly.
+		SvgThumbMaxNodes(ctx context.Context) int
+		// EpubThumbGeneratorEnabled returns true if EPUB cover thumb generator is enabled.
+		EpubThumbGeneratorEnabled(ctx context.Context) bool
+		// EpubThumbMaxSize returns the maximum size of EPUB cover thumb generator.
+		EpubThumbMaxSize(ctx context.Context) int64
 		// CustomProps returns the custom props settings.
 		CustomProps(ctx context.Context) []types.CustomProps
 		// CustomNavItems returns the custom nav items settings.
@@ -208,6 +317,18 @@ type (
 		CustomHTML(ctx context.Context) *CustomHTML
 		// FFMpegExtraArgs returns the extra arguments of ffmpeg thumb generator.
 		FFMpegExtraArgs(ctx context.Context) string
+		// FFMpegAnimatedThumb returns the animated video preview settings.
+		FFMpegAnimatedThumb(ctx context.Context) *AnimatedThumb
+		// ThumbAnimatedEntitySuffix returns the suffix of animated thumbnail entities.
+		ThumbAnimatedEntitySuffix(ctx context.Context) string
+		// Get returns the raw string value of a setting, empty if not found.
+		Get(ctx context.Context, name string) string
+		// GetInt returns the setting value parsed as int, or a *ValueError if it cannot be parsed.
+		GetInt(ctx context.Context, name string) (int, error)
+		// GetBool returns the setting value parsed as bool, or a *ValueError if it cannot be parsed.
+		GetBool(ctx context.Context, name string) (bool, error)
+		// GetDuration returns the setting value parsed as duration, or a *ValueError if it cannot be parsed.
+		GetDuration(ctx context.Context, name string) (time.Duration, error)
 	}
 	UseFirstSiteUrlCtxKey = struct{}
 )
@@ -348,6 +469,7 @@ func (s *settingProvider) Captcha(ctx context.Context) *Captcha {
 		IsShowSlimeLine:    s.getBoolean(ctx, "captcha_IsShowSlimeLine", false),
 		IsShowSineLine:     s.getBoolean(ctx, "captcha_IsShowSineLine", false),
 		Length:             s.getInt(ctx, "captcha_CaptchaLen", 6),
+		AudioEnabled:       s.getBoolean(ctx, "captcha_audio_enabled", false),
 	}
 }
 
@@ -362,6 +484,10 @@ func (s *settingProvider) FolderPropsCacheTTL(ctx context.Context) int {
 	return s.getInt(ctx, "folder_props_timeout", 300)
 }
 
+func (s *settingProvider) FolderPropsBatchSize(ctx context.Context) int {
+	return s.getInt(ctx, "folder_props_batch_size", 1000)
+}
+
 func (s *settingProvider) EmojiPresets(ctx context.Context) string {
 	return s.getString(ctx, "emojis", "{}")
 }
@@ -377,6 +503,16 @@ func (s *settingProvider) Cron(ctx context.Context, t CronType) string {
 	return s.getString(ctx, "cron_"+string(t), "@hourly")
 }
 
+func (s *settingProvider) OauthCredRefresh(ctx context.Context) *OauthCredRefresh {
+	return &OauthCredRefresh{
+		MaxRetry:       s.getInt(ctx, "oauth_cred_refresh_max_retry", 3),
+		RetryDelay:     time.Duration(s.getInt(ctx, "oauth_cred_refresh_retry_delay", 10)) * time.Second,
+		BackoffFactor:  s.getFloat64(ctx, "oauth_cred_refresh_backoff_factor", 2),
+		AlertThreshold: s.getInt(ctx, "oauth_cred_refresh_alert_threshold", 3),
+		AlertMargin:    time.Duration(s.getInt(ctx, "oauth_cred_refresh_alert_margin", 86400)) * time.Second,
+	}
+}
+
 func (s *settingProvider) BuiltinThumbGeneratorEnabled(ctx context.Context) bool {
 	return s.getBoolean(ctx, "thumb_builtin_enabled", true)
 }
@@ -385,6 +521,18 @@ func (s *settingProvider) BuiltinThumbMaxSize(ctx context.Context) int64 {
 	return s.getInt64(ctx, "thumb_builtin_max_size", 78643200)
 }
 
+func (s *settingProvider) ThumbBlurhashEnabled(ctx context.Context) bool {
+	return s.getBoolean(ctx, "thumb_blurhash_enabled", false)
+}
+
+func (s *settingProvider) ThumbDominantColorEnabled(ctx context.Context) bool {
+	return s.getBoolean(ctx, "thumb_dominant_color_enabled", false)
+}
+
+func (s *settingProvider) ThumbExternalConcurrency(ctx context.Context) int {
+	return s.getInt(ctx, "thumb_external_concurrency", 0)
+}
+
 func (s *settingProvider) MusicCoverThumbGeneratorEnabled(ctx context.Context) bool {
 	return s.getBoolean(ctx, "thumb_music_cover_enabled", true)
 }
@@ -397,6 +545,14 @@ func (s *settingProvider) MusicCoverThumbExts(ctx context.Context) []string {
 	return s.getStringList(ctx, "thumb_music_cover_exts", []string{})
 }
 
+func (s *settingProvider) MusicCoverFolderCandidates(ctx context.Context) []string {
+	return s.getStringList(ctx, "thumb_music_cover_folder_candidates", []string{})
+}
+
+func (s *settingProvider) MusicCoverFolderMaxSize(ctx context.Context) int64 {
+	return s.getInt64(ctx, "thumb_music_cover_folder_max_size", 10485760)
+}
+
 func (s *settingProvider) FFMpegPath(ctx context.Context) string {
 	return s.getString(ctx, "thumb_ffmpeg_path", "ffmpeg")
 }
@@ -417,6 +573,14 @@ func (s *settingProvider) FFMpegExtraArgs(ctx context.Context) string {
 	return s.getString(ctx, "thumb_ffmpeg_extra_args", "")
 }
 
+func (s *settingProvider) FFMpegAnimatedThumb(ctx context.Context) *AnimatedThumb {
+	return &AnimatedThumb{
+		Enabled: s.getBoolean(ctx, "thumb_ffmpeg_animated", false),
+		Frames:  s.getInt(ctx, "thumb_ffmpeg_animated_frames", 8),
+		Format:  s.getString(ctx, "thumb_ffmpeg_animated_format", "webp"),
+	}
+}
+
 func (s *settingProvider) FFMpegThumbMaxSize(ctx context.Context) int64 {
 	return s.getInt64(ctx, "thumb_ffmpeg_max_size", 10737418240)
 }
@@ -453,6 +617,34 @@ func (s *settingProvider) LibRawThumbPath(ctx context.Context) string {
 	return s.getString(ctx, "thumb_libraw_path", "simple_dcraw")
 }
 
+func (s *settingProvider) PdfThumbGeneratorEnabled(ctx context.Context) bool {
+	return s.getBoolean(ctx, "thumb_pdf_enabled", false)
+}
+
+func (s *settingProvider) PdfThumbMaxSize(ctx context.Context) int64 {
+	return s.getInt64(ctx, "thumb_pdf_max_size", 78643200)
+}
+
+func (s *settingProvider) SvgThumbGeneratorEnabled(ctx context.Context) bool {
+	return s.getBoolean(ctx, "thumb_svg_enabled", true)
+}
+
+func (s *settingProvider) SvgThumbMaxSize(ctx context.Context) int64 {
+	return s.getInt64(ctx, "thumb_svg_max_size", 2097152)
+}
+
+func (s *settingProvider) SvgThumbMaxNodes(ctx context.Context) int {
+	return s.getInt(ctx, "thumb_svg_max_nodes", 10000)
+}
+
+func (s *settingProvider) EpubThumbGeneratorEnabled(ctx context.Context) bool {
+	return s.getBoolean(ctx, "thumb_epub_enabled", true)
+}
+
+func (s *settingProvider) EpubThumbMaxSize(ctx context.Context) int64 {
+	return s.getInt64(ctx, "thumb_epub_max_size", 104857600)
+}
+
 func (s *settingProvider) LibreOfficeThumbGeneratorEnabled(ctx context.Context) bool {
 	return s.getBoolean(ctx, "thumb_libreoffice_enabled", false)
 }
@@ -484,6 +676,10 @@ func (s *settingProvider) ThumbEntitySuffix(ctx context.Context) string {
 	return s.getString(ctx, "thumb_entity_suffix", "._thumb")
 }
 
+func (s *settingProvider) ThumbAnimatedEntitySuffix(ctx context.Context) string {
+	return s.getString(ctx, "thumb_animated_entity_suffix", "._thumb_animated")
+}
+
 func (s *settingProvider) ThumbSlaveSidecarSuffix(ctx context.Context) string {
 	return s.getString(ctx, "thumb_slave_sidecar_suffix", "._thumb_sidecar")
 }
@@ -492,10 +688,26 @@ func (s *settingProvider) ThumbGCAfterGen(ctx context.Context) bool {
 	return s.getBoolean(ctx, "thumb_gc_after_gen", false)
 }
 
+func (s *settingProvider) ThumbContentCacheMaxSize(ctx context.Context) int64 {
+	return s.getInt64(ctx, "thumb_content_cache_max_size", 0)
+}
+
 func (s *settingProvider) TempPath(ctx context.Context) string {
 	return s.getString(ctx, "temp_path", "temp")
 }
 
+func (s *settingProvider) ArchiveTempPath(ctx context.Context) string {
+	if p := s.getString(ctx, "archive_temp_path", ""); p != "" {
+		return p
+	}
+
+	return s.TempPath(ctx)
+}
+
+func (s *settingProvider) ArchiveExtractMaxEntrySize(ctx context.Context) int64 {
+	return s.getInt64(ctx, "archive_extract_max_entry_size", 0)
+}
+
 func (s *settingProvider) MediaMetaFFProbePath(ctx context.Context) string {
 	return s.getString(ctx, "media_meta_ffprobe_path", "ffprobe")
 }
@@ -512,6 +724,14 @@ func (s *settingProvider) MediaMetaMusicSizeLimit(ctx context.Context) (int64, i
 	return s.getInt64(ctx, "media_meta_music_size_local", 0), s.getInt64(ctx, "media_meta_music_size_remote", 0)
 }
 
+func (s *settingProvider) MediaMetaAudioAnalysisEnabled(ctx context.Context) bool {
+	return s.getBoolean(ctx, "media_meta_audio_analysis", false)
+}
+
+func (s *settingProvider) MediaMetaAudioAnalysisMaxSize(ctx context.Context) int64 {
+	return s.getInt64(ctx, "media_meta_audio_analysis_max_size", 0)
+}
+
 func (s *settingProvider) MediaMetaMusicEnabled(ctx context.Context) bool {
 	return s.getBoolean(ctx, "media_meta_music", true)
 }
@@ -528,6 +748,30 @@ func (s *settingProvider) MediaMetaExifEnabled(ctx context.Context) bool {
 	return s.getBoolean(ctx, "media_meta_exif", true)
 }
 
+func (s *settingProvider) MediaMetaIPTCEnabled(ctx context.Context) bool {
+	return s.getBoolean(ctx, "media_meta_iptc", true)
+}
+
+func (s *settingProvider) MediaMetaIPTCKeywordsAsTags(ctx context.Context) bool {
+	return s.getBoolean(ctx, "media_meta_iptc_keywords_as_tags", false)
+}
+
+func (s *settingProvider) MediaMetaPdfEnabled(ctx context.Context) bool {
+	return s.getBoolean(ctx, "media_meta_pdf", true)
+}
+
+func (s *settingProvider) MediaMetaPdfSizeLimit(ctx context.Context) (int64, int64) {
+	return s.getInt64(ctx, "media_meta_pdf_size_local", 0), s.getInt64(ctx, "media_meta_pdf_size_remote", 0)
+}
+
+func (s *settingProvider) MediaMetaXMPEnabled(ctx context.Context) bool {
+	return s.getBoolean(ctx, "media_meta_xmp", true)
+}
+
+func (s *settingProvider) MediaMetaXMPSizeLimit(ctx context.Context) (int64, int64) {
+	return s.getInt64(ctx, "media_meta_xmp_size_local", 0), s.getInt64(ctx, "media_meta_xmp_size_remote", 0)
+}
+
 func (s *settingProvider) MediaMetaEnabled(ctx context.Context) bool {
 	return s.getBoolean(ctx, "media_meta", true)
 }
@@ -540,6 +784,42 @@ func (s *settingProvider) MediaMetaGeocodingMapboxAK(ctx context.Context) string
 	return s.getString(ctx, "media_meta_geocoding_mapbox_ak", "")
 }
 
+func (s *settingProvider) MediaMetaGeocodingProvider(ctx context.Context) GeocodingProvider {
+	return GeocodingProvider(s.getString(ctx, "media_meta_geocoding_provider", string(GeocodingProviderMapbox)))
+}
+
+func (s *settingProvider) EntityChecksumAlgorithm(ctx context.Context) ChecksumAlgorithm {
+	return ChecksumAlgorithm(s.getString(ctx, "entity_checksum_algorithm", string(ChecksumAlgorithmNone)))
+}
+
+func (s *settingProvider) MediaMetaGeocodingNominatimURL(ctx context.Context) string {
+	return s.getString(ctx, "media_meta_geocoding_nominatim_url", "https://nominatim.openstreetmap.org/reverse")
+}
+
+func (s *settingProvider) MediaMetaGeocodingUserAgent(ctx context.Context) string {
+	return s.getString(ctx, "media_meta_geocoding_user_agent", "")
+}
+
+func (s *settingProvider) MediaMetaGeocodingBatchSize(ctx context.Context) int {
+	return s.getInt(ctx, "media_meta_geocoding_batch_size", 0)
+}
+
+func (s *settingProvider) MediaMetaGeocodingTimeout(ctx context.Context) time.Duration {
+	return time.Duration(s.getInt(ctx, "media_meta_geocoding_timeout", 10)) * time.Second
+}
+
+func (s *settingProvider) MediaMetaGeocodingMaxRetry(ctx context.Context) int {
+	return s.getInt(ctx, "media_meta_geocoding_max_retry", 3)
+}
+
+func (s *settingProvider) MediaMetaPairing(ctx context.Context) *MediaMetaPairing {
+	return &MediaMetaPairing{
+		Enabled:       s.getBoolean(ctx, "media_meta_pair", false),
+		PrimaryExts:   s.getStringList(ctx, "media_meta_pair_primary_exts", []string{}),
+		CompanionExts: s.getStringList(ctx, "media_meta_pair_companion_exts", []string{"jpg", "jpeg"}),
+	}
+}
+
 func (s *settingProvider) PublicResourceMaxAge(ctx context.Context) int {
 	return s.getInt(ctx, "public_resource_maxage", 0)
 }
@@ -552,6 +832,41 @@ func (s *settingProvider) EntityUrlValidDuration(ctx context.Context) time.Durat
 	return time.Duration(s.getInt(ctx, "entity_url_default_ttl", 3600)) * time.Second
 }
 
+func (s *settingProvider) HealthProbeTimeout(ctx context.Context) time.Duration {
+	return time.Duration(s.getInt(ctx, "health_probe_timeout", 5)) * time.Second
+}
+
+func (s *settingProvider) MetricsEnabled(ctx context.Context) bool {
+	return s.getBoolean(ctx, "metrics_enabled", false)
+}
+
+func (s *settingProvider) MetricsToken(ctx context.Context) string {
+	return s.getString(ctx, "metrics_token", "")
+}
+
+func (s *settingProvider) SettingAuditRetention(ctx context.Context) time.Duration {
+	return time.Duration(s.getInt(ctx, "setting_audit_retention_days", 180)) * 24 * time.Hour
+}
+
+func (s *settingProvider) MaxRequestBodySize(ctx context.Context) int64 {
+	return s.getInt64(ctx, "max_request_body_size", 2097152)
+}
+
+func (s *settingProvider) AccessLog(ctx context.Context) *AccessLog {
+	sampleRates := make(map[string]float64)
+	if err := json.Unmarshal([]byte(s.getString(ctx, "access_log_sample_rates", "{}")), &sampleRates); err != nil {
+		sampleRates = map[string]float64{}
+	}
+
+	return &AccessLog{
+		Enabled:         s.getBoolean(ctx, "access_log_enabled", false),
+		Format:          AccessLogFormat(s.getString(ctx, "access_log_format", string(AccessLogFormatText))),
+		Fields:          trimmedList(s.getStringList(ctx, "access_log_fields", []string{})),
+		SampleRates:     sampleRates,
+		SensitiveParams: trimmedList(s.getStringList(ctx, "access_log_sensitive_params", []string{})),
+	}
+}
+
 func (s *settingProvider) Queue(ctx context.Context, queueType QueueType) *QueueSetting {
 	queueTypeStr := string(queueType)
 	return &QueueSetting{
@@ -564,6 +879,13 @@ func (s *settingProvider) Queue(ctx context.Context, queueType QueueType) *Queue
 	}
 }
 
+func (s *settingProvider) ArchiveConcurrency(ctx context.Context) *ArchiveConcurrency {
+	return &ArchiveConcurrency{
+		Global:  s.getInt(ctx, "queue_archive_worker_num", 10),
+		PerUser: s.getInt(ctx, "queue_archive_user_worker_num", 2),
+	}
+}
+
 func (s *settingProvider) UseChunkBuffer(ctx context.Context) bool {
 	return s.getBoolean(ctx, "use_temp_chunk_buffer", true)
 }
@@ -688,6 +1010,59 @@ func (s *settingProvider) SMTP(ctx context.Context) *SMTP {
 		ForceEncryption: s.getBoolean(ctx, "smtpEncryption", false),
 		Port:            s.getInt(ctx, "smtpPort", 25),
 		Keepalive:       s.getInt(ctx, "mail_keepalive", 30),
+		MaxRetry:        s.getInt(ctx, "mail_max_retry", 3),
+		RetryInterval:   s.getInt(ctx, "mail_retry_interval", 30),
+		QueueSize:       s.getInt(ctx, "mail_queue_size", 30),
+		Workers:         s.getInt(ctx, "mail_workers", 1),
+	}
+}
+
+func (s *settingProvider) DKIM(ctx context.Context) *DKIM {
+	return &DKIM{
+		PrivateKey: s.getString(ctx, "dkim_private_key", ""),
+		Domain:     s.getString(ctx, "dkim_domain", ""),
+		Selector:   s.getString(ctx, "dkim_selector", ""),
+	}
+}
+
+func (s *settingProvider) MailMaxAttachmentSize(ctx context.Context) int64 {
+	return s.getInt64(ctx, "mail_max_attachment_size", 10485760)
+}
+
+func (s *settingProvider) MailBccAll(ctx context.Context) []string {
+	return trimmedList(s.getStringList(ctx, "mail_bcc_all", []string{}))
+}
+
+func (s *settingProvider) MailSkipDomains(ctx context.Context) []string {
+	return trimmedList(s.getStringList(ctx, "mail_skip_domains", []string{"login.qq.com"}))
+}
+
+func (s *settingProvider) MailExtraHeaders(ctx context.Context) map[string]string {
+	raw := s.getString(ctx, "mail_extra_headers", "{}")
+	headers := make(map[string]string)
+	if err := json.Unmarshal([]byte(raw), &headers); err != nil {
+		return map[string]string{}
+	}
+	return headers
+}
+
+func (s *settingProvider) MailDriver(ctx context.Context) MailDriver {
+	return MailDriver(s.getString(ctx, "mail_driver", string(MailDriverSMTP)))
+}
+
+func (s *settingProvider) MailAPI(ctx context.Context) *MailAPI {
+	return &MailAPI{
+		Key:      s.getString(ctx, "mail_api_key", ""),
+		Domain:   s.getString(ctx, "mail_api_domain", ""),
+		Endpoint: s.getString(ctx, "mail_api_endpoint", ""),
+	}
+}
+
+func (s *settingProvider) SES(ctx context.Context) *SES {
+	return &SES{
+		Region:    s.getString(ctx, "ses_region", ""),
+		AccessKey: s.getString(ctx, "ses_access_key", ""),
+		SecretKey: s.getString(ctx, "ses_secret_key", ""),
 	}
 }
 
@@ -715,6 +1090,20 @@ func (s *settingProvider) TurnstileCaptcha(ctx context.Context) *Turnstile {
 	}
 }
 
+func (s *settingProvider) HCaptcha(ctx context.Context) *HCaptcha {
+	return &HCaptcha{
+		Secret: s.getString(ctx, "captcha_hcaptcha_secret", ""),
+		Key:    s.getString(ctx, "captcha_hcaptcha_site_key", ""),
+	}
+}
+
+func (s *settingProvider) GeeTestCaptcha(ctx context.Context) *GeeTest {
+	return &GeeTest{
+		ID:  s.getString(ctx, "captcha_geetest_id", ""),
+		Key: s.getString(ctx, "captcha_geetest_key", ""),
+	}
+}
+
 func (s *settingProvider) CapCaptcha(ctx context.Context) *Cap {
 	return &Cap{
 		InstanceURL: s.getString(ctx, "captcha_cap_instance_url", ""),
@@ -751,6 +1140,10 @@ func (s *settingProvider) AuthnEnabled(ctx context.Context) bool {
 	return s.getBoolean(ctx, "authn_enabled", false)
 }
 
+func (s *settingProvider) MaintenanceModeEnabled(ctx context.Context) bool {
+	return s.getBoolean(ctx, "maintenance_mode", false)
+}
+
 func (s *settingProvider) RegisterEnabled(ctx context.Context) bool {
 	return s.getBoolean(ctx, "register_enabled", false)
 }
@@ -762,6 +1155,8 @@ func (s *settingProvider) SiteBasic(ctx context.Context) *SiteBasic {
 		ID:          s.getString(ctx, "siteID", ""),
 		Description: s.getString(ctx, "siteDes", ""),
 		Script:      s.getString(ctx, "siteScript", ""),
+
+		DefaultLanguage: s.getString(ctx, "site_default_language", ""),
 	}
 }
 
@@ -851,6 +1246,17 @@ func (s *settingProvider) getStringListRaw(ctx context.Context, name string, def
 	return strings.Split(val, stringListDefaultSeparator), val
 }
 
+// trimmedList trims spaces of each item and removes empty ones.
+func trimmedList(list []string) []string {
+	res := make([]string, 0, len(list))
+	for _, item := range list {
+		if item = strings.TrimSpace(item); item != "" {
+			res = append(res, item)
+		}
+	}
+	return res
+}
+
 func (s *settingProvider) getBoolSet(ctx context.Context, name string) *boolset.BooleanSet {
 	val := s.getString(ctx, name, "")
 	if val == "" {
@@ -0,0 +1,24 @@
+// Package settingtest provides a map backed setting store for tests.
+package settingtest
+
+import (
+	"context"
+
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
+)
+
+// Store is a setting store backed by a map, settings not in the map fall back to default value.
+type Store map[string]any
+
+func (s Store) Get(ctx context.Context, name string, defaultVal any) any {
+	if v, ok := s[name]; ok {
+		return v
+	}
+
+	return defaultVal
+}
+
+// NewProvider creates a setting provider reading settings from given map.
+func NewProvider(settings map[string]any) setting.Provider {
+	return setting.NewProvider(Store(settings))
+}
@@ -0,0 +1,102 @@
+package setting
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+type (
+	// Value is the type that a setting value can be parsed into.
+	Value interface {
+		int | int64 | float64 | bool | string | time.Duration
+	}
+
+	// ValueError is returned when a setting value cannot be parsed into the requested type.
+	ValueError struct {
+		Name  string
+		Value string
+		Type  string
+		Err   error
+	}
+)
+
+func (e *ValueError) Error() string {
+	return fmt.Sprintf("setting %q has invalid %s value %q: %s", e.Name, e.Type, e.Value, e.Err)
+}
+
+func (e *ValueError) Unwrap() error {
+	return e.Err
+}
+
+// GetWithDefault returns the setting value parsed as T, defaultVal is returned if the setting
+// is not found or cannot be parsed.
+func GetWithDefault[T Value](ctx context.Context, p Provider, name string, defaultVal T) T {
+	raw := p.Get(ctx, name)
+	if raw == "" {
+		return defaultVal
+	}
+
+	res, err := parseValue[T](name, raw)
+	if err != nil {
+		return defaultVal
+	}
+
+	return res
+}
+
+func (s *settingProvider) Get(ctx context.Context, name string) string {
+	val := s.adapterChain.Get(ctx, name, "")
+	if str, ok := val.(string); ok {
+		return str
+	}
+
+	// Typed value cached by other getters
+	return fmt.Sprint(val)
+}
+
+func (s *settingProvider) GetInt(ctx context.Context, name string) (int, error) {
+	return parseValue[int](name, s.Get(ctx, name))
+}
+
+func (s *settingProvider) GetBool(ctx context.Context, name string) (bool, error) {
+	return parseValue[bool](name, s.Get(ctx, name))
+}
+
+func (s *settingProvider) GetDuration(ctx context.Context, name string) (time.Duration, error) {
+	return parseValue[time.Duration](name, s.Get(ctx, name))
+}
+
+// parseValue parses raw setting value into T. Durations are stored as seconds in most settings,
+// so an integer duration is treated as seconds, otherwise it's parsed by time.ParseDuration.
+func parseValue[T Value](name, raw string) (T, error) {
+	var (
+		res T
+		err error
+	)
+	switch v := any(&res).(type) {
+	case *int:
+		*v, err = strconv.Atoi(raw)
+	case *int64:
+		*v, err = strconv.ParseInt(raw, 10, 64)
+	case *float64:
+		*v, err = strconv.ParseFloat(raw, 64)
+	case *bool:
+		*v, err = strconv.ParseBool(raw)
+	case *string:
+		*v = raw
+	case *time.Duration:
+		if seconds, intErr := strconv.ParseInt(raw, 10, 64); intErr == nil {
+			*v = time.Duration(seconds) * time.Second
+		} else {
+			*v, err = time.ParseDuration(raw)
+		}
+	}
+
+	if err != nil {
+		return res, &ValueError{Name: name, Value: raw, Type: fmt.Sprintf("%T", res), Err: err}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,114 @@
+package setting
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/cloudreve/Cloudreve/v4/pkg/cache"
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestProvider(db map[string]any) Provider {
+	l := logging.NewConsoleLogger(logging.LevelError)
+	return NewProvider(NewEnvOverrideStore(
+		NewKvSettingStore(cache.NewMemoStore("", l),
+			&staticSettingStore{settings: db},
+		),
+		l,
+	))
+}
+
+func TestTypedGetters(t *testing.T) {
+	a := assert.New(t)
+	ctx := context.Background()
+	p := newTestProvider(map[string]any{
+		"int":          "42",
+		"bool":         "1",
+		"bool_word":    "false",
+		"seconds":      "30",
+		"duration":     "1h30m",
+		"bad_int":      "4 2",
+		"bad_bool":     "yes",
+		"bad_duration": "soon",
+	})
+
+	v, err := p.GetInt(ctx, "int")
+	a.NoError(err)
+	a.Equal(42, v)
+
+	b, err := p.GetBool(ctx, "bool")
+	a.NoError(err)
+	a.True(b)
+	b, err = p.GetBool(ctx, "bool_word")
+	a.NoError(err)
+	a.False(b)
+
+	d, err := p.GetDuration(ctx, "seconds")
+	a.NoError(err)
+	a.Equal(30*time.Second, d)
+	d, err = p.GetDuration(ctx, "duration")
+	a.NoError(err)
+	a.Equal(90*time.Minute, d)
+
+	// Malformed values
+	_, err = p.GetInt(ctx, "bad_int")
+	var valueErr *ValueError
+	a.True(errors.As(err, &valueErr))
+	a.Equal("bad_int", valueErr.Name)
+	a.Equal("4 2", valueErr.Value)
+	a.Equal("int", valueErr.Type)
+	a.ErrorIs(err, strconv.ErrSyntax)
+
+	_, err = p.GetBool(ctx, "bad_bool")
+	a.True(errors.As(err, &valueErr))
+	_, err = p.GetDuration(ctx, "bad_duration")
+	a.True(errors.As(err, &valueErr))
+	a.Equal("time.Duration", valueErr.Type)
+	_, err = p.GetInt(ctx, "missing")
+	a.True(errors.As(err, &valueErr))
+}
+
+func TestGetWithDefault(t *testing.T) {
+	a := assert.New(t)
+	ctx := context.Background()
+	p := newTestProvider(map[string]any{
+		"int":     "42",
+		"bad_int": "abc",
+		"rate":    "0.5",
+		"ttl":     "60",
+		"name":    "cloudreve",
+	})
+
+	a.Equal(42, GetWithDefault(ctx, p, "int", 1))
+	a.Equal(1, GetWithDefault(ctx, p, "bad_int", 1))
+	a.Equal(1, GetWithDefault(ctx, p, "missing", 1))
+	a.Equal(int64(42), GetWithDefault(ctx, p, "int", int64(0)))
+	a.Equal(0.5, GetWithDefault(ctx, p, "rate", 1.0))
+	a.Equal(time.Minute, GetWithDefault(ctx, p, "ttl", time.Second))
+	a.Equal("cloudreve", GetWithDefault(ctx, p, "name", "default"))
+	a.True(GetWithDefault(ctx, p, "missing", true))
+}
+
+func TestTypedGetters_EnvOverride(t *testing.T) {
+	a := assert.New(t)
+	ctx := context.Background()
+	t.Setenv(EnvSettingOverwritePrefix+"int", "7")
+	t.Setenv(EnvSettingOverwritePrefix+"bool", "oops")
+	p := newTestProvider(map[string]any{"int": "42", "bool": "1"})
+
+	// Environment overrides take precedence over cached and stored values
+	v, err := p.GetInt(ctx, "int")
+	a.NoError(err)
+	a.Equal(7, v)
+	a.Equal(7, GetWithDefault(ctx, p, "int", 1))
+
+	_, err = p.GetBool(ctx, "bool")
+	var valueErr *ValueError
+	a.True(errors.As(err, &valueErr))
+	a.Equal("oops", valueErr.Value)
+	a.False(GetWithDefault(ctx, p, "bool", false))
+}
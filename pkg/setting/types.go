@@ -19,6 +19,8 @@ type SiteBasic struct {
 	ID          string
 	Description string
 	Script      string
+	// DefaultLanguage is used for emails to anonymous users or users without language preference.
+	DefaultLanguage string
 }
 
 type CaptchaType string
@@ -29,6 +31,8 @@ const (
 	CaptchaTcaptcha  = CaptchaType("tcaptcha")
 	CaptchaTurnstile = CaptchaType("turnstile")
 	CaptchaCap       = CaptchaType("cap")
+	CaptchaHCaptcha  = CaptchaType("hcaptcha")
+	CaptchaGeeTest   = CaptchaType("geetest")
 )
 
 type ReCaptcha struct {
@@ -48,10 +52,22 @@ type Turnstile struct {
 	Secret string
 }
 
+type HCaptcha struct {
+	Key    string
+	Secret string
+}
+
+type GeeTest struct {
+	ID  string
+	Key string
+}
+
 type Cap struct {
 	InstanceURL string
 	SiteKey     string
 	SecretKey   string
+	// AssetServer is where client loads Cap widget and its WASM from, either a public CDN
+	// ("jsdelivr" or "unpkg") or base URL of a self-hosted asset server.
 	AssetServer string
 }
 
@@ -65,6 +81,68 @@ type SMTP struct {
 	ForceEncryption bool
 	Port            int
 	Keepalive       int
+	// MaxRetry is the max number of retries of a failed email, 0 to disable retry.
+	MaxRetry int
+	// RetryInterval is the base delay in seconds before retrying a failed email, doubled on each attempt.
+	RetryInterval int
+	// QueueSize is the buffer size of the sending queue.
+	QueueSize int
+	// Workers is the number of SMTP connections sending emails concurrently.
+	Workers int
+}
+
+// DKIM is the DKIM signing settings of outbound SMTP emails.
+type DKIM struct {
+	PrivateKey string
+	Domain     string
+	Selector   string
+}
+
+type MailDriver string
+
+const (
+	MailDriverSMTP     = MailDriver("smtp")
+	MailDriverMailgun  = MailDriver("mailgun")
+	MailDriverSendGrid = MailDriver("sendgrid")
+	MailDriverSES      = MailDriver("ses")
+)
+
+// MailAPI is the settings of HTTP API based email providers.
+type MailAPI struct {
+	Key string
+	// Domain is the sending domain, only used by Mailgun.
+	Domain string
+	// Endpoint overrides the default API base URL of the provider.
+	Endpoint string
+}
+
+// SES is the settings of Amazon SES email driver.
+type SES struct {
+	Region string
+	// AccessKey and SecretKey are optional, default AWS credential chain is used if not set.
+	AccessKey string
+	SecretKey string
+}
+
+type AccessLogFormat string
+
+const (
+	AccessLogFormatText = AccessLogFormat("text")
+	AccessLogFormatJSON = AccessLogFormat("json")
+)
+
+// AccessLog is the settings of structured access log.
+type AccessLog struct {
+	Enabled bool
+	Format  AccessLogFormat
+	// Fields are the fields included in each record.
+	Fields []string
+	// SampleRates maps path prefix to the ratio of requests being logged, requests not
+	// matching any prefix are always logged.
+	SampleRates map[string]float64
+	// SensitiveParams are query parameters removed from logged path, route parameters of the same
+	// names are masked.
+	SensitiveParams []string
 }
 
 type TokenAuth struct {
@@ -96,6 +174,15 @@ type ThumbEncode struct {
 	Format  string
 }
 
+// AnimatedThumb is the setting of animated video previews generated by ffmpeg.
+type AnimatedThumb struct {
+	Enabled bool
+	// Frames is the number of evenly-spaced frames in preview.
+	Frames int
+	// Format is the output format, "webp" or "gif".
+	Format string
+}
+
 var (
 	QueueTypeMediaMeta      = QueueType("media_meta")
 	QueueTypeIOIntense      = QueueType("io_intense")
@@ -108,11 +195,38 @@ var (
 type CronType string
 
 var (
-	CronTypeEntityCollect    = CronType("entity_collect")
-	CronTypeTrashBinCollect  = CronType("trash_bin_collect")
-	CronTypeOauthCredRefresh = CronType("oauth_cred_refresh")
+	CronTypeEntityCollect       = CronType("entity_collect")
+	CronTypeTrashBinCollect     = CronType("trash_bin_collect")
+	CronTypeOauthCredRefresh    = CronType("oauth_cred_refresh")
+	CronTypeSettingAuditCollect = CronType("setting_audit_collect")
+	// CronTypeCaptchaSessionCollect removes expired captcha sessions from KV drivers without
+	// native TTL eviction.
+	CronTypeCaptchaSessionCollect = CronType("captcha_session_collect")
 )
 
+// MediaMetaPairing describes how RAW/HEIC files are paired with their JPEG companions.
+type MediaMetaPairing struct {
+	Enabled       bool
+	PrimaryExts   []string
+	CompanionExts []string
+}
+
+// ArchiveConcurrency limits concurrent archive creation of background compress tasks, 0 for unlimited.
+// Streaming archive downloads are not limited.
+type ArchiveConcurrency struct {
+	Global  int
+	PerUser int
+}
+
+// OauthCredRefresh controls retry and alerting of periodic OAuth credential refresh.
+type OauthCredRefresh struct {
+	MaxRetry       int
+	RetryDelay     time.Duration
+	BackoffFactor  float64
+	AlertThreshold int
+	AlertMargin    time.Duration
+}
+
 type Theme struct {
 	Themes       string
 	DefaultTheme string
@@ -149,6 +263,8 @@ type Captcha struct {
 	IsShowSlimeLine    bool
 	IsShowSineLine     bool
 	Length             int
+	// AudioEnabled indicates whether audio companion of the captcha is available.
+	AudioEnabled bool
 }
 
 type ExplorerFrontendSettings struct {
@@ -163,6 +279,22 @@ const (
 	MapProviderMapbox        = MapProvider("mapbox")
 )
 
+type GeocodingProvider string
+
+const (
+	GeocodingProviderMapbox    = GeocodingProvider("mapbox")
+	GeocodingProviderNominatim = GeocodingProvider("nominatim")
+)
+
+// ChecksumAlgorithm is the hash algorithm of entity content checksums.
+type ChecksumAlgorithm string
+
+const (
+	ChecksumAlgorithmNone   = ChecksumAlgorithm("")
+	ChecksumAlgorithmMD5    = ChecksumAlgorithm("md5")
+	ChecksumAlgorithmSHA256 = ChecksumAlgorithm("sha256")
+)
+
 type MapGoogleTileType string
 
 const (
@@ -196,9 +328,11 @@ type AppSetting struct {
 }
 
 type EmailTemplate struct {
-	Title    string `json:"title"`
-	Body     string `json:"body"`
-	Language string `json:"language"`
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	// PlainBody is the optional plaintext alternative of Body, generated from Body if empty.
+	PlainBody string `json:"plain_body,omitempty"`
+	Language  string `json:"language"`
 }
 
 type Avatar struct {
@@ -0,0 +1,20 @@
+//go:build avif
+
+package thumb
+
+import (
+	"image"
+	"io"
+
+	"github.com/gen2brain/avif"
+)
+
+// AVIF support is only compiled in with the "avif" build tag, the encoder is backed by
+// libavif compiled to WASM, or the shared library if it is found at runtime.
+func init() {
+	avifEncoder = func(w io.Writer, img image.Image, quality int) error {
+		return avif.Encode(w, img, avif.Options{Quality: quality, QualityAlpha: quality, Speed: 8})
+	}
+	avifDecoder = avif.Decode
+	BuiltinSupportedExts = append(BuiltinSupportedExts, "avif")
+}
@@ -3,6 +3,7 @@ package thumb
 import (
 	"context"
 	"fmt"
+	"github.com/buckket/go-blurhash"
 	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/manager/entitysource"
 	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
 	"github.com/cloudreve/Cloudreve/v4/pkg/util"
@@ -13,16 +14,38 @@ import (
 	"image/png"
 	"io"
 	"path/filepath"
+	"sync"
 	//"github.com/nfnt/resize"
 	"golang.org/x/image/draw"
 )
 
-const thumbTempFolder = "thumb"
+const (
+	thumbTempFolder = "thumb"
+
+	// MetadataBlurhash is the metadata key of image blurhash.
+	MetadataBlurhash = "blurhash"
+	// MetadataDominantColor is the metadata key of image dominant color in hex format.
+	MetadataDominantColor = "dominant_color"
+
+	blurhashComponentsX = 4
+	blurhashComponentsY = 3
+	blurhashSampleSize  = 64
+
+	dominantColorSampleSize = 16
+)
 
 // BuiltinSupportedExts lists file extensions supported by the built-in
 // thumbnail generator. Extensions are lowercased and do not include the dot.
 var BuiltinSupportedExts = []string{"jpg", "jpeg", "png", "gif"}
 
+var (
+	// avifEncoder and avifDecoder are set if AVIF support is compiled in with the "avif" build tag.
+	avifEncoder func(w io.Writer, img image.Image, quality int) error
+	avifDecoder func(r io.Reader) (image.Image, error)
+
+	avifFallbackOnce sync.Once
+)
+
 // Thumb 缩略图
 type Thumb struct {
 	src image.Image
@@ -46,6 +69,11 @@ func NewThumbFromFile(file io.Reader, ext string) (*Thumb, error) {
 		img, err = gif.Decode(file)
 	case "png":
 		img, err = png.Decode(file)
+	case "avif":
+		if avifDecoder == nil {
+			return nil, fmt.Errorf("AVIF decoder is not compiled in: %w", ErrPassThrough)
+		}
+		img, err = avifDecoder(file)
 	default:
 		return nil, fmt.Errorf("unknown image format %q: %w", ext, ErrPassThrough)
 	}
@@ -73,7 +101,19 @@ func (image *Thumb) GetSize() (int, int) {
 
 // Save 保存图像到给定路径
 func (image *Thumb) Save(w io.Writer, encodeSetting *setting.ThumbEncode) (err error) {
-	switch encodeSetting.Format {
+	format := encodeSetting.Format
+	if format == "avif" {
+		if avifEncoder != nil {
+			return avifEncoder(w, image.src, encodeSetting.Quality)
+		}
+
+		avifFallbackOnce.Do(func() {
+			util.Log().Warning("AVIF encoder is not compiled in, thumbnails are encoded as jpg instead.")
+		})
+		format = "jpg"
+	}
+
+	switch format {
 	case "png":
 		err = png.Encode(w, image.src)
 	default:
@@ -126,6 +166,35 @@ func Resize(newWidth, newHeight uint, img image.Image) image.Image {
 	return dst
 }
 
+// Blurhash computes blurhash of the image, it is downsampled first for speed.
+func (image *Thumb) Blurhash() (string, error) {
+	return blurhash.Encode(blurhashComponentsX, blurhashComponentsY, Thumbnail(blurhashSampleSize, blurhashSampleSize, image.src))
+}
+
+// DominantColor returns the average color of the image in #rrggbb format, it is downsampled first for speed.
+func (image *Thumb) DominantColor() string {
+	sample := Thumbnail(dominantColorSampleSize, dominantColorSampleSize, image.src)
+	bounds := sample.Bounds()
+	var r, g, b, n uint64
+	for x := bounds.Min.X; x < bounds.Max.X; x++ {
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			pr, pg, pb, pa := sample.At(x, y).RGBA()
+			// Skip transparent pixels
+			if pa == 0 {
+				continue
+			}
+
+			r, g, b, n = r+uint64(pr>>8), g+uint64(pg>>8), b+uint64(pb>>8), n+1
+		}
+	}
+
+	if n == 0 {
+		return "#ffffff"
+	}
+
+	return fmt.Sprintf("#%02x%02x%02x", r/n, g/n, b/n)
+}
+
 // CreateAvatar 创建头像
 func (image *Thumb) CreateAvatar(width int) {
 	image.src = Resize(uint(width), uint(width), image.src)
@@ -153,6 +222,17 @@ func (b Builtin) Generate(ctx context.Context, es entitysource.EntitySource, ext
 
 	w, h := b.settings.ThumbSize(ctx)
 	img.GetThumb(uint(w), uint(h))
+
+	metadata := make(map[string]string)
+	if b.settings.ThumbBlurhashEnabled(ctx) {
+		if hash, err := img.Blurhash(); err == nil {
+			metadata[MetadataBlurhash] = hash
+		}
+	}
+
+	if b.settings.ThumbDominantColorEnabled(ctx) {
+		metadata[MetadataDominantColor] = img.DominantColor()
+	}
 	tempPath := filepath.Join(
 		util.DataPath(b.settings.TempPath(ctx)),
 		thumbTempFolder,
@@ -169,7 +249,7 @@ func (b Builtin) Generate(ctx context.Context, es entitysource.EntitySource, ext
 		return &Result{Path: tempPath}, err
 	}
 
-	return &Result{Path: tempPath}, nil
+	return &Result{Path: tempPath, Metadata: metadata}, nil
 }
 
 func (b Builtin) Priority() int {
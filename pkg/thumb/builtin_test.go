@@ -0,0 +1,59 @@
+package thumb
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+
+	"github.com/buckket/go-blurhash"
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThumb_SaveAvifFallback(t *testing.T) {
+	if avifEncoder != nil {
+		t.Skip("AVIF encoder is compiled in")
+	}
+
+	a := assert.New(t)
+	thumb := &Thumb{src: image.NewRGBA(image.Rect(0, 0, 4, 4))}
+
+	buf := &bytes.Buffer{}
+	a.NoError(thumb.Save(buf, &setting.ThumbEncode{Format: "avif", Quality: 80}))
+	_, err := jpeg.Decode(buf)
+	a.NoError(err)
+	a.NotContains(BuiltinSupportedExts, "avif")
+}
+
+func TestThumb_Blurhash(t *testing.T) {
+	a := assert.New(t)
+	src := image.NewRGBA(image.Rect(0, 0, 800, 600))
+	for x := 0; x < 800; x++ {
+		for y := 0; y < 600; y++ {
+			src.Set(x, y, color.RGBA{uint8(x / 4), uint8(y / 4), 128, 255})
+		}
+	}
+
+	hash, err := (&Thumb{src: src}).Blurhash()
+	a.NoError(err)
+	x, y, err := blurhash.Components(hash)
+	a.NoError(err)
+	a.Equal(blurhashComponentsX, x)
+	a.Equal(blurhashComponentsY, y)
+}
+
+func TestThumb_DominantColor(t *testing.T) {
+	a := assert.New(t)
+	src := image.NewNRGBA(image.Rect(0, 0, 400, 300))
+	for x := 0; x < 400; x++ {
+		for y := 0; y < 300; y++ {
+			src.Set(x, y, color.NRGBA{200, 100, 50, 255})
+		}
+	}
+	a.Equal("#c86432", (&Thumb{src: src}).DominantColor())
+
+	// Fully transparent image
+	a.Equal("#ffffff", (&Thumb{src: image.NewNRGBA(image.Rect(0, 0, 10, 10))}).DominantColor())
+}
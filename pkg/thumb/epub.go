@@ -0,0 +1,216 @@
+package thumb
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/manager/entitysource"
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
+	"github.com/cloudreve/Cloudreve/v4/pkg/util"
+	"github.com/gofrs/uuid"
+)
+
+const epubContainerPath = "META-INF/container.xml"
+
+var ErrEpubCoverNotFound = errors.New("no cover image found in EPUB")
+
+type (
+	epubContainer struct {
+		Rootfiles []struct {
+			FullPath  string `xml:"full-path,attr"`
+			MediaType string `xml:"media-type,attr"`
+		} `xml:"rootfiles>rootfile"`
+	}
+
+	epubPackage struct {
+		Metas []struct {
+			Name    string `xml:"name,attr"`
+			Content string `xml:"content,attr"`
+		} `xml:"metadata>meta"`
+		Items []epubManifestItem `xml:"manifest>item"`
+	}
+
+	epubManifestItem struct {
+		ID         string `xml:"id,attr"`
+		Href       string `xml:"href,attr"`
+		MediaType  string `xml:"media-type,attr"`
+		Properties string `xml:"properties,attr"`
+	}
+)
+
+func NewEpubGenerator(l logging.Logger, settings setting.Provider) *EpubGenerator {
+	return &EpubGenerator{l: l, settings: settings}
+}
+
+// EpubGenerator extracts the cover image declared in the OPF package document of EPUB files,
+// the image is then resized by following generators.
+type EpubGenerator struct {
+	l        logging.Logger
+	settings setting.Provider
+}
+
+func (e *EpubGenerator) Generate(ctx context.Context, es entitysource.EntitySource, ext string, previous *Result) (*Result, error) {
+	if ext != "epub" {
+		return nil, fmt.Errorf("unsupported e-book format: %w", ErrPassThrough)
+	}
+
+	maxSize := e.settings.EpubThumbMaxSize(ctx)
+	if es.Entity().Size() > maxSize {
+		return nil, fmt.Errorf("file is too big: %w", ErrPassThrough)
+	}
+
+	zr, err := zip.NewReader(es, es.Entity().Size())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open EPUB: %w", err)
+	}
+
+	cover, err := epubCover(zr)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cover is extracted as is, uncompressed size is capped by the same limit of EPUB file.
+	if cover.UncompressedSize64 > uint64(maxSize) {
+		return nil, fmt.Errorf("cover image is too big: %w", ErrPassThrough)
+	}
+
+	src, err := cover.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cover image: %w", err)
+	}
+	defer src.Close()
+
+	thumbExt := strings.TrimPrefix(path.Ext(cover.Name), ".")
+	if thumbExt == "" {
+		thumbExt = "jpg"
+	}
+
+	tempPath := filepath.Join(
+		util.DataPath(e.settings.TempPath(ctx)),
+		thumbTempFolder,
+		fmt.Sprintf("epub_%s.%s", uuid.Must(uuid.NewV4()).String(), thumbExt),
+	)
+
+	thumbFile, err := util.CreatNestedFile(tempPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+
+	defer thumbFile.Close()
+	if _, err := io.Copy(thumbFile, io.LimitReader(src, maxSize)); err != nil {
+		return &Result{Path: tempPath}, fmt.Errorf("failed to write cover to file: %w", err)
+	}
+
+	return &Result{
+		Path:     tempPath,
+		Continue: true,
+		Cleanup:  []func(){func() { _ = os.Remove(tempPath) }},
+	}, nil
+}
+
+func (e *EpubGenerator) Priority() int {
+	return 50
+}
+
+func (e *EpubGenerator) Enabled(ctx context.Context) bool {
+	return e.settings.EpubThumbGeneratorEnabled(ctx)
+}
+
+// epubCover locates the cover image in EPUB archive following the first rootfile in container.xml.
+// EPUB 3 "cover-image" manifest property takes precedence over EPUB 2 <meta name="cover">, manifest
+// items named as "cover" are used as a last resort.
+func epubCover(zr *zip.Reader) (*zip.File, error) {
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	var container epubContainer
+	if err := decodeEpubXml(files[epubContainerPath], &container); err != nil {
+		return nil, fmt.Errorf("failed to read container: %w", err)
+	}
+
+	if len(container.Rootfiles) == 0 {
+		return nil, fmt.Errorf("no rootfile found in container")
+	}
+
+	opfPath := container.Rootfiles[0].FullPath
+	var pkg epubPackage
+	if err := decodeEpubXml(files[opfPath], &pkg); err != nil {
+		return nil, fmt.Errorf("failed to read package document: %w", err)
+	}
+
+	item := pkg.coverItem()
+	if item == nil {
+		return nil, ErrEpubCoverNotFound
+	}
+
+	// Hrefs are URLs relative to the package document.
+	href, err := url.PathUnescape(item.Href)
+	if err != nil {
+		href = item.Href
+	}
+
+	cover, ok := files[path.Join(path.Dir(opfPath), href)]
+	if !ok {
+		return nil, fmt.Errorf("cover image %q not found in EPUB: %w", href, ErrEpubCoverNotFound)
+	}
+
+	return cover, nil
+}
+
+func (p *epubPackage) coverItem() *epubManifestItem {
+	isImage := func(item *epubManifestItem) bool {
+		return strings.HasPrefix(item.MediaType, "image/")
+	}
+
+	for i := range p.Items {
+		if isImage(&p.Items[i]) && util.ContainsString(strings.Fields(p.Items[i].Properties), "cover-image") {
+			return &p.Items[i]
+		}
+	}
+
+	for _, meta := range p.Metas {
+		if meta.Name != "cover" {
+			continue
+		}
+
+		for i := range p.Items {
+			if p.Items[i].ID == meta.Content && isImage(&p.Items[i]) {
+				return &p.Items[i]
+			}
+		}
+	}
+
+	for i := range p.Items {
+		if isImage(&p.Items[i]) && strings.Contains(strings.ToLower(p.Items[i].ID), "cover") {
+			return &p.Items[i]
+		}
+	}
+
+	return nil
+}
+
+func decodeEpubXml(f *zip.File, v any) error {
+	if f == nil {
+		return fmt.Errorf("file not found")
+	}
+
+	r, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	return xml.NewDecoder(r).Decode(v)
+}
@@ -0,0 +1,113 @@
+package thumb
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting/settingtest"
+	"github.com/stretchr/testify/assert"
+)
+
+const testEpubContainer = `<?xml version="1.0"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>`
+
+func buildEpub(t *testing.T, files map[string]string) []byte {
+	buf := &bytes.Buffer{}
+	w := zip.NewWriter(buf)
+	for name, content := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(content))
+	}
+	w.Close()
+	return buf.Bytes()
+}
+
+func newTestEpubGenerator(t *testing.T, maxSize string) *EpubGenerator {
+	settings := settingtest.NewProvider(map[string]any{
+		"thumb_epub_max_size": maxSize,
+		"temp_path":           t.TempDir(),
+	})
+	return NewEpubGenerator(logging.NewConsoleLogger(logging.LevelError), settings)
+}
+
+func TestEpubGenerator_Generate(t *testing.T) {
+	a := assert.New(t)
+	g := newTestEpubGenerator(t, "1048576")
+
+	// EPUB 3 cover-image property
+	epub := buildEpub(t, map[string]string{
+		"META-INF/container.xml": testEpubContainer,
+		"OEBPS/content.opf": `<package xmlns="http://www.idpf.org/2007/opf" version="3.0">
+  <manifest>
+    <item id="img" href="images/my%20cover.png" media-type="image/png" properties="cover-image"/>
+  </manifest>
+</package>`,
+		"OEBPS/images/my cover.png": "epub3",
+	})
+	res, err := g.Generate(context.Background(), newMemorySource(t, epub), "epub", nil)
+	a.NoError(err)
+	a.True(res.Continue)
+	a.True(strings.HasSuffix(res.Path, ".png"))
+	content, err := os.ReadFile(res.Path)
+	a.NoError(err)
+	a.Equal("epub3", string(content))
+
+	// EPUB 2 cover meta
+	epub = buildEpub(t, map[string]string{
+		"META-INF/container.xml": testEpubContainer,
+		"OEBPS/content.opf": `<package xmlns="http://www.idpf.org/2007/opf" version="2.0">
+  <metadata><meta name="cover" content="cover-img"/></metadata>
+  <manifest>
+    <item id="cover" href="cover.xhtml" media-type="application/xhtml+xml"/>
+    <item id="cover-img" href="../cover.jpg" media-type="image/jpeg"/>
+  </manifest>
+</package>`,
+		"cover.jpg": "epub2",
+	})
+	res, err = g.Generate(context.Background(), newMemorySource(t, epub), "epub", nil)
+	a.NoError(err)
+	content, err = os.ReadFile(res.Path)
+	a.NoError(err)
+	a.Equal("epub2", string(content))
+}
+
+func TestEpubGenerator_Unavailable(t *testing.T) {
+	a := assert.New(t)
+	g := newTestEpubGenerator(t, "1048576")
+
+	// Other formats are passed through
+	_, err := g.Generate(context.Background(), newMemorySource(t, []byte("pdf")), "pdf", nil)
+	a.ErrorIs(err, ErrPassThrough)
+
+	// No cover declared
+	epub := buildEpub(t, map[string]string{
+		"META-INF/container.xml": testEpubContainer,
+		"OEBPS/content.opf":      `<package><manifest><item id="c1" href="c1.xhtml" media-type="application/xhtml+xml"/></manifest></package>`,
+	})
+	res, err := g.Generate(context.Background(), newMemorySource(t, epub), "epub", nil)
+	a.ErrorIs(err, ErrEpubCoverNotFound)
+	a.Nil(res)
+
+	// Missing container
+	_, err = g.Generate(context.Background(), newMemorySource(t, buildEpub(t, map[string]string{"a.txt": "a"})), "epub", nil)
+	a.Error(err)
+	a.False(errors.Is(err, ErrPassThrough))
+
+	// File exceeding size limit
+	g = newTestEpubGenerator(t, "10")
+	_, err = g.Generate(context.Background(), newMemorySource(t, epub), "epub", nil)
+	a.ErrorIs(err, ErrPassThrough)
+}
@@ -4,8 +4,11 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -21,13 +24,14 @@ const (
 	urlTimeout = time.Duration(1) * time.Hour
 )
 
-func NewFfmpegGenerator(l logging.Logger, settings setting.Provider) *FfmpegGenerator {
-	return &FfmpegGenerator{l: l, settings: settings}
+func NewFfmpegGenerator(l logging.Logger, settings setting.Provider, limiter ProcessLimiter) *FfmpegGenerator {
+	return &FfmpegGenerator{l: l, settings: settings, limiter: limiter}
 }
 
 type FfmpegGenerator struct {
 	l        logging.Logger
 	settings setting.Provider
+	limiter  ProcessLimiter
 }
 
 func (f *FfmpegGenerator) Generate(ctx context.Context, es entitysource.EntitySource, ext string, previous *Result) (*Result, error) {
@@ -49,17 +53,9 @@ func (f *FfmpegGenerator) Generate(ctx context.Context, es entitysource.EntitySo
 		return nil, fmt.Errorf("failed to create temp folder: %w", err)
 	}
 
-	input := ""
-	expire := time.Now().Add(urlTimeout)
-	if es.IsLocal() {
-		input = es.LocalPath(ctx)
-	} else {
-		src, err := es.Url(driver.WithForcePublicEndpoint(ctx, false), entitysource.WithNoInternalProxy(), entitysource.WithContext(ctx), entitysource.WithExpire(&expire))
-		if err != nil {
-			return &Result{Path: tempOutputPath}, fmt.Errorf("failed to get entity url: %w", err)
-		}
-
-		input = src.Url
+	input, err := f.input(ctx, es)
+	if err != nil {
+		return &Result{Path: tempOutputPath}, err
 	}
 
 	// Invoke ffmpeg
@@ -80,18 +76,137 @@ func (f *FfmpegGenerator) Generate(ctx context.Context, es entitysource.EntitySo
 		"-vframes", "1",
 		tempOutputPath,
 	}...)
+	if err := f.run(ctx, args...); err != nil {
+		return &Result{Path: tempOutputPath}, err
+	}
+
+	return &Result{Path: tempOutputPath}, nil
+}
+
+// GenerateAnimated generates a looping animated preview from evenly-spaced frames of the video. Unlike
+// Generate, it is not part of the generator pipeline and only invoked on demand.
+func (f *FfmpegGenerator) GenerateAnimated(ctx context.Context, es entitysource.EntitySource, ext string) (*Result, error) {
+	if !util.IsInExtensionListExt(f.settings.FFMpegThumbExts(ctx), ext) {
+		return nil, fmt.Errorf("unsupported video format: %w", ErrPassThrough)
+	}
+
+	if es.Entity().Size() > f.settings.FFMpegThumbMaxSize(ctx) {
+		return nil, fmt.Errorf("file is too big: %w", ErrPassThrough)
+	}
+
+	animated := f.settings.FFMpegAnimatedThumb(ctx)
+	tempFolder := filepath.Join(
+		util.DataPath(f.settings.TempPath(ctx)),
+		thumbTempFolder,
+		fmt.Sprintf("animated_%s", uuid.Must(uuid.NewV4()).String()),
+	)
+	if err := util.CreatNestedFolder(tempFolder); err != nil {
+		return nil, fmt.Errorf("failed to create temp folder: %w", err)
+	}
+
+	res := &Result{
+		Path:    filepath.Join(tempFolder, "animated."+animated.Format),
+		Cleanup: []func(){func() { _ = os.RemoveAll(tempFolder) }},
+	}
+
+	input, err := f.input(ctx, es)
+	if err != nil {
+		return res, err
+	}
+
+	duration, err := f.probeDuration(ctx, input)
+	if err != nil {
+		return res, err
+	}
+
+	// Grab frames one by one with fast input seeking, so that the whole video is not decoded.
+	w, h := f.settings.ThumbSize(ctx)
+	scaleOpt := fmt.Sprintf("scale=%d:%d:force_original_aspect_ratio=decrease", w, h)
+	for i := 0; i < animated.Frames; i++ {
+		seek := duration * (float64(i) + 0.5) / float64(animated.Frames)
+		args := []string{"-ss", strconv.FormatFloat(seek, 'f', 2, 64)}
+		if extraArgs := f.settings.FFMpegExtraArgs(ctx); extraArgs != "" {
+			args = append(args, strings.Split(extraArgs, " ")...)
+		}
+
+		args = append(args, "-i", input, "-vf", scaleOpt, "-vframes", "1",
+			filepath.Join(tempFolder, fmt.Sprintf("frame_%03d.png", i)))
+		if err := f.run(ctx, args...); err != nil {
+			return res, err
+		}
+	}
+
+	args := []string{"-framerate", "2", "-i", filepath.Join(tempFolder, "frame_%03d.png"), "-loop", "0"}
+	if animated.Format == "gif" {
+		args = append(args, "-filter_complex", "split[a][b];[a]palettegen[p];[b][p]paletteuse")
+	}
+
+	args = append(args, res.Path)
+	if err := f.run(ctx, args...); err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+// input returns the ffmpeg input of given entity source, local path or a signed URL.
+func (f *FfmpegGenerator) input(ctx context.Context, es entitysource.EntitySource) (string, error) {
+	if es.IsLocal() {
+		return es.LocalPath(ctx), nil
+	}
+
+	expire := time.Now().Add(urlTimeout)
+	src, err := es.Url(driver.WithForcePublicEndpoint(ctx, false), entitysource.WithNoInternalProxy(), entitysource.WithContext(ctx), entitysource.WithExpire(&expire))
+	if err != nil {
+		return "", fmt.Errorf("failed to get entity url: %w", err)
+	}
+
+	return src.Url, nil
+}
+
+func (f *FfmpegGenerator) run(ctx context.Context, args ...string) error {
 	cmd := exec.CommandContext(ctx, f.settings.FFMpegPath(ctx), args...)
 
 	// Redirect IO
 	var stdErr bytes.Buffer
 	cmd.Stderr = &stdErr
 
-	if err := cmd.Run(); err != nil {
+	if err := runExternal(ctx, f.settings, f.limiter, cmd); err != nil {
 		f.l.Warning("Failed to invoke ffmpeg: %s", stdErr.String())
-		return &Result{Path: tempOutputPath}, fmt.Errorf("failed to invoke ffmpeg: %w, raw output: %s", err, stdErr.String())
+		return fmt.Errorf("failed to invoke ffmpeg: %w, raw output: %s", err, stdErr.String())
 	}
 
-	return &Result{Path: tempOutputPath}, nil
+	return nil
+}
+
+// probeDuration returns duration of input in seconds, parsed from ffmpeg's stream info output.
+func (f *FfmpegGenerator) probeDuration(ctx context.Context, input string) (float64, error) {
+	cmd := exec.CommandContext(ctx, f.settings.FFMpegPath(ctx), "-hide_banner", "-i", input)
+	var stdErr bytes.Buffer
+	cmd.Stderr = &stdErr
+
+	// ffmpeg exits with error as no output is specified
+	_ = runExternal(ctx, f.settings, f.limiter, cmd)
+	return parseFfmpegDuration(stdErr.String())
+}
+
+var ffmpegDurationReg = regexp.MustCompile(`Duration: (\d+):(\d{2}):(\d{2}(?:\.\d+)?)`)
+
+func parseFfmpegDuration(output string) (float64, error) {
+	match := ffmpegDurationReg.FindStringSubmatch(output)
+	if match == nil {
+		return 0, fmt.Errorf("duration not found in ffmpeg output: %s", output)
+	}
+
+	hours, _ := strconv.Atoi(match[1])
+	minutes, _ := strconv.Atoi(match[2])
+	seconds, _ := strconv.ParseFloat(match[3], 64)
+	duration := float64(hours*3600+minutes*60) + seconds
+	if duration <= 0 {
+		return 0, fmt.Errorf("invalid duration %q", match[0])
+	}
+
+	return duration, nil
 }
 
 func (f *FfmpegGenerator) Priority() int {
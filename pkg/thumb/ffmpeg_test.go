@@ -0,0 +1,25 @@
+package thumb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFfmpegDuration(t *testing.T) {
+	a := assert.New(t)
+	output := `Input #0, mov,mp4,m4a,3gp,3g2,mj2, from 'video.mp4':
+  Duration: 01:02:03.50, start: 0.000000, bitrate: 1205 kb/s
+  Stream #0:0[0x1](und): Video: h264 (High) (avc1 / 0x31637661), yuv420p, 1920x1080, 30 fps
+At least one output file must be specified`
+
+	duration, err := parseFfmpegDuration(output)
+	a.NoError(err)
+	a.Equal(3723.5, duration)
+
+	// Live streams or broken files have no duration
+	_, err = parseFfmpegDuration("  Duration: N/A, start: 0.000000, bitrate: N/A")
+	a.Error(err)
+	_, err = parseFfmpegDuration("  Duration: 00:00:00.00, start: 0.000000")
+	a.Error(err)
+}
@@ -20,13 +20,14 @@ import (
 	"github.com/gofrs/uuid"
 )
 
-func NewLibRawGenerator(l logging.Logger, settings setting.Provider) *LibRawGenerator {
-	return &LibRawGenerator{l: l, settings: settings}
+func NewLibRawGenerator(l logging.Logger, settings setting.Provider, limiter ProcessLimiter) *LibRawGenerator {
+	return &LibRawGenerator{l: l, settings: settings, limiter: limiter}
 }
 
 type LibRawGenerator struct {
 	l        logging.Logger
 	settings setting.Provider
+	limiter  ProcessLimiter
 }
 
 func (l *LibRawGenerator) Generate(ctx context.Context, es entitysource.EntitySource, ext string, previous *Result) (*Result, error) {
@@ -54,29 +55,54 @@ func (l *LibRawGenerator) Generate(ctx context.Context, es entitysource.EntitySo
 	defer os.Remove(tempPath)
 	defer tempInputFile.Close()
 
+	succeed := false
+	defer func() {
+		if !succeed {
+			_ = os.RemoveAll(tempFolder)
+		}
+	}()
+
 	if _, err = io.Copy(tempInputFile, es); err != nil {
 		return &Result{Path: tempPath}, fmt.Errorf("failed to write input file: %w", err)
 	}
 
 	tempInputFile.Close()
 
-	cmd := exec.CommandContext(ctx,
-		l.settings.LibRawThumbPath(ctx), "-e", tempPath)
+	// Prefer embedded JPEG preview, which is much faster than extracting with dcraw.
+	thumbPath := filepath.Join(tempFolder, tempInputFileName+".thumb.jpg")
+	w, h := l.settings.ThumbSize(ctx)
+	rawOrientation, err := extractEmbeddedPreview(tempPath, thumbPath, w, h)
+	if err != nil {
+		l.l.Debug("No suitable embedded preview found, fallback to dcraw: %s", err)
+		cmd := exec.CommandContext(ctx,
+			l.settings.LibRawThumbPath(ctx), "-e", tempPath)
 
-	// Redirect IO
-	var dcrawErr bytes.Buffer
-	cmd.Stderr = &dcrawErr
+		// Redirect IO
+		var dcrawErr bytes.Buffer
+		cmd.Stderr = &dcrawErr
 
-	if err := cmd.Run(); err != nil {
-		l.l.Warning("Failed to invoke dcraw: %s", dcrawErr.String())
-		return &Result{Path: tempPath}, fmt.Errorf("failed to invoke dcraw: %w, raw output: %s", err, dcrawErr.String())
+		if err := runExternal(ctx, l.settings, l.limiter, cmd); err != nil {
+			l.l.Warning("Failed to invoke dcraw: %s", dcrawErr.String())
+			return &Result{Path: tempPath}, fmt.Errorf("failed to invoke dcraw: %w, raw output: %s", err, dcrawErr.String())
+		}
 	}
 
+	// Embedded previews are stored as is, apply EXIF orientation so that thumbnails are upright.
+	// Orientation of RAW file is used if preview does not have its own.
+	orientation, err := getJpegOrientation(thumbPath)
+	if (err != nil || orientation <= 1) && rawOrientation > 1 {
+		orientation = rawOrientation
+	}
+
+	if orientation > 1 {
+		if err := rotateImg(thumbPath, orientation); err != nil {
+			l.l.Warning("Failed to rotate thumbnail with orientation %d: %s", orientation, err)
+		}
+	}
+
+	succeed = true
 	return &Result{
-		Path: filepath.Join(
-			tempFolder,
-			tempInputFileName+".thumb.jpg",
-		),
+		Path:     thumbPath,
 		Continue: true,
 		Cleanup:  []func(){func() { _ = os.RemoveAll(tempFolder) }},
 	}, nil
@@ -90,6 +116,185 @@ func (l *LibRawGenerator) Enabled(ctx context.Context) bool {
 	return l.settings.LibRawThumbGeneratorEnabled(ctx)
 }
 
+// extractEmbeddedPreview finds the largest JPEG preview embedded in TIFF based RAW file, and writes it
+// to dst. Previews smaller than given size are ignored. Orientation in IFD0 of the RAW file is returned.
+func extractEmbeddedPreview(rawPath, dst string, minWidth, minHeight int) (int, error) {
+	f, err := os.Open(rawPath)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	previews, orientation, err := parseRawPreviews(f, stat.Size())
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		best     *io.SectionReader
+		bestArea int
+	)
+	for _, p := range previews {
+		preview := io.NewSectionReader(f, p.offset, p.length)
+		cfg, err := jpeg.DecodeConfig(preview)
+		if err != nil {
+			continue
+		}
+
+		if (cfg.Width < minWidth && cfg.Height < minHeight) || cfg.Width*cfg.Height <= bestArea {
+			continue
+		}
+
+		best, bestArea = io.NewSectionReader(f, p.offset, p.length), cfg.Width*cfg.Height
+	}
+
+	if best == nil {
+		return 0, errors.New("no suitable embedded preview")
+	}
+
+	out, err := util.CreatNestedFile(dst)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create preview file: %w", err)
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, best); err != nil {
+		_ = out.Close()
+		_ = os.Remove(dst)
+		return 0, fmt.Errorf("failed to write preview file: %w", err)
+	}
+
+	return orientation, nil
+}
+
+type rawPreview struct {
+	offset int64
+	length int64
+}
+
+// parseRawPreviews walks IFDs of TIFF based RAW file, returns locations of JPEG previews
+// and orientation in IFD0.
+func parseRawPreviews(r io.ReaderAt, size int64) ([]rawPreview, int, error) {
+	header := make([]byte, 8)
+	if _, err := r.ReadAt(header, 0); err != nil {
+		return nil, 0, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	parse16, parse32, err := initParseMethod(header[:2])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	read16 := func(b []byte) uint32 { return uint32(uint16(parse16(b))) }
+	read32 := func(b []byte) uint32 { return uint32(parse32(b)) }
+	if read16(header[2:4]) != 42 {
+		return nil, 0, errors.New("not a TIFF based RAW file")
+	}
+
+	const (
+		maxIFDs        = 64
+		tagOrientation = 0x112
+		tagCompression = 0x103
+		tagStripOffset = 0x111
+		tagStripBytes  = 0x117
+		tagSubIFDs     = 0x14A
+		tagJpegOffset  = 0x201
+		tagJpegLength  = 0x202
+		typeShort      = 3
+	)
+
+	var (
+		previews    []rawPreview
+		orientation int
+		visited     = make(map[uint32]bool)
+		queue       = []uint32{read32(header[4:8])}
+	)
+
+	addPreview := func(offset, length uint32) {
+		if length > 0 && int64(offset)+int64(length) <= size {
+			previews = append(previews, rawPreview{offset: int64(offset), length: int64(length)})
+		}
+	}
+
+	for len(queue) > 0 && len(visited) < maxIFDs {
+		offset := queue[0]
+		queue = queue[1:]
+		if offset == 0 || visited[offset] || int64(offset)+2 > size {
+			continue
+		}
+		visited[offset] = true
+
+		countBuf := make([]byte, 2)
+		if _, err := r.ReadAt(countBuf, int64(offset)); err != nil {
+			continue
+		}
+
+		count := read16(countBuf)
+		entries := make([]byte, count*12+4)
+		if _, err := r.ReadAt(entries, int64(offset)+2); err != nil {
+			continue
+		}
+
+		var jpegOffset, jpegLength, compression, stripOffset, stripBytes uint32
+		for e := uint32(0); e < count; e++ {
+			entry := entries[e*12 : e*12+12]
+			tag, typ, n := read16(entry[0:2]), read16(entry[2:4]), read32(entry[4:8])
+			value := read32(entry[8:12])
+			if typ == typeShort {
+				value = read16(entry[8:10])
+			}
+
+			switch tag {
+			case tagOrientation:
+				if len(visited) == 1 {
+					orientation = int(value)
+				}
+			case tagCompression:
+				compression = value
+			case tagStripOffset:
+				if n == 1 {
+					stripOffset = value
+				}
+			case tagStripBytes:
+				if n == 1 {
+					stripBytes = value
+				}
+			case tagJpegOffset:
+				jpegOffset = value
+			case tagJpegLength:
+				jpegLength = value
+			case tagSubIFDs:
+				if n == 1 {
+					queue = append(queue, value)
+				} else if n <= maxIFDs {
+					subIFDs := make([]byte, n*4)
+					if _, err := r.ReadAt(subIFDs, int64(value)); err == nil {
+						for s := uint32(0); s < n; s++ {
+							queue = append(queue, read32(subIFDs[s*4:s*4+4]))
+						}
+					}
+				}
+			}
+		}
+
+		addPreview(jpegOffset, jpegLength)
+
+		// JPEG compressed image stored in single strip
+		if compression == 6 || compression == 7 {
+			addPreview(stripOffset, stripBytes)
+		}
+
+		queue = append(queue, read32(entries[count*12:]))
+	}
+
+	return previews, orientation, nil
+}
+
 func rotateImg(filePath string, orientation int) error {
 	resultImg, err := os.OpenFile(filePath, os.O_RDWR, 0777)
 	if err != nil {
@@ -115,6 +320,22 @@ func rotateImg(filePath string, orientation int) error {
 		return err
 	}
 
+	img = orientImg(img, orientation)
+	if err = resultImg.Truncate(0); err != nil {
+		return err
+	}
+	if _, err = resultImg.Seek(0, 0); err != nil {
+		return err
+	}
+
+	if bytes.Equal(imgFlag, []byte{0xFF, 0xD8, 0xFF}) {
+		return jpeg.Encode(resultImg, img, nil)
+	}
+	return png.Encode(resultImg, img)
+}
+
+// orientImg transforms img stored with given EXIF orientation to be displayed upright.
+func orientImg(img image.Image, orientation int) image.Image {
 	switch orientation {
 	case 8:
 		img = rotate90(img)
@@ -125,24 +346,14 @@ func rotateImg(filePath string, orientation int) error {
 	case 2:
 		img = mirrorImg(img)
 	case 7:
-		img = rotate90(mirrorImg(img))
+		img = rotate90(rotate90(rotate90(mirrorImg(img))))
 	case 4:
 		img = rotate90(rotate90(mirrorImg(img)))
 	case 5:
-		img = rotate90(rotate90(rotate90(mirrorImg(img))))
-	}
-
-	if err = resultImg.Truncate(0); err != nil {
-		return err
-	}
-	if _, err = resultImg.Seek(0, 0); err != nil {
-		return err
+		img = rotate90(mirrorImg(img))
 	}
 
-	if bytes.Equal(imgFlag, []byte{0xFF, 0xD8, 0xFF}) {
-		return jpeg.Encode(resultImg, img, nil)
-	}
-	return png.Encode(resultImg, img)
+	return img
 }
 
 func getJpegOrientation(fileName string) (int, error) {
@@ -170,6 +381,10 @@ func getJpegOrientation(fileName string) (int, error) {
 
 	// exif data total length
 	totalLen := int(header[4])<<8 + int(header[5]) - 2
+	if totalLen < 14 {
+		return 0, errors.New("invalid exif length")
+	}
+
 	buf := make([]byte, totalLen)
 	defer func() { buf = nil }()
 	if _, err = io.ReadFull(f, buf); err != nil {
@@ -193,6 +408,9 @@ func getJpegOrientation(fileName string) (int, error) {
 
 	// first DE offset
 	offset += 2
+	if offset < 8 || int(offset) > len(buf) {
+		return 0, errors.New("invalid IFD offset")
+	}
 	buf = buf[offset:]
 
 	const (
@@ -202,7 +420,8 @@ func getJpegOrientation(fileName string) (int, error) {
 	for len(buf) > deEntryLength {
 		tag := parse16(buf[:2])
 		if tag == orientationTag {
-			return int(parse32(buf[8:12])), nil
+			// Orientation is a SHORT value, stored in the first 2 bytes of value field
+			return int(parse16(buf[8:10])), nil
 		}
 		buf = buf[deEntryLength:]
 	}
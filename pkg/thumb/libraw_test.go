@@ -0,0 +1,176 @@
+package thumb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetJpegOrientation(t *testing.T) {
+	a := assert.New(t)
+
+	// Fixture is a 32x16 image with big-endian EXIF orientation 6
+	orientation, err := getJpegOrientation("testdata/orientation_6.jpg")
+	a.NoError(err)
+	a.Equal(6, orientation)
+
+	// JPEG without EXIF
+	plain := filepath.Join(t.TempDir(), "plain.jpg")
+	f, err := os.Create(plain)
+	a.NoError(err)
+	a.NoError(jpeg.Encode(f, image.NewRGBA(image.Rect(0, 0, 4, 4)), nil))
+	f.Close()
+	orientation, err = getJpegOrientation(plain)
+	a.NoError(err)
+	a.Equal(1, orientation)
+
+	// Not a JPEG
+	notJpeg := filepath.Join(t.TempDir(), "not.jpg")
+	a.NoError(os.WriteFile(notJpeg, []byte("not a jpeg"), 0644))
+	_, err = getJpegOrientation(notJpeg)
+	a.Error(err)
+}
+
+func TestRotateImg(t *testing.T) {
+	a := assert.New(t)
+	fixture, err := os.ReadFile("testdata/orientation_6.jpg")
+	a.NoError(err)
+	thumbPath := filepath.Join(t.TempDir(), "raw.thumb.jpg")
+	a.NoError(os.WriteFile(thumbPath, fixture, 0644))
+
+	a.NoError(rotateImg(thumbPath, 6))
+
+	f, err := os.Open(thumbPath)
+	a.NoError(err)
+	defer f.Close()
+	img, err := jpeg.Decode(f)
+	a.NoError(err)
+
+	// Red block at top-left is rotated clockwise to top-right
+	a.Equal(image.Rect(0, 0, 16, 32), img.Bounds())
+	r, _, b, _ := img.At(12, 4).RGBA()
+	a.Greater(r, b)
+	r, _, b, _ = img.At(4, 4).RGBA()
+	a.Greater(b, r)
+
+	// EXIF is dropped after rotation
+	orientation, err := getJpegOrientation(thumbPath)
+	a.NoError(err)
+	a.Equal(1, orientation)
+}
+
+func TestOrientImg(t *testing.T) {
+	const w, h = 3, 2
+	src := image.NewRGBA(image.Rect(0, 0, w, h))
+	for x := 0; x < w; x++ {
+		for y := 0; y < h; y++ {
+			src.Set(x, y, color.RGBA{uint8(x), uint8(y), 0, 255})
+		}
+	}
+
+	// Position of stored pixel (x, y) when displayed upright
+	cases := map[int]func(x, y int) (int, int){
+		1: func(x, y int) (int, int) { return x, y },
+		2: func(x, y int) (int, int) { return w - 1 - x, y },
+		3: func(x, y int) (int, int) { return w - 1 - x, h - 1 - y },
+		4: func(x, y int) (int, int) { return x, h - 1 - y },
+		5: func(x, y int) (int, int) { return y, x },
+		6: func(x, y int) (int, int) { return h - 1 - y, x },
+		7: func(x, y int) (int, int) { return h - 1 - y, w - 1 - x },
+		8: func(x, y int) (int, int) { return y, w - 1 - x },
+	}
+
+	for orientation, mapping := range cases {
+		res := orientImg(src, orientation)
+		for x := 0; x < w; x++ {
+			for y := 0; y < h; y++ {
+				dx, dy := mapping(x, y)
+				assert.Equal(t, src.At(x, y), color.RGBAModel.Convert(res.At(dx, dy)), "orientation %d, pixel (%d, %d)", orientation, x, y)
+			}
+		}
+	}
+}
+
+// buildTestRaw builds a little-endian TIFF with a small preview in IFD0 and a large one in SubIFD.
+func buildTestRaw(t *testing.T) ([]byte, []byte) {
+	encode := func(w, h int) []byte {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h)), nil); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+	small, large := encode(16, 8), encode(64, 32)
+
+	type entry struct {
+		tag, typ uint16
+		value    uint32
+	}
+	ifd := func(entries ...entry) []byte {
+		var buf bytes.Buffer
+		_ = binary.Write(&buf, binary.LittleEndian, uint16(len(entries)))
+		for _, e := range entries {
+			_ = binary.Write(&buf, binary.LittleEndian, e.tag)
+			_ = binary.Write(&buf, binary.LittleEndian, e.typ)
+			_ = binary.Write(&buf, binary.LittleEndian, uint32(1))
+			_ = binary.Write(&buf, binary.LittleEndian, e.value)
+		}
+		_ = binary.Write(&buf, binary.LittleEndian, uint32(0))
+		return buf.Bytes()
+	}
+
+	const ifd0Offset, subIFDOffset = 8, 8 + 2 + 4*12 + 4
+	smallOffset := uint32(subIFDOffset + 2 + 3*12 + 4)
+	largeOffset := smallOffset + uint32(len(small))
+
+	raw := []byte{'I', 'I', 42, 0, ifd0Offset, 0, 0, 0}
+	raw = append(raw, ifd(
+		entry{0x112, 3, 6},
+		entry{0x14A, 4, subIFDOffset},
+		entry{0x201, 4, smallOffset},
+		entry{0x202, 4, uint32(len(small))},
+	)...)
+	raw = append(raw, ifd(
+		entry{0x103, 3, 7},
+		entry{0x111, 4, largeOffset},
+		entry{0x117, 4, uint32(len(large))},
+	)...)
+	raw = append(raw, small...)
+	raw = append(raw, large...)
+	return raw, large
+}
+
+func TestExtractEmbeddedPreview(t *testing.T) {
+	a := assert.New(t)
+	raw, large := buildTestRaw(t)
+	rawPath := filepath.Join(t.TempDir(), "test.nef")
+	a.NoError(os.WriteFile(rawPath, raw, 0644))
+
+	// Largest preview is picked
+	dst := filepath.Join(t.TempDir(), "preview.jpg")
+	orientation, err := extractEmbeddedPreview(rawPath, dst, 32, 32)
+	a.NoError(err)
+	a.Equal(6, orientation)
+	content, err := os.ReadFile(dst)
+	a.NoError(err)
+	a.Equal(large, content)
+
+	// No preview is large enough
+	dst = filepath.Join(t.TempDir(), "preview.jpg")
+	_, err = extractEmbeddedPreview(rawPath, dst, 128, 128)
+	a.Error(err)
+	a.NoFileExists(dst)
+
+	// Not a TIFF based RAW
+	notRaw := filepath.Join(t.TempDir(), "test.cr3")
+	a.NoError(os.WriteFile(notRaw, []byte("not a raw file"), 0644))
+	_, err = extractEmbeddedPreview(notRaw, dst, 1, 1)
+	a.Error(err)
+}
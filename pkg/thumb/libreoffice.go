@@ -17,13 +17,14 @@ import (
 	"github.com/gofrs/uuid"
 )
 
-func NewLibreOfficeGenerator(l logging.Logger, settings setting.Provider) *LibreOfficeGenerator {
-	return &LibreOfficeGenerator{l: l, settings: settings}
+func NewLibreOfficeGenerator(l logging.Logger, settings setting.Provider, limiter ProcessLimiter) *LibreOfficeGenerator {
+	return &LibreOfficeGenerator{l: l, settings: settings, limiter: limiter}
 }
 
 type LibreOfficeGenerator struct {
 	settings setting.Provider
 	l        logging.Logger
+	limiter  ProcessLimiter
 }
 
 func (l *LibreOfficeGenerator) Generate(ctx context.Context, es entitysource.EntitySource, ext string, previous *Result) (*Result, error) {
@@ -77,7 +78,7 @@ func (l *LibreOfficeGenerator) Generate(ctx context.Context, es entitysource.Ent
 	var stdErr bytes.Buffer
 	cmd.Stderr = &stdErr
 
-	if err := cmd.Run(); err != nil {
+	if err := runExternal(ctx, l.settings, l.limiter, cmd); err != nil {
 		l.l.Warning("Failed to invoke LibreOffice: %s", stdErr.String())
 		return &Result{Path: tempOutputPath}, fmt.Errorf("failed to invoke LibreOffice: %w, raw output: %s", err, stdErr.String())
 	}
@@ -0,0 +1,69 @@
+package thumb
+
+import (
+	"context"
+	"os/exec"
+	"sync"
+
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
+)
+
+type (
+	// ProcessLimiter limits concurrent external processes spawned by thumbnail generators on this node,
+	// independently of the thumbnail queue worker count.
+	ProcessLimiter interface {
+		// Run calls fn once less than limit calls are running, limit <= 0 means no limit.
+		Run(ctx context.Context, limit int, fn func() error) error
+	}
+
+	// processLimiter is a semaphore whose capacity can be changed at runtime.
+	processLimiter struct {
+		mu       sync.Mutex
+		running  int
+		released chan struct{}
+	}
+)
+
+// NewProcessLimiter creates a new ProcessLimiter, it should be shared by all generators on this node.
+func NewProcessLimiter() ProcessLimiter {
+	return newProcessLimiter()
+}
+
+func newProcessLimiter() *processLimiter {
+	return &processLimiter{released: make(chan struct{})}
+}
+
+func (p *processLimiter) Run(ctx context.Context, limit int, fn func() error) error {
+	for {
+		p.mu.Lock()
+		if limit <= 0 || p.running < limit {
+			p.running++
+			p.mu.Unlock()
+			break
+		}
+
+		released := p.released
+		p.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-released:
+		}
+	}
+
+	defer func() {
+		p.mu.Lock()
+		p.running--
+		close(p.released)
+		p.released = make(chan struct{})
+		p.mu.Unlock()
+	}()
+
+	return fn()
+}
+
+// runExternal runs cmd within the concurrency limit of external thumbnail generators.
+func runExternal(ctx context.Context, settings setting.Provider, limiter ProcessLimiter, cmd *exec.Cmd) error {
+	return limiter.Run(ctx, settings.ThumbExternalConcurrency(ctx), cmd.Run)
+}
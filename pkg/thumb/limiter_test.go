@@ -0,0 +1,74 @@
+package thumb
+
+import (
+	"context"
+	"os/exec"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessLimiter_BoundsExec(t *testing.T) {
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep is not available")
+	}
+
+	a := assert.New(t)
+	limiter := newProcessLimiter()
+
+	var running, maxRunning int32
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := limiter.Run(context.Background(), 2, func() error {
+				current := atomic.AddInt32(&running, 1)
+				defer atomic.AddInt32(&running, -1)
+				for {
+					old := atomic.LoadInt32(&maxRunning)
+					if current <= old || atomic.CompareAndSwapInt32(&maxRunning, old, current) {
+						break
+					}
+				}
+
+				return exec.Command("sleep", "0.1").Run()
+			})
+			a.NoError(err)
+		}()
+	}
+
+	wg.Wait()
+	a.EqualValues(2, maxRunning)
+}
+
+func TestProcessLimiter_ContextCanceled(t *testing.T) {
+	a := assert.New(t)
+	limiter := newProcessLimiter()
+
+	hold := make(chan struct{})
+	started := make(chan struct{})
+	go limiter.Run(context.Background(), 1, func() error {
+		close(started)
+		<-hold
+		return nil
+	})
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	called := false
+	err := limiter.Run(ctx, 1, func() error {
+		called = true
+		return nil
+	})
+	a.ErrorIs(err, context.DeadlineExceeded)
+	a.False(called)
+
+	// No limit
+	a.NoError(limiter.Run(context.Background(), 0, func() error { return nil }))
+	close(hold)
+}
@@ -3,14 +3,24 @@ package thumb
 import (
 	"context"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
 	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/manager/entitysource"
 	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
 	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
 	"github.com/cloudreve/Cloudreve/v4/pkg/util"
 	"github.com/dhowden/tag"
 	"github.com/gofrs/uuid"
-	"os"
-	"path/filepath"
+)
+
+type (
+	// SiblingOpener opens a file with given name in the same folder of the file being processed.
+	SiblingOpener func(ctx context.Context, name string) (entitysource.EntitySource, error)
+	// SiblingOpenerCtx is the context key of SiblingOpener.
+	SiblingOpenerCtx struct{}
 )
 
 func NewMusicCoverGenerator(l logging.Logger, settings setting.Provider) *MusicCoverGenerator {
@@ -33,12 +43,13 @@ func (v *MusicCoverGenerator) Generate(ctx context.Context, es entitysource.Enti
 
 	m, err := tag.ReadFrom(es)
 	if err != nil {
-		return nil, fmt.Errorf("faield to read audio tags from file: %w", err)
+		v.l.Debug("Failed to read audio tags from file: %s, trying cover in folder...", err)
+		return v.folderCover(ctx, fmt.Errorf("faield to read audio tags from file: %w", err))
 	}
 
 	p := m.Picture()
 	if p == nil || len(p.Data) == 0 {
-		return nil, fmt.Errorf("no cover found in given file")
+		return v.folderCover(ctx, fmt.Errorf("no cover found in given file"))
 	}
 
 	thumbExt := ".jpg"
@@ -46,15 +57,9 @@ func (v *MusicCoverGenerator) Generate(ctx context.Context, es entitysource.Enti
 		thumbExt = p.Ext
 	}
 
-	tempPath := filepath.Join(
-		util.DataPath(v.settings.TempPath(ctx)),
-		thumbTempFolder,
-		fmt.Sprintf("thumb_%s.%s", uuid.Must(uuid.NewV4()).String(), thumbExt),
-	)
-
-	thumbFile, err := util.CreatNestedFile(tempPath)
+	tempPath, thumbFile, err := v.createTempFile(ctx, thumbExt)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create temp file: %w", err)
+		return nil, err
 	}
 
 	defer thumbFile.Close()
@@ -70,6 +75,78 @@ func (v *MusicCoverGenerator) Generate(ctx context.Context, es entitysource.Enti
 	}, nil
 }
 
+// folderCover looks for a conventional cover image in the folder of the track, cause is returned
+// if none of the candidates is found.
+func (v *MusicCoverGenerator) folderCover(ctx context.Context, cause error) (*Result, error) {
+	opener, ok := ctx.Value(SiblingOpenerCtx{}).(SiblingOpener)
+	if !ok {
+		return nil, cause
+	}
+
+	maxSize := v.settings.MusicCoverFolderMaxSize(ctx)
+	for _, name := range v.settings.MusicCoverFolderCandidates(ctx) {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		res, err := v.copySibling(ctx, opener, name, maxSize)
+		if err != nil {
+			v.l.Debug("Cover candidate %q not usable: %s", name, err)
+			continue
+		}
+
+		return res, nil
+	}
+
+	return nil, cause
+}
+
+func (v *MusicCoverGenerator) copySibling(ctx context.Context, opener SiblingOpener, name string, maxSize int64) (*Result, error) {
+	src, err := opener(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	defer src.Close()
+	if maxSize > 0 && src.Entity().Size() > maxSize {
+		return nil, fmt.Errorf("cover size %d exceeds the limit %d", src.Entity().Size(), maxSize)
+	}
+
+	tempPath, thumbFile, err := v.createTempFile(ctx, util.Ext(name))
+	if err != nil {
+		return nil, err
+	}
+
+	defer thumbFile.Close()
+	if _, err := io.Copy(thumbFile, src); err != nil {
+		thumbFile.Close()
+		_ = os.Remove(tempPath)
+		return nil, fmt.Errorf("failed to copy cover to file: %w", err)
+	}
+
+	return &Result{
+		Path:     tempPath,
+		Continue: true,
+		Cleanup:  []func(){func() { _ = os.Remove(tempPath) }},
+	}, nil
+}
+
+func (v *MusicCoverGenerator) createTempFile(ctx context.Context, ext string) (string, *os.File, error) {
+	tempPath := filepath.Join(
+		util.DataPath(v.settings.TempPath(ctx)),
+		thumbTempFolder,
+		fmt.Sprintf("thumb_%s.%s", uuid.Must(uuid.NewV4()).String(), strings.TrimPrefix(ext, ".")),
+	)
+
+	thumbFile, err := util.CreatNestedFile(tempPath)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+
+	return tempPath, thumbFile, nil
+}
+
 func (v *MusicCoverGenerator) Priority() int {
 	return 50
 }
@@ -0,0 +1,130 @@
+package thumb
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/cloudreve/Cloudreve/v4/inventory/types"
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/driver/local"
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/fs"
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/manager/entitysource"
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting/settingtest"
+	"github.com/stretchr/testify/assert"
+)
+
+// memorySource is an entity source backed by in-memory content.
+type memorySource struct {
+	entitysource.EntitySource
+	*bytes.Reader
+	e fs.Entity
+}
+
+func (m *memorySource) Read(p []byte) (int, error)              { return m.Reader.Read(p) }
+func (m *memorySource) ReadAt(p []byte, off int64) (int, error) { return m.Reader.ReadAt(p, off) }
+func (m *memorySource) Seek(offset int64, whence int) (int64, error) {
+	return m.Reader.Seek(offset, whence)
+}
+func (m *memorySource) Close() error      { return nil }
+func (m *memorySource) Entity() fs.Entity { return m.e }
+
+func newMemorySource(t *testing.T, content []byte) *memorySource {
+	f, err := os.CreateTemp(t.TempDir(), "src")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write(content)
+	f.Close()
+
+	e, err := local.NewLocalFileEntity(types.EntityTypeVersion, f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &memorySource{Reader: bytes.NewReader(content), e: e}
+}
+
+// id3WithCover builds a minimal ID3v2.3 tag with an APIC frame.
+func id3WithCover(cover []byte) []byte {
+	frame := &bytes.Buffer{}
+	frame.WriteByte(0)
+	frame.WriteString("image/jpeg\x00")
+	frame.WriteByte(3)
+	frame.WriteByte(0)
+	frame.Write(cover)
+
+	body := &bytes.Buffer{}
+	body.WriteString("APIC")
+	binary.Write(body, binary.BigEndian, uint32(frame.Len()))
+	body.Write([]byte{0, 0})
+	body.Write(frame.Bytes())
+
+	size := body.Len()
+	res := &bytes.Buffer{}
+	res.WriteString("ID3")
+	res.Write([]byte{3, 0, 0})
+	res.Write([]byte{byte(size >> 21 & 0x7f), byte(size >> 14 & 0x7f), byte(size >> 7 & 0x7f), byte(size & 0x7f)})
+	res.Write(body.Bytes())
+	return res.Bytes()
+}
+
+func newTestMusicCoverGenerator(t *testing.T) *MusicCoverGenerator {
+	settings := settingtest.NewProvider(map[string]any{
+		"thumb_music_cover_exts":              "mp3",
+		"thumb_music_cover_folder_candidates": "cover.jpg,folder.jpg",
+		"temp_path":                           t.TempDir(),
+	})
+	return NewMusicCoverGenerator(logging.NewConsoleLogger(logging.LevelError), settings)
+}
+
+func TestMusicCoverGenerator_EmbeddedCover(t *testing.T) {
+	a := assert.New(t)
+	g := newTestMusicCoverGenerator(t)
+
+	res, err := g.Generate(context.Background(), newMemorySource(t, id3WithCover([]byte("embedded"))), "mp3", nil)
+	a.NoError(err)
+	a.True(res.Continue)
+	content, err := os.ReadFile(res.Path)
+	a.NoError(err)
+	a.Equal("embedded", string(content))
+}
+
+func TestMusicCoverGenerator_FolderCover(t *testing.T) {
+	a := assert.New(t)
+	g := newTestMusicCoverGenerator(t)
+
+	requested := []string{}
+	ctx := context.WithValue(context.Background(), SiblingOpenerCtx{}, SiblingOpener(func(ctx context.Context, name string) (entitysource.EntitySource, error) {
+		requested = append(requested, name)
+		if name == "folder.jpg" {
+			return newMemorySource(t, []byte("folder")), nil
+		}
+		return nil, fs.ErrEntityNotExist
+	}))
+
+	res, err := g.Generate(ctx, newMemorySource(t, []byte("no tags here")), "mp3", nil)
+	a.NoError(err)
+	a.Equal([]string{"cover.jpg", "folder.jpg"}, requested)
+	a.Equal("jpg", res.Path[len(res.Path)-3:])
+	content, err := os.ReadFile(res.Path)
+	a.NoError(err)
+	a.Equal("folder", string(content))
+}
+
+func TestMusicCoverGenerator_NoCover(t *testing.T) {
+	a := assert.New(t)
+	g := newTestMusicCoverGenerator(t)
+
+	ctx := context.WithValue(context.Background(), SiblingOpenerCtx{}, SiblingOpener(func(ctx context.Context, name string) (entitysource.EntitySource, error) {
+		return nil, fs.ErrEntityNotExist
+	}))
+
+	res, err := g.Generate(ctx, newMemorySource(t, []byte("no tags here")), "mp3", nil)
+	a.Error(err)
+	a.False(errors.Is(err, ErrPassThrough))
+	a.Nil(res)
+}
@@ -0,0 +1,92 @@
+package thumb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"path/filepath"
+
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/manager/entitysource"
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
+	"github.com/cloudreve/Cloudreve/v4/pkg/util"
+	"github.com/gofrs/uuid"
+)
+
+var (
+	// pdfRenderer renders the first page of a PDF to fit in given size, it is set if a native
+	// PDF rasterizer is compiled in with the "pdf" build tag.
+	pdfRenderer func(r io.Reader, width, height int) (image.Image, error)
+
+	errPdfEncrypted = errors.New("PDF is encrypted")
+)
+
+// PdfRendererAvailable returns true if a native PDF rasterizer is compiled in.
+func PdfRendererAvailable() bool {
+	return pdfRenderer != nil
+}
+
+func NewPdfGenerator(l logging.Logger, settings setting.Provider) *PdfGenerator {
+	return &PdfGenerator{l: l, settings: settings}
+}
+
+// PdfGenerator renders thumbnails of PDF files natively, without invoking LibreOffice. It is only
+// registered in pipeline if Cloudreve is built with the "pdf" tag, e.g. `go build -tags pdf`.
+type PdfGenerator struct {
+	l        logging.Logger
+	settings setting.Provider
+}
+
+func (p *PdfGenerator) Generate(ctx context.Context, es entitysource.EntitySource, ext string, previous *Result) (*Result, error) {
+	if ext != "pdf" {
+		return nil, fmt.Errorf("unsupported document format: %w", ErrPassThrough)
+	}
+
+	if pdfRenderer == nil {
+		return nil, fmt.Errorf("PDF renderer is not compiled in: %w", ErrPassThrough)
+	}
+
+	if es.Entity().Size() > p.settings.PdfThumbMaxSize(ctx) {
+		return nil, fmt.Errorf("file is too big: %w", ErrPassThrough)
+	}
+
+	w, h := p.settings.ThumbSize(ctx)
+	img, err := pdfRenderer(es, w, h)
+	if err != nil {
+		if errors.Is(err, errPdfEncrypted) {
+			return nil, fmt.Errorf("%w: %w", err, ErrPassThrough)
+		}
+
+		return nil, fmt.Errorf("failed to render PDF: %w", err)
+	}
+
+	thumb := &Thumb{src: img, ext: ext}
+	thumb.GetThumb(uint(w), uint(h))
+	tempPath := filepath.Join(
+		util.DataPath(p.settings.TempPath(ctx)),
+		thumbTempFolder,
+		fmt.Sprintf("pdf_%s", uuid.Must(uuid.NewV4()).String()),
+	)
+
+	thumbFile, err := util.CreatNestedFile(tempPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+
+	defer thumbFile.Close()
+	if err := thumb.Save(thumbFile, p.settings.ThumbEncode(ctx)); err != nil {
+		return &Result{Path: tempPath}, err
+	}
+
+	return &Result{Path: tempPath}, nil
+}
+
+func (p *PdfGenerator) Priority() int {
+	return 75
+}
+
+func (p *PdfGenerator) Enabled(ctx context.Context) bool {
+	return p.settings.PdfThumbGeneratorEnabled(ctx)
+}
@@ -0,0 +1,43 @@
+//go:build pdf
+
+package thumb
+
+import (
+	"errors"
+	"image"
+	"io"
+
+	"github.com/gen2brain/go-fitz"
+)
+
+// Native PDF rendering is only compiled in with the "pdf" build tag, pages are rasterized by
+// MuPDF, either linked statically with cgo, or loaded as shared library at runtime otherwise.
+func init() {
+	pdfRenderer = func(r io.Reader, width, height int) (image.Image, error) {
+		doc, err := fitz.NewFromReader(r)
+		if errors.Is(err, fitz.ErrNeedsPassword) {
+			_ = doc.Close()
+			return nil, errPdfEncrypted
+		} else if err != nil {
+			return nil, err
+		}
+		defer doc.Close()
+
+		if doc.NumPage() < 1 {
+			return nil, errors.New("PDF has no pages")
+		}
+
+		bound, err := doc.Bound(0)
+		if err != nil {
+			return nil, err
+		}
+
+		// Bound is measured in 72 DPI, pick a DPI so that the page just fits in thumb size.
+		dpi := 72.0
+		if bound.Dx() > 0 && bound.Dy() > 0 {
+			dpi = min(72*float64(width)/float64(bound.Dx()), 72*float64(height)/float64(bound.Dy()))
+		}
+
+		return doc.ImageDPI(0, min(dpi, 300))
+	}
+}
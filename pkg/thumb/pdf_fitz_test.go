@@ -0,0 +1,60 @@
+//go:build pdf
+
+package thumb
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/stretchr/testify/assert"
+)
+
+// testPdf returns a single page PDF of 200x100 points, with left half filled in red. It has no
+// xref table, which is rebuilt by MuPDF on load.
+func testPdf() []byte {
+	content := "1 0 0 rg 0 0 100 100 re f"
+	return []byte(fmt.Sprintf(`%%PDF-1.4
+1 0 obj <</Type /Catalog /Pages 2 0 R>> endobj
+2 0 obj <</Type /Pages /Kids [3 0 R] /Count 1>> endobj
+3 0 obj <</Type /Page /Parent 2 0 R /MediaBox [0 0 200 100] /Contents 4 0 R>> endobj
+4 0 obj <</Length %d>> stream
+%s
+endstream endobj
+trailer <</Root 1 0 R>>
+%%%%EOF
+`, len(content), content))
+}
+
+func TestPdfRenderer(t *testing.T) {
+	a := assert.New(t)
+	a.True(PdfRendererAvailable())
+
+	// Registered in pipeline
+	p := NewPipeline(nil, logging.NewConsoleLogger(logging.LevelError), NewProcessLimiter()).(pipeline)
+	registered := false
+	for _, g := range p.generators {
+		if _, ok := g.(*PdfGenerator); ok {
+			registered = true
+		}
+	}
+	a.True(registered)
+
+	// Fit in thumb size with aspect ratio kept
+	img, err := pdfRenderer(bytes.NewReader(testPdf()), 400, 400)
+	if !a.NoError(err) {
+		return
+	}
+	a.Equal(400, img.Bounds().Dx())
+	a.Equal(200, img.Bounds().Dy())
+
+	r, g, b, _ := img.At(100, 100).RGBA()
+	a.Equal([]uint32{0xffff, 0, 0}, []uint32{r, g, b})
+	r, g, b, _ = img.At(300, 100).RGBA()
+	a.Equal([]uint32{0xffff, 0xffff, 0xffff}, []uint32{r, g, b})
+
+	// Invalid input
+	_, err = pdfRenderer(bytes.NewReader([]byte("not a pdf")), 400, 400)
+	a.Error(err)
+}
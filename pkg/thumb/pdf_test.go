@@ -0,0 +1,28 @@
+//go:build !pdf
+
+package thumb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPdfGenerator_NotCompiled(t *testing.T) {
+	a := assert.New(t)
+	l := logging.NewConsoleLogger(logging.LevelError)
+	a.False(PdfRendererAvailable())
+
+	// Not registered in pipeline
+	p := NewPipeline(nil, l, NewProcessLimiter()).(pipeline)
+	for _, g := range p.generators {
+		_, isPdf := g.(*PdfGenerator)
+		a.False(isPdf)
+	}
+
+	// Skipped if constructed explicitly
+	_, err := NewPdfGenerator(l, nil).Generate(context.Background(), nil, "pdf", nil)
+	a.ErrorIs(err, ErrPassThrough)
+}
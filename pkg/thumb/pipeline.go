@@ -7,10 +7,12 @@ import (
 	"io"
 	"reflect"
 	"sort"
+	"time"
 
 	"github.com/cloudreve/Cloudreve/v4/inventory/types"
 	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/manager/entitysource"
 	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/cloudreve/Cloudreve/v4/pkg/metrics"
 	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
 	"github.com/cloudreve/Cloudreve/v4/pkg/util"
 )
@@ -34,6 +36,8 @@ type (
 		Ext      string
 		Continue bool
 		Cleanup  []func()
+		// Metadata extracted during generation, saved as thumb metadata of the file.
+		Metadata map[string]string
 	}
 	GeneratorType string
 
@@ -62,18 +66,24 @@ func (g generatorList) Swap(i, j int) {
 	g[i], g[j] = g[j], g[i]
 }
 
-// NewPipeline creates a new pipeline with all available generators.
-func NewPipeline(settings setting.Provider, l logging.Logger) Generator {
+// NewPipeline creates a new pipeline with all available generators. External processes spawned by
+// generators are limited by given limiter.
+func NewPipeline(settings setting.Provider, l logging.Logger, limiter ProcessLimiter) Generator {
 	generators := generatorList{}
 	generators = append(
 		generators,
 		NewBuiltinGenerator(settings),
-		NewFfmpegGenerator(l, settings),
-		NewVipsGenerator(l, settings),
-		NewLibreOfficeGenerator(l, settings),
+		NewFfmpegGenerator(l, settings, limiter),
+		NewVipsGenerator(l, settings, limiter),
+		NewLibreOfficeGenerator(l, settings, limiter),
 		NewMusicCoverGenerator(l, settings),
-		NewLibRawGenerator(l, settings),
+		NewLibRawGenerator(l, settings, limiter),
+		NewSvgGenerator(l, settings),
+		NewEpubGenerator(l, settings),
 	)
+	if PdfRendererAvailable() {
+		generators = append(generators, NewPdfGenerator(l, settings))
+	}
 	sort.Sort(generators)
 
 	return pipeline{
@@ -91,7 +101,9 @@ func (p pipeline) Generate(ctx context.Context, es entitysource.EntitySource, ex
 				return nil, fmt.Errorf("thumb: failed to seek to start of file: %w", err)
 			}
 
+			start := time.Now()
 			res, err := generator.Generate(ctx, es, ext, state)
+			observeGenerator(generator, start, res, err)
 			if errors.Is(err, ErrPassThrough) {
 				p.l.Debug("Failed to generate thumbnail using %s for %s: %s, passing through to next generator.", reflect.TypeOf(generator).String(), e.Source(), err)
 				continue
@@ -123,6 +135,19 @@ func (p pipeline) Generate(ctx context.Context, es entitysource.EntitySource, ex
 	return nil, ErrNotAvailable
 }
 
+// observeGenerator records time spent by a generator in one generation attempt.
+func observeGenerator(generator Generator, start time.Time, res *Result, err error) {
+	result := metrics.ResultSuccess
+	if errors.Is(err, ErrPassThrough) || (err == nil && res != nil && res.Continue) {
+		result = metrics.ResultPass
+	} else if err != nil {
+		result = metrics.ResultFailure
+	}
+
+	name := reflect.Indirect(reflect.ValueOf(generator)).Type().Name()
+	metrics.ThumbDuration.WithLabelValues(name, result).Observe(time.Since(start).Seconds())
+}
+
 func (p pipeline) Priority() int {
 	return 0
 }
@@ -0,0 +1,192 @@
+package thumb
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/manager/entitysource"
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
+	"github.com/cloudreve/Cloudreve/v4/pkg/util"
+	"github.com/gofrs/uuid"
+	"github.com/srwiley/oksvg"
+	"github.com/srwiley/rasterx"
+	"golang.org/x/image/draw"
+	"golang.org/x/net/html/charset"
+)
+
+var ErrSvgTooComplex = errors.New("SVG is too complex")
+
+func NewSvgGenerator(l logging.Logger, settings setting.Provider) *SvgGenerator {
+	return &SvgGenerator{l: l, settings: settings}
+}
+
+// SvgGenerator rasterizes SVG files with a pure Go renderer.
+type SvgGenerator struct {
+	l        logging.Logger
+	settings setting.Provider
+}
+
+func (s *SvgGenerator) Generate(ctx context.Context, es entitysource.EntitySource, ext string, previous *Result) (*Result, error) {
+	if ext != "svg" {
+		return nil, fmt.Errorf("unsupported image format: %w", ErrPassThrough)
+	}
+
+	if es.Entity().Size() > s.settings.SvgThumbMaxSize(ctx) {
+		return nil, fmt.Errorf("file is too big: %w", ErrPassThrough)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(es, s.settings.SvgThumbMaxSize(ctx)+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SVG: %w", err)
+	}
+
+	if int64(len(data)) > s.settings.SvgThumbMaxSize(ctx) {
+		return nil, fmt.Errorf("file is too big: %w", ErrPassThrough)
+	}
+
+	encode := s.settings.ThumbEncode(ctx)
+	w, h := s.settings.ThumbSize(ctx)
+	img, err := rasterizeSvg(data, w, h, s.settings.SvgThumbMaxNodes(ctx), encode.Format != "png")
+	if err != nil {
+		return nil, fmt.Errorf("failed to rasterize SVG: %w (%w)", err, ErrPassThrough)
+	}
+
+	thumb := &Thumb{src: img, ext: ext}
+	tempPath := filepath.Join(
+		util.DataPath(s.settings.TempPath(ctx)),
+		thumbTempFolder,
+		fmt.Sprintf("svg_%s", uuid.Must(uuid.NewV4()).String()),
+	)
+
+	thumbFile, err := util.CreatNestedFile(tempPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+
+	defer thumbFile.Close()
+	if err := thumb.Save(thumbFile, encode); err != nil {
+		return &Result{Path: tempPath}, err
+	}
+
+	return &Result{Path: tempPath}, nil
+}
+
+func (s *SvgGenerator) Priority() int {
+	return 250
+}
+
+func (s *SvgGenerator) Enabled(ctx context.Context) bool {
+	return s.settings.SvgThumbGeneratorEnabled(ctx)
+}
+
+// rasterizeSvg renders SVG document to fit in given size. Formats without alpha channel
+// are rendered on white background.
+func rasterizeSvg(data []byte, width, height, maxNodes int, opaque bool) (img image.Image, err error) {
+	if err := checkSvgComplexity(bytes.NewReader(data), maxNodes); err != nil {
+		return nil, err
+	}
+
+	icon, err := oksvg.ReadIconStream(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	iw, ih := icon.ViewBox.W, icon.ViewBox.H
+	if iw <= 0 || ih <= 0 {
+		iw, ih = float64(width), float64(height)
+	}
+
+	scale := min(float64(width)/iw, float64(height)/ih)
+	tw, th := max(int(iw*scale), 1), max(int(ih*scale), 1)
+	icon.SetTarget(0, 0, float64(tw), float64(th))
+
+	rgba := image.NewRGBA(image.Rect(0, 0, tw, th))
+	if opaque {
+		draw.Draw(rgba, rgba.Bounds(), image.White, image.Point{}, draw.Src)
+	}
+
+	// Renderer may panic on malformed paths
+	defer func() {
+		if r := recover(); r != nil {
+			img, err = nil, fmt.Errorf("renderer panic: %v", r)
+		}
+	}()
+
+	scanner := rasterx.NewScannerGV(tw, th, rgba, rgba.Bounds())
+	icon.Draw(rasterx.NewDasher(tw, th, scanner), 1)
+	return rgba, nil
+}
+
+// checkSvgComplexity rejects SVG documents declaring entities, or having more than maxNodes elements
+// after expanding <use> references.
+func checkSvgComplexity(r io.Reader, maxNodes int) error {
+	decoder := xml.NewDecoder(r)
+	decoder.CharsetReader = charset.NewReaderLabel
+
+	type element struct {
+		id   string
+		size int
+	}
+
+	var (
+		stack    []element
+		sizes    = make(map[string]int)
+		expanded = 0
+	)
+	for {
+		t, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch se := t.(type) {
+		case xml.Directive:
+			if bytes.Contains(se, []byte("ENTITY")) {
+				return fmt.Errorf("%w: entity declaration is not allowed", ErrSvgTooComplex)
+			}
+		case xml.StartElement:
+			e := element{size: 1}
+			for _, attr := range se.Attr {
+				switch attr.Name.Local {
+				case "id":
+					e.id = attr.Value
+				case "href":
+					if se.Name.Local == "use" {
+						e.size += sizes[strings.TrimPrefix(attr.Value, "#")]
+					}
+				}
+			}
+
+			expanded += e.size
+			if expanded > maxNodes {
+				return fmt.Errorf("%w: more than %d elements", ErrSvgTooComplex, maxNodes)
+			}
+
+			stack = append(stack, e)
+		case xml.EndElement:
+			if len(stack) == 0 {
+				continue
+			}
+
+			e := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if e.id != "" {
+				sizes[e.id] = e.size
+			}
+			if len(stack) > 0 {
+				stack[len(stack)-1].size += e.size
+			}
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package thumb
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRasterizeSvg(t *testing.T) {
+	a := assert.New(t)
+	svg := `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 200 100"><rect x="0" y="0" width="100" height="100" fill="#ff0000"/></svg>`
+
+	img, err := rasterizeSvg([]byte(svg), 400, 400, 100, true)
+	a.NoError(err)
+
+	// Fit in thumb size with aspect ratio kept
+	a.Equal(400, img.Bounds().Dx())
+	a.Equal(200, img.Bounds().Dy())
+
+	r, g, b, _ := img.At(50, 100).RGBA()
+	a.Equal([]uint32{0xffff, 0, 0}, []uint32{r, g, b})
+
+	// Opaque background
+	r, g, b, _ = img.At(300, 100).RGBA()
+	a.Equal([]uint32{0xffff, 0xffff, 0xffff}, []uint32{r, g, b})
+
+	// Transparent background
+	img, err = rasterizeSvg([]byte(svg), 400, 400, 100, false)
+	a.NoError(err)
+	_, _, _, alpha := img.At(300, 100).RGBA()
+	a.EqualValues(0, alpha)
+}
+
+func TestCheckSvgComplexity(t *testing.T) {
+	a := assert.New(t)
+
+	// Elements referenced by <use> repeatedly
+	bomb := &strings.Builder{}
+	bomb.WriteString(`<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink"><defs>`)
+	bomb.WriteString(`<g id="l0"><rect width="1" height="1"/></g>`)
+	for i := 1; i < 10; i++ {
+		bomb.WriteString(fmt.Sprintf(`<g id="l%d">`, i))
+		for j := 0; j < 10; j++ {
+			bomb.WriteString(fmt.Sprintf(`<use xlink:href="#l%d"/>`, i-1))
+		}
+		bomb.WriteString(`</g>`)
+	}
+	bomb.WriteString(`</defs><use xlink:href="#l9"/></svg>`)
+	a.ErrorIs(checkSvgComplexity(strings.NewReader(bomb.String()), 10000), ErrSvgTooComplex)
+
+	// Entity expansion
+	laughs := `<?xml version="1.0"?><!DOCTYPE svg [<!ENTITY lol "lol"><!ENTITY lol2 "&lol;&lol;">]><svg>&lol2;</svg>`
+	a.ErrorIs(checkSvgComplexity(strings.NewReader(laughs), 10000), ErrSvgTooComplex)
+
+	// Plain elements
+	plain := `<svg xmlns="http://www.w3.org/2000/svg">` + strings.Repeat(`<rect width="1" height="1"/>`, 10) + `</svg>`
+	a.NoError(checkSvgComplexity(strings.NewReader(plain), 11))
+	a.ErrorIs(checkSvgComplexity(strings.NewReader(plain), 10), ErrSvgTooComplex)
+}
@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strconv"
+	"sync"
 
 	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/manager/entitysource"
 	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
@@ -18,13 +19,17 @@ import (
 	"github.com/gofrs/uuid"
 )
 
-func NewVipsGenerator(l logging.Logger, settings setting.Provider) *VipsGenerator {
-	return &VipsGenerator{l: l, settings: settings}
+// vipsAvifFallbackOnce logs once that AVIF thumbnails are generated as PNG by vips.
+var vipsAvifFallbackOnce sync.Once
+
+func NewVipsGenerator(l logging.Logger, settings setting.Provider, limiter ProcessLimiter) *VipsGenerator {
+	return &VipsGenerator{l: l, settings: settings, limiter: limiter}
 }
 
 type VipsGenerator struct {
 	l        logging.Logger
 	settings setting.Provider
+	limiter  ProcessLimiter
 }
 
 func (v *VipsGenerator) Generate(ctx context.Context, es entitysource.EntitySource, ext string, previous *Result) (*Result, error) {
@@ -38,8 +43,13 @@ func (v *VipsGenerator) Generate(ctx context.Context, es entitysource.EntitySour
 
 	outputOpt := ".png"
 	encode := v.settings.ThumbEncode(ctx)
-	if encode.Format == "jpg" || encode.Format == "webp" {
+	switch encode.Format {
+	case "jpg", "webp":
 		outputOpt = fmt.Sprintf(".%s[Q=%d]", encode.Format, encode.Quality)
+	case "avif":
+		vipsAvifFallbackOnce.Do(func() {
+			v.l.Warning("AVIF output is not supported by vips generator, thumbnails are encoded as png instead.")
+		})
 	}
 
 	input := "[descriptor=0]"
@@ -103,7 +113,7 @@ func (v *VipsGenerator) Generate(ctx context.Context, es entitysource.EntitySour
 	cmd.Stdout = thumbFile
 	cmd.Stderr = &vipsErr
 
-	if err := cmd.Run(); err != nil {
+	if err := runExternal(ctx, v.settings, v.limiter, cmd); err != nil {
 		v.l.Warning("Failed to invoke vips: %s", vipsErr.String())
 		return &Result{Path: tempPath}, fmt.Errorf("failed to invoke vips: %w, raw output: %s", err, vipsErr.String())
 	}
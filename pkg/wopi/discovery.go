@@ -66,3 +66,47 @@ func DiscoveryXmlToViewerGroup(xmlStr string) (*types.ViewerGroup, error) {
 
 	return group, nil
 }
+
+// MergeDiscoveryXmlToViewerGroup parses multiple discovery documents and merges their apps into
+// one viewer group. Documents are given in priority order: if an extension is supported by apps
+// from more than one document, only the app from the earliest document keeps it.
+func MergeDiscoveryXmlToViewerGroup(xmlStrs ...string) (*types.ViewerGroup, error) {
+	merged := &types.ViewerGroup{Viewers: []types.Viewer{}}
+	claimed := make(map[string]bool)
+
+	for i, xmlStr := range xmlStrs {
+		group, err := DiscoveryXmlToViewerGroup(xmlStr)
+		if err != nil {
+			return nil, fmt.Errorf("discovery #%d: %w", i, err)
+		}
+
+		// Apps within the same document may share extensions, only claim them after the whole
+		// document is processed.
+		exts := make(map[string]bool)
+		for _, viewer := range group.Viewers {
+			for ext := range viewer.WopiActions {
+				if claimed[ext] {
+					delete(viewer.WopiActions, ext)
+					continue
+				}
+				exts[ext] = true
+			}
+
+			if len(viewer.WopiActions) == 0 {
+				continue
+			}
+
+			viewer.Exts = lo.Filter(viewer.Exts, func(ext string, _ int) bool {
+				_, ok := viewer.WopiActions[ext]
+				return ok
+			})
+			merged.Viewers = append(merged.Viewers, viewer)
+		}
+
+		for ext := range exts {
+			claimed[ext] = true
+		}
+	}
+
+	return merged, nil
+}
@@ -3,6 +3,9 @@ package wopi
 import (
 	"fmt"
 	"testing"
+
+	"github.com/cloudreve/Cloudreve/v4/inventory/types"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestDiscoveryXmlToViewerGroup(t *testing.T) {
@@ -436,3 +439,42 @@ func TestDiscoveryXmlToViewerGroup(t *testing.T) {
 	group, _ := DiscoveryXmlToViewerGroup(xmlSrc)
 	fmt.Print(group)
 }
+
+func TestMergeDiscoveryXmlToViewerGroup(t *testing.T) {
+	a := assert.New(t)
+	onlyOffice := `<wopi-discovery><net-zone name="external-https">
+<app name="Word"><action ext="docx" name="edit" urlsrc="https://oo/edit?"/><action ext="docx" name="view" urlsrc="https://oo/view?"/></app>
+<app name="Excel"><action ext="xlsx" name="edit" urlsrc="https://oo/cell?"/></app>
+</net-zone></wopi-discovery>`
+	collabora := `<wopi-discovery><net-zone name="external-http">
+<app name="writer"><action ext="docx" name="edit" urlsrc="https://co/docx?"/><action ext="odt" name="edit" urlsrc="https://co/odt?"/></app>
+<app name="calc"><action ext="xlsx" name="edit" urlsrc="https://co/xlsx?"/></app>
+</net-zone></wopi-discovery>`
+
+	group, err := MergeDiscoveryXmlToViewerGroup(onlyOffice, collabora)
+	a.NoError(err)
+	if a.Len(group.Viewers, 3) {
+		a.Equal("Word", group.Viewers[0].DisplayName)
+		a.Equal(map[types.ViewerAction]string{
+			types.ViewerActionView: "https://oo/view?",
+			types.ViewerActionEdit: "https://oo/edit?",
+		}, group.Viewers[0].WopiActions["docx"])
+		a.Equal("Excel", group.Viewers[1].DisplayName)
+		a.Equal("writer", group.Viewers[2].DisplayName)
+		a.Equal([]string{"odt"}, group.Viewers[2].Exts)
+		a.Equal(map[string]map[types.ViewerAction]string{
+			"odt": {types.ViewerActionEdit: "https://co/odt?"},
+		}, group.Viewers[2].WopiActions)
+	}
+
+	// Priority follows the order of documents
+	group, err = MergeDiscoveryXmlToViewerGroup(collabora, onlyOffice)
+	a.NoError(err)
+	if a.Len(group.Viewers, 2) {
+		a.Equal("https://co/docx?", group.Viewers[0].WopiActions["docx"][types.ViewerActionEdit])
+		a.Equal("calc", group.Viewers[1].DisplayName)
+	}
+
+	_, err = MergeDiscoveryXmlToViewerGroup(onlyOffice, "<invalid")
+	a.Error(err)
+}
@@ -42,9 +42,14 @@ const (
 	ServerErrorHeader     = WopiHeaderPrefix + "ServerError"
 	RenameRequestHeader   = WopiHeaderPrefix + "RequestedName"
 	LockTokenHeader       = WopiHeaderPrefix + "Lock"
+	OldLockHeader         = WopiHeaderPrefix + "OldLock"
 	ItemVersionHeader     = WopiHeaderPrefix + "ItemVersion"
 	SuggestedTargetHeader = WopiHeaderPrefix + "SuggestedTarget"
 
+	RelativeTargetHeader          = WopiHeaderPrefix + "RelativeTarget"
+	OverwriteRelativeTargetHeader = WopiHeaderPrefix + "OverwriteRelativeTarget"
+	ValidRelativeTargetHeader     = WopiHeaderPrefix + "ValidRelativeTarget"
+
 	MethodLock           = "LOCK"
 	MethodUnlock         = "UNLOCK"
 	MethodRefreshLock    = "REFRESH_LOCK"
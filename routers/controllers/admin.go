@@ -41,6 +41,47 @@ func AdminSetSettings(c *gin.Context) {
 	c.JSON(200, serializer.Response{Data: res})
 }
 
+func AdminExportSettings(c *gin.Context) {
+	res, err := admin.ExportSettings(c)
+	if err != nil {
+		c.JSON(200, serializer.Err(c, err))
+		return
+	}
+
+	c.JSON(200, serializer.Response{Data: res})
+}
+
+func AdminImportSettings(c *gin.Context) {
+	service := ParametersFromContext[*admin.ImportSettingService](c, admin.ImportSettingParamCtx{})
+	if err := service.Import(c); err != nil {
+		c.JSON(200, serializer.Err(c, err))
+		return
+	}
+
+	c.JSON(200, serializer.Response{})
+}
+
+func AdminResetSettings(c *gin.Context) {
+	service := ParametersFromContext[*admin.ResetSettingService](c, admin.ResetSettingParamCtx{})
+	if err := service.Reset(c); err != nil {
+		c.JSON(200, serializer.Err(c, err))
+		return
+	}
+
+	c.JSON(200, serializer.Response{})
+}
+
+func AdminListSettingAudits(c *gin.Context) {
+	service := ParametersFromContext[*admin.AdminListService](c, admin.AdminListServiceParamsCtx{})
+	res, err := service.SettingAudits(c)
+	if err != nil {
+		c.JSON(200, serializer.Err(c, err))
+		return
+	}
+
+	c.JSON(200, serializer.Response{Data: res})
+}
+
 // AdminListGroups 获取用户组列表
 func AdminListGroups(c *gin.Context) {
 	service := ParametersFromContext[*admin.AdminListService](c, admin.AdminListServiceParamsCtx{})
@@ -116,6 +157,17 @@ func AdminSendTestMail(c *gin.Context) {
 	c.JSON(200, serializer.Response{})
 }
 
+// AdminPreviewEmailTemplate 预览邮件模板
+func AdminPreviewEmailTemplate(c *gin.Context) {
+	service := ParametersFromContext[*admin.PreviewEmailTemplateService](c, admin.PreviewEmailTemplateParamCtx{})
+	res, err := service.Preview(c)
+	if err != nil {
+		c.JSON(200, serializer.Err(c, err))
+		return
+	}
+	c.JSON(200, serializer.Response{Data: res})
+}
+
 func AdminCreatePolicy(c *gin.Context) {
 	service := ParametersFromContext[*admin.CreateStoragePolicyService](c, admin.CreateStoragePolicyParamCtx{})
 	res, err := service.Create(c)
@@ -161,6 +213,14 @@ func AdminClearEntityUrlCache(c *gin.Context) {
 	c.JSON(200, serializer.Response{})
 }
 
+func AdminBackfillEntityChecksum(c *gin.Context) {
+	if err := admin.BackfillEntityChecksum(c); err != nil {
+		c.JSON(200, serializer.Err(c, err))
+		return
+	}
+	c.JSON(200, serializer.Response{})
+}
+
 func AdminCreateStoragePolicyCors(c *gin.Context) {
 	service := ParametersFromContext[*admin.CreateStoragePolicyCorsService](c, admin.CreateStoragePolicyCorsParamCtx{})
 	err := service.Create(c)
@@ -172,6 +232,17 @@ func AdminCreateStoragePolicyCors(c *gin.Context) {
 	c.JSON(200, serializer.Response{})
 }
 
+func AdminTestPolicy(c *gin.Context) {
+	service := ParametersFromContext[*admin.TestStoragePolicyService](c, admin.TestStoragePolicyParamCtx{})
+	res, err := service.Test(c)
+	if err != nil {
+		c.JSON(200, serializer.Err(c, err))
+		return
+	}
+
+	c.JSON(200, serializer.Response{Data: res})
+}
+
 func AdminOdOAuthURL(c *gin.Context) {
 	service := ParametersFromContext[*admin.GetOauthRedirectService](c, admin.GetOauthRedirectParamCtx{})
 	res, err := service.GetOAuth(c)
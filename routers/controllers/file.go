@@ -141,6 +141,19 @@ func Thumb(c *gin.Context) {
 	c.JSON(200, serializer.Response{Data: res})
 }
 
+// AnimatedThumb get animated preview of video file
+func AnimatedThumb(c *gin.Context) {
+	service := ParametersFromContext[*explorer.FileThumbService](c, explorer.FileThumbParameterCtx{})
+	res, err := service.GetAnimated(c)
+	if err != nil {
+		c.JSON(200, serializer.Err(c, err))
+		c.Abort()
+		return
+	}
+
+	c.JSON(200, serializer.Response{Data: res})
+}
+
 // FileURL get temporary file url for preview or download
 func FileURL(c *gin.Context) {
 	service := ParametersFromContext[*explorer.FileURLService](c, explorer.FileURLParameterCtx{})
@@ -227,6 +240,19 @@ func DeleteUploadSession(c *gin.Context) {
 	c.JSON(200, serializer.Response{})
 }
 
+// ListUploadedParts lists parts already uploaded in an upload session
+func ListUploadedParts(c *gin.Context) {
+	service := ParametersFromContext[*explorer.ListUploadedPartsService](c, explorer.ListUploadedPartsParameterCtx{})
+	res, err := service.List(c)
+	if err != nil {
+		c.JSON(200, serializer.Err(c, err))
+		c.Abort()
+		return
+	}
+
+	c.JSON(200, serializer.Response{Data: res})
+}
+
 // CreateUploadSession 创建上传会话
 func CreateUploadSession(c *gin.Context) {
 	service := ParametersFromContext[*explorer.CreateUploadSessionService](c, explorer.CreateUploadSessionParameterCtx{})
@@ -347,6 +373,45 @@ func Unpin(c *gin.Context) {
 	c.JSON(200, serializer.Response{})
 }
 
+// Favorite marks files as favorite
+func Favorite(c *gin.Context) {
+	service := ParametersFromContext[*explorer.FavoriteFileService](c, explorer.FavoriteFileParameterCtx{})
+	err := service.Favorite(c)
+	if err != nil {
+		c.JSON(200, serializer.Err(c, err))
+		c.Abort()
+		return
+	}
+
+	c.JSON(200, serializer.Response{})
+}
+
+// Unfavorite removes favorite flag of files
+func Unfavorite(c *gin.Context) {
+	service := ParametersFromContext[*explorer.FavoriteFileService](c, explorer.FavoriteFileParameterCtx{})
+	err := service.Unfavorite(c)
+	if err != nil {
+		c.JSON(200, serializer.Err(c, err))
+		c.Abort()
+		return
+	}
+
+	c.JSON(200, serializer.Response{})
+}
+
+// ListFavorites lists favorite files of current user
+func ListFavorites(c *gin.Context) {
+	service := ParametersFromContext[*explorer.ListFavoriteService](c, explorer.ListFavoriteParameterCtx{})
+	resp, err := service.List(c)
+	if err != nil {
+		c.JSON(200, serializer.Err(c, err))
+		c.Abort()
+		return
+	}
+
+	c.JSON(200, serializer.Response{Data: resp})
+}
+
 // PatchMetadata patch metadata
 func PatchMetadata(c *gin.Context) {
 	service := ParametersFromContext[*explorer.PatchMetadataService](c, explorer.PatchMetadataParameterCtx{})
@@ -360,6 +425,31 @@ func PatchMetadata(c *gin.Context) {
 	c.JSON(200, serializer.Response{})
 }
 
+// PatchMetadataBatch patch metadata of multiple files with different values
+func PatchMetadataBatch(c *gin.Context) {
+	service := ParametersFromContext[*explorer.PatchMetadataBatchService](c, explorer.PatchMetadataBatchParameterCtx{})
+	err := service.Patch(c)
+	if err != nil {
+		c.JSON(200, serializer.Err(c, err))
+		c.Abort()
+		return
+	}
+
+	c.JSON(200, serializer.Response{})
+}
+
+// ListTagTree lists tags of current user as a tree
+func ListTagTree(c *gin.Context) {
+	res, err := explorer.ListTagTree(c)
+	if err != nil {
+		c.JSON(200, serializer.Err(c, err))
+		c.Abort()
+		return
+	}
+
+	c.JSON(200, serializer.Response{Data: res})
+}
+
 // GetFileInfo gets file info
 func GetFileInfo(c *gin.Context) {
 	service := ParametersFromContext[*explorer.GetFileInfoService](c, explorer.GetFileInfoParameterCtx{})
@@ -3,6 +3,10 @@ package controllers
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
 	"github.com/cloudreve/Cloudreve/v4/pkg/serializer"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -64,6 +68,10 @@ func FromJSON[T any](ctxKey any) gin.HandlerFunc {
 		if err := c.ShouldBindJSON(&service); err == nil {
 			c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), ctxKey, &service))
 			c.Next()
+		} else if tooLarge := (*http.MaxBytesError)(nil); errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, serializer.ErrWithDetails(c, serializer.CodeRequestTooLarge,
+				fmt.Sprintf("Request body exceeds the size limit of %d bytes", tooLarge.Limit), nil))
+			c.Abort()
 		} else {
 			c.JSON(200, ErrorResponse(err))
 			c.Abort()
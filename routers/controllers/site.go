@@ -1,6 +1,9 @@
 package controllers
 
 import (
+	"net/http"
+	"time"
+
 	"github.com/cloudreve/Cloudreve/v4/application/constants"
 	"github.com/cloudreve/Cloudreve/v4/application/dependency"
 	"github.com/cloudreve/Cloudreve/v4/pkg/serializer"
@@ -12,13 +15,18 @@ import (
 func SiteConfig(c *gin.Context) {
 	service := ParametersFromContext[*basic.GetSettingService](c, basic.GetSettingParamCtx{})
 
-	resp, err := service.GetSiteConfig(c)
+	resp, notModified, err := service.GetSiteConfigWithCache(c)
 	if err != nil {
 		c.JSON(200, serializer.Err(c, err))
 		c.Abort()
 		return
 	}
 
+	if notModified {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
 	c.JSON(200, serializer.Response{
 		Data: resp,
 	})
@@ -37,6 +45,35 @@ func Ping(c *gin.Context) {
 	})
 }
 
+// healthReportTTL is the duration a health report is reused for.
+const healthReportTTL = 5 * time.Second
+
+// Health 汇总各子系统健康状态，子系统详情仅对管理员或持有监控凭证的请求可见
+func Health() gin.HandlerFunc {
+	checker := basic.NewHealthChecker(healthReportTTL)
+	return func(c *gin.Context) {
+		dep := dependency.FromContext(c)
+		report := checker.Check(c, dep.SettingProvider().HealthProbeTimeout(c), basic.DefaultHealthProbes(dep))
+		status := http.StatusOK
+		if report.Status == basic.HealthStatusDown {
+			status = http.StatusServiceUnavailable
+		}
+
+		if !basic.HealthDetailsAllowed(c) {
+			report = report.Summary()
+		}
+
+		c.JSON(status, serializer.Response{
+			Data: report,
+		})
+	}
+}
+
+// Metrics 输出 Prometheus 格式的监控指标
+func Metrics(dep dependency.Dep) gin.HandlerFunc {
+	return gin.WrapH(basic.MetricsHandler(dep))
+}
+
 // Captcha 获取验证码
 func Captcha(c *gin.Context) {
 	c.JSON(200, serializer.Response{
@@ -45,6 +82,35 @@ func Captcha(c *gin.Context) {
 	})
 }
 
+// CaptchaAudio 获取验证码的语音版本
+func CaptchaAudio(c *gin.Context) {
+	service := ParametersFromContext[*basic.GetCaptchaAudioService](c, basic.GetCaptchaAudioParamCtx{})
+	resp, err := service.GetCaptchaAudio(c)
+	if err != nil {
+		c.JSON(200, serializer.Err(c, err))
+		c.Abort()
+		return
+	}
+
+	c.JSON(200, serializer.Response{
+		Data: resp,
+	})
+}
+
+// GeeTestRegister 获取极验验证码挑战
+func GeeTestRegister(c *gin.Context) {
+	resp, err := basic.RegisterGeeTest(c)
+	if err != nil {
+		c.JSON(200, serializer.Err(c, err))
+		c.Abort()
+		return
+	}
+
+	c.JSON(200, serializer.Response{
+		Data: resp,
+	})
+}
+
 // Manifest 获取manifest.json
 func Manifest(c *gin.Context) {
 	settingClient := dependency.FromContext(c).SettingProvider()
@@ -35,7 +35,7 @@ func GetFile(c *gin.Context) {
 // PutFile Puts file content
 func PutFile(c *gin.Context) {
 	service := &explorer.WopiService{}
-	err := service.PutContent(c, false)
+	err := service.PutContent(c)
 	if err != nil {
 		c.Status(http.StatusInternalServerError)
 		c.Header(wopi.ServerErrorHeader, err.Error())
@@ -52,7 +52,11 @@ func ModifyFile(c *gin.Context) {
 
 	switch action {
 	case wopi.MethodLock:
-		err = service.Lock(c)
+		if c.GetHeader(wopi.OldLockHeader) != "" {
+			err = service.UnlockAndRelock(c)
+		} else {
+			err = service.Lock(c)
+		}
 		if err == nil {
 			return
 		}
@@ -67,7 +71,7 @@ func ModifyFile(c *gin.Context) {
 			return
 		}
 	case wopi.MethodPutRelative:
-		err = service.PutContent(c, true)
+		err = service.PutRelative(c)
 		if err == nil {
 			return
 		}
@@ -2,6 +2,7 @@ package routers
 
 import (
 	"net/http"
+	"os"
 
 	"github.com/abslant/gzip"
 	"github.com/cloudreve/Cloudreve/v4/application/constants"
@@ -200,6 +201,7 @@ func initCORS(l logging.Logger, config conf.ConfigProvider, router *gin.Engine)
 // initMasterRouter 初始化主机模式路由
 func initMasterRouter(dep dependency.Dep) *gin.Engine {
 	r := newGinEngine(dep)
+	r.Use(middleware.AccessLog(os.Stdout))
 	// 跨域相关
 	initCORS(dep.Logger(), dep.ConfigProvider(), r) // Done
 
@@ -218,9 +220,9 @@ func initMasterRouter(dep dependency.Dep) *gin.Engine {
 		// 获取文件内容
 		wopi.GET(":id/contents", controllers.GetFile)
 		// 更新文件内容
-		wopi.POST(":id/contents", controllers.PutFile)
+		wopi.POST(":id/contents", middleware.MaintenanceMode(), controllers.PutFile)
 		// 通用文件操作
-		wopi.POST(":id", controllers.ModifyFile)
+		wopi.POST(":id", middleware.MaintenanceMode(), controllers.ModifyFile)
 	}
 
 	v4 := r.Group(constants.APIPrefix)
@@ -236,6 +238,17 @@ func initMasterRouter(dep dependency.Dep) *gin.Engine {
 	// 禁止缓存
 	v4.Use(middleware.CacheControl()) // Done
 
+	// 维护模式下拒绝写操作，登录相关路由除外
+	v4.Use(middleware.MaintenanceMode(constants.APIPrefix + "/session"))
+
+	// 限制非上传请求的请求体大小
+	v4.Use(middleware.RequestSizeLimit(
+		constants.APIPrefix+"/file/upload/:sessionId/:index",
+		constants.APIPrefix+"/file/content",
+		// Chunk upload via slave RPC
+		constants.APIPrefix+"/slave/upload/:sessionId",
+	))
+
 	/*
 		路由
 	*/
@@ -268,8 +281,23 @@ func initMasterRouter(dep dependency.Dep) *gin.Engine {
 		{
 			// 测试用路由
 			site.GET("ping", controllers.Ping)
+			// 健康检查
+			site.GET("health", controllers.Health())
+			// 监控指标
+			site.GET("metrics",
+				middleware.IsFunctionEnabled(func(c *gin.Context) bool {
+					return dep.SettingProvider().MetricsEnabled(c)
+				}),
+				middleware.MetricsAuth(),
+				controllers.Metrics(dep),
+			)
 			// 验证码
 			site.GET("captcha", controllers.Captcha)
+			site.GET("captcha/audio",
+				controllers.FromQuery[basic.GetCaptchaAudioService](basic.GetCaptchaAudioParamCtx{}),
+				controllers.CaptchaAudio,
+			)
+			site.GET("captcha/geetest", controllers.GeeTestRegister)
 			// 站点全局配置
 			site.GET("config/:section",
 				controllers.FromUri[basic.GetSettingService](basic.GetSettingParamCtx{}),
@@ -624,6 +652,12 @@ func initMasterRouter(dep dependency.Dep) *gin.Engine {
 				controllers.FromQuery[explorer.FileThumbService](explorer.FileThumbParameterCtx{}),
 				controllers.Thumb,
 			)
+			// get animated preview of video
+			file.GET("thumb/animated",
+				middleware.ContextHint(),
+				controllers.FromQuery[explorer.FileThumbService](explorer.FileThumbParameterCtx{}),
+				controllers.AnimatedThumb,
+			)
 			// Delete files
 			file.DELETE("",
 				controllers.FromJSON[explorer.DeleteFileService](explorer.DeleteFileParameterCtx{}),
@@ -647,6 +681,35 @@ func initMasterRouter(dep dependency.Dep) *gin.Engine {
 				middleware.ValidateBatchFileCount(dep, explorer.PatchMetadataParameterCtx{}),
 				controllers.PatchMetadata,
 			)
+			// List tag tree
+			file.GET("tags", middleware.LoginRequired(), controllers.ListTagTree)
+			// Favorite files
+			favorite := file.Group("favorite", middleware.LoginRequired())
+			{
+				// List favorite files
+				favorite.GET("",
+					controllers.FromQuery[explorer.ListFavoriteService](explorer.ListFavoriteParameterCtx{}),
+					controllers.ListFavorites,
+				)
+				// Mark files as favorite
+				favorite.PUT("",
+					controllers.FromJSON[explorer.FavoriteFileService](explorer.FavoriteFileParameterCtx{}),
+					middleware.ValidateBatchFileCount(dep, explorer.FavoriteFileParameterCtx{}),
+					controllers.Favorite,
+				)
+				// Remove favorite flag
+				favorite.DELETE("",
+					controllers.FromJSON[explorer.FavoriteFileService](explorer.FavoriteFileParameterCtx{}),
+					middleware.ValidateBatchFileCount(dep, explorer.FavoriteFileParameterCtx{}),
+					controllers.Unfavorite,
+				)
+			}
+			// Patch metadata of each file with different values
+			file.PATCH("metadata/batch",
+				controllers.FromJSON[explorer.PatchMetadataBatchService](explorer.PatchMetadataBatchParameterCtx{}),
+				middleware.ValidateBatchFileCount(dep, explorer.PatchMetadataBatchParameterCtx{}),
+				controllers.PatchMetadataBatch,
+			)
 			// Upload related
 			upload := file.Group("upload")
 			{
@@ -660,6 +723,11 @@ func initMasterRouter(dep dependency.Dep) *gin.Engine {
 					controllers.FromUri[explorer.UploadService](explorer.UploadParameterCtx{}),
 					controllers.FileUpload,
 				)
+				// List uploaded parts to resume upload
+				upload.GET(":sessionId/parts",
+					controllers.FromUri[explorer.ListUploadedPartsService](explorer.ListUploadedPartsParameterCtx{}),
+					controllers.ListUploadedParts,
+				)
 				upload.DELETE("",
 					controllers.FromJSON[explorer.DeleteUploadSessionService](explorer.DeleteUploadSessionParameterCtx{}),
 					controllers.DeleteUploadSession,
@@ -799,6 +867,23 @@ func initMasterRouter(dep dependency.Dep) *gin.Engine {
 						controllers.FromJSON[adminsvc.SetSettingService](adminsvc.SetSettingParamCtx{}),
 						controllers.AdminSetSettings,
 					)
+					// Export all settings
+					settings.GET("export", controllers.AdminExportSettings)
+					// Import settings from exported backup
+					settings.POST("import",
+						controllers.FromJSON[adminsvc.ImportSettingService](adminsvc.ImportSettingParamCtx{}),
+						controllers.AdminImportSettings,
+					)
+					// Reset settings to default values
+					settings.POST("reset",
+						controllers.FromJSON[adminsvc.ResetSettingService](adminsvc.ResetSettingParamCtx{}),
+						controllers.AdminResetSettings,
+					)
+					// List audit logs of setting changes
+					settings.POST("audits",
+						controllers.FromJSON[adminsvc.AdminListService](adminsvc.AdminListServiceParamsCtx{}),
+						controllers.AdminListSettingAudits,
+					)
 				}
 
 				// 用户组管理
@@ -844,9 +929,16 @@ func initMasterRouter(dep dependency.Dep) *gin.Engine {
 						controllers.FromJSON[adminsvc.TestSMTPService](adminsvc.TestSMTPParamCtx{}),
 						controllers.AdminSendTestMail,
 					)
+					tool.POST("mail/preview",
+						controllers.FromJSON[adminsvc.PreviewEmailTemplateService](adminsvc.PreviewEmailTemplateParamCtx{}),
+						controllers.AdminPreviewEmailTemplate,
+					)
 					tool.DELETE("entityUrlCache",
 						controllers.AdminClearEntityUrlCache,
 					)
+					tool.POST("entityChecksum",
+						controllers.AdminBackfillEntityChecksum,
+					)
 				}
 
 				queue := admin.Group("queue")
@@ -906,6 +998,11 @@ func initMasterRouter(dep dependency.Dep) *gin.Engine {
 						controllers.FromJSON[adminsvc.CreateStoragePolicyCorsService](adminsvc.CreateStoragePolicyCorsParamCtx{}),
 						controllers.AdminCreateStoragePolicyCors,
 					)
+					// 测试存储策略可用性
+					policy.POST("test",
+						controllers.FromJSON[adminsvc.TestStoragePolicyService](adminsvc.TestStoragePolicyParamCtx{}),
+						controllers.AdminTestPolicy,
+					)
 					// // 获取 OneDrive OAuth URL
 					oauth := policy.Group("oauth")
 					{
@@ -1179,7 +1276,7 @@ func initMasterRouter(dep dependency.Dep) *gin.Engine {
 // initWebDAV 初始化WebDAV相关路由
 func initWebDAV(group *gin.RouterGroup) {
 	{
-		group.Use(middleware.CacheControl(), middleware.WebDAVAuth())
+		group.Use(middleware.CacheControl(), middleware.WebDAVAuth(), middleware.MaintenanceMode())
 		group.Any("/*path", webdav.ServeHTTP)
 		group.Any("", webdav.ServeHTTP)
 		group.Handle("PROPFIND", "/*path", webdav.ServeHTTP)
@@ -8,6 +8,7 @@ import (
 
 	"github.com/cloudreve/Cloudreve/v4/application/dependency"
 	"github.com/cloudreve/Cloudreve/v4/ent"
+	"github.com/cloudreve/Cloudreve/v4/ent/schema"
 	"github.com/cloudreve/Cloudreve/v4/inventory"
 	"github.com/cloudreve/Cloudreve/v4/inventory/types"
 	"github.com/cloudreve/Cloudreve/v4/pkg/cluster/routes"
@@ -157,6 +158,7 @@ const (
 	fileMetadataCondition   = "file_metadata"
 	fileSharedCondition     = "file_shared"
 	fileDirectLinkCondition = "file_direct_link"
+	fileDeletedCondition    = "file_deleted"
 )
 
 func (service *AdminListService) Files(c *gin.Context) (*ListFileResponse, error) {
@@ -218,6 +220,7 @@ func (service *AdminListService) Files(c *gin.Context) (*ListFileResponse, error
 		HasMetadata:     metadata,
 		Shared:          shared,
 		HasDirectLink:   directLink,
+		IncludeDeleted:  setting.IsTrueValue(service.Conditions[fileDeletedCondition]),
 	})
 
 	if err != nil {
@@ -255,6 +258,8 @@ func (service *SingleFileService) Get(c *gin.Context) (*GetFileResponse, error)
 	ctx = context.WithValue(ctx, inventory.LoadEntityUser{}, true)
 	ctx = context.WithValue(ctx, inventory.LoadEntityStoragePolicy{}, true)
 	ctx = context.WithValue(ctx, inventory.LoadFileDirectLink{}, true)
+	// Files in trash bin can also be inspected by admin
+	ctx = schema.IncludeDeletedFiles(ctx)
 
 	file, err := fileClient.GetByID(ctx, service.ID)
 	if err != nil {
@@ -369,7 +374,7 @@ func (s *BatchFileService) Delete(c *gin.Context) error {
 	dep := dependency.FromContext(c)
 	fileClient := dep.FileClient()
 
-	ctx := context.WithValue(c, inventory.LoadFileEntity{}, true)
+	ctx := context.WithValue(schema.IncludeDeletedFiles(c), inventory.LoadFileEntity{}, true)
 	files, _, err := fileClient.GetByIDs(ctx, s.IDs, 0)
 	if err != nil {
 		return serializer.NewError(serializer.CodeDBError, "Failed to get files", err)
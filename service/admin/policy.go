@@ -2,6 +2,8 @@ package admin
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/url"
@@ -16,6 +18,7 @@ import (
 	"github.com/cloudreve/Cloudreve/v4/inventory/types"
 	"github.com/cloudreve/Cloudreve/v4/pkg/cluster/routes"
 	"github.com/cloudreve/Cloudreve/v4/pkg/credmanager"
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/driver"
 	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/driver/cos"
 	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/driver/ks3"
 	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/driver/obs"
@@ -29,6 +32,7 @@ import (
 	"github.com/cloudreve/Cloudreve/v4/pkg/request"
 	"github.com/cloudreve/Cloudreve/v4/pkg/serializer"
 	"github.com/gin-gonic/gin"
+	"github.com/samber/lo"
 )
 
 // PathTestService 本地路径测试服务
@@ -271,7 +275,7 @@ func (service *CreateStoragePolicyService) Create(c *gin.Context) (*GetStoragePo
 		return nil, serializer.NewError(serializer.CodeDBError, "Failed to create policy", err)
 	}
 
-	return &GetStoragePolicyResponse{StoragePolicy: policy}, nil
+	return &GetStoragePolicyResponse{StoragePolicy: policy, Cors: applyCorsOnSave(c, policy)}, nil
 }
 
 type (
@@ -314,7 +318,13 @@ func (service *UpdateStoragePolicyService) Update(c *gin.Context) (*GetStoragePo
 	_ = dep.KV().Delete(manager.EntityUrlCacheKeyPrefix)
 
 	s := SingleStoragePolicyService{ID: idInt}
-	return s.Get(c)
+	res, err := s.Get(c)
+	if err != nil {
+		return nil, err
+	}
+
+	res.Cors = applyCorsOnSave(c, service.Policy)
+	return res, nil
 }
 
 type (
@@ -325,69 +335,83 @@ type (
 )
 
 func (service *CreateStoragePolicyCorsService) Create(c *gin.Context) error {
-	dep := dependency.FromContext(c)
+	handler, err := newCorsDriver(c, service.Policy)
+	if err != nil {
+		return err
+	}
 
-	switch service.Policy.Type {
-	case types.PolicyTypeOss:
-		handler, err := oss.New(c, service.Policy, dep.SettingProvider(), dep.ConfigProvider(), dep.Logger(), dep.MimeDetector(c))
-		if err != nil {
-			return serializer.NewError(serializer.CodeDBError, "Failed to create oss driver", err)
-		}
-		if err := handler.CORS(); err != nil {
-			return serializer.NewError(serializer.CodeInternalSetting, "Failed to create cors: "+err.Error(), err)
-		}
+	if err := handler.CORS(); err != nil {
+		return serializer.NewError(serializer.CodeInternalSetting, "Failed to create cors: "+err.Error(), err)
+	}
 
-		return nil
+	return nil
+}
 
-	case types.PolicyTypeCos:
-		handler, err := cos.New(c, service.Policy, dep.SettingProvider(), dep.ConfigProvider(), dep.Logger(), dep.MimeDetector(c))
-		if err != nil {
-			return serializer.NewError(serializer.CodeDBError, "Failed to create cos driver", err)
-		}
+// corsDriver is implemented by drivers of S3-style storage policies that can set CORS rules on bucket.
+type corsDriver interface {
+	CORS() error
+}
 
-		if err := handler.CORS(); err != nil {
-			return serializer.NewError(serializer.CodeInternalSetting, "Failed to create cors: "+err.Error(), err)
-		}
+var corsPolicyTypes = []string{types.PolicyTypeOss, types.PolicyTypeCos, types.PolicyTypeS3, types.PolicyTypeKs3, types.PolicyTypeObs}
 
-		return nil
+func newCorsDriver(c *gin.Context, policy *ent.StoragePolicy) (corsDriver, error) {
+	dep := dependency.FromContext(c)
 
+	var (
+		handler corsDriver
+		err     error
+	)
+	switch policy.Type {
+	case types.PolicyTypeOss:
+		handler, err = oss.New(c, policy, dep.SettingProvider(), dep.ConfigProvider(), dep.Logger(), dep.MimeDetector(c))
+	case types.PolicyTypeCos:
+		handler, err = cos.New(c, policy, dep.SettingProvider(), dep.ConfigProvider(), dep.Logger(), dep.MimeDetector(c))
 	case types.PolicyTypeS3:
-		handler, err := s3.New(c, service.Policy, dep.SettingProvider(), dep.ConfigProvider(), dep.Logger(), dep.MimeDetector(c))
-		if err != nil {
-			return serializer.NewError(serializer.CodeDBError, "Failed to create s3 driver", err)
-		}
+		handler, err = s3.New(c, policy, dep.SettingProvider(), dep.ConfigProvider(), dep.Logger(), dep.MimeDetector(c))
+	case types.PolicyTypeKs3:
+		handler, err = ks3.New(c, policy, dep.SettingProvider(), dep.ConfigProvider(), dep.Logger(), dep.MimeDetector(c))
+	case types.PolicyTypeObs:
+		handler, err = obs.New(c, policy, dep.SettingProvider(), dep.ConfigProvider(), dep.Logger(), dep.MimeDetector(c))
+	default:
+		return nil, serializer.NewError(serializer.CodeParamErr, "Unsupported policy type", nil)
+	}
 
-		if err := handler.CORS(); err != nil {
-			return serializer.NewError(serializer.CodeInternalSetting, "Failed to create cors: "+err.Error(), err)
-		}
+	if err != nil {
+		return nil, serializer.NewError(serializer.CodeDBError, fmt.Sprintf("Failed to create %s driver", policy.Type), err)
+	}
 
-		return nil
+	return handler, nil
+}
 
-	case types.PolicyTypeKs3:
-		handler, err := ks3.New(c, service.Policy, dep.SettingProvider(), dep.ConfigProvider(), dep.Logger(), dep.MimeDetector(c))
-		if err != nil {
-			return serializer.NewError(serializer.CodeDBError, "Failed to create ks3 driver", err)
-		}
+// autoApplyCors applies CORS rules to the bucket if the policy opts in. Nil is returned if
+// auto applying is disabled or not supported by the policy type. Failure won't block saving
+// the policy, it is reported in the result instead.
+func autoApplyCors(policy *ent.StoragePolicy, newDriver func(*ent.StoragePolicy) (corsDriver, error)) *PolicyCorsResult {
+	if policy.Settings == nil || !policy.Settings.AutoApplyCors || !lo.Contains(corsPolicyTypes, policy.Type) {
+		return nil
+	}
 
-		if err := handler.CORS(); err != nil {
-			return serializer.NewError(serializer.CodeInternalSetting, "Failed to create cors: "+err.Error(), err)
-		}
+	handler, err := newDriver(policy)
+	if err == nil {
+		err = handler.CORS()
+	}
 
-		return nil
-	case types.PolicyTypeObs:
-		handler, err := obs.New(c, service.Policy, dep.SettingProvider(), dep.ConfigProvider(), dep.Logger(), dep.MimeDetector(c))
-		if err != nil {
-			return serializer.NewError(serializer.CodeDBError, "Failed to create obs driver", err)
-		}
+	if err != nil {
+		return &PolicyCorsResult{Error: err.Error()}
+	}
 
-		if err := handler.CORS(); err != nil {
-			return serializer.NewError(serializer.CodeInternalSetting, "Failed to create cors: "+err.Error(), err)
-		}
+	return &PolicyCorsResult{Applied: true}
+}
 
-		return nil
-	default:
-		return serializer.NewError(serializer.CodeParamErr, "Unsupported policy type", nil)
+func applyCorsOnSave(c *gin.Context, policy *ent.StoragePolicy) *PolicyCorsResult {
+	res := autoApplyCors(policy, func(p *ent.StoragePolicy) (corsDriver, error) {
+		return newCorsDriver(c, p)
+	})
+	if res != nil && !res.Applied {
+		logging.FromContext(c).Warning("Failed to apply CORS rules for storage policy %q: %s", policy.Name, res.Error)
 	}
+
+	return res
 }
 
 type (
@@ -455,7 +479,8 @@ func (service *SingleStoragePolicyService) GetOauthCredentialStatus(c *gin.Conte
 		return nil, serializer.NewError(serializer.CodeDBError, "Failed to get credential", err)
 	}
 
-	return &OauthCredentialStatus{Valid: true, LastRefreshTime: token.RefreshedAt()}, nil
+	status, _ := dep.CredManager().RefreshStatus(onedrive.CredentialKey(policy.ID))
+	return &OauthCredentialStatus{Valid: true, LastRefreshTime: token.RefreshedAt(), RefreshStatus: status}, nil
 }
 
 type (
@@ -525,3 +550,71 @@ func (service *SingleStoragePolicyService) GetSharePointDriverRoot(c *gin.Contex
 
 	return fmt.Sprintf("sites/%s/drive", root), nil
 }
+
+const (
+	policyHealthCachePrefix = "policy_health_"
+	policyHealthCacheTTL    = 30 // seconds
+)
+
+type (
+	TestStoragePolicyService struct {
+		Policy *ent.StoragePolicy `json:"policy" binding:"required"`
+	}
+	TestStoragePolicyParamCtx struct{}
+)
+
+// Test checks whether storage of given policy is reachable and writable. Result is cached
+// briefly, keyed by policy content so that an edited policy is always checked again.
+func (service *TestStoragePolicyService) Test(c *gin.Context) (*PolicyHealthResult, error) {
+	dep := dependency.FromContext(c)
+	kv := dep.KV()
+	if service.Policy.Settings == nil {
+		service.Policy.Settings = &types.PolicySetting{}
+	}
+
+	content, err := json.Marshal(service.Policy)
+	if err != nil {
+		return nil, serializer.NewError(serializer.CodeParamErr, "Failed to encode policy", err)
+	}
+
+	key := fmt.Sprintf("%s%x", policyHealthCachePrefix, sha256.Sum256(content))
+	if cached, ok := kv.Get(key); ok {
+		if res, ok := cached.(PolicyHealthResult); ok {
+			return &res, nil
+		}
+	}
+
+	m := manager.NewFileManager(dep, inventory.UserFromContext(c))
+	defer m.Recycle()
+
+	d, err := m.GetStorageDriver(c, service.Policy)
+	if err != nil {
+		return nil, serializer.NewError(serializer.CodeInternalSetting, "Failed to get storage driver", err)
+	}
+
+	res := policyHealth(d.HealthCheck(c))
+	_ = kv.Set(key, *res, policyHealthCacheTTL)
+	return res, nil
+}
+
+// policyHealth converts error returned by driver health check into result.
+func policyHealth(err error) *PolicyHealthResult {
+	res := &PolicyHealthResult{Healthy: err == nil, CheckedAt: time.Now()}
+	if err == nil {
+		return res
+	}
+
+	res.Error = err.Error()
+	switch {
+	case errors.Is(err, driver.ErrHealthCheckAuth):
+		res.Reason = PolicyHealthReasonAuth
+	case errors.Is(err, driver.ErrHealthCheckBucketNotFound):
+		res.Reason = PolicyHealthReasonBucketNotFound
+	case errors.Is(err, driver.ErrHealthCheckUnreachable):
+		res.Reason = PolicyHealthReasonUnreachable
+	default:
+		res.Reason = PolicyHealthReasonUnknown
+	}
+
+	return res
+}
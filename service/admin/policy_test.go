@@ -0,0 +1,74 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/cloudreve/Cloudreve/v4/ent"
+	"github.com/cloudreve/Cloudreve/v4/inventory/types"
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/driver"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeCorsDriver struct {
+	applied int
+	err     error
+}
+
+func (d *fakeCorsDriver) CORS() error {
+	d.applied++
+	return d.err
+}
+
+func TestAutoApplyCors(t *testing.T) {
+	a := assert.New(t)
+	driver := &fakeCorsDriver{}
+	newDriver := func(*ent.StoragePolicy) (corsDriver, error) { return driver, nil }
+
+	policy := &ent.StoragePolicy{Type: types.PolicyTypeS3, Settings: &types.PolicySetting{AutoApplyCors: true}}
+	a.Equal(&PolicyCorsResult{Applied: true}, autoApplyCors(policy, newDriver))
+	a.Equal(1, driver.applied)
+
+	// Failure is reported instead of blocking policy saving
+	driver.err = errors.New("access denied")
+	a.Equal(&PolicyCorsResult{Error: "access denied"}, autoApplyCors(policy, newDriver))
+	a.Equal(&PolicyCorsResult{Error: "invalid credential"}, autoApplyCors(policy,
+		func(*ent.StoragePolicy) (corsDriver, error) { return nil, errors.New("invalid credential") }))
+}
+
+func TestAutoApplyCors_OptOut(t *testing.T) {
+	a := assert.New(t)
+	driver := &fakeCorsDriver{}
+	newDriver := func(*ent.StoragePolicy) (corsDriver, error) { return driver, nil }
+
+	// Disabled by default so that buckets shared with other apps are not overwritten
+	a.Nil(autoApplyCors(&ent.StoragePolicy{Type: types.PolicyTypeOss, Settings: &types.PolicySetting{}}, newDriver))
+	a.Nil(autoApplyCors(&ent.StoragePolicy{Type: types.PolicyTypeOss}, newDriver))
+
+	// Policy types without bucket CORS are skipped
+	a.Nil(autoApplyCors(&ent.StoragePolicy{Type: types.PolicyTypeLocal, Settings: &types.PolicySetting{AutoApplyCors: true}}, newDriver))
+	a.Zero(driver.applied)
+}
+
+func TestPolicyHealth(t *testing.T) {
+	a := assert.New(t)
+
+	res := policyHealth(nil)
+	a.True(res.Healthy)
+	a.Empty(res.Reason)
+	a.False(res.CheckedAt.IsZero())
+
+	reasons := map[error]string{
+		driver.ErrHealthCheckAuth:           PolicyHealthReasonAuth,
+		driver.ErrHealthCheckBucketNotFound: PolicyHealthReasonBucketNotFound,
+		driver.ErrHealthCheckUnreachable:    PolicyHealthReasonUnreachable,
+		errors.New("not implemented"):       PolicyHealthReasonUnknown,
+	}
+	for err, reason := range reasons {
+		res = policyHealth(fmt.Errorf("wrapped: %w", err))
+		a.False(res.Healthy)
+		a.Equal(reason, res.Reason)
+		a.Equal("wrapped: "+err.Error(), res.Error)
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/cloudreve/Cloudreve/v4/ent"
 	"github.com/cloudreve/Cloudreve/v4/inventory"
+	"github.com/cloudreve/Cloudreve/v4/pkg/credmanager"
 	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/fs"
 	"github.com/cloudreve/Cloudreve/v4/pkg/queue"
 	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
@@ -80,14 +81,37 @@ type GetGroupResponse struct {
 }
 
 type OauthCredentialStatus struct {
-	Valid           bool       `json:"valid"`
-	LastRefreshTime *time.Time `json:"last_refresh_time"`
+	Valid           bool                       `json:"valid"`
+	LastRefreshTime *time.Time                 `json:"last_refresh_time"`
+	RefreshStatus   *credmanager.RefreshStatus `json:"refresh_status,omitempty"`
 }
 
 type GetStoragePolicyResponse struct {
 	*ent.StoragePolicy
-	EntitiesCount int `json:"entities_count,omitempty"`
-	EntitiesSize  int `json:"entities_size,omitempty"`
+	EntitiesCount int               `json:"entities_count,omitempty"`
+	EntitiesSize  int               `json:"entities_size,omitempty"`
+	Cors          *PolicyCorsResult `json:"cors,omitempty"`
+}
+
+// PolicyCorsResult is the result of applying CORS rules to the bucket of storage policy.
+type PolicyCorsResult struct {
+	Applied bool   `json:"applied"`
+	Error   string `json:"error,omitempty"`
+}
+
+const (
+	PolicyHealthReasonAuth           = "auth"
+	PolicyHealthReasonBucketNotFound = "bucket_not_found"
+	PolicyHealthReasonUnreachable    = "unreachable"
+	PolicyHealthReasonUnknown        = "unknown"
+)
+
+// PolicyHealthResult is the result of checking whether storage of a policy is available.
+type PolicyHealthResult struct {
+	Healthy   bool      `json:"healthy"`
+	Reason    string    `json:"reason,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
 }
 
 type ListNodeResponse struct {
@@ -140,4 +164,10 @@ type Version struct {
 
 func init() {
 	gob.Register(MetricsSummary{})
+	gob.Register(PolicyHealthResult{})
+}
+
+type ListSettingAuditResponse struct {
+	Pagination *inventory.PaginationResults `json:"pagination"`
+	Audits     []*ent.SettingAudit          `json:"audits"`
 }
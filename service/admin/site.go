@@ -4,9 +4,11 @@ import (
 	"context"
 	"encoding/gob"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/url"
 	"reflect"
+	"strconv"
 	"strings"
 	"time"
 
@@ -231,6 +233,20 @@ var (
 		"replyTo":                                    emailPostProcessor,
 		"fromName":                                   emailPostProcessor,
 		"fromAdress":                                 emailPostProcessor,
+		"mail_driver":                                emailPostProcessor,
+		"mail_bcc_all":                               emailPostProcessor,
+		"mail_extra_headers":                         emailPostProcessor,
+		"mail_max_retry":                             emailPostProcessor,
+		"mail_retry_interval":                        emailPostProcessor,
+		"dkim_private_key":                           emailPostProcessor,
+		"dkim_domain":                                emailPostProcessor,
+		"dkim_selector":                              emailPostProcessor,
+		"mail_api_key":                               emailPostProcessor,
+		"mail_api_domain":                            emailPostProcessor,
+		"mail_api_endpoint":                          emailPostProcessor,
+		"ses_region":                                 emailPostProcessor,
+		"ses_access_key":                             emailPostProcessor,
+		"ses_secret_key":                             emailPostProcessor,
 		"queue_media_meta_worker_num":                mediaMetaQueuePostProcessor,
 		"queue_media_meta_max_execution":             mediaMetaQueuePostProcessor,
 		"queue_media_meta_backoff_factor":            mediaMetaQueuePostProcessor,
@@ -304,6 +320,9 @@ func (s *SetSettingService) SetSetting(c *gin.Context) (map[string]string, error
 
 	if err := sc.Set(ctx, s.Settings); err != nil {
 		_ = inventory.Rollback(tx)
+		if errors.Is(err, inventory.ErrInvalidSetting) {
+			return nil, serializer.NewError(serializer.CodeParamErr, err.Error(), err)
+		}
 		return nil, serializer.NewError(serializer.CodeDBError, "Failed to save settings", err)
 	}
 
@@ -315,8 +334,13 @@ func (s *SetSettingService) SetSetting(c *gin.Context) (map[string]string, error
 	if err := kv.Delete(setting.KvSettingPrefix, lo.Keys(s.Settings)...); err != nil {
 		return nil, serializer.NewError(serializer.CodeInternalSetting, "Failed to clear cache", err)
 	}
+	if err := kv.Delete(setting.KvSiteConfigETagPrefix); err != nil {
+		return nil, serializer.NewError(serializer.CodeInternalSetting, "Failed to clear cache", err)
+	}
 
-	// Execute post preprocessors
+	// Execute post preprocessors. Settings are reloaded from primary database to avoid
+	// caching stale values from lagging replicas.
+	ctx = inventory.WithPrimaryRead(ctx)
 	for _, postprocessor := range allPostprocessors {
 		if err := postprocessor(ctx, s.Settings); err != nil {
 			return nil, serializer.NewError(serializer.CodeParamErr, "Failed to post process settings", err)
@@ -410,3 +434,152 @@ func secretKeyPostProcessor(ctx context.Context, settings map[string]string) err
 	settings["secret_key"] = ""
 	return nil
 }
+
+// ExportSettings returns all settings for backup, secret_key is excluded.
+func ExportSettings(c *gin.Context) (map[string]string, error) {
+	dep := dependency.FromContext(c)
+	res, err := dep.SettingClient().Export(c)
+	if err != nil {
+		return nil, serializer.NewError(serializer.CodeDBError, "Failed to export settings", err)
+	}
+
+	delete(res, "secret_key")
+	return res, nil
+}
+
+type (
+	ImportSettingService struct {
+		Settings map[string]string `json:"settings" binding:"required"`
+		// Overwrite existing settings, otherwise only missing settings are created.
+		Overwrite bool `json:"overwrite"`
+		// SkipUnknown ignores settings not defined in current version instead of rejecting the import.
+		SkipUnknown bool `json:"skip_unknown"`
+	}
+	ImportSettingParamCtx struct{}
+)
+
+// Import restores settings from an exported backup. secret_key is never imported.
+func (s *ImportSettingService) Import(c *gin.Context) error {
+	dep := dependency.FromContext(c)
+	settings := make(map[string]string, len(s.Settings))
+	for k, v := range s.Settings {
+		if k == "secret_key" {
+			continue
+		}
+
+		if _, ok := inventory.DefaultSettings[k]; !ok {
+			if s.SkipUnknown {
+				continue
+			}
+
+			return serializer.NewError(serializer.CodeParamErr, fmt.Sprintf("Unknown setting %q", k), nil)
+		}
+
+		settings[k] = v
+	}
+
+	// Settings are imported in one transaction, cache is cleared after commit.
+	if err := dep.SettingClient().Import(c, settings, s.Overwrite); err != nil {
+		if errors.Is(err, inventory.ErrInvalidSetting) {
+			return serializer.NewError(serializer.CodeParamErr, err.Error(), err)
+		}
+		return serializer.NewError(serializer.CodeDBError, "Failed to import settings", err)
+	}
+
+	if err := runPostProcessors(c, settings); err != nil {
+		return serializer.NewError(serializer.CodeParamErr, "Failed to post process settings", err)
+	}
+
+	return nil
+}
+
+const (
+	settingAuditNameCondition     = "setting_audit_name"
+	settingAuditOperatorCondition = "setting_audit_operator_id"
+)
+
+// SettingAudits lists audit logs of setting changes.
+func (s *AdminListService) SettingAudits(c *gin.Context) (*ListSettingAuditResponse, error) {
+	dep := dependency.FromContext(c)
+	operatorID := 0
+	if s.Conditions[settingAuditOperatorCondition] != "" {
+		var err error
+		operatorID, err = strconv.Atoi(s.Conditions[settingAuditOperatorCondition])
+		if err != nil {
+			return nil, serializer.NewError(serializer.CodeParamErr, "Invalid operator ID", err)
+		}
+	}
+
+	res, err := dep.SettingClient().ListAudits(c, &inventory.ListSettingAuditArgs{
+		PaginationArgs: &inventory.PaginationArgs{
+			Page:     s.Page - 1,
+			PageSize: s.PageSize,
+		},
+		Name:       s.Conditions[settingAuditNameCondition],
+		OperatorID: operatorID,
+	})
+	if err != nil {
+		return nil, serializer.NewError(serializer.CodeDBError, "Failed to list setting audit logs", err)
+	}
+
+	return &ListSettingAuditResponse{
+		Pagination: res.PaginationResults,
+		Audits:     res.Audits,
+	}, nil
+}
+
+// runPostProcessors reloads components affected by given settings, each post processor runs once.
+func runPostProcessors(ctx context.Context, settings map[string]string) error {
+	executed := make(map[uintptr]struct{})
+	for k := range settings {
+		postprocessor, ok := postprocessors[k]
+		if !ok {
+			continue
+		}
+
+		fn := reflect.ValueOf(postprocessor).Pointer()
+		if _, ok := executed[fn]; ok {
+			continue
+		}
+
+		executed[fn] = struct{}{}
+		if err := postprocessor(ctx, settings); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type (
+	ResetSettingService struct {
+		Names []string `json:"names" binding:"required,min=1"`
+		// Confirm must be true to reset settings, it cannot be undone.
+		Confirm bool `json:"confirm"`
+	}
+	ResetSettingParamCtx struct{}
+)
+
+// Reset reverts given settings to their default values.
+func (s *ResetSettingService) Reset(c *gin.Context) error {
+	if !s.Confirm {
+		return serializer.NewError(serializer.CodeParamErr, "Confirmation is required to reset settings", nil)
+	}
+
+	dep := dependency.FromContext(c)
+	if err := dep.SettingClient().Reset(c, s.Names...); err != nil {
+		if errors.Is(err, inventory.ErrUnknownSetting) {
+			return serializer.NewError(serializer.CodeParamErr, err.Error(), err)
+		}
+		return serializer.NewError(serializer.CodeDBError, "Failed to reset settings", err)
+	}
+
+	settings := lo.SliceToMap(s.Names, func(name string) (string, string) {
+		return name, ""
+	})
+	if err := runPostProcessors(c, settings); err != nil {
+		return serializer.NewError(serializer.CodeParamErr, "Failed to post process settings", err)
+	}
+
+	return nil
+}
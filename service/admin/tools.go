@@ -3,19 +3,26 @@ package admin
 import (
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/cloudreve/Cloudreve/v4/inventory/types"
 
 	"github.com/cloudreve/Cloudreve/v4/application/dependency"
+	"github.com/cloudreve/Cloudreve/v4/ent"
+	"github.com/cloudreve/Cloudreve/v4/inventory"
 	"github.com/cloudreve/Cloudreve/v4/pkg/boolset"
+	"github.com/cloudreve/Cloudreve/v4/pkg/cluster/routes"
+	"github.com/cloudreve/Cloudreve/v4/pkg/email"
 	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/manager"
+	"github.com/cloudreve/Cloudreve/v4/pkg/hashid"
 	request2 "github.com/cloudreve/Cloudreve/v4/pkg/request"
 	"github.com/cloudreve/Cloudreve/v4/pkg/serializer"
 	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
 	"github.com/cloudreve/Cloudreve/v4/pkg/wopi"
 	"github.com/gin-gonic/gin"
+	"github.com/samber/lo"
 	"github.com/wneessen/go-mail"
 )
 
@@ -104,21 +111,38 @@ func (service *BsDecodeService) Decode(c *gin.Context) (*BsDecodeRes, error) {
 }
 
 type (
+	// FetchWOPIDiscoveryService fetches discovery documents from one or more WOPI endpoints and
+	// merges them into one viewer group. Endpoints are in priority order, the former one wins
+	// when multiple endpoints support the same extension.
 	FetchWOPIDiscoveryService struct {
-		Endpoint string `form:"endpoint" binding:"required"`
+		Endpoint  string   `form:"endpoint"`
+		Endpoints []string `form:"endpoints"`
 	}
 	FetchWOPIDiscoveryParamCtx struct{}
 )
 
 func (s *FetchWOPIDiscoveryService) Fetch(c *gin.Context) (*types.ViewerGroup, error) {
+	endpoints := s.Endpoints
+	if s.Endpoint != "" {
+		endpoints = append([]string{s.Endpoint}, endpoints...)
+	}
+	endpoints = lo.Uniq(lo.Compact(endpoints))
+	if len(endpoints) == 0 {
+		return nil, serializer.NewError(serializer.CodeParamErr, "At least one WOPI endpoint is required", nil)
+	}
+
 	dep := dependency.FromContext(c)
 	requestClient := dep.RequestClient(request2.WithContext(c), request2.WithLogger(dep.Logger()))
-	content, err := requestClient.Request("GET", s.Endpoint, nil).CheckHTTPResponse(http.StatusOK).GetResponse()
-	if err != nil {
-		return nil, serializer.NewError(serializer.CodeInternalSetting, "WOPI endpoint id unavailable", err)
+	contents := make([]string, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		content, err := requestClient.Request("GET", endpoint, nil).CheckHTTPResponse(http.StatusOK).GetResponse()
+		if err != nil {
+			return nil, serializer.NewError(serializer.CodeInternalSetting, fmt.Sprintf("WOPI endpoint %q is unavailable", endpoint), err)
+		}
+		contents = append(contents, content)
 	}
 
-	vg, err := wopi.DiscoveryXmlToViewerGroup(content)
+	vg, err := wopi.MergeDiscoveryXmlToViewerGroup(contents...)
 	if err != nil {
 		return nil, serializer.NewError(serializer.CodeParamErr, "Failed to parse WOPI response", err)
 	}
@@ -135,6 +159,10 @@ type (
 )
 
 func (s *TestSMTPService) Test(c *gin.Context) error {
+	if setting.MailDriver(s.Settings["mail_driver"]) == setting.MailDriverSES {
+		return s.testSES(c)
+	}
+
 	port, err := strconv.Atoi(s.Settings["smtpPort"])
 	if err != nil {
 		return serializer.NewError(serializer.CodeParamErr, "Invalid SMTP port", err)
@@ -179,7 +207,107 @@ func (s *TestSMTPService) Test(c *gin.Context) error {
 	return nil
 }
 
+func (s *TestSMTPService) testSES(c *gin.Context) error {
+	err := email.SendSESTestEmail(c,
+		&setting.SES{
+			Region:    s.Settings["ses_region"],
+			AccessKey: s.Settings["ses_access_key"],
+			SecretKey: s.Settings["ses_secret_key"],
+		},
+		&setting.SMTP{
+			FromName: s.Settings["fromName"],
+			From:     s.Settings["fromAdress"],
+			ReplyTo:  s.Settings["replyTo"],
+		},
+		s.To, "Cloudreve SMTP Test", "This is a test email from Cloudreve.",
+	)
+	if err != nil {
+		return serializer.NewError(serializer.CodeInternalSetting, "Failed to send test email: "+err.Error(), err)
+	}
+
+	return nil
+}
+
+const (
+	EmailTemplateKindActivation = "activation"
+	EmailTemplateKindReset      = "reset"
+)
+
+type (
+	PreviewEmailTemplateService struct {
+		Kind     string `json:"kind" binding:"required,eq=activation|eq=reset"`
+		Language string `json:"language"`
+	}
+	PreviewEmailTemplateParamCtx struct{}
+	PreviewEmailTemplateResponse struct {
+		Title     string `json:"title"`
+		Body      string `json:"body"`
+		PlainBody string `json:"plain_body"`
+	}
+)
+
+// Preview renders configured email template with placeholder user data.
+func (s *PreviewEmailTemplateService) Preview(c *gin.Context) (*PreviewEmailTemplateResponse, error) {
+	dep := dependency.FromContext(c)
+	settings := dep.SettingProvider()
+	u := &ent.User{
+		ID:       1,
+		Email:    "user@example.com",
+		Nick:     "Cloudreve User",
+		Settings: &types.UserSetting{Language: s.Language},
+	}
+
+	// Sample link in the same form as real ones, with credential replaced by placeholder
+	base := settings.SiteURL(c)
+	sampleUrl, credentialKey := routes.MasterUserActivateUrl(base), "sign"
+	if s.Kind == EmailTemplateKindReset {
+		sampleUrl, credentialKey = routes.MasterUserResetUrl(base), "secret"
+	}
+	queries := sampleUrl.Query()
+	queries.Add("id", hashid.EncodeUserID(dep.HashIDEncoder(), u.ID))
+	queries.Add(credentialKey, "preview")
+	sampleUrl.RawQuery = queries.Encode()
+
+	var (
+		title, body, plainBody string
+		err                    error
+	)
+	if s.Kind == EmailTemplateKindReset {
+		title, body, plainBody, err = email.NewResetEmail(c, settings, u, sampleUrl.String())
+	} else {
+		title, body, plainBody, err = email.NewActivationEmail(c, settings, u, sampleUrl.String())
+	}
+	if err != nil {
+		return nil, serializer.NewError(serializer.CodeParamErr, "Failed to render email template: "+err.Error(), err)
+	}
+
+	return &PreviewEmailTemplateResponse{
+		Title:     title,
+		Body:      body,
+		PlainBody: plainBody,
+	}, nil
+}
+
 func ClearEntityUrlCache(c *gin.Context) {
 	dep := dependency.FromContext(c)
 	dep.KV().Delete(manager.EntityUrlCacheKeyPrefix)
 }
+
+// BackfillEntityChecksum queues a task to compute checksums of existing entities.
+func BackfillEntityChecksum(c *gin.Context) error {
+	dep := dependency.FromContext(c)
+	if dep.SettingProvider().EntityChecksumAlgorithm(c) == setting.ChecksumAlgorithmNone {
+		return serializer.NewError(serializer.CodeParamErr, "Entity checksum is not enabled", nil)
+	}
+
+	t, err := manager.NewEntityChecksumBackfillTask(c, inventory.UserFromContext(c))
+	if err != nil {
+		return serializer.NewError(serializer.CodeCreateTaskError, "Failed to create task", err)
+	}
+
+	if err := dep.IoIntenseQueue(c).QueueTask(c, t); err != nil {
+		return serializer.NewError(serializer.CodeCreateTaskError, "Failed to queue task", err)
+	}
+
+	return nil
+}
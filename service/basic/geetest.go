@@ -0,0 +1,154 @@
+package basic
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/cloudreve/Cloudreve/v4/application/dependency"
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/cloudreve/Cloudreve/v4/pkg/request"
+	"github.com/cloudreve/Cloudreve/v4/pkg/serializer"
+	"github.com/cloudreve/Cloudreve/v4/pkg/util"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	geeTestSessionPrefix = CaptchaSessionPrefix + "geetest_"
+	geeTestOnline        = "1"
+	geeTestOffline       = "0"
+)
+
+var geeTestEndpoint = "https://api.geetest.com"
+
+type (
+	// GeeTestRegisterResponse is the parameters used to initialize GeeTest captcha on client side.
+	GeeTestRegisterResponse struct {
+		Success    int    `json:"success"`
+		GT         string `json:"gt"`
+		Challenge  string `json:"challenge"`
+		NewCaptcha bool   `json:"new_captcha"`
+	}
+
+	geeTestRegisterResult struct {
+		Challenge string `json:"challenge"`
+	}
+
+	geeTestValidateResult struct {
+		Seccode string `json:"seccode"`
+	}
+)
+
+// RegisterGeeTest requests a new challenge from GeeTest. If GeeTest server is not available, a
+// local challenge is generated and the client falls back to offline mode.
+func RegisterGeeTest(c *gin.Context) (*GeeTestRegisterResponse, error) {
+	dep := dependency.FromContext(c)
+	captchaSetting := dep.SettingProvider().GeeTestCaptcha(c)
+	l := logging.FromContext(c)
+
+	res := &GeeTestRegisterResponse{
+		Success:    1,
+		GT:         captchaSetting.ID,
+		NewCaptcha: true,
+	}
+
+	values := url.Values{}
+	values.Set("gt", captchaSetting.ID)
+	values.Set("json_format", "1")
+	values.Set("new_captcha", "1")
+	values.Set("client_type", "web")
+	values.Set("digestmod", "md5")
+	values.Set("ip_address", c.ClientIP())
+	resp, err := dep.RequestClient(
+		request.WithContext(c),
+		request.WithLogger(l),
+	).Request("GET", geeTestEndpoint+"/register.php?"+values.Encode(), nil).
+		CheckHTTPResponse(http.StatusOK).
+		GetResponse()
+
+	var registered geeTestRegisterResult
+	if err == nil {
+		err = json.Unmarshal([]byte(resp), &registered)
+	}
+
+	session := geeTestOnline
+	if err != nil || len(registered.Challenge) != 32 {
+		l.Warning("Failed to register GeeTest challenge, fallback to offline mode: %v", err)
+		session = geeTestOffline
+		res.Success = 0
+		res.Challenge = md5Hex(util.RandStringRunes(32))
+	} else {
+		res.Challenge = md5Hex(registered.Challenge + captchaSetting.Key)
+	}
+
+	if err := dep.KV().Set(geeTestSessionPrefix+res.Challenge, session, CaptchaTTL); err != nil {
+		return nil, serializer.NewError(serializer.CodeCacheOperation, "Failed to save captcha session", err)
+	}
+
+	return res, nil
+}
+
+// VerifyGeeTest validates the user's response to the challenge issued by RegisterGeeTest. The
+// challenge is consumed no matter the result.
+func VerifyGeeTest(ctx context.Context, challenge, validate, seccode string) error {
+	if challenge == "" || validate == "" || seccode == "" {
+		return errors.New("missing GeeTest response")
+	}
+
+	dep := dependency.FromContext(ctx)
+	kv := dep.KV()
+	session, ok := kv.Get(geeTestSessionPrefix + challenge)
+	if !ok {
+		return errors.New("GeeTest challenge not found or expired")
+	}
+	_ = kv.Delete(geeTestSessionPrefix, challenge)
+
+	// In offline mode the captcha is verified only on client side.
+	if session == geeTestOffline {
+		return nil
+	}
+
+	captchaSetting := dep.SettingProvider().GeeTestCaptcha(ctx)
+	if validate != md5Hex(captchaSetting.Key+"geetest"+challenge) {
+		return errors.New("invalid GeeTest validate")
+	}
+
+	formData := url.Values{}
+	formData.Set("seccode", seccode)
+	formData.Set("challenge", challenge)
+	formData.Set("captchaid", captchaSetting.ID)
+	formData.Set("json_format", "1")
+	formData.Set("sdk", "cloudreve")
+	resp, err := dep.RequestClient(
+		request.WithContext(ctx),
+		request.WithLogger(logging.FromContext(ctx)),
+		request.WithHeader(http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}}),
+	).Request("POST", geeTestEndpoint+"/validate.php", strings.NewReader(formData.Encode())).
+		CheckHTTPResponse(http.StatusOK).
+		GetResponse()
+	if err != nil {
+		return fmt.Errorf("failed to validate with GeeTest: %w", err)
+	}
+
+	var validated geeTestValidateResult
+	if err := json.Unmarshal([]byte(resp), &validated); err != nil {
+		return fmt.Errorf("failed to unmarshal GeeTest validate result: %w", err)
+	}
+
+	if validated.Seccode != md5Hex(seccode) {
+		return errors.New("GeeTest validation returned false")
+	}
+
+	return nil
+}
+
+func md5Hex(s string) string {
+	sum := md5.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,100 @@
+package basic
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cloudreve/Cloudreve/v4/application/dependency"
+	"github.com/cloudreve/Cloudreve/v4/pkg/cache"
+	"github.com/cloudreve/Cloudreve/v4/pkg/conf"
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type geeTestSettings struct {
+	setting.Provider
+}
+
+func (s *geeTestSettings) GeeTestCaptcha(ctx context.Context) *setting.GeeTest {
+	return &setting.GeeTest{ID: "gt-id", Key: "gt-key"}
+}
+
+type masterConfig struct {
+	conf.ConfigProvider
+}
+
+func (c *masterConfig) System() *conf.System {
+	return &conf.System{Mode: conf.MasterMode}
+}
+
+func newGeeTestContext(t *testing.T, online bool) *gin.Context {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !online {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/register.php":
+			_ = json.NewEncoder(w).Encode(geeTestRegisterResult{Challenge: md5Hex("origin")})
+		case "/validate.php":
+			_ = r.ParseForm()
+			_ = json.NewEncoder(w).Encode(geeTestValidateResult{Seccode: md5Hex(r.PostForm.Get("seccode"))})
+		}
+	}))
+	t.Cleanup(srv.Close)
+	oldEndpoint := geeTestEndpoint
+	geeTestEndpoint = srv.URL
+	t.Cleanup(func() { geeTestEndpoint = oldEndpoint })
+
+	dep := dependency.NewDependency(
+		dependency.WithSettingProvider(&geeTestSettings{}),
+		dependency.WithConfigProvider(&masterConfig{}),
+		dependency.WithKV(cache.NewMemoStore("", logging.NewConsoleLogger(logging.LevelError))),
+	)
+	c, engine := gin.CreateTestContext(httptest.NewRecorder())
+	engine.ContextWithFallback = true
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil).
+		WithContext(context.WithValue(context.Background(), dependency.DepCtx{}, dep))
+	return c
+}
+
+func TestGeeTest_Online(t *testing.T) {
+	a := assert.New(t)
+	c := newGeeTestContext(t, true)
+
+	res, err := RegisterGeeTest(c)
+	a.NoError(err)
+	a.Equal(1, res.Success)
+	a.Equal("gt-id", res.GT)
+	a.Equal(md5Hex(md5Hex("origin")+"gt-key"), res.Challenge)
+
+	validate := md5Hex("gt-key" + "geetest" + res.Challenge)
+	a.Error(VerifyGeeTest(c.Request.Context(), res.Challenge, "wrong", "seccode"))
+
+	// Challenge is consumed by failed attempt
+	a.Error(VerifyGeeTest(c.Request.Context(), res.Challenge, validate, "seccode"))
+
+	res, err = RegisterGeeTest(c)
+	a.NoError(err)
+	validate = md5Hex("gt-key" + "geetest" + res.Challenge)
+	a.NoError(VerifyGeeTest(c.Request.Context(), res.Challenge, validate, "seccode"))
+}
+
+func TestGeeTest_Offline(t *testing.T) {
+	a := assert.New(t)
+	c := newGeeTestContext(t, false)
+
+	res, err := RegisterGeeTest(c)
+	a.NoError(err)
+	a.Equal(0, res.Success)
+	a.Len(res.Challenge, 32)
+
+	a.Error(VerifyGeeTest(c.Request.Context(), "unknown", "validate", "seccode"))
+	a.NoError(VerifyGeeTest(c.Request.Context(), res.Challenge, "validate", "seccode"))
+}
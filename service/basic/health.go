@@ -0,0 +1,325 @@
+package basic
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudreve/Cloudreve/v4/application/dependency"
+	"github.com/cloudreve/Cloudreve/v4/inventory"
+	"github.com/cloudreve/Cloudreve/v4/inventory/types"
+	"github.com/cloudreve/Cloudreve/v4/pkg/auth"
+	"github.com/cloudreve/Cloudreve/v4/pkg/email"
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/manager"
+	"github.com/cloudreve/Cloudreve/v4/pkg/queue"
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+)
+
+const (
+	HealthStatusUp       HealthStatus = "up"
+	HealthStatusDegraded HealthStatus = "degraded"
+	HealthStatusDown     HealthStatus = "down"
+)
+
+const (
+	HealthSubsystemDB      = "db"
+	HealthSubsystemCache   = "cache"
+	HealthSubsystemStorage = "storage"
+	HealthSubsystemMail    = "mail"
+	HealthSubsystemQueue   = "queue"
+)
+
+// healthCacheKey is the cache key prefix used by cache round trip probe.
+const healthCacheKey = "health_probe_"
+
+// healthStoragePrefix is the path listed by storage probe, a non-existing path is
+// enough to verify that the storage is reachable with current credentials.
+const healthStoragePrefix = "cloudreve-health-probe"
+
+// ErrHealthDegraded is returned by probes when the subsystem works with reduced functionality.
+var ErrHealthDegraded = errors.New("degraded")
+
+type (
+	HealthStatus string
+
+	// HealthProbe checks the status of a subsystem, returned details are attached to the report.
+	// Returns an error wrapping ErrHealthDegraded if the subsystem is degraded.
+	HealthProbe func(ctx context.Context) (map[string]any, error)
+
+	// SubsystemHealth is the probe result of a subsystem.
+	SubsystemHealth struct {
+		Status  HealthStatus   `json:"status"`
+		Latency int64          `json:"latency_ms"`
+		Error   string         `json:"error,omitempty"`
+		Details map[string]any `json:"details,omitempty"`
+	}
+
+	// HealthReport aggregates status of all subsystems.
+	HealthReport struct {
+		Status     HealthStatus                `json:"status"`
+		Subsystems map[string]*SubsystemHealth `json:"subsystems,omitempty"`
+	}
+
+	// HealthChecker reuses the latest report within ttl, so that frequent polling does not
+	// probe every subsystem on each request.
+	HealthChecker struct {
+		mu        sync.Mutex
+		ttl       time.Duration
+		report    *HealthReport
+		checkedAt time.Time
+	}
+)
+
+func NewHealthChecker(ttl time.Duration) *HealthChecker {
+	return &HealthChecker{ttl: ttl}
+}
+
+// Check returns the cached report if it is not older than ttl, otherwise runs all probes.
+// Concurrent callers wait for the ongoing check instead of starting their own.
+func (h *HealthChecker) Check(ctx context.Context, timeout time.Duration, probes map[string]HealthProbe) *HealthReport {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.report != nil && time.Since(h.checkedAt) < h.ttl {
+		return h.report
+	}
+
+	h.report = CheckHealth(ctx, timeout, probes)
+	h.checkedAt = time.Now()
+	return h.report
+}
+
+// Summary returns a copy of the report with overall status only, details of subsystems
+// (errors, storage policy, etc.) are omitted.
+func (r *HealthReport) Summary() *HealthReport {
+	return &HealthReport{Status: r.Status}
+}
+
+// HealthDetailsAllowed reports whether the requester can view subsystem details of health
+// report. Admins are always allowed, others need to provide the metrics token if it is set.
+func HealthDetailsAllowed(c *gin.Context) bool {
+	if u := inventory.UserFromContext(c); u != nil && u.Edges.Group != nil &&
+		u.Edges.Group.Permissions.Enabled(int(types.GroupPermissionIsAdmin)) {
+		return true
+	}
+
+	token := dependency.FromContext(c).SettingProvider().MetricsToken(c)
+	if token == "" {
+		return false
+	}
+
+	provided := strings.TrimPrefix(c.GetHeader(auth.AuthorizationHeader), auth.TokenHeaderPrefix)
+	return subtle.ConstantTimeCompare([]byte(provided), []byte(token)) == 1
+}
+
+// CheckHealth runs all probes concurrently, each probe is given at most timeout to finish.
+// The overall status is down if any subsystem is down, degraded if any subsystem is degraded.
+func CheckHealth(ctx context.Context, timeout time.Duration, probes map[string]HealthProbe) *HealthReport {
+	report := &HealthReport{
+		Status:     HealthStatusUp,
+		Subsystems: make(map[string]*SubsystemHealth, len(probes)),
+	}
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+	for name, probe := range probes {
+		wg.Add(1)
+		go func(name string, probe HealthProbe) {
+			defer wg.Done()
+			res := runProbe(ctx, timeout, probe)
+			mu.Lock()
+			report.Subsystems[name] = res
+			mu.Unlock()
+		}(name, probe)
+	}
+	wg.Wait()
+
+	for _, res := range report.Subsystems {
+		if res.Status == HealthStatusDown {
+			report.Status = HealthStatusDown
+			break
+		}
+
+		if res.Status == HealthStatusDegraded {
+			report.Status = HealthStatusDegraded
+		}
+	}
+
+	return report
+}
+
+func runProbe(ctx context.Context, timeout time.Duration, probe HealthProbe) *SubsystemHealth {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type result struct {
+		details map[string]any
+		err     error
+	}
+
+	// Some subsystems (e.g. cache) do not accept context, run probe in a separated
+	// goroutine so that timeout is honored anyway.
+	start := time.Now()
+	done := make(chan result, 1)
+	go func() {
+		details, err := probe(ctx)
+		done <- result{details, err}
+	}()
+
+	var r result
+	select {
+	case r = <-done:
+	case <-ctx.Done():
+		r.err = fmt.Errorf("probe timed out after %s: %w", timeout, ctx.Err())
+	}
+
+	res := &SubsystemHealth{
+		Status:  HealthStatusUp,
+		Latency: time.Since(start).Milliseconds(),
+		Details: r.details,
+	}
+	if r.err != nil {
+		res.Error = r.err.Error()
+		res.Status = HealthStatusDown
+		if errors.Is(r.err, ErrHealthDegraded) {
+			res.Status = HealthStatusDegraded
+		}
+	}
+
+	return res
+}
+
+// DefaultHealthProbes returns probes of all subsystems.
+func DefaultHealthProbes(dep dependency.Dep) map[string]HealthProbe {
+	return map[string]HealthProbe{
+		HealthSubsystemDB:      dbProbe(dep),
+		HealthSubsystemCache:   cacheProbe(dep),
+		HealthSubsystemStorage: storageProbe(dep),
+		HealthSubsystemMail:    mailProbe(dep),
+		HealthSubsystemQueue:   queueProbe(dep),
+	}
+}
+
+func dbProbe(dep dependency.Dep) HealthProbe {
+	return func(ctx context.Context) (map[string]any, error) {
+		if _, err := dep.DBClient().Setting.Query().Exist(ctx); err != nil {
+			return nil, fmt.Errorf("failed to query database: %w", err)
+		}
+
+		return nil, nil
+	}
+}
+
+func cacheProbe(dep dependency.Dep) HealthProbe {
+	return func(ctx context.Context) (map[string]any, error) {
+		kv := dep.KV()
+		key := uuid.Must(uuid.NewV4()).String()
+		if err := kv.Set(healthCacheKey+key, key, 60); err != nil {
+			return nil, fmt.Errorf("failed to write cache: %w", err)
+		}
+		defer kv.Delete(healthCacheKey, key)
+
+		val, ok := kv.Get(healthCacheKey + key)
+		if !ok || val != key {
+			return nil, errors.New("cache value read back does not match")
+		}
+
+		return nil, nil
+	}
+}
+
+// storageProbe checks the storage policy of default user group.
+func storageProbe(dep dependency.Dep) HealthProbe {
+	return func(ctx context.Context) (map[string]any, error) {
+		group, err := dep.GroupClient().GetByID(ctx, dep.SettingProvider().DefaultGroup(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get default group: %w", err)
+		}
+
+		policy, err := dep.StoragePolicyClient().GetByGroup(ctx, group)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get default storage policy: %w", err)
+		}
+
+		details := map[string]any{"policy_id": policy.ID, "type": policy.Type}
+		m := manager.NewFileManager(dep, nil)
+		defer m.Recycle()
+
+		d, err := m.GetStorageDriver(ctx, policy)
+		if err != nil {
+			return details, fmt.Errorf("failed to get storage driver: %w", err)
+		}
+
+		if _, err := d.List(ctx, healthStoragePrefix, nil, false); err != nil {
+			return details, fmt.Errorf("storage is not reachable: %w", err)
+		}
+
+		return details, nil
+	}
+}
+
+// mailProbe reports status of async email queue, it is degraded if any email is dropped.
+func mailProbe(dep dependency.Dep) HealthProbe {
+	return func(ctx context.Context) (map[string]any, error) {
+		details := map[string]any{"driver": dep.SettingProvider().MailDriver(ctx)}
+		reporter, ok := dep.EmailClient(ctx).(email.StatusReporter)
+		if !ok {
+			return details, nil
+		}
+
+		status, err := reporter.Status()
+		if err != nil {
+			return details, fmt.Errorf("email driver failed to initialize: %w", err)
+		}
+
+		details["queue"] = status
+		if status.Closed {
+			return details, errors.New("email queue is closed")
+		}
+
+		if status.Failed > 0 {
+			return details, fmt.Errorf("%w: %d emails failed to send", ErrHealthDegraded, status.Failed)
+		}
+
+		return details, nil
+	}
+}
+
+// queueProbe reports depths of task queues.
+func queueProbe(dep dependency.Dep) HealthProbe {
+	return func(ctx context.Context) (map[string]any, error) {
+		return queueDepths(taskQueues(ctx, dep)), nil
+	}
+}
+
+// taskQueues returns all task queues of master node by type.
+func taskQueues(ctx context.Context, dep dependency.Dep) map[setting.QueueType]queue.Queue {
+	return map[setting.QueueType]queue.Queue{
+		setting.QueueTypeMediaMeta:      dep.MediaMetaQueue(ctx),
+		setting.QueueTypeEntityRecycle:  dep.EntityRecycleQueue(ctx),
+		setting.QueueTypeIOIntense:      dep.IoIntenseQueue(ctx),
+		setting.QueueTypeRemoteDownload: dep.RemoteDownloadQueue(ctx),
+		setting.QueueTypeThumb:          dep.ThumbQueue(ctx),
+	}
+}
+
+func queueDepths(queues map[setting.QueueType]queue.Queue) map[string]any {
+	details := make(map[string]any, len(queues))
+	for t, q := range queues {
+		details[string(t)] = map[string]int{
+			"busy_workers": q.BusyWorkers(),
+			"pending":      max(q.SubmittedTasks()-q.SuccessTasks()-q.FailureTasks()-q.BusyWorkers(), 0),
+			"suspending":   q.SuspendingTasks(),
+		}
+	}
+
+	return details
+}
@@ -0,0 +1,124 @@
+package basic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cloudreve/Cloudreve/v4/pkg/queue"
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
+	"github.com/stretchr/testify/assert"
+)
+
+func upProbe(ctx context.Context) (map[string]any, error) {
+	return map[string]any{"ok": true}, nil
+}
+
+func downProbe(ctx context.Context) (map[string]any, error) {
+	return nil, errors.New("connection refused")
+}
+
+func degradedProbe(ctx context.Context) (map[string]any, error) {
+	return nil, fmt.Errorf("%w: 2 emails failed to send", ErrHealthDegraded)
+}
+
+func hangingProbe(ctx context.Context) (map[string]any, error) {
+	// Ignores context, like cache drivers do
+	time.Sleep(time.Second)
+	return nil, nil
+}
+
+func TestCheckHealth(t *testing.T) {
+	a := assert.New(t)
+	ctx := context.Background()
+
+	report := CheckHealth(ctx, time.Second, map[string]HealthProbe{
+		HealthSubsystemDB:    upProbe,
+		HealthSubsystemCache: upProbe,
+	})
+	a.Equal(HealthStatusUp, report.Status)
+	a.Equal(map[string]any{"ok": true}, report.Subsystems[HealthSubsystemDB].Details)
+
+	report = CheckHealth(ctx, time.Second, map[string]HealthProbe{
+		HealthSubsystemDB:   upProbe,
+		HealthSubsystemMail: degradedProbe,
+	})
+	a.Equal(HealthStatusDegraded, report.Status)
+	a.Equal(HealthStatusDegraded, report.Subsystems[HealthSubsystemMail].Status)
+	a.Contains(report.Subsystems[HealthSubsystemMail].Error, "2 emails failed")
+
+	report = CheckHealth(ctx, time.Second, map[string]HealthProbe{
+		HealthSubsystemDB:      downProbe,
+		HealthSubsystemMail:    degradedProbe,
+		HealthSubsystemStorage: upProbe,
+	})
+	a.Equal(HealthStatusDown, report.Status)
+	a.Equal(HealthStatusDown, report.Subsystems[HealthSubsystemDB].Status)
+	a.Equal("connection refused", report.Subsystems[HealthSubsystemDB].Error)
+	a.Equal(HealthStatusUp, report.Subsystems[HealthSubsystemStorage].Status)
+}
+
+func TestCheckHealth_Timeout(t *testing.T) {
+	a := assert.New(t)
+	start := time.Now()
+	report := CheckHealth(context.Background(), 50*time.Millisecond, map[string]HealthProbe{
+		HealthSubsystemCache: hangingProbe,
+		HealthSubsystemDB:    upProbe,
+	})
+	a.Less(time.Since(start), time.Second)
+	a.Equal(HealthStatusDown, report.Status)
+	a.Contains(report.Subsystems[HealthSubsystemCache].Error, "timed out")
+	a.Equal(HealthStatusUp, report.Subsystems[HealthSubsystemDB].Status)
+}
+
+func TestHealthChecker(t *testing.T) {
+	a := assert.New(t)
+	calls := 0
+	probes := map[string]HealthProbe{
+		HealthSubsystemDB: func(ctx context.Context) (map[string]any, error) {
+			calls++
+			return nil, errors.New("connection refused")
+		},
+	}
+
+	// Report is reused within ttl
+	h := NewHealthChecker(time.Hour)
+	report := h.Check(context.Background(), time.Second, probes)
+	a.Equal(HealthStatusDown, report.Status)
+	a.Same(report, h.Check(context.Background(), time.Second, probes))
+	a.Equal(1, calls)
+
+	h = NewHealthChecker(0)
+	h.Check(context.Background(), time.Second, probes)
+	h.Check(context.Background(), time.Second, probes)
+	a.Equal(3, calls)
+
+	// Summary omits subsystem details
+	summary := report.Summary()
+	a.Equal(HealthStatusDown, summary.Status)
+	a.Nil(summary.Subsystems)
+	a.Len(report.Subsystems, 1)
+}
+
+type fakeQueue struct {
+	queue.Queue
+	busy, success, failure, submitted, suspending int
+}
+
+func (q *fakeQueue) BusyWorkers() int     { return q.busy }
+func (q *fakeQueue) SuccessTasks() int    { return q.success }
+func (q *fakeQueue) FailureTasks() int    { return q.failure }
+func (q *fakeQueue) SubmittedTasks() int  { return q.submitted }
+func (q *fakeQueue) SuspendingTasks() int { return q.suspending }
+
+func TestQueueDepths(t *testing.T) {
+	a := assert.New(t)
+	details := queueDepths(map[setting.QueueType]queue.Queue{
+		setting.QueueTypeThumb:     &fakeQueue{busy: 2, success: 5, failure: 1, submitted: 12, suspending: 1},
+		setting.QueueTypeMediaMeta: &fakeQueue{},
+	})
+	a.Equal(map[string]int{"busy_workers": 2, "pending": 4, "suspending": 1}, details["thumb"])
+	a.Equal(map[string]int{"busy_workers": 0, "pending": 0, "suspending": 0}, details["media_meta"])
+}
@@ -0,0 +1,29 @@
+package basic
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/cloudreve/Cloudreve/v4/application/dependency"
+	"github.com/cloudreve/Cloudreve/v4/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// MetricsHandler returns the handler rendering all metrics in Prometheus exposition format.
+func MetricsHandler(dep dependency.Dep) http.Handler {
+	return newMetricsHandler(func() map[string]metrics.QueueStats {
+		res := make(map[string]metrics.QueueStats)
+		for t, q := range taskQueues(context.Background(), dep) {
+			res[string(t)] = q
+		}
+
+		return res
+	})
+}
+
+func newMetricsHandler(queues func() map[string]metrics.QueueStats) http.Handler {
+	queueRegistry := prometheus.NewRegistry()
+	queueRegistry.MustRegister(metrics.NewQueueCollector(queues))
+	return promhttp.HandlerFor(prometheus.Gatherers{metrics.Registry, queueRegistry}, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,43 @@
+package basic
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cloudreve/Cloudreve/v4/pkg/metrics"
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
+	"github.com/prometheus/common/expfmt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsHandler(t *testing.T) {
+	a := assert.New(t)
+	metrics.ObserveStorage("ks3", metrics.StorageOpUpload, 1024, nil)
+	handler := newMetricsHandler(func() map[string]metrics.QueueStats {
+		return map[string]metrics.QueueStats{
+			string(setting.QueueTypeThumb): &fakeQueue{busy: 2, success: 5, failure: 1, submitted: 12, suspending: 1},
+		}
+	})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	a.Equal(http.StatusOK, rec.Code)
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(rec.Body)
+	a.NoError(err)
+	a.Contains(families, "cloudreve_storage_operations_total")
+	a.Contains(families, "cloudreve_storage_bytes_total")
+
+	depth := map[string]float64{}
+	for _, m := range families["cloudreve_queue_tasks"].GetMetric() {
+		for _, l := range m.GetLabel() {
+			if l.GetName() == "state" {
+				depth[l.GetValue()] = m.GetGauge().GetValue()
+			}
+		}
+	}
+	a.Equal(map[string]float64{"pending": 4, "busy": 2, "suspending": 1}, depth)
+	a.Len(families["cloudreve_queue_tasks_total"].GetMetric(), 3)
+}
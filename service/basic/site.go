@@ -1,12 +1,22 @@
 package basic
 
 import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
 	"sort"
 	"strings"
 
 	"github.com/cloudreve/Cloudreve/v4/application/dependency"
 	"github.com/cloudreve/Cloudreve/v4/inventory"
 	"github.com/cloudreve/Cloudreve/v4/inventory/types"
+	"github.com/cloudreve/Cloudreve/v4/pkg/cache"
+	"github.com/cloudreve/Cloudreve/v4/pkg/cluster/routes"
+	"github.com/cloudreve/Cloudreve/v4/pkg/crontab"
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/cloudreve/Cloudreve/v4/pkg/serializer"
 	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
 	"github.com/cloudreve/Cloudreve/v4/pkg/thumb"
 	"github.com/cloudreve/Cloudreve/v4/service/user"
@@ -35,12 +45,16 @@ type SiteConfig struct {
 	ReCaptchaKey     string              `json:"captcha_ReCaptchaKey,omitempty"`
 	CaptchaType      setting.CaptchaType `json:"captcha_type,omitempty"`
 	TurnstileSiteID  string              `json:"turnstile_site_id,omitempty"`
+	HCaptchaSiteKey  string              `json:"captcha_hcaptcha_site_key,omitempty"`
+	GeeTestID        string              `json:"captcha_geetest_id,omitempty"`
+	TcCaptchaAppID   string              `json:"captcha_tcaptcha_app_id,omitempty"`
 	CapInstanceURL   string              `json:"captcha_cap_instance_url,omitempty"`
 	CapSiteKey       string              `json:"captcha_cap_site_key,omitempty"`
 	CapAssetServer   string              `json:"captcha_cap_asset_server,omitempty"`
 	RegisterEnabled  bool                `json:"register_enabled,omitempty"`
 	TosUrl           string              `json:"tos_url,omitempty"`
 	PrivacyPolicyUrl string              `json:"privacy_policy_url,omitempty"`
+	MaintenanceMode  bool                `json:"maintenance_mode,omitempty"`
 
 	// Explorer section
 	Icons             string                    `json:"icons,omitempty"`
@@ -55,7 +69,8 @@ type SiteConfig struct {
 	CustomProps       []types.CustomProps       `json:"custom_props,omitempty"`
 
 	// Thumbnail section
-	ThumbExts []string `json:"thumb_exts,omitempty"`
+	ThumbExts     []string `json:"thumb_exts,omitempty"`
+	ThumbAnimated bool     `json:"thumb_animated,omitempty"`
 
 	// App settings
 	AppPromotion bool `json:"app_promotion,omitempty"`
@@ -78,6 +93,73 @@ type (
 	GetSettingParamCtx struct{}
 )
 
+const (
+	// siteConfigMaxAgeCap caps max-age of cacheable site config sections, so that setting
+	// changes reach clients in reasonable time even with long public_resource_maxage.
+	siteConfigMaxAgeCap = 300
+)
+
+// cacheableSiteConfigSections are sections that do not depend on current user.
+var cacheableSiteConfigSections = map[string]bool{
+	"login":    true,
+	"explorer": true,
+	"emojis":   true,
+	"app":      true,
+	"thumb":    true,
+}
+
+// GetSiteConfigWithCache returns site config of the section. For sections not depending on
+// current user, ETag and Cache-Control headers are set, and notModified is true if client's
+// If-None-Match matches current ETag, in which case config is not built.
+func (s *GetSettingService) GetSiteConfigWithCache(c *gin.Context) (config *SiteConfig, notModified bool, err error) {
+	if !cacheableSiteConfigSections[s.Section] {
+		config, err = s.GetSiteConfig(c)
+		return config, false, err
+	}
+
+	dep := dependency.FromContext(c)
+	kv := dep.KV()
+	maxAge := min(dep.SettingProvider().PublicResourceMaxAge(c), siteConfigMaxAgeCap)
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d, must-revalidate", max(maxAge, 0)))
+
+	if cached, ok := kv.Get(setting.KvSiteConfigETagPrefix + s.Section); ok {
+		if etag, ok := cached.(string); ok && etagMatch(c.GetHeader("If-None-Match"), etag) {
+			c.Header("ETag", etag)
+			return nil, true, nil
+		}
+	}
+
+	config, err = s.GetSiteConfig(c)
+	if err != nil {
+		return nil, false, err
+	}
+
+	content, err := json.Marshal(config)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to encode site config: %w", err)
+	}
+
+	sum := sha1.Sum(content)
+	etag := "\"" + hex.EncodeToString(sum[:]) + "\""
+	_ = kv.Set(setting.KvSiteConfigETagPrefix+s.Section, etag, 0)
+	c.Header("ETag", etag)
+
+	return config, etagMatch(c.GetHeader("If-None-Match"), etag), nil
+}
+
+// etagMatch reports whether If-None-Match header matches given strong ETag, using weak
+// comparison as required by RFC 7232.
+func etagMatch(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (s *GetSettingService) GetSiteConfig(c *gin.Context) (*SiteConfig, error) {
 	dep := dependency.FromContext(c)
 	settings := dep.SettingProvider()
@@ -90,9 +172,14 @@ func (s *GetSettingService) GetSiteConfig(c *gin.Context) (*SiteConfig, error) {
 			RegCaptcha:       settings.RegCaptchaEnabled(c),
 			ForgetCaptcha:    settings.ForgotPasswordCaptchaEnabled(c),
 			Authn:            settings.AuthnEnabled(c),
+			HCaptchaSiteKey:  settings.HCaptcha(c).Key,
+			GeeTestID:        settings.GeeTestCaptcha(c).ID,
+			TcCaptchaAppID:   settings.TcCaptcha(c).AppID,
+			CapAssetServer:   settings.CapCaptcha(c).AssetServer,
 			RegisterEnabled:  settings.RegisterEnabled(c),
 			PrivacyPolicyUrl: legalDocs.PrivacyPolicy,
 			TosUrl:           legalDocs.TermsOfService,
+			MaintenanceMode:  settings.MaintenanceModeEnabled(c),
 		}, nil
 	case "explorer":
 		explorerSettings := settings.ExplorerFrontendSettings(c)
@@ -160,6 +247,15 @@ func (s *GetSettingService) GetSiteConfig(c *gin.Context) (*SiteConfig, error) {
 				exts[strings.ToLower(e)] = true
 			}
 		}
+		if settings.PdfThumbGeneratorEnabled(c) && thumb.PdfRendererAvailable() {
+			exts["pdf"] = true
+		}
+		if settings.SvgThumbGeneratorEnabled(c) {
+			exts["svg"] = true
+		}
+		if settings.EpubThumbGeneratorEnabled(c) {
+			exts["epub"] = true
+		}
 
 		// map -> sorted slice
 		result := make([]string, 0, len(exts))
@@ -167,7 +263,10 @@ func (s *GetSettingService) GetSiteConfig(c *gin.Context) (*SiteConfig, error) {
 			result = append(result, e)
 		}
 		sort.Strings(result)
-		return &SiteConfig{ThumbExts: result}, nil
+		return &SiteConfig{
+			ThumbExts:     result,
+			ThumbAnimated: settings.FFMpegThumbGeneratorEnabled(c) && settings.FFMpegAnimatedThumb(c).Enabled,
+		}, nil
 	default:
 		break
 	}
@@ -192,6 +291,9 @@ func (s *GetSettingService) GetSiteConfig(c *gin.Context) (*SiteConfig, error) {
 		LogoLight:       logo.Light,
 		CaptchaType:     settings.CaptchaType(c),
 		TurnstileSiteID: settings.TurnstileCaptcha(c).Key,
+		HCaptchaSiteKey: settings.HCaptcha(c).Key,
+		GeeTestID:       settings.GeeTestCaptcha(c).ID,
+		TcCaptchaAppID:  settings.TcCaptcha(c).AppID,
 		ReCaptchaKey:    reCaptcha.Key,
 		CapInstanceURL:  capCaptcha.InstanceURL,
 		CapSiteKey:      capCaptcha.SiteKey,
@@ -199,6 +301,7 @@ func (s *GetSettingService) GetSiteConfig(c *gin.Context) (*SiteConfig, error) {
 		AppPromotion:    appSetting.Promotion,
 		CustomNavItems:  customNavItems,
 		CustomHTML:      customHTML,
+		MaintenanceMode: settings.MaintenanceModeEnabled(c),
 	}, nil
 }
 
@@ -207,11 +310,42 @@ const (
 	CaptchaTTL           = 1800 // 30 minutes
 )
 
+func init() {
+	crontab.Register(setting.CronTypeCaptchaSessionCollect, CronCollectCaptchaSessions)
+}
+
+// CronCollectCaptchaSessions removes expired captcha sessions. Only needed for in-memory KV,
+// Redis evicts them by TTL.
+func CronCollectCaptchaSessions(ctx context.Context) {
+	dep := dependency.FromContext(ctx)
+	store, ok := dep.KV().(*cache.MemoStore)
+	if !ok {
+		return
+	}
+
+	l := dep.Logger()
+	if collected := store.GarbageCollectPrefix(l, CaptchaSessionPrefix); collected > 0 {
+		l.Info("Collected %d expired captcha sessions.", collected)
+	}
+}
+
 type (
 	CaptchaResponse struct {
 		Image  string `json:"image"`
 		Ticket string `json:"ticket"`
+		// Audio is the URL to get audio companion of the captcha, only set if audio captcha is enabled.
+		Audio string `json:"audio,omitempty"`
+	}
+
+	CaptchaAudioResponse struct {
+		Audio string `json:"audio"`
 	}
+
+	GetCaptchaAudioService struct {
+		Ticket   string `form:"ticket" binding:"required"`
+		Language string `form:"lang"`
+	}
+	GetCaptchaAudioParamCtx struct{}
 )
 
 // GetCaptchaImage generates captcha session
@@ -237,8 +371,74 @@ func GetCaptchaImage(c *gin.Context) *CaptchaResponse {
 
 	base64stringD := base64Captcha.CaptchaWriteToBase64Encoding(capD)
 
-	return &CaptchaResponse{
+	res := &CaptchaResponse{
 		Image:  base64stringD,
 		Ticket: idKeyD,
 	}
+
+	if captchaSettings.AudioEnabled {
+		// Session value is the ID of audio captcha, empty until audio is requested.
+		if err := dep.KV().Set(CaptchaSessionPrefix+idKeyD, "", CaptchaTTL); err != nil {
+			logging.FromContext(c).Warning("Failed to create captcha session: %s", err)
+			return res
+		}
+
+		res.Audio = routes.MasterCaptchaAudioUrl(dep.SettingProvider().SiteURL(c), idKeyD).String()
+	}
+
+	return res
+}
+
+// GetCaptchaAudio generates audio companion of the captcha identified by ticket. Audio captcha
+// only reads out digits, so it has its own answer stored in the captcha session.
+func (s *GetCaptchaAudioService) GetCaptchaAudio(c *gin.Context) (*CaptchaAudioResponse, error) {
+	dep := dependency.FromContext(c)
+	captchaSettings := dep.SettingProvider().Captcha(c)
+	if !captchaSettings.AudioEnabled {
+		return nil, serializer.NewError(serializer.CodeFeatureNotEnabled, "Audio captcha is not enabled", nil)
+	}
+
+	kv := dep.KV()
+	if _, ok := kv.Get(CaptchaSessionPrefix + s.Ticket); !ok {
+		return nil, serializer.NewError(serializer.CodeNotFound, "Captcha session not found or expired", nil)
+	}
+
+	language := s.Language
+	if language == "" {
+		language = "en"
+	}
+
+	idKeyA, capA := base64Captcha.GenerateCaptcha("", base64Captcha.ConfigAudio{
+		CaptchaLen: captchaSettings.Length,
+		Language:   language,
+	})
+	if err := kv.Set(CaptchaSessionPrefix+s.Ticket, idKeyA, CaptchaTTL); err != nil {
+		return nil, serializer.NewError(serializer.CodeCacheOperation, "Failed to save captcha session", err)
+	}
+
+	return &CaptchaAudioResponse{
+		Audio: base64Captcha.CaptchaWriteToBase64Encoding(capA),
+	}, nil
+}
+
+// VerifyCaptcha verifies answer of the builtin captcha identified by ticket, answer of either the
+// image or its audio companion is accepted. Both challenges are consumed after verification.
+func VerifyCaptcha(kv cache.Driver, ticket, answer string) bool {
+	if ticket == "" {
+		return false
+	}
+
+	passed := base64Captcha.VerifyCaptcha(ticket, answer)
+	session, ok := kv.Get(CaptchaSessionPrefix + ticket)
+	if !ok {
+		return passed
+	}
+
+	_ = kv.Delete(CaptchaSessionPrefix, ticket)
+	if audioID, ok := session.(string); ok && audioID != "" {
+		// Always verify to clear the audio answer from store
+		passed = base64Captcha.VerifyCaptcha(audioID, answer) || passed
+	}
+
+	return passed
 }
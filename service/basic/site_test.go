@@ -0,0 +1,135 @@
+package basic
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cloudreve/Cloudreve/v4/application/dependency"
+	"github.com/cloudreve/Cloudreve/v4/pkg/cache"
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
+	"github.com/gin-gonic/gin"
+	"github.com/mojocn/base64Captcha"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestCaptcha generates an image captcha with audio companion, returns ticket and both answers.
+func newTestCaptcha(t *testing.T, kv cache.Driver) (string, string, string) {
+	ticket, image := base64Captcha.GenerateCaptcha("", base64Captcha.ConfigCharacter{Height: 60, Width: 240, CaptchaLen: 4})
+	audioID, audio := base64Captcha.GenerateCaptcha("", base64Captcha.ConfigAudio{CaptchaLen: 4, Language: "en"})
+	if err := kv.Set(CaptchaSessionPrefix+ticket, audioID, CaptchaTTL); err != nil {
+		t.Fatal(err)
+	}
+
+	return ticket, image.(*base64Captcha.CaptchaImageChar).VerifyValue, audio.(*base64Captcha.Audio).VerifyValue
+}
+
+func TestVerifyCaptcha(t *testing.T) {
+	a := assert.New(t)
+	kv := cache.NewMemoStore("", logging.NewConsoleLogger(logging.LevelError))
+
+	// Image answer
+	ticket, imageAnswer, _ := newTestCaptcha(t, kv)
+	a.True(VerifyCaptcha(kv, ticket, imageAnswer))
+	a.False(VerifyCaptcha(kv, ticket, imageAnswer))
+
+	// Audio answer
+	ticket, _, audioAnswer := newTestCaptcha(t, kv)
+	a.True(VerifyCaptcha(kv, ticket, audioAnswer))
+	_, ok := kv.Get(CaptchaSessionPrefix + ticket)
+	a.False(ok)
+
+	// Failed attempt consumes both challenges
+	ticket, imageAnswer, audioAnswer = newTestCaptcha(t, kv)
+	a.False(VerifyCaptcha(kv, ticket, "wrong"))
+	a.False(VerifyCaptcha(kv, ticket, audioAnswer))
+	a.False(VerifyCaptcha(kv, ticket, imageAnswer))
+
+	// Image only captcha without session
+	ticket, image := base64Captcha.GenerateCaptcha("", base64Captcha.ConfigCharacter{Height: 60, Width: 240, CaptchaLen: 4})
+	a.True(VerifyCaptcha(kv, ticket, image.(*base64Captcha.CaptchaImageChar).VerifyValue))
+	a.False(VerifyCaptcha(kv, "", ""))
+}
+
+func TestCronCollectCaptchaSessions(t *testing.T) {
+	a := assert.New(t)
+	l := logging.NewConsoleLogger(logging.LevelError)
+	kv := cache.NewMemoStore("", l)
+	a.NoError(kv.Set(CaptchaSessionPrefix+"expired", "", 1))
+	a.NoError(kv.Set(CaptchaSessionPrefix+"valid", "", CaptchaTTL))
+	a.NoError(kv.Set("other_expired", "", 1))
+	time.Sleep(2 * time.Second)
+
+	dep := dependency.NewDependency(dependency.WithKV(kv), dependency.WithLogger(l))
+	CronCollectCaptchaSessions(context.WithValue(context.Background(), dependency.DepCtx{}, dep))
+
+	_, ok := kv.Store.Load(CaptchaSessionPrefix + "expired")
+	a.False(ok)
+	_, ok = kv.Store.Load(CaptchaSessionPrefix + "valid")
+	a.True(ok)
+	_, ok = kv.Store.Load("other_expired")
+	a.True(ok)
+}
+
+type siteConfigSettings struct {
+	setting.Provider
+	emojis string
+}
+
+func (s *siteConfigSettings) EmojiPresets(ctx context.Context) string      { return s.emojis }
+func (s *siteConfigSettings) PublicResourceMaxAge(ctx context.Context) int { return 86400 }
+
+func TestGetSiteConfigWithCache(t *testing.T) {
+	a := assert.New(t)
+	kv := cache.NewMemoStore("", logging.NewConsoleLogger(logging.LevelError))
+	settings := &siteConfigSettings{emojis: `{"smileys":["😀"]}`}
+	dep := dependency.NewDependency(
+		dependency.WithSettingProvider(settings),
+		dependency.WithKV(kv),
+	)
+	request := func(section, ifNoneMatch string) (*httptest.ResponseRecorder, *SiteConfig, bool) {
+		w := httptest.NewRecorder()
+		c, engine := gin.CreateTestContext(w)
+		engine.ContextWithFallback = true
+		c.Request = httptest.NewRequest(http.MethodGet, "/", nil).
+			WithContext(context.WithValue(context.Background(), dependency.DepCtx{}, dep))
+		c.Request.Header.Set("If-None-Match", ifNoneMatch)
+		config, notModified, err := (&GetSettingService{Section: section}).GetSiteConfigWithCache(c)
+		a.NoError(err)
+		return w, config, notModified
+	}
+
+	w, config, notModified := request("emojis", "")
+	a.False(notModified)
+	a.Equal(settings.emojis, config.EmojiPreset)
+	a.Equal("public, max-age=300, must-revalidate", w.Header().Get("Cache-Control"))
+	etag := w.Header().Get("ETag")
+	a.NotEmpty(etag)
+
+	// Matching ETag from cache, config is not rebuilt
+	w, config, notModified = request("emojis", `"other", W/`+etag)
+	a.True(notModified)
+	a.Nil(config)
+	a.Equal(etag, w.Header().Get("ETag"))
+
+	// Stale ETag
+	_, config, notModified = request("emojis", `"other"`)
+	a.False(notModified)
+	a.NotNil(config)
+
+	// Settings changed, cache is cleared on save
+	settings.emojis = `{"smileys":["😃"]}`
+	a.NoError(kv.Delete(setting.KvSiteConfigETagPrefix))
+	w, _, notModified = request("emojis", etag)
+	a.False(notModified)
+	a.NotEqual(etag, w.Header().Get("ETag"))
+
+	// Stable across cache misses
+	newEtag := w.Header().Get("ETag")
+	a.NoError(kv.Delete(setting.KvSiteConfigETagPrefix))
+	_, _, notModified = request("emojis", newEtag)
+	a.True(notModified)
+}
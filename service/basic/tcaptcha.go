@@ -0,0 +1,164 @@
+package basic
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudreve/Cloudreve/v4/application/dependency"
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/cloudreve/Cloudreve/v4/pkg/request"
+)
+
+const (
+	tcCaptchaService = "captcha"
+	tcCaptchaAction  = "DescribeCaptchaResult"
+	tcCaptchaVersion = "2019-07-22"
+	// tcCaptchaTypeSlider is the only captcha type accepted by DescribeCaptchaResult.
+	tcCaptchaTypeSlider = 9
+	// tcCaptchaCodeOK is the CaptchaCode returned for a passed verification.
+	tcCaptchaCodeOK = 1
+)
+
+var tcCaptchaEndpoint = "https://captcha.tencentcloudapi.com"
+
+// ErrCaptchaNotConfigured is returned when credentials of the captcha provider are missing.
+var ErrCaptchaNotConfigured = errors.New("captcha provider is not configured")
+
+type (
+	tcCaptchaRequest struct {
+		CaptchaType  int    `json:"CaptchaType"`
+		Ticket       string `json:"Ticket"`
+		UserIp       string `json:"UserIp"`
+		Randstr      string `json:"Randstr"`
+		CaptchaAppId uint64 `json:"CaptchaAppId"`
+		AppSecretKey string `json:"AppSecretKey"`
+	}
+
+	tcCaptchaResponse struct {
+		Response struct {
+			CaptchaCode int    `json:"CaptchaCode"`
+			CaptchaMsg  string `json:"CaptchaMsg"`
+			Error       *struct {
+				Code    string `json:"Code"`
+				Message string `json:"Message"`
+			} `json:"Error"`
+			RequestId string `json:"RequestId"`
+		} `json:"Response"`
+	}
+)
+
+// VerifyTcCaptcha validates the ticket and randstr returned by Tencent Cloud Captcha on client side
+// against DescribeCaptchaResult API.
+func VerifyTcCaptcha(ctx context.Context, ticket, randstr, userIP string) error {
+	dep := dependency.FromContext(ctx)
+	captchaSetting := dep.SettingProvider().TcCaptcha(ctx)
+	appID, err := strconv.ParseUint(captchaSetting.AppID, 10, 64)
+	if err != nil || captchaSetting.AppSecretKey == "" || captchaSetting.SecretID == "" || captchaSetting.SecretKey == "" {
+		return ErrCaptchaNotConfigured
+	}
+
+	if ticket == "" || randstr == "" {
+		return errors.New("missing Tencent Captcha response")
+	}
+
+	payload, err := json.Marshal(tcCaptchaRequest{
+		CaptchaType:  tcCaptchaTypeSlider,
+		Ticket:       ticket,
+		UserIp:       userIP,
+		Randstr:      randstr,
+		CaptchaAppId: appID,
+		AppSecretKey: captchaSetting.AppSecretKey,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	endpoint, err := url.Parse(tcCaptchaEndpoint)
+	if err != nil {
+		return fmt.Errorf("failed to parse endpoint: %w", err)
+	}
+
+	now := time.Now()
+	contentType := "application/json; charset=utf-8"
+	resp, err := dep.RequestClient(
+		request.WithContext(ctx),
+		request.WithLogger(logging.FromContext(ctx)),
+		request.WithHeader(http.Header{
+			"Content-Type":   []string{contentType},
+			"Authorization":  []string{tc3Authorization(captchaSetting.SecretID, captchaSetting.SecretKey, endpoint.Host, contentType, payload, now)},
+			"X-TC-Action":    []string{tcCaptchaAction},
+			"X-TC-Version":   []string{tcCaptchaVersion},
+			"X-TC-Timestamp": []string{strconv.FormatInt(now.Unix(), 10)},
+		}),
+	).Request("POST", endpoint.String(), strings.NewReader(string(payload))).
+		CheckHTTPResponse(http.StatusOK).
+		GetResponse()
+	if err != nil {
+		return fmt.Errorf("failed to request Tencent Captcha: %w", err)
+	}
+
+	var res tcCaptchaResponse
+	if err := json.Unmarshal([]byte(resp), &res); err != nil {
+		return fmt.Errorf("failed to unmarshal Tencent Captcha response: %w", err)
+	}
+
+	if res.Response.Error != nil {
+		return fmt.Errorf("Tencent Captcha API error %s: %s", res.Response.Error.Code, res.Response.Error.Message)
+	}
+
+	if res.Response.CaptchaCode != tcCaptchaCodeOK {
+		return fmt.Errorf("Tencent Captcha validation returned %d: %s", res.Response.CaptchaCode, res.Response.CaptchaMsg)
+	}
+
+	return nil
+}
+
+// tc3Authorization signs a Tencent Cloud API request with TC3-HMAC-SHA256.
+func tc3Authorization(secretID, secretKey, host, contentType string, payload []byte, t time.Time) string {
+	date := t.UTC().Format("2006-01-02")
+	canonicalRequest := strings.Join([]string{
+		"POST",
+		"/",
+		"",
+		"content-type:" + contentType + "\nhost:" + host + "\n",
+		"content-type;host",
+		sha256Hex(payload),
+	}, "\n")
+
+	credentialScope := date + "/" + tcCaptchaService + "/tc3_request"
+	stringToSign := strings.Join([]string{
+		"TC3-HMAC-SHA256",
+		strconv.FormatInt(t.Unix(), 10),
+		credentialScope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	secretDate := hmacSHA256([]byte("TC3"+secretKey), date)
+	secretService := hmacSHA256(secretDate, tcCaptchaService)
+	secretSigning := hmacSHA256(secretService, "tc3_request")
+	signature := hex.EncodeToString(hmacSHA256(secretSigning, stringToSign))
+
+	return fmt.Sprintf("TC3-HMAC-SHA256 Credential=%s/%s, SignedHeaders=content-type;host, Signature=%s",
+		secretID, credentialScope, signature)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
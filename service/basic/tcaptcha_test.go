@@ -0,0 +1,71 @@
+package basic
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cloudreve/Cloudreve/v4/application/dependency"
+	"github.com/cloudreve/Cloudreve/v4/pkg/setting"
+	"github.com/stretchr/testify/assert"
+)
+
+type tcCaptchaSettings struct {
+	setting.Provider
+	captcha *setting.TcCaptcha
+}
+
+func (s *tcCaptchaSettings) TcCaptcha(ctx context.Context) *setting.TcCaptcha {
+	return s.captcha
+}
+
+func TestVerifyTcCaptcha(t *testing.T) {
+	a := assert.New(t)
+	var received tcCaptchaRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal(tcCaptchaAction, r.Header.Get("X-TC-Action"))
+		a.Equal(tcCaptchaVersion, r.Header.Get("X-TC-Version"))
+		a.NotEmpty(r.Header.Get("X-TC-Timestamp"))
+		a.True(strings.HasPrefix(r.Header.Get("Authorization"), "TC3-HMAC-SHA256 Credential=secret-id/"))
+		a.Contains(r.Header.Get("Authorization"), "/captcha/tc3_request, SignedHeaders=content-type;host, Signature=")
+		a.NoError(json.NewDecoder(r.Body).Decode(&received))
+
+		switch received.Ticket {
+		case "valid":
+			_, _ = w.Write([]byte(`{"Response":{"CaptchaCode":1,"CaptchaMsg":"OK","RequestId":"1"}}`))
+		case "invalid":
+			_, _ = w.Write([]byte(`{"Response":{"CaptchaCode":9,"CaptchaMsg":"verify ticket timeout","RequestId":"2"}}`))
+		default:
+			_, _ = w.Write([]byte(`{"Response":{"Error":{"Code":"AuthFailure.SignatureFailure","Message":"signature mismatch"},"RequestId":"3"}}`))
+		}
+	}))
+	defer srv.Close()
+	oldEndpoint := tcCaptchaEndpoint
+	tcCaptchaEndpoint = srv.URL
+	defer func() { tcCaptchaEndpoint = oldEndpoint }()
+
+	settings := &tcCaptchaSettings{captcha: &setting.TcCaptcha{AppID: "190000", AppSecretKey: "app-secret", SecretID: "secret-id", SecretKey: "secret-key"}}
+	dep := dependency.NewDependency(
+		dependency.WithSettingProvider(settings),
+		dependency.WithConfigProvider(&masterConfig{}),
+	)
+	ctx := context.WithValue(context.Background(), dependency.DepCtx{}, dep)
+
+	a.NoError(VerifyTcCaptcha(ctx, "valid", "rand", "1.2.3.4"))
+	a.Equal(tcCaptchaRequest{CaptchaType: 9, Ticket: "valid", UserIp: "1.2.3.4", Randstr: "rand", CaptchaAppId: 190000, AppSecretKey: "app-secret"}, received)
+
+	err := VerifyTcCaptcha(ctx, "invalid", "rand", "1.2.3.4")
+	a.ErrorContains(err, "verify ticket timeout")
+	err = VerifyTcCaptcha(ctx, "other", "rand", "1.2.3.4")
+	a.ErrorContains(err, "AuthFailure.SignatureFailure")
+	a.Error(VerifyTcCaptcha(ctx, "", "rand", "1.2.3.4"))
+
+	// Missing or malformed credentials
+	settings.captcha = &setting.TcCaptcha{AppID: "190000", AppSecretKey: "app-secret", SecretID: "secret-id"}
+	a.ErrorIs(VerifyTcCaptcha(ctx, "valid", "rand", "1.2.3.4"), ErrCaptchaNotConfigured)
+	settings.captcha = &setting.TcCaptcha{AppID: "app", AppSecretKey: "app-secret", SecretID: "secret-id", SecretKey: "secret-key"}
+	a.ErrorIs(VerifyTcCaptcha(ctx, "valid", "rand", "1.2.3.4"), ErrCaptchaNotConfigured)
+}
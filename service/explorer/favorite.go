@@ -0,0 +1,76 @@
+package explorer
+
+import (
+	"github.com/cloudreve/Cloudreve/v4/application/dependency"
+	"github.com/cloudreve/Cloudreve/v4/inventory"
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/fs"
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/manager"
+	"github.com/cloudreve/Cloudreve/v4/pkg/serializer"
+	"github.com/gin-gonic/gin"
+)
+
+type (
+	FavoriteFileService struct {
+		Uris []string `json:"uris" binding:"required"`
+	}
+	FavoriteFileParameterCtx struct{}
+)
+
+func (s *FavoriteFileService) GetUris() []string {
+	return s.Uris
+}
+
+// Favorite marks given files as favorite
+func (s *FavoriteFileService) Favorite(c *gin.Context) error {
+	return s.setFavorite(c, true)
+}
+
+// Unfavorite removes favorite flag of given files
+func (s *FavoriteFileService) Unfavorite(c *gin.Context) error {
+	return s.setFavorite(c, false)
+}
+
+func (s *FavoriteFileService) setFavorite(c *gin.Context, favorite bool) error {
+	dep := dependency.FromContext(c)
+	user := inventory.UserFromContext(c)
+	m := manager.NewFileManager(dep, user)
+	defer m.Recycle()
+
+	uris, err := fs.NewUriFromStrings(s.Uris...)
+	if err != nil {
+		return serializer.NewError(serializer.CodeParamErr, "unknown uri", err)
+	}
+
+	return m.SetFavorite(c, uris, favorite)
+}
+
+type (
+	// ListFavoriteService stores parameters for listing favorite files
+	ListFavoriteService struct {
+		PageSize       int    `form:"page_size" json:"page_size"`
+		OrderBy        string `form:"order_by" json:"order_by"`
+		OrderDirection string `form:"order_direction" json:"order_direction"`
+		NextPageToken  string `form:"next_page_token" json:"next_page_token"`
+	}
+	ListFavoriteParameterCtx struct{}
+)
+
+// List lists favorite files of current user across all folders
+func (s *ListFavoriteService) List(c *gin.Context) (*ListResponse, error) {
+	dep := dependency.FromContext(c)
+	user := inventory.UserFromContext(c)
+	m := manager.NewFileManager(dep, user)
+	defer m.Recycle()
+
+	parent, res, err := m.ListFavorites(c, &manager.ListArgs{
+		PageSize:       s.PageSize,
+		Order:          s.OrderBy,
+		OrderDirection: s.OrderDirection,
+		PageToken:      s.NextPageToken,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return BuildListResponse(c, user, parent, res, dep.HashIDEncoder()), nil
+}
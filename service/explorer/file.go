@@ -75,9 +75,24 @@ func (service *ArchiveService) DownloadArchived(c *gin.Context) error {
 
 	// 开始打包
 	c.Header("Content-Disposition", "attachment;")
-	c.Header("Content-Type", "application/zip")
+	if archiveSession.Format == fs.ArchiveFormatTarGz {
+		c.Header("Content-Type", "application/gzip")
+	} else {
+		c.Header("Content-Type", "application/zip")
+	}
+
+	opts := []fs.Option{
+		fs.WithArchiveFormat(archiveSession.Format),
+		fs.WithArchiveAllVersions(archiveSession.AllVersions),
+	}
+	// Stream compressed data to client after each file instead of buffering it, if supported.
+	if flusher, ok := c.Writer.(http.Flusher); ok {
+		opts = append(opts, fs.WithProgressFunc(func(current, diff int64, total int64) {
+			flusher.Flush()
+		}))
+	}
 
-	if _, err := fm.CreateArchive(c, archiveSession.Uris, c.Writer); err != nil {
+	if _, err := fm.CreateArchive(c, archiveSession.Uris, c.Writer, opts...); err != nil {
 		return serializer.NewError(serializer.CodeIOFailed, "Failed to create archive", err)
 	}
 
@@ -356,14 +371,16 @@ func (service *FileUpdateService) PutContent(c *gin.Context, ls fs.LockSession)
 type (
 	FileURLParameterCtx struct{}
 	FileURLService      struct {
-		Uris              []string `json:"uris" binding:"required"`
-		Download          bool     `json:"download"`
-		Redirect          bool     `json:"redirect"` // Only works if Uris count is 1.
-		Entity            string   `json:"entity"`   // Only works if Uris count is 1.
-		UsePrimarySiteURL bool     `json:"use_primary_site_url"`
-		SkipError         bool     `json:"skip_error"`
-		Archive           bool     `json:"archive"`
-		NoCache           bool     `json:"no_cache"`
+		Uris               []string `json:"uris" binding:"required"`
+		Download           bool     `json:"download"`
+		Redirect           bool     `json:"redirect"` // Only works if Uris count is 1.
+		Entity             string   `json:"entity"`   // Only works if Uris count is 1.
+		UsePrimarySiteURL  bool     `json:"use_primary_site_url"`
+		SkipError          bool     `json:"skip_error"`
+		Archive            bool     `json:"archive"`
+		ArchiveFormat      string   `json:"archive_format" binding:"omitempty,oneof=zip tar.gz"`
+		ArchiveAllVersions bool     `json:"archive_all_versions"`
+		NoCache            bool     `json:"no_cache"`
 	}
 	FileURLResponse struct {
 		Urls    []manager.EntityUrl `json:"urls"`
@@ -372,6 +389,8 @@ type (
 	ArchiveDownloadSession struct {
 		Uris        []*fs.URI `json:"uris"`
 		RequesterID int       `json:"requester_id"`
+		Format      string    `json:"format"`
+		AllVersions bool      `json:"all_versions"`
 	}
 )
 
@@ -402,6 +421,8 @@ func (s *FileURLService) GetArchiveDownloadSession(c *gin.Context) (*FileURLResp
 	archiveSession := &ArchiveDownloadSession{
 		Uris:        uris,
 		RequesterID: user.ID,
+		Format:      s.ArchiveFormat,
+		AllVersions: s.ArchiveAllVersions,
 	}
 	sessionId := uuid.Must(uuid.NewV4()).String()
 	ttl := settings.ArchiveDownloadSessionTTL(c)
@@ -495,6 +516,15 @@ type (
 
 // Get redirect to thumb file.
 func (s *FileThumbService) Get(c *gin.Context) (*FileThumbResponse, error) {
+	return s.get(c, false)
+}
+
+// GetAnimated redirect to animated preview of video file.
+func (s *FileThumbService) GetAnimated(c *gin.Context) (*FileThumbResponse, error) {
+	return s.get(c, true)
+}
+
+func (s *FileThumbService) get(c *gin.Context, animated bool) (*FileThumbResponse, error) {
 	dep := dependency.FromContext(c)
 	user := inventory.UserFromContext(c)
 	m := manager.NewFileManager(dep, user)
@@ -506,7 +536,12 @@ func (s *FileThumbService) Get(c *gin.Context) (*FileThumbResponse, error) {
 	}
 
 	// Get thumbnail
-	thumb, err := m.Thumbnail(c, uri)
+	thumbGetter := m.Thumbnail
+	if animated {
+		thumbGetter = m.AnimatedThumbnail
+	}
+
+	thumb, err := thumbGetter(c, uri)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get thumbnail: %w", err)
 	}
@@ -740,10 +775,10 @@ func (s *ArchiveListFilesService) List(c *gin.Context) (*ArchiveListFilesRespons
 		return nil, serializer.NewError(serializer.CodeParamErr, "unknown uri", err)
 	}
 
-	files, err := m.ListArchiveFiles(c, uri, s.Entity, s.TextEncoding)
+	files, encoding, err := m.ListArchiveFiles(c, uri, s.Entity, s.TextEncoding)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list archive files: %w", err)
 	}
 
-	return BuildArchiveListFilesResponse(files), nil
+	return BuildArchiveListFilesResponse(files, encoding), nil
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/manager"
 	"github.com/cloudreve/Cloudreve/v4/pkg/serializer"
 	"github.com/gin-gonic/gin"
+	"github.com/samber/lo"
 )
 
 type (
@@ -35,3 +36,51 @@ func (s *PatchMetadataService) Patch(c *gin.Context) error {
 
 	return m.PatchMedata(c, uris, s.Patches...)
 }
+
+type (
+	PatchMetadataBatchService struct {
+		Items []PatchMetadataBatchItem `json:"items" binding:"required,dive"`
+	}
+	PatchMetadataBatchItem struct {
+		Uri     string             `json:"uri" binding:"required"`
+		Patches []fs.MetadataPatch `json:"patches" binding:"required,dive"`
+	}
+
+	PatchMetadataBatchParameterCtx struct{}
+)
+
+func (s *PatchMetadataBatchService) GetUris() []string {
+	return lo.Map(s.Items, func(item PatchMetadataBatchItem, _ int) string {
+		return item.Uri
+	})
+}
+
+// Patch applies patches of each item to its file, failed items are reported in aggregate error.
+func (s *PatchMetadataBatchService) Patch(c *gin.Context) error {
+	dep := dependency.FromContext(c)
+	user := inventory.UserFromContext(c)
+	m := manager.NewFileManager(dep, user)
+	defer m.Recycle()
+
+	items := make([]manager.MetadataPatchItem, 0, len(s.Items))
+	for _, item := range s.Items {
+		uri, err := fs.NewUriFromString(item.Uri)
+		if err != nil {
+			return serializer.NewError(serializer.CodeParamErr, "unknown uri", err)
+		}
+
+		items = append(items, manager.MetadataPatchItem{URI: uri, Patches: item.Patches})
+	}
+
+	return m.PatchMetadataBatch(c, items)
+}
+
+// ListTagTree lists tags of current user's files as a tree.
+func ListTagTree(c *gin.Context) ([]*inventory.TagNode, error) {
+	dep := dependency.FromContext(c)
+	user := inventory.UserFromContext(c)
+	m := manager.NewFileManager(dep, user)
+	defer m.Recycle()
+
+	return m.ListTagTree(c)
+}
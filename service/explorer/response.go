@@ -16,6 +16,7 @@ import (
 	"github.com/cloudreve/Cloudreve/v4/pkg/boolset"
 	"github.com/cloudreve/Cloudreve/v4/pkg/cluster/routes"
 	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/fs"
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/fs/dbfs"
 	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/manager"
 	"github.com/cloudreve/Cloudreve/v4/pkg/hashid"
 	"github.com/cloudreve/Cloudreve/v4/pkg/queue"
@@ -28,11 +29,22 @@ import (
 
 type ArchiveListFilesResponse struct {
 	Files []manager.ArchivedFile `json:"files"`
+	// Encoding is the text encoding used to decode non-UTF8 entry names, either specified
+	// by the client or detected automatically.
+	Encoding string `json:"encoding,omitempty"`
+	// UnsafeEntries is the number of entries escaping destination folder, they are never extracted.
+	UnsafeEntries int `json:"unsafe_entries,omitempty"`
+	// SuspiciousEntries is the number of entries with implausible compression ratio, archive with
+	// any of them cannot be extracted.
+	SuspiciousEntries int `json:"suspicious_entries,omitempty"`
 }
 
-func BuildArchiveListFilesResponse(files []manager.ArchivedFile) *ArchiveListFilesResponse {
+func BuildArchiveListFilesResponse(files []manager.ArchivedFile, encoding string) *ArchiveListFilesResponse {
 	return &ArchiveListFilesResponse{
-		Files: files,
+		Files:             files,
+		Encoding:          encoding,
+		UnsafeEntries:     lo.CountBy(files, func(f manager.ArchivedFile) bool { return f.Unsafe }),
+		SuspiciousEntries: lo.CountBy(files, func(f manager.ArchivedFile) bool { return f.Suspicious }),
 	}
 }
 
@@ -238,6 +250,7 @@ type FileResponse struct {
 	Capability    *boolset.BooleanSet `json:"capability,omitempty"`
 	Owned         bool                `json:"owned,omitempty"`
 	PrimaryEntity string              `json:"primary_entity,omitempty"`
+	DominantColor string              `json:"dominant_color,omitempty"`
 
 	FolderSummary *fs.FolderSummary `json:"folder_summary,omitempty"`
 	ExtendedInfo  *ExtendedInfo     `json:"extended_info,omitempty"`
@@ -279,6 +292,8 @@ type Entity struct {
 	CreatedAt     time.Time        `json:"created_at"`
 	StoragePolicy *StoragePolicy   `json:"storage_policy,omitempty"`
 	CreatedBy     *user.User       `json:"created_by,omitempty"`
+	// Checksums are content checksums keyed by hash algorithm.
+	Checksums map[string]string `json:"checksums,omitempty"`
 }
 
 type Share struct {
@@ -388,6 +403,7 @@ func BuildFileResponse(ctx context.Context, u *ent.User, f fs.File, hasher hashi
 		FolderSummary: f.FolderSummary(),
 		ExtendedInfo:  BuildExtendedInfo(ctx, u, f, hasher),
 		PrimaryEntity: hashid.EncodeEntityID(hasher, f.PrimaryEntityID()),
+		DominantColor: f.Metadata()[dbfs.ThumbDominantColorKey],
 	}
 	return res
 }
@@ -446,6 +462,7 @@ func BuildEntity(extendedInfo *fs.FileExtendedInfo, e fs.Entity, hasher hashid.E
 		StoragePolicy: BuildStoragePolicy(extendedInfo.EntityStoragePolicies[e.PolicyID()], hasher),
 		Size:          e.Size(),
 		CreatedBy:     u,
+		Checksums:     e.Checksums(),
 	}
 }
 
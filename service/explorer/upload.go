@@ -7,6 +7,7 @@ import (
 	"github.com/cloudreve/Cloudreve/v4/inventory"
 	"github.com/cloudreve/Cloudreve/v4/inventory/types"
 	"github.com/cloudreve/Cloudreve/v4/pkg/cluster"
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/driver"
 	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/fs"
 	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/manager"
 	"github.com/cloudreve/Cloudreve/v4/pkg/hashid"
@@ -28,6 +29,7 @@ type (
 		PolicyID     string            `json:"policy_id"`
 		Metadata     map[string]string `json:"metadata" binding:"max=256"`
 		EntityType   string            `json:"entity_type" binding:"eq=|eq=live_photo|eq=version"`
+		ExpectedHash string            `json:"expected_hash" binding:"max=128"`
 	}
 )
 
@@ -68,6 +70,7 @@ func (service *CreateUploadSessionService) Create(c context.Context) (*UploadSes
 			Metadata:               service.Metadata,
 			EntityType:             entityType,
 			PreferredStoragePolicy: policyId,
+			ExpectedHash:           service.ExpectedHash,
 		},
 	}
 
@@ -220,3 +223,21 @@ func (service *DeleteUploadSessionService) Delete(c *gin.Context) error {
 
 	return m.CancelUploadSession(c, uri, service.ID)
 }
+
+type (
+	ListUploadedPartsParameterCtx struct{}
+	ListUploadedPartsService      struct {
+		ID string `uri:"sessionId" binding:"required"`
+	}
+)
+
+// List lists parts already uploaded in the specified upload session, clients can resume
+// the upload by only uploading missing parts.
+func (service *ListUploadedPartsService) List(c *gin.Context) ([]driver.UploadedPart, error) {
+	dep := dependency.FromContext(c)
+	user := inventory.UserFromContext(c)
+	m := manager.NewFileManager(dep, user)
+	defer m.Recycle()
+
+	return m.ListUploadedParts(c, service.ID)
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/manager"
 	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/manager/entitysource"
 	"github.com/cloudreve/Cloudreve/v4/pkg/hashid"
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
 	"github.com/cloudreve/Cloudreve/v4/pkg/serializer"
 	"github.com/cloudreve/Cloudreve/v4/pkg/wopi"
 	"github.com/gin-gonic/gin"
@@ -43,87 +44,150 @@ func prepareFs(c *gin.Context) (*fs.URI, manager.FileManager, *ent.User, *manage
 	return uri, m, user, viewerSession, dep, nil
 }
 
+// wopiLockTarget is the resolved file of WOPI lock operations.
+type wopiLockTarget struct {
+	m    manager.FileManager
+	l    logging.Logger
+	user *ent.User
+	app  lock.Application
+	file fs.File
+}
+
+func newWopiLockTarget(c *gin.Context) (*wopiLockTarget, error) {
+	uri, m, user, viewerSession, dep, err := prepareFs(c)
+	if err != nil {
+		return nil, err
+	}
+
+	// Make sure file exists and readable
+	file, err := m.Get(c, uri, dbfs.WithRequiredCapabilities(dbfs.NavigatorCapabilityLockFile), dbfs.WithNotRoot())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file: %w", err)
+	}
+
+	return &wopiLockTarget{
+		m:    m,
+		l:    dep.Logger(),
+		user: user,
+		app: lock.Application{
+			Type:     string(fs.ApplicationViewer),
+			ViewerID: viewerSession.ViewerID,
+		},
+		file: file,
+	}, nil
+}
+
 func (service *WopiService) Unlock(c *gin.Context) error {
-	_, m, _, _, dep, err := prepareFs(c)
+	t, err := newWopiLockTarget(c)
 	if err != nil {
 		return err
 	}
 
-	l := dep.Logger()
+	return t.unlock(c)
+}
 
-	lockToken := c.GetHeader(wopi.LockTokenHeader)
-	if err = m.Unlock(c, lockToken); err != nil {
-		l.Debug("WOPI unlock, not locked or not match: %w", err)
-		c.Status(http.StatusConflict)
-		c.Header(wopi.LockTokenHeader, "")
-		return nil
+// UnlockAndRelock replaces the lock identified by X-WOPI-OldLock with the one in X-WOPI-Lock.
+func (service *WopiService) UnlockAndRelock(c *gin.Context) error {
+	t, err := newWopiLockTarget(c)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	return t.unlockAndRelock(c)
 }
 
 func (service *WopiService) RefreshLock(c *gin.Context) error {
-	uri, m, _, _, dep, err := prepareFs(c)
+	t, err := newWopiLockTarget(c)
 	if err != nil {
 		return err
 	}
 
-	l := dep.Logger()
+	return t.refresh(c)
+}
 
-	// Make sure file exists and readable
-	file, err := m.Get(c, uri, dbfs.WithRequiredCapabilities(dbfs.NavigatorCapabilityLockFile), dbfs.WithNotRoot())
+func (service *WopiService) Lock(c *gin.Context) error {
+	t, err := newWopiLockTarget(c)
 	if err != nil {
-		return fmt.Errorf("failed to get file: %w", err)
+		return err
 	}
 
+	return t.lock(c)
+}
+
+func (t *wopiLockTarget) unlock(c *gin.Context) error {
+	// Only unlock the lock on current file
 	lockToken := c.GetHeader(wopi.LockTokenHeader)
-	release, _, err := m.ConfirmLock(c, file, file.Uri(false), lockToken)
+	release, _, err := t.m.ConfirmLock(c, t.file, t.file.Uri(false), lockToken)
 	if err != nil {
-		// File not locked for token not match
+		t.l.Debug("WOPI unlock, not locked or not match: %s", err)
+		t.conflict(c)
+		return nil
+	}
 
-		l.Debug("WOPI refresh lock, not locked or not match: %w", err)
-		c.Status(http.StatusConflict)
-		c.Header(wopi.LockTokenHeader, "")
+	release()
+	if err = t.m.Unlock(c, lockToken); err != nil {
+		t.l.Debug("WOPI unlock, not locked or not match: %s", err)
+		t.conflict(c)
 		return nil
+	}
 
+	return nil
+}
+
+func (t *wopiLockTarget) unlockAndRelock(c *gin.Context) error {
+	oldLockToken := c.GetHeader(wopi.OldLockHeader)
+	release, _, err := t.m.ConfirmLock(c, t.file, t.file.Uri(false), oldLockToken)
+	if err != nil {
+		t.l.Debug("WOPI unlock and relock, not locked or not match: %s", err)
+		t.conflict(c)
+		return nil
 	}
 
-	// refresh lock
+	// Token is swapped atomically, old lock is kept if relock fails.
 	release()
-	_, err = m.Refresh(c, wopi.LockDuration, lockToken)
-	if err != nil {
-		return err
+	lockToken := c.GetHeader(wopi.LockTokenHeader)
+	if _, err := t.m.Relock(c, wopi.LockDuration, oldLockToken, lockToken); err != nil {
+		if errors.Is(err, lock.ErrLocked) || errors.Is(err, lock.ErrNoSuchLock) {
+			t.l.Debug("WOPI unlock and relock, failed to swap lock: %s", err)
+			t.conflict(c)
+			return nil
+		}
+
+		return fmt.Errorf("failed to relock file: %w", err)
 	}
 
 	c.Header(wopi.LockTokenHeader, lockToken)
 	return nil
 }
 
-func (service *WopiService) Lock(c *gin.Context) error {
-	uri, m, user, viewerSession, dep, err := prepareFs(c)
+func (t *wopiLockTarget) refresh(c *gin.Context) error {
+	lockToken := c.GetHeader(wopi.LockTokenHeader)
+	release, _, err := t.m.ConfirmLock(c, t.file, t.file.Uri(false), lockToken)
 	if err != nil {
-		return err
+		// File not locked for token not match
+		t.l.Debug("WOPI refresh lock, not locked or not match: %s", err)
+		t.conflict(c)
+		return nil
 	}
 
-	l := dep.Logger()
-
-	// Make sure file exists and readable
-	file, err := m.Get(c, uri, dbfs.WithRequiredCapabilities(dbfs.NavigatorCapabilityLockFile), dbfs.WithNotRoot())
-	if err != nil {
-		return fmt.Errorf("failed to get file: %w", err)
+	// refresh lock
+	release()
+	if _, err = t.m.Refresh(c, wopi.LockDuration, lockToken); err != nil {
+		return err
 	}
 
+	c.Header(wopi.LockTokenHeader, lockToken)
+	return nil
+}
+
+func (t *wopiLockTarget) lock(c *gin.Context) error {
 	lockToken := c.GetHeader(wopi.LockTokenHeader)
-	release, _, err := m.ConfirmLock(c, file, file.Uri(false), lockToken)
+	release, _, err := t.m.ConfirmLock(c, t.file, t.file.Uri(false), lockToken)
 	if err != nil {
 		// File not locked for token not match
 
 		// Try to lock using given token
-		app := lock.Application{
-			Type:     string(fs.ApplicationViewer),
-			ViewerID: viewerSession.ViewerID,
-		}
-		_, err = m.Lock(c, wopi.LockDuration, user, true, app, file.Uri(false), lockToken)
+		_, err = t.m.Lock(c, wopi.LockDuration, t.user, true, t.app, t.file.Uri(false), lockToken)
 		if err != nil {
 			// Token not match
 			var lockConflict lock.ConflictError
@@ -131,7 +195,7 @@ func (service *WopiService) Lock(c *gin.Context) error {
 				c.Status(http.StatusConflict)
 				c.Header(wopi.LockTokenHeader, lockConflict[0].Token)
 
-				l.Debug("WOPI lock, lock conflict: %w", err)
+				t.l.Debug("WOPI lock, lock conflict: %s", err)
 				return nil
 			}
 
@@ -146,8 +210,7 @@ func (service *WopiService) Lock(c *gin.Context) error {
 
 	// refresh lock
 	release()
-	_, err = m.Refresh(c, wopi.LockDuration, lockToken)
-	if err != nil {
+	if _, err = t.m.Refresh(c, wopi.LockDuration, lockToken); err != nil {
 		return err
 	}
 
@@ -155,7 +218,19 @@ func (service *WopiService) Lock(c *gin.Context) error {
 	return nil
 }
 
-func (service *WopiService) PutContent(c *gin.Context, isPutRelative bool) error {
+// conflict responds lock mismatch with the token of lock currently on the file, which is
+// empty if the file is not locked.
+func (t *wopiLockTarget) conflict(c *gin.Context) {
+	current := ""
+	if detail := t.m.PeekLock(c, t.file, t.file.Uri(false)); detail != nil {
+		current = detail.Token
+	}
+
+	c.Status(http.StatusConflict)
+	c.Header(wopi.LockTokenHeader, current)
+}
+
+func (service *WopiService) PutContent(c *gin.Context) error {
 	uri, m, user, viewerSession, _, err := prepareFs(c)
 	if err != nil {
 		return err
@@ -209,62 +284,156 @@ func (service *WopiService) PutContent(c *gin.Context, isPutRelative bool) error
 		lockSession = ls
 	}
 
-	fileUri := viewerSession.Uri
-	if isPutRelative {
+	subService := FileUpdateService{
+		Uri: viewerSession.Uri,
+	}
+
+	res, err := subService.PutContent(c, lockSession)
+	if err != nil {
+		return handleWopiPutError(c, err)
+	}
+
+	c.Header(wopi.ItemVersionHeader, res.PrimaryEntity)
+	return nil
+}
+
+// PutRelative creates a new file next to the file of current session, with the name given in either
+// X-WOPI-SuggestedTarget (can be adjusted to avoid conflict) or X-WOPI-RelativeTarget (must be used as is).
+func (service *WopiService) PutRelative(c *gin.Context) error {
+	uri, m, user, _, dep, err := prepareFs(c)
+	if err != nil {
+		return err
+	}
+
+	file, err := m.Get(c, uri, dbfs.WithNotRoot())
+	if err != nil {
+		return fmt.Errorf("failed to get file: %w", err)
+	}
+
+	// Same as UserCanNotWriteRelative in CheckFileInfo
+	if file.OwnerID() != user.ID || uri.FileSystem() != constants.FileSystemMy {
+		c.Status(http.StatusNotImplemented)
+		return nil
+	}
+
+	suggested, relative := c.GetHeader(wopi.SuggestedTargetHeader), c.GetHeader(wopi.RelativeTargetHeader)
+	if (suggested == "") == (relative == "") {
+		// Exactly one of the two headers must be present.
+		c.Status(http.StatusNotImplemented)
+		return nil
+	}
+
+	dir := uri.DirUri()
+	var target *fs.URI
+	if suggested != "" {
 		// If the header contains only a file extension (starts with a period), then the resulting file name will consist of this extension and the initial file name without extension.
 		// If the header contains a full file name, then it will be a name for the resulting file.
-		fileName, err := wopi.UTF7Decode(c.GetHeader(wopi.SuggestedTargetHeader))
+		fileName, err := wopi.UTF7Decode(suggested)
 		if err != nil {
 			return fmt.Errorf("failed to decode X-WOPI-SuggestedTarget header (UTF-7): %w", err)
 		}
 
-		fileUriParsed, err := fs.NewUriFromString(fileUri)
+		if strings.HasPrefix(fileName, ".") {
+			fileName = strings.TrimSuffix(file.Name(), filepath.Ext(file.Name())) + fileName
+		}
+
+		target = dir.JoinRaw(availableRelativeName(c, m, dir, fileName))
+	} else {
+		fileName, err := wopi.UTF7Decode(relative)
 		if err != nil {
-			return fmt.Errorf("failed to parse file uri: %w", err)
+			return fmt.Errorf("failed to decode X-WOPI-RelativeTarget header (UTF-7): %w", err)
 		}
 
-		if strings.HasPrefix(fileName, ".") {
-			fileName = strings.TrimSuffix(fileUriParsed.Name(), filepath.Ext(fileUriParsed.Name())) + fileName
+		target = dir.JoinRaw(fileName)
+		if _, err := m.Get(c, target); err == nil && c.GetHeader(wopi.OverwriteRelativeTargetHeader) != "true" {
+			c.Header(wopi.ValidRelativeTargetHeader, wopi.UTF7Encode(availableRelativeName(c, m, dir, fileName)))
+			c.Status(http.StatusConflict)
+			return nil
 		}
-		fileUri = fileUriParsed.DirUri().JoinRaw(fileName).String()
 	}
 
 	subService := FileUpdateService{
-		Uri: fileUri,
+		Uri: target.String(),
 	}
 
-	res, err := subService.PutContent(c, lockSession)
+	res, err := subService.PutContent(c, nil)
 	if err != nil {
 		var appErr serializer.AppError
-		if errors.As(err, &appErr) {
-			switch appErr.Code {
-			case serializer.CodeFileTooLarge:
-				c.Status(http.StatusRequestEntityTooLarge)
-				c.Header(wopi.ServerErrorHeader, err.Error())
-			case serializer.CodeNotFound:
-				c.Status(http.StatusNotFound)
-				c.Header(wopi.ServerErrorHeader, err.Error())
-			case 0:
-				c.Status(http.StatusOK)
-			default:
-				return err
-			}
-
+		if errors.As(err, &appErr) && appErr.Code == serializer.CodeIllegalObjectName {
+			c.Header(wopi.ValidRelativeTargetHeader, wopi.UTF7Encode(availableRelativeName(c, m, dir, file.Name())))
+			c.Status(http.StatusBadRequest)
 			return nil
 		}
 
-		return err
+		return handleWopiPutError(c, err)
 	}
 
-	c.Header(wopi.ItemVersionHeader, res.PrimaryEntity)
-	if isPutRelative {
-		c.JSON(http.StatusOK, PutRelativeResponse{
-			Name: res.Name,
-		})
+	// Access token of current session is bound to the original file, create a new one for the new file.
+	viewerSession, err := m.CreateViewerSession(c, target, "", manager.ViewerFromContext(c))
+	if err != nil {
+		return fmt.Errorf("failed to create viewer session for new file: %w", err)
 	}
+
+	wopiSrc := routes.MasterWopiSrc(dep.SettingProvider().SiteURL(c), hashid.EncodeFileID(dep.HashIDEncoder(), viewerSession.File.ID()))
+	query := wopiSrc.Query()
+	query.Set(wopi.AccessTokenQuery, viewerSession.AccessToken)
+	wopiSrc.RawQuery = query.Encode()
+
+	c.Header(wopi.ItemVersionHeader, res.PrimaryEntity)
+	c.JSON(http.StatusOK, PutRelativeResponse{
+		Name: res.Name,
+		Url:  wopiSrc.String(),
+	})
 	return nil
 }
 
+// availableRelativeName returns given name, or name with a numeric suffix if a file with given name
+// already exists under dir.
+func availableRelativeName(c *gin.Context, m manager.FileManager, dir *fs.URI, name string) string {
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	candidate := name
+	for i := 1; i <= 100; i++ {
+		if _, err := m.Get(c, dir.JoinRaw(candidate)); err != nil {
+			return candidate
+		}
+
+		candidate = fmt.Sprintf("%s (%d)%s", base, i, ext)
+	}
+
+	return fmt.Sprintf("%s_%d%s", base, time.Now().UnixNano(), ext)
+}
+
+// handleWopiPutError writes WOPI status for errors of updating file content.
+func handleWopiPutError(c *gin.Context, err error) error {
+	var lockConflict lock.ConflictError
+	if errors.As(err, &lockConflict) && len(lockConflict) > 0 {
+		c.Status(http.StatusConflict)
+		c.Header(wopi.LockTokenHeader, lockConflict[0].Token)
+		return nil
+	}
+
+	var appErr serializer.AppError
+	if errors.As(err, &appErr) {
+		switch appErr.Code {
+		case serializer.CodeFileTooLarge:
+			c.Status(http.StatusRequestEntityTooLarge)
+			c.Header(wopi.ServerErrorHeader, err.Error())
+		case serializer.CodeNotFound:
+			c.Status(http.StatusNotFound)
+			c.Header(wopi.ServerErrorHeader, err.Error())
+		case 0:
+			c.Status(http.StatusOK)
+		default:
+			return err
+		}
+
+		return nil
+	}
+
+	return err
+}
+
 func (service *WopiService) GetFile(c *gin.Context) error {
 	uri, m, _, viewerSession, dep, err := prepareFs(c)
 	if err != nil {
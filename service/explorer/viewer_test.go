@@ -0,0 +1,186 @@
+package explorer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cloudreve/Cloudreve/v4/ent"
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/fs"
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/lock"
+	"github.com/cloudreve/Cloudreve/v4/pkg/filemanager/manager"
+	"github.com/cloudreve/Cloudreve/v4/pkg/logging"
+	"github.com/cloudreve/Cloudreve/v4/pkg/wopi"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// wopiLockFm serves lock operations of file manager from an in-memory lock system.
+type wopiLockFm struct {
+	manager.FileManager
+	ls lock.LockSystem
+}
+
+func (m *wopiLockFm) ConfirmLock(ctx context.Context, ancestor fs.File, uri *fs.URI, token ...string) (func(), fs.LockSession, error) {
+	release, _, err := m.ls.Confirm(time.Now(), lock.LockInfo{Ns: "my", Root: uri.Path(), Token: token})
+	return release, nil, err
+}
+
+func (m *wopiLockFm) Lock(ctx context.Context, d time.Duration, requester *ent.User, zeroDepth bool, application lock.Application,
+	uri *fs.URI, token string) (fs.LockSession, error) {
+	_, err := m.ls.Create(time.Now(), lock.LockDetails{
+		Ns:        "my",
+		Root:      uri.Path(),
+		Duration:  d,
+		ZeroDepth: zeroDepth,
+		Token:     token,
+		Owner:     lock.Owner{Application: application},
+	})
+	return nil, err
+}
+
+func (m *wopiLockFm) Unlock(ctx context.Context, tokens ...string) error {
+	return m.ls.Unlock(time.Now(), tokens...)
+}
+
+func (m *wopiLockFm) Refresh(ctx context.Context, d time.Duration, token string) (lock.LockDetails, error) {
+	return m.ls.Refresh(time.Now(), d, token)
+}
+
+func (m *wopiLockFm) Relock(ctx context.Context, d time.Duration, oldToken, newToken string) (lock.LockDetails, error) {
+	return m.ls.Relock(time.Now(), d, oldToken, newToken)
+}
+
+func (m *wopiLockFm) PeekLock(ctx context.Context, ancestor fs.File, uri *fs.URI) *lock.ConflictDetail {
+	return m.ls.Peek(time.Now(), "my", uri.Path())
+}
+
+type wopiLockFile struct {
+	fs.File
+	uri *fs.URI
+}
+
+func (f *wopiLockFile) Uri(isRoot bool) *fs.URI {
+	return f.uri
+}
+
+func newTestWopiLockTarget(t *testing.T, ls lock.LockSystem, path string) *wopiLockTarget {
+	uri, err := fs.NewUriFromString("cloudreve://my" + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &wopiLockTarget{
+		m:    &wopiLockFm{ls: ls},
+		l:    logging.NewConsoleLogger(logging.LevelError),
+		user: &ent.User{ID: 1},
+		app:  lock.Application{Type: string(fs.ApplicationViewer), ViewerID: "viewer"},
+		file: &wopiLockFile{uri: uri},
+	}
+}
+
+// wopiLockRequest runs a WOPI lock operation with given X-WOPI-Lock and X-WOPI-OldLock headers,
+// returns the status code and X-WOPI-Lock header of response.
+func wopiLockRequest(t *testing.T, op func(c *gin.Context) error, token, oldToken string) (int, string) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+	c.Request.Header.Set(wopi.LockTokenHeader, token)
+	if oldToken != "" {
+		c.Request.Header.Set(wopi.OldLockHeader, oldToken)
+	}
+
+	if err := op(c); err != nil {
+		t.Fatal(err)
+	}
+
+	c.Writer.WriteHeaderNow()
+	return w.Code, w.Header().Get(wopi.LockTokenHeader)
+}
+
+func TestWopiLockTarget_Lock(t *testing.T) {
+	a := assert.New(t)
+	target := newTestWopiLockTarget(t, lock.NewMemLS(nil, logging.NewConsoleLogger(logging.LevelError)), "/doc.docx")
+
+	status, token := wopiLockRequest(t, target.lock, "a", "")
+	a.Equal(http.StatusOK, status)
+	a.Equal("a", token)
+
+	// Same token refreshes the lock
+	status, token = wopiLockRequest(t, target.lock, "a", "")
+	a.Equal(http.StatusOK, status)
+	a.Equal("a", token)
+
+	// Mismatch returns current lock
+	status, token = wopiLockRequest(t, target.lock, "b", "")
+	a.Equal(http.StatusConflict, status)
+	a.Equal("a", token)
+}
+
+func TestWopiLockTarget_RefreshLock(t *testing.T) {
+	a := assert.New(t)
+	ls := lock.NewMemLS(nil, logging.NewConsoleLogger(logging.LevelError))
+	target := newTestWopiLockTarget(t, ls, "/doc.docx")
+
+	// Not locked
+	status, token := wopiLockRequest(t, target.refresh, "a", "")
+	a.Equal(http.StatusConflict, status)
+	a.Equal("", token)
+
+	wopiLockRequest(t, target.lock, "a", "")
+	status, token = wopiLockRequest(t, target.refresh, "b", "")
+	a.Equal(http.StatusConflict, status)
+	a.Equal("a", token)
+
+	status, token = wopiLockRequest(t, target.refresh, "a", "")
+	a.Equal(http.StatusOK, status)
+	a.Equal("a", token)
+	a.Equal("a", ls.Peek(time.Now(), "my", "/doc.docx").Token)
+}
+
+func TestWopiLockTarget_UnlockAndRelock(t *testing.T) {
+	a := assert.New(t)
+	ls := lock.NewMemLS(nil, logging.NewConsoleLogger(logging.LevelError))
+	target := newTestWopiLockTarget(t, ls, "/doc.docx")
+	wopiLockRequest(t, target.lock, "a", "")
+
+	// Old lock mismatch
+	status, token := wopiLockRequest(t, target.unlockAndRelock, "c", "b")
+	a.Equal(http.StatusConflict, status)
+	a.Equal("a", token)
+
+	status, token = wopiLockRequest(t, target.unlockAndRelock, "c", "a")
+	a.Equal(http.StatusOK, status)
+	a.Equal("c", token)
+	a.Equal("c", ls.Peek(time.Now(), "my", "/doc.docx").Token)
+
+	// New token is used by another file, old lock is kept
+	wopiLockRequest(t, newTestWopiLockTarget(t, ls, "/other.docx").lock, "d", "")
+	status, token = wopiLockRequest(t, target.unlockAndRelock, "d", "c")
+	a.Equal(http.StatusConflict, status)
+	a.Equal("c", token)
+	a.Equal("c", ls.Peek(time.Now(), "my", "/doc.docx").Token)
+}
+
+func TestWopiLockTarget_Unlock(t *testing.T) {
+	a := assert.New(t)
+	ls := lock.NewMemLS(nil, logging.NewConsoleLogger(logging.LevelError))
+	target := newTestWopiLockTarget(t, ls, "/doc.docx")
+	wopiLockRequest(t, target.lock, "a", "")
+
+	status, token := wopiLockRequest(t, target.unlock, "b", "")
+	a.Equal(http.StatusConflict, status)
+	a.Equal("a", token)
+
+	status, _ = wopiLockRequest(t, target.unlock, "a", "")
+	a.Equal(http.StatusOK, status)
+	a.Nil(ls.Peek(time.Now(), "my", "/doc.docx"))
+
+	// Not locked, current lock is empty
+	status, token = wopiLockRequest(t, target.unlock, "a", "")
+	a.Equal(http.StatusConflict, status)
+	a.Equal("", token)
+}
@@ -2,6 +2,7 @@ package user
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/cloudreve/Cloudreve/v4/application/dependency"
@@ -108,12 +109,15 @@ func (service *UserResetEmailService) Reset(c *gin.Context) error {
 	queries.Add("secret", secret)
 	resetUrl.RawQuery = queries.Encode()
 
-	title, body, err := email.NewResetEmail(c, dep.SettingProvider(), u, resetUrl.String())
+	title, body, plainBody, err := email.NewResetEmail(c, dep.SettingProvider(), u, resetUrl.String())
 	if err != nil {
 		return serializer.NewError(serializer.CodeFailedSendEmail, "Failed to send activation email", err)
 	}
 
-	if err := dep.EmailClient(c).Send(c, u.Email, title, body); err != nil {
+	if err := dep.EmailClient(c).Send(c, u.Email, title, body, email.WithPlainBody(plainBody), email.WithRateLimit(u)); err != nil {
+		if errors.Is(err, email.ErrRateLimited) {
+			return serializer.NewError(serializer.CodeTooManyRequests, err.Error(), err)
+		}
 		return serializer.NewError(serializer.CodeFailedSendEmail, "Failed to send activation email", err)
 	}
 
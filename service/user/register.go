@@ -108,12 +108,15 @@ func sendActivationEmail(ctx context.Context, dep dependency.Dep, newUser *ent.U
 	finalURL.RawQuery = queries.Encode()
 
 	// 返送激活邮件
-	title, body, err := email.NewActivationEmail(ctx, dep.SettingProvider(), newUser, finalURL.String())
+	title, body, plainBody, err := email.NewActivationEmail(ctx, dep.SettingProvider(), newUser, finalURL.String())
 	if err != nil {
 		return serializer.NewError(serializer.CodeFailedSendEmail, "Failed to send activation email", err)
 	}
 
-	if err := dep.EmailClient(ctx).Send(ctx, newUser.Email, title, body); err != nil {
+	if err := dep.EmailClient(ctx).Send(ctx, newUser.Email, title, body, email.WithPlainBody(plainBody), email.WithRateLimit(newUser)); err != nil {
+		if errors.Is(err, email.ErrRateLimited) {
+			return serializer.NewError(serializer.CodeTooManyRequests, err.Error(), err)
+		}
 		return serializer.NewError(serializer.CodeFailedSendEmail, "Failed to send activation email", err)
 	}
 